@@ -0,0 +1,93 @@
+package api
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"GNote/api/gnotev1"
+	"GNote/events"
+	"GNote/models"
+)
+
+// changeKinds сопоставляет типы событий шины типам изменений API
+var changeKinds = map[events.Kind]gnotev1.Change_Kind{
+	events.NoteCreated:       gnotev1.Change_NOTE_CREATED,
+	events.NoteUpdated:       gnotev1.Change_NOTE_UPDATED,
+	events.NoteDeleted:       gnotev1.Change_NOTE_DELETED,
+	events.AttachmentCreated: gnotev1.Change_ATTACHMENT_CREATED,
+	events.AttachmentDeleted: gnotev1.Change_ATTACHMENT_DELETED,
+}
+
+// noteToProto преобразует заметку в сообщение API; вложения передаются, если они загружены
+func noteToProto(n *models.Note) *gnotev1.Note {
+	p := &gnotev1.Note{
+		Id:               int64(n.ID),
+		Title:            n.Title,
+		Content:          n.Content,
+		CreatedAt:        timestamppb.New(n.CreatedAt),
+		UpdatedAt:        timestamppb.New(n.UpdatedAt),
+		ReminderAt:       timestampOf(n.ReminderAt),
+		DueAt:            timestampOf(n.DueAt),
+		Tags:             n.Tags,
+		SourceUrl:        n.SourceURL,
+		Favorite:         n.Favorite,
+		Priority:         gnotev1.Priority(n.Priority),
+		ContentTruncated: n.ContentTruncated,
+	}
+	if n.Location != nil {
+		p.Location = &gnotev1.Location{Name: n.Location.Name, Lat: n.Location.Lat, Lon: n.Location.Lon}
+	}
+	for i := range n.Attachments {
+		p.Attachments = append(p.Attachments, attachmentToProto(&n.Attachments[i]))
+	}
+	return p
+}
+
+// applyNote переносит в заметку поля сообщения API, которые клиент может менять.
+// Остальные поля (UID, время создания, вложения) остаются как есть.
+func applyNote(n *models.Note, p *gnotev1.Note) {
+	n.Title = p.GetTitle()
+	n.Content = p.GetContent()
+	n.ReminderAt = timeOf(p.GetReminderAt())
+	n.DueAt = timeOf(p.GetDueAt())
+	n.Tags = p.GetTags()
+	n.SourceURL = p.GetSourceUrl()
+	n.Favorite = p.GetFavorite()
+	n.Priority = models.Priority(p.GetPriority())
+	n.ContentTruncated = p.GetContentTruncated()
+	n.Location = nil
+	if l := p.GetLocation(); l != nil {
+		n.Location = &models.Location{Name: l.GetName(), Lat: l.GetLat(), Lon: l.GetLon()}
+	}
+}
+
+// attachmentToProto преобразует вложение в сообщение API; путь к файлу на сервере не передается
+func attachmentToProto(a *models.Attachment) *gnotev1.Attachment {
+	return &gnotev1.Attachment{
+		Id:         int64(a.ID),
+		NoteId:     int64(a.NoteID),
+		Filename:   a.Filename,
+		MimeType:   a.MimeType,
+		SizeBytes:  a.SizeBytes,
+		UploadedAt: timestamppb.New(a.UploadedAt),
+		Text:       a.Text,
+	}
+}
+
+// timestampOf возвращает nil для незаданного времени
+func timestampOf(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// timeOf возвращает nil для незаданного времени
+func timeOf(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime().Local()
+	return &t
+}
@@ -0,0 +1,1614 @@
+// Схема API GNote для программного доступа: заметки, теги, вложения и поток изменений.
+// Сервер запускается вместе с лентой изменений (gnote --serve) на адресе server.grpc_listen
+// и принимает тот же ключ, что и лента: метаданные authorization: Bearer <ключ>.
+// Код в api/gnotev1 сгенерирован из этой схемы protoc-gen-go и protoc-gen-go-grpc.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: gnote.proto
+
+package gnotev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Priority — важность заметки, как models.Priority
+type Priority int32
+
+const (
+	Priority_PRIORITY_NONE   Priority = 0
+	Priority_PRIORITY_LOW    Priority = 1
+	Priority_PRIORITY_MEDIUM Priority = 2
+	Priority_PRIORITY_HIGH   Priority = 3
+)
+
+// Enum value maps for Priority.
+var (
+	Priority_name = map[int32]string{
+		0: "PRIORITY_NONE",
+		1: "PRIORITY_LOW",
+		2: "PRIORITY_MEDIUM",
+		3: "PRIORITY_HIGH",
+	}
+	Priority_value = map[string]int32{
+		"PRIORITY_NONE":   0,
+		"PRIORITY_LOW":    1,
+		"PRIORITY_MEDIUM": 2,
+		"PRIORITY_HIGH":   3,
+	}
+)
+
+func (x Priority) Enum() *Priority {
+	p := new(Priority)
+	*p = x
+	return p
+}
+
+func (x Priority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Priority) Descriptor() protoreflect.EnumDescriptor {
+	return file_gnote_proto_enumTypes[0].Descriptor()
+}
+
+func (Priority) Type() protoreflect.EnumType {
+	return &file_gnote_proto_enumTypes[0]
+}
+
+func (x Priority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Priority.Descriptor instead.
+func (Priority) EnumDescriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{0}
+}
+
+type Change_Kind int32
+
+const (
+	Change_KIND_UNSPECIFIED   Change_Kind = 0
+	Change_NOTE_CREATED       Change_Kind = 1
+	Change_NOTE_UPDATED       Change_Kind = 2
+	Change_NOTE_DELETED       Change_Kind = 3
+	Change_ATTACHMENT_CREATED Change_Kind = 4
+	Change_ATTACHMENT_DELETED Change_Kind = 5
+)
+
+// Enum value maps for Change_Kind.
+var (
+	Change_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "NOTE_CREATED",
+		2: "NOTE_UPDATED",
+		3: "NOTE_DELETED",
+		4: "ATTACHMENT_CREATED",
+		5: "ATTACHMENT_DELETED",
+	}
+	Change_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED":   0,
+		"NOTE_CREATED":       1,
+		"NOTE_UPDATED":       2,
+		"NOTE_DELETED":       3,
+		"ATTACHMENT_CREATED": 4,
+		"ATTACHMENT_DELETED": 5,
+	}
+)
+
+func (x Change_Kind) Enum() *Change_Kind {
+	p := new(Change_Kind)
+	*p = x
+	return p
+}
+
+func (x Change_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Change_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_gnote_proto_enumTypes[1].Descriptor()
+}
+
+func (Change_Kind) Type() protoreflect.EnumType {
+	return &file_gnote_proto_enumTypes[1]
+}
+
+func (x Change_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Change_Kind.Descriptor instead.
+func (Change_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{22, 0}
+}
+
+type Location struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Lat           float64                `protobuf:"fixed64,2,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon           float64                `protobuf:"fixed64,3,opt,name=lon,proto3" json:"lon,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Location) Reset() {
+	*x = Location{}
+	mi := &file_gnote_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Location) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Location) ProtoMessage() {}
+
+func (x *Location) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Location) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Location) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Location) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+type Note struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title       string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Content     string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ReminderAt  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=reminder_at,json=reminderAt,proto3" json:"reminder_at,omitempty"` // Не задано — без напоминания
+	DueAt       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`                // Не задано — без срока
+	Tags        []string               `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty"`                               // Вложенные теги через "/": "работа/проекты"
+	SourceUrl   string                 `protobuf:"bytes,9,opt,name=source_url,json=sourceUrl,proto3" json:"source_url,omitempty"`
+	Location    *Location              `protobuf:"bytes,10,opt,name=location,proto3" json:"location,omitempty"`
+	Favorite    bool                   `protobuf:"varint,11,opt,name=favorite,proto3" json:"favorite,omitempty"`
+	Priority    Priority               `protobuf:"varint,12,opt,name=priority,proto3,enum=gnote.v1.Priority" json:"priority,omitempty"`
+	Attachments []*Attachment          `protobuf:"bytes,13,rep,name=attachments,proto3" json:"attachments,omitempty"` // Только в GetNote; в ListNotes пусто
+	// В content только начало: ListNotes отдает превью больших заметок, целиком — GetNote.
+	// Такую заметку сервер не сохранит в UpdateNote, чтобы не потерять конец
+	ContentTruncated bool `protobuf:"varint,14,opt,name=content_truncated,json=contentTruncated,proto3" json:"content_truncated,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Note) Reset() {
+	*x = Note{}
+	mi := &file_gnote_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Note) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Note) ProtoMessage() {}
+
+func (x *Note) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Note.ProtoReflect.Descriptor instead.
+func (*Note) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Note) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Note) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Note) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Note) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Note) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Note) GetReminderAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReminderAt
+	}
+	return nil
+}
+
+func (x *Note) GetDueAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueAt
+	}
+	return nil
+}
+
+func (x *Note) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Note) GetSourceUrl() string {
+	if x != nil {
+		return x.SourceUrl
+	}
+	return ""
+}
+
+func (x *Note) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *Note) GetFavorite() bool {
+	if x != nil {
+		return x.Favorite
+	}
+	return false
+}
+
+func (x *Note) GetPriority() Priority {
+	if x != nil {
+		return x.Priority
+	}
+	return Priority_PRIORITY_NONE
+}
+
+func (x *Note) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+func (x *Note) GetContentTruncated() bool {
+	if x != nil {
+		return x.ContentTruncated
+	}
+	return false
+}
+
+type Attachment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	NoteId        int64                  `protobuf:"varint,2,opt,name=note_id,json=noteId,proto3" json:"note_id,omitempty"`
+	Filename      string                 `protobuf:"bytes,3,opt,name=filename,proto3" json:"filename,omitempty"`
+	MimeType      string                 `protobuf:"bytes,4,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,5,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	UploadedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=uploaded_at,json=uploadedAt,proto3" json:"uploaded_at,omitempty"`
+	Text          string                 `protobuf:"bytes,7,opt,name=text,proto3" json:"text,omitempty"` // Текст, извлеченный из вложения (распознавание, расшифровка)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	mi := &file_gnote_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Attachment) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Attachment) GetNoteId() int64 {
+	if x != nil {
+		return x.NoteId
+	}
+	return 0
+}
+
+func (x *Attachment) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Attachment) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *Attachment) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *Attachment) GetUploadedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UploadedAt
+	}
+	return nil
+}
+
+func (x *Attachment) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type AttachmentChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NoteId        int64                  `protobuf:"varint,1,opt,name=note_id,json=noteId,proto3" json:"note_id,omitempty"` // Только в первом сообщении загрузки
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`            // Только в первом сообщении загрузки
+	MimeType      string                 `protobuf:"bytes,3,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Data          []byte                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachmentChunk) Reset() {
+	*x = AttachmentChunk{}
+	mi := &file_gnote_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachmentChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachmentChunk) ProtoMessage() {}
+
+func (x *AttachmentChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachmentChunk.ProtoReflect.Descriptor instead.
+func (*AttachmentChunk) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AttachmentChunk) GetNoteId() int64 {
+	if x != nil {
+		return x.NoteId
+	}
+	return 0
+}
+
+func (x *AttachmentChunk) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *AttachmentChunk) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *AttachmentChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type ListNotesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"` // Заметки с тегом и вложенными в него; пусто — все
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNotesRequest) Reset() {
+	*x = ListNotesRequest{}
+	mi := &file_gnote_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNotesRequest) ProtoMessage() {}
+
+func (x *ListNotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNotesRequest.ProtoReflect.Descriptor instead.
+func (*ListNotesRequest) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListNotesRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type ListNotesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Notes         []*Note                `protobuf:"bytes,1,rep,name=notes,proto3" json:"notes,omitempty"` // От новых к старым, как GetAllNotes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNotesResponse) Reset() {
+	*x = ListNotesResponse{}
+	mi := &file_gnote_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNotesResponse) ProtoMessage() {}
+
+func (x *ListNotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNotesResponse.ProtoReflect.Descriptor instead.
+func (*ListNotesResponse) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListNotesResponse) GetNotes() []*Note {
+	if x != nil {
+		return x.Notes
+	}
+	return nil
+}
+
+type GetNoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNoteRequest) Reset() {
+	*x = GetNoteRequest{}
+	mi := &file_gnote_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNoteRequest) ProtoMessage() {}
+
+func (x *GetNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNoteRequest.ProtoReflect.Descriptor instead.
+func (*GetNoteRequest) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetNoteRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CreateNoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"` // id, created_at и updated_at заполняет сервер
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateNoteRequest) Reset() {
+	*x = CreateNoteRequest{}
+	mi := &file_gnote_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateNoteRequest) ProtoMessage() {}
+
+func (x *CreateNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateNoteRequest.ProtoReflect.Descriptor instead.
+func (*CreateNoteRequest) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateNoteRequest) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+type UpdateNoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateNoteRequest) Reset() {
+	*x = UpdateNoteRequest{}
+	mi := &file_gnote_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNoteRequest) ProtoMessage() {}
+
+func (x *UpdateNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNoteRequest.ProtoReflect.Descriptor instead.
+func (*UpdateNoteRequest) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateNoteRequest) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+type DeleteNoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteNoteRequest) Reset() {
+	*x = DeleteNoteRequest{}
+	mi := &file_gnote_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNoteRequest) ProtoMessage() {}
+
+func (x *DeleteNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNoteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteNoteRequest) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteNoteRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteNoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteNoteResponse) Reset() {
+	*x = DeleteNoteResponse{}
+	mi := &file_gnote_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNoteResponse) ProtoMessage() {}
+
+func (x *DeleteNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNoteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteNoteResponse) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{10}
+}
+
+type SetFavoriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Favorite      bool                   `protobuf:"varint,2,opt,name=favorite,proto3" json:"favorite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFavoriteRequest) Reset() {
+	*x = SetFavoriteRequest{}
+	mi := &file_gnote_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFavoriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFavoriteRequest) ProtoMessage() {}
+
+func (x *SetFavoriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFavoriteRequest.ProtoReflect.Descriptor instead.
+func (*SetFavoriteRequest) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SetFavoriteRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SetFavoriteRequest) GetFavorite() bool {
+	if x != nil {
+		return x.Favorite
+	}
+	return false
+}
+
+type SearchTagsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchTagsRequest) Reset() {
+	*x = SearchTagsRequest{}
+	mi := &file_gnote_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchTagsRequest) ProtoMessage() {}
+
+func (x *SearchTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchTagsRequest.ProtoReflect.Descriptor instead.
+func (*SearchTagsRequest) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SearchTagsRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type SearchTagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []string               `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchTagsResponse) Reset() {
+	*x = SearchTagsResponse{}
+	mi := &file_gnote_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchTagsResponse) ProtoMessage() {}
+
+func (x *SearchTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchTagsResponse.ProtoReflect.Descriptor instead.
+func (*SearchTagsResponse) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SearchTagsResponse) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type RenameTagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OldName       string                 `protobuf:"bytes,1,opt,name=old_name,json=oldName,proto3" json:"old_name,omitempty"`
+	NewName       string                 `protobuf:"bytes,2,opt,name=new_name,json=newName,proto3" json:"new_name,omitempty"` // Вложенные теги переименовываются вместе с родителем
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameTagRequest) Reset() {
+	*x = RenameTagRequest{}
+	mi := &file_gnote_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameTagRequest) ProtoMessage() {}
+
+func (x *RenameTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameTagRequest.ProtoReflect.Descriptor instead.
+func (*RenameTagRequest) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RenameTagRequest) GetOldName() string {
+	if x != nil {
+		return x.OldName
+	}
+	return ""
+}
+
+func (x *RenameTagRequest) GetNewName() string {
+	if x != nil {
+		return x.NewName
+	}
+	return ""
+}
+
+type RenameTagResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NoteIds       []int64                `protobuf:"varint,1,rep,packed,name=note_ids,json=noteIds,proto3" json:"note_ids,omitempty"` // Измененные заметки
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameTagResponse) Reset() {
+	*x = RenameTagResponse{}
+	mi := &file_gnote_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameTagResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameTagResponse) ProtoMessage() {}
+
+func (x *RenameTagResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameTagResponse.ProtoReflect.Descriptor instead.
+func (*RenameTagResponse) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RenameTagResponse) GetNoteIds() []int64 {
+	if x != nil {
+		return x.NoteIds
+	}
+	return nil
+}
+
+type ListAttachmentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NoteId        int64                  `protobuf:"varint,1,opt,name=note_id,json=noteId,proto3" json:"note_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAttachmentsRequest) Reset() {
+	*x = ListAttachmentsRequest{}
+	mi := &file_gnote_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAttachmentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAttachmentsRequest) ProtoMessage() {}
+
+func (x *ListAttachmentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAttachmentsRequest.ProtoReflect.Descriptor instead.
+func (*ListAttachmentsRequest) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListAttachmentsRequest) GetNoteId() int64 {
+	if x != nil {
+		return x.NoteId
+	}
+	return 0
+}
+
+type ListAttachmentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attachments   []*Attachment          `protobuf:"bytes,1,rep,name=attachments,proto3" json:"attachments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAttachmentsResponse) Reset() {
+	*x = ListAttachmentsResponse{}
+	mi := &file_gnote_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAttachmentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAttachmentsResponse) ProtoMessage() {}
+
+func (x *ListAttachmentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAttachmentsResponse.ProtoReflect.Descriptor instead.
+func (*ListAttachmentsResponse) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListAttachmentsResponse) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+type DownloadAttachmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	NoteId        int64                  `protobuf:"varint,2,opt,name=note_id,json=noteId,proto3" json:"note_id,omitempty"` // Заметка, к которой приложено вложение
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadAttachmentRequest) Reset() {
+	*x = DownloadAttachmentRequest{}
+	mi := &file_gnote_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadAttachmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadAttachmentRequest) ProtoMessage() {}
+
+func (x *DownloadAttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadAttachmentRequest.ProtoReflect.Descriptor instead.
+func (*DownloadAttachmentRequest) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DownloadAttachmentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DownloadAttachmentRequest) GetNoteId() int64 {
+	if x != nil {
+		return x.NoteId
+	}
+	return 0
+}
+
+type DeleteAttachmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAttachmentRequest) Reset() {
+	*x = DeleteAttachmentRequest{}
+	mi := &file_gnote_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAttachmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAttachmentRequest) ProtoMessage() {}
+
+func (x *DeleteAttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAttachmentRequest.ProtoReflect.Descriptor instead.
+func (*DeleteAttachmentRequest) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeleteAttachmentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteAttachmentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAttachmentResponse) Reset() {
+	*x = DeleteAttachmentResponse{}
+	mi := &file_gnote_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAttachmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAttachmentResponse) ProtoMessage() {}
+
+func (x *DeleteAttachmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAttachmentResponse.ProtoReflect.Descriptor instead.
+func (*DeleteAttachmentResponse) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{20}
+}
+
+type WatchChangesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchChangesRequest) Reset() {
+	*x = WatchChangesRequest{}
+	mi := &file_gnote_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchChangesRequest) ProtoMessage() {}
+
+func (x *WatchChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchChangesRequest.ProtoReflect.Descriptor instead.
+func (*WatchChangesRequest) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{21}
+}
+
+// Change — изменение данных, как events.Event
+type Change struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          Change_Kind            `protobuf:"varint,1,opt,name=kind,proto3,enum=gnote.v1.Change_Kind" json:"kind,omitempty"`
+	NoteId        int64                  `protobuf:"varint,2,opt,name=note_id,json=noteId,proto3" json:"note_id,omitempty"`
+	Note          *Note                  `protobuf:"bytes,3,opt,name=note,proto3" json:"note,omitempty"` // Новое состояние для создания и изменения заметки
+	AttachmentId  int64                  `protobuf:"varint,4,opt,name=attachment_id,json=attachmentId,proto3" json:"attachment_id,omitempty"`
+	Attachment    *Attachment            `protobuf:"bytes,5,opt,name=attachment,proto3" json:"attachment,omitempty"` // Для добавления вложения
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Change) Reset() {
+	*x = Change{}
+	mi := &file_gnote_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Change) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Change) ProtoMessage() {}
+
+func (x *Change) ProtoReflect() protoreflect.Message {
+	mi := &file_gnote_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Change.ProtoReflect.Descriptor instead.
+func (*Change) Descriptor() ([]byte, []int) {
+	return file_gnote_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Change) GetKind() Change_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return Change_KIND_UNSPECIFIED
+}
+
+func (x *Change) GetNoteId() int64 {
+	if x != nil {
+		return x.NoteId
+	}
+	return 0
+}
+
+func (x *Change) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+func (x *Change) GetAttachmentId() int64 {
+	if x != nil {
+		return x.AttachmentId
+	}
+	return 0
+}
+
+func (x *Change) GetAttachment() *Attachment {
+	if x != nil {
+		return x.Attachment
+	}
+	return nil
+}
+
+var File_gnote_proto protoreflect.FileDescriptor
+
+const file_gnote_proto_rawDesc = "" +
+	"\n" +
+	"\vgnote.proto\x12\bgnote.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"B\n" +
+	"\bLocation\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03lat\x18\x02 \x01(\x01R\x03lat\x12\x10\n" +
+	"\x03lon\x18\x03 \x01(\x01R\x03lon\"\xc0\x04\n" +
+	"\x04Note\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12;\n" +
+	"\vreminder_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"reminderAt\x121\n" +
+	"\x06due_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x05dueAt\x12\x12\n" +
+	"\x04tags\x18\b \x03(\tR\x04tags\x12\x1d\n" +
+	"\n" +
+	"source_url\x18\t \x01(\tR\tsourceUrl\x12.\n" +
+	"\blocation\x18\n" +
+	" \x01(\v2\x12.gnote.v1.LocationR\blocation\x12\x1a\n" +
+	"\bfavorite\x18\v \x01(\bR\bfavorite\x12.\n" +
+	"\bpriority\x18\f \x01(\x0e2\x12.gnote.v1.PriorityR\bpriority\x126\n" +
+	"\vattachments\x18\r \x03(\v2\x14.gnote.v1.AttachmentR\vattachments\x12+\n" +
+	"\x11content_truncated\x18\x0e \x01(\bR\x10contentTruncated\"\xde\x01\n" +
+	"\n" +
+	"Attachment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\anote_id\x18\x02 \x01(\x03R\x06noteId\x12\x1a\n" +
+	"\bfilename\x18\x03 \x01(\tR\bfilename\x12\x1b\n" +
+	"\tmime_type\x18\x04 \x01(\tR\bmimeType\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x05 \x01(\x03R\tsizeBytes\x12;\n" +
+	"\vuploaded_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"uploadedAt\x12\x12\n" +
+	"\x04text\x18\a \x01(\tR\x04text\"w\n" +
+	"\x0fAttachmentChunk\x12\x17\n" +
+	"\anote_id\x18\x01 \x01(\x03R\x06noteId\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12\x1b\n" +
+	"\tmime_type\x18\x03 \x01(\tR\bmimeType\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\fR\x04data\"$\n" +
+	"\x10ListNotesRequest\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\"9\n" +
+	"\x11ListNotesResponse\x12$\n" +
+	"\x05notes\x18\x01 \x03(\v2\x0e.gnote.v1.NoteR\x05notes\" \n" +
+	"\x0eGetNoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"7\n" +
+	"\x11CreateNoteRequest\x12\"\n" +
+	"\x04note\x18\x01 \x01(\v2\x0e.gnote.v1.NoteR\x04note\"7\n" +
+	"\x11UpdateNoteRequest\x12\"\n" +
+	"\x04note\x18\x01 \x01(\v2\x0e.gnote.v1.NoteR\x04note\"#\n" +
+	"\x11DeleteNoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x14\n" +
+	"\x12DeleteNoteResponse\"@\n" +
+	"\x12SetFavoriteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\bfavorite\x18\x02 \x01(\bR\bfavorite\"+\n" +
+	"\x11SearchTagsRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\"(\n" +
+	"\x12SearchTagsResponse\x12\x12\n" +
+	"\x04tags\x18\x01 \x03(\tR\x04tags\"H\n" +
+	"\x10RenameTagRequest\x12\x19\n" +
+	"\bold_name\x18\x01 \x01(\tR\aoldName\x12\x19\n" +
+	"\bnew_name\x18\x02 \x01(\tR\anewName\".\n" +
+	"\x11RenameTagResponse\x12\x19\n" +
+	"\bnote_ids\x18\x01 \x03(\x03R\anoteIds\"1\n" +
+	"\x16ListAttachmentsRequest\x12\x17\n" +
+	"\anote_id\x18\x01 \x01(\x03R\x06noteId\"Q\n" +
+	"\x17ListAttachmentsResponse\x126\n" +
+	"\vattachments\x18\x01 \x03(\v2\x14.gnote.v1.AttachmentR\vattachments\"D\n" +
+	"\x19DownloadAttachmentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\anote_id\x18\x02 \x01(\x03R\x06noteId\")\n" +
+	"\x17DeleteAttachmentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x1a\n" +
+	"\x18DeleteAttachmentResponse\"\x15\n" +
+	"\x13WatchChangesRequest\"\xd0\x02\n" +
+	"\x06Change\x12)\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x15.gnote.v1.Change.KindR\x04kind\x12\x17\n" +
+	"\anote_id\x18\x02 \x01(\x03R\x06noteId\x12\"\n" +
+	"\x04note\x18\x03 \x01(\v2\x0e.gnote.v1.NoteR\x04note\x12#\n" +
+	"\rattachment_id\x18\x04 \x01(\x03R\fattachmentId\x124\n" +
+	"\n" +
+	"attachment\x18\x05 \x01(\v2\x14.gnote.v1.AttachmentR\n" +
+	"attachment\"\x82\x01\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fNOTE_CREATED\x10\x01\x12\x10\n" +
+	"\fNOTE_UPDATED\x10\x02\x12\x10\n" +
+	"\fNOTE_DELETED\x10\x03\x12\x16\n" +
+	"\x12ATTACHMENT_CREATED\x10\x04\x12\x16\n" +
+	"\x12ATTACHMENT_DELETED\x10\x05*W\n" +
+	"\bPriority\x12\x11\n" +
+	"\rPRIORITY_NONE\x10\x00\x12\x10\n" +
+	"\fPRIORITY_LOW\x10\x01\x12\x13\n" +
+	"\x0fPRIORITY_MEDIUM\x10\x02\x12\x11\n" +
+	"\rPRIORITY_HIGH\x10\x032\xa2\a\n" +
+	"\x05Notes\x12D\n" +
+	"\tListNotes\x12\x1a.gnote.v1.ListNotesRequest\x1a\x1b.gnote.v1.ListNotesResponse\x123\n" +
+	"\aGetNote\x12\x18.gnote.v1.GetNoteRequest\x1a\x0e.gnote.v1.Note\x129\n" +
+	"\n" +
+	"CreateNote\x12\x1b.gnote.v1.CreateNoteRequest\x1a\x0e.gnote.v1.Note\x129\n" +
+	"\n" +
+	"UpdateNote\x12\x1b.gnote.v1.UpdateNoteRequest\x1a\x0e.gnote.v1.Note\x12G\n" +
+	"\n" +
+	"DeleteNote\x12\x1b.gnote.v1.DeleteNoteRequest\x1a\x1c.gnote.v1.DeleteNoteResponse\x12;\n" +
+	"\vSetFavorite\x12\x1c.gnote.v1.SetFavoriteRequest\x1a\x0e.gnote.v1.Note\x12G\n" +
+	"\n" +
+	"SearchTags\x12\x1b.gnote.v1.SearchTagsRequest\x1a\x1c.gnote.v1.SearchTagsResponse\x12D\n" +
+	"\tRenameTag\x12\x1a.gnote.v1.RenameTagRequest\x1a\x1b.gnote.v1.RenameTagResponse\x12V\n" +
+	"\x0fListAttachments\x12 .gnote.v1.ListAttachmentsRequest\x1a!.gnote.v1.ListAttachmentsResponse\x12V\n" +
+	"\x12DownloadAttachment\x12#.gnote.v1.DownloadAttachmentRequest\x1a\x19.gnote.v1.AttachmentChunk0\x01\x12E\n" +
+	"\x10UploadAttachment\x12\x19.gnote.v1.AttachmentChunk\x1a\x14.gnote.v1.Attachment(\x01\x12Y\n" +
+	"\x10DeleteAttachment\x12!.gnote.v1.DeleteAttachmentRequest\x1a\".gnote.v1.DeleteAttachmentResponse\x12A\n" +
+	"\fWatchChanges\x12\x1d.gnote.v1.WatchChangesRequest\x1a\x10.gnote.v1.Change0\x01B\x13Z\x11GNote/api/gnotev1b\x06proto3"
+
+var (
+	file_gnote_proto_rawDescOnce sync.Once
+	file_gnote_proto_rawDescData []byte
+)
+
+func file_gnote_proto_rawDescGZIP() []byte {
+	file_gnote_proto_rawDescOnce.Do(func() {
+		file_gnote_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_gnote_proto_rawDesc), len(file_gnote_proto_rawDesc)))
+	})
+	return file_gnote_proto_rawDescData
+}
+
+var file_gnote_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_gnote_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_gnote_proto_goTypes = []any{
+	(Priority)(0),                     // 0: gnote.v1.Priority
+	(Change_Kind)(0),                  // 1: gnote.v1.Change.Kind
+	(*Location)(nil),                  // 2: gnote.v1.Location
+	(*Note)(nil),                      // 3: gnote.v1.Note
+	(*Attachment)(nil),                // 4: gnote.v1.Attachment
+	(*AttachmentChunk)(nil),           // 5: gnote.v1.AttachmentChunk
+	(*ListNotesRequest)(nil),          // 6: gnote.v1.ListNotesRequest
+	(*ListNotesResponse)(nil),         // 7: gnote.v1.ListNotesResponse
+	(*GetNoteRequest)(nil),            // 8: gnote.v1.GetNoteRequest
+	(*CreateNoteRequest)(nil),         // 9: gnote.v1.CreateNoteRequest
+	(*UpdateNoteRequest)(nil),         // 10: gnote.v1.UpdateNoteRequest
+	(*DeleteNoteRequest)(nil),         // 11: gnote.v1.DeleteNoteRequest
+	(*DeleteNoteResponse)(nil),        // 12: gnote.v1.DeleteNoteResponse
+	(*SetFavoriteRequest)(nil),        // 13: gnote.v1.SetFavoriteRequest
+	(*SearchTagsRequest)(nil),         // 14: gnote.v1.SearchTagsRequest
+	(*SearchTagsResponse)(nil),        // 15: gnote.v1.SearchTagsResponse
+	(*RenameTagRequest)(nil),          // 16: gnote.v1.RenameTagRequest
+	(*RenameTagResponse)(nil),         // 17: gnote.v1.RenameTagResponse
+	(*ListAttachmentsRequest)(nil),    // 18: gnote.v1.ListAttachmentsRequest
+	(*ListAttachmentsResponse)(nil),   // 19: gnote.v1.ListAttachmentsResponse
+	(*DownloadAttachmentRequest)(nil), // 20: gnote.v1.DownloadAttachmentRequest
+	(*DeleteAttachmentRequest)(nil),   // 21: gnote.v1.DeleteAttachmentRequest
+	(*DeleteAttachmentResponse)(nil),  // 22: gnote.v1.DeleteAttachmentResponse
+	(*WatchChangesRequest)(nil),       // 23: gnote.v1.WatchChangesRequest
+	(*Change)(nil),                    // 24: gnote.v1.Change
+	(*timestamppb.Timestamp)(nil),     // 25: google.protobuf.Timestamp
+}
+var file_gnote_proto_depIdxs = []int32{
+	25, // 0: gnote.v1.Note.created_at:type_name -> google.protobuf.Timestamp
+	25, // 1: gnote.v1.Note.updated_at:type_name -> google.protobuf.Timestamp
+	25, // 2: gnote.v1.Note.reminder_at:type_name -> google.protobuf.Timestamp
+	25, // 3: gnote.v1.Note.due_at:type_name -> google.protobuf.Timestamp
+	2,  // 4: gnote.v1.Note.location:type_name -> gnote.v1.Location
+	0,  // 5: gnote.v1.Note.priority:type_name -> gnote.v1.Priority
+	4,  // 6: gnote.v1.Note.attachments:type_name -> gnote.v1.Attachment
+	25, // 7: gnote.v1.Attachment.uploaded_at:type_name -> google.protobuf.Timestamp
+	3,  // 8: gnote.v1.ListNotesResponse.notes:type_name -> gnote.v1.Note
+	3,  // 9: gnote.v1.CreateNoteRequest.note:type_name -> gnote.v1.Note
+	3,  // 10: gnote.v1.UpdateNoteRequest.note:type_name -> gnote.v1.Note
+	4,  // 11: gnote.v1.ListAttachmentsResponse.attachments:type_name -> gnote.v1.Attachment
+	1,  // 12: gnote.v1.Change.kind:type_name -> gnote.v1.Change.Kind
+	3,  // 13: gnote.v1.Change.note:type_name -> gnote.v1.Note
+	4,  // 14: gnote.v1.Change.attachment:type_name -> gnote.v1.Attachment
+	6,  // 15: gnote.v1.Notes.ListNotes:input_type -> gnote.v1.ListNotesRequest
+	8,  // 16: gnote.v1.Notes.GetNote:input_type -> gnote.v1.GetNoteRequest
+	9,  // 17: gnote.v1.Notes.CreateNote:input_type -> gnote.v1.CreateNoteRequest
+	10, // 18: gnote.v1.Notes.UpdateNote:input_type -> gnote.v1.UpdateNoteRequest
+	11, // 19: gnote.v1.Notes.DeleteNote:input_type -> gnote.v1.DeleteNoteRequest
+	13, // 20: gnote.v1.Notes.SetFavorite:input_type -> gnote.v1.SetFavoriteRequest
+	14, // 21: gnote.v1.Notes.SearchTags:input_type -> gnote.v1.SearchTagsRequest
+	16, // 22: gnote.v1.Notes.RenameTag:input_type -> gnote.v1.RenameTagRequest
+	18, // 23: gnote.v1.Notes.ListAttachments:input_type -> gnote.v1.ListAttachmentsRequest
+	20, // 24: gnote.v1.Notes.DownloadAttachment:input_type -> gnote.v1.DownloadAttachmentRequest
+	5,  // 25: gnote.v1.Notes.UploadAttachment:input_type -> gnote.v1.AttachmentChunk
+	21, // 26: gnote.v1.Notes.DeleteAttachment:input_type -> gnote.v1.DeleteAttachmentRequest
+	23, // 27: gnote.v1.Notes.WatchChanges:input_type -> gnote.v1.WatchChangesRequest
+	7,  // 28: gnote.v1.Notes.ListNotes:output_type -> gnote.v1.ListNotesResponse
+	3,  // 29: gnote.v1.Notes.GetNote:output_type -> gnote.v1.Note
+	3,  // 30: gnote.v1.Notes.CreateNote:output_type -> gnote.v1.Note
+	3,  // 31: gnote.v1.Notes.UpdateNote:output_type -> gnote.v1.Note
+	12, // 32: gnote.v1.Notes.DeleteNote:output_type -> gnote.v1.DeleteNoteResponse
+	3,  // 33: gnote.v1.Notes.SetFavorite:output_type -> gnote.v1.Note
+	15, // 34: gnote.v1.Notes.SearchTags:output_type -> gnote.v1.SearchTagsResponse
+	17, // 35: gnote.v1.Notes.RenameTag:output_type -> gnote.v1.RenameTagResponse
+	19, // 36: gnote.v1.Notes.ListAttachments:output_type -> gnote.v1.ListAttachmentsResponse
+	5,  // 37: gnote.v1.Notes.DownloadAttachment:output_type -> gnote.v1.AttachmentChunk
+	4,  // 38: gnote.v1.Notes.UploadAttachment:output_type -> gnote.v1.Attachment
+	22, // 39: gnote.v1.Notes.DeleteAttachment:output_type -> gnote.v1.DeleteAttachmentResponse
+	24, // 40: gnote.v1.Notes.WatchChanges:output_type -> gnote.v1.Change
+	28, // [28:41] is the sub-list for method output_type
+	15, // [15:28] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_gnote_proto_init() }
+func file_gnote_proto_init() {
+	if File_gnote_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gnote_proto_rawDesc), len(file_gnote_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   23,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gnote_proto_goTypes,
+		DependencyIndexes: file_gnote_proto_depIdxs,
+		EnumInfos:         file_gnote_proto_enumTypes,
+		MessageInfos:      file_gnote_proto_msgTypes,
+	}.Build()
+	File_gnote_proto = out.File
+	file_gnote_proto_goTypes = nil
+	file_gnote_proto_depIdxs = nil
+}
@@ -0,0 +1,361 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"GNote/api/gnotev1"
+	"GNote/events"
+	"GNote/models"
+	"GNote/policy"
+	"GNote/storage"
+)
+
+// chunkSize — сколько байт содержимого вложения в одном сообщении DownloadAttachment
+const chunkSize = 64 << 10
+
+// Changes — источник изменений для WatchChanges; его реализует feed.Hub
+type Changes interface {
+	Watch() (changes <-chan events.Event, stop func())
+}
+
+// Server реализует API gRPC из api/gnote.proto поверх хранилища заметок
+type Server struct {
+	gnotev1.UnimplementedNotesServer
+
+	store          storage.Store
+	changes        Changes
+	attachmentsDir string // Каталог для загруженных вложений, если они хранятся не в БД
+	inDatabase     bool   // Загруженные вложения сохраняются в БД
+}
+
+// NewServer создает API над store. Изменения данных должны публиковаться в changes
+// (обычно store — storage.PublishingStore, чья шина передает события ленте изменений),
+// иначе подписчики WatchChanges и окна GNote не узнают об изменениях через API.
+func NewServer(store storage.Store, changes Changes, attachmentsDir string, inDatabase bool) *Server {
+	return &Server{store: store, changes: changes, attachmentsDir: attachmentsDir, inDatabase: inDatabase}
+}
+
+// NewGRPCServer создает сервер gRPC с API notes. Вызовы без ключа token в метаданных
+// authorization: Bearer отклоняются (пустой — доступ открыт), как подключения к ленте изменений.
+func NewGRPCServer(notes gnotev1.NotesServer, token string) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := authorize(ctx, token); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := authorize(ss.Context(), token); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	gnotev1.RegisterNotesServer(server, notes)
+	return server
+}
+
+// authorize проверяет ключ доступа в метаданных вызова
+func authorize(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		got, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "неверный ключ доступа")
+}
+
+// statusError переводит ошибку хранилища в статус gRPC
+func statusError(err error) error {
+	var validation *storage.ValidationError
+	switch {
+	case errors.Is(err, storage.ErrNoteNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &validation), errors.Is(err, storage.ErrContentTruncated):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, policy.ErrRejected):
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// ListNotes возвращает заметки с тегом и вложенными в него; большие заметки — с превью содержимого
+func (s *Server) ListNotes(_ context.Context, req *gnotev1.ListNotesRequest) (*gnotev1.ListNotesResponse, error) {
+	notes, err := s.store.GetAllNotes()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	resp := &gnotev1.ListNotesResponse{}
+	for i := range notes {
+		note := &notes[i]
+		if req.GetTag() != "" && !hasTag(note, req.GetTag()) {
+			continue
+		}
+		note.Attachments = nil
+		resp.Notes = append(resp.Notes, noteToProto(note))
+	}
+	return resp, nil
+}
+
+// hasTag сообщает, что у заметки есть тег tag или вложенный в него
+func hasTag(note *models.Note, tag string) bool {
+	for _, t := range note.Tags {
+		if models.TagHasPrefix(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetNote возвращает заметку целиком, с вложениями
+func (s *Server) GetNote(_ context.Context, req *gnotev1.GetNoteRequest) (*gnotev1.Note, error) {
+	note, err := s.store.GetNoteByID(int(req.GetId()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return noteToProto(note), nil
+}
+
+// CreateNote создает заметку; ID и время создания заполняет хранилище
+func (s *Server) CreateNote(_ context.Context, req *gnotev1.CreateNoteRequest) (*gnotev1.Note, error) {
+	var note models.Note
+	applyNote(&note, req.GetNote())
+	if err := s.store.CreateNote(&note); err != nil {
+		return nil, statusError(err)
+	}
+	return noteToProto(&note), nil
+}
+
+// UpdateNote сохраняет изменения заметки. Поля, которых нет в API, берутся из БД.
+func (s *Server) UpdateNote(_ context.Context, req *gnotev1.UpdateNoteRequest) (*gnotev1.Note, error) {
+	note, err := s.store.GetNoteByID(int(req.GetNote().GetId()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	applyNote(note, req.GetNote())
+	if err := s.store.UpdateNote(note); err != nil {
+		return nil, statusError(err)
+	}
+	return noteToProto(note), nil
+}
+
+// DeleteNote удаляет заметку
+func (s *Server) DeleteNote(_ context.Context, req *gnotev1.DeleteNoteRequest) (*gnotev1.DeleteNoteResponse, error) {
+	if err := s.store.DeleteNote(int(req.GetId())); err != nil {
+		return nil, statusError(err)
+	}
+	return &gnotev1.DeleteNoteResponse{}, nil
+}
+
+// SetFavorite меняет отметку избранного и возвращает заметку
+func (s *Server) SetFavorite(ctx context.Context, req *gnotev1.SetFavoriteRequest) (*gnotev1.Note, error) {
+	if err := s.store.SetFavorite(int(req.GetId()), req.GetFavorite()); err != nil {
+		return nil, statusError(err)
+	}
+	return s.GetNote(ctx, &gnotev1.GetNoteRequest{Id: req.GetId()})
+}
+
+// SearchTags возвращает теги, начинающиеся с prefix
+func (s *Server) SearchTags(_ context.Context, req *gnotev1.SearchTagsRequest) (*gnotev1.SearchTagsResponse, error) {
+	tags, err := s.store.SearchTags(req.GetPrefix())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &gnotev1.SearchTagsResponse{Tags: tags}, nil
+}
+
+// RenameTag переименовывает тег вместе с вложенными
+func (s *Server) RenameTag(_ context.Context, req *gnotev1.RenameTagRequest) (*gnotev1.RenameTagResponse, error) {
+	noteIDs, err := s.store.RenameTag(req.GetOldName(), req.GetNewName())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	resp := &gnotev1.RenameTagResponse{}
+	for _, id := range noteIDs {
+		resp.NoteIds = append(resp.NoteIds, int64(id))
+	}
+	return resp, nil
+}
+
+// ListAttachments возвращает вложения заметки
+func (s *Server) ListAttachments(_ context.Context, req *gnotev1.ListAttachmentsRequest) (*gnotev1.ListAttachmentsResponse, error) {
+	attachments, err := s.store.GetAttachmentsByNoteID(int(req.GetNoteId()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	resp := &gnotev1.ListAttachmentsResponse{}
+	for i := range attachments {
+		resp.Attachments = append(resp.Attachments, attachmentToProto(&attachments[i]))
+	}
+	return resp, nil
+}
+
+// DownloadAttachment передает содержимое вложения частями по chunkSize
+func (s *Server) DownloadAttachment(req *gnotev1.DownloadAttachmentRequest, stream grpc.ServerStreamingServer[gnotev1.AttachmentChunk]) error {
+	attachments, err := s.store.GetAttachmentsByNoteID(int(req.GetNoteId()))
+	if err != nil {
+		return statusError(err)
+	}
+	var attachment *models.Attachment
+	for i := range attachments {
+		if attachments[i].ID == int(req.GetId()) {
+			attachment = &attachments[i]
+		}
+	}
+	if attachment == nil {
+		return status.Errorf(codes.NotFound, "у заметки ID %d нет вложения ID %d", req.GetNoteId(), req.GetId())
+	}
+
+	var src io.ReadCloser
+	if attachment.InDatabase {
+		src, err = s.store.OpenAttachmentBlob(attachment.ID)
+	} else {
+		// Файл доступен, только если сервер видит каталог вложений экземпляра, который его сохранил
+		src, err = os.Open(attachment.Filepath)
+	}
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "не удалось открыть вложение: %v", err)
+	}
+	defer src.Close()
+
+	buf := make([]byte, chunkSize)
+	first := true
+	for {
+		n, err := src.Read(buf)
+		if n > 0 || first {
+			chunk := &gnotev1.AttachmentChunk{Data: buf[:n]}
+			if first {
+				chunk.NoteId, chunk.Filename, chunk.MimeType = int64(attachment.NoteID), attachment.Filename, attachment.MimeType
+				first = false
+			}
+			if sendErr := stream.Send(chunk); sendErr != nil {
+				return sendErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "ошибка при чтении вложения: %v", err)
+		}
+	}
+}
+
+// UploadAttachment сохраняет вложение: первое сообщение задает заметку, имя и тип,
+// содержимое приходит во всех сообщениях по порядку. Без типа он определяется по содержимому.
+func (s *Server) UploadAttachment(stream grpc.ClientStreamingServer[gnotev1.AttachmentChunk, gnotev1.Attachment]) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "нет данных вложения")
+	}
+	if err != nil {
+		return err
+	}
+	if first.GetNoteId() == 0 || first.GetFilename() == "" {
+		return status.Error(codes.InvalidArgument, "в первом сообщении нужны note_id и filename")
+	}
+	attachment := &models.Attachment{NoteID: int(first.GetNoteId()), Filename: first.GetFilename(), MimeType: first.GetMimeType()}
+
+	content := bufio.NewReaderSize(&chunkReader{stream: stream, buf: first.GetData()}, storage.SniffLen)
+	if attachment.MimeType == "" {
+		head, _ := content.Peek(storage.SniffLen) // Файл короче — тип определяется по тому, что есть
+		attachment.MimeType = storage.DetectMimeType(attachment.Filename, head)
+	}
+	if err := storage.SaveAttachmentStream(s.store, s.attachmentsDir, s.inDatabase, attachment, content); err != nil {
+		return statusError(err)
+	}
+	return stream.SendAndClose(attachmentToProto(attachment))
+}
+
+// chunkReader читает содержимое вложения из сообщений потока загрузки
+type chunkReader struct {
+	stream grpc.ClientStreamingServer[gnotev1.AttachmentChunk, gnotev1.Attachment]
+	buf    []byte
+}
+
+// Read отдает данные текущего сообщения и принимает следующее, когда они закончились
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			return 0, err // io.EOF — клиент закончил загрузку
+		}
+		r.buf = chunk.GetData()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// DeleteAttachment удаляет вложение
+func (s *Server) DeleteAttachment(_ context.Context, req *gnotev1.DeleteAttachmentRequest) (*gnotev1.DeleteAttachmentResponse, error) {
+	if err := s.store.DeleteAttachment(int(req.GetId())); err != nil {
+		return nil, statusError(err)
+	}
+	return &gnotev1.DeleteAttachmentResponse{}, nil
+}
+
+// WatchChanges передает изменения, пока клиент держит соединение. Для создания и изменения
+// заметки передается ее новое состояние; если ее уже удалили, событие пропускается.
+func (s *Server) WatchChanges(_ *gnotev1.WatchChangesRequest, stream grpc.ServerStreamingServer[gnotev1.Change]) error {
+	changes, stop := s.changes.Watch()
+	defer stop()
+	// Заголовки отправляются сразу: получив их, клиент знает, что следующие изменения не пропадут
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e := <-changes:
+			change, ok := s.change(e)
+			if !ok {
+				continue
+			}
+			if err := stream.Send(change); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// change возвращает изменение API для события; false — событие передавать не нужно
+func (s *Server) change(e events.Event) (*gnotev1.Change, bool) {
+	change := &gnotev1.Change{Kind: changeKinds[e.Kind], NoteId: int64(e.NoteID), AttachmentId: int64(e.AttachmentID)}
+	switch e.Kind {
+	case events.NoteCreated, events.NoteUpdated:
+		note, err := s.store.GetNoteByID(e.NoteID)
+		if errors.Is(err, storage.ErrNoteNotFound) {
+			return nil, false // Заметку уже удалили, событие об этом придет следом
+		}
+		if err != nil {
+			log.Printf("API: ошибка при загрузке измененной заметки ID %d: %v", e.NoteID, err)
+			break
+		}
+		change.Note = noteToProto(note)
+	case events.AttachmentCreated:
+		if e.Attachment != nil {
+			change.Attachment = attachmentToProto(e.Attachment)
+		}
+	}
+	return change, true
+}
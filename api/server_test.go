@@ -0,0 +1,309 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"GNote/api/gnotev1"
+	"GNote/events"
+	"GNote/feed"
+	"GNote/models"
+	"GNote/storage"
+)
+
+// memStore — хранилище в памяти с методами, которые нужны API
+type memStore struct {
+	storage.Store
+
+	mu          sync.Mutex
+	notes       map[int]models.Note
+	attachments map[int]models.Attachment
+	blobs       map[int][]byte
+	nextID      int
+}
+
+func newMemStore() *memStore {
+	return &memStore{notes: make(map[int]models.Note), attachments: make(map[int]models.Attachment), blobs: make(map[int][]byte)}
+}
+
+func (s *memStore) CreateNote(note *models.Note) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	note.ID, note.UID = s.nextID, fmt.Sprintf("uid-%d", s.nextID)
+	note.CreatedAt, note.UpdatedAt = time.Now(), time.Now()
+	s.notes[note.ID] = *note
+	return nil
+}
+
+func (s *memStore) GetNoteByID(id int) (*models.Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	note, ok := s.notes[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: ID %d", storage.ErrNoteNotFound, id)
+	}
+	return &note, nil
+}
+
+func (s *memStore) GetAllNotes() ([]models.Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var notes []models.Note
+	for _, note := range s.notes {
+		notes = append(notes, note)
+	}
+	return notes, nil
+}
+
+func (s *memStore) UpdateNote(note *models.Note) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if note.ContentTruncated {
+		return storage.ErrContentTruncated
+	}
+	note.UpdatedAt = time.Now()
+	s.notes[note.ID] = *note
+	return nil
+}
+
+func (s *memStore) GetAttachmentsByNoteID(noteID int) ([]models.Attachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var attachments []models.Attachment
+	for _, a := range s.attachments {
+		if a.NoteID == noteID {
+			attachments = append(attachments, a)
+		}
+	}
+	return attachments, nil
+}
+
+func (s *memStore) CreateAttachmentBlob(attachment *models.Attachment, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	attachment.ID, attachment.SizeBytes, attachment.InDatabase = s.nextID, int64(len(data)), true
+	s.attachments[attachment.ID] = *attachment
+	s.blobs[attachment.ID] = data
+	return nil
+}
+
+func (s *memStore) OpenAttachmentBlob(attachmentID int) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return io.NopCloser(bytes.NewReader(s.blobs[attachmentID])), nil
+}
+
+// startServer запускает API с ключом "secret" над store и возвращает клиента
+func startServer(t *testing.T, store storage.Store) gnotev1.NotesClient {
+	t.Helper()
+	hub := feed.NewHub("secret", nil)
+	bus := events.NewBus()
+	bus.Subscribe(hub.Publish)
+	server := NewGRPCServer(NewServer(storage.NewPublishingStore(store, bus), hub, t.TempDir(), true), "secret")
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///gnote",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return gnotev1.NewNotesClient(conn)
+}
+
+// withToken добавляет ключ доступа в метаданные вызова
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func TestAuthorization(t *testing.T) {
+	client := startServer(t, newMemStore())
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{"без ключа", context.Background(), codes.Unauthenticated},
+		{"неверный ключ", withToken(context.Background(), "other"), codes.Unauthenticated},
+		{"верный ключ", withToken(context.Background(), "secret"), codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ListNotes(tt.ctx, &gnotev1.ListNotesRequest{})
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("ListNotes() = %v, ожидалось %v", got, tt.want)
+			}
+
+			// Потоковые вызовы проверяются так же; принятая подписка ждет изменений до конца срока
+			ctx, cancel := context.WithTimeout(tt.ctx, 200*time.Millisecond)
+			defer cancel()
+			stream, err := client.WatchChanges(ctx, &gnotev1.WatchChangesRequest{})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			want := tt.want
+			if want == codes.OK {
+				want = codes.DeadlineExceeded
+			}
+			if got := status.Code(err); got != want {
+				t.Errorf("WatchChanges() = %v, ожидалось %v", got, want)
+			}
+		})
+	}
+}
+
+func TestNotesRoundTrip(t *testing.T) {
+	store := newMemStore()
+	client := startServer(t, store)
+	ctx, cancel := context.WithTimeout(withToken(context.Background(), "secret"), 5*time.Second)
+	defer cancel()
+
+	changes, err := client.WatchChanges(ctx, &gnotev1.WatchChangesRequest{})
+	if err != nil {
+		t.Fatalf("WatchChanges() = %v", err)
+	}
+	if _, err := changes.Header(); err != nil {
+		t.Fatalf("WatchChanges: заголовки: %v", err)
+	}
+
+	created, err := client.CreateNote(ctx, &gnotev1.CreateNoteRequest{Note: &gnotev1.Note{
+		Title:    "План",
+		Content:  "Содержимое",
+		Tags:     []string{"работа/проекты"},
+		Priority: gnotev1.Priority_PRIORITY_HIGH,
+	}})
+	if err != nil {
+		t.Fatalf("CreateNote() = %v", err)
+	}
+	change, err := changes.Recv()
+	if err != nil {
+		t.Fatalf("WatchChanges: %v", err)
+	}
+	if change.GetKind() != gnotev1.Change_NOTE_CREATED || change.GetNoteId() != created.GetId() || change.GetNote().GetTitle() != "План" {
+		t.Errorf("WatchChanges: получено %v, ожидалось создание заметки %d", change, created.GetId())
+	}
+
+	for tag, want := range map[string]int{"": 1, "работа": 1, "Работа/Проекты": 1, "дом": 0} {
+		list, err := client.ListNotes(ctx, &gnotev1.ListNotesRequest{Tag: tag})
+		if err != nil {
+			t.Fatalf("ListNotes(%q) = %v", tag, err)
+		}
+		if len(list.GetNotes()) != want {
+			t.Errorf("ListNotes(%q): %d заметок, ожидалось %d", tag, len(list.GetNotes()), want)
+		}
+	}
+
+	// Поля, которых нет в API, сохраняются
+	updated := created
+	updated.Title = "План на неделю"
+	if _, err := client.UpdateNote(ctx, &gnotev1.UpdateNoteRequest{Note: updated}); err != nil {
+		t.Fatalf("UpdateNote() = %v", err)
+	}
+	if note := store.notes[int(created.GetId())]; note.Title != "План на неделю" || note.UID != "uid-1" || note.Priority != models.PriorityHigh {
+		t.Errorf("после UpdateNote в хранилище %+v", note)
+	}
+	if change, err := changes.Recv(); err != nil || change.GetKind() != gnotev1.Change_NOTE_UPDATED {
+		t.Errorf("WatchChanges после UpdateNote: %v, %v", change, err)
+	}
+
+	// Превью большой заметки не сохраняется поверх полного содержимого
+	updated.ContentTruncated = true
+	if _, err := client.UpdateNote(ctx, &gnotev1.UpdateNoteRequest{Note: updated}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("UpdateNote(превью) = %v, ожидалось %v", err, codes.InvalidArgument)
+	}
+
+	if _, err := client.GetNote(ctx, &gnotev1.GetNoteRequest{Id: 100}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetNote(100) = %v, ожидалось %v", err, codes.NotFound)
+	}
+}
+
+func TestAttachmentRoundTrip(t *testing.T) {
+	store := newMemStore()
+	client := startServer(t, store)
+	ctx, cancel := context.WithTimeout(withToken(context.Background(), "secret"), 5*time.Second)
+	defer cancel()
+
+	note, err := client.CreateNote(ctx, &gnotev1.CreateNoteRequest{Note: &gnotev1.Note{Title: "С вложением"}})
+	if err != nil {
+		t.Fatalf("CreateNote() = %v", err)
+	}
+	content := bytes.Repeat([]byte("%PDF-1.4 строка вложения\n"), 5000) // Больше одного сообщения DownloadAttachment
+
+	upload, err := client.UploadAttachment(ctx)
+	if err != nil {
+		t.Fatalf("UploadAttachment() = %v", err)
+	}
+	// Тип не передан: сервер определяет его по содержимому
+	chunks := []*gnotev1.AttachmentChunk{
+		{NoteId: note.GetId(), Filename: "отчет.pdf"},
+		{Data: content[:100]},
+		{Data: content[100:]},
+	}
+	for _, chunk := range chunks {
+		if err := upload.Send(chunk); err != nil {
+			t.Fatalf("UploadAttachment: отправка: %v", err)
+		}
+	}
+	attachment, err := upload.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("UploadAttachment: %v", err)
+	}
+	if attachment.GetMimeType() != "application/pdf" || attachment.GetSizeBytes() != int64(len(content)) {
+		t.Errorf("UploadAttachment() = %v, ожидалось application/pdf, %d байт", attachment, len(content))
+	}
+
+	download, err := client.DownloadAttachment(ctx, &gnotev1.DownloadAttachmentRequest{Id: attachment.GetId(), NoteId: note.GetId()})
+	if err != nil {
+		t.Fatalf("DownloadAttachment() = %v", err)
+	}
+	var got []byte
+	var messages int
+	for {
+		chunk, err := download.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("DownloadAttachment: %v", err)
+		}
+		if messages == 0 && chunk.GetFilename() != "отчет.pdf" {
+			t.Errorf("DownloadAttachment: в первом сообщении имя %q", chunk.GetFilename())
+		}
+		got = append(got, chunk.GetData()...)
+		messages++
+	}
+	if !bytes.Equal(got, content) || messages < 2 {
+		t.Errorf("DownloadAttachment: получено %d байт в %d сообщениях, ожидалось %d байт частями", len(got), messages, len(content))
+	}
+
+	other, err := client.DownloadAttachment(ctx, &gnotev1.DownloadAttachmentRequest{Id: attachment.GetId(), NoteId: note.GetId() + 1})
+	if err == nil {
+		_, err = other.Recv()
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("DownloadAttachment(чужая заметка) = %v, ожидалось %v", err, codes.NotFound)
+	}
+}
@@ -0,0 +1,176 @@
+package clipper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+
+	"GNote/linkpreview"
+)
+
+const (
+	maxPageSize  = 10 << 20 // Максимальный размер загружаемой страницы
+	maxImageSize = 10 << 20 // Максимальный размер одного изображения
+	maxImages    = 30       // Максимальное количество сохраняемых изображений
+)
+
+// Image — изображение со страницы, которое будет сохранено как вложение
+type Image struct {
+	Name     string // Имя файла, на которое ссылается markdown
+	URL      string // Исходный адрес изображения
+	MimeType string
+	Data     []byte
+}
+
+// Clip — результат обработки веб-страницы
+type Clip struct {
+	Title     string
+	Markdown  string
+	SourceURL string
+	Images    []Image
+}
+
+// imageExtensions — расширения файлов изображений по типу, определенному по содержимому
+var imageExtensions = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/bmp":     ".bmp",
+	"image/x-icon":  ".ico",
+	"image/svg+xml": ".svg",
+	"image/avif":    ".avif",
+}
+
+// Clipper загружает веб-страницы и превращает их в заметки
+type Clipper struct {
+	client *http.Client // Для страницы, адрес которой ввел пользователь
+	public *http.Client // Для изображений с других сайтов: только адреса в интернете
+}
+
+// New создает клиппер. Страница загружается с любого адреса, который указал пользователь, а изображения
+// с других сайтов — только из интернета: чужая страница не должна заставлять GNote обращаться
+// к сервисам этого компьютера и локальной сети.
+func New() *Clipper {
+	return &Clipper{client: &http.Client{Timeout: 30 * time.Second}, public: linkpreview.NewPublicClient(30 * time.Second)}
+}
+
+// Fetch загружает страницу, выделяет основной текст, преобразует его в markdown
+// и скачивает встречающиеся в нем изображения
+func (c *Clipper) Fetch(ctx context.Context, rawURL string) (*Clip, error) {
+	pageURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") {
+		return nil, fmt.Errorf("некорректный адрес страницы '%s'", rawURL)
+	}
+
+	body, contentType, err := c.get(ctx, c.client, pageURL.String(), maxPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось загрузить страницу: %w", err)
+	}
+	reader, err := charset.NewReader(strings.NewReader(string(body)), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось определить кодировку страницы: %w", err)
+	}
+	doc, err := html.Parse(reader)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при разборе HTML: %w", err)
+	}
+
+	conv := newConverter(pageURL)
+	content := extractContent(doc)
+	markdown := conv.convert(content)
+
+	clip := &Clip{
+		Title:     extractTitle(doc),
+		Markdown:  markdown,
+		SourceURL: pageURL.String(),
+	}
+	if clip.Title == "" {
+		clip.Title = pageURL.Host
+	}
+
+	// Имена файлов известны только после загрузки: расширение определяется по содержимому
+	replacements := make([]string, 0, 2*len(conv.images))
+	for i, img := range conv.images {
+		target := img.URL // Изображение не критично: незагруженное остается ссылкой на исходный адрес
+		if data, mimeType, err := c.image(ctx, pageURL, img.URL); err == nil {
+			img.Data, img.MimeType = data, mimeType
+			img.Name = imageName(len(clip.Images)+1, mimeType)
+			clip.Images = append(clip.Images, img)
+			target = img.Name
+		}
+		replacements = append(replacements, imagePlaceholder(i), target)
+	}
+	clip.Markdown = strings.NewReplacer(replacements...).Replace(markdown)
+	return clip, nil
+}
+
+// image загружает изображение и возвращает его тип. Изображения с сайта самой страницы загружаются
+// так же, как она, с других сайтов — только из интернета.
+func (c *Clipper) image(ctx context.Context, pageURL *url.URL, rawURL string) ([]byte, string, error) {
+	client := c.public
+	if u, err := url.Parse(rawURL); err == nil && u.Host == pageURL.Host {
+		client = c.client
+	}
+	data, contentType, err := c.get(ctx, client, rawURL, maxImageSize)
+	if err != nil {
+		return nil, "", err
+	}
+	// Тип определяется по содержимому; SVG так не распознается, для него остается Content-Type
+	mimeType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType, _, _ = mime.ParseMediaType(contentType)
+		if mimeType != "image/svg+xml" {
+			return nil, "", fmt.Errorf("по адресу '%s' не изображение", rawURL)
+		}
+	}
+	return data, mimeType, nil
+}
+
+// get выполняет GET-запрос и возвращает тело ответа (не больше limit байт) и его Content-Type
+func (c *Clipper) get(ctx context.Context, client *http.Client, target string, limit int64) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", "GNote web clipper")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("сервер вернул статус %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > limit {
+		return nil, "", fmt.Errorf("размер ответа превышает %d байт", limit)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// imageName формирует имя файла для изображения с порядковым номером и расширением по типу mimeType.
+// Расширение из адреса не используется: по нему нельзя судить о содержимом.
+func imageName(index int, mimeType string) string {
+	ext, ok := imageExtensions[mimeType]
+	if !ok {
+		ext = ".img"
+	}
+	return fmt.Sprintf("image_%d%s", index, ext)
+}
+
+// imagePlaceholder — метка изображения index в markdown, которую Fetch заменяет именем файла или адресом
+func imagePlaceholder(index int) string {
+	return fmt.Sprintf("\x00image%d\x00", index)
+}
@@ -0,0 +1,130 @@
+package clipper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestMarkdownFromHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"абзацы и заголовок", "<h2>Итоги</h2><p>Первый   абзац</p><p>Второй</p>", "## Итоги\n\nПервый абзац\n\nВторой\n"},
+		{"выделение и код", "<p><b>важно</b>, <em>курсив</em> и <code>go test</code></p>", "**важно**, *курсив* и `go test`\n"},
+		{"ссылка", `<p><a href="https://example.com/a">пример</a></p>`, "[пример](https://example.com/a)\n"},
+		{"ссылка javascript", `<p><a href="javascript:alert(1)">нажми</a></p>`, "нажми\n"},
+		{"списки", "<ul><li>один</li><li>два</li></ul><ol><li>первый</li><li>второй</li></ol>",
+			"- один\n- два\n\n1. первый\n2. второй\n"},
+		{"цитата", "<blockquote><p>строка</p></blockquote>", "> строка\n"},
+		{"блок кода", "<pre>\nfunc main() {}\n</pre>", "```\nfunc main() {}\n```\n"},
+		{"изображение остается ссылкой", `<p><img src="https://example.com/a.png" alt="схема"></p>`, "![схема](https://example.com/a.png)\n"},
+		{"служебные элементы пропускаются", "<nav>меню</nav><script>x()</script><p>текст</p>", "текст\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarkdownFromHTML(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("MarkdownFromHTML() = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractTitle(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"title", "<title> Страница </title>", "Страница"},
+		{"og:title важнее title", `<title>Сайт</title><meta property="og:title" content="Статья">`, "Статья"},
+		{"без заголовка", "<p>текст</p>", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := extractTitle(doc); got != tt.want {
+				t.Errorf("extractTitle() = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetch(t *testing.T) {
+	// Сервис локальной сети на другом адресе: чужая страница не должна заставить GNote к нему обратиться
+	var localHits int
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		localHits++
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	}))
+	defer local.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><title>Статья</title></head><body>
+			<nav><a href="/">Главная</a></nav>
+			<article><h1>Заголовок</h1><p>Основной текст статьи.</p><p><img src="/img/photo.png" alt="фото"><img src="/img/photo.png"><img src="/missing.jpg"></p><p><img src="/img/photo.php?id=1"><img src="` + local.URL + `/router.png"><img src="/page.png"></p></article>
+			<footer>подвал</footer></body></html>`))
+	})
+	mux.HandleFunc("/img/photo.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	})
+	mux.HandleFunc("/img/photo.php", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte("\xff\xd8\xff\xe0 JFIF"))
+	})
+	mux.HandleFunc("/page.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("<html><body>не изображение</body></html>"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	clip, err := New().Fetch(context.Background(), server.URL+"/article")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clip.Title != "Статья" {
+		t.Errorf("Title = %q", clip.Title)
+	}
+	// Незагруженные изображения остаются ссылками на исходные адреса
+	want := "# Заголовок\n\nОсновной текст статьи.\n\n![фото](image_1.png)![](image_1.png)![](" + server.URL + "/missing.jpg)\n\n" +
+		"![](image_2.jpg)![](" + local.URL + "/router.png)![](" + server.URL + "/page.png)\n"
+	if clip.Markdown != want {
+		t.Errorf("Markdown = %q, ожидалось %q", clip.Markdown, want)
+	}
+	// Недоступное изображение не сохраняется, повторное — сохраняется один раз,
+	// а расширение определяется по содержимому, а не по адресу
+	var names []string
+	for _, img := range clip.Images {
+		names = append(names, img.Name+" "+img.MimeType)
+	}
+	if got, want := strings.Join(names, ", "), "image_1.png image/png, image_2.jpg image/jpeg"; got != want {
+		t.Errorf("Images = %s, ожидалось %s", got, want)
+	}
+	if localHits != 0 {
+		t.Errorf("изображение с адреса локальной сети загружено %d раз", localHits)
+	}
+
+	for _, rawURL := range []string{"ftp://example.com/", "не адрес", server.URL + "/404"} {
+		if _, err := New().Fetch(context.Background(), rawURL); err == nil {
+			t.Errorf("Fetch(%q): ожидалась ошибка", rawURL)
+		}
+	}
+}
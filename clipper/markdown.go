@@ -0,0 +1,204 @@
+package clipper
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	spacesRe   = regexp.MustCompile(`\s+`)
+	newlinesRe = regexp.MustCompile(`\n{3,}`)
+)
+
+// converter преобразует HTML в markdown и собирает изображения для скачивания
+type converter struct {
+	base     *url.URL
+	images   []Image
+	seen     map[string]string // Адрес изображения -> метка в markdown, см. imagePlaceholder
+	noImages bool              // Изображения остаются ссылками на исходные адреса и не скачиваются
+}
+
+// MarkdownFromHTML преобразует HTML-документ (например, тело письма) в markdown.
+// Изображения остаются ссылками на исходные адреса.
+func MarkdownFromHTML(r io.Reader) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при разборе HTML: %w", err)
+	}
+	conv := newConverter(&url.URL{})
+	conv.noImages = true
+	if body := findFirst(doc, atom.Body); body != nil {
+		return conv.convert(body), nil
+	}
+	return conv.convert(doc), nil
+}
+
+// newConverter создает конвертер, разрешающий относительные ссылки относительно base
+func newConverter(base *url.URL) *converter {
+	return &converter{base: base, seen: make(map[string]string)}
+}
+
+// convert возвращает markdown для узла
+func (c *converter) convert(n *html.Node) string {
+	var b strings.Builder
+	c.block(&b, n)
+	md := newlinesRe.ReplaceAllString(b.String(), "\n\n")
+	return strings.TrimSpace(md) + "\n"
+}
+
+// block выводит блочные элементы, разделяя их пустыми строками
+func (c *converter) block(b *strings.Builder, n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode {
+			b.WriteString(c.inlineText(child.Data))
+			continue
+		}
+		if child.Type != html.ElementNode || skippedTags[child.DataAtom] {
+			continue
+		}
+
+		switch child.DataAtom {
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			level := int(child.Data[1] - '0')
+			fmt.Fprintf(b, "\n\n%s %s\n\n", strings.Repeat("#", level), strings.TrimSpace(c.inline(child)))
+		case atom.P:
+			fmt.Fprintf(b, "\n\n%s\n\n", strings.TrimSpace(c.inline(child)))
+		case atom.Br:
+			b.WriteString("  \n")
+		case atom.Hr:
+			b.WriteString("\n\n---\n\n")
+		case atom.Pre:
+			fmt.Fprintf(b, "\n\n```\n%s\n```\n\n", strings.Trim(textContent(child), "\n"))
+		case atom.Blockquote:
+			var inner strings.Builder
+			c.block(&inner, child)
+			lines := strings.Split(strings.TrimSpace(newlinesRe.ReplaceAllString(inner.String(), "\n\n")), "\n")
+			b.WriteString("\n\n")
+			for _, line := range lines {
+				fmt.Fprintf(b, "> %s\n", line)
+			}
+			b.WriteString("\n")
+		case atom.Ul, atom.Ol:
+			b.WriteString("\n\n")
+			c.list(b, child, child.DataAtom == atom.Ol)
+			b.WriteString("\n")
+		case atom.Img, atom.A, atom.Strong, atom.B, atom.Em, atom.I, atom.Code, atom.Span:
+			c.inlineNode(b, child)
+		default:
+			c.block(b, child)
+		}
+	}
+}
+
+// list выводит элементы списка
+func (c *converter) list(b *strings.Builder, n *html.Node, ordered bool) {
+	index := 1
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+		marker := "-"
+		if ordered {
+			marker = fmt.Sprintf("%d.", index)
+			index++
+		}
+		var inner strings.Builder
+		c.block(&inner, li)
+		text := strings.TrimSpace(newlinesRe.ReplaceAllString(inner.String(), "\n"))
+		text = strings.ReplaceAll(text, "\n\n", "\n")
+		fmt.Fprintf(b, "%s %s\n", marker, strings.ReplaceAll(text, "\n", "\n  "))
+	}
+}
+
+// inline возвращает markdown для строчного содержимого узла
+func (c *converter) inline(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.inlineNode(&b, child)
+	}
+	return b.String()
+}
+
+// inlineNode выводит markdown для одного строчного узла
+func (c *converter) inlineNode(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(c.inlineText(n.Data))
+	case html.ElementNode:
+		if skippedTags[n.DataAtom] {
+			return
+		}
+		switch n.DataAtom {
+		case atom.Strong, atom.B:
+			if text := strings.TrimSpace(c.inline(n)); text != "" {
+				fmt.Fprintf(b, "**%s**", text)
+			}
+		case atom.Em, atom.I:
+			if text := strings.TrimSpace(c.inline(n)); text != "" {
+				fmt.Fprintf(b, "*%s*", text)
+			}
+		case atom.Code:
+			fmt.Fprintf(b, "`%s`", textContent(n))
+		case atom.Br:
+			b.WriteString("  \n")
+		case atom.A:
+			text := strings.TrimSpace(c.inline(n))
+			href := c.resolve(attr(n, "href"))
+			if href == "" || strings.HasPrefix(href, "javascript:") {
+				b.WriteString(text)
+			} else if text != "" {
+				fmt.Fprintf(b, "[%s](%s)", text, href)
+			}
+		case atom.Img:
+			b.WriteString(c.image(n))
+		default:
+			b.WriteString(c.inline(n))
+		}
+	}
+}
+
+// inlineText схлопывает пробельные символы текста
+func (c *converter) inlineText(s string) string {
+	return spacesRe.ReplaceAllString(s, " ")
+}
+
+// image регистрирует изображение для скачивания и возвращает ссылку на него с меткой вместо имени файла
+func (c *converter) image(n *html.Node) string {
+	src := c.resolve(attr(n, "src"))
+	if src == "" || strings.HasPrefix(src, "data:") {
+		return ""
+	}
+	alt := strings.TrimSpace(attr(n, "alt"))
+	if c.noImages {
+		return fmt.Sprintf("![%s](%s)", alt, src)
+	}
+	if name, ok := c.seen[src]; ok {
+		return fmt.Sprintf("![%s](%s)", alt, name)
+	}
+	if len(c.images) >= maxImages {
+		return fmt.Sprintf("![%s](%s)", alt, src)
+	}
+	placeholder := imagePlaceholder(len(c.images))
+	c.seen[src] = placeholder
+	c.images = append(c.images, Image{URL: src})
+	return fmt.Sprintf("![%s](%s)", alt, placeholder)
+}
+
+// resolve превращает относительную ссылку в абсолютную
+func (c *converter) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return c.base.ResolveReference(u).String()
+}
@@ -0,0 +1,139 @@
+package clipper
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skippedTags — элементы, которые никогда не относятся к основному тексту страницы
+var skippedTags = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Nav:      true,
+	atom.Header:   true,
+	atom.Footer:   true,
+	atom.Aside:    true,
+	atom.Form:     true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+	atom.Button:   true,
+}
+
+// extractTitle возвращает заголовок страницы (og:title или <title>)
+func extractTitle(doc *html.Node) string {
+	var title, ogTitle string
+	walk(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		switch n.DataAtom {
+		case atom.Title:
+			if title == "" {
+				title = strings.TrimSpace(textContent(n))
+			}
+		case atom.Meta:
+			if attr(n, "property") == "og:title" && ogTitle == "" {
+				ogTitle = strings.TrimSpace(attr(n, "content"))
+			}
+		}
+		return true
+	})
+	if ogTitle != "" {
+		return ogTitle
+	}
+	return title
+}
+
+// extractContent находит элемент с основным текстом страницы:
+// самый «текстовый» <article>, иначе элемент с наибольшим объемом текста в абзацах
+func extractContent(doc *html.Node) *html.Node {
+	var best *html.Node
+	bestScore := 0
+
+	walk(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		if skippedTags[n.DataAtom] {
+			return false
+		}
+		if n.DataAtom == atom.Article {
+			if score := len(textContent(n)) * 2; score > bestScore {
+				best, bestScore = n, score
+			}
+			return true
+		}
+		score := 0
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && (c.DataAtom == atom.P || c.DataAtom == atom.Pre || c.DataAtom == atom.Blockquote) {
+				score += len(textContent(c))
+			}
+		}
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+		return true
+	})
+
+	if best != nil {
+		return best
+	}
+	if body := findFirst(doc, atom.Body); body != nil {
+		return body
+	}
+	return doc
+}
+
+// walk обходит дерево в глубину; если fn возвращает false, потомки узла пропускаются
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+// findFirst возвращает первый элемент с указанным тегом
+func findFirst(n *html.Node, a atom.Atom) *html.Node {
+	var found *html.Node
+	walk(n, func(c *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			found = c
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// textContent возвращает весь видимый текст узла
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(c *html.Node) bool {
+		if c.Type == html.ElementNode && skippedTags[c.DataAtom] {
+			return false
+		}
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+		return true
+	})
+	return b.String()
+}
+
+// attr возвращает значение атрибута элемента
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,695 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"GNote/hooks"
+	"GNote/policy"
+	"GNote/storage"
+)
+
+// Config — настройки приложения из файла config.toml.
+// Переменные окружения имеют приоритет над значениями из файла.
+type Config struct {
+	Database      DatabaseConfig      `toml:"database"`
+	Storage       StorageConfig       `toml:"storage"`
+	Notes         NotesConfig         `toml:"notes"`
+	UI            UIConfig            `toml:"ui"`
+	OCR           OCRConfig           `toml:"ocr"`
+	Transcription TranscriptionConfig `toml:"transcription"`
+	Mirror        MirrorConfig        `toml:"mirror"`
+	Speech        SpeechConfig        `toml:"speech"`
+	Server        ServerConfig        `toml:"server"`
+	Hooks         HooksConfig         `toml:"hooks"`
+	Email         EmailConfig         `toml:"email"`
+	Summary       SummaryConfig       `toml:"summary"`
+	Scanner       ScannerConfig       `toml:"scanner"`
+	Attachments   AttachmentsConfig   `toml:"attachments"`
+
+	// Profiles — именованные профили ([profiles.work.database] и т.д.), переопределяющие основные настройки
+	Profiles map[string]toml.Primitive `toml:"profiles"`
+	Profile  string                    `toml:"-"` // Выбранный профиль (пусто — основные настройки)
+
+	meta toml.MetaData
+}
+
+// DatabaseConfig — параметры подключения к PostgreSQL
+type DatabaseConfig struct {
+	URL      string `toml:"url"` // Строка подключения postgres://...; если задана, остальные поля не используются
+	Host     string `toml:"host"`
+	Port     int    `toml:"port"`
+	User     string `toml:"user"`
+	Password string `toml:"password"`
+	Name     string `toml:"name"`
+	SSLMode  string `toml:"sslmode"`
+	// Файлы TLS для управляемых PostgreSQL (verify-full, клиентские сертификаты); дополняют и url
+	SSLRootCert    string `toml:"sslrootcert"`
+	SSLCert        string `toml:"sslcert"`
+	SSLKey         string `toml:"sslkey"`
+	ChannelBinding string `toml:"channel_binding"` // disable, prefer или require; пусто — как в url
+	// ReplicaURL — строка подключения к реплике только для чтения для списка заметок, поиска и статистики
+	ReplicaURL string `toml:"replica_url"`
+	// SlowQueryMS — вызовы хранилища дольше порога в миллисекундах записываются в журнал; 0 — не записываются
+	SlowQueryMS int `toml:"slow_query_ms"`
+	// ListenChanges — получать от PostgreSQL (LISTEN/NOTIFY) уведомления об изменениях заметок
+	// другими экземплярами и обновлять только измененные заметки
+	ListenChanges bool `toml:"listen_changes"`
+}
+
+// StorageConfig — где хранятся заметки и вложения
+type StorageConfig struct {
+	Backend        string `toml:"backend"`         // Пока поддерживается только "postgres"
+	DataDir        string `toml:"data_dir"`        // Каталог данных; пусто — каталог данных приложения
+	AttachmentsDir string `toml:"attachments_dir"` // Пусто — подкаталог attachments каталога данных
+	Attachments    string `toml:"attachments"`     // "files" — файлы в каталоге вложений, "database" — в самой БД
+	OfflineCache   bool   `toml:"offline_cache"`   // Локальная копия заметок для работы без связи с БД
+}
+
+// NotesConfig — ограничения размера заметки; редактор предупреждает, когда заметка к ним приближается
+type NotesConfig struct {
+	MaxTitleLength int   `toml:"max_title_length"` // Символов в заголовке; не больше 255 — длины колонки в БД
+	MaxContentKB   int64 `toml:"max_content_kb"`   // Размер содержимого в килобайтах
+}
+
+// Режимы хранения вложений
+const (
+	AttachmentsFiles    = "files"
+	AttachmentsDatabase = "database"
+)
+
+// Плотность интерфейса
+const (
+	DensityComfortable = "comfortable"
+	DensityCompact     = "compact"
+)
+
+// UIConfig — настройки интерфейса
+type UIConfig struct {
+	Theme string `toml:"theme"` // "system", "light" или "dark"
+	// Density — плотность интерфейса: "comfortable" или "compact" с уменьшенными отступами для небольших экранов
+	Density string `toml:"density"`
+	// LinkPreviews — показывать под заметкой карточки ссылок (заголовок, описание, значок). Страница
+	// загружается, когда пользователь нажимает кнопку на карточке; адреса локальной сети не загружаются.
+	LinkPreviews bool `toml:"link_previews"`
+}
+
+// OCRConfig — распознавание текста на изображениях-вложениях
+type OCRConfig struct {
+	Command string `toml:"command"` // Команда распознавания; {file} заменяется путем к изображению. Пусто — выключено
+}
+
+// TranscriptionConfig — расшифровка аудиовложений: локальной программой или через API
+type TranscriptionConfig struct {
+	Command string `toml:"command"` // Команда расшифровки (например, whisper.cpp); {file} заменяется путем к аудио
+	APIURL  string `toml:"api_url"` // Адрес API, совместимого с OpenAI /v1/audio/transcriptions; используется, если command пуст
+	APIKey  string `toml:"api_key"`
+	Model   string `toml:"model"`
+}
+
+// SummaryConfig — краткий пересказ заметок: локально или языковой моделью через API
+type SummaryConfig struct {
+	APIURL    string `toml:"api_url"` // Адрес API, совместимого с OpenAI /v1/chat/completions; пусто — локальный пересказ
+	APIKey    string `toml:"api_key"`
+	Model     string `toml:"model"`
+	Sentences int    `toml:"sentences"` // Сколько предложений оставляет локальный пересказ
+}
+
+// ScannerConfig — сканирование документов во вложения
+type ScannerConfig struct {
+	// Command сохраняет отсканированную страницу в файл {file} с расширением формата. Пусто — scanimage или WIA
+	Command   string `toml:"command"`
+	HotFolder string `toml:"hot_folder"` // Папка, файлы из которой прикрепляются к открытой заметке; пусто — выключено
+}
+
+// AttachmentsConfig — какие вложения принимаются; нужно при общей БД для команды
+type AttachmentsConfig struct {
+	AllowedTypes      []string `toml:"allowed_types"`      // Типы MIME или группы вида "image/*"; пусто — любые
+	BlockedExtensions []string `toml:"blocked_extensions"` // Запрещенные расширения, например ".exe"
+	MaxSizeMB         int64    `toml:"max_size_mb"`        // Предельный размер вложения; 0 — без ограничения
+	// ScanCommand проверяет файл {file} антивирусом перед сохранением; код выхода 1 — найден вирус
+	ScanCommand string `toml:"scan_command"`
+}
+
+// SpeechConfig — чтение заметок вслух
+type SpeechConfig struct {
+	Command string `toml:"command"` // Команда синтеза речи, текст подается на stdin; {rate} — слов в минуту. Пусто — синтезатор системы
+}
+
+// MirrorConfig — копия заметок в markdown-файлах (например, для версионирования в git)
+type MirrorConfig struct {
+	Dir  string `toml:"dir"`  // Каталог копии; пусто — выключено
+	Mode string `toml:"mode"` // "nightly" — раз в сутки, "on_save" — еще и после каждого изменения заметки
+	Time string `toml:"time"` // Местное время ежедневной копии ЧЧ:ММ
+	Git  bool   `toml:"git"`  // Фиксировать каждое обновление копии коммитом в git
+	// Remote — куда отправлять коммиты (имя удаленного репозитория или адрес); пусто — не отправлять
+	Remote string `toml:"remote"`
+}
+
+// Режимы копии заметок в markdown
+const (
+	MirrorNightly = "nightly"
+	MirrorOnSave  = "on_save"
+)
+
+// Clock возвращает время ежедневной копии
+func (m MirrorConfig) Clock() (hour, minute int, err error) {
+	t, err := time.Parse("15:04", m.Time)
+	if err != nil {
+		return 0, 0, fmt.Errorf("время копии заметок %q должно быть в формате ЧЧ:ММ", m.Time)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// ServerConfig — режим сервера (gnote --serve) и подключение окна заметок к его ленте изменений
+type ServerConfig struct {
+	Listen     string `toml:"listen"`      // Адрес, на котором сервер принимает подключения
+	GRPCListen string `toml:"grpc_listen"` // Адрес API gRPC (схема api/gnote.proto); пусто — не запускать
+	Token      string `toml:"token"`       // Ключ доступа к серверу; без него режим сервера не запускается
+	// AllowedOrigins — страницы (https://host), которым браузер разрешит подключаться к ленте изменений;
+	// подключения с другим заголовком Origin отклоняются; окна GNote передают свой (gnote://window) и принимаются всегда
+	AllowedOrigins []string `toml:"allowed_origins"`
+	// ChangesURL — лента изменений сервера (ws://host:port/changes), через которую окна заметок
+	// на разных компьютерах узнают об изменениях друг друга. Пусто — не подключаться.
+	ChangesURL string `toml:"changes_url"`
+	// PublicNotebooks — блокноты (теги с вложенными), RSS которых читается без ключа доступа
+	PublicNotebooks []string `toml:"public_notebooks"`
+	FeedItems       int      `toml:"feed_items"` // Сколько заметок в RSS
+}
+
+// HooksConfig — обработчики событий: команды (данные в JSON на stdin) или адреса вебхуков http(s)://.
+// Обработчики событий заметок только уведомляют; данные меняет лишь before_export.
+type HooksConfig struct {
+	NoteCreated  []string `toml:"note_created"`
+	NoteSaved    []string `toml:"note_saved"`
+	NoteDeleted  []string `toml:"note_deleted"`
+	BeforeExport []string `toml:"before_export"` // Непустой вывод команды заменяет выгружаемый JSON
+}
+
+// ByEvent возвращает обработчики по названиям событий пакета hooks
+func (h HooksConfig) ByEvent() map[string][]string {
+	return map[string][]string{
+		hooks.NoteCreated:  h.NoteCreated,
+		hooks.NoteSaved:    h.NoteSaved,
+		hooks.NoteDeleted:  h.NoteDeleted,
+		hooks.BeforeExport: h.BeforeExport,
+	}
+}
+
+// EmailConfig — прием писем из почтового ящика IMAP в заметки с тегом email
+type EmailConfig struct {
+	IMAP            string `toml:"imap"` // Сервер host:port; пусто — выключено
+	TLS             bool   `toml:"tls"`
+	User            string `toml:"user"`
+	Password        string `toml:"password"`
+	Folder          string `toml:"folder"`
+	IntervalMinutes int    `toml:"interval_minutes"` // Как часто проверять новые письма
+}
+
+// Default возвращает настройки по умолчанию
+func Default() Config {
+	return Config{
+		Database: DatabaseConfig{
+			Host:    "localhost",
+			Port:    5432,
+			User:    "dima",
+			Name:    "gnote_db",
+			SSLMode: "disable",
+
+			ListenChanges: true,
+			SlowQueryMS:   500,
+		},
+		Storage:       StorageConfig{Backend: "postgres", Attachments: AttachmentsFiles},
+		Notes:         NotesConfig{MaxTitleLength: storage.MaxTitleLength, MaxContentKB: storage.DefaultLimits.MaxContentBytes >> 10},
+		UI:            UIConfig{Theme: "system", Density: DensityComfortable},
+		Transcription: TranscriptionConfig{Model: "whisper-1"},
+		Summary:       SummaryConfig{Model: "gpt-4o-mini", Sentences: 3},
+		Mirror:        MirrorConfig{Mode: MirrorNightly, Time: "03:00"},
+		Server:        ServerConfig{Listen: "127.0.0.1:8765", GRPCListen: "127.0.0.1:8766", FeedItems: 50},
+		Email:         EmailConfig{TLS: true, Folder: "INBOX", IntervalMinutes: 5},
+	}
+}
+
+// DefaultPath возвращает путь к файлу настроек: ~/.config/gnote/config.toml на Linux, см. ConfigDir
+func DefaultPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// Load читает настройки из файла path и применяет переменные окружения.
+// Если файла нет, создается пример с настройками по умолчанию.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	meta, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return cfg, fmt.Errorf("ошибка в файле настроек %s: %w", path, err)
+		}
+		if err := writeSample(path); err != nil {
+			log.Printf("Не удалось создать пример файла настроек %s: %v", path, err)
+		} else {
+			log.Printf("Создан пример файла настроек: %s", path)
+		}
+	}
+
+	cfg.meta = meta
+
+	if err := cfg.applyEnv(); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.validate()
+}
+
+// validate проверяет значения, которые нельзя исправить по умолчанию
+func (c Config) validate() error {
+	if c.Storage.Backend != "postgres" {
+		return fmt.Errorf("неподдерживаемое хранилище %q: доступно только postgres", c.Storage.Backend)
+	}
+	if err := c.Database.validateTLS(); err != nil {
+		return err
+	}
+	if c.Database.SlowQueryMS < 0 {
+		return fmt.Errorf("slow_query_ms в [database] не может быть отрицательным")
+	}
+	if c.Storage.Attachments != AttachmentsFiles && c.Storage.Attachments != AttachmentsDatabase {
+		return fmt.Errorf("неизвестный режим хранения вложений %q: доступны %s и %s",
+			c.Storage.Attachments, AttachmentsFiles, AttachmentsDatabase)
+	}
+	if c.UI.Density != DensityComfortable && c.UI.Density != DensityCompact {
+		return fmt.Errorf("неизвестная плотность интерфейса %q: доступны %s и %s",
+			c.UI.Density, DensityComfortable, DensityCompact)
+	}
+	if c.Notes.MaxTitleLength <= 0 || c.Notes.MaxTitleLength > storage.MaxTitleLength {
+		return fmt.Errorf("max_title_length в [notes] должен быть от 1 до %d", storage.MaxTitleLength)
+	}
+	if c.Notes.MaxContentKB <= 0 {
+		return fmt.Errorf("max_content_kb в [notes] должен быть больше нуля")
+	}
+	if c.Mirror.Mode != MirrorNightly && c.Mirror.Mode != MirrorOnSave {
+		return fmt.Errorf("неизвестный режим копии заметок %q: доступны %s и %s", c.Mirror.Mode, MirrorNightly, MirrorOnSave)
+	}
+	if _, _, err := c.Mirror.Clock(); err != nil {
+		return err
+	}
+	if c.Email.IMAP != "" && c.Email.IntervalMinutes <= 0 {
+		return fmt.Errorf("интервал проверки почты interval_minutes в [email] должен быть больше нуля")
+	}
+	if c.Mirror.Remote != "" && !c.Mirror.Git {
+		return fmt.Errorf("для отправки копии заметок в %s включите git = true в [mirror]", c.Mirror.Remote)
+	}
+	if _, err := c.AttachmentPolicy(); err != nil {
+		return err
+	}
+	if err := hooks.Validate(c.Hooks.ByEvent()); err != nil {
+		return fmt.Errorf("ошибка в разделе [hooks]: %w", err)
+	}
+	return nil
+}
+
+// ProfileNames возвращает имена профилей из файла настроек по алфавиту
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForProfile возвращает настройки профиля name: значения из [profiles.name] поверх основных.
+// Переменные окружения относятся только к основным настройкам, профили берутся из файла.
+func (c Config) ForProfile(name string) (Config, error) {
+	if name == "" {
+		return c, nil
+	}
+	primitive, ok := c.Profiles[name]
+	if !ok {
+		return c, fmt.Errorf("профиль %q не найден в файле настроек", name)
+	}
+	profile := c
+	if err := c.meta.PrimitiveDecode(primitive, &profile); err != nil {
+		return c, fmt.Errorf("ошибка в настройках профиля %q: %w", name, err)
+	}
+	profile.Profiles = c.Profiles
+	profile.Profile = name
+	return profile, profile.validate()
+}
+
+// AttachmentsPath возвращает каталог вложений. appDataDir — каталог данных приложения,
+// используемый, если data_dir не задан; у каждого профиля свой подкаталог.
+func (c Config) AttachmentsPath(appDataDir string) string {
+	if c.Storage.AttachmentsDir != "" {
+		return c.Storage.AttachmentsDir
+	}
+	return filepath.Join(c.profileDataDir(appDataDir), "attachments")
+}
+
+// OfflineCachePath возвращает файл офлайн-кэша заметок; у каждого профиля свой, как у вложений
+func (c Config) OfflineCachePath(appDataDir string) string {
+	return filepath.Join(c.profileDataDir(appDataDir), "offline-cache.json")
+}
+
+// profileDataDir возвращает каталог данных профиля
+func (c Config) profileDataDir(appDataDir string) string {
+	dir := c.Storage.DataDir
+	if dir == "" {
+		dir = appDataDir
+	}
+	if c.Profile != "" {
+		dir = filepath.Join(dir, "profiles", c.Profile)
+	}
+	return dir
+}
+
+// MirrorPath возвращает каталог копии заметок в markdown (пусто — копия выключена);
+// у каждого профиля свой подкаталог, как у вложений
+func (c Config) MirrorPath() string {
+	if c.Mirror.Dir == "" || c.Profile == "" {
+		return c.Mirror.Dir
+	}
+	return filepath.Join(c.Mirror.Dir, "profiles", c.Profile)
+}
+
+// validateTLS проверяет режим SSL и файлы сертификатов, чтобы ошибка была понятной еще до подключения
+func (d DatabaseConfig) validateTLS() error {
+	if d.URL == "" && !slices.Contains([]string{"disable", "require", "verify-ca", "verify-full"}, d.SSLMode) {
+		return fmt.Errorf("неподдерживаемый sslmode %q: доступны disable, require, verify-ca и verify-full", d.SSLMode)
+	}
+	if (d.SSLCert == "") != (d.SSLKey == "") {
+		return fmt.Errorf("для сертификата клиента нужны оба файла: sslcert и sslkey")
+	}
+	if d.URL == "" && d.SSLRootCert == "" && (d.SSLMode == "verify-ca" || d.SSLMode == "verify-full") {
+		return fmt.Errorf("для sslmode %s укажите sslrootcert — сертификат центра сертификации сервера", d.SSLMode)
+	}
+	for _, path := range []string{d.SSLRootCert, d.SSLCert, d.SSLKey} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("файл TLS для подключения к БД недоступен: %w", err)
+		}
+	}
+	switch d.ChannelBinding {
+	case "", storage.ChannelBindingDisable, storage.ChannelBindingPrefer:
+	case storage.ChannelBindingRequire:
+		return storage.ErrChannelBindingUnsupported
+	default:
+		return fmt.Errorf("неизвестный режим channel_binding %q: доступны disable, prefer и require", d.ChannelBinding)
+	}
+	return nil
+}
+
+// applyEnv переопределяет настройки переменными окружения
+func (c *Config) applyEnv() error {
+	// Пустые переменные не учитываются, как и раньше (например, DB_PASSWORD= в exp.sh)
+	setString := func(key string, target *string) {
+		if value := os.Getenv(key); value != "" {
+			*target = value
+		}
+	}
+	// DATABASE_URL — общепринятое имя (Heroku, Railway, Supabase); GNOTE_DB_URL важнее
+	setString("DATABASE_URL", &c.Database.URL)
+	setString("GNOTE_DB_URL", &c.Database.URL)
+	setString("DB_HOST", &c.Database.Host)
+	setString("DB_USER", &c.Database.User)
+	setString("DB_PASSWORD", &c.Database.Password)
+	setString("DB_NAME", &c.Database.Name)
+	setString("DB_SSLMODE", &c.Database.SSLMode)
+	setString("DB_SSLROOTCERT", &c.Database.SSLRootCert)
+	setString("DB_SSLCERT", &c.Database.SSLCert)
+	setString("DB_SSLKEY", &c.Database.SSLKey)
+	setString("DB_CHANNEL_BINDING", &c.Database.ChannelBinding)
+	setString("GNOTE_DB_REPLICA_URL", &c.Database.ReplicaURL)
+	setString("GNOTE_STORAGE", &c.Storage.Backend)
+	setString("GNOTE_DATA_DIR", &c.Storage.DataDir)
+	setString("GNOTE_ATTACHMENTS_DIR", &c.Storage.AttachmentsDir)
+	setString("GNOTE_ATTACHMENTS", &c.Storage.Attachments)
+	setString("GNOTE_THEME", &c.UI.Theme)
+	setString("GNOTE_DENSITY", &c.UI.Density)
+	setString("GNOTE_OCR_COMMAND", &c.OCR.Command)
+	setString("GNOTE_TRANSCRIBE_COMMAND", &c.Transcription.Command)
+	setString("GNOTE_TRANSCRIBE_URL", &c.Transcription.APIURL)
+	setString("GNOTE_TRANSCRIBE_KEY", &c.Transcription.APIKey)
+	setString("GNOTE_MIRROR_DIR", &c.Mirror.Dir)
+	setString("GNOTE_SPEECH_COMMAND", &c.Speech.Command)
+	setString("GNOTE_SERVER_LISTEN", &c.Server.Listen)
+	setString("GNOTE_SERVER_GRPC_LISTEN", &c.Server.GRPCListen)
+	setString("GNOTE_SERVER_TOKEN", &c.Server.Token)
+	setString("GNOTE_CHANGES_URL", &c.Server.ChangesURL)
+	setString("GNOTE_EMAIL_IMAP", &c.Email.IMAP)
+	setString("GNOTE_EMAIL_USER", &c.Email.User)
+	setString("GNOTE_EMAIL_PASSWORD", &c.Email.Password)
+	setString("GNOTE_SUMMARY_URL", &c.Summary.APIURL)
+	setString("GNOTE_SUMMARY_KEY", &c.Summary.APIKey)
+	setString("GNOTE_SCAN_COMMAND", &c.Scanner.Command)
+	setString("GNOTE_SCAN_FOLDER", &c.Scanner.HotFolder)
+	setString("GNOTE_ATTACHMENT_SCAN_COMMAND", &c.Attachments.ScanCommand)
+
+	if value := os.Getenv("DB_PORT"); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("неверное значение DB_PORT %q: %w", value, err)
+		}
+		c.Database.Port = port
+	}
+	return nil
+}
+
+// AttachmentPolicy возвращает правила приема вложений
+func (c Config) AttachmentPolicy() (*policy.Policy, error) {
+	a := c.Attachments
+	return policy.New(a.AllowedTypes, a.BlockedExtensions, a.MaxSizeMB<<20, a.ScanCommand)
+}
+
+// StorageConfig возвращает параметры подключения для хранилища
+func (c Config) StorageConfig() storage.Config {
+	return storage.Config{
+		URL:      c.Database.URL,
+		Host:     c.Database.Host,
+		Port:     c.Database.Port,
+		User:     c.Database.User,
+		Password: c.Database.Password,
+		DBName:   c.Database.Name,
+		SSLMode:  c.Database.SSLMode,
+
+		SSLRootCert:    c.Database.SSLRootCert,
+		SSLCert:        c.Database.SSLCert,
+		SSLKey:         c.Database.SSLKey,
+		ChannelBinding: c.Database.ChannelBinding,
+		ReplicaURL:     c.Database.ReplicaURL,
+
+		Limits: storage.Limits{MaxTitleLength: c.Notes.MaxTitleLength, MaxContentBytes: c.Notes.MaxContentKB << 10},
+	}
+}
+
+// writeSample создает файл настроек с комментариями и значениями по умолчанию
+func writeSample(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// O_EXCL: не перезаписываем файл, если он появился одновременно из другого экземпляра
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(sample); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// sample — пример файла настроек. Значения совпадают с Default.
+const sample = `# Настройки GNote.
+# Переменные окружения (DATABASE_URL, GNOTE_DB_URL, DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME, DB_SSLMODE,
+# DB_SSLROOTCERT, DB_SSLCERT, DB_SSLKEY, DB_CHANNEL_BINDING, GNOTE_DB_REPLICA_URL, GNOTE_STORAGE, GNOTE_DATA_DIR, GNOTE_ATTACHMENTS_DIR, GNOTE_ATTACHMENTS, GNOTE_THEME, GNOTE_DENSITY,
+# GNOTE_OCR_COMMAND, GNOTE_TRANSCRIBE_COMMAND, GNOTE_TRANSCRIBE_URL, GNOTE_TRANSCRIBE_KEY, GNOTE_MIRROR_DIR, GNOTE_SPEECH_COMMAND,
+# GNOTE_SERVER_LISTEN, GNOTE_SERVER_GRPC_LISTEN, GNOTE_SERVER_TOKEN, GNOTE_CHANGES_URL, GNOTE_EMAIL_IMAP, GNOTE_EMAIL_USER,
+# GNOTE_EMAIL_PASSWORD, GNOTE_SUMMARY_URL, GNOTE_SUMMARY_KEY, GNOTE_SCAN_COMMAND, GNOTE_SCAN_FOLDER,
+# GNOTE_ATTACHMENT_SCAN_COMMAND) переопределяют
+# основные значения, а флаги командной строки (--db-url, --data-dir, --profile) — все остальное.
+# Каталоги следуют XDG: настройки — $XDG_CONFIG_HOME/gnote (или GNOTE_CONFIG_DIR), данные —
+# $XDG_DATA_HOME/gnote, кэш — $XDG_CACHE_HOME/gnote (GNOTE_CACHE_DIR), журнал — $XDG_STATE_HOME/gnote (GNOTE_STATE_DIR).
+
+[database]
+# Строка подключения; если задана, host/port/user/password/name/sslmode не используются.
+# Поддерживаются параметры libpq, например sslmode=verify-full&sslrootcert=/path/root.crt
+# url = "postgres://dima@localhost:5432/gnote_db?sslmode=disable"
+host = "localhost"
+port = 5432
+user = "dima"
+password = ""
+name = "gnote_db"
+# disable, require, verify-ca или verify-full. Управляемым PostgreSQL (RDS, Cloud SQL, Azure)
+# нужен verify-full с сертификатом центра сертификации провайдера
+sslmode = "disable"
+# Файлы TLS; дополняют и url. sslrootcert — сертификат центра сертификации для verify-ca и verify-full,
+# sslcert и sslkey — сертификат и ключ клиента, если сервер проверяет клиентов по сертификатам
+# sslrootcert = "/home/user/.postgresql/root.crt"
+# sslcert = "/home/user/.postgresql/postgresql.crt"
+# sslkey = "/home/user/.postgresql/postgresql.key"
+# Привязка аутентификации к TLS-соединению: disable или prefer. Драйвер не поддерживает привязку,
+# поэтому require — ошибка: используйте verify-full, он тоже защищает от подмены сервера
+# channel_binding = "prefer"
+# Реплика только для чтения (потоковая репликация) для большой общей БД: на нее идут список заметок,
+# поиск, счетчики тегов и статистика, а запись — в основную БД. Несколько секунд после сохранения
+# и при недоступности реплики чтения тоже идут в основную БД. Пусто — реплика не используется
+# replica_url = "postgres://dima@replica.example.com:5432/gnote_db?sslmode=verify-full"
+# Записывать в журнал запросы к БД дольше порога (мс); 0 — не записывать. Время всех запросов видно
+# в Инструменты → Запросы к БД, а в режиме сервера — по адресу /metrics в формате Prometheus
+slow_query_ms = 500
+# Обновлять заметки, измененные в другом окне GNote или другой программой, без перезагрузки.
+# Нужны триггеры из database.sql
+listen_changes = true
+
+[storage]
+backend = "postgres"
+# Каталог данных; по умолчанию — $XDG_DATA_HOME/gnote (~/.local/share/gnote)
+# data_dir = "/home/user/.local/share/gnote"
+# Каталог для файлов вложений; по умолчанию — attachments в каталоге данных
+# attachments_dir = "/home/user/Documents/gnote-attachments"
+# Где хранить содержимое вложений: "files" — в каталоге вложений, "database" — в PostgreSQL,
+# чтобы резервная копия БД содержала все. Перенос существующих: gnote --migrate-attachments=database
+attachments = "files"
+# Локальная копия заметок: без связи с БД заметки читаются из нее, а изменения отправляются
+# в БД после восстановления связи. Если заметку за это время изменили в БД, создается копия.
+offline_cache = false
+
+[notes]
+# Ограничения размера заметки. Редактор предупреждает, когда заметка приближается к ним,
+# а заметку больше не сохраняет. Заголовок — не больше 255 символов
+max_title_length = 255
+# Размер содержимого в килобайтах
+max_content_kb = 10240
+
+[ui]
+# system, light или dark
+theme = "system"
+# Плотность: comfortable или compact — меньше отступы и высота строк списка и редактора
+density = "comfortable"
+# Карточки ссылок под заметкой: заголовок, описание и значок страницы. Страница загружается из сети
+# по нажатию кнопки на карточке; адреса этого компьютера и локальной сети не загружаются
+link_previews = false
+
+[ocr]
+# Распознавание текста на изображениях-вложениях, чтобы их можно было найти поиском.
+# {file} заменяется путем к изображению; программа должна печатать текст в stdout.
+# Путь с пробелами заключается в кавычки, как и в других командах: '"C:\Program Files\Tesseract-OCR\tesseract.exe" {file} stdout'
+# command = "tesseract {file} stdout -l rus+eng"
+
+[transcription]
+# Расшифровка голосовых заметок (аудиовложений) для поиска и чтения.
+# Локально через whisper.cpp; {file} заменяется путем к аудио, текст должен печататься в stdout:
+# command = "whisper-cli -m /path/ggml-base.bin -l auto -nt -np -f {file}"
+# Или через API, совместимый с OpenAI (используется, если command не задан):
+# api_url = "https://api.openai.com/v1/audio/transcriptions"
+# api_key = ""
+model = "whisper-1"
+
+[summary]
+# Краткий пересказ заметки (кнопка "Кратко"). По умолчанию — локально: выбираются самые
+# содержательные предложения. Или языковой моделью через API, совместимый с OpenAI:
+# api_url = "http://localhost:11434/v1/chat/completions"
+# api_key = ""
+model = "gpt-4o-mini"
+sentences = 3
+
+[speech]
+# Чтение заметок вслух. По умолчанию — espeak-ng (Linux), say (macOS) или SAPI (Windows).
+# Текст подается команде на stdin, {rate} заменяется скоростью в словах в минуту:
+# command = "espeak-ng -v ru -s {rate} --stdin"
+
+[scanner]
+# Сканирование документов (чеки, бумаги) во вложения открытой заметки кнопкой "Сканировать".
+# По умолчанию — scanimage (SANE) в Linux и macOS, WIA в Windows. Команда сохраняет страницу
+# в файл {file} с расширением формата:
+# command = "scanimage --format=png --resolution 300 --output-file={file}.png"
+# Папка, куда сканер или МФУ кладет файлы: новые PDF и изображения из нее прикрепляются
+# к открытой заметке, а затем переносятся в подпапку imported. Пусто — выключено
+# hot_folder = "/home/user/Scans"
+
+[attachments]
+# Правила приема вложений, например для общей БД команды. Вложение, которое им не подходит,
+# не сохраняется ни из окна, ни из почты, ни из папки сканера.
+# Разрешенные типы MIME; "image/*" — любые изображения. Пусто — любые
+# allowed_types = ["image/*", "application/pdf", "text/plain"]
+# blocked_extensions = [".exe", ".bat", ".cmd", ".scr", ".js", ".vbs", ".msi"]
+# Предельный размер вложения в мегабайтах; 0 — без ограничения
+max_size_mb = 0
+# Проверка антивирусом перед сохранением; {file} — путь к файлу, код выхода 1 — найден вирус
+# scan_command = "clamscan --no-summary {file}"
+
+[mirror]
+# Копия всех заметок в markdown-файлах, например для версионирования в git. Пусто — выключено.
+# dir = "/home/user/Documents/gnote-mirror"
+# nightly — раз в сутки в time; on_save — еще и сразу после каждого изменения заметки
+mode = "nightly"
+time = "03:00"
+# Фиксировать каждое обновление копии коммитом в git (репозиторий создается в каталоге копии)
+git = false
+# Отправлять коммиты в удаленный репозиторий: имя (origin) или адрес; пусто — не отправлять
+# remote = "git@example.com:user/notes.git"
+
+[server]
+# Режим сервера: gnote --serve. Сервер рассылает изменения заметок подключенным окнам GNote,
+# чтобы несколько экземпляров, работающих с одной БД, сразу видели изменения друг друга.
+listen = "127.0.0.1:8765"
+# API gRPC для программ (схема api/gnote.proto): заметки, теги, вложения и поток изменений.
+# Принимает тот же ключ в метаданных authorization: Bearer. Пусто — не запускать
+grpc_listen = "127.0.0.1:8766"
+# Ключ доступа; клиенты передают его в заголовке Authorization: Bearer. Обязателен для gnote --serve
+token = ""
+# Страницы, которым разрешено подключаться к ленте изменений из браузера; остальные отклоняются.
+# Окна GNote принимаются всегда
+# allowed_origins = ["https://notes.example.com"]
+# Лента изменений сервера, к которой подключается окно заметок; пусто — не подключаться
+# changes_url = "ws://server:8765/changes"
+# RSS недавно измененных заметок: http://server:8765/feed.xml?notebook=работа&token=...
+# Без notebook — все заметки. Блокноты из public_notebooks доступны без ключа
+# public_notebooks = ["блог"]
+feed_items = 50
+
+[email]
+# Заметки из писем: новые письма в папке становятся заметками с тегом email
+# (тема — заголовок, текст — содержимое, приложенные файлы — вложения), а сами письма отмечаются прочитанными.
+# Лучше завести для этого отдельный ящик или папку. Пусто — выключено
+# imap = "imap.example.com:993"
+# false — подключаться без TLS и включать его командой STARTTLS (порт 143); открытым текстом пароль
+# отправляется только на этот же компьютер (localhost)
+tls = true
+# user = "notes@example.com"
+# password = ""
+folder = "INBOX"
+interval_minutes = 5
+
+[hooks]
+# Обработчики событий: команды или адреса вебхуков (http:// и https:// получают POST).
+# Команда получает JSON {"event", "note_id", "note"} на stdin и переменные GNOTE_EVENT и GNOTE_NOTE_ID.
+# Обработчики событий заметок только уведомляют: их вывод не записывается в заметку.
+# Секреты в "note" скрыты: заметки с тегом sensitive и фрагменты [sensitive]...[/sensitive].
+# note_created = ["notify-send 'GNote: новая заметка'"]
+# note_saved = ["https://example.com/gnote-webhook"]
+# note_deleted = []
+# before_export получает выгружаемый JSON; непустой вывод команды заменяет его, ошибка отменяет экспорт
+# before_export = ["jq ."]
+
+# Профили: gnote --profile work. Незаданные значения берутся из основных настроек.
+# [profiles.work.database]
+# name = "gnote_work"
+#
+# [profiles.personal.database]
+# url = "postgres://dima@localhost:5432/gnote_personal?sslmode=disable"
+`
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig записывает файл настроек во временный каталог и возвращает путь к нему
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		check   func(t *testing.T, cfg Config)
+		wantErr string
+	}{
+		{
+			name:    "значения по умолчанию",
+			content: "",
+			check: func(t *testing.T, cfg Config) {
+				if cfg.Database.Host != "localhost" || cfg.Database.Port != 5432 || cfg.UI.LinkPreviews {
+					t.Errorf("настройки по умолчанию: %+v, link_previews %v", cfg.Database, cfg.UI.LinkPreviews)
+				}
+			},
+		},
+		{
+			name:    "значения из файла",
+			content: "[database]\nhost = \"db.example.com\"\nport = 6432\n[ui]\ndensity = \"compact\"\n",
+			check: func(t *testing.T, cfg Config) {
+				if cfg.Database.Host != "db.example.com" || cfg.Database.Port != 6432 || cfg.UI.Density != DensityCompact {
+					t.Errorf("не применены значения из файла: %+v, %q", cfg.Database, cfg.UI.Density)
+				}
+				if cfg.Database.Name != "gnote_db" {
+					t.Errorf("незаданное в файле значение не взято по умолчанию: %q", cfg.Database.Name)
+				}
+			},
+		},
+		{
+			name:    "переменные окружения важнее файла",
+			content: "[database]\nhost = \"db.example.com\"\n",
+			env:     map[string]string{"DB_HOST": "env-host", "DB_PORT": "7000", "GNOTE_DB_URL": "postgres://u@h/db"},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.Database.Host != "env-host" || cfg.Database.Port != 7000 || cfg.Database.URL != "postgres://u@h/db" {
+					t.Errorf("не применены переменные окружения: %+v", cfg.Database)
+				}
+			},
+		},
+		{
+			name:    "файл со старым разделом sync",
+			content: "[sync]\nenabled = true\nserver_url = \"https://sync.example.com\"\n[ui]\ndensity = \"compact\"\n",
+			check: func(t *testing.T, cfg Config) {
+				if cfg.UI.Density != DensityCompact {
+					t.Errorf("настройки после раздела sync не применены: %q", cfg.UI.Density)
+				}
+			},
+		},
+		{name: "неверный DB_PORT", env: map[string]string{"DB_PORT": "пять"}, wantErr: "DB_PORT"},
+		{name: "синтаксическая ошибка", content: "[database\n", wantErr: "ошибка в файле настроек"},
+		{name: "неизвестное хранилище", content: "[storage]\nbackend = \"sqlite\"\n", wantErr: "sqlite"},
+		{name: "неверная плотность", content: "[ui]\ndensity = \"tiny\"\n", wantErr: "tiny"},
+		{name: "длинный заголовок", content: "[notes]\nmax_title_length = 100000\n", wantErr: "max_title_length"},
+		{name: "неверное время копии", content: "[mirror]\ntime = \"25:00\"\n", wantErr: "ЧЧ:ММ"},
+		{name: "пустая команда обработчика", content: "[hooks]\nnote_saved = [\"  \"]\n", wantErr: "[hooks]"},
+		{name: "незакрытая кавычка в обработчике", content: "[hooks]\nnote_created = [\"'notify-send\"]\n", wantErr: "кавычка"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"DATABASE_URL", "GNOTE_DB_URL", "DB_HOST", "DB_PORT"} {
+				t.Setenv(key, tt.env[key])
+			}
+			cfg, err := Load(writeConfig(t, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() ошибка = %v, ожидалась с %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load(): %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadWritesSample(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gnote", "config.toml")
+	if _, err := Load(path); err != nil {
+		t.Fatalf("Load() без файла: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("пример файла настроек не создан: %v", err)
+	}
+	// Созданный пример должен читаться без ошибок
+	if _, err := Load(path); err != nil {
+		t.Fatalf("Load() примера: %v", err)
+	}
+}
+
+func TestForProfile(t *testing.T) {
+	path := writeConfig(t, `
+[database]
+host = "main-host"
+name = "main_db"
+
+[profiles.work.database]
+host = "work-host"
+
+[profiles.work.ui]
+density = "compact"
+
+[profiles.broken.ui]
+density = "tiny"
+`)
+	for _, key := range []string{"DATABASE_URL", "GNOTE_DB_URL", "DB_HOST", "DB_NAME"} {
+		t.Setenv(key, "")
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := cfg.ProfileNames(); strings.Join(names, ",") != "broken,work" {
+		t.Errorf("ProfileNames() = %v", names)
+	}
+
+	tests := []struct {
+		profile     string
+		wantHost    string
+		wantName    string
+		wantDensity string
+		wantErr     bool
+	}{
+		{"", "main-host", "main_db", DensityComfortable, false},
+		{"work", "work-host", "main_db", DensityCompact, false}, // Незаданное в профиле берется из основных настроек
+		{"broken", "", "", "", true},
+		{"нет-такого", "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			profile, err := cfg.ForProfile(tt.profile)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ожидалась ошибка")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if profile.Database.Host != tt.wantHost || profile.Database.Name != tt.wantName ||
+				profile.UI.Density != tt.wantDensity || profile.Profile != tt.profile {
+				t.Errorf("ForProfile(%q) = %+v, %q, профиль %q", tt.profile, profile.Database, profile.UI.Density, profile.Profile)
+			}
+		})
+	}
+	// Профиль не меняет основные настройки
+	if cfg.Database.Host != "main-host" || cfg.UI.Density != DensityComfortable {
+		t.Errorf("основные настройки изменены: %+v", cfg.Database)
+	}
+}
+
+func TestProfileDataPaths(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		attachments string
+		cache       string
+	}{
+		{"основные настройки", Config{}, "/data/attachments", "/data/offline-cache.json"},
+		{"профиль", Config{Profile: "work"}, "/data/profiles/work/attachments", "/data/profiles/work/offline-cache.json"},
+		{"свой каталог данных", Config{Storage: StorageConfig{DataDir: "/srv/gnote"}, Profile: "work"},
+			"/srv/gnote/profiles/work/attachments", "/srv/gnote/profiles/work/offline-cache.json"},
+		{"свой каталог вложений", Config{Storage: StorageConfig{AttachmentsDir: "/files"}, Profile: "work"},
+			"/files", "/data/profiles/work/offline-cache.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.AttachmentsPath("/data"); got != tt.attachments {
+				t.Errorf("AttachmentsPath() = %q, ожидалось %q", got, tt.attachments)
+			}
+			if got := tt.cfg.OfflineCachePath("/data"); got != tt.cache {
+				t.Errorf("OfflineCachePath() = %q, ожидалось %q", got, tt.cache)
+			}
+		})
+	}
+}
+
+func TestMigrateFyneDirs(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"missing-id-1700000000/offline-cache.json":                "старый",
+		"missing-id-1700000000/attachments/1_a.png":               "",
+		"missing-id-1710000000/offline-cache.json":                "новый",
+		"missing-id-1710000000/profiles/work/offline-cache.json":  "работа",
+		"missing-id-1720000000/profiles/home/attachments/2_b.pdf": "",
+		"data/profiles/home/offline-cache.json":                   "уже есть",
+		"missing-id-1720000000/profiles/home/offline-cache.json":  "лишний",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old, err := MigrateFyneDirs(filepath.Join(root, "com.github.dmitryreaper.gnote"), filepath.Join(root, "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOld := []string{filepath.Join(root, "missing-id-1720000000"), filepath.Join(root, "missing-id-1700000000")}
+	if strings.Join(old, "\n") != strings.Join(wantOld, "\n") {
+		t.Errorf("каталоги с вложениями %q, ожидалось %q", old, wantOld)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"data/offline-cache.json", "новый"},
+		{"data/profiles/work/offline-cache.json", "работа"},
+		{"data/profiles/home/offline-cache.json", "уже есть"},
+		{"missing-id-1700000000/offline-cache.json", "старый"}, // Более новый кэш уже перенесен
+		{"missing-id-1720000000/profiles/home/offline-cache.json", "лишний"},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join(root, tt.path))
+		if err != nil {
+			t.Errorf("%s: %v", tt.path, err)
+			continue
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s = %q, ожидалось %q", tt.path, data, tt.want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+)
+
+// Каталоги GNote по спецификации XDG Base Directory: настройки, данные (вложения, офлайн-кэш),
+// кэш (временные копии вложений) и состояние (журнал). XDG_CONFIG_HOME, XDG_DATA_HOME,
+// XDG_CACHE_HOME и XDG_STATE_HOME меняют базовые каталоги; GNOTE_CONFIG_DIR, GNOTE_CACHE_DIR
+// и GNOTE_STATE_DIR задают каталоги GNote целиком, каталог данных — data_dir или GNOTE_DATA_DIR.
+// В песочнице Flatpak базовые каталоги указывают внутрь ~/.var/app, поэтому доступ к остальной
+// файловой системе не нужен. В переносном режиме (см. UsePortable) все каталоги лежат рядом с программой.
+
+const (
+	appDirName      = "gnote"
+	portableDirName = "gnote-portable" // Каталог переносного режима рядом с программой
+)
+
+// portableDir — корень переносного режима; пусто — каталоги XDG
+var portableDir string
+
+// UsePortable включает переносной режим: настройки, данные, кэш, журнал и настройки окон хранятся
+// в каталоге gnote-portable рядом с программой, например на флешке. Вызывается до создания приложения Fyne:
+// его настройки перенаправляются через XDG_CONFIG_HOME (Linux) и APPDATA (Windows).
+// Возвращает корень переносного каталога.
+func UsePortable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить путь к программе: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	dir := filepath.Join(filepath.Dir(exe), portableDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("не удалось создать переносной каталог: %w", err)
+	}
+	settings := filepath.Join(dir, "settings")
+	for _, env := range []string{"XDG_CONFIG_HOME", "APPDATA"} {
+		if err := os.Setenv(env, settings); err != nil {
+			return "", fmt.Errorf("не удалось перенаправить настройки окон: %w", err)
+		}
+	}
+	portableDir = dir
+	return dir, nil
+}
+
+// ConfigDir возвращает каталог настроек: ~/.config/gnote на Linux
+func ConfigDir() (string, error) {
+	if portableDir != "" {
+		return portableDir, nil
+	}
+	if dir := os.Getenv("GNOTE_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить каталог настроек: %w", err)
+	}
+	return filepath.Join(dir, appDirName), nil
+}
+
+// CacheDir возвращает каталог кэша: ~/.cache/gnote на Linux
+func CacheDir() (string, error) {
+	if portableDir != "" {
+		return filepath.Join(portableDir, "cache"), nil
+	}
+	if dir := os.Getenv("GNOTE_CACHE_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить каталог кэша: %w", err)
+	}
+	return filepath.Join(dir, appDirName), nil
+}
+
+// StateDir возвращает каталог состояния для журнала: ~/.local/state/gnote на Linux.
+// На системах без XDG журнал хранится в каталоге кэша.
+func StateDir() (string, error) {
+	if portableDir != "" {
+		return filepath.Join(portableDir, "logs"), nil
+	}
+	if dir := os.Getenv("GNOTE_STATE_DIR"); dir != "" {
+		return dir, nil
+	}
+	if !usesXDG() {
+		return CacheDir()
+	}
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// DataDir возвращает каталог данных приложения, если data_dir не задан: ~/.local/share/gnote на Linux.
+// legacy — каталог данных Fyne, где данные хранились раньше: пока в нем есть вложения или офлайн-кэш,
+// а каталога XDG еще нет, используется он, чтобы не потерять файлы вложений и неотправленные изменения.
+// На системах без XDG (Windows, macOS, мобильные) всегда используется legacy.
+func DataDir(legacy string) string {
+	if portableDir != "" {
+		return filepath.Join(portableDir, "data")
+	}
+	if !usesXDG() {
+		return legacy
+	}
+	dir, err := xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+	if err != nil {
+		return legacy
+	}
+	if !exists(dir) && hasLegacyData(legacy) {
+		return legacy
+	}
+	return dir
+}
+
+// OpenLogFile открывает журнал gnote.log в каталоге состояния. Журнал прошлого запуска
+// сохраняется как gnote.log.1, чтобы файл не рос бесконечно.
+func OpenLogFile() (*os.File, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("не удалось создать каталог журнала: %w", err)
+	}
+	path := filepath.Join(dir, "gnote.log")
+	if err := os.Rename(path, path+".1"); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("не удалось сохранить прошлый журнал: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть журнал: %w", err)
+	}
+	return f, nil
+}
+
+// usesXDG сообщает, принято ли в системе раскладывать файлы по каталогам XDG
+func usesXDG() bool {
+	switch runtime.GOOS {
+	case "windows", "darwin", "ios", "android":
+		return false
+	}
+	return true
+}
+
+// xdgDir возвращает каталог GNote в базовом каталоге из переменной env, а без нее — в home/fallback.
+// Относительный путь в переменной по спецификации XDG не учитывается.
+func xdgDir(env, fallback string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appDirName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить домашний каталог: %w", err)
+	}
+	return filepath.Join(home, fallback, appDirName), nil
+}
+
+// hasLegacyData сообщает, что в старом каталоге данных есть вложения, офлайн-кэш или данные профилей
+func hasLegacyData(dir string) bool {
+	for _, name := range []string{"attachments", "offline-cache.json", "profiles"} {
+		if exists(filepath.Join(dir, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrateFyneDirs переносит данные из каталогов, которые Fyne создавал рядом с fyneDir, пока у приложения
+// не было ID: missing-id-<время>, новый при каждом запуске. Офлайн-кэши (общий и профилей) переносятся
+// в dataDir, если там их еще нет, — в них могут быть неотправленные изменения; при нескольких старых
+// каталогах берется самый новый кэш. Файлы вложений остаются на месте, так как в БД записаны их полные пути.
+// Возвращает старые каталоги, в которых остались вложения.
+func MigrateFyneDirs(fyneDir, dataDir string) ([]string, error) {
+	old, err := filepath.Glob(filepath.Join(filepath.Dir(fyneDir), "missing-id-*"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(old)
+	slices.Reverse(old) // Сначала самые новые
+	var withAttachments []string
+	for _, dir := range old {
+		caches, _ := filepath.Glob(filepath.Join(dir, "profiles", "*", "offline-cache.json"))
+		for _, cache := range append(caches, filepath.Join(dir, "offline-cache.json")) {
+			rel, err := filepath.Rel(dir, cache)
+			if err != nil || !exists(cache) {
+				continue
+			}
+			dest := filepath.Join(dataDir, rel)
+			if exists(dest) {
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return withAttachments, fmt.Errorf("не удалось перенести офлайн-кэш %s: %w", cache, err)
+			}
+			if err := os.Rename(cache, dest); err != nil {
+				return withAttachments, fmt.Errorf("не удалось перенести офлайн-кэш %s: %w", cache, err)
+			}
+		}
+		attachments, _ := filepath.Glob(filepath.Join(dir, "profiles", "*", "attachments"))
+		if exists(filepath.Join(dir, "attachments")) || len(attachments) > 0 {
+			withAttachments = append(withAttachments, dir)
+		}
+	}
+	return withAttachments, nil
+}
+
+// exists сообщает, что файл или каталог существует
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
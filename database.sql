@@ -6,12 +6,22 @@ CREATE TABLE IF NOT EXISTS notes (
     content TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    reminder_at TIMESTAMP WITH TIME ZONE
+    reminder_at TIMESTAMP WITH TIME ZONE,
+    due_at TIMESTAMP WITH TIME ZONE, -- Срок выполнения задачи; в отличие от reminder_at не уведомляет
+    source_url TEXT NOT NULL DEFAULT '',
+    location_name VARCHAR(255) NOT NULL DEFAULT '',
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    is_favorite BOOLEAN NOT NULL DEFAULT FALSE,
+    priority SMALLINT NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 3), -- 0 — нет, 1 — низкий, 2 — средний, 3 — высокий
+    uid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid() -- Постоянный идентификатор для импорта и синхронизации между базами
 );
 
 CREATE TABLE IF NOT EXISTS tags (
     id SERIAL PRIMARY KEY,
-    name VARCHAR(255) UNIQUE NOT NULL
+    name VARCHAR(255) UNIQUE NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '', -- Цвет тега "#rrggbb"; пусто — без цвета
+    icon VARCHAR(16) NOT NULL DEFAULT '' -- Значок (эмодзи) перед именем тега
 );
 
 CREATE TABLE IF NOT EXISTS note_tags (
@@ -24,12 +34,123 @@ CREATE TABLE IF NOT EXISTS attachments (
     id SERIAL PRIMARY KEY,
     note_id INT NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
     filename VARCHAR(255) NOT NULL,
-    filepath VARCHAR(255) UNIQUE NOT NULL,
+    filepath VARCHAR(255) UNIQUE, -- NULL, если содержимое хранится в БД
     mimetype VARCHAR(255),
     size_bytes BIGINT,
-    uploaded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    uploaded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    data_oid OID, -- large object с содержимым вложения (режим attachments = "database")
+    attachment_text TEXT, -- Текст, извлеченный из вложения; NULL — еще не обработано
+    description TEXT NOT NULL DEFAULT '', -- Подпись к вложению, заданная пользователем
+    position INT NOT NULL DEFAULT 0, -- Место в списке вложений заметки; при равных — по времени прикрепления
+    source_url TEXT NOT NULL DEFAULT '' -- Адрес, с которого загружен файл; пусто для локальных файлов
 );
 
+-- Правила периодического создания заметок (например, еженедельный отчет по шаблону)
+CREATE TABLE IF NOT EXISTS recurring_rules (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL, -- Заголовок новой заметки; {date} и {week} заменяются датой и номером недели
+    template_note_id INT REFERENCES notes(id) ON DELETE SET NULL, -- Заметка-шаблон
+    tags TEXT[] NOT NULL DEFAULT '{}', -- Дополнительные теги новой заметки
+    frequency VARCHAR(16) NOT NULL, -- daily, weekly или monthly
+    day SMALLINT NOT NULL DEFAULT 0, -- День недели (0 — воскресенье) или число месяца
+    at_time VARCHAR(5) NOT NULL DEFAULT '09:00', -- Местное время создания ЧЧ:ММ
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_run_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Сниппеты: сокращения, которые при вводе в редакторе заменяются расшифровкой
+CREATE TABLE IF NOT EXISTS snippets (
+    id SERIAL PRIMARY KEY,
+    abbreviation VARCHAR(32) UNIQUE NOT NULL, -- Слово без пробелов, например "адр"
+    expansion TEXT NOT NULL -- Текст подстановки; {date}, {week} и {cursor} заменяются при вводе
+);
+
+-- Снимки заметок до массовой замены текста: по ним замену можно отменить
+CREATE TABLE IF NOT EXISTS replace_snapshots (
+    id SERIAL PRIMARY KEY,
+    description TEXT NOT NULL, -- Что заменялось, например «Проект А» → «Проект Б»
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS replace_snapshot_notes (
+    snapshot_id INT NOT NULL REFERENCES replace_snapshots(id) ON DELETE CASCADE,
+    note_id INT NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL, -- Заголовок и содержимое до замены
+    content TEXT NOT NULL,
+    PRIMARY KEY (snapshot_id, note_id)
+);
+
+-- Журнал изменений: кто и когда создал, изменил, удалил или выгрузил заметки
+CREATE TABLE IF NOT EXISTS activity_log (
+    id BIGSERIAL PRIMARY KEY,
+    at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    action VARCHAR(16) NOT NULL, -- create, update, delete или export
+    note_id INT NOT NULL DEFAULT 0, -- Без внешнего ключа: записи об удаленных заметках остаются; 0 — несколько заметок
+    note_title VARCHAR(255) NOT NULL DEFAULT '', -- Заголовок на момент действия
+    details TEXT NOT NULL DEFAULT '', -- Измененные поля, файл экспорта и т. п.
+    actor VARCHAR(255) NOT NULL DEFAULT '' -- Пользователь и компьютер, с которого внесено изменение
+);
+
+-- Обновление существующих баз: до индексов и триггеров, которые используют новые столбцы
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS source_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS location_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS is_favorite BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 3);
+ALTER TABLE attachments ALTER COLUMN filepath DROP NOT NULL;
+ALTER TABLE attachments ADD COLUMN IF NOT EXISTS data_oid OID;
+ALTER TABLE attachments ADD COLUMN IF NOT EXISTS attachment_text TEXT;
+ALTER TABLE attachments ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE attachments ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+ALTER TABLE attachments ADD COLUMN IF NOT EXISTS source_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS color VARCHAR(7) NOT NULL DEFAULT '';
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS icon VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS due_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS uid UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX IF NOT EXISTS notes_uid_key ON notes (uid);
+
 CREATE INDEX IF NOT EXISTS idx_notes_created_at ON notes (created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_notes_reminder_at ON notes (reminder_at);
 CREATE INDEX IF NOT EXISTS idx_attachments_note_id ON attachments (note_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_filename ON attachments (lower(filename)); -- Поиск file:
+CREATE INDEX IF NOT EXISTS idx_attachments_mimetype ON attachments (mimetype); -- Поиск has:
+CREATE INDEX IF NOT EXISTS idx_activity_log_at ON activity_log (at DESC);
+
+-- Уведомления об изменениях для открытых окон GNote (LISTEN gnote_changes).
+-- source — application_name подключения: по нему экземпляр пропускает свои изменения.
+CREATE OR REPLACE FUNCTION gnote_notify_note() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('gnote_changes', json_build_object(
+        'kind', CASE TG_OP WHEN 'INSERT' THEN 'note-created' WHEN 'UPDATE' THEN 'note-updated' ELSE 'note-deleted' END,
+        'note_id', CASE TG_OP WHEN 'DELETE' THEN OLD.id ELSE NEW.id END,
+        'source', current_setting('application_name'))::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION gnote_notify_attachment() RETURNS trigger AS $$
+DECLARE
+    changed attachments%ROWTYPE;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed := OLD;
+    ELSE
+        changed := NEW;
+    END IF;
+    PERFORM pg_notify('gnote_changes', json_build_object(
+        -- Изменение вложения — распознанный текст, имя, описание или порядок; заметку нужно перечитать
+        'kind', CASE TG_OP WHEN 'INSERT' THEN 'attachment-created' WHEN 'UPDATE' THEN 'note-updated' ELSE 'attachment-deleted' END,
+        'note_id', changed.note_id,
+        'attachment_id', changed.id,
+        'source', current_setting('application_name'))::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS notes_notify ON notes;
+CREATE TRIGGER notes_notify AFTER INSERT OR UPDATE OR DELETE ON notes
+    FOR EACH ROW EXECUTE FUNCTION gnote_notify_note();
+DROP TRIGGER IF EXISTS attachments_notify ON attachments;
+CREATE TRIGGER attachments_notify AFTER INSERT OR UPDATE OF attachment_text, filename, description, position OR DELETE ON attachments
+    FOR EACH ROW EXECUTE FUNCTION gnote_notify_attachment();
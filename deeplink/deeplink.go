@@ -0,0 +1,85 @@
+package deeplink
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Scheme — схема ссылок на заметки (gnote://note/{id})
+const Scheme = "gnote"
+
+// NoteURL возвращает ссылку на заметку с указанным ID
+func NoteURL(id int) string {
+	return fmt.Sprintf("%s://note/%d", Scheme, id)
+}
+
+// ParseNoteID извлекает ID заметки из ссылки вида gnote://note/{id}
+func ParseNoteID(raw string) (int, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return 0, fmt.Errorf("некорректная ссылка '%s': %w", raw, err)
+	}
+	if u.Scheme != Scheme {
+		return 0, fmt.Errorf("неподдерживаемая схема ссылки '%s'", u.Scheme)
+	}
+	if u.Host != "note" {
+		return 0, fmt.Errorf("неподдерживаемый тип ссылки '%s'", u.Host)
+	}
+	id, err := strconv.Atoi(strings.Trim(u.Path, "/"))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("некорректный ID заметки в ссылке '%s'", raw)
+	}
+	return id, nil
+}
+
+// desktopEntry — шаблон .desktop-файла, регистрирующего обработчик схемы
+const desktopEntry = `[Desktop Entry]
+Type=Application
+Name=GNote
+Exec="%s" %%u
+Terminal=false
+NoDisplay=true
+MimeType=x-scheme-handler/%s;
+`
+
+// Register регистрирует текущий исполняемый файл как обработчик ссылок gnote:// (только Linux/XDG)
+func Register() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("регистрация схемы %s:// поддерживается только в Linux", Scheme)
+	}
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("не удалось определить путь к исполняемому файлу: %w", err)
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("не удалось определить домашнюю директорию: %w", err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	appsDir := filepath.Join(dataHome, "applications")
+	if err := os.MkdirAll(appsDir, 0755); err != nil {
+		return fmt.Errorf("не удалось создать директорию '%s': %w", appsDir, err)
+	}
+
+	desktopName := "gnote-url-handler.desktop"
+	content := fmt.Sprintf(desktopEntry, execPath, Scheme)
+	if err := os.WriteFile(filepath.Join(appsDir, desktopName), []byte(content), 0644); err != nil {
+		return fmt.Errorf("не удалось записать .desktop файл: %w", err)
+	}
+
+	cmd := exec.Command("xdg-mime", "default", desktopName, "x-scheme-handler/"+Scheme)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ошибка xdg-mime: %w (%s)", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package deeplink
+
+import "testing"
+
+func TestParseNoteID(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    int
+		wantErr bool
+	}{
+		{"gnote://note/42", 42, false},
+		{"gnote://note/42/", 42, false},
+		{"  gnote://note/7\n", 7, false},
+		{NoteURL(1234), 1234, false},
+		{"GNOTE://note/5", 5, false}, // Схема URL не зависит от регистра
+		{"http://note/42", 0, true},
+		{"gnote://tag/42", 0, true},
+		{"gnote://note/", 0, true},
+		{"gnote://note/abc", 0, true},
+		{"gnote://note/0", 0, true},
+		{"gnote://note/-3", 0, true},
+		{"gnote://note/1/2", 0, true},
+		{"", 0, true},
+		{"%zz", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseNoteID(tt.raw)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseNoteID(%q) = %d, %v; ожидалось %d, ошибка: %v", tt.raw, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package deeplink
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// instance — имя профиля, у экземпляров разных профилей разные сокеты
+var instance string
+
+// SetInstance задает имя профиля запущенного экземпляра; вызывается до Send и Listen
+func SetInstance(name string) {
+	instance = name
+}
+
+// socketPath возвращает путь к сокету, через который запущенный экземпляр принимает ссылки
+func socketPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	if instance != "" {
+		return filepath.Join(dir, fmt.Sprintf("gnote-%d-%s.sock", os.Getuid(), instance))
+	}
+	return filepath.Join(dir, fmt.Sprintf("gnote-%d.sock", os.Getuid()))
+}
+
+// Send передает ссылку уже запущенному экземпляру приложения.
+// Возвращает ошибку, если запущенного экземпляра нет.
+func Send(link string) error {
+	conn, err := net.DialTimeout("unix", socketPath(), time.Second)
+	if err != nil {
+		return fmt.Errorf("запущенный экземпляр не найден: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := fmt.Fprintln(conn, link); err != nil {
+		return fmt.Errorf("не удалось передать ссылку: %w", err)
+	}
+	return nil
+}
+
+// Listener принимает ссылки от других экземпляров приложения
+type Listener struct {
+	ln   net.Listener
+	path string
+}
+
+// Listen начинает принимать ссылки; handler вызывается в отдельной горутине для каждой ссылки
+func Listen(handler func(link string)) (*Listener, error) {
+	path := socketPath()
+	// Если на сокете кто-то отвечает, значит уже запущен другой экземпляр
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("другой экземпляр уже принимает ссылки на '%s'", path)
+	}
+	// Иначе сокет остался от завершившегося процесса
+	os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть сокет '%s': %w", path, err)
+	}
+	l := &Listener{ln: ln, path: path}
+	go l.serve(handler)
+	log.Printf("Прием ссылок %s:// на сокете %s", Scheme, path)
+	return l, nil
+}
+
+// serve принимает соединения до закрытия слушателя
+func (l *Listener) serve(handler func(link string)) {
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			return // Слушатель закрыт
+		}
+		go func(c net.Conn) {
+			defer c.Close()
+			c.SetReadDeadline(time.Now().Add(5 * time.Second))
+			line, err := bufio.NewReader(c).ReadString('\n')
+			if err != nil && line == "" {
+				log.Printf("Ошибка при чтении ссылки: %v", err)
+				return
+			}
+			handler(strings.TrimSpace(line))
+		}(conn)
+	}
+}
+
+// Close прекращает прием ссылок и удаляет сокет
+func (l *Listener) Close() error {
+	err := l.ln.Close()
+	os.Remove(l.path)
+	return err
+}
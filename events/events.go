@@ -0,0 +1,98 @@
+package events
+
+import (
+	"sync"
+
+	"GNote/models"
+)
+
+// Kind — тип события изменения данных
+type Kind int
+
+const (
+	NoteCreated       Kind = iota // Создана заметка
+	NoteUpdated                   // Изменена заметка (Note может быть nil, если изменена часть полей)
+	NoteDeleted                   // Удалена заметка
+	AttachmentCreated             // К заметке добавлено вложение
+	AttachmentDeleted             // Вложение удалено
+)
+
+// String возвращает название типа события для логов
+func (k Kind) String() string {
+	switch k {
+	case NoteCreated:
+		return "note-created"
+	case NoteUpdated:
+		return "note-updated"
+	case NoteDeleted:
+		return "note-deleted"
+	case AttachmentCreated:
+		return "attachment-created"
+	case AttachmentDeleted:
+		return "attachment-deleted"
+	}
+	return "unknown"
+}
+
+// ParseKind возвращает тип события по названию из String
+func ParseKind(name string) (Kind, bool) {
+	for k := NoteCreated; k <= AttachmentDeleted; k++ {
+		if k.String() == name {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// Event описывает изменение данных в хранилище
+type Event struct {
+	Kind         Kind
+	NoteID       int
+	Note         *models.Note       // Новое состояние заметки (для создания и изменения)
+	AttachmentID int                // ID вложения (для событий вложений)
+	Attachment   *models.Attachment // Вложение (для создания)
+	Remote       bool               // Изменение сделано другим экземпляром GNote и пришло по ленте изменений
+}
+
+// Handler обрабатывает событие. Вызывается в горутине, опубликовавшей событие.
+type Handler func(e Event)
+
+// Bus — простая шина событий «издатель-подписчик»
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[int]Handler
+	nextID int
+}
+
+// NewBus создает пустую шину событий
+func NewBus() *Bus {
+	return &Bus{subs: make(map[int]Handler)}
+}
+
+// Subscribe подписывает обработчик на все события и возвращает функцию отписки
+func (b *Bus) Subscribe(h Handler) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = h
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs, id)
+	}
+}
+
+// Publish синхронно передает событие всем подписчикам
+func (b *Bus) Publish(e Event) {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.subs))
+	for _, h := range b.subs {
+		handlers = append(handlers, h)
+	}
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(e)
+	}
+}
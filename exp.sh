@@ -4,3 +4,13 @@ export DB_USER=dima
 export DB_PASSWORD=
 export DB_NAME=notes_db
 export DB_SSLMODE=disable
+# Управляемый PostgreSQL с проверкой сертификата сервера (и клиента, если нужен):
+# export DB_SSLMODE=verify-full
+# export DB_SSLROOTCERT=$HOME/.postgresql/root.crt
+# export DB_SSLCERT=$HOME/.postgresql/postgresql.crt
+# export DB_SSLKEY=$HOME/.postgresql/postgresql.key
+# Вместо DB_* можно задать одну строку подключения:
+# export DATABASE_URL="postgres://dima@localhost:5432/notes_db?sslmode=disable"
+
+# export GNOTE_WEATHER_URL="https://wttr.in/{city}?format=3&lang=ru"
+# export GNOTE_WEATHER_CITY=Moscow
@@ -0,0 +1,122 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// FilePlaceholder в команде заменяется путем к файлу
+const FilePlaceholder = "{file}"
+
+// Command — команда внешней программы для файла: tesseract для изображений, whisper.cpp для аудио,
+// антивирус для вложений, программа сканирования и т.п. {file} в аргументах заменяется путем к файлу.
+type Command struct {
+	args []string
+}
+
+// ParseCommand разбирает строку команды на аргументы. Аргумент с пробелами заключается в кавычки,
+// например "C:\Program Files\Tesseract-OCR\tesseract.exe" {file} stdout; обратная косая черта
+// не экранирует, чтобы пути Windows записывались как есть.
+func ParseCommand(command string) (*Command, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("в команде %s не закрыта кавычка %c", command, quote)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("команда не задана")
+	}
+	return &Command{args: args}, nil
+}
+
+// CommandOf создает команду из готовых аргументов
+func CommandOf(args ...string) *Command {
+	return &Command{args: args}
+}
+
+// New разбирает строку команды. Если в ней нет {file}, путь к файлу добавляется последним аргументом.
+func New(command string) (*Command, error) {
+	c, err := ParseCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	if !c.HasFile() {
+		c.args = append(c.args, FilePlaceholder)
+	}
+	return c, nil
+}
+
+// HasFile сообщает, что в аргументах команды есть {file}
+func (c *Command) HasFile() bool {
+	for _, arg := range c.args {
+		if strings.Contains(arg, FilePlaceholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// Args возвращает копию аргументов команды вместе с программой, без замены {file}
+func (c *Command) Args() []string {
+	return slices.Clone(c.args)
+}
+
+// Name возвращает программу команды для сообщений об ошибках
+func (c *Command) Name() string {
+	return c.args[0]
+}
+
+// Cmd подготавливает запуск команды для файла path; отмена ctx завершает программу
+func (c *Command) Cmd(ctx context.Context, path string) *exec.Cmd {
+	args := make([]string, len(c.args))
+	for i, arg := range c.args {
+		args[i] = strings.ReplaceAll(arg, FilePlaceholder, path)
+	}
+	return exec.CommandContext(ctx, args[0], args[1:]...)
+}
+
+// Extract запускает команду для файла path и возвращает текст, напечатанный ею в stdout
+func (c *Command) Extract(ctx context.Context, path string) (string, error) {
+	cmd := c.Cmd(ctx, path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("команда %s завершилась с ошибкой: %w: %s", c.Name(), err, msg)
+		}
+		return "", fmt.Errorf("команда %s завершилась с ошибкой: %w", c.Name(), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,84 @@
+package extract
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+		wantErr bool
+	}{
+		{"tesseract {file} stdout -l rus+eng", []string{"tesseract", "{file}", "stdout", "-l", "rus+eng"}, false},
+		{"  clamscan\t--no-summary  ", []string{"clamscan", "--no-summary"}, false},
+		{`"C:\Program Files\ClamAV\clamscan.exe" {file}`, []string{`C:\Program Files\ClamAV\clamscan.exe`, "{file}"}, false},
+		{`/opt/my tools/ocr '{file}'`, []string{"/opt/my", "tools/ocr", "{file}"}, false},
+		{`'/opt/my tools/ocr' --lang="rus eng"`, []string{"/opt/my tools/ocr", "--lang=rus eng"}, false},
+		{`whisper "" {file}`, []string{"whisper", "", "{file}"}, false}, // Пустой аргумент в кавычках сохраняется
+		{`ocr "{file}`, nil, true},
+		{"", nil, true},
+		{"   ", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			c, err := ParseCommand(tt.command)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseCommand() = %q, ожидалась ошибка", c.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(c.args, tt.want) {
+				t.Errorf("ParseCommand() = %q, ожидалось %q", c.args, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAddsFile(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"tesseract {file} stdout", []string{"tesseract", "{file}", "stdout"}},
+		{"clamscan --no-summary", []string{"clamscan", "--no-summary", "{file}"}},
+		{"scanimage --output-file={file}.png", []string{"scanimage", "--output-file={file}.png"}},
+	}
+	for _, tt := range tests {
+		c, err := New(tt.command)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(c.args, tt.want) {
+			t.Errorf("New(%q) = %q, ожидалось %q", tt.command, c.args, tt.want)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("нужен sh")
+	}
+	c, err := New(`sh -c 'echo "  $0  "'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := c.Extract(context.Background(), "/tmp/путь с пробелами.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "/tmp/путь с пробелами.png" {
+		t.Errorf("Extract() = %q", text)
+	}
+	failing, _ := New(`sh -c 'echo сбой >&2; exit 3'`)
+	if _, err := failing.Extract(context.Background(), "x"); err == nil {
+		t.Error("Extract() для завершившейся с ошибкой команды: ожидалась ошибка")
+	}
+}
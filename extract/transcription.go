@@ -0,0 +1,90 @@
+package extract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TranscriptionAPI расшифровывает аудио через API, совместимый с OpenAI /v1/audio/transcriptions
+// (OpenAI, whisper.cpp server, faster-whisper-server и т.п.)
+type TranscriptionAPI struct {
+	URL    string // Полный адрес метода, например https://api.openai.com/v1/audio/transcriptions
+	Key    string // Ключ API; пусто — без авторизации (локальный сервер)
+	Model  string // Название модели, например whisper-1
+	Client *http.Client
+}
+
+// Extract отправляет аудиофайл path и возвращает текст расшифровки.
+// Файл передается потоком, не загружаясь в память целиком.
+func (a *TranscriptionAPI) Extract(ctx context.Context, path string) (string, error) {
+	client := a.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Minute}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("не удалось открыть аудиофайл: %w", err)
+	}
+	defer f.Close()
+
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeTranscriptionForm(form, a.Model, filepath.Base(path), f))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, body)
+	if err != nil {
+		body.Close()
+		return "", fmt.Errorf("некорректный адрес API расшифровки: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if a.Key != "" {
+		req.Header.Set("Authorization", "Bearer "+a.Key)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ошибка запроса к API расшифровки: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("API расшифровки вернул статус %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("ошибка чтения ответа API расшифровки: %w", err)
+	}
+	return strings.TrimSpace(result.Text), nil
+}
+
+// writeTranscriptionForm записывает поля запроса расшифровки и содержимое файла
+func writeTranscriptionForm(form *multipart.Writer, model, filename string, file io.Reader) error {
+	if model != "" {
+		if err := form.WriteField("model", model); err != nil {
+			return err
+		}
+	}
+	if err := form.WriteField("response_format", "json"); err != nil {
+		return err
+	}
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	return form.Close()
+}
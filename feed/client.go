@@ -0,0 +1,147 @@
+package feed
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"GNote/events"
+	"GNote/storage"
+)
+
+// Пауза перед повторным подключением к ленте растет от минимальной до максимальной
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+)
+
+// Client подключает шину событий профиля к ленте изменений сервера: отправляет свои изменения
+// и публикует в шину изменения других экземпляров с отметкой Remote
+type Client struct {
+	url   string
+	token string
+	store storage.Store // Для загрузки заметок, измененных другими экземплярами
+	bus   *events.Bus
+
+	out  chan Message
+	done chan struct{}
+}
+
+// Start подключается к ленте url в фоне и переподключается при обрыве связи.
+// store — хранилище без публикации событий, bus — шина профиля. Возвращает функцию остановки.
+func Start(url, token string, store storage.Store, bus *events.Bus) func() {
+	c := &Client{
+		url:   url,
+		token: token,
+		store: store,
+		bus:   bus,
+		out:   make(chan Message, sendBuffer),
+		done:  make(chan struct{}),
+	}
+	unsubscribe := bus.Subscribe(c.enqueue)
+	go c.run()
+	return func() {
+		unsubscribe()
+		close(c.done)
+	}
+}
+
+// enqueue ставит свое изменение в очередь отправки; пришедшие из ленты не отправляются обратно
+func (c *Client) enqueue(e events.Event) {
+	if e.Remote {
+		return
+	}
+	select {
+	case c.out <- messageFromEvent(e):
+	default:
+		log.Printf("Лента изменений: очередь отправки заполнена, событие %s пропущено", e.Kind)
+	}
+}
+
+// run подключается к ленте, пока клиент не остановлен
+func (c *Client) run() {
+	delay := minRetryDelay
+	for {
+		ws, err := c.dial()
+		if err != nil {
+			log.Printf("Не удалось подключиться к ленте изменений %s: %v", c.url, err)
+		} else {
+			log.Printf("Подключено к ленте изменений %s", c.url)
+			delay = minRetryDelay
+			if !c.serve(ws) {
+				return
+			}
+			log.Printf("Связь с лентой изменений %s потеряна", c.url)
+		}
+		select {
+		case <-c.done:
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// ClientOrigin — заголовок Origin подключений окон GNote. Без Origin x/net/websocket не подключается,
+// а браузер не позволит странице выдать себя за gnote://: ее Origin — http(s) или null.
+const ClientOrigin = "gnote://window"
+
+// dial открывает подключение к ленте с ключом доступа
+func (c *Client) dial() (*websocket.Conn, error) {
+	cfg, err := websocket.NewConfig(c.url, ClientOrigin)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		cfg.Header = http.Header{"Authorization": {"Bearer " + c.token}}
+	}
+	cfg.Dialer = &net.Dialer{Timeout: 10 * time.Second}
+	return websocket.DialConfig(cfg)
+}
+
+// serve отправляет свои изменения и принимает чужие, пока подключение живо.
+// Возвращает false, если клиент остановлен.
+func (c *Client) serve(ws *websocket.Conn) bool {
+	defer ws.Close()
+	received := make(chan struct{})
+	go func() {
+		defer close(received)
+		for {
+			var msg Message
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				return
+			}
+			c.apply(msg)
+		}
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			return false
+		case <-received:
+			return true
+		case msg := <-c.out:
+			if err := websocket.JSON.Send(ws, msg); err != nil {
+				select {
+				case c.out <- msg: // Отправим после переподключения
+				default:
+				}
+				return true
+			}
+		}
+	}
+}
+
+// apply публикует изменение другого экземпляра в шину
+func (c *Client) apply(msg Message) {
+	e, ok := msg.event()
+	if !ok {
+		log.Printf("Лента изменений: неизвестное событие %q", msg.Kind)
+		return
+	}
+	storage.PublishRemote(c.store, c.bus, e)
+}
@@ -0,0 +1,147 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+
+	"golang.org/x/net/websocket"
+
+	"GNote/events"
+)
+
+// sendBuffer — сколько сообщений ждут отправки одному подключению; лишние пропускаются
+const sendBuffer = 256
+
+// Hub — лента изменений режима сервера: рассылает каждое изменение, полученное от одного
+// подключенного экземпляра GNote, всем остальным
+type Hub struct {
+	token   string
+	origins []string // Разрешенные значения заголовка Origin
+
+	mu       sync.Mutex
+	conns    map[*websocket.Conn]chan Message
+	watchers map[chan events.Event]struct{} // Подписчики Watch внутри сервера
+}
+
+// NewHub создает ленту изменений; подключения без ключа token отклоняются (пустой — доступ открыт).
+// Подключения с заголовком Origin, кроме ClientOrigin окон GNote, принимаются, только если он есть в origins.
+func NewHub(token string, origins []string) *Hub {
+	return &Hub{token: token, origins: origins, conns: make(map[*websocket.Conn]chan Message), watchers: make(map[chan events.Event]struct{})}
+}
+
+// Handler возвращает обработчик WebSocket-подключений к ленте
+func (h *Hub) Handler() http.Handler {
+	return websocket.Server{Handshake: h.handshake, Handler: h.serve}
+}
+
+// Publish рассылает событие, случившееся на сервере, всем подключениям
+func (h *Hub) Publish(e events.Event) {
+	h.broadcast(messageFromEvent(e), nil)
+}
+
+// Watch подписывается на все изменения ленты: и полученные от подключений, и опубликованные Publish.
+// События приходят без состояния заметки, как от других экземпляров; stop отменяет подписку.
+func (h *Hub) Watch() (changes <-chan events.Event, stop func()) {
+	ch := make(chan events.Event, sendBuffer)
+	h.mu.Lock()
+	h.watchers[ch] = struct{}{}
+	h.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.watchers, ch)
+			h.mu.Unlock()
+		})
+	}
+}
+
+// Clients возвращает количество подключенных экземпляров
+func (h *Hub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns)
+}
+
+// handshake проверяет ключ доступа и источник. Окна GNote передают Origin ClientOrigin, другие программы
+// обычно не передают его вовсе, а браузер передает адрес страницы: чужая страница, открытая на этом
+// компьютере, не должна подключаться к ленте от имени пользователя.
+func (h *Hub) handshake(_ *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin != "" && origin != ClientOrigin && !slices.Contains(h.origins, origin) {
+		return fmt.Errorf("источник %s не разрешен", origin)
+	}
+	if !Authorized(r, h.token) {
+		return errors.New("неверный ключ доступа")
+	}
+	return nil
+}
+
+// serve обслуживает подключение: принимает изменения и отправляет изменения других экземпляров
+func (h *Hub) serve(ws *websocket.Conn) {
+	out := make(chan Message, sendBuffer)
+	h.mu.Lock()
+	h.conns[ws] = out
+	h.mu.Unlock()
+	log.Printf("К ленте изменений подключен %s", ws.Request().RemoteAddr)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range out {
+			if err := websocket.JSON.Send(ws, msg); err != nil {
+				ws.Close() // Чтение ниже завершится с ошибкой
+				return
+			}
+		}
+	}()
+
+	for {
+		var msg Message
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			break
+		}
+		if _, ok := msg.event(); !ok {
+			log.Printf("Лента изменений: неизвестное событие %q от %s", msg.Kind, ws.Request().RemoteAddr)
+			continue
+		}
+		h.broadcast(msg, ws)
+	}
+
+	h.mu.Lock()
+	delete(h.conns, ws)
+	h.mu.Unlock()
+	close(out)
+	<-done
+	ws.Close()
+	log.Printf("От ленты изменений отключен %s", ws.Request().RemoteAddr)
+}
+
+// broadcast ставит сообщение в очередь всем подключениям, кроме from, и подписчикам Watch
+func (h *Hub) broadcast(msg Message, from *websocket.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if e, ok := msg.event(); ok {
+		for ch := range h.watchers {
+			select {
+			case ch <- e:
+			default:
+				log.Printf("Лента изменений: подписчик не успевает принимать изменения, событие %s пропущено", msg.Kind)
+			}
+		}
+	}
+	for ws, out := range h.conns {
+		if ws == from {
+			continue
+		}
+		select {
+		case out <- msg:
+		default:
+			log.Printf("Лента изменений: %s не успевает принимать изменения, событие %s пропущено", ws.Request().RemoteAddr, msg.Kind)
+		}
+	}
+}
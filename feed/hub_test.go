@@ -0,0 +1,134 @@
+package feed
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"GNote/events"
+)
+
+func TestHubHandshake(t *testing.T) {
+	hub := NewHub("секрет", []string{"https://notes.example.com"})
+	tests := []struct {
+		name   string
+		origin string
+		auth   string
+		ok     bool
+	}{
+		{"окно GNote с ключом", ClientOrigin, "Bearer секрет", true},
+		{"окно GNote без ключа", ClientOrigin, "", false},
+		{"программа без Origin с ключом", "", "Bearer секрет", true},
+		{"без ключа", "", "", false},
+		{"неверный ключ", "", "Bearer другой", false},
+		{"разрешенная страница", "https://notes.example.com", "Bearer секрет", true},
+		{"чужая страница с ключом", "https://evil.example", "Bearer секрет", false},
+		{"чужая страница без ключа", "https://evil.example", "", false},
+		{"страница на этом компьютере", "http://localhost:3000", "Bearer секрет", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/changes", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			if err := hub.handshake(nil, r); (err == nil) != tt.ok {
+				t.Errorf("handshake() = %v, ожидалось разрешение: %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+// waitFor ждет выполнения cond не дольше нескольких секунд
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("не дождались: %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// received собирает события шины, пришедшие из ленты
+func received(bus *events.Bus) func() []events.Event {
+	ch := make(chan events.Event, 16)
+	bus.Subscribe(func(e events.Event) {
+		if e.Remote {
+			ch <- e
+		}
+	})
+	var got []events.Event
+	return func() []events.Event {
+		for {
+			select {
+			case e := <-ch:
+				got = append(got, e)
+			default:
+				return got
+			}
+		}
+	}
+}
+
+func TestClientHubRoundTrip(t *testing.T) {
+	hub := NewHub("секрет", nil) // allowed_origins по умолчанию пуст
+	server := httptest.NewServer(hub.Handler())
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	busA, busB := events.NewBus(), events.NewBus()
+	gotA, gotB := received(busA), received(busB)
+	watched, stopWatch := hub.Watch()
+	stopA := Start(url, "секрет", nil, busA)
+	defer stopA()
+	stopB := Start(url, "секрет", nil, busB)
+	defer stopB()
+	waitFor(t, "подключения двух окон", func() bool { return hub.Clients() == 2 })
+
+	// Изменение одного окна приходит другому, но не возвращается отправителю
+	busA.Publish(events.Event{Kind: events.NoteDeleted, NoteID: 7})
+	waitFor(t, "изменения от другого окна", func() bool { return len(gotB()) == 1 })
+	if e := gotB()[0]; e.Kind != events.NoteDeleted || e.NoteID != 7 {
+		t.Errorf("получено %+v", e)
+	}
+
+	// Изменение на сервере получают все окна
+	hub.Publish(events.Event{Kind: events.NoteDeleted, NoteID: 8})
+	waitFor(t, "изменения с сервера", func() bool { return len(gotA()) == 1 && len(gotB()) == 2 })
+	if e := gotA()[0]; e.NoteID != 8 {
+		t.Errorf("окно-отправитель получило %+v", e)
+	}
+
+	// Подписчик Watch получает и изменения окон, и изменения сервера
+	for _, want := range []int{7, 8} {
+		select {
+		case e := <-watched:
+			if e.Kind != events.NoteDeleted || e.NoteID != want || !e.Remote {
+				t.Errorf("Watch: получено %+v, ожидалось удаление заметки %d", e, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Watch: не дождались изменения заметки %d", want)
+		}
+	}
+	stopWatch()
+	hub.Publish(events.Event{Kind: events.NoteDeleted, NoteID: 9})
+	select {
+	case e := <-watched:
+		t.Errorf("Watch после отписки: получено %+v", e)
+	default:
+	}
+
+	// Окно с неверным ключом не подключается
+	stopC := Start(url, "другой", nil, events.NewBus())
+	defer stopC()
+	time.Sleep(200 * time.Millisecond)
+	if n := hub.Clients(); n != 2 {
+		t.Errorf("подключений %d, ожидалось 2", n)
+	}
+}
@@ -0,0 +1,46 @@
+package feed
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"GNote/events"
+	"GNote/models"
+)
+
+// Message — событие изменения в ленте. Передается в JSON; сама заметка не передается,
+// клиенты работают с той же БД и загружают ее сами.
+type Message struct {
+	Kind         string             `json:"kind"` // Название типа события, см. events.Kind.String
+	NoteID       int                `json:"note_id,omitempty"`
+	AttachmentID int                `json:"attachment_id,omitempty"`
+	Attachment   *models.Attachment `json:"attachment,omitempty"`
+}
+
+// messageFromEvent возвращает сообщение ленты о событии шины
+func messageFromEvent(e events.Event) Message {
+	return Message{Kind: e.Kind.String(), NoteID: e.NoteID, AttachmentID: e.AttachmentID, Attachment: e.Attachment}
+}
+
+// event возвращает событие шины из сообщения; false — неизвестный тип события
+func (m Message) event() (events.Event, bool) {
+	kind, ok := events.ParseKind(m.Kind)
+	if !ok {
+		return events.Event{}, false
+	}
+	return events.Event{Kind: kind, NoteID: m.NoteID, AttachmentID: m.AttachmentID, Attachment: m.Attachment, Remote: true}, true
+}
+
+// Authorized сообщает, что запрос передал ключ доступа token в заголовке Authorization: Bearer
+// или в параметре token. Пустой token — доступ открыт.
+func Authorized(r *http.Request, token string) bool {
+	if token == "" {
+		return true
+	}
+	got := r.URL.Query().Get("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		got = bearer
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
@@ -0,0 +1,146 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"GNote/deeplink"
+	"GNote/models"
+	"GNote/storage"
+)
+
+// defaultRSSItems — сколько заметок в RSS, если в запросе не указано иное
+const defaultRSSItems = 50
+
+// RSS отдает RSS 2.0 с недавно измененными заметками блокнота — тега вместе с вложенными:
+// /feed.xml?notebook=работа/проекты. Без notebook в ленте все заметки.
+type RSS struct {
+	Store  storage.Store
+	Token  string   // Ключ доступа (заголовок Authorization: Bearer или параметр token); пусто — доступ открыт
+	Public []string // Блокноты, которые можно читать без ключа
+	Items  int      // Сколько заметок в ленте; 0 — defaultRSSItems
+}
+
+// Структура RSS 2.0 для encoding/xml
+type (
+	rssDocument struct {
+		XMLName xml.Name   `xml:"rss"`
+		Version string     `xml:"version,attr"`
+		Channel rssChannel `xml:"channel"`
+	}
+	rssChannel struct {
+		Title         string    `xml:"title"`
+		Link          string    `xml:"link"`
+		Description   string    `xml:"description"`
+		LastBuildDate string    `xml:"lastBuildDate"`
+		Items         []rssItem `xml:"item"`
+	}
+	rssItem struct {
+		Title       string   `xml:"title"`
+		Link        string   `xml:"link"`
+		GUID        rssGUID  `xml:"guid"`
+		PubDate     string   `xml:"pubDate"`
+		Categories  []string `xml:"category"`
+		Description string   `xml:"description"`
+	}
+	rssGUID struct {
+		Value       string `xml:",chardata"`
+		IsPermaLink bool   `xml:"isPermaLink,attr"`
+	}
+)
+
+// ServeHTTP отдает ленту блокнота из параметра notebook
+func (f *RSS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	notebook := models.NormalizeTag(r.URL.Query().Get("notebook"))
+	if !f.public(notebook) && !Authorized(r, f.Token) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="gnote"`)
+		http.Error(w, "нужен ключ доступа", http.StatusUnauthorized)
+		return
+	}
+	notes, err := f.Store.GetAllNotes()
+	if err != nil {
+		log.Printf("Ошибка при загрузке заметок для RSS: %v", err)
+		http.Error(w, "не удалось загрузить заметки", http.StatusServiceUnavailable)
+		return
+	}
+	// Лента бывает публичной, поэтому секретные заметки в нее не попадают, а секретные фрагменты скрываются
+	notes = slices.DeleteFunc(notes, func(note models.Note) bool { return note.HasSensitiveTag() })
+	notes = recentInNotebook(notes, notebook, f.Items)
+
+	title := "GNote"
+	if notebook != "" {
+		title += ": " + notebook
+	}
+	doc := rssDocument{Version: "2.0", Channel: rssChannel{
+		Title:         title,
+		Link:          "http://" + r.Host + r.URL.Path,
+		Description:   "Недавно измененные заметки",
+		LastBuildDate: time.Now().Format(time.RFC1123Z),
+	}}
+	for _, note := range notes {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title: note.Title,
+			Link:  deeplink.NoteURL(note.ID),
+			// Время изменения в GUID: каждое изменение заметки — новая запись ленты
+			GUID:        rssGUID{Value: fmt.Sprintf("gnote-note-%d-%d", note.ID, note.UpdatedAt.Unix())},
+			PubDate:     note.UpdatedAt.Format(time.RFC1123Z),
+			Categories:  note.Tags,
+			Description: "<pre>" + html.EscapeString(models.RedactText(note.Content)) + "</pre>",
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		log.Printf("Ошибка при отправке RSS: %v", err)
+	}
+}
+
+// public сообщает, что блокнот открыт для чтения без ключа
+func (f *RSS) public(notebook string) bool {
+	if notebook == "" {
+		return false
+	}
+	for _, p := range f.Public {
+		if models.TagHasPrefix(notebook, models.NormalizeTag(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// recentInNotebook возвращает не больше limit последних измененных заметок блокнота notebook (пусто — все)
+func recentInNotebook(notes []models.Note, notebook string, limit int) []models.Note {
+	if limit <= 0 {
+		limit = defaultRSSItems
+	}
+	var found []models.Note
+	for _, note := range notes {
+		if notebook == "" || inNotebook(note, notebook) {
+			found = append(found, note)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].UpdatedAt.After(found[j].UpdatedAt) })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found
+}
+
+// inNotebook сообщает, что у заметки есть тег блокнота или вложенный в него
+func inNotebook(note models.Note, notebook string) bool {
+	for _, tag := range note.Tags {
+		if models.TagHasPrefix(tag, notebook) {
+			return true
+		}
+	}
+	return false
+}
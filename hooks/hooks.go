@@ -0,0 +1,230 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"GNote/events"
+	"GNote/extract"
+	"GNote/models"
+	"GNote/storage"
+)
+
+// События, на которые срабатывают обработчики
+const (
+	NoteCreated  = "note-created"
+	NoteSaved    = "note-saved"
+	NoteDeleted  = "note-deleted"
+	BeforeExport = "before-export"
+)
+
+// timeout — сколько может работать один обработчик
+const timeout = 30 * time.Second
+
+// Payload — данные, которые обработчик получает в JSON: команда на stdin, вебхук в теле POST
+type Payload struct {
+	Event  string       `json:"event"`
+	NoteID int          `json:"note_id,omitempty"`
+	Note   *models.Note `json:"note,omitempty"` // Нет у note-deleted
+}
+
+// handler — команда или адрес вебхука
+type handler struct {
+	args []string // Команда с аргументами; пусто — вебхук
+	url  string
+}
+
+// String возвращает обработчик для сообщений об ошибках
+func (h handler) String() string {
+	if h.url != "" {
+		return h.url
+	}
+	return h.args[0]
+}
+
+// Hooks запускает пользовательские обработчики событий: внешние команды (например, уведомление
+// или запись в журнал) или вебхуки — адреса http:// и https://, на которые отправляется POST.
+// Обработчики событий заметок только получают уведомление: заметку они не меняют, их вывод не используется.
+// Данные меняет только before-export.
+type Hooks struct {
+	handlers map[string][]handler
+	client   *http.Client
+
+	mu    sync.Mutex
+	store storage.Store // Для загрузки заметки, если событие пришло без нее
+}
+
+// New разбирает обработчики по событиям: ключ — событие (note-created и т.п.), значение — команды и адреса.
+// Аргументы команды с пробелами заключаются в кавычки, как в extract.ParseCommand.
+// Возвращает nil, если обработчиков нет.
+func New(store storage.Store, byEvent map[string][]string) (*Hooks, error) {
+	handlers, err := parse(byEvent)
+	if err != nil {
+		return nil, err
+	}
+	if len(handlers) == 0 {
+		return nil, nil
+	}
+	return &Hooks{handlers: handlers, client: &http.Client{Timeout: timeout}, store: store}, nil
+}
+
+// Validate проверяет обработчики так же, как New, чтобы ошибку было видно при загрузке настроек
+func Validate(byEvent map[string][]string) error {
+	_, err := parse(byEvent)
+	return err
+}
+
+// parse разбирает обработчики по событиям
+func parse(byEvent map[string][]string) (map[string][]handler, error) {
+	handlers := make(map[string][]handler)
+	for event, targets := range byEvent {
+		switch event {
+		case NoteCreated, NoteSaved, NoteDeleted, BeforeExport:
+		default:
+			return nil, fmt.Errorf("неизвестное событие обработчиков %q", event)
+		}
+		for _, target := range targets {
+			if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+				handlers[event] = append(handlers[event], handler{url: target})
+				continue
+			}
+			c, err := extract.ParseCommand(target)
+			if err != nil {
+				return nil, fmt.Errorf("неверная команда обработчика %s: %w", event, err)
+			}
+			handlers[event] = append(handlers[event], handler{args: c.Args()})
+		}
+	}
+	return handlers, nil
+}
+
+// SetStore переключает обработчики на другое хранилище (например, при смене профиля)
+func (h *Hooks) SetStore(store storage.Store) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.store = store
+}
+
+// NoteChanged запускает обработчики note-created, note-saved и note-deleted в фоне; подходит как
+// обработчик шины событий. Изменения, пришедшие от других экземпляров, обрабатывают они сами.
+// Вывод команд не записывается в заметку: обработчик получает заметку без секретов, а запись
+// из фона затерла бы правки в открытом редакторе и снова вызвала бы note-saved.
+func (h *Hooks) NoteChanged(e events.Event) {
+	if e.Remote {
+		return
+	}
+	var event string
+	switch e.Kind {
+	case events.NoteCreated:
+		event = NoteCreated
+	case events.NoteUpdated:
+		event = NoteSaved
+	case events.NoteDeleted:
+		event = NoteDeleted
+	default:
+		return
+	}
+	if len(h.handlers[event]) == 0 {
+		return
+	}
+	go func() {
+		payload := Payload{Event: event, NoteID: e.NoteID, Note: e.Note}
+		if payload.Note == nil && event != NoteDeleted {
+			h.mu.Lock()
+			store := h.store
+			h.mu.Unlock()
+			note, err := store.GetNoteByID(e.NoteID)
+			if err != nil {
+				log.Printf("Обработчики %s для заметки ID %d не запущены: %v", event, e.NoteID, err)
+				return
+			}
+			payload.Note = note
+		}
+		if payload.Note != nil { // Обработчики — внешние программы и сервисы, секреты им не передаются
+			redacted := payload.Note.Redacted()
+			payload.Note = &redacted
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			log.Printf("Ошибка при подготовке данных для обработчиков %s: %v", event, err)
+			return
+		}
+		for _, hd := range h.handlers[event] {
+			out, err := h.run(hd, event, payload.NoteID, data)
+			if err != nil {
+				log.Printf("Обработчик %s (%s) для заметки ID %d: %v", event, hd, e.NoteID, err)
+				continue
+			}
+			if len(bytes.TrimSpace(out)) > 0 {
+				log.Printf("Обработчик %s (%s) для заметки ID %d что-то вывел; вывод обработчиков заметок не используется", event, hd, e.NoteID)
+			}
+		}
+	}()
+}
+
+// BeforeExport пропускает выгружаемые данные через обработчики before-export по очереди:
+// непустой вывод команды заменяет данные. Ошибка обработчика отменяет экспорт.
+func (h *Hooks) BeforeExport(data []byte) ([]byte, error) {
+	for _, hd := range h.handlers[BeforeExport] {
+		out, err := h.run(hd, BeforeExport, 0, data)
+		if err != nil {
+			return nil, fmt.Errorf("обработчик %s: %w", hd, err)
+		}
+		if hd.url == "" && len(bytes.TrimSpace(out)) > 0 {
+			data = out
+		}
+	}
+	return data, nil
+}
+
+// run запускает обработчик с данными data и возвращает вывод команды
+func (h *Hooks) run(hd handler, event string, noteID int, data []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if hd.url != "" {
+		return nil, h.post(ctx, hd.url, event, data)
+	}
+
+	cmd := exec.CommandContext(ctx, hd.args[0], hd.args[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(), "GNOTE_EVENT="+event, "GNOTE_NOTE_ID="+strconv.Itoa(noteID))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("команда завершилась с ошибкой: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("команда завершилась с ошибкой: %w", err)
+	}
+	return out, nil
+}
+
+// post отправляет данные на вебхук
+func (h *Hooks) post(ctx context.Context, url, event string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("неверный адрес вебхука: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GNote-Event", event)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка при отправке вебхука: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("вебхук ответил %s", resp.Status)
+	}
+	return nil
+}
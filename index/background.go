@@ -0,0 +1,100 @@
+package index
+
+import (
+	"log"
+	"sync"
+
+	"GNote/events"
+	"GNote/storage"
+)
+
+// Background поддерживает индекс в актуальном состоянии в отдельной горутине:
+// строит его по всем заметкам и применяет события хранилища
+type Background struct {
+	*Index
+	store storage.Store
+
+	mu      sync.Mutex
+	pending []events.Event
+	wake    chan struct{}
+	done    chan struct{}
+}
+
+// Start строит индекс заметок store в фоне и подписывает его на события bus.
+// Возвращает индекс и функцию остановки.
+func Start(store storage.Store, bus *events.Bus) (*Background, func()) {
+	b := &Background{
+		Index: New(),
+		store: store,
+		wake:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	// Подписываемся до загрузки, чтобы не потерять изменения, сделанные во время построения
+	unsubscribe := bus.Subscribe(b.enqueue)
+	go b.run()
+	return b, func() {
+		unsubscribe()
+		close(b.done)
+	}
+}
+
+// enqueue ставит событие в очередь; вызывается в горутине публикации и не блокирует ее
+func (b *Background) enqueue(e events.Event) {
+	switch e.Kind {
+	case events.NoteCreated, events.NoteUpdated, events.NoteDeleted:
+	default:
+		return
+	}
+	b.mu.Lock()
+	b.pending = append(b.pending, e)
+	b.mu.Unlock()
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run строит индекс и затем применяет события, пока индекс не остановят
+func (b *Background) run() {
+	notes, err := b.store.GetAllNotes()
+	if err != nil {
+		log.Printf("Ошибка при построении индекса похожих заметок: %v", err)
+	} else {
+		b.Rebuild(notes)
+		log.Printf("Индекс похожих заметок построен: %d заметок", len(notes))
+	}
+	for {
+		b.apply()
+		select {
+		case <-b.done:
+			return
+		case <-b.wake:
+		}
+	}
+}
+
+// apply применяет накопленные события к индексу
+func (b *Background) apply() {
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	for _, e := range pending {
+		switch e.Kind {
+		case events.NoteDeleted:
+			b.Remove(e.NoteID)
+		case events.NoteCreated, events.NoteUpdated:
+			note := e.Note
+			if note == nil { // Изменилось избранное или текст вложения: перечитываем заметку
+				loaded, err := b.store.GetNoteByID(e.NoteID)
+				if err != nil {
+					log.Printf("Ошибка при обновлении индекса для заметки ID %d: %v", e.NoteID, err)
+					continue
+				}
+				note = loaded
+			}
+			b.Add(*note)
+		}
+	}
+}
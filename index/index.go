@@ -0,0 +1,224 @@
+package index
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"GNote/models"
+	"GNote/nlp"
+)
+
+// tagWeight — вес совпадения тегов относительно сходства текста
+const tagWeight = 0.5
+
+// Match — похожая заметка и степень сходства (больше — ближе)
+type Match struct {
+	NoteID int
+	Score  float64
+}
+
+// document — заметка в индексе: частоты слов и теги
+type document struct {
+	title string // Заголовок в нижнем регистре для поиска дубликатов
+	terms map[string]int
+	tags  map[string]bool
+}
+
+// Index хранит частоты слов заметок и находит похожие по TF-IDF и общим тегам
+type Index struct {
+	mu   sync.RWMutex
+	docs map[int]document
+	df   map[string]int // В скольких заметках встречается слово
+}
+
+// New создает пустой индекс
+func New() *Index {
+	return &Index{docs: make(map[int]document), df: make(map[string]int)}
+}
+
+// Rebuild заменяет содержимое индекса заметками notes
+func (ix *Index) Rebuild(notes []models.Note) {
+	docs := make(map[int]document, len(notes))
+	df := make(map[string]int)
+	for _, note := range notes {
+		doc := newDocument(note)
+		docs[note.ID] = doc
+		for term := range doc.terms {
+			df[term]++
+		}
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.docs = docs
+	ix.df = df
+}
+
+// Add добавляет заметку в индекс или обновляет ее
+func (ix *Index) Add(note models.Note) {
+	doc := newDocument(note)
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(note.ID)
+	ix.docs[note.ID] = doc
+	for term := range doc.terms {
+		ix.df[term]++
+	}
+}
+
+// Remove удаляет заметку из индекса
+func (ix *Index) Remove(id int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+}
+
+// remove удаляет заметку; вызывается под блокировкой
+func (ix *Index) remove(id int) {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		if ix.df[term]--; ix.df[term] <= 0 {
+			delete(ix.df, term)
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// Related возвращает до limit заметок, похожих на заметку id, от самой похожей
+func (ix *Index) Related(id int, limit int) []Match {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	doc, ok := ix.docs[id]
+	if !ok {
+		return nil
+	}
+	vec := ix.weights(doc)
+	var matches []Match
+	for otherID, other := range ix.docs {
+		if otherID == id {
+			continue
+		}
+		score := cosine(vec, ix.weights(other)) + tagWeight*jaccard(doc.tags, other.tags)
+		if score > 0 {
+			matches = append(matches, Match{NoteID: otherID, Score: score})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].NoteID < matches[j].NoteID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Keywords возвращает до limit самых характерных слов text по TF-IDF относительно заметок индекса
+func (ix *Index) Keywords(text string, limit int) []nlp.Term {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return nlp.TopTerms(text, func(word string) int { return ix.df[word] }, len(ix.docs), limit)
+}
+
+// Duplicate — пара почти одинаковых заметок; A — более ранняя (с меньшим ID)
+type Duplicate struct {
+	A, B      int
+	Score     float64 // Сходство текста от 0 до 1
+	SameTitle bool
+}
+
+// Duplicates находит пары заметок с одинаковым заголовком или сходством текста не меньше minScore,
+// от самых похожих
+func (ix *Index) Duplicates(minScore float64) []Duplicate {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	ids := make([]int, 0, len(ix.docs))
+	vecs := make(map[int]map[string]float64, len(ix.docs))
+	for id, doc := range ix.docs {
+		ids = append(ids, id)
+		vecs[id] = ix.weights(doc)
+	}
+	sort.Ints(ids)
+
+	var dups []Duplicate
+	for i, a := range ids {
+		for _, b := range ids[i+1:] {
+			sameTitle := ix.docs[a].title != "" && ix.docs[a].title == ix.docs[b].title
+			score := cosine(vecs[a], vecs[b])
+			if sameTitle || score >= minScore {
+				dups = append(dups, Duplicate{A: a, B: b, Score: score, SameTitle: sameTitle})
+			}
+		}
+	}
+	sort.SliceStable(dups, func(i, j int) bool { return dups[i].Score > dups[j].Score })
+	return dups
+}
+
+// weights возвращает TF-IDF веса слов заметки; вызывается под блокировкой
+func (ix *Index) weights(doc document) map[string]float64 {
+	n := len(ix.docs)
+	vec := make(map[string]float64, len(doc.terms))
+	for term, count := range doc.terms {
+		vec[term] = nlp.TF(count) * nlp.IDF(ix.df[term], n)
+	}
+	return vec
+}
+
+// cosine — косинусное сходство двух векторов весов
+func cosine(a, b map[string]float64) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var dot, normA, normB float64
+	for term, w := range a {
+		dot += w * b[term]
+		normA += w * w
+	}
+	if dot == 0 {
+		return 0
+	}
+	for _, w := range b {
+		normB += w * w
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// jaccard — доля общих тегов среди всех тегов двух заметок
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for tag := range a {
+		if b[tag] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// newDocument разбивает заголовок, содержимое и текст вложений заметки на слова
+func newDocument(note models.Note) document {
+	doc := document{
+		title: strings.ToLower(strings.TrimSpace(note.Title)),
+		terms: make(map[string]int),
+		tags:  make(map[string]bool, len(note.Tags)),
+	}
+	for _, text := range []string{note.Title, note.Content, note.AttachmentText} {
+		for _, term := range nlp.Tokenize(text) {
+			doc.terms[term]++
+		}
+	}
+	for _, tag := range note.Tags {
+		doc.tags[strings.ToLower(tag)] = true
+	}
+	return doc
+}
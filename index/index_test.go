@@ -0,0 +1,171 @@
+package index
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"GNote/events"
+	"GNote/models"
+	"GNote/storage"
+)
+
+// indexNotes — заметки для проверки похожих и дубликатов
+var indexNotes = []models.Note{
+	{ID: 1, Title: "Отпуск в Италии", Content: "Рим, Флоренция, Венеция: билеты и гостиницы", Tags: []string{"путешествия"}},
+	{ID: 2, Title: "Италия: маршрут", Content: "Рим и Флоренция на поезде, гостиницы у вокзала", Tags: []string{"путешествия"}},
+	{ID: 3, Title: "Рецепт пасты", Content: "Спагетти, томаты, базилик, пармезан"},
+	{ID: 4, Title: "Список покупок", Content: "Молоко, хлеб, сыр", Tags: []string{"путешествия"}},
+	{ID: 5, Title: "отпуск в италии", Content: "Черновик"},
+}
+
+// matchIDs возвращает ID похожих заметок по порядку
+func matchIDs(matches []Match) []int {
+	ids := make([]int, len(matches))
+	for i, m := range matches {
+		ids[i] = m.NoteID
+	}
+	return ids
+}
+
+func TestRelated(t *testing.T) {
+	ix := New()
+	ix.Rebuild(indexNotes)
+	tests := []struct {
+		name  string
+		id    int
+		limit int
+		want  []int
+	}{
+		{"текст и теги вместе выше", 1, 10, []int{2, 4, 5}},
+		{"ограничение количества", 1, 1, []int{2}},
+		{"нет общих слов и тегов", 3, 10, []int{}},
+		{"общий тег без общих слов", 4, 10, []int{1, 2}},
+		{"заметки нет в индексе", 42, 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchIDs(ix.Related(tt.id, tt.limit))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Related(%d) = %v, ожидалось %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	ix := New()
+	ix.Rebuild(indexNotes[:2])
+	before := len(ix.df)
+
+	// Повторное добавление той же заметки не меняет частоты слов
+	ix.Add(indexNotes[1])
+	if len(ix.df) != before || ix.df["флоренция"] != 2 {
+		t.Errorf("после повторного Add: %d слов, df[флоренция] = %d", len(ix.df), ix.df["флоренция"])
+	}
+	// Изменение заметки убирает ее старые слова
+	ix.Add(models.Note{ID: 2, Title: "Венеция"})
+	if ix.df["флоренция"] != 1 || ix.df["венеция"] != 2 {
+		t.Errorf("после изменения: df[флоренция] = %d, df[венеция] = %d", ix.df["флоренция"], ix.df["венеция"])
+	}
+	ix.Remove(1)
+	ix.Remove(2)
+	if len(ix.docs) != 0 || len(ix.df) != 0 {
+		t.Errorf("после удаления всех заметок осталось %d заметок и %d слов", len(ix.docs), len(ix.df))
+	}
+	ix.Remove(3) // Удаление отсутствующей заметки ничего не ломает
+}
+
+func TestDuplicates(t *testing.T) {
+	ix := New()
+	ix.Rebuild(append(slices.Clone(indexNotes),
+		models.Note{ID: 6, Title: "Паста", Content: "Спагетти, томаты, базилик, пармезан"}))
+	tests := []struct {
+		minScore float64
+		want     [][2]int
+	}{
+		{1.1, [][2]int{{1, 5}}}, // Только одинаковые заголовки, без учета регистра
+		{0.5, [][2]int{{3, 6}, {1, 5}}},
+		{0.3, [][2]int{{3, 6}, {1, 5}, {1, 2}}}, // От самых похожих
+	}
+	for _, tt := range tests {
+		var got [][2]int
+		for _, d := range ix.Duplicates(tt.minScore) {
+			got = append(got, [2]int{d.A, d.B})
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Duplicates(%g) = %v, ожидалось %v", tt.minScore, got, tt.want)
+		}
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	ix := New()
+	ix.Rebuild(indexNotes)
+	terms := ix.Keywords("Флоренция и Флоренция, гостиницы", 1)
+	if len(terms) != 1 || terms[0].Word != "флоренция" {
+		t.Errorf("Keywords() = %+v, ожидалось слово «флоренция»", terms)
+	}
+}
+
+// fakeStore отдает заметки индексу; остальные методы хранилища не нужны
+type fakeStore struct {
+	storage.Store
+	mu    sync.Mutex
+	notes map[int]models.Note
+}
+
+func (s *fakeStore) GetAllNotes() ([]models.Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var notes []models.Note
+	for _, note := range s.notes {
+		notes = append(notes, note)
+	}
+	return notes, nil
+}
+
+func (s *fakeStore) GetNoteByID(id int) (*models.Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	note := s.notes[id]
+	return &note, nil
+}
+
+func TestBackground(t *testing.T) {
+	store := &fakeStore{notes: map[int]models.Note{1: indexNotes[0], 2: indexNotes[1]}}
+	bus := events.NewBus()
+	b, stop := Start(store, bus)
+	defer stop()
+
+	created := indexNotes[4]
+	bus.Publish(events.Event{Kind: events.NoteCreated, NoteID: created.ID, Note: &created})
+	store.mu.Lock()
+	store.notes[3] = indexNotes[2]
+	store.mu.Unlock()
+	bus.Publish(events.Event{Kind: events.NoteUpdated, NoteID: 3}) // Без заметки: перечитывается из хранилища
+	bus.Publish(events.Event{Kind: events.NoteDeleted, NoteID: 2})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		b.mu.Lock()
+		pending := len(b.pending)
+		b.mu.Unlock()
+		b.Index.mu.RLock()
+		_, has3 := b.docs[3]
+		_, has2 := b.docs[2]
+		count := len(b.docs)
+		b.Index.mu.RUnlock()
+		if pending == 0 && has3 && !has2 && count == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("события не применены: %d заметок, 2 в индексе: %v, 3 в индексе: %v", count, has2, has3)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := matchIDs(b.Related(1, 10)); !slices.Equal(got, []int{5}) {
+		t.Errorf("Related(1) = %v, ожидалось [5]", got)
+	}
+}
@@ -0,0 +1,39 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// ContextProvider добавляет в дневниковую запись строку контекста (дата, погода и т.п.)
+type ContextProvider interface {
+	// Name возвращает название провайдера для логов
+	Name() string
+	// Context возвращает строку контекста для момента now
+	Context(ctx context.Context, now time.Time) (string, error)
+}
+
+// Stamp опрашивает провайдеров по порядку и собирает их строки в блок контекста.
+// Ошибки отдельных провайдеров только логируются, чтобы не мешать созданию записи.
+func Stamp(ctx context.Context, now time.Time, providers []ContextProvider) string {
+	var lines []string
+	for _, p := range providers {
+		line, err := p.Context(ctx, now)
+		if err != nil {
+			log.Printf("Провайдер контекста '%s' вернул ошибку: %v", p.Name(), err)
+			continue
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Title возвращает заголовок дневниковой записи за указанный день
+func Title(day time.Time) string {
+	return fmt.Sprintf("Дневник %s", day.Format("02.01.2006"))
+}
@@ -0,0 +1,74 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var weekdays = [...]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"}
+
+var months = [...]string{"января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря"}
+
+// DateProvider добавляет дату и день недели
+type DateProvider struct{}
+
+// Name возвращает название провайдера
+func (DateProvider) Name() string { return "дата" }
+
+// Context возвращает строку вида "17 октября 2026, суббота"
+func (DateProvider) Context(_ context.Context, now time.Time) (string, error) {
+	return FormatDate(now), nil
+}
+
+// FormatDate возвращает дату по-русски с днем недели: "17 октября 2026, суббота"
+func FormatDate(t time.Time) string {
+	return fmt.Sprintf("%d %s %d, %s", t.Day(), months[t.Month()-1], t.Year(), weekdays[t.Weekday()])
+}
+
+// WeatherProvider получает краткую сводку погоды из настраиваемого HTTP API.
+// В URL можно использовать {city}, он будет заменен на значение City
+// (например, https://wttr.in/{city}?format=3&lang=ru).
+type WeatherProvider struct {
+	URL    string
+	City   string
+	Client *http.Client
+}
+
+// Name возвращает название провайдера
+func (p *WeatherProvider) Name() string { return "погода" }
+
+// Context запрашивает API и возвращает первую строку ответа
+func (p *WeatherProvider) Context(ctx context.Context, _ time.Time) (string, error) {
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	target := strings.ReplaceAll(p.URL, "{city}", url.PathEscape(p.City))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", fmt.Errorf("некорректный адрес API погоды: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ошибка запроса погоды: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("API погоды вернул статус %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", fmt.Errorf("ошибка чтения ответа API погоды: %w", err)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	if line == "" {
+		return "", nil
+	}
+	return "Погода: " + line, nil
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"GNote/config"
+	"GNote/deeplink"
+	"GNote/events"
+	"GNote/extract"
+	"GNote/feed"
+	"GNote/hooks"
+	"GNote/linkpreview"
+	"GNote/mailin"
+	"GNote/mirror"
+	"GNote/scan"
+	"GNote/scheduler"
+	"GNote/speech"
+	"GNote/storage"
+	"GNote/summary"
+	"GNote/ui"
+)
+
+// schemaSQL — скрипт схемы БД; выполняется из окна устаревшей схемы
+//
+//go:embed database.sql
+var schemaSQL string
+
+// launcher запускает окна приложения после подключения к хранилищу
+type launcher struct {
+	app     fyne.App
+	cfg     config.Config
+	profile string // Профиль, выбранный при запуске
+	dbURL   string // Строка подключения из --db-url или окна ошибки подключения
+	dataDir string
+	daemon  bool
+	link    string           // Ссылка gnote://, переданная при запуске
+	metrics *storage.Metrics // Время запросов к БД всех профилей; nil — еще не создано
+
+	cleanup []func() // Выполняются в обратном порядке при завершении
+}
+
+// storeMetrics возвращает общий для всех профилей сбор времени запросов к БД
+func (l *launcher) storeMetrics() *storage.Metrics {
+	if l.metrics == nil {
+		l.metrics = storage.NewMetrics(time.Duration(l.cfg.Database.SlowQueryMS) * time.Millisecond)
+	}
+	return l.metrics
+}
+
+// appDataDir возвращает каталог данных приложения: каталог XDG или, для старых установок, каталог данных Fyne
+func (l *launcher) appDataDir() string {
+	return config.DataDir(l.app.Storage().RootURI().Path())
+}
+
+// migrateFyneDirs переносит офлайн-кэши из каталогов данных, которые Fyne создавал до появления ID
+// приложения, и сообщает, где остались их вложения
+func (l *launcher) migrateFyneDirs() {
+	fyneDir := l.app.Storage().RootURI().Path()
+	old, err := config.MigrateFyneDirs(fyneDir, l.appDataDir())
+	if err != nil {
+		log.Printf("Ошибка при переносе данных из прежнего каталога приложения: %v", err)
+	}
+	for _, dir := range old {
+		log.Printf("Вложения прежних версий остались в %s: они открываются оттуда, каталог не удаляйте", dir)
+	}
+}
+
+// profileConfig возвращает настройки профиля name.
+// Для профиля, выбранного при запуске, строка подключения и каталог данных из флагов имеют приоритет.
+func (l *launcher) profileConfig(name string) (config.Config, error) {
+	cfg, err := l.cfg.ForProfile(name)
+	if err != nil {
+		return cfg, err
+	}
+	if name == l.profile && l.dbURL != "" {
+		cfg.Database.URL = l.dbURL
+	}
+	if name == l.profile && l.dataDir != "" {
+		cfg.Storage.DataDir = l.dataDir
+	}
+	return cfg, nil
+}
+
+// openProfile подключается к хранилищу профиля name
+func (l *launcher) openProfile(name string) (*ui.ProfileSession, error) {
+	cfg, err := l.profileConfig(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cachePath := cfg.OfflineCachePath(l.appDataDir())
+	store, err := storage.NewPostgresStore(cfg.StorageConfig())
+	if err != nil && cfg.Storage.OfflineCache && storage.IsUnavailable(err) && storage.HasCache(cachePath) {
+		log.Printf("Работаем с офлайн-кэшем, пока БД недоступна: %v", err)
+		store, err = storage.OpenPostgresStore(cfg.StorageConfig())
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Изменения данных публикуются в шину событий, на которую подписываются окна
+	bus := events.NewBus()
+	var inner storage.Store = storage.NewMetricsStore(store, l.storeMetrics())
+	var offline *storage.OfflineStore
+	if cfg.Storage.OfflineCache {
+		if offline, err = storage.NewOfflineStore(inner, cachePath, bus); err != nil {
+			store.Close()
+			return nil, err
+		}
+		inner = offline
+	}
+	// Правила приема вложений проверяются до записи в БД и в офлайн-кэш; команда проверена в config.validate
+	if p, _ := cfg.AttachmentPolicy(); !p.Empty() {
+		inner = storage.NewPolicyStore(inner, p)
+	}
+	// Изменения других экземпляров приходят в ту же шину: от PostgreSQL и по ленте изменений сервера
+	var stops []func()
+	if cfg.Database.ListenChanges {
+		stops = append(stops, store.ListenChanges(bus))
+	}
+	if cfg.Server.ChangesURL != "" {
+		stops = append(stops, feed.Start(cfg.Server.ChangesURL, cfg.Server.Token, inner, bus))
+	}
+	return &ui.ProfileSession{
+		Name:            name,
+		Store:           storage.NewPublishingStore(inner, bus),
+		Offline:         offline,
+		Bus:             bus,
+		AttachmentsDir:  cfg.AttachmentsPath(l.appDataDir()),
+		AttachmentsInDB: cfg.Storage.Attachments == config.AttachmentsDatabase,
+		Theme:           cfg.UI.Theme,
+		Density:         cfg.UI.Density,
+		Close: func() {
+			for _, stop := range stops {
+				stop()
+			}
+			if err := store.Close(); err != nil {
+				log.Printf("Ошибка при закрытии хранилища профиля '%s': %v", name, err)
+			}
+		},
+	}, nil
+}
+
+// migrateSchema обновляет схему БД профиля, выбранного при запуске, скриптом database.sql
+func (l *launcher) migrateSchema() error {
+	cfg, err := l.profileConfig(l.profile)
+	if err != nil {
+		return err
+	}
+	return storage.ApplySchema(cfg.StorageConfig(), schemaSQL)
+}
+
+// migrateAttachments переносит содержимое вложений профиля, выбранного при запуске,
+// в режим mode: "database" — из файлов в БД, "files" — из БД в каталог вложений
+func (l *launcher) migrateAttachments(mode string) error {
+	cfg, err := l.profileConfig(l.profile)
+	if err != nil {
+		return err
+	}
+	store, err := storage.NewPostgresStore(cfg.StorageConfig())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var moved int
+	switch mode {
+	case config.AttachmentsDatabase:
+		moved, err = store.MoveAttachmentsToDatabase()
+	case config.AttachmentsFiles:
+		moved, err = store.MoveAttachmentsToFiles(cfg.AttachmentsPath(l.appDataDir()))
+	default:
+		return fmt.Errorf("неизвестный режим хранения вложений %q: доступны %s и %s",
+			mode, config.AttachmentsFiles, config.AttachmentsDatabase)
+	}
+	log.Printf("Перенесено вложений: %d", moved)
+	if err != nil {
+		return err
+	}
+	if cfg.Storage.Attachments != mode {
+		log.Printf("Чтобы новые вложения сохранялись так же, укажите attachments = %q в разделе [storage] настроек", mode)
+	}
+	return nil
+}
+
+// start открывает окна и фоновые задачи для подключенного профиля
+func (l *launcher) start(session *ui.ProfileSession) {
+	ui.ApplyTheme(l.app, session.Theme, session.Density)
+	profiles := ui.NewProfiles(session, l.cfg.ProfileNames(), l.openProfile)
+	l.cleanup = append(l.cleanup, func() { profiles.Current().Close() })
+	l.cleanup = append(l.cleanup, ui.WaitBackground) // Фоновые записи завершаются до закрытия хранилища
+
+	// Планировщик напоминаний работает в обоих режимах и следует за открытым профилем
+	sched := scheduler.New()
+	reminders := scheduler.NewReminderChecker(session.Store, ui.ReminderNotifier(l.app))
+	profiles.AddListener(func(_ *ui.NoteApp, s *ui.ProfileSession) { reminders.SetStore(s.Store) })
+	sched.Every("напоминания", 30*time.Second, reminders.Check)
+	recurring := scheduler.NewRecurringNotes(session.Store)
+	profiles.AddListener(func(_ *ui.NoteApp, s *ui.ProfileSession) { recurring.SetStore(s.Store) })
+	sched.Every("повторяющиеся заметки", time.Minute, recurring.Check)
+	// Проверка связи с БД для индикатора в окне заметок
+	health := storage.NewHealthChecker(session.Store)
+	profiles.AddListener(func(_ *ui.NoteApp, s *ui.ProfileSession) {
+		health.SetStore(s.Store)
+		go health.Check(time.Now())
+	})
+	sched.Every("проверка БД", 15*time.Second, health.Check)
+	// Изменения, сделанные без связи с БД, отправляются после ее восстановления
+	var offline atomic.Pointer[storage.OfflineStore]
+	offline.Store(session.Offline)
+	profiles.AddListener(func(_ *ui.NoteApp, s *ui.ProfileSession) { offline.Store(s.Offline) })
+	sched.Every("офлайн-кэш", 30*time.Second, func(now time.Time) {
+		if o := offline.Load(); o != nil {
+			o.Sync(now)
+		}
+	})
+	if l.cfg.Mirror.Dir != "" {
+		l.scheduleMirror(sched, profiles, session)
+	}
+	if l.cfg.OCR.Command != "" {
+		l.scheduleOCR(sched, profiles, session.Store)
+	}
+	if l.cfg.Transcription.Command != "" || l.cfg.Transcription.APIURL != "" {
+		l.scheduleTranscription(sched, profiles, session.Store)
+	}
+	if l.cfg.Email.IMAP != "" {
+		l.scheduleEmail(sched, profiles, session)
+	}
+	sched.Start()
+	l.cleanup = append(l.cleanup, sched.Stop)
+	eventHooks := l.startHooks(profiles, session)
+	scanner := l.scanner()
+	var linkPreviews *linkpreview.Fetcher // Один кэш карточек на все окна и профили
+	if l.cfg.UI.LinkPreviews {
+		linkPreviews = linkpreview.New()
+	}
+
+	if l.daemon {
+		d := ui.NewDaemon(l.app, session.Store, session.Bus)
+		d.SetContextProviders(contextProviders())
+		d.SetAttachmentsDir(session.AttachmentsDir)
+		d.SetAttachmentsInDatabase(session.AttachmentsInDB)
+		d.SetProfiles(profiles)
+		d.SetHealth(health)
+		d.SetSpeaker(l.speaker())
+		d.SetHooks(eventHooks)
+		d.SetSummarizer(l.summarizer())
+		d.SetScanner(scanner)
+		d.SetLinkPreviews(linkPreviews)
+		d.SetMetrics(l.storeMetrics())
+		if l.cfg.Scanner.HotFolder != "" {
+			l.scheduleScanFolder(sched, profiles, session, d.CurrentNoteID)
+		}
+		if !d.SetupTray() {
+			log.Println("Системный трей недоступен, открываем окно заметок")
+			d.ShowNotes()
+		}
+		l.listenLinks(d.HandleLink)
+		if l.link != "" {
+			d.HandleLink(l.link)
+		}
+		log.Println("GNote запущен в фоновом режиме")
+		return
+	}
+
+	w := l.app.NewWindow(windowTitle(session.Name))
+	w.SetIcon(fyne.NewStaticResource("note.png", []byte{}))
+
+	// Создание и запуск UI приложения
+	noteApp := ui.NewNoteApp(w, session.Store, session.Name)
+	noteApp.Subscribe(session.Bus)
+	noteApp.SetContextProviders(contextProviders())
+	noteApp.SetAttachmentsDir(session.AttachmentsDir)
+	noteApp.SetAttachmentsInDatabase(session.AttachmentsInDB)
+	noteApp.SetProfiles(profiles)
+	noteApp.SetHealth(health)
+	noteApp.SetSpeaker(l.speaker())
+	noteApp.SetHooks(eventHooks)
+	noteApp.SetSummarizer(l.summarizer())
+	noteApp.SetScanner(scanner)
+	noteApp.SetLinkPreviews(linkPreviews)
+	noteApp.SetMetrics(l.storeMetrics())
+	var current atomic.Pointer[ui.NoteApp] // Окно заметок для фоновых задач
+	current.Store(noteApp)
+	profiles.AddListener(func(app *ui.NoteApp, s *ui.ProfileSession) {
+		noteApp = app
+		current.Store(app)
+		w.SetTitle(windowTitle(s.Name))
+	})
+	if l.cfg.Scanner.HotFolder != "" {
+		l.scheduleScanFolder(sched, profiles, session, func() int { return current.Load().CurrentNoteID() })
+	}
+	w.SetMaster() // Устанавливаем окно как основное
+
+	l.listenLinks(func(link string) { noteApp.HandleLink(link) })
+	if l.link != "" {
+		noteApp.HandleLink(l.link)
+	}
+	w.Show()
+}
+
+// speaker возвращает синтезатор речи для чтения заметок вслух или nil, если его нет
+func (l *launcher) speaker() *speech.Speaker {
+	speaker, err := speech.New(l.cfg.Speech.Command)
+	if err != nil {
+		log.Printf("Чтение вслух недоступно: %v", err)
+		return nil
+	}
+	return speaker
+}
+
+// scanner возвращает сканер документов или nil, если программы сканирования нет
+func (l *launcher) scanner() *scan.Scanner {
+	scanner, err := scan.New(l.cfg.Scanner.Command)
+	if err != nil {
+		log.Printf("Сканирование недоступно: %v", err)
+		return nil
+	}
+	return scanner
+}
+
+// summarizer возвращает способ краткого пересказа заметок из настроек
+func (l *launcher) summarizer() summary.Summarizer {
+	cfg := l.cfg.Summary
+	if cfg.APIURL != "" {
+		return &summary.API{URL: cfg.APIURL, Key: cfg.APIKey, Model: cfg.Model}
+	}
+	return summary.Local{Sentences: cfg.Sentences}
+}
+
+// startHooks включает обработчики событий из настроек; они следуют за открытым профилем.
+// Возвращает nil, если обработчики не заданы.
+func (l *launcher) startHooks(profiles *ui.Profiles, session *ui.ProfileSession) *hooks.Hooks {
+	h, err := hooks.New(session.Store, l.cfg.Hooks.ByEvent())
+	if err != nil {
+		log.Printf("Обработчики событий выключены: %v", err)
+		return nil
+	}
+	if h == nil {
+		return nil
+	}
+	unsubscribe := session.Bus.Subscribe(h.NoteChanged)
+	profiles.AddListener(func(_ *ui.NoteApp, s *ui.ProfileSession) {
+		unsubscribe()
+		h.SetStore(s.Store)
+		unsubscribe = s.Bus.Subscribe(h.NoteChanged)
+	})
+	l.cleanup = append(l.cleanup, func() { unsubscribe() })
+	return h
+}
+
+// scheduleMirror включает копию заметок в markdown; каталог следует за открытым профилем
+func (l *launcher) scheduleMirror(sched *scheduler.Scheduler, profiles *ui.Profiles, session *ui.ProfileSession) {
+	hour, minute, _ := l.cfg.Mirror.Clock() // Проверено при загрузке настроек
+	m := l.newMirror(session.Name)
+	job := scheduler.NewMarkdownMirror(session.Store, m, hour, minute)
+	unsubscribe := func() {}
+	follow := func(s *ui.ProfileSession) {
+		if l.cfg.Mirror.Mode == config.MirrorOnSave {
+			unsubscribe()
+			unsubscribe = s.Bus.Subscribe(job.NoteChanged)
+		}
+	}
+	follow(session)
+	profiles.AddListener(func(_ *ui.NoteApp, s *ui.ProfileSession) {
+		job.SetStore(s.Store, l.newMirror(s.Name))
+		follow(s)
+	})
+	l.cleanup = append(l.cleanup, func() { unsubscribe() })
+	sched.Every("копия заметок в markdown", 5*time.Second, job.Check)
+	log.Printf("Копия заметок в markdown: %s (%s в %s)", m.Dir(), l.cfg.Mirror.Mode, l.cfg.Mirror.Time)
+}
+
+// newMirror создает копию заметок профиля name по его настройкам (каталог, git и remote)
+func (l *launcher) newMirror(name string) *mirror.Mirror {
+	cfg, err := l.profileConfig(name)
+	if err != nil {
+		cfg = l.cfg
+	}
+	m := mirror.New(cfg.MirrorPath())
+	if cfg.Mirror.Git {
+		m.UseGit(cfg.Mirror.Remote)
+	}
+	return m
+}
+
+// scheduleOCR включает фоновое распознавание текста на изображениях-вложениях
+func (l *launcher) scheduleOCR(sched *scheduler.Scheduler, profiles *ui.Profiles, store storage.Store) {
+	command, err := extract.New(l.cfg.OCR.Command)
+	if err != nil {
+		log.Printf("Распознавание текста выключено: %v", err)
+		return
+	}
+	recognizer := scheduler.NewAttachmentText("распознавание текста", store, "image/", command.Extract)
+	scheduleAttachmentText(sched, profiles, recognizer)
+}
+
+// scheduleTranscription включает фоновую расшифровку аудиовложений командой или через API
+func (l *launcher) scheduleTranscription(sched *scheduler.Scheduler, profiles *ui.Profiles, store storage.Store) {
+	cfg := l.cfg.Transcription
+	var fn scheduler.ExtractFunc
+	if cfg.Command != "" {
+		command, err := extract.New(cfg.Command)
+		if err != nil {
+			log.Printf("Расшифровка аудио выключена: %v", err)
+			return
+		}
+		fn = command.Extract
+	} else {
+		api := &extract.TranscriptionAPI{URL: cfg.APIURL, Key: cfg.APIKey, Model: cfg.Model}
+		fn = api.Extract
+	}
+	transcriber := scheduler.NewAttachmentText("расшифровка аудио", store, "audio/", fn)
+	transcriber.SetTimeout(30 * time.Minute)
+	scheduleAttachmentText(sched, profiles, transcriber)
+}
+
+// scheduleEmail включает создание заметок из писем; заметки попадают в открытый профиль
+func (l *launcher) scheduleEmail(sched *scheduler.Scheduler, profiles *ui.Profiles, session *ui.ProfileSession) {
+	cfg := l.cfg.Email
+	mailbox := mailin.Mailbox{Addr: cfg.IMAP, TLS: cfg.TLS, User: cfg.User, Password: cfg.Password, Folder: cfg.Folder}
+	job := scheduler.NewEmailNotes(mailbox, session.Store, session.AttachmentsDir, session.AttachmentsInDB)
+	profiles.AddListener(func(_ *ui.NoteApp, s *ui.ProfileSession) {
+		job.SetStore(s.Store, s.AttachmentsDir, s.AttachmentsInDB)
+	})
+	sched.Every("заметки из писем", time.Duration(cfg.IntervalMinutes)*time.Minute, job.Check)
+	log.Printf("Заметки из писем: %s, папка %s", cfg.User, cfg.Folder)
+}
+
+// scheduleScanFolder прикрепляет файлы из папки сканера к заметке, которую вернет target;
+// вложения сохраняются в открытый профиль
+func (l *launcher) scheduleScanFolder(sched *scheduler.Scheduler, profiles *ui.Profiles, session *ui.ProfileSession, target func() int) {
+	dir := l.cfg.Scanner.HotFolder
+	job := scheduler.NewScanFolder(dir, target, session.Store, session.AttachmentsDir, session.AttachmentsInDB)
+	profiles.AddListener(func(_ *ui.NoteApp, s *ui.ProfileSession) {
+		job.SetStore(s.Store, s.AttachmentsDir, s.AttachmentsInDB)
+	})
+	sched.Every("папка сканера", 5*time.Second, job.Check)
+	log.Printf("Папка сканера: %s", dir)
+}
+
+// scheduleAttachmentText запускает обработку вложений по расписанию; она следует за открытым профилем
+func scheduleAttachmentText(sched *scheduler.Scheduler, profiles *ui.Profiles, job *scheduler.AttachmentText) {
+	profiles.AddListener(func(_ *ui.NoteApp, s *ui.ProfileSession) { job.SetStore(s.Store) })
+	sched.EveryContext(job.Name(), time.Minute, job.Check)
+}
+
+// listenLinks принимает ссылки gnote:// от других экземпляров и передает их в поток интерфейса
+func (l *launcher) listenLinks(handle func(link string)) {
+	listener, err := deeplink.Listen(func(link string) { fyne.Do(func() { handle(link) }) })
+	if err != nil {
+		log.Printf("Прием ссылок недоступен: %v", err)
+		return
+	}
+	l.cleanup = append(l.cleanup, func() { listener.Close() })
+}
+
+// shutdown освобождает ресурсы в порядке, обратном запуску
+func (l *launcher) shutdown() {
+	for i := len(l.cleanup) - 1; i >= 0; i-- {
+		l.cleanup[i]()
+	}
+}
+
+// windowTitle возвращает заголовок окна с именем профиля
+func windowTitle(profile string) string {
+	if profile == "" {
+		return "Приложение для заметок"
+	}
+	return "Приложение для заметок — " + profile
+}
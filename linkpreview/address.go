@@ -0,0 +1,29 @@
+package linkpreview
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// sharedAddressSpace — адреса провайдеров (RFC 6598), за которыми тоже бывают внутренние сервисы
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublic сообщает, что адрес ip находится в интернете, а не на этом компьютере или в локальной сети
+func isPublic(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified() && !sharedAddressSpace.Contains(ip)
+}
+
+// publicOnly запрещает соединения с адресами локальной сети. Проверяется адрес, к которому действительно
+// идет подключение после разрешения имени, поэтому проверку не обойти ни записью DNS, ни переадресацией.
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+		return fmt.Errorf("адрес %s относится к локальной сети, загрузка с него запрещена", host)
+	}
+	return nil
+}
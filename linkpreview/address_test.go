@@ -0,0 +1,47 @@
+package linkpreview
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false}, // Метаданные облачных серверов
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublic(net.ParseIP(tt.ip)); got != tt.public {
+			t.Errorf("isPublic(%s) = %v, ожидалось %v", tt.ip, got, tt.public)
+		}
+	}
+}
+
+func TestFetcherRefusesLocalAddresses(t *testing.T) {
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<title>секрет</title>"))
+	}))
+	defer local.Close()
+	if p, err := New().Get(context.Background(), local.URL); err == nil {
+		t.Errorf("Get(%s) = %q, ожидалась ошибка", local.URL, p.Title)
+	}
+}
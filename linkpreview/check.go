@@ -0,0 +1,63 @@
+package linkpreview
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// checkTimeout — сколько ждать ответа сайта при проверке ссылки
+const checkTimeout = 15 * time.Second
+
+// checkClient проверяет ссылки; переадресации выполняются как обычно
+var checkClient = &http.Client{Timeout: checkTimeout}
+
+// Status — результат проверки ссылки
+type Status struct {
+	Code int   // Код ответа HTTP; 0 — сайт не ответил
+	Err  error // Ошибка соединения
+}
+
+// Alive сообщает, что ссылка открывается (код 2xx или 3xx)
+func (s Status) Alive() bool {
+	return s.Err == nil && s.Code >= 200 && s.Code < 400
+}
+
+// String описывает результат проверки для списка ссылок
+func (s Status) String() string {
+	switch {
+	case s.Err != nil:
+		return "нет ответа"
+	case s.Alive():
+		return fmt.Sprintf("работает (%d)", s.Code)
+	default:
+		return fmt.Sprintf("не работает (%d)", s.Code)
+	}
+}
+
+// Check проверяет, открывается ли ссылка: запросом HEAD, а если сайт его не поддерживает — GET
+func Check(ctx context.Context, rawURL string) Status {
+	code, err := request(ctx, http.MethodHead, rawURL)
+	if err == nil && (code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented || code == http.StatusForbidden) {
+		code, err = request(ctx, http.MethodGet, rawURL)
+	}
+	return Status{Code: code, Err: err}
+}
+
+// request выполняет запрос и возвращает код ответа; тело ответа не читается
+func request(ctx context.Context, method, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "GNote link checker")
+	resp, err := checkClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10)) // Соединение можно переиспользовать
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
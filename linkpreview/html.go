@@ -0,0 +1,126 @@
+package linkpreview
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// page — сведения о странице из ее <head>
+type page struct {
+	title, ogTitle      string
+	description, ogDesc string
+	siteName            string
+	icon                string // Адрес значка из <link rel="icon">, как он записан на странице
+}
+
+// parseHead читает теги <head> до начала <body>: заголовок, описание, название сайта и значок
+func parseHead(r io.Reader) page {
+	var p page
+	z := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return p
+		case html.TextToken:
+			if inTitle && p.title == "" {
+				p.title = strings.TrimSpace(string(z.Text()))
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if atom.Lookup(name) == atom.Title {
+				inTitle = false
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			attrs := make(map[string]string)
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				attrs[string(key)] = string(val)
+			}
+			switch atom.Lookup(name) {
+			case atom.Body:
+				return p // Все нужное находится в <head>
+			case atom.Title:
+				inTitle = true
+			case atom.Meta:
+				p.meta(attrs)
+			case atom.Link:
+				if rel := strings.ToLower(attrs["rel"]); p.icon == "" && attrs["href"] != "" &&
+					(rel == "icon" || rel == "shortcut icon" || rel == "apple-touch-icon") {
+					p.icon = attrs["href"]
+				}
+			}
+		}
+	}
+}
+
+// meta запоминает значение тега <meta>, если он описывает страницу
+func (p *page) meta(attrs map[string]string) {
+	key := attrs["property"]
+	if key == "" {
+		key = attrs["name"]
+	}
+	content := strings.TrimSpace(attrs["content"])
+	if content == "" {
+		return
+	}
+	switch strings.ToLower(key) {
+	case "og:title", "twitter:title":
+		if p.ogTitle == "" {
+			p.ogTitle = content
+		}
+	case "og:description", "twitter:description":
+		if p.ogDesc == "" {
+			p.ogDesc = content
+		}
+	case "description":
+		if p.description == "" {
+			p.description = content
+		}
+	case "og:site_name":
+		p.siteName = content
+	}
+}
+
+// preview собирает карточку ссылки из сведений страницы по адресу pageURL
+func (p page) preview(pageURL *url.URL) *Preview {
+	pr := &Preview{
+		URL:         pageURL.String(),
+		Title:       firstNonEmpty(p.ogTitle, p.title),
+		Description: firstNonEmpty(p.ogDesc, p.description),
+		SiteName:    firstNonEmpty(p.siteName, pageURL.Hostname()),
+	}
+	if pr.Title == "" {
+		pr.Title = pageURL.String()
+	}
+	return pr
+}
+
+// iconURL возвращает адрес значка страницы: из <link rel="icon"> или /favicon.ico сайта
+func (p page) iconURL(pageURL *url.URL) string {
+	ref := p.icon
+	if ref == "" {
+		ref = "/favicon.ico"
+	}
+	u, err := pageURL.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+// firstNonEmpty возвращает первую непустую строку
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,175 @@
+package linkpreview
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	maxHeadSize  = 512 << 10 // Сколько байт страницы читается в поисках <head>
+	maxIconSize  = 256 << 10 // Максимальный размер значка сайта
+	maxCached    = 500       // Сколько карточек хранится в кэше
+	failedRetry  = 10 * time.Minute
+	fetchTimeout = 15 * time.Second
+	maxRedirects = 5 // Сколько переадресаций допускается при загрузке страницы
+)
+
+// Preview — карточка ссылки: заголовок, описание и значок страницы
+type Preview struct {
+	URL         string
+	Title       string
+	Description string
+	SiteName    string
+	Icon        []byte // Значок сайта; nil — не найден
+}
+
+// entry — карточка в кэше или ошибка ее загрузки
+type entry struct {
+	preview *Preview
+	err     error
+	fetched time.Time
+}
+
+// Fetcher загружает карточки ссылок и хранит их в памяти, чтобы не обращаться к сайтам повторно
+type Fetcher struct {
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]entry
+	order []string // Адреса в порядке добавления: старые карточки вытесняются первыми
+}
+
+// New создает загрузчик карточек. Страницы загружаются только с адресов в интернете: ссылка из чужой
+// заметки не должна заставлять GNote обращаться к сервисам этого компьютера и локальной сети.
+func New() *Fetcher {
+	return &Fetcher{client: NewPublicClient(fetchTimeout), cache: make(map[string]entry)}
+}
+
+// NewPublicClient создает HTTP-клиент, который подключается только к адресам в интернете,
+// в том числе после переадресаций, и отказывается от переадресаций на другие схемы
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: publicOnly}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext, // Без прокси: иначе проверялся бы адрес прокси, а не сайта
+		TLSHandshakeTimeout: timeout,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     time.Minute,
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("больше %d переадресаций", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("переадресация на адрес '%s' не поддерживается", req.URL)
+			}
+			return nil // Адрес назначения проверит publicOnly при подключении
+		},
+	}
+}
+
+// Cached возвращает карточку из кэша без обращения к сети
+func (f *Fetcher) Cached(rawURL string) (*Preview, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e, ok := f.cache[rawURL]
+	if !ok || e.err != nil {
+		return nil, false
+	}
+	return e.preview, true
+}
+
+// Get возвращает карточку ссылки из кэша или загружает страницу. Неудачная загрузка
+// тоже запоминается и повторяется не раньше чем через несколько минут.
+func (f *Fetcher) Get(ctx context.Context, rawURL string) (*Preview, error) {
+	f.mu.Lock()
+	e, ok := f.cache[rawURL]
+	f.mu.Unlock()
+	if ok && (e.err == nil || time.Since(e.fetched) < failedRetry) {
+		return e.preview, e.err
+	}
+
+	preview, err := f.fetch(ctx, rawURL)
+	if ctx.Err() != nil {
+		return nil, ctx.Err() // Отмененную загрузку не запоминаем
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.cache[rawURL]; !ok {
+		f.order = append(f.order, rawURL)
+		if len(f.order) > maxCached {
+			delete(f.cache, f.order[0])
+			f.order = f.order[1:]
+		}
+	}
+	f.cache[rawURL] = entry{preview: preview, err: err, fetched: time.Now()}
+	return preview, err
+}
+
+// fetch загружает начало страницы и ее значок
+func (f *Fetcher) fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	pageURL, err := url.Parse(rawURL)
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") {
+		return nil, fmt.Errorf("некорректный адрес ссылки '%s'", rawURL)
+	}
+	body, contentType, err := f.get(ctx, pageURL.String(), maxHeadSize, false)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось загрузить страницу: %w", err)
+	}
+	var p page
+	if strings.Contains(contentType, "html") {
+		reader, err := charset.NewReader(bytes.NewReader(body), contentType)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось определить кодировку страницы: %w", err)
+		}
+		p = parseHead(reader)
+	}
+	preview := p.preview(pageURL)
+	if iconURL := p.iconURL(pageURL); iconURL != "" {
+		if icon, iconType, err := f.get(ctx, iconURL, maxIconSize, true); err == nil && strings.HasPrefix(iconType, "image/") {
+			preview.Icon = icon
+		}
+	}
+	return preview, nil
+}
+
+// get выполняет GET-запрос и возвращает тело ответа и его Content-Type. Тело длиннее limit
+// считается ошибкой, если strict, иначе обрезается.
+func (f *Fetcher) get(ctx context.Context, target string, limit int64, strict bool) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", "GNote link preview")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("сервер вернул статус %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > limit {
+		if strict {
+			return nil, "", fmt.Errorf("размер ответа превышает %d байт", limit)
+		}
+		data = data[:limit] // Для карточки достаточно начала страницы
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
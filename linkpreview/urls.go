@@ -0,0 +1,30 @@
+package linkpreview
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// urlRe находит адреса http(s) в тексте заметки
+var urlRe = regexp.MustCompile(`https?://[^\s<>"'()\[\]{}]+`)
+
+// FindURLs возвращает до limit (0 — все) разных адресов http(s) из текста в порядке появления.
+// Знаки препинания в конце адреса считаются концом предложения, а не частью ссылки.
+func FindURLs(text string, limit int) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, match := range urlRe.FindAllString(text, -1) {
+		match = strings.TrimRight(match, ".,;:!?…»")
+		u, err := url.Parse(match)
+		if err != nil || u.Host == "" || seen[match] {
+			continue
+		}
+		seen[match] = true
+		urls = append(urls, match)
+		if limit > 0 && len(urls) == limit {
+			break
+		}
+	}
+	return urls
+}
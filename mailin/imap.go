@@ -0,0 +1,283 @@
+package mailin
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLiteral — предельный размер строкового литерала в ответе сервера (письмо целиком)
+const maxLiteral = 50 << 20
+
+// response — нетегированный ответ сервера: строка без литералов и содержимое литералов по порядку
+type response struct {
+	line     string
+	literals [][]byte
+}
+
+// imapClient — минимальный клиент IMAP4rev1: ровно то, что нужно для чтения новых писем
+type imapClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// dialIMAP подключается к серверу addr (host:port) и читает приветствие. Без useTLS соединение
+// защищается командой STARTTLS; открытым остается только соединение с этим же компьютером.
+func dialIMAP(ctx context.Context, addr string, useTLS bool) (*imapClient, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный адрес сервера %s: %w", addr, err)
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	var conn net.Conn
+	if useTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось подключиться к %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c := &imapClient{conn: conn, r: bufio.NewReader(conn)}
+	greeting, err := c.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("сервер %s не ответил: %w", addr, err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") && !strings.HasPrefix(greeting, "* PREAUTH") {
+		conn.Close()
+		return nil, fmt.Errorf("сервер %s отказал в подключении: %s", addr, greeting)
+	}
+	if !useTLS {
+		if err := c.startTLS(ctx, host); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("сервер %s: %w", addr, err)
+		}
+	}
+	return c, nil
+}
+
+// startTLS переводит соединение на TLS командой STARTTLS. Если сервер ее не поддерживает,
+// подключение отклоняется: иначе пароль ушел бы по сети открытым текстом.
+func (c *imapClient) startTLS(ctx context.Context, host string) error {
+	caps, err := c.capabilities()
+	if err != nil {
+		return err
+	}
+	if !hasCapability(caps, "STARTTLS") {
+		if isLoopbackHost(host) {
+			return nil // Соединение не покидает компьютер, например туннель к серверу
+		}
+		return fmt.Errorf("сервер не поддерживает STARTTLS; включите tls = true в [email]")
+	}
+	if _, err := c.command("STARTTLS"); err != nil {
+		return fmt.Errorf("не удалось включить TLS: %w", err)
+	}
+	tlsConn := tls.Client(c.conn, &tls.Config{ServerName: host})
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("не удалось включить TLS: %w", err)
+	}
+	c.conn = tlsConn
+	c.r = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// capabilities возвращает возможности сервера из ответа на CAPABILITY
+func (c *imapClient) capabilities() ([]string, error) {
+	responses, err := c.command("CAPABILITY")
+	if err != nil {
+		return nil, fmt.Errorf("не удалось узнать возможности сервера: %w", err)
+	}
+	var caps []string
+	for _, resp := range responses {
+		fields := strings.Fields(resp.line)
+		if len(fields) >= 2 && fields[0] == "*" && strings.EqualFold(fields[1], "CAPABILITY") {
+			caps = append(caps, fields[2:]...)
+		}
+	}
+	return caps, nil
+}
+
+// hasCapability сообщает, что среди возможностей сервера есть name
+func hasCapability(caps []string, name string) bool {
+	for _, c := range caps {
+		if strings.EqualFold(c, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoopbackHost сообщает, что host — этот же компьютер
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Close завершает сеанс и закрывает соединение
+func (c *imapClient) Close() error {
+	c.command("LOGOUT")
+	return c.conn.Close()
+}
+
+// login входит в почтовый ящик; сервер, запретивший LOGIN (LOGINDISABLED), пароль не получает
+func (c *imapClient) login(user, password string) error {
+	caps, err := c.capabilities()
+	if err != nil {
+		return err
+	}
+	if hasCapability(caps, "LOGINDISABLED") {
+		return fmt.Errorf("сервер запретил вход по паролю (LOGINDISABLED) для %s", user)
+	}
+	if _, err := c.command("LOGIN " + quote(user) + " " + quote(password)); err != nil {
+		return fmt.Errorf("не удалось войти в почтовый ящик %s: %w", user, err)
+	}
+	return nil
+}
+
+// selectMailbox открывает папку
+func (c *imapClient) selectMailbox(name string) error {
+	if _, err := c.command("SELECT " + quote(name)); err != nil {
+		return fmt.Errorf("не удалось открыть папку %s: %w", name, err)
+	}
+	return nil
+}
+
+// unseen возвращает UID непрочитанных писем
+func (c *imapClient) unseen() ([]uint32, error) {
+	responses, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при поиске новых писем: %w", err)
+	}
+	var uids []uint32
+	for _, resp := range responses {
+		fields := strings.Fields(resp.line)
+		if len(fields) < 2 || fields[0] != "*" || !strings.EqualFold(fields[1], "SEARCH") {
+			continue
+		}
+		for _, f := range fields[2:] {
+			if uid, err := strconv.ParseUint(f, 10, 32); err == nil {
+				uids = append(uids, uint32(uid))
+			}
+		}
+	}
+	return uids, nil
+}
+
+// fetch возвращает письмо целиком, не отмечая его прочитанным
+func (c *imapClient) fetch(uid uint32) ([]byte, error) {
+	responses, err := c.command(fmt.Sprintf("UID FETCH %d (BODY.PEEK[])", uid))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при загрузке письма UID %d: %w", uid, err)
+	}
+	for _, resp := range responses {
+		if strings.Contains(strings.ToUpper(resp.line), "FETCH") && len(resp.literals) > 0 {
+			return resp.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("сервер не вернул письмо UID %d", uid)
+}
+
+// markSeen отмечает письмо прочитанным, чтобы не импортировать его повторно
+func (c *imapClient) markSeen(uid uint32) error {
+	if _, err := c.command(fmt.Sprintf(`UID STORE %d +FLAGS.SILENT (\Seen)`, uid)); err != nil {
+		return fmt.Errorf("не удалось отметить письмо UID %d прочитанным: %w", uid, err)
+	}
+	return nil
+}
+
+// command отправляет команду и возвращает нетегированные ответы; ошибка — если сервер ответил не OK
+func (c *imapClient) command(cmd string) ([]response, error) {
+	c.tag++
+	tag := fmt.Sprintf("G%03d", c.tag)
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, cmd); err != nil {
+		return nil, err
+	}
+	var responses []response
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		status, ok := strings.CutPrefix(resp.line, tag+" ")
+		if !ok {
+			responses = append(responses, resp)
+			continue
+		}
+		if !strings.HasPrefix(strings.ToUpper(status), "OK") {
+			return nil, fmt.Errorf("сервер ответил: %s", status)
+		}
+		return responses, nil
+	}
+}
+
+// readResponse читает ответ сервера вместе с литералами {n}, которые могут быть внутри строки
+func (c *imapClient) readResponse() (response, error) {
+	var resp response
+	var b strings.Builder
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return resp, err
+		}
+		n, rest, ok := literalSize(line)
+		if !ok {
+			b.WriteString(line)
+			resp.line = b.String()
+			return resp, nil
+		}
+		if n > maxLiteral {
+			return resp, fmt.Errorf("ответ сервера больше %d МБ", maxLiteral>>20)
+		}
+		b.WriteString(rest)
+		literal := make([]byte, n)
+		if _, err := io.ReadFull(c.r, literal); err != nil {
+			return resp, err
+		}
+		resp.literals = append(resp.literals, literal)
+	}
+}
+
+// readLine читает строку ответа без CRLF
+func (c *imapClient) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// literalSize разбирает литерал {n} в конце строки: возвращает его размер и строку без него
+func literalSize(line string) (int, string, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, "", false
+	}
+	open := strings.LastIndexByte(line, '{')
+	if open == -1 {
+		return 0, "", false
+	}
+	n, err := strconv.Atoi(line[open+1 : len(line)-1])
+	if err != nil || n < 0 {
+		return 0, "", false
+	}
+	return n, line[:open], true
+}
+
+// quote возвращает строку IMAP в кавычках
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,77 @@
+package mailin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeServer отвечает на команды клиента как сервер IMAP с возможностями caps и сообщает,
+// получил ли он LOGIN. STARTTLS отклоняется: тесту достаточно того, что клиент его запросил.
+func fakeServer(conn net.Conn, caps string, login chan<- bool) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	gotLogin := false
+	defer func() { login <- gotLogin }()
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, cmd, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch verb, _, _ := strings.Cut(cmd, " "); verb {
+		case "CAPABILITY":
+			fmt.Fprintf(conn, "* CAPABILITY %s\r\n%s OK CAPABILITY completed\r\n", caps, tag)
+		case "STARTTLS":
+			fmt.Fprintf(conn, "%s NO TLS недоступен\r\n", tag)
+		case "LOGIN":
+			gotLogin = true
+			fmt.Fprintf(conn, "%s OK LOGIN completed\r\n", tag)
+		default:
+			fmt.Fprintf(conn, "%s BAD неизвестная команда\r\n", tag)
+		}
+	}
+}
+
+func TestLoginRequiresSecureConnection(t *testing.T) {
+	tests := []struct {
+		name      string
+		host      string
+		caps      string
+		wantLogin bool
+		wantErr   string
+	}{
+		{"без STARTTLS на удаленный сервер", "imap.example.com", "IMAP4rev1", false, "STARTTLS"},
+		{"STARTTLS запрашивается", "imap.example.com", "IMAP4rev1 STARTTLS", false, "не удалось включить TLS"},
+		{"открытый текст на этот компьютер", "127.0.0.1", "IMAP4rev1", true, ""},
+		{"localhost", "localhost", "IMAP4rev1", true, ""},
+		{"LOGINDISABLED", "localhost", "IMAP4rev1 LOGINDISABLED", false, "LOGINDISABLED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			login := make(chan bool, 1)
+			go fakeServer(server, tt.caps, login)
+
+			c := &imapClient{conn: client, r: bufio.NewReader(client)}
+			err := c.startTLS(context.Background(), tt.host)
+			if err == nil {
+				err = c.login("user", "secret")
+			}
+			client.Close()
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ошибка: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ошибка = %v, ожидалась с %q", err, tt.wantErr)
+			}
+			if got := <-login; got != tt.wantLogin {
+				t.Errorf("LOGIN отправлен: %v, ожидалось %v", got, tt.wantLogin)
+			}
+		})
+	}
+}
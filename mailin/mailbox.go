@@ -0,0 +1,60 @@
+package mailin
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// Mailbox — почтовый ящик IMAP, письма из которого превращаются в заметки
+type Mailbox struct {
+	Addr     string // Сервер host:port
+	TLS      bool   // Подключаться по TLS (обычно порт 993); иначе через STARTTLS (порт 143)
+	User     string
+	Password string
+	Folder   string // Папка с письмами, обычно INBOX
+}
+
+// Receive загружает непрочитанные письма и передает каждое в handle. Письмо отмечается прочитанным,
+// только если handle вернул nil; иначе оно будет загружено снова при следующей проверке.
+// Возвращает количество обработанных писем.
+func (m Mailbox) Receive(ctx context.Context, handle func(msg *Message) error) (int, error) {
+	c, err := dialIMAP(ctx, m.Addr, m.TLS)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+	if err := c.login(m.User, m.Password); err != nil {
+		return 0, err
+	}
+	if err := c.selectMailbox(m.Folder); err != nil {
+		return 0, err
+	}
+	uids, err := c.unseen()
+	if err != nil {
+		return 0, err
+	}
+
+	handled := 0
+	for _, uid := range uids {
+		if ctx.Err() != nil {
+			return handled, ctx.Err()
+		}
+		raw, err := c.fetch(uid)
+		if err != nil {
+			return handled, err
+		}
+		msg, err := Parse(raw)
+		if err != nil {
+			// Письмо, которое не удается разобрать, не разберется и потом: оставляем его в ящике прочитанным
+			log.Printf("Письмо UID %d в %s пропущено: %v", uid, m.Folder, err)
+		} else if err := handle(msg); err != nil {
+			return handled, fmt.Errorf("письмо «%s»: %w", msg.Subject, err)
+		}
+		if err := c.markSeen(uid); err != nil {
+			return handled, err
+		}
+		handled++
+	}
+	return handled, nil
+}
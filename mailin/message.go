@@ -0,0 +1,166 @@
+package mailin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"GNote/clipper"
+)
+
+// wordDecoder раскодирует заголовки вида =?koi8-r?B?...?= в любых кодировках, известных x/net
+var wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// Attachment — файл, приложенный к письму
+type Attachment struct {
+	Filename string
+	MimeType string
+	Data     []byte
+}
+
+// Message — разобранное письмо
+type Message struct {
+	Subject     string
+	From        string
+	Date        time.Time
+	Text        string // Текст письма; если есть только HTML — он переводится в markdown
+	Attachments []Attachment
+}
+
+// Parse разбирает письмо в формате RFC 5322 с вложениями MIME
+func Parse(raw []byte) (*Message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при разборе письма: %w", err)
+	}
+	m := &Message{
+		Subject: decodeHeader(msg.Header.Get("Subject")),
+		From:    decodeHeader(msg.Header.Get("From")),
+	}
+	m.Date, _ = msg.Header.Date()
+
+	var parts bodyParts
+	if err := parts.walk(textproto.MIMEHeader(msg.Header), msg.Body); err != nil {
+		return nil, err
+	}
+	m.Text = strings.TrimSpace(parts.plain)
+	if m.Text == "" && parts.html != "" {
+		if m.Text, err = clipper.MarkdownFromHTML(strings.NewReader(parts.html)); err != nil {
+			return nil, err
+		}
+		m.Text = strings.TrimSpace(m.Text)
+	}
+	m.Attachments = parts.attachments
+	return m, nil
+}
+
+// bodyParts собирает текстовые части и вложения письма
+type bodyParts struct {
+	plain, html string
+	attachments []Attachment
+}
+
+// walk обходит часть письма с заголовками header, спускаясь во вложенные multipart
+func (p *bodyParts) walk(header textproto.MIMEHeader, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil // Так читаются письма без Content-Type
+	}
+	body = transferDecoder(header.Get("Content-Transfer-Encoding"), body)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart() // Кодировку частей раскодирует walk
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("ошибка при разборе частей письма: %w", err)
+			}
+			if err := p.walk(part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	disposition, dispParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := decodeHeader(dispParams["filename"])
+	if filename == "" {
+		filename = decodeHeader(params["name"])
+	}
+	isText := mediaType == "text/plain" || mediaType == "text/html"
+	if disposition == "attachment" || !isText {
+		data, err := io.ReadAll(io.LimitReader(body, maxLiteral))
+		if err != nil {
+			return fmt.Errorf("ошибка при чтении вложения письма: %w", err)
+		}
+		if filename == "" {
+			filename = defaultFilename(mediaType, len(p.attachments)+1)
+		}
+		p.attachments = append(p.attachments, Attachment{Filename: filename, MimeType: mediaType, Data: data})
+		return nil
+	}
+
+	if cs := params["charset"]; cs != "" {
+		if body, err = charset.NewReaderLabel(cs, body); err != nil {
+			return fmt.Errorf("неизвестная кодировка письма %s: %w", cs, err)
+		}
+	}
+	text, err := io.ReadAll(io.LimitReader(body, maxLiteral))
+	if err != nil {
+		return fmt.Errorf("ошибка при чтении текста письма: %w", err)
+	}
+	// Из альтернативных частей берется первая; остальные (например, подписи) дописываются
+	switch {
+	case mediaType == "text/html" && p.html == "":
+		p.html = string(text)
+	case mediaType == "text/plain" && p.plain == "":
+		p.plain = string(text)
+	case mediaType == "text/plain":
+		p.plain += "\n\n" + string(text)
+	}
+	return nil
+}
+
+// transferDecoder раскодирует содержимое части письма по Content-Transfer-Encoding
+func transferDecoder(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r) // Переводы строк декодер пропускает сам
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
+
+// decodeHeader раскодирует закодированные слова в заголовке; при ошибке заголовок возвращается как есть
+func decodeHeader(s string) string {
+	decoded, err := wordDecoder.DecodeHeader(s)
+	if err != nil {
+		return s
+	}
+	return decoded
+}
+
+// defaultFilename придумывает имя вложению без имени по его типу
+func defaultFilename(mediaType string, n int) string {
+	if mediaType == "message/rfc822" {
+		return fmt.Sprintf("письмо-%d.eml", n)
+	}
+	name := fmt.Sprintf("вложение-%d", n)
+	if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+		name += exts[0]
+	}
+	return name
+}
@@ -0,0 +1,162 @@
+package mailin
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// crlf переводит строки письма в CRLF, как они приходят с сервера
+func crlf(s string) []byte {
+	return []byte(strings.ReplaceAll(strings.TrimPrefix(s, "\n"), "\n", "\r\n"))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		subject     string
+		from        string
+		text        string
+		attachments []Attachment
+	}{
+		{
+			name: "простое письмо без Content-Type",
+			raw: `
+Subject: Привет
+From: Иван <ivan@example.com>
+Date: Mon, 03 Mar 2025 10:00:00 +0300
+
+Текст письма
+`,
+			subject: "Привет", from: "Иван <ivan@example.com>", text: "Текст письма",
+		},
+		{
+			name: "закодированная тема и текст KOI8-R в quoted-printable",
+			raw: `
+Subject: =?koi8-r?B?8NLJ18XU?=
+From: a@example.com
+Content-Type: text/plain; charset=koi8-r
+Content-Transfer-Encoding: quoted-printable
+
+=F0=D2=C9=D7=C5=D4
+`,
+			subject: "Привет", from: "a@example.com", text: "Привет",
+		},
+		{
+			name: "только HTML переводится в markdown",
+			raw: `
+Subject: html
+Content-Type: text/html; charset=utf-8
+
+<html><body><p><b>Важно</b>: <a href="https://example.com">ссылка</a></p></body></html>
+`,
+			subject: "html", text: "**Важно**: [ссылка](https://example.com)",
+		},
+		{
+			name: "alternative: текстовая часть важнее HTML",
+			raw: `
+Subject: alt
+Content-Type: multipart/alternative; boundary=b1
+
+--b1
+Content-Type: text/plain; charset=utf-8
+
+Обычный текст
+--b1
+Content-Type: text/html; charset=utf-8
+
+<p>HTML</p>
+--b1--
+`,
+			subject: "alt", text: "Обычный текст",
+		},
+		{
+			name: "вложения в base64, с именем и без",
+			raw: `
+Subject: =?utf-8?Q?=D0=A1=D1=87=D0=B5=D1=82?=
+Content-Type: multipart/mixed; boundary=b2
+
+--b2
+Content-Type: text/plain; charset=utf-8
+
+См. вложение
+--b2
+Content-Type: application/pdf; name="=?utf-8?B?0YHRh9C10YIucGRm?="
+Content-Disposition: attachment
+Content-Transfer-Encoding: base64
+
+JVBERi0x
+LjQ=
+--b2
+Content-Type: image/png
+Content-Transfer-Encoding: base64
+
+iVBORw==
+--b2--
+`,
+			subject: "Счет", text: "См. вложение",
+			attachments: []Attachment{
+				{Filename: "счет.pdf", MimeType: "application/pdf", Data: []byte("%PDF-1.4")},
+				{Filename: "вложение-2.png", MimeType: "image/png", Data: []byte("\x89PNG")},
+			},
+		},
+		{
+			name: "вложенное письмо",
+			raw: `
+Subject: fwd
+Content-Type: multipart/mixed; boundary=b3
+
+--b3
+Content-Type: text/plain
+
+Пересылаю
+--b3
+Content-Type: message/rfc822
+
+Subject: inner
+
+body
+--b3--
+`,
+			subject: "fwd", text: "Пересылаю",
+			attachments: []Attachment{
+				{Filename: "письмо-1.eml", MimeType: "message/rfc822", Data: crlf("\nSubject: inner\n\nbody")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := Parse(crlf(tt.raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg.Subject != tt.subject || msg.From != tt.from || msg.Text != tt.text {
+				t.Errorf("Parse() = %q, %q, %q; ожидалось %q, %q, %q", msg.Subject, msg.From, msg.Text, tt.subject, tt.from, tt.text)
+			}
+			if len(msg.Attachments) != len(tt.attachments) {
+				t.Fatalf("вложений %d, ожидалось %d: %+v", len(msg.Attachments), len(tt.attachments), msg.Attachments)
+			}
+			for i, want := range tt.attachments {
+				got := msg.Attachments[i]
+				if got.Filename != want.Filename || got.MimeType != want.MimeType || string(got.Data) != string(want.Data) {
+					t.Errorf("вложение %d = %q %q %q, ожидалось %q %q %q", i,
+						got.Filename, got.MimeType, got.Data, want.Filename, want.MimeType, want.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	msg, err := Parse(crlf("\nSubject: x\nDate: Mon, 03 Mar 2025 10:00:00 +0300\n\nx\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 3, 3, 7, 0, 0, 0, time.UTC); !msg.Date.Equal(want) {
+		t.Errorf("Date = %v, ожидалось %v", msg.Date, want)
+	}
+	if _, err := Parse([]byte("не письмо")); err == nil {
+		t.Error("Parse() без заголовков: ожидалась ошибка")
+	}
+}
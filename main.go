@@ -1,68 +1,140 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"io"
 	"log"
 	"os"
-	"strconv"
+	"path/filepath"
 
-	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 
+	"GNote/config"
+	"GNote/deeplink"
+	"GNote/journal"
 	"GNote/storage"
 	"GNote/ui" 
 )
 
 func main() {
-
-	dbHost := os.Getenv("DB_HOST")
-	if dbHost == "" {
-		dbHost = "localhost"
-	}
-	dbPortStr := os.Getenv("DB_PORT")
-	dbPort, err := strconv.Atoi(dbPortStr)
-	if err != nil {
-		dbPort = 5432
+	daemon := flag.Bool("daemon", false, "Фоновый режим: только напоминания и значок в трее, окно открывается по требованию")
+	registerScheme := flag.Bool("register-scheme", false, "Зарегистрировать GNote как обработчик ссылок gnote:// и выйти")
+	dbURL := flag.String("db-url", "", "Строка подключения к БД (postgres://...), имеет приоритет над настройками")
+	profile := flag.String("profile", "", "Профиль из файла настроек ([profiles.<имя>]), например work")
+	dataDir := flag.String("data-dir", "", "Каталог данных (вложения) вместо каталога данных приложения")
+	serve := flag.Bool("serve", false, "Режим сервера без окон: лента изменений для окон GNote и RSS заметок на адресе из раздела [server] настроек")
+	portable := flag.Bool("portable", false, "Переносной режим: настройки, вложения, кэш и журнал в каталоге gnote-portable рядом с программой")
+	migrate := flag.String("migrate-attachments", "", "Перенести содержимое вложений в режим database (в БД) или files (в каталог вложений) и выйти")
+	flag.Parse()
+	if *portable {
+		dir, err := config.UsePortable()
+		if err != nil {
+			log.Fatalf("Ошибка переносного режима: %v", err)
+		}
+		log.Printf("Переносной режим: данные в %s", dir)
 	}
-	dbUser := os.Getenv("DB_USER")
-	if dbUser == "" {
-		dbUser = "dima"
+	deeplink.SetInstance(*profile) // У каждого профиля свой экземпляр для приема ссылок
+
+	if *registerScheme {
+		if err := deeplink.Register(); err != nil {
+			log.Fatalf("Не удалось зарегистрировать обработчик ссылок: %v", err)
+		}
+		log.Printf("GNote зарегистрирован как обработчик ссылок %s://", deeplink.Scheme)
+		return
 	}
-	dbPassword := os.Getenv("DB_PASSWORD")
-	if dbPassword == "" {
-		dbPassword = ""
+
+	// Ссылка вида gnote://note/{id}, переданная системой при открытии
+	link := flag.Arg(0)
+	if link != "" {
+		if err := deeplink.Send(link); err == nil {
+			log.Printf("Ссылка '%s' передана запущенному экземпляру", link)
+			return
+		}
 	}
-	dbName := os.Getenv("DB_NAME")
-	if dbName == "" {
-		dbName = "gnote_db"
+
+	// Журнал пишется и в консоль, и в файл в каталоге состояния ($XDG_STATE_HOME/gnote/gnote.log).
+	// Файл открывается после передачи ссылки, чтобы короткий запуск не сдвигал журнал работающего экземпляра
+	if logFile, err := config.OpenLogFile(); err != nil {
+		log.Printf("Журнал в файл не пишется: %v", err)
+	} else {
+		log.SetOutput(io.MultiWriter(os.Stderr, logFile))
+		defer logFile.Close()
 	}
-	dbSSLMode := os.Getenv("DB_SSLMODE")
-	if dbSSLMode == "" {
-		dbSSLMode = "disable" 
+	if cacheDir, err := config.CacheDir(); err == nil {
+		ui.SetCacheDir(cacheDir)
 	}
 
-	dbConfig := storage.Config{
-		Host:     dbHost,
-		Port:     dbPort,
-		User:     dbUser,
-		Password: dbPassword,
-		DBName:   dbName,
-		SSLMode:  dbSSLMode,
-	}
+	// Инициализация Fyne приложения
+	a := app.NewWithID("com.github.dmitryreaper.gnote") // ID нужен для постоянных настроек и каталога данных
 
-	// Инициализация хранилища (PostgreSQL)
-	store, err := storage.NewPostgresStore(dbConfig)
+	// Настройки из $XDG_CONFIG_HOME/gnote/config.toml, на телефоне — из каталога данных приложения,
+	// так как домашнего каталога там нет; переменные окружения имеют приоритет
+	configPath, err := config.DefaultPath()
+	if a.Driver().Device().IsMobile() {
+		configPath, err = filepath.Join(a.Storage().RootURI().Path(), "config.toml"), nil
+	}
 	if err != nil {
-		log.Fatalf("Ошибка при инициализации хранилища БД: %v", err)
+		log.Fatalf("Ошибка при загрузке настроек: %v", err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Ошибка при загрузке настроек: %v", err)
 	}
 
-	// Инициализация Fyne приложения
-	a := app.New()
-	w := a.NewWindow("Приложение для заметок")
-	w.SetIcon(fyne.NewStaticResource("note.png", []byte{})) 
+	l := &launcher{app: a, cfg: cfg, profile: *profile, dbURL: *dbURL, dataDir: *dataDir, daemon: *daemon, link: link}
+	defer l.shutdown()
+	l.migrateFyneDirs()
 
-	// Создание и запуск UI приложения
-	noteApp := ui.NewNoteApp(w, store)
-	_ = noteApp 
+	if *migrate != "" {
+		if err := l.migrateAttachments(*migrate); err != nil {
+			log.Fatalf("Ошибка при переносе вложений: %v", err)
+		}
+		return
+	}
+	if *serve {
+		if err := l.serve(); err != nil {
+			log.Fatalf("Ошибка сервера: %v", err)
+		}
+		return
+	}
 
-	w.ShowAndRun()
+	// Открываем профиль, выбранный при запуске; остальные открываются из меню "Профиль"
+	session, err := l.openProfile(*profile)
+	var schemaErr *storage.SchemaError
+	if errors.As(err, &schemaErr) {
+		log.Printf("Ошибка при подключении к БД: %v", err)
+		ui.ShowSchemaError(a, schemaErr, func() (*ui.ProfileSession, error) {
+			if err := l.migrateSchema(); err != nil {
+				return nil, err
+			}
+			return l.openProfile(l.profile)
+		}, l.start)
+	} else if err != nil {
+		log.Printf("Ошибка при подключении к БД: %v", err)
+		initialURL := l.dbURL
+		if initialURL == "" {
+			initialURL = cfg.Database.URL
+		}
+		ui.ShowConnectionError(a, err, initialURL, func(url string) (*ui.ProfileSession, error) {
+			l.dbURL = url
+			return l.openProfile(l.profile)
+		}, l.start)
+	} else {
+		l.start(session)
+	}
+	a.Run()
+}
+
+// contextProviders собирает провайдеров контекста для дневниковых записей.
+// Погода добавляется, только если задан GNOTE_WEATHER_URL.
+func contextProviders() []journal.ContextProvider {
+	providers := []journal.ContextProvider{journal.DateProvider{}}
+	if weatherURL := os.Getenv("GNOTE_WEATHER_URL"); weatherURL != "" {
+		providers = append(providers, &journal.WeatherProvider{
+			URL:  weatherURL,
+			City: os.Getenv("GNOTE_WEATHER_CITY"),
+		})
+	}
+	return providers
 }
@@ -0,0 +1,93 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitTimeout ограничивает одну команду git (push по сети может зависнуть)
+const gitTimeout = 2 * time.Minute
+
+// gitIgnore не дает репозиторию основного профиля включить копии других профилей,
+// которые лежат в его подкаталоге profiles/ и ведут собственные репозитории
+const gitIgnore = "/profiles/\n.gnote-*.tmp\n"
+
+// UseGit включает версионирование каталога в git: после каждого обновления копии изменения
+// фиксируются коммитом, а если задан remote (имя или адрес) — еще и отправляются туда
+func (m *Mirror) UseGit(remote string) {
+	m.git = true
+	m.remote = remote
+}
+
+// Commit фиксирует изменения каталога коммитом с сообщением message и отправляет их в remote.
+// Без UseGit ничего не делает. Репозиторий создается при первом коммите.
+func (m *Mirror) Commit(message string) error {
+	if !m.git {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(m.dir, ".git")); os.IsNotExist(err) {
+		if _, err := m.runGit("init"); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(m.dir, ".gitignore"), []byte(gitIgnore), 0644); err != nil {
+			return fmt.Errorf("не удалось создать .gitignore копии заметок: %w", err)
+		}
+	}
+	if _, err := m.runGit("add", "--all"); err != nil {
+		return err
+	}
+	status, err := m.runGit("status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	if status == "" {
+		return m.push() // Повторяем отправку, если прошлая не удалась
+	}
+	if email, _ := m.runGit("config", "user.email"); email == "" {
+		// Без автора git отказывается делать коммит; задаем его только для этого репозитория
+		if _, err := m.runGit("config", "user.name", "GNote"); err != nil {
+			return err
+		}
+		if _, err := m.runGit("config", "user.email", "gnote@localhost"); err != nil {
+			return err
+		}
+	}
+	if _, err := m.runGit("commit", "--quiet", "--message", message); err != nil {
+		return err
+	}
+	return m.push()
+}
+
+// push отправляет коммиты в remote, если он задан
+func (m *Mirror) push() error {
+	if m.remote == "" {
+		return nil
+	}
+	_, err := m.runGit("push", "--quiet", m.remote, "HEAD")
+	return err
+}
+
+// runGit выполняет команду git в каталоге копии и возвращает ее вывод
+func (m *Mirror) runGit(args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = m.dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0") // Не ждать ввода пароля в фоне
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s завершился с ошибкой: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("git %s завершился с ошибкой: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
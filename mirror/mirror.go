@@ -0,0 +1,224 @@
+package mirror
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"GNote/models"
+)
+
+// maxSlugLength — длина части имени файла из заголовка заметки (в символах)
+const maxSlugLength = 50
+
+// noteFilePattern распознает файлы заметок: "12-zagolovok.md" или "12.md".
+// Остальные файлы каталога (README, .git) не трогаются.
+var noteFilePattern = regexp.MustCompile(`^(\d+)(-.*)?\.md$`)
+
+// Mirror — каталог с копией заметок в markdown, по файлу на заметку,
+// чтобы у пользователя всегда была текстовая версия (например, под git)
+type Mirror struct {
+	dir    string
+	git    bool   // Фиксировать изменения в git
+	remote string // Куда отправлять коммиты; пусто — только локальный репозиторий
+}
+
+// New создает копию заметок в каталоге dir; каталог создается при первой записи
+func New(dir string) *Mirror {
+	return &Mirror{dir: dir}
+}
+
+// Dir возвращает каталог копии
+func (m *Mirror) Dir() string {
+	return m.dir
+}
+
+// FileName возвращает имя файла заметки: ID и заголовок, пригодный для имени файла.
+// Имя файла секретной заметки — только ID, чтобы заголовок не попал в список файлов.
+func FileName(note models.Note) string {
+	var b strings.Builder
+	dash := false
+	title := note.Title
+	if note.HasSensitiveTag() {
+		title = ""
+	}
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	slug := []rune(strings.TrimSuffix(b.String(), "-"))
+	if len(slug) > maxSlugLength {
+		slug = []rune(strings.TrimSuffix(string(slug[:maxSlugLength]), "-"))
+	}
+	if len(slug) == 0 {
+		return fmt.Sprintf("%d.md", note.ID)
+	}
+	return fmt.Sprintf("%d-%s.md", note.ID, string(slug))
+}
+
+// Render возвращает markdown заметки: свойства во front matter, затем заголовок и содержимое.
+// Секреты скрываются: копию часто отправляют в git на чужой сервер.
+func Render(note models.Note) []byte {
+	note = note.Redacted()
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "id: %d\n", note.ID)
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(note.Title))
+	if len(note.Tags) > 0 {
+		tags := make([]string, len(note.Tags))
+		for i, tag := range note.Tags {
+			tags[i] = strconv.Quote(tag)
+		}
+		fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(tags, ", "))
+	}
+	fmt.Fprintf(&b, "created: %s\n", note.CreatedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "updated: %s\n", note.UpdatedAt.UTC().Format(time.RFC3339))
+	if note.Favorite {
+		b.WriteString("favorite: true\n")
+	}
+	if note.Priority != models.PriorityNone {
+		fmt.Fprintf(&b, "priority: %d\n", note.Priority)
+	}
+	if note.DueAt != nil {
+		fmt.Fprintf(&b, "due: %s\n", note.DueAt.UTC().Format(time.RFC3339))
+	}
+	if note.SourceURL != "" {
+		fmt.Fprintf(&b, "source: %s\n", strconv.Quote(note.SourceURL))
+	}
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "# %s\n\n", note.Title)
+	b.WriteString(note.Content)
+	if !strings.HasSuffix(note.Content, "\n") {
+		b.WriteString("\n")
+	}
+	return b.Bytes()
+}
+
+// Sync приводит каталог к списку notes: записывает изменившиеся файлы и удаляет файлы
+// заметок, которых больше нет. Возвращает количество записанных и удаленных файлов.
+func (m *Mirror) Sync(notes []models.Note) (written, removed int, err error) {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return 0, 0, fmt.Errorf("не удалось создать каталог копии заметок: %w", err)
+	}
+	wanted := make(map[string]bool, len(notes))
+	for _, note := range notes {
+		name := FileName(note)
+		wanted[name] = true
+		changed, err := m.write(name, Render(note))
+		if err != nil {
+			return written, removed, err
+		}
+		if changed {
+			written++
+		}
+	}
+	names, err := m.noteFiles()
+	if err != nil {
+		return written, removed, err
+	}
+	for name := range names {
+		if wanted[name] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(m.dir, name)); err != nil {
+			return written, removed, fmt.Errorf("не удалось удалить файл копии %s: %w", name, err)
+		}
+		removed++
+	}
+	return written, removed, nil
+}
+
+// WriteNote записывает файл заметки и удаляет ее файл со старым заголовком
+func (m *Mirror) WriteNote(note models.Note) error {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return fmt.Errorf("не удалось создать каталог копии заметок: %w", err)
+	}
+	name := FileName(note)
+	if _, err := m.write(name, Render(note)); err != nil {
+		return err
+	}
+	return m.removeFiles(note.ID, name)
+}
+
+// RemoveNote удаляет файл заметки
+func (m *Mirror) RemoveNote(id int) error {
+	return m.removeFiles(id, "")
+}
+
+// removeFiles удаляет файлы заметки id, кроме keep
+func (m *Mirror) removeFiles(id int, keep string) error {
+	names, err := m.noteFiles()
+	if err != nil {
+		return err
+	}
+	for name, noteID := range names {
+		if noteID != id || name == keep {
+			continue
+		}
+		if err := os.Remove(filepath.Join(m.dir, name)); err != nil {
+			return fmt.Errorf("не удалось удалить файл копии %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// noteFiles возвращает файлы заметок в каталоге и ID заметки каждого файла
+func (m *Mirror) noteFiles() (map[string]int, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("не удалось прочитать каталог копии заметок: %w", err)
+	}
+	names := make(map[string]int)
+	for _, entry := range entries {
+		match := noteFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		id, _ := strconv.Atoi(match[1])
+		names[entry.Name()] = id
+	}
+	return names, nil
+}
+
+// write записывает файл, только если его содержимое изменилось (чтобы не менять время
+// изменения зря); запись идет через временный файл, чтобы не оставить файл наполовину записанным
+func (m *Mirror) write(name string, data []byte) (bool, error) {
+	path := filepath.Join(m.dir, name)
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, data) {
+		return false, nil
+	}
+	tmp, err := os.CreateTemp(m.dir, ".gnote-*.tmp")
+	if err != nil {
+		return false, fmt.Errorf("не удалось создать временный файл копии: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // После переименования файла уже нет
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return false, fmt.Errorf("не удалось записать файл копии %s: %w", name, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return false, fmt.Errorf("не удалось записать файл копии %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return false, fmt.Errorf("не удалось записать файл копии %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return false, fmt.Errorf("не удалось сохранить файл копии %s: %w", name, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// ActivityAction — что произошло с заметкой
+type ActivityAction string
+
+const (
+	ActivityCreated  ActivityAction = "create" // Заметка создана
+	ActivityUpdated  ActivityAction = "update" // Заметка изменена; в Details — какие поля
+	ActivityDeleted  ActivityAction = "delete" // Заметка удалена
+	ActivityExported ActivityAction = "export" // Заметки выгружены в файл
+)
+
+// Activity — запись журнала изменений
+type Activity struct {
+	ID        int            `json:"id"`
+	At        time.Time      `json:"at"`
+	Action    ActivityAction `json:"action"`
+	NoteID    int            `json:"note_id"`    // 0 — действие над несколькими заметками (экспорт всех)
+	NoteTitle string         `json:"note_title"` // Заголовок на момент действия: заметку могли удалить
+	Details   string         `json:"details"`
+	Actor     string         `json:"actor"` // Кто внес изменение: пользователь@компьютер
+}
+
+// ChangedFields возвращает названия полей, которыми заметка after отличается от before
+func ChangedFields(before, after *Note) []string {
+	var fields []string
+	add := func(changed bool, name string) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+	add(before.Title != after.Title, "заголовок")
+	add(before.Content != after.Content, "содержимое")
+	add(!sameTags(before.Tags, after.Tags), "теги")
+	add(!sameTime(before.ReminderAt, after.ReminderAt), "напоминание")
+	add(!sameTime(before.DueAt, after.DueAt), "срок")
+	add(before.Priority != after.Priority, "приоритет")
+	add(before.Favorite != after.Favorite, "избранное")
+	add(before.SourceURL != after.SourceURL, "источник")
+	add(!sameLocation(before.Location, after.Location), "место")
+	return fields
+}
+
+// sameTags сравнивает теги без учета порядка
+func sameTags(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// sameTime сравнивает необязательные моменты времени
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// sameLocation сравнивает необязательные места
+func sameLocation(a, b *Location) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ExportVersion — версия формата файла экспорта. Файлы первой версии содержали только массив заметок.
+const ExportVersion = 2
+
+// Export — файл экспорта: заметки и их организация (оформление тегов, повторяющиеся заметки, настройки списка).
+// Иерархия тегов («блокноты» вида работа/проекты) сохраняется в тегах самих заметок.
+type Export struct {
+	Version        int                 `json:"version"`
+	ExportedAt     time.Time           `json:"exported_at"`
+	Notes          []Note              `json:"notes"`
+	TagStyles      map[string]TagStyle `json:"tag_styles,omitempty"`
+	RecurringRules []RecurringRule     `json:"recurring_rules,omitempty"`
+	Settings       *ExportSettings     `json:"settings,omitempty"`
+}
+
+// ExportSettings — настройки списка заметок, переносимые вместе с заметками
+type ExportSettings struct {
+	Sort string `json:"sort,omitempty"` // Вариант сортировки списка
+}
+
+// ParseExport разбирает файл экспорта любой версии; из файла первой версии берутся только заметки
+func ParseExport(data []byte) (*Export, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var notes []Note
+		if err := json.Unmarshal(trimmed, &notes); err != nil {
+			return nil, fmt.Errorf("ошибка при парсинге JSON: %w", err)
+		}
+		return &Export{Version: 1, Notes: notes}, nil
+	}
+	var export Export
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("ошибка при парсинге JSON: %w", err)
+	}
+	if export.Version > ExportVersion {
+		return nil, fmt.Errorf("файл создан более новой версией программы (формат %d), обновите GNote", export.Version)
+	}
+	return &export, nil
+}
+
+// IsEmpty сообщает, что в файле нет ни заметок, ни организации
+func (e *Export) IsEmpty() bool {
+	return len(e.Notes) == 0 && len(e.TagStyles) == 0 && len(e.RecurringRules) == 0 && e.Settings == nil
+}
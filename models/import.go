@@ -0,0 +1,31 @@
+package models
+
+// ImportResult — итог импорта одной заметки
+type ImportResult struct {
+	Note              Note              // Заметка с ID в БД; при ошибке — как в файле
+	Updated           bool              // Перезаписана существующая заметка, а не создана новая
+	Err               error             // Почему заметка не импортирована; nil — импортирована
+	FailedAttachments []AttachmentError // Вложения заметки, которые не удалось импортировать
+}
+
+// AttachmentError — вложение, которое не удалось импортировать, и причина
+type AttachmentError struct {
+	Filename string
+	Reason   string
+}
+
+// ImportReport — итоги импорта заметок в порядке, в котором они переданы
+type ImportReport struct {
+	Results []ImportResult
+}
+
+// Imported возвращает число импортированных заметок
+func (r ImportReport) Imported() int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Err == nil {
+			n++
+		}
+	}
+	return n
+}
@@ -1,27 +1,82 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strings"
 	"time"
 )
 
 type Note struct {
-	ID         int        `json:"id"`
-	Title      string     `json:"title"`
-	Content    string     `json:"content"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
-	ReminderAt *time.Time `json:"reminder_at"`
-	Tags       []string   `json:"tags"`
-	Attachments []Attachment `json:"attachments"` 
+	ID          int          `json:"id"`
+	UID         string       `json:"uid,omitempty"` // Постоянный идентификатор (UUID), одинаковый во всех базах при импорте и синхронизации
+	Title       string       `json:"title"`
+	Content     string       `json:"content"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	ReminderAt  *time.Time   `json:"reminder_at"`
+	DueAt       *time.Time   `json:"due_at,omitempty"` // Срок выполнения (в отличие от напоминания не уведомляет)
+	Tags        []string     `json:"tags"`
+	SourceURL   string       `json:"source_url,omitempty"` // Адрес страницы, с которой создана заметка
+	Location    *Location    `json:"location,omitempty"`   // Место, к которому привязана заметка
+	Favorite    bool         `json:"favorite"`             // Заметка отмечена звездочкой
+	Priority    Priority     `json:"priority"`             // Важность заметки
+	Attachments []Attachment `json:"attachments"`
+
+	ContentTruncated bool `json:"content_truncated,omitempty"` // В Content только начало: списки загружают превью больших заметок
+
+	AttachmentText  string   `json:"-"` // Текст, извлеченный из вложений (распознанный текст, расшифровки); для поиска
+	AttachmentNames []string `json:"-"` // Имена файлов вложений; для поиска file:
+	AttachmentTypes []string `json:"-"` // MIME-типы вложений; для поиска has:
 }
 
 // структура вложения
 type Attachment struct {
-	ID        int        `json:"id"`
-	NoteID    int        `json:"note_id"`
-	Filename  string     `json:"filename"`
-	Filepath  string     `json:"filepath"` // путь на диске
-	MimeType  string     `json:"mime_type"`
-	SizeBytes int64      `json:"size_bytes"`
-	UploadedAt time.Time `json:"uploaded_at"`
+	ID          int       `json:"id"`
+	NoteID      int       `json:"note_id"`
+	Filename    string    `json:"filename"`
+	Filepath    string    `json:"filepath"` // путь на диске; пусто, если содержимое хранится в БД
+	MimeType    string    `json:"mime_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+	InDatabase  bool      `json:"in_database"`           // содержимое хранится в БД как large object
+	Text        string    `json:"text,omitempty"`        // текст, извлеченный из вложения (распознанный на изображении, расшифровка аудио)
+	Description string    `json:"description,omitempty"` // подпись к вложению, заданная пользователем; участвует в поиске
+	Position    int       `json:"position"`              // место в списке вложений заметки
+	SourceURL   string    `json:"source_url,omitempty"`  // адрес, с которого файл загружен; пусто для локальных файлов
+}
+
+// Location — географическая точка, привязанная к заметке
+type Location struct {
+	Name string  `json:"name,omitempty"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+}
+
+// DistanceKm возвращает расстояние по поверхности Земли до другой точки в километрах (формула гаверсинусов)
+func (l Location) DistanceKm(other Location) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(other.Lat - l.Lat)
+	dLon := toRad(other.Lon - l.Lon)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(l.Lat))*math.Cos(toRad(other.Lat))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// ContentHash возвращает хеш заголовка и содержимого заметки,
+// используемый для поиска дубликатов (например, при импорте)
+func (n *Note) ContentHash() string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(n.Title) + "\x00" + strings.TrimSpace(n.Content)))
+	return hex.EncodeToString(sum[:])
+}
+
+// AttachmentMeta возвращает имена файлов и MIME-типы вложений для поиска
+func AttachmentMeta(attachments []Attachment) (names, types []string) {
+	for _, a := range attachments {
+		names = append(names, a.Filename)
+		types = append(types, a.MimeType)
+	}
+	return names, types
 }
@@ -0,0 +1,16 @@
+package models
+
+// Priority — важность заметки; чем больше значение, тем выше приоритет
+type Priority int
+
+const (
+	PriorityNone   Priority = iota // Приоритет не задан
+	PriorityLow                    // Низкий
+	PriorityMedium                 // Средний
+	PriorityHigh                   // Высокий
+)
+
+// Valid сообщает, что значение входит в перечисление приоритетов
+func (p Priority) Valid() bool {
+	return p >= PriorityNone && p <= PriorityHigh
+}
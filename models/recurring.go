@@ -0,0 +1,106 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency — как часто правило создает заметку
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "daily"   // Каждый день
+	FrequencyWeekly  Frequency = "weekly"  // Раз в неделю, в день Day (0 — воскресенье, как time.Weekday)
+	FrequencyMonthly Frequency = "monthly" // Раз в месяц, в число Day (в коротких месяцах — в последний день)
+)
+
+// RecurringRule — правило периодического создания заметки, например
+// «каждый понедельник в 9:00 создавать еженедельный отчет по шаблону с тегом работа/отчеты»
+type RecurringRule struct {
+	ID             int        `json:"id"`
+	Title          string     `json:"title"`            // Заголовок новой заметки; {date} и {week} заменяются датой и номером недели
+	TemplateNoteID int        `json:"template_note_id"` // Заметка-шаблон: ее содержимое и теги копируются (0 — без шаблона)
+	Tags           []string   `json:"tags"`             // Дополнительные теги новой заметки (например, блокнот "работа/отчеты")
+	Frequency      Frequency  `json:"frequency"`
+	Day            int        `json:"day"`  // День недели или число месяца; для ежедневных не используется
+	Time           string     `json:"time"` // Местное время создания "ЧЧ:ММ"
+	Enabled        bool       `json:"enabled"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"` // Когда правило сработало в последний раз
+}
+
+// clock разбирает время правила "ЧЧ:ММ"
+func (r RecurringRule) clock() (hour, minute int, err error) {
+	h, m, ok := strings.Cut(strings.TrimSpace(r.Time), ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("время %q должно быть в формате ЧЧ:ММ", r.Time)
+	}
+	if hour, err = strconv.Atoi(h); err != nil || hour < 0 || hour > 23 {
+		return 0, 0, fmt.Errorf("неверный час в %q", r.Time)
+	}
+	if minute, err = strconv.Atoi(m); err != nil || minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("неверные минуты в %q", r.Time)
+	}
+	return hour, minute, nil
+}
+
+// Validate проверяет, что правило можно сохранить
+func (r RecurringRule) Validate() error {
+	if strings.TrimSpace(r.Title) == "" {
+		return fmt.Errorf("заголовок не может быть пустым")
+	}
+	if _, _, err := r.clock(); err != nil {
+		return err
+	}
+	switch r.Frequency {
+	case FrequencyDaily:
+	case FrequencyWeekly:
+		if r.Day < 0 || r.Day > 6 {
+			return fmt.Errorf("неверный день недели: %d", r.Day)
+		}
+	case FrequencyMonthly:
+		if r.Day < 1 || r.Day > 31 {
+			return fmt.Errorf("неверное число месяца: %d", r.Day)
+		}
+	default:
+		return fmt.Errorf("неизвестная периодичность %q", r.Frequency)
+	}
+	return nil
+}
+
+// NextRun возвращает первый момент срабатывания правила строго после after в часовом поясе loc
+func (r RecurringRule) NextRun(after time.Time, loc *time.Location) (time.Time, error) {
+	if err := r.Validate(); err != nil {
+		return time.Time{}, err
+	}
+	hour, minute, _ := r.clock()
+	local := after.In(loc)
+	// Перебираем дни начиная с текущего; месяц — самый длинный период правил
+	for d := 0; d <= 62; d++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+d, hour, minute, 0, 0, loc)
+		if !day.After(after) || !r.matches(day) {
+			continue
+		}
+		return day, nil
+	}
+	return time.Time{}, fmt.Errorf("не удалось вычислить следующий запуск правила %q", r.Title)
+}
+
+// matches сообщает, что в день t правило срабатывает
+func (r RecurringRule) matches(t time.Time) bool {
+	switch r.Frequency {
+	case FrequencyWeekly:
+		return int(t.Weekday()) == r.Day
+	case FrequencyMonthly:
+		lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+		return t.Day() == min(r.Day, lastDay)
+	}
+	return true
+}
+
+// ExpandDatePlaceholders подставляет в текст дату и номер недели момента t: {date} → 02.01.2006, {week} → 14
+func ExpandDatePlaceholders(text string, t time.Time) string {
+	_, week := t.ISOWeek()
+	return strings.NewReplacer("{date}", t.Format("02.01.2006"), "{week}", strconv.Itoa(week)).Replace(text)
+}
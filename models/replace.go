@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// TextEdit — замена заголовка и содержимого заметки при массовой замене.
+// Старые значения нужны, чтобы не затереть заметку, измененную после поиска, и чтобы замену можно было отменить.
+type TextEdit struct {
+	NoteID     int
+	OldTitle   string
+	OldContent string
+	Title      string
+	Content    string
+}
+
+// ReplaceSnapshot — снимок заметок до массовой замены; по нему замену можно отменить
+type ReplaceSnapshot struct {
+	ID          int       `json:"id"`
+	Description string    `json:"description"` // Что заменялось, например «Проект А» → «Проект Б»
+	CreatedAt   time.Time `json:"created_at"`
+	NoteCount   int       `json:"note_count"` // Сколько заметок изменила замена
+}
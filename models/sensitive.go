@@ -0,0 +1,90 @@
+package models
+
+import (
+	"slices"
+	"strings"
+)
+
+// SensitiveTag — тег секретной заметки (пароли, ключи, личные данные): при экспорте, в журнале
+// и при передаче в другие программы ее заголовок и содержимое скрываются
+const SensitiveTag = "sensitive"
+
+// Границы секретного фрагмента в тексте обычной заметки
+const (
+	SensitiveStart = "[sensitive]"
+	SensitiveEnd   = "[/sensitive]"
+)
+
+// RedactedText заменяет скрытый текст
+const RedactedText = "[скрыто]"
+
+// HasSensitiveTag сообщает, что заметка целиком отмечена как секретная (в том числе вложенным тегом sensitive/...)
+func (n *Note) HasSensitiveTag() bool {
+	return slices.ContainsFunc(n.Tags, func(tag string) bool { return TagHasPrefix(tag, SensitiveTag) })
+}
+
+// IsSensitive сообщает, что в заметке есть что скрывать: она секретная целиком или содержит секретные фрагменты
+func (n *Note) IsSensitive() bool {
+	return n.HasSensitiveTag() || HasSensitiveRegions(n.Content)
+}
+
+// LogTitle возвращает заголовок заметки для журнала; заголовок секретной заметки скрывается
+func (n *Note) LogTitle() string {
+	if n.HasSensitiveTag() {
+		return RedactedText
+	}
+	return n.Title
+}
+
+// Redacted возвращает копию заметки без секретов. У секретной заметки скрываются заголовок, содержимое,
+// текст вложений и их подписи; у обычной — только секретные фрагменты содержимого.
+func (n Note) Redacted() Note {
+	if !n.HasSensitiveTag() {
+		n.Content = RedactText(n.Content)
+		return n
+	}
+	n.Title = RedactedText
+	n.Content = RedactedText
+	n.AttachmentText = ""
+	n.Attachments = slices.Clone(n.Attachments)
+	for i := range n.Attachments {
+		n.Attachments[i].Text = ""
+		n.Attachments[i].Description = ""
+	}
+	return n
+}
+
+// RedactNotes возвращает копии заметок без секретов
+func RedactNotes(notes []Note) []Note {
+	redacted := make([]Note, len(notes))
+	for i, note := range notes {
+		redacted[i] = note.Redacted()
+	}
+	return redacted
+}
+
+// HasSensitiveRegions сообщает, что в тексте есть секретные фрагменты
+func HasSensitiveRegions(text string) bool {
+	return strings.Contains(text, SensitiveStart)
+}
+
+// RedactText заменяет секретные фрагменты текста вместе с границами на RedactedText.
+// Незакрытый фрагмент скрывается до конца текста: лучше скрыть лишнее, чем показать секрет.
+func RedactText(text string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(text, SensitiveStart)
+		if start < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		b.WriteString(text[:start])
+		b.WriteString(RedactedText)
+		text = text[start+len(SensitiveStart):]
+		end := strings.Index(text, SensitiveEnd)
+		if end < 0 {
+			return b.String()
+		}
+		text = text[end+len(SensitiveEnd):]
+	}
+}
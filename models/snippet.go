@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// CursorPlaceholder в расшифровке сниппета отмечает, где окажется курсор после подстановки
+const CursorPlaceholder = "{cursor}"
+
+// MaxAbbreviationLength — предельная длина сокращения в символах
+const MaxAbbreviationLength = 32
+
+// Snippet — сокращение, которое при вводе в редакторе заменяется расшифровкой,
+// например «адр» → «г. Москва, ул. Ленина, д. 1»
+type Snippet struct {
+	ID           int    `json:"id"`
+	Abbreviation string `json:"abbreviation"` // Слово без пробелов; срабатывает после пробела, знака препинания или Enter
+	Expansion    string `json:"expansion"`    // Текст подстановки; {date}, {week} и {cursor} заменяются при вводе
+}
+
+// Validate проверяет, что сниппет можно сохранить
+func (s Snippet) Validate() error {
+	if s.Abbreviation == "" {
+		return fmt.Errorf("сокращение не может быть пустым")
+	}
+	if len([]rune(s.Abbreviation)) > MaxAbbreviationLength {
+		return fmt.Errorf("сокращение длиннее %d символов", MaxAbbreviationLength)
+	}
+	for _, r := range s.Abbreviation {
+		if IsSnippetDelimiter(r) {
+			return fmt.Errorf("сокращение %q не должно содержать пробелов и знаков препинания", s.Abbreviation)
+		}
+	}
+	if strings.TrimSpace(s.Expansion) == "" {
+		return fmt.Errorf("расшифровка не может быть пустой")
+	}
+	if strings.Count(s.Expansion, CursorPlaceholder) > 1 {
+		return fmt.Errorf("%s можно указать в расшифровке только один раз", CursorPlaceholder)
+	}
+	return nil
+}
+
+// Expand возвращает текст подстановки на момент t и сколько символов от его конца курсор должен отступить к {cursor}
+func (s Snippet) Expand(t time.Time) (text string, cursorBack int) {
+	text = ExpandDatePlaceholders(s.Expansion, t)
+	before, after, found := strings.Cut(text, CursorPlaceholder)
+	if !found {
+		return text, 0
+	}
+	return before + after, len([]rune(after))
+}
+
+// IsSnippetDelimiter сообщает, что символ завершает слово и после него сокращение разворачивается
+func IsSnippetDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '_' && r != '-' && r != '/')
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// WritingStats — объем написанного: количество заметок, слов и символов
+type WritingStats struct {
+	Notes int `json:"notes"`
+	Words int `json:"words"`
+	Chars int `json:"chars"`
+}
+
+// TagStats — объем заметок с тегом
+type TagStats struct {
+	Tag string `json:"tag"`
+	WritingStats
+}
+
+// MonthStats — объем заметок, созданных за месяц
+type MonthStats struct {
+	Month time.Time `json:"month"` // Первое число месяца
+	WritingStats
+}
+
+// Statistics — статистика заметок за период: итог, по тегам и по месяцам создания
+type Statistics struct {
+	Total  WritingStats `json:"total"`
+	Tags   []TagStats   `json:"tags"`   // По убыванию количества слов
+	Months []MonthStats `json:"months"` // По возрастанию месяца; месяцы без заметок пропущены
+}
+
+// NoteFilter — условия подсчета заметок; пустые поля ничего не ограничивают.
+// Диапазоны дат включают начало и не включают конец: [From, To).
+type NoteFilter struct {
+	Text           string    // Текст в заголовке или содержимом, без учета регистра
+	Tag            string    // Тег; заметки с вложенными в него тегами тоже считаются
+	ExcludeTags    []string  // Заметки с этими тегами или вложенными в них не считаются
+	Favorite       bool      // Только отмеченные звездочкой
+	MinPriority    Priority  // Приоритет не ниже заданного
+	HasReminder    bool      // Только с напоминанием
+	HasAttachments bool      // Только с вложениями
+	CreatedFrom    time.Time // Созданные начиная с этого момента
+	CreatedTo      time.Time // Созданные до этого момента
+	UpdatedFrom    time.Time // Измененные начиная с этого момента
+	UpdatedTo      time.Time // Измененные до этого момента
+}
+
+// StorageUsage — сколько места занимают заметки и вложения
+type StorageUsage struct {
+	Notes         int   `json:"notes"`
+	ContentBytes  int64 `json:"content_bytes"` // Заголовки и содержимое заметок
+	Attachments   int   `json:"attachments"`
+	FileBytes     int64 `json:"file_bytes"`     // Вложения в файлах на диске
+	DatabaseBytes int64 `json:"database_bytes"` // Вложения, хранящиеся в БД
+}
@@ -0,0 +1,57 @@
+package models
+
+import "strings"
+
+// TagSeparator разделяет уровни вложенных тегов: "работа/проекты/альфа"
+const TagSeparator = "/"
+
+// TagStyle — оформление тега: цвет "#rrggbb" и необязательный значок (эмодзи); пустые поля — не задано
+type TagStyle struct {
+	Color string `json:"color,omitempty"`
+	Icon  string `json:"icon,omitempty"`
+}
+
+// IsZero сообщает, что у тега нет ни цвета, ни значка
+func (s TagStyle) IsZero() bool {
+	return s.Color == "" && s.Icon == ""
+}
+
+// NormalizeTag убирает пробелы вокруг уровней тега и пустые уровни: " работа / проекты/ " → "работа/проекты"
+func NormalizeTag(tag string) string {
+	var parts []string
+	for _, part := range strings.Split(tag, TagSeparator) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, TagSeparator)
+}
+
+// TagHasPrefix сообщает, что тег совпадает с prefix или вложен в него (без учета регистра)
+func TagHasPrefix(tag, prefix string) bool {
+	tag, prefix = strings.ToLower(tag), strings.ToLower(prefix)
+	return tag == prefix || strings.HasPrefix(tag, prefix+TagSeparator)
+}
+
+// RenameTagPrefix заменяет в теге начало oldPrefix на newPrefix, если тег совпадает с oldPrefix
+// или вложен в него; иначе возвращает тег без изменений
+func RenameTagPrefix(tag, oldPrefix, newPrefix string) string {
+	if tag == oldPrefix {
+		return newPrefix
+	}
+	if strings.HasPrefix(tag, oldPrefix+TagSeparator) {
+		return newPrefix + tag[len(oldPrefix):]
+	}
+	return tag
+}
+
+// TagAncestors возвращает тег и все его родительские уровни: "a/b/c" → "a", "a/b", "a/b/c"
+func TagAncestors(tag string) []string {
+	var paths []string
+	for i, r := range tag {
+		if string(r) == TagSeparator {
+			paths = append(paths, tag[:i])
+		}
+	}
+	return append(paths, tag)
+}
@@ -0,0 +1,96 @@
+package nlp
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"GNote/models"
+)
+
+// minKeywordCount — сколько раз слово должно встретиться в тексте, чтобы его предложили новым тегом
+const minKeywordCount = 2
+
+// SuggestTags предлагает до limit тегов для заметки с характерными словами terms (см. TopTerms).
+// Сначала идут уже использованные теги (used — тег и число заметок с ним), последнее слово которых
+// встречается в тексте в какой-либо форме, затем частые слова текста как новые теги.
+// Теги из current не предлагаются.
+func SuggestTags(terms []Term, used map[string]int, current []string, limit int) []string {
+	stems := make(map[string]float64, len(terms))
+	stemCounts := make(map[string]int, len(terms)) // Формы одного слова считаются вместе
+	for _, t := range terms {
+		stems[Stem(t.Word)] += t.Weight
+		stemCounts[Stem(t.Word)] += t.Count
+	}
+	covered := make(map[string]bool) // Основы слов, уже представленных тегами
+	cover := func(tag string) {
+		for _, w := range tagWords(tag) {
+			covered[Stem(w)] = true
+		}
+	}
+	has := make(map[string]bool, len(current))
+	for _, tag := range current {
+		has[strings.ToLower(tag)] = true
+		cover(tag)
+	}
+
+	type scored struct {
+		tag   string
+		score float64
+	}
+	var known []scored
+	for tag, count := range used {
+		if has[strings.ToLower(tag)] {
+			continue
+		}
+		words := tagWords(tag)
+		score := 0.0
+		for _, w := range words {
+			weight, ok := stems[Stem(w)]
+			if !ok {
+				score = 0
+				break
+			}
+			score += weight
+		}
+		if score > 0 {
+			// Часто используемые теги немного выше среди одинаково подходящих
+			known = append(known, scored{tag, score + 0.1*math.Log(1+float64(count))})
+		}
+	}
+	sort.Slice(known, func(i, j int) bool {
+		if known[i].score != known[j].score {
+			return known[i].score > known[j].score
+		}
+		return known[i].tag < known[j].tag
+	})
+
+	var tags []string
+	for _, k := range known {
+		if len(tags) == limit {
+			return tags
+		}
+		tags = append(tags, k.tag)
+		cover(k.tag)
+	}
+	for _, t := range terms {
+		if len(tags) == limit {
+			break
+		}
+		stem := Stem(t.Word)
+		if stemCounts[stem] < minKeywordCount || covered[stem] {
+			continue
+		}
+		tags = append(tags, t.Word)
+		covered[stem] = true
+	}
+	return tags
+}
+
+// tagWords возвращает слова последнего уровня тега: для "работа/проекты" — "проекты"
+func tagWords(tag string) []string {
+	if i := strings.LastIndex(tag, models.TagSeparator); i >= 0 {
+		tag = tag[i+len(models.TagSeparator):]
+	}
+	return Words(tag)
+}
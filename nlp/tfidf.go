@@ -0,0 +1,67 @@
+package nlp
+
+import (
+	"math"
+	"sort"
+	"unicode"
+)
+
+// Term — слово текста, сколько раз оно встречается и его вес TF-IDF
+type Term struct {
+	Word   string
+	Count  int
+	Weight float64
+}
+
+// IDF — обратная частота слова, которое встречается в df документах из n.
+// Слово, которого еще нет ни в одном документе, считается встреченным один раз.
+func IDF(df, n int) float64 {
+	if df < 1 {
+		df = 1
+	}
+	if n < df {
+		n = df
+	}
+	return math.Log(1 + float64(n)/float64(df))
+}
+
+// TF — вес частоты слова в документе: логарифм, чтобы повторы не перевешивали все остальное
+func TF(count int) float64 {
+	return 1 + math.Log(float64(count))
+}
+
+// TopTerms возвращает до limit самых характерных слов text по TF-IDF, от самого весомого.
+// df сообщает, в скольких из n документов встречается слово.
+func TopTerms(text string, df func(word string) int, n int, limit int) []Term {
+	counts := make(map[string]int)
+	for _, w := range Tokenize(text) {
+		if isNumber(w) {
+			continue // Числа и даты не говорят о теме
+		}
+		counts[w]++
+	}
+	terms := make([]Term, 0, len(counts))
+	for w, count := range counts {
+		terms = append(terms, Term{Word: w, Count: count, Weight: TF(count) * IDF(df(w), n)})
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].Weight != terms[j].Weight {
+			return terms[i].Weight > terms[j].Weight
+		}
+		return terms[i].Word < terms[j].Word
+	})
+	if len(terms) > limit {
+		terms = terms[:limit]
+	}
+	return terms
+}
+
+// isNumber сообщает, что слово состоит только из цифр
+func isNumber(w string) bool {
+	for _, r := range w {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
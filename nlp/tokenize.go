@@ -0,0 +1,55 @@
+package nlp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MinWordLen — более короткие слова не учитываются (предлоги, союзы и т.п.)
+const MinWordLen = 3
+
+// stemLen — сколько первых букв слова оставляет Stem
+const stemLen = 5
+
+// stopWords — частые служебные слова, которые не говорят о теме текста
+var stopWords = map[string]bool{
+	"это": true, "как": true, "так": true, "что": true, "чтобы": true, "его": true, "она": true, "они": true,
+	"оно": true, "для": true, "при": true, "или": true, "если": true, "уже": true, "еще": true, "ещё": true,
+	"все": true, "всё": true, "был": true, "была": true, "были": true, "было": true, "быть": true, "есть": true,
+	"над": true, "под": true, "без": true, "через": true, "также": true, "тоже": true, "только": true, "когда": true,
+	"где": true, "там": true, "тут": true, "здесь": true, "мне": true, "меня": true, "нам": true, "нас": true,
+	"вам": true, "вас": true, "них": true, "ним": true, "этот": true, "эта": true, "эти": true, "того": true,
+	"the": true, "and": true, "for": true, "that": true, "this": true, "with": true, "are": true, "was": true,
+	"were": true, "but": true, "not": true, "you": true, "have": true, "has": true, "from": true, "they": true,
+	"his": true, "her": true, "its": true, "our": true, "your": true, "can": true, "will": true, "would": true,
+}
+
+// Words делит текст на слова из букв и цифр в нижнем регистре
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Tokenize возвращает значимые слова текста в нижнем регистре: без служебных слов и слов короче MinWordLen букв
+func Tokenize(text string) []string {
+	words := Words(text)
+	terms := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) < MinWordLen || stopWords[w] {
+			continue
+		}
+		terms = append(terms, w)
+	}
+	return terms
+}
+
+// Stem грубо отбрасывает окончание слова, оставляя первые буквы: "проекты" и "проекта" дают "проек".
+// Этого хватает, чтобы сопоставлять формы одного слова без словарей.
+func Stem(word string) string {
+	runes := []rune(strings.ToLower(word))
+	if len(runes) <= stemLen {
+		return string(runes)
+	}
+	return string(runes[:stemLen])
+}
@@ -0,0 +1,128 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"GNote/extract"
+)
+
+// scanTimeout — сколько ждать антивирус; базы clamscan загружаются при каждом запуске
+const scanTimeout = 5 * time.Minute
+
+// ErrRejected — вложение не принято по правилам; текст ошибки объясняет причину
+var ErrRejected = errors.New("вложение отклонено")
+
+// Policy — правила приема вложений: разрешенные типы, запрещенные расширения, размер и проверка антивирусом
+type Policy struct {
+	AllowedTypes      []string         // Типы MIME ("application/pdf") или группы ("image/*"); пусто — любые
+	BlockedExtensions []string         // Расширения с точкой или без (".exe", "bat")
+	MaxSize           int64            // Предельный размер в байтах; 0 — без ограничения
+	scan              *extract.Command // Команда проверки файла; nil — не проверять
+}
+
+// New создает правила; scanCommand — команда антивируса вроде "clamscan --no-summary {file}",
+// которая завершается с кодом 1, если нашла вирус. Если {file} нет, путь добавляется последним аргументом.
+func New(allowedTypes, blockedExtensions []string, maxSize int64, scanCommand string) (*Policy, error) {
+	p := &Policy{AllowedTypes: allowedTypes, MaxSize: maxSize}
+	for _, ext := range blockedExtensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		p.BlockedExtensions = append(p.BlockedExtensions, ext)
+	}
+	if strings.TrimSpace(scanCommand) != "" {
+		scan, err := extract.New(scanCommand)
+		if err != nil {
+			return nil, fmt.Errorf("неверная команда проверки вложений: %w", err)
+		}
+		p.scan = scan
+	}
+	return p, nil
+}
+
+// Empty сообщает, что правила ничего не ограничивают
+func (p *Policy) Empty() bool {
+	return p == nil || (len(p.AllowedTypes) == 0 && len(p.BlockedExtensions) == 0 && p.MaxSize == 0 && p.scan == nil)
+}
+
+// Scans сообщает, что вложения проверяются антивирусом
+func (p *Policy) Scans() bool {
+	return p != nil && p.scan != nil
+}
+
+// Check проверяет имя, тип и размер вложения (size < 0 — размер еще неизвестен)
+func (p *Policy) Check(filename, mimeType string, size int64) error {
+	if p == nil {
+		return nil
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, blocked := range p.BlockedExtensions {
+		if ext == blocked {
+			return fmt.Errorf("%w: файлы %s запрещены", ErrRejected, ext)
+		}
+	}
+	if len(p.AllowedTypes) > 0 && !p.allowedType(mimeType) {
+		return fmt.Errorf("%w: тип %s не входит в разрешенные (%s)", ErrRejected, mimeType, strings.Join(p.AllowedTypes, ", "))
+	}
+	return p.CheckSize(size)
+}
+
+// CheckSize проверяет размер вложения
+func (p *Policy) CheckSize(size int64) error {
+	if p != nil && p.MaxSize > 0 && size > p.MaxSize {
+		return fmt.Errorf("%w: размер больше разрешенного (%d МБ)", ErrRejected, p.MaxSize>>20)
+	}
+	return nil
+}
+
+// allowedType сообщает, что тип подходит под один из разрешенных; параметры вроде charset не учитываются
+func (p *Policy) allowedType(mimeType string) bool {
+	mimeType, _, _ = strings.Cut(strings.ToLower(mimeType), ";")
+	mimeType = strings.TrimSpace(mimeType)
+	major, _, _ := strings.Cut(mimeType, "/")
+	for _, allowed := range p.AllowedTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == mimeType || allowed == major+"/*" || allowed == "*/*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan проверяет файл path антивирусом. Код выхода 1 означает найденный вирус,
+// другие ненулевые коды — ошибку проверки; в обоих случаях вложение не принимается.
+func (p *Policy) Scan(ctx context.Context, path string) error {
+	if !p.Scans() {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+	cmd := p.scan.Cmd(ctx, path)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	msg := strings.TrimSpace(strings.ReplaceAll(output.String(), path, filepath.Base(path)))
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		if msg == "" {
+			return fmt.Errorf("%w: антивирус обнаружил угрозу", ErrRejected)
+		}
+		return fmt.Errorf("%w: антивирус обнаружил угрозу: %s", ErrRejected, msg)
+	}
+	if msg != "" {
+		return fmt.Errorf("%w: проверка антивирусом (%s) не удалась: %v: %s", ErrRejected, p.scan.Name(), err, msg)
+	}
+	return fmt.Errorf("%w: проверка антивирусом (%s) не удалась: %v", ErrRejected, p.scan.Name(), err)
+}
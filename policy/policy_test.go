@@ -0,0 +1,101 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	p, err := New([]string{"image/*", "application/pdf"}, []string{"exe", ".BAT", " "}, 1<<20, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		filename string
+		mimeType string
+		size     int64
+		ok       bool
+	}{
+		{"изображение по группе", "фото.jpg", "image/jpeg", 1000, true},
+		{"точный тип", "счет.pdf", "application/pdf", 1000, true},
+		{"тип с параметрами и в другом регистре", "счет.pdf", "Application/PDF; charset=binary", 1000, true},
+		{"тип не разрешен", "заметки.txt", "text/plain", 10, false},
+		{"запрещенное расширение", "setup.exe", "image/png", 10, false},
+		{"расширение без учета регистра", "run.Bat", "image/png", 10, false},
+		{"ровно предельный размер", "фото.png", "image/png", 1 << 20, true},
+		{"больше предельного размера", "фото.png", "image/png", 1<<20 + 1, false},
+		{"размер еще неизвестен", "фото.png", "image/png", -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Check(tt.filename, tt.mimeType, tt.size)
+			if tt.ok && err != nil {
+				t.Errorf("Check() = %v, ожидалось принять", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrRejected) {
+				t.Errorf("Check() = %v, ожидалось ErrRejected", err)
+			}
+		})
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy func() *Policy
+		empty  bool
+	}{
+		{"nil", func() *Policy { return nil }, true},
+		{"без правил", func() *Policy { p, _ := New(nil, nil, 0, " "); return p }, true},
+		{"любой тип", func() *Policy { p, _ := New([]string{"*/*"}, nil, 0, ""); return p }, false},
+		{"размер", func() *Policy { p, _ := New(nil, nil, 10, ""); return p }, false},
+		{"антивирус", func() *Policy { p, _ := New(nil, nil, 0, "clamscan"); return p }, false},
+	}
+	for _, tt := range tests {
+		if got := tt.policy().Empty(); got != tt.empty {
+			t.Errorf("%s: Empty() = %v, ожидалось %v", tt.name, got, tt.empty)
+		}
+	}
+	var p *Policy
+	if err := p.Check("setup.exe", "application/x-msdownload", 1<<40); err != nil {
+		t.Errorf("nil-правила отклонили вложение: %v", err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("нужен sh")
+	}
+	tests := []struct {
+		name    string
+		command string
+		ok      bool
+	}{
+		{"чисто", `sh -c 'exit 0'`, true},
+		{"угроза (код 1)", `sh -c 'echo "$0: Eicar FOUND"; exit 1'`, false},
+		{"ошибка проверки", `sh -c 'exit 2'`, false},
+		{"путь с пробелами у программы", `"/bin/sh" -c 'test -n "$0"'`, true},
+		{"нет программы", "/нет/такой/программы {file}", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(nil, nil, 0, tt.command)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.Scan(context.Background(), "/tmp/вложение.pdf")
+			if tt.ok && err != nil {
+				t.Errorf("Scan() = %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrRejected) {
+				t.Errorf("Scan() = %v, ожидалось ErrRejected", err)
+			}
+		})
+	}
+	if _, err := New(nil, nil, 0, `clamscan "{file}`); err == nil {
+		t.Error("New() с незакрытой кавычкой: ожидалась ошибка")
+	}
+}
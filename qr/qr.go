@@ -0,0 +1,441 @@
+package qr
+
+import (
+	"errors"
+	"image"
+	"image/color"
+)
+
+// ErrTooLong — данные не помещаются в QR-код поддерживаемых версий
+var ErrTooLong = errors.New("текст слишком длинный для QR-кода")
+
+// MaxBytes — сколько байт текста вмещает самый большой поддерживаемый код (версия 10, уровень L)
+const MaxBytes = 271
+
+// quietZone — светлая рамка вокруг кода в модулях, без нее телефоны плохо распознают код
+const quietZone = 4
+
+// Level — уровень коррекции ошибок
+type Level int
+
+const (
+	LevelM Level = iota // Восстанавливает около 15% кода
+	LevelL              // Около 7%, зато вмещает больше текста
+)
+
+// formatBits — биты уровня коррекции в служебной информации кода
+var formatBits = [...]int{LevelM: 0, LevelL: 1}
+
+// blockLayout — разбиение кодовых слов версии на блоки: blocks1 блоков по data1 слов данных
+// и blocks2 блоков по data1+1 слову; к каждому блоку добавляется ec слов коррекции
+type blockLayout struct {
+	ec, blocks1, data1, blocks2 int
+}
+
+// layouts — блоки версий 1–10 по уровням коррекции (ISO/IEC 18004, таблица 9)
+var layouts = [...][10]blockLayout{
+	LevelM: {
+		{10, 1, 16, 0}, {16, 1, 28, 0}, {26, 1, 44, 0}, {18, 2, 32, 0}, {24, 2, 43, 0},
+		{16, 4, 27, 0}, {18, 4, 31, 0}, {22, 2, 38, 2}, {22, 3, 36, 2}, {26, 4, 43, 1},
+	},
+	LevelL: {
+		{7, 1, 19, 0}, {10, 1, 34, 0}, {15, 1, 55, 0}, {20, 1, 80, 0}, {26, 1, 108, 0},
+		{18, 2, 68, 0}, {20, 2, 78, 0}, {24, 2, 97, 0}, {30, 2, 116, 0}, {18, 2, 68, 2},
+	},
+}
+
+// alignments — координаты центров выравнивающих узоров версий 1–10
+var alignments = [10][]int{
+	nil, {6, 18}, {6, 22}, {6, 26}, {6, 30}, {6, 34},
+	{6, 22, 38}, {6, 24, 42}, {6, 26, 46}, {6, 28, 50},
+}
+
+// remainderBits — биты, дополняющие данные до размера матрицы версий 1–10
+var remainderBits = [10]int{0, 7, 7, 7, 7, 7, 0, 0, 0, 0}
+
+// Code — готовый QR-код: квадрат из темных и светлых модулей
+type Code struct {
+	Size     int
+	modules  [][]bool // Темные модули [строка][столбец]
+	function [][]bool // Служебные модули, которые не маскируются
+}
+
+// Encode кодирует data в QR-код наименьшей подходящей версии (1–10) с уровнем коррекции M,
+// а если текст не помещается — с уровнем L
+func Encode(data []byte) (*Code, error) {
+	for _, level := range []Level{LevelM, LevelL} {
+		for version := 1; version <= len(alignments); version++ {
+			if dataBits(version, len(data)) <= 8*dataCodewords(layouts[level][version-1]) {
+				return encode(data, version, level), nil
+			}
+		}
+	}
+	return nil, ErrTooLong
+}
+
+// dataBits возвращает длину данных в битах в байтовом режиме
+func dataBits(version, n int) int {
+	count := 8 // Длина поля количества байт
+	if version >= 10 {
+		count = 16
+	}
+	return 4 + count + 8*n
+}
+
+// dataCodewords возвращает количество кодовых слов данных версии
+func dataCodewords(l blockLayout) int {
+	return l.blocks1*l.data1 + l.blocks2*(l.data1+1)
+}
+
+// encode строит код заданных версии и уровня коррекции, выбирая маску с наименьшим штрафом
+func encode(data []byte, version int, level Level) *Code {
+	l := layouts[level][version-1]
+	codewords := interleave(dataCodewordsOf(data, version, l), l)
+
+	size := 17 + 4*version
+	c := &Code{Size: size, modules: newGrid(size), function: newGrid(size)}
+	c.drawFunctionPatterns(version)
+	c.drawCodewords(codewords, remainderBits[version-1])
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormat(level, mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.applyMask(mask) // Маска снимается повторным наложением
+	}
+	c.applyMask(best)
+	c.drawFormat(level, best)
+	return c
+}
+
+// newGrid создает пустую матрицу size×size
+func newGrid(size int) [][]bool {
+	grid := make([][]bool, size)
+	for i := range grid {
+		grid[i] = make([]bool, size)
+	}
+	return grid
+}
+
+// dataCodewordsOf кодирует data в байтовом режиме и дополняет до емкости версии
+func dataCodewordsOf(data []byte, version int, l blockLayout) []byte {
+	var w bitWriter
+	w.write(0b0100, 4) // Байтовый режим
+	if version >= 10 {
+		w.write(len(data), 16)
+	} else {
+		w.write(len(data), 8)
+	}
+	for _, b := range data {
+		w.write(int(b), 8)
+	}
+	capacity := 8 * dataCodewords(l)
+	w.write(0, min(4, capacity-w.n)) // Завершающие нули
+	w.write(0, (8-w.n%8)%8)
+	for pad := 0xEC; w.n < capacity; pad ^= 0xEC ^ 0x11 {
+		w.write(pad, 8)
+	}
+	return w.bytes
+}
+
+// interleave делит данные на блоки, добавляет к каждому коды Рида — Соломона
+// и перемежает слова блоков, как требует стандарт
+func interleave(data []byte, l blockLayout) []byte {
+	var blocks, ecBlocks [][]byte
+	for i := 0; i < l.blocks1+l.blocks2; i++ {
+		n := l.data1
+		if i >= l.blocks1 {
+			n++
+		}
+		blocks = append(blocks, data[:n])
+		ecBlocks = append(ecBlocks, reedSolomon(data[:n], l.ec))
+		data = data[n:]
+	}
+	var result []byte
+	for i := 0; i <= l.data1; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < l.ec; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// setFunction рисует служебный модуль в столбце x, строке y
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+// drawFunctionPatterns рисует поисковые, выравнивающие и синхронизирующие узоры
+// и резервирует место служебной информации
+func (c *Code) drawFunctionPatterns(version int) {
+	size := c.Size
+	for i := 0; i < size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+	for _, center := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := center[0]+dx, center[1]+dy
+				if x < 0 || x >= size || y < 0 || y >= size {
+					continue
+				}
+				dist := max(abs(dx), abs(dy))
+				c.setFunction(x, y, dist != 2 && dist != 4)
+			}
+		}
+	}
+	positions := alignments[version-1]
+	last := len(positions) - 1
+	for i, y := range positions {
+		for j, x := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue // Место поисковых узоров
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+	c.drawFormat(LevelM, 0) // Резервирует место, настоящие биты рисуются после выбора маски
+	if version >= 7 {
+		c.drawVersion(version)
+	}
+}
+
+// drawFormat рисует обе копии уровня коррекции и маски, защищенные кодом БЧХ
+func (c *Code) drawFormat(level Level, mask int) {
+	data := formatBits[level]<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+
+	size := c.Size
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(i))
+	}
+	c.setFunction(8, 7, bit(6))
+	c.setFunction(8, 8, bit(7))
+	c.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		c.setFunction(size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, size-15+i, bit(i))
+	}
+	c.setFunction(8, size-8, true) // Всегда темный модуль
+}
+
+// drawVersion рисует номер версии (для версий от 7), защищенный кодом Голея
+func (c *Code) drawVersion(version int) {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	bits := version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 != 0
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, dark)
+		c.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords размещает кодовые слова змейкой парами столбцов снизу вверх и обратно, справа налево
+func (c *Code) drawCodewords(codewords []byte, remainder int) {
+	total := 8*len(codewords) + remainder // Оставшиеся биты — светлые
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Столбец синхронизирующего узора пропускается
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 { // Вверх
+					y = c.Size - 1 - vert
+				}
+				if c.function[y][x] || i >= total {
+					continue
+				}
+				if i < 8*len(codewords) {
+					c.modules[y][x] = codewords[i>>3]>>(7-i&7)&1 != 0
+				}
+				i++
+			}
+		}
+	}
+}
+
+// applyMask инвертирует модули данных по маске mask
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !c.function[y][x] {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty оценивает, насколько трудно распознать код: длинные полосы, квадраты одного цвета,
+// узоры, похожие на поисковые, и перекос темных и светлых модулей
+func (c *Code) penalty() int {
+	size := c.Size
+	result, dark := 0, 0
+	for i := 0; i < size; i++ {
+		row := make([]bool, size)
+		col := make([]bool, size)
+		for j := 0; j < size; j++ {
+			row[j], col[j] = c.modules[i][j], c.modules[j][i]
+			if row[j] {
+				dark++
+			}
+		}
+		result += linePenalty(row) + linePenalty(col)
+	}
+	for y := 0; y < size-1; y++ {
+		for x := 0; x < size-1; x++ {
+			m := c.modules[y][x]
+			if m == c.modules[y][x+1] && m == c.modules[y+1][x] && m == c.modules[y+1][x+1] {
+				result += 3
+			}
+		}
+	}
+	total := size * size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return result + max(k, 0)*10
+}
+
+// finderLike — узор 1:1:3:1:1, похожий на поисковый
+var finderLike = []bool{true, false, true, true, true, false, true}
+
+// linePenalty штрафует строку или столбец за полосы от 5 модулей и узоры, похожие на поисковые
+func linePenalty(line []bool) int {
+	result := 0
+	for i := 0; i < len(line); {
+		j := i
+		for j < len(line) && line[j] == line[i] {
+			j++
+		}
+		if j-i >= 5 {
+			result += 3 + j - i - 5
+		}
+		i = j
+	}
+	light := func(i int) bool { return i < 0 || i >= len(line) || !line[i] }
+	for i := 0; i+len(finderLike) <= len(line); i++ {
+		match := true
+		for j, dark := range finderLike {
+			if line[i+j] != dark {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		before, after := true, true
+		for j := 1; j <= 4; j++ {
+			before = before && light(i-j)
+			after = after && light(i+len(finderLike)-1+j)
+		}
+		if before || after {
+			result += 40
+		}
+	}
+	return result
+}
+
+// Dark сообщает, что модуль в столбце x, строке y темный
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Image рисует код с рамкой, по scale пикселей на модуль
+func (c *Code) Image(scale int) image.Image {
+	side := (c.Size + 2*quietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, side, side))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetGray((x+quietZone)*scale+dx, (y+quietZone)*scale+dy, color.Gray{})
+				}
+			}
+		}
+	}
+	return img
+}
+
+// abs возвращает модуль числа
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// bitWriter накапливает биты от старшего к младшему
+type bitWriter struct {
+	bytes []byte
+	n     int // Записано битов
+}
+
+// write дописывает младшие count битов value
+func (w *bitWriter) write(value, count int) {
+	for i := count - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.bytes = append(w.bytes, 0)
+		}
+		if value>>i&1 != 0 {
+			w.bytes[len(w.bytes)-1] |= 0x80 >> (w.n % 8)
+		}
+		w.n++
+	}
+}
@@ -0,0 +1,123 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// «HELLO WORLD», версия 1-M: пример из ISO/IEC 18004
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := reedSolomon(data, len(want)); !bytes.Equal(got, want) {
+		t.Errorf("reedSolomon() = %v, ожидалось %v", got, want)
+	}
+}
+
+// formatStrings — служебная информация по уровням коррекции и маскам (ISO/IEC 18004, приложение C)
+var formatStrings = map[Level][8]int{
+	LevelL: {0x77C4, 0x72F3, 0x7DAA, 0x789D, 0x662F, 0x6318, 0x6C41, 0x6976},
+	LevelM: {0x5412, 0x5125, 0x5E7C, 0x5B4B, 0x45F9, 0x40CE, 0x4F97, 0x4AA0},
+}
+
+// readFormat читает обе копии служебной информации кода
+func readFormat(c *Code) (first, second int) {
+	bit := func(dark bool, i int) int {
+		if dark {
+			return 1 << i
+		}
+		return 0
+	}
+	for i := 0; i <= 5; i++ {
+		first |= bit(c.Dark(8, i), i)
+	}
+	first |= bit(c.Dark(8, 7), 6) | bit(c.Dark(8, 8), 7) | bit(c.Dark(7, 8), 8)
+	for i := 9; i < 15; i++ {
+		first |= bit(c.Dark(14-i, 8), i)
+	}
+	for i := 0; i < 8; i++ {
+		second |= bit(c.Dark(c.Size-1-i, 8), i)
+	}
+	for i := 8; i < 15; i++ {
+		second |= bit(c.Dark(8, c.Size-15+i), i)
+	}
+	return first, second
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int // Длина данных в байтах
+		size    int
+		level   Level
+		version int // Для версий от 7 проверяется номер версии в коде
+	}{
+		{"пусто", 0, 21, LevelM, 1},
+		{"версия 1-M полностью", 14, 21, LevelM, 1},
+		{"версия 2", 15, 25, LevelM, 2},
+		{"версия 7", 110, 45, LevelM, 7},
+		{"самая большая версия M", 213, 57, LevelM, 10},
+		{"уровень L", 214, 53, LevelL, 9},
+		{"MaxBytes", MaxBytes, 57, LevelL, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Encode(bytes.Repeat([]byte("a"), tt.n))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Size != tt.size {
+				t.Fatalf("Size = %d, ожидалось %d", c.Size, tt.size)
+			}
+			// Поисковые узоры: темная рамка, светлое кольцо и темный центр 3×3 в трех углах
+			for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+				for _, d := range [][3]int{{0, 0, 1}, {1, 1, 0}, {3, 3, 1}, {6, 6, 1}, {5, 1, 0}} {
+					if c.Dark(corner[0]+d[0], corner[1]+d[1]) != (d[2] == 1) {
+						t.Errorf("поисковый узор в (%d, %d) нарушен", corner[0], corner[1])
+					}
+				}
+			}
+			first, second := readFormat(c)
+			if first != second {
+				t.Errorf("копии служебной информации различаются: %015b и %015b", first, second)
+			}
+			valid := false
+			for _, s := range formatStrings[tt.level] {
+				valid = valid || s == first
+			}
+			if !valid {
+				t.Errorf("служебная информация %015b не соответствует уровню %d", first, tt.level)
+			}
+			if tt.version >= 7 {
+				want := map[int]int{7: 0x07C94, 9: 0x09A99, 10: 0x0A4D3}[tt.version]
+				got := 0
+				for i := 0; i < 18; i++ {
+					if c.Dark(c.Size-11+i%3, i/3) {
+						got |= 1 << i
+					}
+				}
+				if got != want {
+					t.Errorf("номер версии %018b, ожидалось %018b", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(make([]byte, MaxBytes+1)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode(%d байт) = %v, ожидалась ErrTooLong", MaxBytes+1, err)
+	}
+}
+
+func TestImage(t *testing.T) {
+	c, err := Encode([]byte("gnote://note/42"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := c.Image(3)
+	if side := (c.Size + 2*quietZone) * 3; img.Bounds().Dx() != side || img.Bounds().Dy() != side {
+		t.Errorf("размер изображения %v, ожидалось %d", img.Bounds(), side)
+	}
+}
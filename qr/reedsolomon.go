@@ -0,0 +1,56 @@
+package qr
+
+// gfExp и gfLog — степени образующего элемента поля GF(256) с многочленом x^8+x^4+x^3+x^2+1 и их логарифмы
+var gfExp, gfLog = func() (exp [512]byte, log [256]byte) {
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+	for i := 255; i < len(exp); i++ { // Чтобы не брать остаток при умножении
+		exp[i] = exp[i-255]
+	}
+	return exp, log
+}()
+
+// gfMul умножает элементы поля GF(256)
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// generator возвращает коэффициенты порождающего многочлена (x-α^0)(x-α^1)…(x-α^(n-1))
+// без старшего, от старших степеней к младшим
+func generator(n int) []byte {
+	g := []byte{1}
+	for i := 0; i < n; i++ {
+		next := make([]byte, len(g)+1)
+		for j, coef := range g {
+			next[j] ^= coef
+			next[j+1] ^= gfMul(coef, gfExp[i])
+		}
+		g = next
+	}
+	return g[1:]
+}
+
+// reedSolomon возвращает n кодовых слов коррекции ошибок для data — остаток от деления на порождающий многочлен
+func reedSolomon(data []byte, n int) []byte {
+	g := generator(n)
+	rem := make([]byte, n)
+	for _, b := range data {
+		factor := b ^ rem[0]
+		copy(rem, rem[1:])
+		rem[n-1] = 0
+		for i, coef := range g {
+			rem[i] ^= gfMul(coef, factor)
+		}
+	}
+	return rem
+}
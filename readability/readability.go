@@ -0,0 +1,132 @@
+package readability
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// WordsPerMinute — средняя скорость чтения про себя
+const WordsPerMinute = 180
+
+// Stats — объем текста, время чтения и оценка читаемости
+type Stats struct {
+	Words     int
+	Sentences int
+	Syllables int
+	Russian   bool // Кириллических букв больше, чем латинских
+}
+
+// Analyze считает слова, предложения и слоги текста
+func Analyze(text string) Stats {
+	var s Stats
+	cyrillic, latin := 0, 0
+	inSentence := false
+	for _, word := range strings.Fields(text) {
+		letters := 0
+		for _, r := range word {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				letters++
+			}
+			switch {
+			case unicode.Is(unicode.Cyrillic, r):
+				cyrillic++
+			case unicode.Is(unicode.Latin, r):
+				latin++
+			}
+		}
+		if letters == 0 {
+			continue // Тире, маркеры списков и прочие отдельные знаки
+		}
+		s.Words++
+		s.Syllables += syllables(word)
+		inSentence = true
+		if strings.ContainsAny(word, ".!?…") {
+			s.Sentences++
+			inSentence = false
+		}
+	}
+	if inSentence {
+		s.Sentences++ // Последнее предложение без точки
+	}
+	s.Sentences += paragraphBreaks(text)
+	s.Sentences = min(s.Sentences, s.Words)
+	s.Russian = cyrillic > latin
+	return s
+}
+
+// paragraphBreaks считает строки, которые кончаются без знака конца предложения:
+// заголовки и пункты списков читаются как отдельные предложения
+func paragraphBreaks(text string) int {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	n := 0
+	for _, line := range lines[:len(lines)-1] {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.ContainsAny(lastRune(line), ".!?…") {
+			n++
+		}
+	}
+	return n
+}
+
+// lastRune возвращает последний символ строки
+func lastRune(s string) string {
+	runes := []rune(s)
+	return string(runes[len(runes)-1])
+}
+
+// syllables считает слоги слова по гласным; подряд идущие латинские гласные — один слог
+func syllables(word string) int {
+	n := 0
+	prevVowel := false
+	for _, r := range strings.ToLower(word) {
+		switch {
+		case strings.ContainsRune("аеёиоуыэюя", r):
+			n++
+			prevVowel = false
+		case strings.ContainsRune("aeiouy", r):
+			if !prevVowel {
+				n++
+			}
+			prevVowel = true
+		default:
+			prevVowel = false
+		}
+	}
+	return max(n, 1)
+}
+
+// ReadingTime возвращает примерное время чтения текста
+func (s Stats) ReadingTime() time.Duration {
+	return time.Duration(s.Words) * time.Minute / WordsPerMinute
+}
+
+// Score возвращает индекс удобочитаемости Флеша от 0 (очень сложно) до 100 (очень легко).
+// Для русского текста используются коэффициенты Обороневой, для остальных — исходные.
+func (s Stats) Score() float64 {
+	if s.Words == 0 || s.Sentences == 0 {
+		return 0
+	}
+	wordsPerSentence := float64(s.Words) / float64(s.Sentences)
+	syllablesPerWord := float64(s.Syllables) / float64(s.Words)
+	score := 206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord
+	if s.Russian {
+		score = 206.835 - 1.3*wordsPerSentence - 60.1*syllablesPerWord
+	}
+	return min(max(score, 0), 100)
+}
+
+// Level возвращает словесную оценку индекса удобочитаемости
+func Level(score float64) string {
+	switch {
+	case score >= 80:
+		return "очень легко"
+	case score >= 60:
+		return "легко"
+	case score >= 40:
+		return "средне"
+	case score >= 20:
+		return "сложно"
+	}
+	return "очень сложно"
+}
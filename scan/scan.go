@@ -0,0 +1,105 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"GNote/extract"
+)
+
+// windowsCommand сканирует страницу через WIA стандартным диалогом Windows и сохраняет ее в PNG
+const windowsCommand = `$d = New-Object -ComObject WIA.CommonDialog; ` +
+	`$i = $d.ShowAcquireImage(1, 0, 0, '{B96B3CAF-0728-11D3-9D7B-0000F81EF32E}'); ` +
+	`if ($i -eq $null) { exit 1 }; $i.SaveFile('` + extract.FilePlaceholder + `.png')`
+
+// Page — отсканированная страница
+type Page struct {
+	Filename string
+	MimeType string
+	Data     []byte
+}
+
+// Scanner сканирует страницы внешней программой: scanimage (SANE) в Linux и macOS, WIA в Windows
+type Scanner struct {
+	command *extract.Command
+}
+
+// New разбирает команду сканирования; результат она сохраняет в файл {file} без расширения,
+// добавляя расширение формата, например {file}.png. Пустая команда — scanimage или WIA.
+func New(command string) (*Scanner, error) {
+	if command != "" {
+		c, err := extract.ParseCommand(command)
+		if err != nil {
+			return nil, err
+		}
+		if !c.HasFile() {
+			return nil, fmt.Errorf("в команде сканирования нет %s", extract.FilePlaceholder)
+		}
+		return &Scanner{command: c}, nil
+	}
+	if runtime.GOOS == "windows" {
+		return &Scanner{command: extract.CommandOf("powershell", "-NoProfile", "-Command", windowsCommand)}, nil
+	}
+	if _, err := exec.LookPath("scanimage"); err != nil {
+		return nil, errors.New("не найдена программа сканирования: установите SANE (scanimage) или укажите команду в разделе [scanner] настроек")
+	}
+	return &Scanner{command: extract.CommandOf("scanimage", "--format=png", "--output-file="+extract.FilePlaceholder+".png")}, nil
+}
+
+// Scan сканирует одну страницу и возвращает ее; отмена ctx прерывает сканирование
+func (s *Scanner) Scan(ctx context.Context) (*Page, error) {
+	dir, err := os.MkdirTemp("", "gnote-scan-*")
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать временный каталог: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := filepath.Join(dir, "scan")
+	cmd := s.command.Cmd(ctx, base)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("команда %s завершилась с ошибкой: %w: %s", s.command.Name(), err, msg)
+		}
+		return nil, fmt.Errorf("команда %s завершилась с ошибкой: %w", s.command.Name(), err)
+	}
+	files, _ := filepath.Glob(base + "*")
+	if len(files) == 0 {
+		return nil, errors.New("сканер не сохранил страницу")
+	}
+	mimeType := MimeType(files[0])
+	if mimeType == "" {
+		return nil, fmt.Errorf("неизвестный формат скана %s", filepath.Ext(files[0]))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать скан: %w", err)
+	}
+	return &Page{Filename: "scan" + strings.ToLower(filepath.Ext(files[0])), MimeType: mimeType, Data: data}, nil
+}
+
+// mimeTypes — форматы сканов, которые принимаются как вложения
+var mimeTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".tif":  "image/tiff",
+	".tiff": "image/tiff",
+}
+
+// MimeType возвращает тип файла скана по расширению или пустую строку для других файлов
+func MimeType(name string) string {
+	return mimeTypes[strings.ToLower(filepath.Ext(name))]
+}
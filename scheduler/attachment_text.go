@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"GNote/models"
+	"GNote/storage"
+)
+
+// attachmentTextBatch — сколько вложений обрабатывается за один запуск
+const attachmentTextBatch = 10
+
+// defaultAttachmentTextTimeout ограничивает обработку одного вложения, если не задано иное
+const defaultAttachmentTextTimeout = 2 * time.Minute
+
+// ExtractFunc извлекает текст из файла вложения path
+type ExtractFunc func(ctx context.Context, path string) (string, error)
+
+// AttachmentText извлекает текст из вложений одного типа (распознает изображения, расшифровывает аудио)
+// и сохраняет его в хранилище для поиска
+type AttachmentText struct {
+	name       string
+	mimePrefix string
+	extract    ExtractFunc
+	timeout    time.Duration
+
+	mu    sync.Mutex
+	store storage.Store
+}
+
+// NewAttachmentText создает обработку вложений, MIME-тип которых начинается с mimePrefix.
+// name используется в журнале.
+func NewAttachmentText(name string, store storage.Store, mimePrefix string, extract ExtractFunc) *AttachmentText {
+	return &AttachmentText{name: name, store: store, mimePrefix: mimePrefix, extract: extract, timeout: defaultAttachmentTextTimeout}
+}
+
+// SetTimeout задает предельное время обработки одного вложения (расшифровка длинной записи идет дольше распознавания)
+func (t *AttachmentText) SetTimeout(timeout time.Duration) {
+	t.timeout = timeout
+}
+
+// Name возвращает название обработки для журнала и планировщика
+func (t *AttachmentText) Name() string {
+	return t.name
+}
+
+// SetStore переключает обработку на другое хранилище (например, при смене профиля).
+// Не ждет текущей порции: она прерывается перед следующим вложением.
+func (t *AttachmentText) SetStore(store storage.Store) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store = store
+}
+
+// currentStore возвращает хранилище, с которым сейчас работает обработка
+func (t *AttachmentText) currentStore() storage.Store {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.store
+}
+
+// Check обрабатывает очередную порцию необработанных вложений; подходит как ContextJob.
+// Блокировка не держится во время извлечения текста, которое может идти часами.
+func (t *AttachmentText) Check(ctx context.Context, now time.Time) {
+	store := t.currentStore()
+	attachments, err := store.GetAttachmentsWithoutText(t.mimePrefix, attachmentTextBatch)
+	if err != nil {
+		log.Printf("Ошибка при поиске вложений для обработки (%s): %v", t.name, err)
+		return
+	}
+	for i := range attachments {
+		if ctx.Err() != nil || t.currentStore() != store {
+			return // Выход из программы или смена профиля: остальное обработает следующий запуск
+		}
+		attachment := &attachments[i]
+		text, err := t.process(ctx, store, attachment)
+		if ctx.Err() != nil {
+			log.Printf("Обработка вложения ID %d '%s' (%s) прервана", attachment.ID, attachment.Filename, t.name)
+			return
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			// Программа не установлена: вложения остаются необработанными до ее появления
+			log.Printf("Обработка вложений (%s) недоступна: %v", t.name, err)
+			return
+		}
+		if !errors.Is(err, context.DeadlineExceeded) && storage.IsUnavailable(err) {
+			// Нет связи с БД или API: повторим при следующем запуске
+			log.Printf("Обработка вложений (%s) отложена: %v", t.name, err)
+			return
+		}
+		if err != nil {
+			// Вложение отмечается обработанным с пустым текстом, чтобы не повторять ошибку на каждом запуске
+			log.Printf("Ошибка при обработке вложения ID %d '%s' (%s): %v", attachment.ID, attachment.Filename, t.name, err)
+		}
+		if err := store.SetAttachmentText(attachment, text); err != nil {
+			log.Printf("Ошибка при сохранении текста вложения ID %d: %v", attachment.ID, err)
+			return
+		}
+		if text != "" {
+			log.Printf("Извлечен текст из вложения ID %d '%s' (%s)", attachment.ID, attachment.Filename, t.name)
+		}
+	}
+}
+
+// process извлекает текст из вложения; содержимое из БД предварительно выгружается во временный файл
+func (t *AttachmentText) process(ctx context.Context, store storage.Store, attachment *models.Attachment) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	if !attachment.InDatabase {
+		return t.extract(ctx, attachment.Filepath)
+	}
+	path, err := exportBlob(store, attachment)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(path)
+	return t.extract(ctx, path)
+}
+
+// exportBlob выгружает содержимое вложения из БД во временный файл с тем же расширением
+func exportBlob(store storage.Store, attachment *models.Attachment) (string, error) {
+	blob, err := store.OpenAttachmentBlob(attachment.ID)
+	if err != nil {
+		return "", err
+	}
+	defer blob.Close()
+
+	f, err := os.CreateTemp("", fmt.Sprintf("gnote-%d-*%s", attachment.ID, filepath.Ext(attachment.Filename)))
+	if err != nil {
+		return "", fmt.Errorf("не удалось создать временный файл: %w", err)
+	}
+	_, err = io.Copy(f, blob)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("не удалось выгрузить вложение: %w", err)
+	}
+	return f.Name(), nil
+}
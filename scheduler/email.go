@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"GNote/mailin"
+	"GNote/models"
+	"GNote/storage"
+)
+
+// EmailTag — тег заметок, созданных из писем
+const EmailTag = "email"
+
+// emailTimeout — сколько может длиться одна проверка почтового ящика
+const emailTimeout = 2 * time.Minute
+
+// EmailNotes создает заметки из новых писем в почтовом ящике: тема становится заголовком,
+// текст — содержимым, приложенные файлы — вложениями
+type EmailNotes struct {
+	mu             sync.Mutex
+	mailbox        mailin.Mailbox
+	store          storage.Store
+	attachmentsDir string
+	inDatabase     bool
+}
+
+// NewEmailNotes создает задачу приема писем; вложения сохраняются в каталог dir или в БД, если inDatabase
+func NewEmailNotes(mailbox mailin.Mailbox, store storage.Store, dir string, inDatabase bool) *EmailNotes {
+	return &EmailNotes{mailbox: mailbox, store: store, attachmentsDir: dir, inDatabase: inDatabase}
+}
+
+// SetStore переключает задачу на другое хранилище и каталог вложений (например, при смене профиля)
+func (j *EmailNotes) SetStore(store storage.Store, dir string, inDatabase bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.store = store
+	j.attachmentsDir = dir
+	j.inDatabase = inDatabase
+}
+
+// Check забирает новые письма и создает из них заметки; подходит как Job
+func (j *EmailNotes) Check(now time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), emailTimeout)
+	defer cancel()
+	created, err := j.mailbox.Receive(ctx, j.createNote)
+	if created > 0 {
+		log.Printf("Из писем %s создано заметок: %d", j.mailbox.User, created)
+	}
+	if err != nil {
+		log.Printf("Ошибка при приеме писем %s: %v", j.mailbox.User, err)
+	}
+}
+
+// createNote создает заметку из письма. Вложения, которые не удалось сохранить, только записываются
+// в лог: иначе письмо осталось бы непрочитанным и заметка создалась бы повторно.
+func (j *EmailNotes) createNote(msg *mailin.Message) error {
+	note := &models.Note{
+		Title:   emailTitle(msg, j.store.NoteLimits().MaxTitleLength),
+		Content: msg.Text,
+		Tags:    []string{EmailTag},
+	}
+	if err := j.store.CreateNote(note); err != nil {
+		return fmt.Errorf("не удалось создать заметку: %w", err)
+	}
+	for _, a := range msg.Attachments {
+		mimeType := a.MimeType
+		if mimeType == "" || mimeType == "application/octet-stream" { // Почтовые программы часто не указывают тип
+			mimeType = storage.DetectMimeType(a.Filename, a.Data[:min(len(a.Data), storage.SniffLen)])
+		}
+		if err := storage.SaveAttachmentData(j.store, j.attachmentsDir, j.inDatabase, note.ID, a.Filename, mimeType, a.Data); err != nil {
+			log.Printf("Вложение '%s' письма «%s» не сохранено: %v", a.Filename, note.Title, err)
+		}
+	}
+	return nil
+}
+
+// emailTitle возвращает заголовок заметки не длиннее maxLength: тему письма или, если ее нет, отправителя
+func emailTitle(msg *mailin.Message, maxLength int) string {
+	title := strings.TrimSpace(msg.Subject)
+	if title == "" {
+		title = "Письмо от " + msg.From
+	}
+	if runes := []rune(title); len(runes) > maxLength {
+		title = string(runes[:maxLength])
+	}
+	return title
+}
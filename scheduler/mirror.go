@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"GNote/events"
+	"GNote/mirror"
+	"GNote/storage"
+)
+
+// MarkdownMirror обновляет копию заметок в markdown: полностью раз в сутки в заданное время,
+// а если включено сохранение после изменений — еще и заметки, измененные с прошлой проверки
+type MarkdownMirror struct {
+	mu       sync.Mutex
+	store    storage.Store
+	mirror   *mirror.Mirror
+	at       time.Duration // Время ежедневной копии от начала суток
+	lastFull time.Time     // Когда копия обновлялась полностью
+	pending  map[int]bool  // Измененные заметки: true — записать, false — удалить файл
+}
+
+// NewMarkdownMirror создает задачу копирования заметок в m; полная копия делается
+// ежедневно в hour:minute местного времени, а если это время сегодня уже прошло — при первой проверке
+func NewMarkdownMirror(store storage.Store, m *mirror.Mirror, hour, minute int) *MarkdownMirror {
+	return &MarkdownMirror{
+		store:   store,
+		mirror:  m,
+		at:      time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute,
+		pending: make(map[int]bool),
+	}
+}
+
+// SetStore переключает задачу на другое хранилище и каталог (например, при смене профиля)
+func (j *MarkdownMirror) SetStore(store storage.Store, m *mirror.Mirror) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.store = store
+	j.mirror = m
+	j.lastFull = time.Time{} // Каталог другого профиля обновляется полностью при следующей проверке
+	j.pending = make(map[int]bool)
+}
+
+// NoteChanged запоминает заметку из события хранилища, чтобы обновить ее файл при следующей проверке.
+// Подходит как обработчик шины событий: сама запись идет в фоне, а не в потоке, изменившем заметку.
+func (j *MarkdownMirror) NoteChanged(e events.Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	switch e.Kind {
+	case events.NoteCreated, events.NoteUpdated:
+		j.pending[e.NoteID] = true
+	case events.NoteDeleted:
+		j.pending[e.NoteID] = false
+	}
+}
+
+// Check обновляет копию: полностью, если наступило время ежедневной копии, иначе только
+// измененные заметки; подходит как Job
+func (j *MarkdownMirror) Check(now time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	y, mo, d := now.Date()
+	scheduled := time.Date(y, mo, d, 0, 0, 0, 0, now.Location()).Add(j.at)
+	if !now.Before(scheduled) && j.lastFull.Before(scheduled) {
+		j.syncAll(now)
+		return
+	}
+	if len(j.pending) == 0 {
+		return
+	}
+	ids := make([]int, 0, len(j.pending))
+	for id, write := range j.pending {
+		delete(j.pending, id)
+		ids = append(ids, id)
+		if !write {
+			if err := j.mirror.RemoveNote(id); err != nil {
+				log.Printf("Ошибка при удалении заметки ID %d из копии: %v", id, err)
+			}
+			continue
+		}
+		note, err := j.store.GetNoteByID(id)
+		if err == nil && note.ContentTruncated { // Без связи в офлайн-кэше бывает только превью
+			err = storage.ErrContentTruncated
+		}
+		if err != nil {
+			log.Printf("Заметка ID %d не скопирована, она обновится при ежедневной копии: %v", id, err)
+			continue
+		}
+		if err := j.mirror.WriteNote(*note); err != nil {
+			log.Printf("Ошибка при копировании заметки ID %d: %v", id, err)
+		}
+	}
+	sort.Ints(ids)
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = strconv.Itoa(id)
+	}
+	j.commit("Изменены заметки: " + strings.Join(names, ", "))
+}
+
+// syncAll полностью обновляет копию по всем заметкам хранилища
+func (j *MarkdownMirror) syncAll(now time.Time) {
+	notes, err := j.store.GetAllNotes()
+	if err == nil {
+		err = storage.LoadContent(j.store, notes)
+	}
+	if err != nil {
+		log.Printf("Ошибка при получении заметок для копии в markdown: %v", err)
+		return // lastFull не сдвигаем: попробуем при следующей проверке
+	}
+	written, removed, err := j.mirror.Sync(notes)
+	if err != nil {
+		log.Printf("Ошибка при обновлении копии заметок в %s: %v", j.mirror.Dir(), err)
+		return
+	}
+	j.lastFull = now
+	j.pending = make(map[int]bool) // Полная копия уже учла все изменения
+	log.Printf("Копия заметок в %s обновлена: записано файлов %d, удалено %d", j.mirror.Dir(), written, removed)
+	j.commit("Копия заметок от " + now.Format("02.01.2006 15:04"))
+}
+
+// commit фиксирует обновление копии в git, если версионирование включено
+func (j *MarkdownMirror) commit(message string) {
+	if err := j.mirror.Commit(message); err != nil {
+		log.Printf("Ошибка при сохранении копии заметок в git: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"GNote/models"
+	"GNote/storage"
+)
+
+// RecurringNotes создает заметки по правилам повторения, когда подходит их время
+type RecurringNotes struct {
+	mu    sync.Mutex
+	store storage.Store
+}
+
+// NewRecurringNotes создает задачу создания повторяющихся заметок
+func NewRecurringNotes(store storage.Store) *RecurringNotes {
+	return &RecurringNotes{store: store}
+}
+
+// SetStore переключает задачу на другое хранилище (например, при смене профиля)
+func (r *RecurringNotes) SetStore(store storage.Store) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.store = store
+}
+
+// Check создает заметки по правилам, время которых наступило; подходит как Job.
+// Если приложение было выключено несколько периодов, создается одна заметка, а не по одной за каждый.
+func (r *RecurringNotes) Check(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rules, err := r.store.GetRecurringRules()
+	if err != nil {
+		log.Printf("Ошибка при получении правил повторяющихся заметок: %v", err)
+		return
+	}
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Enabled {
+			continue
+		}
+		after := now.Add(-time.Minute) // Правило без отметки запуска срабатывает с ближайшего момента
+		if rule.LastRunAt != nil {
+			after = *rule.LastRunAt
+		}
+		next, err := rule.NextRun(after, time.Local)
+		if err != nil {
+			log.Printf("Правило повторяющейся заметки ID %d пропущено: %v", rule.ID, err)
+			continue
+		}
+		if next.After(now) {
+			continue
+		}
+		// Сначала отмечаем запуск: так два экземпляра приложения не создадут одну заметку дважды
+		claimed, err := r.store.ClaimRecurringRun(rule, now)
+		if err != nil {
+			log.Printf("Ошибка при отметке запуска правила ID %d: %v", rule.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		r.create(*rule, next)
+	}
+}
+
+// create создает заметку по правилу для момента срабатывания at
+func (r *RecurringNotes) create(rule models.RecurringRule, at time.Time) {
+	note := &models.Note{Title: models.ExpandDatePlaceholders(rule.Title, at)}
+	if rule.TemplateNoteID != 0 {
+		template, err := r.store.GetNoteByID(rule.TemplateNoteID)
+		if err != nil {
+			log.Printf("Шаблон ID %d правила ID %d не загружен, заметка создается без него: %v", rule.TemplateNoteID, rule.ID, err)
+		} else {
+			note.Content = models.ExpandDatePlaceholders(template.Content, at)
+			note.Tags = append(note.Tags, template.Tags...)
+			note.Priority = template.Priority
+		}
+	}
+	note.Tags = mergeTags(note.Tags, rule.Tags)
+
+	if err := r.store.CreateNote(note); err != nil {
+		log.Printf("Ошибка при создании заметки по правилу ID %d: %v", rule.ID, err)
+		return
+	}
+	log.Printf("По правилу ID %d создана заметка '%s' (ID: %d)", rule.ID, note.LogTitle(), note.ID)
+}
+
+// mergeTags добавляет к тегам extra те, которых еще нет
+func mergeTags(tags, extra []string) []string {
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		seen[tag] = true
+	}
+	for _, tag := range extra {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"GNote/models"
+	"GNote/storage"
+)
+
+// ReminderChecker находит наступившие напоминания и передает их в функцию уведомления
+type ReminderChecker struct {
+	store  storage.Store
+	notify func(note models.Note)
+
+	mu        sync.Mutex
+	lastCheck time.Time // Напоминания до этого момента уже обработаны
+}
+
+// NewReminderChecker создает проверку напоминаний.
+// Напоминания, наступившие раньше момента создания, не отправляются повторно.
+func NewReminderChecker(store storage.Store, notify func(note models.Note)) *ReminderChecker {
+	return &ReminderChecker{
+		store:     store,
+		notify:    notify,
+		lastCheck: time.Now(),
+	}
+}
+
+// SetStore переключает проверку на другое хранилище (например, при смене профиля)
+func (c *ReminderChecker) SetStore(store storage.Store) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store = store
+}
+
+// Check отправляет уведомления для напоминаний в интервале (lastCheck, now]; подходит как Job
+func (c *ReminderChecker) Check(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	notes, err := c.store.GetDueReminders(c.lastCheck, now)
+	if err != nil {
+		log.Printf("Ошибка при проверке напоминаний: %v", err)
+		return // lastCheck не сдвигаем, чтобы не потерять напоминания
+	}
+	for _, note := range notes {
+		log.Printf("Сработало напоминание для заметки '%s' (ID: %d)", note.LogTitle(), note.ID)
+		c.notify(note)
+	}
+	c.lastCheck = now
+}
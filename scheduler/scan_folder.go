@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"GNote/scan"
+	"GNote/storage"
+)
+
+// ImportedDir — подкаталог папки сканера, куда переносятся уже прикрепленные файлы
+const ImportedDir = "imported"
+
+// ScanFolder прикрепляет новые файлы из папки сканера (PDF и изображения) к открытой заметке.
+// Файл берется, когда его размер перестал меняться между проверками: сканер мог еще не дописать его.
+type ScanFolder struct {
+	mu             sync.Mutex
+	dir            string
+	target         func() int // ID открытой заметки; 0 — заметка не выбрана, файлы ждут
+	store          storage.Store
+	attachmentsDir string
+	inDatabase     bool
+	sizes          map[string]int64 // Размер файлов при прошлой проверке
+	waiting        bool             // О том, что файлы ждут заметку, уже сообщено
+}
+
+// NewScanFolder создает задачу для папки сканера dir; вложения сохраняются в каталог attachmentsDir
+// или в БД, если inDatabase
+func NewScanFolder(dir string, target func() int, store storage.Store, attachmentsDir string, inDatabase bool) *ScanFolder {
+	return &ScanFolder{
+		dir:            dir,
+		target:         target,
+		store:          store,
+		attachmentsDir: attachmentsDir,
+		inDatabase:     inDatabase,
+		sizes:          make(map[string]int64),
+	}
+}
+
+// SetStore переключает задачу на другое хранилище и каталог вложений (например, при смене профиля)
+func (j *ScanFolder) SetStore(store storage.Store, attachmentsDir string, inDatabase bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.store = store
+	j.attachmentsDir = attachmentsDir
+	j.inDatabase = inDatabase
+}
+
+// Check прикрепляет к открытой заметке файлы, которые сканер закончил записывать; подходит как Job
+func (j *ScanFolder) Check(now time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := os.ReadDir(j.dir)
+	if err != nil {
+		log.Printf("Ошибка при чтении папки сканера %s: %v", j.dir, err)
+		return
+	}
+	sizes := make(map[string]int64, len(entries))
+	var ready []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || scan.MimeType(entry.Name()) == "" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		sizes[entry.Name()] = info.Size()
+		if prev, ok := j.sizes[entry.Name()]; ok && prev == info.Size() && info.Size() > 0 {
+			ready = append(ready, entry.Name())
+		}
+	}
+	j.sizes = sizes
+	if len(ready) == 0 {
+		j.waiting = false
+		return
+	}
+
+	noteID := j.target()
+	if noteID == 0 {
+		if !j.waiting {
+			log.Printf("Файлов в папке сканера: %d — откройте заметку, к которой их прикрепить", len(ready))
+			j.waiting = true
+		}
+		return
+	}
+	j.waiting = false
+	for _, name := range ready {
+		if err := j.attach(noteID, name); err != nil {
+			log.Printf("Ошибка при прикреплении скана '%s': %v", name, err)
+			continue
+		}
+		delete(j.sizes, name)
+		log.Printf("Скан '%s' прикреплен к заметке ID %d", name, noteID)
+	}
+}
+
+// attach прикрепляет файл name к заметке и переносит его в подкаталог ImportedDir
+func (j *ScanFolder) attach(noteID int, name string) error {
+	path := filepath.Join(j.dir, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := storage.SaveAttachmentData(j.store, j.attachmentsDir, j.inDatabase, noteID, name, scan.MimeType(name), data); err != nil {
+		return err
+	}
+	imported := filepath.Join(j.dir, ImportedDir)
+	if err := os.MkdirAll(imported, 0755); err == nil {
+		err = os.Rename(path, filepath.Join(imported, name))
+		if err == nil {
+			return nil
+		}
+	}
+	// Не перенесенный файл прикрепился бы снова при следующей проверке
+	return os.Remove(path)
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job — фоновая задача, вызываемая планировщиком с текущим временем
+type Job func(now time.Time)
+
+// ContextJob — долгая фоновая задача: ctx отменяется при остановке планировщика
+type ContextJob func(ctx context.Context, now time.Time)
+
+// job описывает зарегистрированную периодическую задачу
+type job struct {
+	name     string
+	interval time.Duration
+	fn       ContextJob
+}
+
+// Scheduler периодически запускает фоновые задачи (напоминания и т.п.)
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []job
+	stop    chan struct{}
+	ctx     context.Context // Отменяется в Stop, чтобы долгие задачи не задерживали выход
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+}
+
+// New создает новый планировщик без задач
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every регистрирует задачу, выполняемую с указанным интервалом.
+// Задачи, добавленные после Start, запускаются сразу.
+func (s *Scheduler) Every(name string, interval time.Duration, fn Job) {
+	s.EveryContext(name, interval, func(_ context.Context, now time.Time) { fn(now) })
+}
+
+// EveryContext регистрирует долгую задачу, как Every; Stop отменяет ее контекст и не ждет, пока она
+// доделает всю работу
+func (s *Scheduler) EveryContext(name string, interval time.Duration, fn ContextJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := job{name: name, interval: interval, fn: fn}
+	s.jobs = append(s.jobs, j)
+	if s.running {
+		s.run(j)
+	}
+}
+
+// Start запускает все зарегистрированные задачи в фоне
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stop = make(chan struct{})
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	for _, j := range s.jobs {
+		s.run(j)
+	}
+	log.Printf("Планировщик запущен, задач: %d", len(s.jobs))
+}
+
+// Stop останавливает планировщик и дожидается завершения выполняющихся задач
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stop)
+	s.cancel()
+	s.mu.Unlock()
+	s.wg.Wait()
+	log.Println("Планировщик остановлен")
+}
+
+// run запускает горутину для одной задачи; вызывается под блокировкой
+func (s *Scheduler) run(j job) {
+	stop, ctx := s.stop, s.ctx
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		j.fn(ctx, time.Now()) // Первый запуск сразу, не дожидаясь интервала
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				j.fn(ctx, now)
+			}
+		}
+	}()
+}
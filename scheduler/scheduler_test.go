@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStopCancelsContextJob(t *testing.T) {
+	s := New()
+	started := make(chan struct{})
+	s.EveryContext("долгая задача", time.Hour, func(ctx context.Context, _ time.Time) {
+		close(started)
+		<-ctx.Done()
+	})
+	s.Start()
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop не отменил контекст выполняющейся задачи")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"GNote/api"
+	"GNote/config"
+	"GNote/events"
+	"GNote/feed"
+	"GNote/storage"
+)
+
+// serve запускает режим сервера для профиля, выбранного при запуске: по адресу /changes
+// окна GNote подключаются к ленте изменений и узнают об изменениях друг друга,
+// /feed.xml отдает RSS недавно измененных заметок, а /metrics — время запросов к БД для Prometheus.
+// На адресе grpc_listen работает API gRPC (api/gnote.proto) с тем же ключом доступа.
+func (l *launcher) serve() error {
+	cfg, err := l.profileConfig(l.profile)
+	if err != nil {
+		return err
+	}
+	store, err := storage.NewPostgresStore(cfg.StorageConfig())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	metrics := l.storeMetrics()
+
+	if cfg.Server.Token == "" {
+		return errors.New("не задан ключ доступа к серверу: укажите token в разделе [server] настроек или GNOTE_SERVER_TOKEN")
+	}
+	hub := feed.NewHub(cfg.Server.Token, cfg.Server.AllowedOrigins)
+	mux := http.NewServeMux()
+	mux.Handle("/changes", hub.Handler())
+	mux.Handle("/feed.xml", &feed.RSS{
+		Store:  storage.NewMetricsStore(store, metrics),
+		Token:  cfg.Server.Token,
+		Public: cfg.Server.PublicNotebooks,
+		Items:  cfg.Server.FeedItems,
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		if !feed.Authorized(r, cfg.Server.Token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gnote"`)
+			http.Error(w, "нужен ключ доступа", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := metrics.WritePrometheus(w); err != nil {
+			log.Printf("Ошибка при отправке метрик: %v", err)
+		}
+	})
+
+	if cfg.Server.GRPCListen != "" {
+		if err := l.serveAPI(cfg, store, metrics, hub); err != nil {
+			return err
+		}
+	}
+
+	server := &http.Server{Addr: cfg.Server.Listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	log.Printf("GNote запущен в режиме сервера: ws://%[1]s/changes, http://%[1]s/feed.xml, http://%[1]s/metrics", cfg.Server.Listen)
+	return server.ListenAndServe()
+}
+
+// serveAPI запускает API gRPC на адресе grpc_listen. Изменения через API проходят те же проверки
+// вложений, что и в окнах, и публикуются в ленту: их видят окна GNote и подписчики WatchChanges.
+func (l *launcher) serveAPI(cfg config.Config, store storage.Store, metrics *storage.Metrics, hub *feed.Hub) error {
+	// Метаданные gRPC передаются как заголовки HTTP/2: ключ с другими символами клиент не отправит
+	if strings.ContainsFunc(cfg.Server.Token, func(r rune) bool { return r < ' ' || r > '~' }) {
+		return errors.New("ключ доступа к серверу должен состоять из латинских букв, цифр и знаков ASCII, иначе его не передать в API gRPC")
+	}
+	var inner storage.Store = storage.NewMetricsStore(store, metrics)
+	p, err := cfg.AttachmentPolicy()
+	if err != nil {
+		return err
+	}
+	if !p.Empty() {
+		inner = storage.NewPolicyStore(inner, p)
+	}
+	bus := events.NewBus()
+	bus.Subscribe(hub.Publish)
+	notes := api.NewServer(storage.NewPublishingStore(inner, bus), hub,
+		cfg.AttachmentsPath(l.appDataDir()), cfg.Storage.Attachments == config.AttachmentsDatabase)
+
+	listener, err := net.Listen("tcp", cfg.Server.GRPCListen)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть адрес API %s: %w", cfg.Server.GRPCListen, err)
+	}
+	server := api.NewGRPCServer(notes, cfg.Server.Token)
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Printf("API gRPC остановлен: %v", err)
+		}
+	}()
+	log.Printf("API gRPC GNote: %s", cfg.Server.GRPCListen)
+	return nil
+}
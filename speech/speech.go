@@ -0,0 +1,123 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"GNote/extract"
+)
+
+// RatePlaceholder в команде заменяется скоростью речи в словах в минуту
+const RatePlaceholder = "{rate}"
+
+// DefaultRate — обычная скорость речи, слов в минуту
+const DefaultRate = 175
+
+// windowsCommand читает текст из stdin встроенным синтезатором Windows (SAPI); скорость SAPI — от -10 до 10
+const windowsCommand = `Add-Type -AssemblyName System.Speech; ` +
+	`$s = New-Object System.Speech.Synthesis.SpeechSynthesizer; ` +
+	`$s.Rate = [Math]::Max(-10, [Math]::Min(10, [int]((` + RatePlaceholder + ` - 175) / 25))); ` +
+	`$s.Speak([Console]::In.ReadToEnd())`
+
+// Speaker читает текст вслух внешней программой синтеза речи: espeak-ng в Linux, say в macOS, SAPI в Windows
+type Speaker struct {
+	args   []string
+	espeak bool // Голос выбирается по языку текста
+}
+
+// New разбирает команду синтеза речи (аргументы с пробелами — в кавычках, как в extract.ParseCommand);
+// текст подается ей на stdin. Пустая команда — синтезатор системы.
+func New(command string) (*Speaker, error) {
+	if command != "" {
+		c, err := extract.ParseCommand(command)
+		if err != nil {
+			return nil, fmt.Errorf("неверная команда синтеза речи: %w", err)
+		}
+		return &Speaker{args: c.Args(), espeak: strings.HasPrefix(filepath.Base(c.Name()), "espeak")}, nil
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return &Speaker{args: []string{"say", "-r", RatePlaceholder}}, nil
+	case "windows":
+		return &Speaker{args: []string{"powershell", "-NoProfile", "-Command", windowsCommand}}, nil
+	}
+	for _, name := range []string{"espeak-ng", "espeak"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return &Speaker{args: []string{name, "-s", RatePlaceholder, "--stdin"}, espeak: true}, nil
+		}
+	}
+	return nil, errors.New("не найдена программа синтеза речи: установите espeak-ng или укажите команду в разделе [speech] настроек")
+}
+
+// Speak читает text вслух со скоростью speed (1 — обычная) и ждет окончания; отмена ctx прерывает чтение
+func (s *Speaker) Speak(ctx context.Context, text string, speed float64) error {
+	rate := strconv.Itoa(int(DefaultRate * speed))
+	args := make([]string, 0, len(s.args)+2)
+	for _, arg := range s.args {
+		args = append(args, strings.ReplaceAll(arg, RatePlaceholder, rate))
+	}
+	if s.espeak && isCyrillic(text) {
+		args = append(args[:1], append([]string{"-v", "ru"}, args[1:]...)...) // Голос по умолчанию — английский
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("команда %s завершилась с ошибкой: %w: %s", args[0], err, msg)
+		}
+		return fmt.Errorf("команда %s завершилась с ошибкой: %w", args[0], err)
+	}
+	return nil
+}
+
+// isCyrillic сообщает, что кириллических букв в тексте больше, чем латинских
+func isCyrillic(text string) bool {
+	cyrillic, latin := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	return cyrillic > latin
+}
+
+// Sentences делит текст на предложения и абзацы: по ним чтение ставится на паузу и продолжается
+func Sentences(text string) []string {
+	var sentences []string
+	var b strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(b.String()); s != "" {
+			sentences = append(sentences, s)
+		}
+		b.Reset()
+	}
+	runes := []rune(text)
+	for i, r := range runes {
+		if r == '\n' {
+			flush()
+			continue
+		}
+		b.WriteRune(r)
+		if strings.ContainsRune(".!?…", r) && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])) {
+			flush()
+		}
+	}
+	flush()
+	return sentences
+}
@@ -0,0 +1,35 @@
+package speech
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+		espeak  bool
+		wantErr bool
+	}{
+		{"программа с аргументами", "say -r {rate}", []string{"say", "-r", "{rate}"}, false, false},
+		{"путь с пробелами в кавычках", `"/opt/speech tools/espeak-ng" -s {rate} --stdin`, []string{"/opt/speech tools/espeak-ng", "-s", "{rate}", "--stdin"}, true, false},
+		{"только пробелы", "   ", nil, false, true},
+		{"незакрытая кавычка", `"espeak-ng --stdin`, nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New(%q) ошибка = %v, ожидалась ошибка: %v", tt.command, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !slices.Equal(s.args, tt.args) || s.espeak != tt.espeak {
+				t.Errorf("New(%q) = %q, espeak %v, ожидалось %q, espeak %v", tt.command, s.args, s.espeak, tt.args, tt.espeak)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"os/user"
+	"sync"
+
+	"github.com/lib/pq"
+
+	"GNote/models"
+)
+
+// activityActor возвращает, от чьего имени вносятся изменения: пользователь@компьютер
+var activityActor = sync.OnceValue(func() string {
+	name := "?"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+})
+
+// logActivity записывает действие с заметкой в журнал изменений в той же транзакции, что и само изменение
+func (s *PostgresStore) logActivity(tx *sql.Tx, action models.ActivityAction, noteID int, title, details string) error {
+	_, err := tx.Exec(`INSERT INTO activity_log (action, note_id, note_title, details, actor) VALUES ($1, $2, $3, $4, $5)`,
+		string(action), noteID, title, details, activityActor())
+	if err != nil {
+		return fmt.Errorf("ошибка при записи в журнал изменений: %w", err)
+	}
+	return nil
+}
+
+// logNoteActivity записывает в журнал действие с заметкой, беря ее заголовок из БД;
+// удаление записывается до DELETE, пока заголовок еще можно прочитать
+func (s *PostgresStore) logNoteActivity(tx *sql.Tx, action models.ActivityAction, noteID int, details string) error {
+	_, err := tx.Exec(`INSERT INTO activity_log (action, note_id, note_title, details, actor)
+		SELECT $1, id, title, $2, $3 FROM notes WHERE id = $4`,
+		string(action), details, activityActor(), noteID)
+	if err != nil {
+		return fmt.Errorf("ошибка при записи в журнал изменений: %w", err)
+	}
+	return nil
+}
+
+// noteBeforeUpdate читает поля заметки, которые сравниваются при записи изменения в журнал,
+// и блокирует строку до конца транзакции
+func (s *PostgresStore) noteBeforeUpdate(tx *sql.Tx, id int) (*models.Note, error) {
+	var note models.Note
+	var reminderAt, dueAt sql.NullTime
+	var locName string
+	var lat, lon sql.NullFloat64
+	var tags pq.StringArray
+	err := tx.QueryRow(`SELECT title, content, reminder_at, due_at, source_url, location_name, latitude, longitude, is_favorite, priority,
+			ARRAY(SELECT t.name FROM tags t JOIN note_tags nt ON t.id = nt.tag_id WHERE nt.note_id = n.id)
+		FROM notes n WHERE id = $1 FOR UPDATE`, id).
+		Scan(&note.Title, &note.Content, &reminderAt, &dueAt, &note.SourceURL, &locName, &lat, &lon, &note.Favorite, &note.Priority, &tags)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("заметка с ID %d не найдена для обновления", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении заметки перед обновлением: %w", err)
+	}
+	if reminderAt.Valid {
+		note.ReminderAt = &reminderAt.Time
+	}
+	if dueAt.Valid {
+		note.DueAt = &dueAt.Time
+	}
+	note.Location = locationFromSQL(locName, lat, lon)
+	note.Tags = []string(tags)
+	return &note, nil
+}
+
+// LogActivity записывает в журнал изменений действие, которое не меняет данные, например экспорт
+func (s *PostgresStore) LogActivity(activity *models.Activity) error {
+	err := s.writeRow(`INSERT INTO activity_log (action, note_id, note_title, details, actor) VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, at, actor`,
+		string(activity.Action), activity.NoteID, activity.NoteTitle, activity.Details, activityActor()).
+		Scan(&activity.ID, &activity.At, &activity.Actor)
+	if err != nil {
+		return fmt.Errorf("ошибка при записи в журнал изменений: %w", err)
+	}
+	return nil
+}
+
+// GetActivity возвращает последние limit записей журнала изменений, от новых к старым
+func (s *PostgresStore) GetActivity(limit int) ([]models.Activity, error) {
+	rows, err := s.readQuery(`SELECT id, at, action, note_id, note_title, details, actor
+		FROM activity_log ORDER BY at DESC, id DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении журнала изменений: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.Activity
+	for rows.Next() {
+		var a models.Activity
+		var action string
+		if err := rows.Scan(&a.ID, &a.At, &action, &a.NoteID, &a.NoteTitle, &a.Details, &a.Actor); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании записи журнала: %w", err)
+		}
+		a.Action = models.ActivityAction(action)
+		entries = append(entries, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по журналу изменений: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"GNote/models"
+)
+
+// moveAttachmentsQuery переносит вложения заметки $2 в конец списка заметки $1, сохраняя их порядок
+const moveAttachmentsQuery = `UPDATE attachments SET note_id = $1, position = position + ` + nextAttachmentPosition + ` WHERE note_id = $2`
+
+// MoveAttachments переносит все вложения заметки fromNoteID в конец списка вложений заметки toNoteID.
+// Файлы на диске и содержимое в БД остаются на месте, меняется только привязка к заметке.
+func (s *PostgresStore) MoveAttachments(fromNoteID, toNoteID int) error {
+	if fromNoteID == toNoteID {
+		return nil
+	}
+	if _, err := s.exec(moveAttachmentsQuery, toNoteID, fromNoteID); err != nil {
+		return fmt.Errorf("ошибка при переносе вложений: %w", err)
+	}
+	return nil
+}
+
+// CopyAttachment создает в конце списка вложений заметки newNoteID копию вложения id вместе с содержимым:
+// файл копируется в тот же каталог, содержимое в БД — в новый large object. Подпись, адрес источника
+// и извлеченный текст тоже копируются, чтобы не распознавать вложение заново.
+func (s *PostgresStore) CopyAttachment(id, newNoteID int) (*models.Attachment, error) {
+	var filename, path string
+	var oid sql.NullInt64
+	err := s.db.QueryRow(`SELECT filename, COALESCE(filepath, ''), data_oid FROM attachments WHERE id = $1`, id).Scan(&filename, &path, &oid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("вложение с ID %d не найдено", id)
+		}
+		return nil, fmt.Errorf("ошибка при получении вложения: %w", err)
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	var newPath sql.NullString
+	var newOID sql.NullInt64
+	if oid.Valid {
+		copied, _, err := writeBlob(tx, &blobReader{db: s.db, oid: oid.Int64})
+		if err != nil {
+			return nil, err
+		}
+		newOID = sql.NullInt64{Int64: copied, Valid: true}
+	} else {
+		copied, err := copyAttachmentFile(path, newNoteID, filename)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось скопировать файл вложения '%s': %w", filename, err)
+		}
+		newPath = sql.NullString{String: copied, Valid: true}
+	}
+
+	row := tx.QueryRow(`
+		INSERT INTO attachments (note_id, filename, filepath, mimetype, size_bytes, data_oid, attachment_text, description, source_url, position)
+		SELECT $1, filename, $2, mimetype, size_bytes, $3, attachment_text, description, source_url, `+nextAttachmentPosition+`
+		FROM attachments WHERE id = $4
+		RETURNING `+attachmentColumns, newNoteID, newPath, newOID, id)
+	attachment, err := scanAttachment(row)
+	if err == nil {
+		err = s.commit(tx)
+	}
+	if err != nil {
+		if newPath.Valid {
+			os.Remove(newPath.String)
+		}
+		return nil, fmt.Errorf("ошибка при копировании вложения: %w", err)
+	}
+	return &attachment, nil
+}
+
+// copyAttachmentFile копирует файл вложения в тот же каталог под именем, как у вложений заметки noteID,
+// и возвращает путь к копии; существующие файлы не перезаписываются
+func copyAttachmentFile(src string, noteID int, filename string) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	base := fmt.Sprintf("%d_%s_%s", noteID, time.Now().Format("20060102150405"), filepath.Base(filename))
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+	for i := 1; ; i++ {
+		dest := filepath.Join(filepath.Dir(src), base)
+		out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			base = fmt.Sprintf("%s_%d%s", name, i, ext)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(out, in)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(dest)
+			return "", err
+		}
+		return dest, nil
+	}
+}
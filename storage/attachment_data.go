@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"GNote/models"
+)
+
+// SaveAttachmentData сохраняет данные в директорию вложений dir (или в БД, если inDatabase) и создает запись о вложении
+func SaveAttachmentData(store Store, dir string, inDatabase bool, noteID int, filename, mimeType string, data []byte) error {
+	attachment := &models.Attachment{NoteID: noteID, Filename: filename, MimeType: mimeType}
+	return SaveAttachmentStream(store, dir, inDatabase, attachment, bytes.NewReader(data))
+}
+
+// SaveAttachmentStream сохраняет содержимое из r в директорию вложений dir (или в БД, если inDatabase)
+// и создает запись о вложении. В attachment должны быть заполнены заметка, имя и тип;
+// путь к файлу и размер заполняются здесь.
+func SaveAttachmentStream(store Store, dir string, inDatabase bool, attachment *models.Attachment, r io.Reader) error {
+	if inDatabase {
+		if err := store.CreateAttachmentBlob(attachment, r); err != nil {
+			return fmt.Errorf("не удалось сохранить вложение в БД: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("не удалось создать каталог вложений: %w", err)
+	}
+	uniqueFilename := fmt.Sprintf("%d_%s_%s", attachment.NoteID, time.Now().Format("20060102150405"), filepath.Base(attachment.Filename))
+	destPath := filepath.Join(dir, uniqueFilename)
+	size, err := writeFile(destPath, r)
+	if err != nil {
+		return fmt.Errorf("не удалось записать файл вложения: %w", err)
+	}
+
+	attachment.Filepath = destPath
+	attachment.SizeBytes = size
+	if err := store.CreateAttachment(attachment); err != nil {
+		if removeErr := os.Remove(destPath); removeErr != nil {
+			log.Printf("Ошибка: не удалось удалить файл '%s' после ошибки БД: %v", destPath, removeErr)
+		}
+		return fmt.Errorf("не удалось сохранить информацию о вложении в БД: %w", err)
+	}
+	return nil
+}
+
+// writeFile записывает r в новый файл path и возвращает размер; при ошибке недописанный файл удаляется
+func writeFile(path string, r io.Reader) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	size, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+	return size, nil
+}
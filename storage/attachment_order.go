@@ -0,0 +1,26 @@
+package storage
+
+import "fmt"
+
+// nextAttachmentPosition — подзапрос с местом нового вложения в конце списка заметки ($1 — ID заметки)
+const nextAttachmentPosition = `(SELECT COALESCE(MAX(position) + 1, 0) FROM attachments WHERE note_id = $1)`
+
+// SetAttachmentOrder расставляет вложения заметки в порядке attachmentIDs одной транзакцией.
+// Вложения других заметок не затрагиваются, даже если их ID переданы по ошибке.
+func (s *PostgresStore) SetAttachmentOrder(noteID int, attachmentIDs []int) error {
+	tx, err := s.begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	for position, id := range attachmentIDs {
+		if _, err := tx.Exec(`UPDATE attachments SET position = $1 WHERE id = $2 AND note_id = $3`, position, id, noteID); err != nil {
+			return fmt.Errorf("ошибка при изменении порядка вложений: %w", err)
+		}
+	}
+	if err := s.commit(tx); err != nil {
+		return fmt.Errorf("ошибка при изменении порядка вложений: %w", err)
+	}
+	return nil
+}
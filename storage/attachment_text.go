@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"GNote/models"
+)
+
+// attachmentText собирает описания и извлеченный текст вложений заметки для поиска
+func attachmentText(attachments []models.Attachment) string {
+	var parts []string
+	for _, a := range attachments {
+		if a.Description != "" {
+			parts = append(parts, a.Description)
+		}
+		if a.Text != "" {
+			parts = append(parts, a.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// GetAttachmentsWithoutText возвращает до limit еще не обработанных вложений,
+// MIME-тип которых начинается с mimePrefix (например, "image/")
+func (s *PostgresStore) GetAttachmentsWithoutText(mimePrefix string, limit int) ([]models.Attachment, error) {
+	query := `SELECT id, note_id, filename, COALESCE(filepath, ''), mimetype, size_bytes, uploaded_at, data_oid IS NOT NULL
+		FROM attachments WHERE attachment_text IS NULL AND mimetype LIKE $1 || '%' ORDER BY id LIMIT $2`
+	rows, err := s.db.Query(query, mimePrefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении необработанных вложений: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []models.Attachment
+	for rows.Next() {
+		var attach models.Attachment
+		if err := rows.Scan(&attach.ID, &attach.NoteID, &attach.Filename, &attach.Filepath, &attach.MimeType, &attach.SizeBytes, &attach.UploadedAt, &attach.InDatabase); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании вложения: %w", err)
+		}
+		attachments = append(attachments, attach)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по строкам вложений: %w", err)
+	}
+	return attachments, nil
+}
+
+// SetAttachmentText сохраняет текст, извлеченный из вложения. Пустой текст отмечает вложение обработанным.
+func (s *PostgresStore) SetAttachmentText(attachment *models.Attachment, text string) error {
+	_, err := s.exec(`UPDATE attachments SET attachment_text = $1 WHERE id = $2`, text, attachment.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении текста вложения: %w", err)
+	}
+	attachment.Text = text
+	return nil
+}
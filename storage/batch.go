@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"GNote/models"
+)
+
+// attachmentColumns — столбцы вложения в порядке scanAttachment
+const attachmentColumns = `id, note_id, filename, COALESCE(filepath, ''), mimetype, size_bytes, uploaded_at,
+	data_oid IS NOT NULL, COALESCE(attachment_text, ''), description, position, source_url`
+
+// rowScanner — *sql.Rows или *sql.Row
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanAttachment читает вложение из строки, выбранной с attachmentColumns
+func scanAttachment(row rowScanner) (models.Attachment, error) {
+	var a models.Attachment
+	if err := row.Scan(&a.ID, &a.NoteID, &a.Filename, &a.Filepath, &a.MimeType, &a.SizeBytes, &a.UploadedAt,
+		&a.InDatabase, &a.Text, &a.Description, &a.Position, &a.SourceURL); err != nil {
+		return a, fmt.Errorf("ошибка при сканировании вложения: %w", err)
+	}
+	return a, nil
+}
+
+// GetNotesWithAttachments загружает заметки ids с тегами и вложениями двумя запросами независимо от
+// числа заметок, например для экспорта. Заметки возвращаются в порядке ids; удаленные пропускаются.
+func (s *PostgresStore) GetNotesWithAttachments(ids []int) ([]models.Note, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := s.readQuery(`
+		SELECT n.id, n.title, n.content, n.created_at, n.updated_at, n.reminder_at, n.source_url,
+			n.location_name, n.latitude, n.longitude, n.is_favorite, n.priority, n.due_at, n.uid,
+			COALESCE((SELECT ARRAY_AGG(t.name ORDER BY t.name) FROM note_tags nt JOIN tags t ON t.id = nt.tag_id
+				WHERE nt.note_id = n.id), '{}')
+		FROM notes n WHERE n.id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении заметок: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[int]*models.Note, len(ids))
+	for rows.Next() {
+		var note models.Note
+		var tags pq.StringArray
+		var reminderAt, dueAt sql.NullTime
+		var locName string
+		var lat, lon sql.NullFloat64
+		if err := rows.Scan(&note.ID, &note.Title, &note.Content, &note.CreatedAt, &note.UpdatedAt, &reminderAt, &note.SourceURL,
+			&locName, &lat, &lon, &note.Favorite, &note.Priority, &dueAt, &note.UID, &tags); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании заметки: %w", err)
+		}
+		if reminderAt.Valid {
+			note.ReminderAt = &reminderAt.Time
+		}
+		if dueAt.Valid {
+			note.DueAt = &dueAt.Time
+		}
+		note.Location = locationFromSQL(locName, lat, lon)
+		note.Tags = []string(tags)
+		note.Attachments = []models.Attachment{}
+		byID[note.ID] = &note
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по строкам: %w", err)
+	}
+
+	attRows, err := s.readQuery(`SELECT `+attachmentColumns+` FROM attachments WHERE note_id = ANY($1)
+		ORDER BY note_id, position, uploaded_at, id`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении вложений заметок: %w", err)
+	}
+	defer attRows.Close()
+	for attRows.Next() {
+		attach, err := scanAttachment(attRows)
+		if err != nil {
+			return nil, err
+		}
+		if note := byID[attach.NoteID]; note != nil {
+			note.Attachments = append(note.Attachments, attach)
+		}
+	}
+	if err := attRows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по строкам вложений: %w", err)
+	}
+
+	notes := make([]models.Note, 0, len(byID))
+	for _, id := range ids {
+		if note := byID[id]; note != nil {
+			note.AttachmentText = attachmentText(note.Attachments)
+			note.AttachmentNames, note.AttachmentTypes = models.AttachmentMeta(note.Attachments)
+			notes = append(notes, *note)
+			delete(byID, id) // Повторный ID в ids не дублирует заметку
+		}
+	}
+	return notes, nil
+}
@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"GNote/models"
+)
+
+// blobChunkSize — размер порции при записи и чтении содержимого вложений в БД
+const blobChunkSize = 1 << 20
+
+// writeBlob создает large object и записывает в него r порциями, не загружая файл в память.
+// Возвращает идентификатор large object и число записанных байт.
+func writeBlob(tx *sql.Tx, r io.Reader) (int64, int64, error) {
+	var oid int64
+	if err := tx.QueryRow(`SELECT lo_create(0)`).Scan(&oid); err != nil {
+		return 0, 0, fmt.Errorf("ошибка при создании содержимого вложения: %w", err)
+	}
+	buf := make([]byte, blobChunkSize)
+	var offset int64
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if _, execErr := tx.Exec(`SELECT lo_put($1, $2, $3)`, oid, offset, buf[:n]); execErr != nil {
+				return 0, 0, fmt.Errorf("ошибка при записи содержимого вложения: %w", execErr)
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return oid, offset, nil
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("ошибка при чтении файла вложения: %w", err)
+		}
+	}
+}
+
+// CreateAttachmentBlob создает вложение, содержимое которого хранится в БД.
+// SizeBytes заполняется по фактически записанному объему.
+func (s *PostgresStore) CreateAttachmentBlob(attachment *models.Attachment, r io.Reader) error {
+	tx, err := s.begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	oid, size, err := writeBlob(tx, r)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO attachments (note_id, filename, filepath, mimetype, size_bytes, data_oid, description, source_url, position) VALUES ($1, $2, NULL, $3, $4, $5, $6, $7, ` + nextAttachmentPosition + `) RETURNING id, uploaded_at, position`
+	err = tx.QueryRow(query, attachment.NoteID, attachment.Filename, attachment.MimeType, size, oid, attachment.Description, attachment.SourceURL).Scan(&attachment.ID, &attachment.UploadedAt, &attachment.Position)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании вложения: %w", err)
+	}
+	if err := s.commit(tx); err != nil {
+		return fmt.Errorf("ошибка при создании вложения: %w", err)
+	}
+	attachment.Filepath = ""
+	attachment.SizeBytes = size
+	attachment.InDatabase = true
+	return nil
+}
+
+// OpenAttachmentBlob открывает на чтение содержимое вложения, хранящееся в БД
+func (s *PostgresStore) OpenAttachmentBlob(attachmentID int) (io.ReadCloser, error) {
+	var oid sql.NullInt64
+	err := s.db.QueryRow(`SELECT data_oid FROM attachments WHERE id = $1`, attachmentID).Scan(&oid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("вложение с ID %d не найдено", attachmentID)
+		}
+		return nil, fmt.Errorf("ошибка при получении вложения: %w", err)
+	}
+	if !oid.Valid {
+		return nil, fmt.Errorf("содержимое вложения с ID %d хранится не в БД", attachmentID)
+	}
+	return &blobReader{db: s.db, oid: oid.Int64}, nil
+}
+
+// blobReader читает large object порциями по blobChunkSize
+type blobReader struct {
+	db     *sql.DB
+	oid    int64
+	offset int64
+	buf    []byte // Прочитанная, но еще не отданная часть порции
+	eof    bool
+}
+
+// Read отдает содержимое, запрашивая следующую порцию из БД, когда буфер пуст
+func (r *blobReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		var chunk []byte
+		err := r.db.QueryRow(`SELECT lo_get($1, $2, $3)`, r.oid, r.offset, blobChunkSize).Scan(&chunk)
+		if err != nil {
+			return 0, fmt.Errorf("ошибка при чтении содержимого вложения: %w", err)
+		}
+		r.offset += int64(len(chunk))
+		r.buf = chunk
+		if len(chunk) < blobChunkSize {
+			r.eof = true
+		}
+		if len(chunk) == 0 {
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close ничего не освобождает: каждая порция читается отдельным запросом
+func (r *blobReader) Close() error {
+	return nil
+}
+
+// MoveAttachmentsToDatabase переносит содержимое вложений из файлов в БД и удаляет перенесенные файлы.
+// Возвращает число перенесенных вложений; при ошибке уже перенесенные остаются в БД.
+func (s *PostgresStore) MoveAttachmentsToDatabase() (int, error) {
+	rows, err := s.db.Query(`SELECT id, filepath FROM attachments WHERE data_oid IS NULL AND filepath IS NOT NULL ORDER BY id`)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении вложений: %w", err)
+	}
+	type fileAttachment struct {
+		id   int
+		path string
+	}
+	var pending []fileAttachment
+	for rows.Next() {
+		var a fileAttachment
+		if err := rows.Scan(&a.id, &a.path); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("ошибка при сканировании вложения: %w", err)
+		}
+		pending = append(pending, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("ошибка после итерации по строкам вложений: %w", err)
+	}
+
+	moved := 0
+	for _, a := range pending {
+		if err := s.moveToDatabase(a.id, a.path); err != nil {
+			return moved, err
+		}
+		if err := os.Remove(a.path); err != nil {
+			log.Printf("Ошибка при удалении перенесенного файла вложения '%s': %v", a.path, err)
+		}
+		moved++
+		log.Printf("Вложение ID %d перенесено в БД", a.id)
+	}
+	return moved, nil
+}
+
+// moveToDatabase записывает файл вложения в БД в одной транзакции с обновлением записи
+func (s *PostgresStore) moveToDatabase(id int, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть файл вложения ID %d: %w", id, err)
+	}
+	defer f.Close()
+
+	tx, err := s.begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	oid, size, err := writeBlob(tx, f)
+	if err != nil {
+		return fmt.Errorf("вложение ID %d: %w", id, err)
+	}
+	if _, err := tx.Exec(`UPDATE attachments SET data_oid = $1, filepath = NULL, size_bytes = $2 WHERE id = $3`, oid, size, id); err != nil {
+		return fmt.Errorf("ошибка при обновлении вложения ID %d: %w", id, err)
+	}
+	if err := s.commit(tx); err != nil {
+		return fmt.Errorf("ошибка при обновлении вложения ID %d: %w", id, err)
+	}
+	return nil
+}
+
+// MoveAttachmentsToFiles выгружает содержимое вложений из БД в файлы каталога dir.
+// Возвращает число перенесенных вложений; при ошибке уже перенесенные остаются файлами.
+func (s *PostgresStore) MoveAttachmentsToFiles(dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("не удалось создать директорию для вложений: %w", err)
+	}
+	rows, err := s.db.Query(`SELECT id, note_id, filename, uploaded_at FROM attachments WHERE data_oid IS NOT NULL ORDER BY id`)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении вложений: %w", err)
+	}
+	var pending []models.Attachment
+	for rows.Next() {
+		var a models.Attachment
+		if err := rows.Scan(&a.ID, &a.NoteID, &a.Filename, &a.UploadedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("ошибка при сканировании вложения: %w", err)
+		}
+		pending = append(pending, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("ошибка после итерации по строкам вложений: %w", err)
+	}
+
+	moved := 0
+	for _, a := range pending {
+		if err := s.moveToFile(a, dir); err != nil {
+			return moved, err
+		}
+		moved++
+		log.Printf("Вложение ID %d выгружено в файл", a.ID)
+	}
+	return moved, nil
+}
+
+// moveToFile выгружает одно вложение в файл и освобождает его содержимое в БД
+func (s *PostgresStore) moveToFile(a models.Attachment, dir string) error {
+	// Имя как у вложений, прикрепленных из окна заметок; ID добавляется, чтобы имена не совпадали
+	name := fmt.Sprintf("%d_%s_%d_%s", a.NoteID, a.UploadedAt.In(time.Local).Format("20060102150405"), a.ID, filepath.Base(a.Filename))
+	path := filepath.Join(dir, name)
+
+	blob, err := s.OpenAttachmentBlob(a.ID)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("не удалось создать файл вложения ID %d: %w", a.ID, err)
+	}
+	_, err = io.Copy(f, blob)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("не удалось выгрузить вложение ID %d: %w", a.ID, err)
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(`SELECT lo_unlink(data_oid) FROM attachments WHERE id = $1`, a.ID)
+	if err == nil {
+		_, err = tx.Exec(`UPDATE attachments SET filepath = $1, data_oid = NULL WHERE id = $2`, path, a.ID)
+	}
+	if err == nil {
+		err = s.commit(tx)
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("ошибка при обновлении вложения ID %d: %w", a.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Режимы channel_binding — привязки аутентификации SCRAM к TLS-соединению, как в libpq
+const (
+	ChannelBindingDisable = "disable"
+	ChannelBindingPrefer  = "prefer"
+	ChannelBindingRequire = "require"
+)
+
+// ErrChannelBindingUnsupported — channel_binding=require нельзя выполнить: драйвер lib/pq не умеет
+// SCRAM-SHA-256-PLUS, а подключаться без привязки вопреки настройке небезопасно
+var ErrChannelBindingUnsupported = errors.New("channel_binding=require не поддерживается драйвером PostgreSQL; " +
+	"вместо него проверяйте сертификат сервера: sslmode=verify-full и sslrootcert")
+
+// connString собирает строку подключения libpq вида key=value из настроек.
+// Файлы TLS (sslrootcert, sslcert, sslkey) дополняют и строку url, например DATABASE_URL от хостинга,
+// к которой сертификаты приходится добавлять локально.
+func (cfg Config) connString() (string, error) {
+	var b strings.Builder
+	add := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s=%s ", key, quoteConnValue(value))
+		}
+	}
+	channelBinding := cfg.ChannelBinding
+	if cfg.URL != "" {
+		connURL, urlBinding, err := withoutChannelBinding(cfg.URL)
+		if err != nil {
+			return "", err
+		}
+		if channelBinding == "" {
+			channelBinding = urlBinding
+		}
+		parsed, err := pq.ParseURL(connURL)
+		if err != nil {
+			return "", fmt.Errorf("неверная строка подключения к БД: %w", err)
+		}
+		b.WriteString(parsed + " ")
+	} else {
+		add("host", cfg.Host)
+		add("port", fmt.Sprint(cfg.Port))
+		add("user", cfg.User)
+		add("password", cfg.Password)
+		add("dbname", cfg.DBName)
+		add("sslmode", cfg.SSLMode)
+	}
+	add("sslrootcert", cfg.SSLRootCert)
+	add("sslcert", cfg.SSLCert)
+	add("sslkey", cfg.SSLKey)
+
+	switch channelBinding {
+	case "", ChannelBindingDisable, ChannelBindingPrefer: // prefer без поддержки в драйвере означает подключение без привязки
+	case ChannelBindingRequire:
+		return "", ErrChannelBindingUnsupported
+	default:
+		return "", fmt.Errorf("неизвестный режим channel_binding %q: доступны disable, prefer и require", channelBinding)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// withoutChannelBinding убирает из адреса postgres:// параметр channel_binding, который драйвер
+// передал бы серверу как неизвестную настройку, и возвращает его значение
+func withoutChannelBinding(connURL string) (string, string, error) {
+	u, err := url.Parse(connURL)
+	if err != nil {
+		return "", "", fmt.Errorf("неверная строка подключения к БД: %w", err)
+	}
+	query := u.Query()
+	binding := query.Get("channel_binding")
+	if binding == "" {
+		return connURL, "", nil
+	}
+	query.Del("channel_binding")
+	u.RawQuery = query.Encode()
+	return u.String(), binding, nil
+}
+
+// quoteConnValue заключает значение в кавычки по правилам libpq, чтобы пути с пробелами
+// и пароли со спецсимволами не ломали строку подключения
+func quoteConnValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"io"
+
+	"GNote/events"
+	"GNote/models"
+)
+
+// PublishingStore оборачивает Store и публикует события после успешных изменений данных.
+// Методы чтения передаются обернутому хранилищу без изменений.
+type PublishingStore struct {
+	Store
+	bus *events.Bus
+}
+
+// NewPublishingStore создает хранилище, публикующее события изменений в шину bus
+func NewPublishingStore(inner Store, bus *events.Bus) *PublishingStore {
+	return &PublishingStore{Store: inner, bus: bus}
+}
+
+// CreateNote создает заметку и публикует NoteCreated
+func (s *PublishingStore) CreateNote(note *models.Note) error {
+	if err := s.Store.CreateNote(note); err != nil {
+		return err
+	}
+	created := *note
+	s.bus.Publish(events.Event{Kind: events.NoteCreated, NoteID: note.ID, Note: &created})
+	return nil
+}
+
+// UpdateNote обновляет заметку и публикует NoteUpdated
+func (s *PublishingStore) UpdateNote(note *models.Note) error {
+	if err := s.Store.UpdateNote(note); err != nil {
+		return err
+	}
+	updated := *note
+	s.bus.Publish(events.Event{Kind: events.NoteUpdated, NoteID: note.ID, Note: &updated})
+	return nil
+}
+
+// DeleteNote удаляет заметку и публикует NoteDeleted
+func (s *PublishingStore) DeleteNote(id int) error {
+	if err := s.Store.DeleteNote(id); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{Kind: events.NoteDeleted, NoteID: id})
+	return nil
+}
+
+// SetFavorite меняет отметку избранного и публикует NoteUpdated без состояния заметки
+func (s *PublishingStore) SetFavorite(id int, favorite bool) error {
+	if err := s.Store.SetFavorite(id, favorite); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{Kind: events.NoteUpdated, NoteID: id})
+	return nil
+}
+
+// MergeNotes объединяет заметки и публикует NoteUpdated для targetID и NoteDeleted для sourceID
+func (s *PublishingStore) MergeNotes(targetID, sourceID int) error {
+	if err := s.Store.MergeNotes(targetID, sourceID); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{Kind: events.NoteDeleted, NoteID: sourceID})
+	s.bus.Publish(events.Event{Kind: events.NoteUpdated, NoteID: targetID})
+	return nil
+}
+
+// RenameTag переименовывает тег с вложенными и публикует NoteUpdated для каждой затронутой заметки
+func (s *PublishingStore) RenameTag(oldName, newName string) ([]int, error) {
+	noteIDs, err := s.Store.RenameTag(oldName, newName)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range noteIDs {
+		s.bus.Publish(events.Event{Kind: events.NoteUpdated, NoteID: id})
+	}
+	return noteIDs, nil
+}
+
+// ReplaceInNotes применяет массовую замену и публикует NoteUpdated для каждой измененной заметки
+func (s *PublishingStore) ReplaceInNotes(description string, edits []models.TextEdit) (int, error) {
+	snapshotID, err := s.Store.ReplaceInNotes(description, edits)
+	if err != nil {
+		return 0, err
+	}
+	for _, edit := range edits {
+		s.bus.Publish(events.Event{Kind: events.NoteUpdated, NoteID: edit.NoteID})
+	}
+	return snapshotID, nil
+}
+
+// UndoReplace отменяет массовую замену и публикует NoteUpdated для каждой восстановленной заметки
+func (s *PublishingStore) UndoReplace(snapshotID int) ([]int, error) {
+	noteIDs, err := s.Store.UndoReplace(snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range noteIDs {
+		s.bus.Publish(events.Event{Kind: events.NoteUpdated, NoteID: id})
+	}
+	return noteIDs, nil
+}
+
+// ImportNotes импортирует заметки и публикует NoteCreated или NoteUpdated для каждой импортированной
+func (s *PublishingStore) ImportNotes(notes []models.Note) (models.ImportReport, error) {
+	report, err := s.Store.ImportNotes(notes)
+	if err != nil {
+		return report, err
+	}
+	for _, result := range report.Results {
+		if result.Err != nil {
+			continue
+		}
+		note := result.Note
+		kind := events.NoteCreated
+		if result.Updated {
+			kind = events.NoteUpdated
+		}
+		s.bus.Publish(events.Event{Kind: kind, NoteID: note.ID, Note: &note})
+	}
+	return report, nil
+}
+
+// UpsertNoteByUID создает или перезаписывает заметку и публикует NoteCreated или NoteUpdated
+func (s *PublishingStore) UpsertNoteByUID(note *models.Note) (bool, error) {
+	created, err := s.Store.UpsertNoteByUID(note)
+	if err != nil {
+		return false, err
+	}
+	kind := events.NoteUpdated
+	if created {
+		kind = events.NoteCreated
+	}
+	published := *note
+	s.bus.Publish(events.Event{Kind: kind, NoteID: note.ID, Note: &published})
+	return created, nil
+}
+
+// CreateAttachment создает вложение и публикует AttachmentCreated
+func (s *PublishingStore) CreateAttachment(attachment *models.Attachment) error {
+	if err := s.Store.CreateAttachment(attachment); err != nil {
+		return err
+	}
+	created := *attachment
+	s.bus.Publish(events.Event{Kind: events.AttachmentCreated, NoteID: attachment.NoteID, AttachmentID: attachment.ID, Attachment: &created})
+	return nil
+}
+
+// CreateAttachmentBlob создает вложение с содержимым в БД и публикует AttachmentCreated
+func (s *PublishingStore) CreateAttachmentBlob(attachment *models.Attachment, r io.Reader) error {
+	if err := s.Store.CreateAttachmentBlob(attachment, r); err != nil {
+		return err
+	}
+	created := *attachment
+	s.bus.Publish(events.Event{Kind: events.AttachmentCreated, NoteID: attachment.NoteID, AttachmentID: attachment.ID, Attachment: &created})
+	return nil
+}
+
+// DeleteAttachment удаляет вложение и публикует AttachmentDeleted.
+// ID заметки неизвестен, поэтому подписчики должны искать вложение по AttachmentID.
+func (s *PublishingStore) DeleteAttachment(attachmentID int) error {
+	if err := s.Store.DeleteAttachment(attachmentID); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{Kind: events.AttachmentDeleted, AttachmentID: attachmentID})
+	return nil
+}
+
+// UpdateAttachment сохраняет имя и описание вложения и публикует NoteUpdated: меняются поиск file: и по описанию
+func (s *PublishingStore) UpdateAttachment(attachment *models.Attachment) error {
+	if err := s.Store.UpdateAttachment(attachment); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{Kind: events.NoteUpdated, NoteID: attachment.NoteID})
+	return nil
+}
+
+// SetAttachmentOrder меняет порядок вложений заметки и публикует NoteUpdated
+func (s *PublishingStore) SetAttachmentOrder(noteID int, attachmentIDs []int) error {
+	if err := s.Store.SetAttachmentOrder(noteID, attachmentIDs); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{Kind: events.NoteUpdated, NoteID: noteID})
+	return nil
+}
+
+// MoveAttachments переносит вложения в другую заметку и публикует NoteUpdated для обеих заметок
+func (s *PublishingStore) MoveAttachments(fromNoteID, toNoteID int) error {
+	if err := s.Store.MoveAttachments(fromNoteID, toNoteID); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{Kind: events.NoteUpdated, NoteID: fromNoteID})
+	s.bus.Publish(events.Event{Kind: events.NoteUpdated, NoteID: toNoteID})
+	return nil
+}
+
+// CopyAttachment копирует вложение в другую заметку и публикует AttachmentCreated
+func (s *PublishingStore) CopyAttachment(id, newNoteID int) (*models.Attachment, error) {
+	attachment, err := s.Store.CopyAttachment(id, newNoteID)
+	if err != nil {
+		return nil, err
+	}
+	created := *attachment
+	s.bus.Publish(events.Event{Kind: events.AttachmentCreated, NoteID: attachment.NoteID, AttachmentID: attachment.ID, Attachment: &created})
+	return attachment, nil
+}
+
+// SetAttachmentText сохраняет текст вложения и публикует NoteUpdated, чтобы заметка нашлась по новому тексту
+func (s *PublishingStore) SetAttachmentText(attachment *models.Attachment, text string) error {
+	if err := s.Store.SetAttachmentText(attachment, text); err != nil {
+		return err
+	}
+	if text != "" {
+		s.bus.Publish(events.Event{Kind: events.NoteUpdated, NoteID: attachment.NoteID})
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// pingTimeout ограничивает время ожидания ответа БД при проверке
+const pingTimeout = 5 * time.Second
+
+// IsUnavailable сообщает, вызвана ли ошибка потерей связи с БД, а не самим запросом
+func IsUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// Класс 08 — ошибки соединения, 57P01–57P03 — сервер остановлен или недоступен
+		switch {
+		case pqErr.Code.Class() == "08":
+			return true
+		case pqErr.Code == "57P01", pqErr.Code == "57P02", pqErr.Code == "57P03":
+			return true
+		}
+	}
+	return false
+}
+
+// Health описывает результат последней проверки БД
+type Health struct {
+	Available bool
+	Latency   time.Duration // Время ответа на последнюю успешную проверку
+	Err       error         // Причина недоступности
+	CheckedAt time.Time
+}
+
+// HealthChecker периодически проверяет связь с БД и сообщает подписчикам о ее состоянии
+type HealthChecker struct {
+	mu        sync.Mutex
+	store     Store
+	last      Health
+	listeners map[int]func(Health)
+	nextID    int
+}
+
+// NewHealthChecker создает проверку связи с хранилищем. До первой проверки БД считается доступной.
+func NewHealthChecker(store Store) *HealthChecker {
+	return &HealthChecker{
+		store:     store,
+		last:      Health{Available: true},
+		listeners: make(map[int]func(Health)),
+	}
+}
+
+// SetStore переключает проверку на другое хранилище (например, при смене профиля)
+func (h *HealthChecker) SetStore(store Store) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.store = store
+}
+
+// Subscribe добавляет обработчик изменений состояния и возвращает функцию отписки.
+// Обработчик вызывается в горутине проверки.
+func (h *HealthChecker) Subscribe(fn func(Health)) (unsubscribe func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	id := h.nextID
+	h.nextID++
+	h.listeners[id] = fn
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.listeners, id)
+	}
+}
+
+// Last возвращает результат последней проверки
+func (h *HealthChecker) Last() Health {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last
+}
+
+// Check проверяет связь с БД; подходит как Job планировщика
+func (h *HealthChecker) Check(now time.Time) {
+	h.mu.Lock()
+	store := h.store
+	h.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	start := time.Now()
+	err := store.Ping(ctx)
+	health := Health{Available: err == nil, Err: err, CheckedAt: now}
+	if err == nil {
+		health.Latency = time.Since(start)
+	}
+	h.set(health)
+}
+
+// MarkUnavailable отмечает БД недоступной после неудачного обращения к хранилищу,
+// не дожидаясь следующей проверки
+func (h *HealthChecker) MarkUnavailable(err error) {
+	h.set(Health{Available: false, Err: err, CheckedAt: time.Now()})
+}
+
+// set сохраняет результат проверки и уведомляет подписчиков
+func (h *HealthChecker) set(health Health) {
+	h.mu.Lock()
+	if h.last.Available != health.Available {
+		if health.Available {
+			log.Printf("Связь с БД восстановлена")
+		} else {
+			log.Printf("БД недоступна: %v", health.Err)
+		}
+	}
+	h.last = health
+	listeners := make([]func(Health), 0, len(h.listeners))
+	for _, fn := range h.listeners {
+		listeners = append(listeners, fn)
+	}
+	h.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(health)
+	}
+}
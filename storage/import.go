@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"GNote/models"
+)
+
+// ImportNotes импортирует заметки одной транзакцией: заметка с UID или ID существующей заметки
+// перезаписывает ее, остальные создаются заново с новыми ID. Ошибка в одной заметке откатывает только ее (через точку
+// сохранения) и попадает в отчет; ошибка самой транзакции отменяет весь импорт.
+// Вложения импортируются как записи о файлах, которые уже лежат на диске по указанным путям.
+func (s *PostgresStore) ImportNotes(notes []models.Note) (models.ImportReport, error) {
+	report := models.ImportReport{Results: make([]models.ImportResult, len(notes))}
+	tx, err := s.begin()
+	if err != nil {
+		return report, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, note := range notes {
+		result := &report.Results[i]
+		if _, err := tx.Exec(`SAVEPOINT import_note`); err != nil {
+			return report, fmt.Errorf("ошибка при импорте заметок: %w", err)
+		}
+		result.Updated, result.Err = s.importNote(tx, &note)
+		if result.Err != nil {
+			if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT import_note`); err != nil {
+				return report, fmt.Errorf("ошибка при импорте заметок: %w", err)
+			}
+			result.Note = notes[i]
+			continue
+		}
+		if _, err := tx.Exec(`RELEASE SAVEPOINT import_note`); err != nil {
+			return report, fmt.Errorf("ошибка при импорте заметок: %w", err)
+		}
+		result.Note = note
+		result.FailedAttachments = s.importAttachments(tx, &result.Note)
+	}
+	if err := s.commit(tx); err != nil {
+		return report, fmt.Errorf("ошибка при импорте заметок: %w", err)
+	}
+	return report, nil
+}
+
+// importNote перезаписывает заметку с тем же UID или, если UID не задан, с ID note.ID, а если такой нет — создает новую
+func (s *PostgresStore) importNote(tx *sql.Tx, note *models.Note) (bool, error) {
+	if note.UID != "" {
+		id, err := noteIDByUID(tx, note.UID)
+		if err != nil {
+			return false, err
+		}
+		note.ID = id
+		if id != 0 {
+			return true, s.updateNoteTx(tx, note)
+		}
+	} else if note.ID != 0 {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM notes WHERE id = $1)`, note.ID).Scan(&exists); err != nil {
+			return false, fmt.Errorf("ошибка при поиске заметки: %w", err)
+		}
+		if exists {
+			return true, s.updateNoteTx(tx, note)
+		}
+	}
+	note.ID = 0
+	return false, s.createNoteTx(tx, note)
+}
+
+// UpsertNoteByUID перезаписывает заметку с UID note.UID или, если такой нет, создает ее с этим UID.
+// ID заметки в других базах отличается, поэтому для синхронизации заметки сопоставляются только по UID.
+func (s *PostgresStore) UpsertNoteByUID(note *models.Note) (bool, error) {
+	if note.UID == "" {
+		return false, errors.New("у заметки не задан UID")
+	}
+	tx, err := s.begin()
+	if err != nil {
+		return false, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	note.ID, err = noteIDByUID(tx, note.UID)
+	if err != nil {
+		return false, err
+	}
+	created := note.ID == 0
+	if created {
+		err = s.createNoteTx(tx, note)
+	} else {
+		err = s.updateNoteTx(tx, note)
+	}
+	if err != nil {
+		return false, err
+	}
+	return created, s.commit(tx)
+}
+
+// noteIDByUID возвращает ID заметки с UID uid и блокирует ее до конца транзакции; 0 — такой заметки нет
+func noteIDByUID(tx *sql.Tx, uid string) (int, error) {
+	var id int
+	err := tx.QueryRow(`SELECT id FROM notes WHERE uid = $1 FOR UPDATE`, uid).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при поиске заметки по UID %s: %w", uid, err)
+	}
+	return id, nil
+}
+
+// importAttachments создает записи о вложениях заметки, файлы которых существуют,
+// и возвращает вложения, которые импортировать не удалось
+func (s *PostgresStore) importAttachments(tx *sql.Tx, note *models.Note) []models.AttachmentError {
+	var failed []models.AttachmentError
+	attachments := note.Attachments
+	note.Attachments = nil
+	for _, attach := range attachments {
+		if _, err := os.Stat(attach.Filepath); err != nil {
+			failed = append(failed, models.AttachmentError{Filename: attach.Filename,
+				Reason: fmt.Sprintf("файл не найден по пути '%s'", attach.Filepath)})
+			continue
+		}
+		attach.NoteID = note.ID
+		attach.InDatabase = false
+		if _, err := tx.Exec(`SAVEPOINT import_attachment`); err != nil {
+			failed = append(failed, models.AttachmentError{Filename: attach.Filename, Reason: err.Error()})
+			continue
+		}
+		if err := createAttachment(tx, &attach); err != nil {
+			tx.Exec(`ROLLBACK TO SAVEPOINT import_attachment`)
+			failed = append(failed, models.AttachmentError{Filename: attach.Filename, Reason: err.Error()})
+			continue
+		}
+		tx.Exec(`RELEASE SAVEPOINT import_attachment`)
+		note.Attachments = append(note.Attachments, attach)
+	}
+	return failed
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"GNote/models"
+)
+
+// defaultMaxContentBytes — предел содержимого заметки по умолчанию; колонка TEXT допускает до 1 ГБ,
+// но такие заметки уже не открыть в редакторе
+const defaultMaxContentBytes = 10 << 20
+
+// Limits — ограничения размера заметки, которые проверяются до записи, раньше ограничений колонок БД
+type Limits struct {
+	MaxTitleLength  int   // Символов в заголовке; не больше MaxTitleLength
+	MaxContentBytes int64 // Байт в содержимом
+}
+
+// DefaultLimits — ограничения, если в настройках не заданы другие
+var DefaultLimits = Limits{MaxTitleLength: MaxTitleLength, MaxContentBytes: defaultMaxContentBytes}
+
+// withDefaults заменяет незаданные и недопустимые значения значениями по умолчанию
+func (l Limits) withDefaults() Limits {
+	if l.MaxTitleLength <= 0 || l.MaxTitleLength > MaxTitleLength {
+		l.MaxTitleLength = DefaultLimits.MaxTitleLength
+	}
+	if l.MaxContentBytes <= 0 {
+		l.MaxContentBytes = DefaultLimits.MaxContentBytes
+	}
+	return l
+}
+
+// Поля заметки, которые проверяются ValidationError
+const (
+	FieldTitle   = "title"
+	FieldContent = "content"
+)
+
+// ValidationError — заметка не сохранена, потому что поле Field больше предела
+type ValidationError struct {
+	Field string // FieldTitle или FieldContent
+	Size  int64  // Длина заголовка в символах или размер содержимого в байтах
+	Limit int64
+}
+
+// Error объясняет, какое поле и насколько превышает предел
+func (e *ValidationError) Error() string {
+	if e.Field == FieldTitle {
+		return fmt.Sprintf("заголовок длиннее %d символов (%d)", e.Limit, e.Size)
+	}
+	return fmt.Sprintf("содержимое больше %s (%s)", formatSize(e.Limit), formatSize(e.Size))
+}
+
+// CheckNote возвращает *ValidationError, если заголовок или содержимое заметки больше пределов
+func (l Limits) CheckNote(note *models.Note) error {
+	l = l.withDefaults()
+	if n := utf8.RuneCountInString(note.Title); n > l.MaxTitleLength {
+		return &ValidationError{Field: FieldTitle, Size: int64(n), Limit: int64(l.MaxTitleLength)}
+	}
+	if n := int64(len(note.Content)); n > l.MaxContentBytes {
+		return &ValidationError{Field: FieldContent, Size: n, Limit: l.MaxContentBytes}
+	}
+	return nil
+}
+
+// NoteLimits возвращает ограничения размера заметки, с которыми работает хранилище
+func (s *PostgresStore) NoteLimits() Limits {
+	return s.limits
+}
+
+// formatSize возвращает размер в байтах, КБ или МБ
+func formatSize(b int64) string {
+	switch {
+	case b >= 1<<20:
+		return fmt.Sprintf("%.1f МБ", float64(b)/(1<<20))
+	case b >= 1<<10:
+		return fmt.Sprintf("%.1f КБ", float64(b)/(1<<10))
+	}
+	return fmt.Sprintf("%d байт", b)
+}
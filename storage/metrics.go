@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// metricBuckets — верхние границы корзин гистограммы длительности вызовов в секундах,
+// как у клиентов Prometheus по умолчанию
+var metricBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// MethodStats — статистика вызовов одного метода хранилища
+type MethodStats struct {
+	Method  string
+	Calls   int64
+	Errors  int64
+	Total   time.Duration
+	Max     time.Duration
+	buckets []int64 // Число вызовов в каждой корзине metricBuckets (не накопительное)
+}
+
+// Average возвращает среднюю длительность вызова
+func (s MethodStats) Average() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Calls)
+}
+
+// Metrics собирает длительность вызовов методов хранилища и записывает в журнал медленные вызовы.
+// Одни метрики на процесс: при смене профиля счетчики продолжаются.
+type Metrics struct {
+	mu      sync.Mutex
+	methods map[string]*MethodStats
+	slow    time.Duration // Порог медленного вызова; 0 — не записывать
+}
+
+// NewMetrics создает сбор метрик; вызовы дольше slow записываются в журнал (0 — не записываются)
+func NewMetrics(slow time.Duration) *Metrics {
+	return &Metrics{methods: make(map[string]*MethodStats), slow: slow}
+}
+
+// track учитывает вызов method, начатый в started; вызывается через defer с указателем на ошибку результата
+func (m *Metrics) track(method string, started time.Time, errp *error) {
+	d := time.Since(started)
+	failed := errp != nil && *errp != nil
+	if m.slow > 0 && d >= m.slow {
+		if failed {
+			log.Printf("Медленный запрос к БД: %s выполнялся %s и завершился ошибкой: %v", method, d.Round(time.Millisecond), *errp)
+		} else {
+			log.Printf("Медленный запрос к БД: %s выполнялся %s", method, d.Round(time.Millisecond))
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.methods[method]
+	if !ok {
+		s = &MethodStats{Method: method, buckets: make([]int64, len(metricBuckets))}
+		m.methods[method] = s
+	}
+	s.Calls++
+	if failed {
+		s.Errors++
+	}
+	s.Total += d
+	s.Max = max(s.Max, d)
+	if i := sort.SearchFloat64s(metricBuckets, d.Seconds()); i < len(metricBuckets) {
+		s.buckets[i]++
+	}
+}
+
+// Snapshot возвращает статистику методов, начиная с занявших больше всего времени
+func (m *Metrics) Snapshot() []MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make([]MethodStats, 0, len(m.methods))
+	for _, s := range m.methods {
+		c := *s
+		c.buckets = append([]int64(nil), s.buckets...)
+		stats = append(stats, c)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total != stats[j].Total {
+			return stats[i].Total > stats[j].Total
+		}
+		return stats[i].Method < stats[j].Method
+	})
+	return stats
+}
+
+// Reset обнуляет статистику, например перед повторением медленного действия
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(m.methods)
+}
+
+// WritePrometheus выводит метрики в текстовом формате Prometheus: гистограмма
+// gnote_store_call_duration_seconds и счетчик ошибок gnote_store_call_errors_total по методам
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	stats := m.Snapshot()
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Method < stats[j].Method }) // Стабильный вывод
+	b := bufio.NewWriter(w)
+	b.WriteString("# HELP gnote_store_call_duration_seconds Длительность вызовов методов хранилища.\n")
+	b.WriteString("# TYPE gnote_store_call_duration_seconds histogram\n")
+	for _, s := range stats {
+		var cumulative int64
+		for i, le := range metricBuckets {
+			cumulative += s.buckets[i]
+			fmt.Fprintf(b, "gnote_store_call_duration_seconds_bucket{method=%q,le=%q} %d\n",
+				s.Method, strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(b, "gnote_store_call_duration_seconds_bucket{method=%q,le=\"+Inf\"} %d\n", s.Method, s.Calls)
+		fmt.Fprintf(b, "gnote_store_call_duration_seconds_sum{method=%q} %g\n", s.Method, s.Total.Seconds())
+		fmt.Fprintf(b, "gnote_store_call_duration_seconds_count{method=%q} %d\n", s.Method, s.Calls)
+	}
+	b.WriteString("# HELP gnote_store_call_errors_total Вызовы методов хранилища, завершившиеся ошибкой.\n")
+	b.WriteString("# TYPE gnote_store_call_errors_total counter\n")
+	for _, s := range stats {
+		fmt.Fprintf(b, "gnote_store_call_errors_total{method=%q} %d\n", s.Method, s.Errors)
+	}
+	return b.Flush()
+}
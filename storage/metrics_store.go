@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"GNote/models"
+)
+
+// MetricsStore оборачивает Store и замеряет длительность каждого вызова: статистика собирается
+// в Metrics, а медленные вызовы записываются в журнал. Оборачивает хранилище PostgreSQL напрямую,
+// чтобы замерять запросы к БД, а не ответы офлайн-кэша.
+type MetricsStore struct {
+	inner   Store
+	metrics *Metrics
+}
+
+// NewMetricsStore создает хранилище, замеряющее вызовы inner
+func NewMetricsStore(inner Store, m *Metrics) *MetricsStore {
+	return &MetricsStore{inner: inner, metrics: m}
+}
+
+// Методы ниже передают вызов обернутому хранилищу. Store не встраивается, чтобы новый метод
+// интерфейса нельзя было забыть замерить: без обертки MetricsStore не скомпилируется.
+
+func (s *MetricsStore) CreateNote(note *models.Note) (err error) {
+	defer s.metrics.track("CreateNote", time.Now(), &err)
+	return s.inner.CreateNote(note)
+}
+
+func (s *MetricsStore) GetNoteByID(id int) (result *models.Note, err error) {
+	defer s.metrics.track("GetNoteByID", time.Now(), &err)
+	return s.inner.GetNoteByID(id)
+}
+
+func (s *MetricsStore) GetAllNotes() (result []models.Note, err error) {
+	defer s.metrics.track("GetAllNotes", time.Now(), &err)
+	return s.inner.GetAllNotes()
+}
+
+func (s *MetricsStore) UpdateNote(note *models.Note) (err error) {
+	defer s.metrics.track("UpdateNote", time.Now(), &err)
+	return s.inner.UpdateNote(note)
+}
+
+func (s *MetricsStore) DeleteNote(id int) (err error) {
+	defer s.metrics.track("DeleteNote", time.Now(), &err)
+	return s.inner.DeleteNote(id)
+}
+
+func (s *MetricsStore) SetFavorite(id int, favorite bool) (err error) {
+	defer s.metrics.track("SetFavorite", time.Now(), &err)
+	return s.inner.SetFavorite(id, favorite)
+}
+
+func (s *MetricsStore) MergeNotes(targetID, sourceID int) (err error) {
+	defer s.metrics.track("MergeNotes", time.Now(), &err)
+	return s.inner.MergeNotes(targetID, sourceID)
+}
+
+func (s *MetricsStore) ImportNotes(notes []models.Note) (result models.ImportReport, err error) {
+	defer s.metrics.track("ImportNotes", time.Now(), &err)
+	return s.inner.ImportNotes(notes)
+}
+
+func (s *MetricsStore) UpsertNoteByUID(note *models.Note) (created bool, err error) {
+	defer s.metrics.track("UpsertNoteByUID", time.Now(), &err)
+	return s.inner.UpsertNoteByUID(note)
+}
+
+func (s *MetricsStore) MoveAttachments(fromNoteID, toNoteID int) (err error) {
+	defer s.metrics.track("MoveAttachments", time.Now(), &err)
+	return s.inner.MoveAttachments(fromNoteID, toNoteID)
+}
+
+func (s *MetricsStore) CopyAttachment(id, newNoteID int) (result *models.Attachment, err error) {
+	defer s.metrics.track("CopyAttachment", time.Now(), &err)
+	return s.inner.CopyAttachment(id, newNoteID)
+}
+
+func (s *MetricsStore) SearchTags(prefix string) (result []string, err error) {
+	defer s.metrics.track("SearchTags", time.Now(), &err)
+	return s.inner.SearchTags(prefix)
+}
+
+func (s *MetricsStore) RenameTag(oldName, newName string) (result []int, err error) {
+	defer s.metrics.track("RenameTag", time.Now(), &err)
+	return s.inner.RenameTag(oldName, newName)
+}
+
+func (s *MetricsStore) ReplaceInNotes(description string, edits []models.TextEdit) (result int, err error) {
+	defer s.metrics.track("ReplaceInNotes", time.Now(), &err)
+	return s.inner.ReplaceInNotes(description, edits)
+}
+
+func (s *MetricsStore) GetReplaceSnapshots() (result []models.ReplaceSnapshot, err error) {
+	defer s.metrics.track("GetReplaceSnapshots", time.Now(), &err)
+	return s.inner.GetReplaceSnapshots()
+}
+
+func (s *MetricsStore) UndoReplace(snapshotID int) (result []int, err error) {
+	defer s.metrics.track("UndoReplace", time.Now(), &err)
+	return s.inner.UndoReplace(snapshotID)
+}
+
+func (s *MetricsStore) GetTagStyles() (result map[string]models.TagStyle, err error) {
+	defer s.metrics.track("GetTagStyles", time.Now(), &err)
+	return s.inner.GetTagStyles()
+}
+
+func (s *MetricsStore) SetTagStyle(name string, style models.TagStyle) (err error) {
+	defer s.metrics.track("SetTagStyle", time.Now(), &err)
+	return s.inner.SetTagStyle(name, style)
+}
+
+func (s *MetricsStore) GetRecurringRules() (result []models.RecurringRule, err error) {
+	defer s.metrics.track("GetRecurringRules", time.Now(), &err)
+	return s.inner.GetRecurringRules()
+}
+
+func (s *MetricsStore) SaveRecurringRule(rule *models.RecurringRule) (err error) {
+	defer s.metrics.track("SaveRecurringRule", time.Now(), &err)
+	return s.inner.SaveRecurringRule(rule)
+}
+
+func (s *MetricsStore) DeleteRecurringRule(id int) (err error) {
+	defer s.metrics.track("DeleteRecurringRule", time.Now(), &err)
+	return s.inner.DeleteRecurringRule(id)
+}
+
+func (s *MetricsStore) ClaimRecurringRun(rule *models.RecurringRule, runAt time.Time) (result bool, err error) {
+	defer s.metrics.track("ClaimRecurringRun", time.Now(), &err)
+	return s.inner.ClaimRecurringRun(rule, runAt)
+}
+
+func (s *MetricsStore) LogActivity(activity *models.Activity) (err error) {
+	defer s.metrics.track("LogActivity", time.Now(), &err)
+	return s.inner.LogActivity(activity)
+}
+
+func (s *MetricsStore) GetActivity(limit int) (result []models.Activity, err error) {
+	defer s.metrics.track("GetActivity", time.Now(), &err)
+	return s.inner.GetActivity(limit)
+}
+
+func (s *MetricsStore) GetSnippets() (result []models.Snippet, err error) {
+	defer s.metrics.track("GetSnippets", time.Now(), &err)
+	return s.inner.GetSnippets()
+}
+
+func (s *MetricsStore) SaveSnippet(snippet *models.Snippet) (err error) {
+	defer s.metrics.track("SaveSnippet", time.Now(), &err)
+	return s.inner.SaveSnippet(snippet)
+}
+
+func (s *MetricsStore) DeleteSnippet(id int) (err error) {
+	defer s.metrics.track("DeleteSnippet", time.Now(), &err)
+	return s.inner.DeleteSnippet(id)
+}
+
+func (s *MetricsStore) GetDueReminders(from, to time.Time) (result []models.Note, err error) {
+	defer s.metrics.track("GetDueReminders", time.Now(), &err)
+	return s.inner.GetDueReminders(from, to)
+}
+
+func (s *MetricsStore) GetStatistics(from time.Time) (result *models.Statistics, err error) {
+	defer s.metrics.track("GetStatistics", time.Now(), &err)
+	return s.inner.GetStatistics(from)
+}
+
+func (s *MetricsStore) CountNotes(filter models.NoteFilter) (result int, err error) {
+	defer s.metrics.track("CountNotes", time.Now(), &err)
+	return s.inner.CountNotes(filter)
+}
+
+func (s *MetricsStore) CountByTag() (result map[string]int, err error) {
+	defer s.metrics.track("CountByTag", time.Now(), &err)
+	return s.inner.CountByTag()
+}
+
+func (s *MetricsStore) StorageUsage() (result *models.StorageUsage, err error) {
+	defer s.metrics.track("StorageUsage", time.Now(), &err)
+	return s.inner.StorageUsage()
+}
+
+func (s *MetricsStore) CreateAttachment(attachment *models.Attachment) (err error) {
+	defer s.metrics.track("CreateAttachment", time.Now(), &err)
+	return s.inner.CreateAttachment(attachment)
+}
+
+func (s *MetricsStore) GetAttachmentsByNoteID(noteID int) (result []models.Attachment, err error) {
+	defer s.metrics.track("GetAttachmentsByNoteID", time.Now(), &err)
+	return s.inner.GetAttachmentsByNoteID(noteID)
+}
+
+func (s *MetricsStore) GetNotesWithAttachments(ids []int) (result []models.Note, err error) {
+	defer s.metrics.track("GetNotesWithAttachments", time.Now(), &err)
+	return s.inner.GetNotesWithAttachments(ids)
+}
+
+func (s *MetricsStore) DeleteAttachment(attachmentID int) (err error) {
+	defer s.metrics.track("DeleteAttachment", time.Now(), &err)
+	return s.inner.DeleteAttachment(attachmentID)
+}
+
+func (s *MetricsStore) CreateAttachmentBlob(attachment *models.Attachment, r io.Reader) (err error) {
+	defer s.metrics.track("CreateAttachmentBlob", time.Now(), &err)
+	return s.inner.CreateAttachmentBlob(attachment, r)
+}
+
+func (s *MetricsStore) OpenAttachmentBlob(attachmentID int) (result io.ReadCloser, err error) {
+	defer s.metrics.track("OpenAttachmentBlob", time.Now(), &err)
+	return s.inner.OpenAttachmentBlob(attachmentID)
+}
+
+func (s *MetricsStore) GetAttachmentsWithoutText(mimePrefix string, limit int) (result []models.Attachment, err error) {
+	defer s.metrics.track("GetAttachmentsWithoutText", time.Now(), &err)
+	return s.inner.GetAttachmentsWithoutText(mimePrefix, limit)
+}
+
+func (s *MetricsStore) SetAttachmentText(attachment *models.Attachment, text string) (err error) {
+	defer s.metrics.track("SetAttachmentText", time.Now(), &err)
+	return s.inner.SetAttachmentText(attachment, text)
+}
+
+func (s *MetricsStore) UpdateAttachment(attachment *models.Attachment) (err error) {
+	defer s.metrics.track("UpdateAttachment", time.Now(), &err)
+	return s.inner.UpdateAttachment(attachment)
+}
+
+func (s *MetricsStore) SetAttachmentOrder(noteID int, attachmentIDs []int) (err error) {
+	defer s.metrics.track("SetAttachmentOrder", time.Now(), &err)
+	return s.inner.SetAttachmentOrder(noteID, attachmentIDs)
+}
+
+func (s *MetricsStore) NoteLimits() Limits {
+	return s.inner.NoteLimits() // Настройка, а не запрос к БД
+}
+
+func (s *MetricsStore) Ping(ctx context.Context) (err error) {
+	defer s.metrics.track("Ping", time.Now(), &err)
+	return s.inner.Ping(ctx)
+}
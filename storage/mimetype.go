@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// SniffLen — сколько первых байт содержимого нужно DetectMimeType
+const SniffLen = 512
+
+// genericTypes — типы, которые http.DetectContentType возвращает для многих форматов сразу:
+// docx и odt распознаются как zip, markdown и csv — как обычный текст. Для них расширение точнее.
+var genericTypes = map[string]bool{
+	"application/octet-stream": true,
+	"application/zip":          true,
+	"text/plain":               true,
+}
+
+// DetectMimeType определяет тип вложения по первым байтам содержимого head, а если по ним
+// понятен только общий вид (zip, текст, двоичные данные) — по расширению filename
+func DetectMimeType(filename string, head []byte) string {
+	sniffed := "application/octet-stream"
+	if len(head) > 0 {
+		sniffed = http.DetectContentType(head)
+	}
+	base, _, _ := strings.Cut(sniffed, ";")
+	if !genericTypes[base] {
+		return sniffed
+	}
+	if byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(filename))); byExt != "" {
+		return byExt
+	}
+	return sniffed
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"GNote/events"
+)
+
+// changesChannel — канал LISTEN/NOTIFY, в который триггеры из database.sql сообщают об изменениях
+const changesChannel = "gnote_changes"
+
+// change — уведомление триггера об изменении заметки или вложения
+type change struct {
+	Kind         string `json:"kind"` // Название типа события, см. events.Kind.String
+	NoteID       int    `json:"note_id"`
+	AttachmentID int    `json:"attachment_id"`
+	Source       string `json:"source"` // application_name подключения, внесшего изменение
+}
+
+// newInstanceName возвращает application_name для подключений этого хранилища
+func newInstanceName() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return "gnote-" + hex.EncodeToString(b)
+}
+
+// withApplicationName добавляет к строке подключения application_name; адрес postgres://
+// переводится в формат ключ=значение, чтобы параметр можно было дописать в конец
+func withApplicationName(connStr, name string) (string, error) {
+	if strings.HasPrefix(connStr, "postgres://") || strings.HasPrefix(connStr, "postgresql://") {
+		parsed, err := pq.ParseURL(connStr)
+		if err != nil {
+			return "", fmt.Errorf("неверная строка подключения к БД: %w", err)
+		}
+		connStr = parsed
+	}
+	return connStr + " application_name=" + name, nil // Последнее значение параметра заменяет прежние
+}
+
+// ListenChanges подписывается на уведомления об изменениях, внесенных другими экземплярами GNote
+// или любыми другими клиентами БД, и публикует их в bus с отметкой Remote.
+// Возвращает функцию остановки.
+func (s *PostgresStore) ListenChanges(bus *events.Bus) func() {
+	listener := pq.NewListener(s.connStr, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		switch ev {
+		case pq.ListenerEventDisconnected:
+			log.Printf("Уведомления об изменениях в БД прерваны: %v", err)
+		case pq.ListenerEventReconnected:
+			log.Println("Уведомления об изменениях в БД возобновлены")
+		}
+	})
+	done := make(chan struct{})
+	go func() {
+		// Listen ждет подключения к БД, поэтому вызывается в фоне
+		if err := listener.Listen(changesChannel); err != nil {
+			log.Printf("Не удалось подписаться на изменения в БД: %v", err)
+			return
+		}
+		for {
+			select {
+			case <-done:
+				return
+			case n := <-listener.Notify:
+				if n != nil { // nil приходит после переподключения
+					s.applyChange(n.Extra, bus)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		listener.Close()
+	}
+}
+
+// applyChange публикует изменение из уведомления, если его внес не этот экземпляр
+func (s *PostgresStore) applyChange(payload string, bus *events.Bus) {
+	var c change
+	if err := json.Unmarshal([]byte(payload), &c); err != nil {
+		log.Printf("Неверное уведомление об изменении в БД %q: %v", payload, err)
+		return
+	}
+	if c.Source == s.instance {
+		return // Свои изменения уже опубликованы PublishingStore
+	}
+	kind, ok := events.ParseKind(c.Kind)
+	if !ok {
+		log.Printf("Неизвестное изменение в БД %q", c.Kind)
+		return
+	}
+	// Реплика может еще не получить это изменение, а подписчики сразу перечитают заметки
+	s.wrote()
+	PublishRemote(s, bus, events.Event{Kind: kind, NoteID: c.NoteID, AttachmentID: c.AttachmentID})
+}
+
+// PublishRemote публикует в bus изменение, внесенное другим экземпляром, с отметкой Remote.
+// Заметку и вложение подписчики ждут в событии, поэтому они загружаются из store.
+func PublishRemote(store Store, bus *events.Bus, e events.Event) {
+	e.Remote = true
+	switch e.Kind {
+	case events.NoteCreated, events.NoteUpdated:
+		note, err := store.GetNoteByID(e.NoteID)
+		if errors.Is(err, ErrNoteNotFound) {
+			return // Заметку уже удалили, событие об этом придет следом
+		}
+		if err != nil {
+			log.Printf("Ошибка при загрузке заметки ID %d, измененной в другом окне: %v", e.NoteID, err)
+			return
+		}
+		e.Note = note
+	case events.AttachmentCreated:
+		if e.Attachment != nil {
+			break
+		}
+		attachments, err := store.GetAttachmentsByNoteID(e.NoteID)
+		if err != nil {
+			log.Printf("Ошибка при загрузке вложений заметки ID %d, измененной в другом окне: %v", e.NoteID, err)
+			return
+		}
+		for i := range attachments {
+			if attachments[i].ID == e.AttachmentID {
+				e.Attachment = &attachments[i]
+			}
+		}
+	}
+	bus.Publish(e)
+}
@@ -0,0 +1,527 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"GNote/events"
+	"GNote/models"
+)
+
+// changeKind — вид изменения, сделанного без связи с БД
+type changeKind string
+
+const (
+	changeCreate changeKind = "create"
+	changeUpdate changeKind = "update"
+	changeDelete changeKind = "delete"
+)
+
+// MaxTitleLength — длина колонки notes.title в символах
+const MaxTitleLength = 255
+
+// pendingChange — изменение заметки, которое еще не отправлено в БД
+type pendingChange struct {
+	Kind   changeKind   `json:"kind"`
+	NoteID int          `json:"note_id"`
+	Note   *models.Note `json:"note,omitempty"` // Состояние после изменения (для создания и изменения)
+	Base   time.Time    `json:"base"`           // updated_at заметки в БД, с которой начато изменение
+}
+
+// offlineCache — содержимое файла кэша
+type offlineCache struct {
+	Notes      []models.Note   `json:"notes"`   // Последнее известное состояние заметок с учетом Pending
+	Pending    []pendingChange `json:"pending"` // По порядку внесения
+	NextTempID int             `json:"next_temp_id"`
+}
+
+// OfflineStore — хранилище с локальным кэшем заметок для работы без связи с БД.
+// Пока БД доступна, запросы идут в нее, а кэш обновляется; без связи заметки читаются из кэша,
+// а создание, изменение и удаление копятся в очереди, которую Sync отправляет после восстановления связи.
+// Заметки, созданные без связи, до отправки имеют отрицательные ID.
+type OfflineStore struct {
+	Store
+	path string
+	bus  *events.Bus // Сюда Sync сообщает о заметках, получивших настоящий ID, и о копиях при конфликтах
+
+	mu      sync.Mutex
+	cache   offlineCache
+	offline bool // Последнее обращение к БД не удалось из-за связи: не ждем таймаута на каждом действии
+	syncMu  sync.Mutex
+}
+
+// NewOfflineStore создает хранилище с кэшем в файле path, загружая сохраненный кэш
+func NewOfflineStore(inner Store, path string, bus *events.Bus) (*OfflineStore, error) {
+	s := &OfflineStore{Store: inner, path: path, bus: bus}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать офлайн-кэш: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.cache); err != nil {
+		return nil, fmt.Errorf("офлайн-кэш %s поврежден: %w", path, err)
+	}
+	return s, nil
+}
+
+// HasCache сообщает, что в файле path есть кэш, с которым можно начать работу без БД
+func HasCache(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Pending возвращает количество изменений, ожидающих отправки в БД
+func (s *OfflineStore) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.cache.Pending)
+}
+
+// online сообщает, стоит ли обращаться к БД
+func (s *OfflineStore) online() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.offline
+}
+
+// unavailable запоминает, что связи с БД нет, если err вызвана ее потерей
+func (s *OfflineStore) unavailable(err error) bool {
+	if !IsUnavailable(err) {
+		return false
+	}
+	s.mu.Lock()
+	s.offline = true
+	s.mu.Unlock()
+	return true
+}
+
+// Ping проверяет связь с БД; после успешной проверки запросы снова идут в БД
+func (s *OfflineStore) Ping(ctx context.Context) error {
+	err := s.Store.Ping(ctx)
+	s.mu.Lock()
+	s.offline = err != nil
+	s.mu.Unlock()
+	return err
+}
+
+// GetAllNotes возвращает заметки из БД, дополненные еще не отправленными изменениями, или из кэша без связи
+func (s *OfflineStore) GetAllNotes() ([]models.Note, error) {
+	if s.online() {
+		notes, err := s.Store.GetAllNotes()
+		if err == nil {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.cache.Notes = applyPending(notes, s.cache.Pending)
+			s.saveLocked()
+			return slices.Clone(s.cache.Notes), nil
+		}
+		if !s.unavailable(err) {
+			return nil, err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log.Printf("БД недоступна, заметки загружены из офлайн-кэша (%d)", len(s.cache.Notes))
+	return slices.Clone(s.cache.Notes), nil
+}
+
+// GetNoteByID возвращает заметку из БД или, без связи и для созданных без связи заметок, из кэша
+func (s *OfflineStore) GetNoteByID(id int) (*models.Note, error) {
+	if id > 0 && s.online() {
+		note, err := s.Store.GetNoteByID(id)
+		if err == nil || !s.unavailable(err) {
+			return note, err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.cachedIndex(id); i != -1 {
+		note := s.cache.Notes[i]
+		return &note, nil
+	}
+	return nil, fmt.Errorf("%w: ID %d (в офлайн-кэше)", ErrNoteNotFound, id)
+}
+
+// CreateNote создает заметку в БД, а без связи — в кэше с временным отрицательным ID
+func (s *OfflineStore) CreateNote(note *models.Note) error {
+	if s.online() {
+		err := s.Store.CreateNote(note)
+		if err == nil {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.upsertLocked(*note)
+			s.saveLocked()
+			return nil
+		}
+		if !s.unavailable(err) {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.NextTempID--
+	note.ID = s.cache.NextTempID
+	note.CreatedAt, note.UpdatedAt = time.Now(), time.Now()
+	created := *note
+	s.upsertLocked(created)
+	s.cache.Pending = append(s.cache.Pending, pendingChange{Kind: changeCreate, NoteID: note.ID, Note: &created})
+	s.saveLocked()
+	log.Printf("БД недоступна, заметка «%s» сохранена в офлайн-кэше", note.LogTitle())
+	return nil
+}
+
+// UpdateNote изменяет заметку в БД, а без связи — в кэше, запоминая изменение для отправки
+func (s *OfflineStore) UpdateNote(note *models.Note) error {
+	if note.ContentTruncated {
+		return fmt.Errorf("%w: ID %d, откройте ее заново", ErrContentTruncated, note.ID)
+	}
+	if note.ID > 0 && s.online() {
+		err := s.Store.UpdateNote(note)
+		if err == nil {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.upsertLocked(*note)
+			s.saveLocked()
+			return nil
+		}
+		if !s.unavailable(err) {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	note.UpdatedAt = time.Now() // Как и в БД: редактор по нему узнает изменения из других редакторов
+	s.updateLocked(*note)
+	s.saveLocked()
+	return nil
+}
+
+// ImportNotes импортирует заметки в БД и добавляет их в кэш. Без связи импорт не выполняется:
+// вложения и перезапись заметок нельзя отложить до синхронизации.
+func (s *OfflineStore) ImportNotes(notes []models.Note) (models.ImportReport, error) {
+	if !s.online() {
+		return models.ImportReport{}, errors.New("импорт невозможен без связи с БД")
+	}
+	report, err := s.Store.ImportNotes(notes)
+	if err != nil {
+		s.unavailable(err)
+		return report, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, result := range report.Results {
+		if result.Err == nil {
+			s.upsertLocked(result.Note)
+		}
+	}
+	s.saveLocked()
+	return report, nil
+}
+
+// UpsertNoteByUID создает или перезаписывает заметку в БД и в кэше; без связи заметку не с чем сопоставить
+func (s *OfflineStore) UpsertNoteByUID(note *models.Note) (bool, error) {
+	if !s.online() {
+		return false, errors.New("сопоставление заметки по UID невозможно без связи с БД")
+	}
+	created, err := s.Store.UpsertNoteByUID(note)
+	if err != nil {
+		s.unavailable(err)
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.upsertLocked(*note)
+	s.saveLocked()
+	return created, nil
+}
+
+// SetFavorite отмечает заметку в БД, а без связи — в кэше, как изменение заметки
+func (s *OfflineStore) SetFavorite(id int, favorite bool) error {
+	if id > 0 && s.online() {
+		err := s.Store.SetFavorite(id, favorite)
+		if err == nil {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if i := s.cachedIndex(id); i != -1 {
+				s.cache.Notes[i].Favorite = favorite
+				s.saveLocked()
+			}
+			return nil
+		}
+		if !s.unavailable(err) {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.cachedIndex(id)
+	if i == -1 {
+		return fmt.Errorf("%w: ID %d (в офлайн-кэше)", ErrNoteNotFound, id)
+	}
+	note := s.cache.Notes[i]
+	if note.ContentTruncated { // Отправка такого изменения затерла бы конец заметки
+		return fmt.Errorf("%w: ID %d (в офлайн-кэше)", ErrContentTruncated, id)
+	}
+	note.Favorite = favorite
+	note.UpdatedAt = time.Now()
+	s.updateLocked(note)
+	s.saveLocked()
+	return nil
+}
+
+// DeleteNote удаляет заметку из БД, а без связи — из кэша, запоминая удаление для отправки
+func (s *OfflineStore) DeleteNote(id int) error {
+	if id > 0 && s.online() {
+		err := s.Store.DeleteNote(id)
+		if err == nil {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.removeLocked(id)
+			s.saveLocked()
+			return nil
+		}
+		if !s.unavailable(err) {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.cachedIndex(id)
+	if i == -1 {
+		return fmt.Errorf("%w: ID %d (в офлайн-кэше)", ErrNoteNotFound, id)
+	}
+	base := s.baseLocked(id, s.cache.Notes[i].UpdatedAt)
+	created := slices.ContainsFunc(s.cache.Pending, func(c pendingChange) bool { return c.Kind == changeCreate && c.NoteID == id })
+	// Прежние изменения заметки больше не нужны, а созданную без связи заметку в БД отправлять не надо
+	s.cache.Pending = slices.DeleteFunc(s.cache.Pending, func(c pendingChange) bool { return c.NoteID == id })
+	if !created {
+		s.cache.Pending = append(s.cache.Pending, pendingChange{Kind: changeDelete, NoteID: id, Base: base})
+	}
+	s.removeLocked(id)
+	s.saveLocked()
+	return nil
+}
+
+// updateLocked изменяет заметку в кэше и ставит изменение в очередь. Несколько изменений одной заметки
+// объединяются, сохраняя исходное состояние БД, чтобы при отправке распознать конфликт.
+func (s *OfflineStore) updateLocked(note models.Note) {
+	base := time.Time{}
+	if i := s.cachedIndex(note.ID); i != -1 {
+		base = s.cache.Notes[i].UpdatedAt
+	}
+	s.upsertLocked(note)
+	for i, c := range s.cache.Pending {
+		if c.NoteID == note.ID && (c.Kind == changeCreate || c.Kind == changeUpdate) {
+			s.cache.Pending[i].Note = &note
+			return
+		}
+	}
+	s.cache.Pending = append(s.cache.Pending, pendingChange{Kind: changeUpdate, NoteID: note.ID, Note: &note, Base: base})
+}
+
+// baseLocked возвращает состояние БД, с которого начаты изменения заметки id без связи
+func (s *OfflineStore) baseLocked(id int, fallback time.Time) time.Time {
+	for _, c := range s.cache.Pending {
+		if c.NoteID == id && c.Kind == changeUpdate {
+			return c.Base
+		}
+	}
+	return fallback
+}
+
+// cachedIndex возвращает индекс заметки id в кэше или -1
+func (s *OfflineStore) cachedIndex(id int) int {
+	return slices.IndexFunc(s.cache.Notes, func(n models.Note) bool { return n.ID == id })
+}
+
+// upsertLocked добавляет заметку в кэш или заменяет ее
+func (s *OfflineStore) upsertLocked(note models.Note) {
+	if i := s.cachedIndex(note.ID); i != -1 {
+		s.cache.Notes[i] = note
+		return
+	}
+	s.cache.Notes = append([]models.Note{note}, s.cache.Notes...) // Новые заметки — первыми, как в GetAllNotes
+}
+
+// removeLocked удаляет заметку из кэша
+func (s *OfflineStore) removeLocked(id int) {
+	s.cache.Notes = slices.DeleteFunc(s.cache.Notes, func(n models.Note) bool { return n.ID == id })
+}
+
+// saveLocked записывает кэш в файл через временный файл, чтобы сбой не оставил его недописанным
+func (s *OfflineStore) saveLocked() {
+	data, err := json.Marshal(s.cache)
+	if err != nil {
+		log.Printf("Ошибка при сохранении офлайн-кэша: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		log.Printf("Ошибка при сохранении офлайн-кэша: %v", err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".gnote-cache-*.tmp")
+	if err != nil {
+		log.Printf("Ошибка при сохранении офлайн-кэша: %v", err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		log.Printf("Ошибка при сохранении офлайн-кэша: %v", err)
+	}
+}
+
+// applyPending накладывает еще не отправленные изменения на заметки из БД
+func applyPending(notes []models.Note, pending []pendingChange) []models.Note {
+	for _, c := range pending {
+		i := slices.IndexFunc(notes, func(n models.Note) bool { return n.ID == c.NoteID })
+		switch {
+		case c.Kind == changeDelete && i != -1:
+			notes = slices.Delete(notes, i, i+1)
+		case c.Kind != changeDelete && i != -1:
+			notes[i] = *c.Note
+		case c.Kind != changeDelete:
+			notes = append([]models.Note{*c.Note}, notes...)
+		}
+	}
+	return notes
+}
+
+// Sync отправляет в БД изменения, сделанные без связи; подходит как Job планировщика.
+// Если заметку за это время изменили в БД, ее версия не перезаписывается: изменения без связи
+// сохраняются отдельной заметкой-копией. Удаление измененной в БД заметки не выполняется.
+func (s *OfflineStore) Sync(now time.Time) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	if s.Pending() == 0 || !s.online() {
+		return
+	}
+	sent := 0
+	for {
+		s.mu.Lock()
+		if len(s.cache.Pending) == 0 {
+			s.mu.Unlock()
+			break
+		}
+		change := s.cache.Pending[0]
+		s.mu.Unlock()
+
+		published, err := s.send(change, now)
+		if err != nil && s.unavailable(err) {
+			log.Printf("Синхронизация офлайн-кэша прервана: %v", err)
+			break
+		}
+		if err != nil {
+			log.Printf("Не удалось отправить изменение заметки ID %d (%s), оно пропущено: %v", change.NoteID, change.Kind, err)
+		}
+		s.mu.Lock()
+		s.cache.Pending = slices.DeleteFunc(s.cache.Pending, func(c pendingChange) bool {
+			return c.Kind == change.Kind && c.NoteID == change.NoteID
+		})
+		for _, e := range published {
+			switch e.Kind {
+			case events.NoteDeleted:
+				s.removeLocked(e.NoteID)
+			case events.NoteCreated, events.NoteUpdated:
+				s.upsertLocked(*e.Note)
+			}
+		}
+		s.saveLocked()
+		s.mu.Unlock()
+		for _, e := range published {
+			s.bus.Publish(e)
+		}
+		sent++
+	}
+	if sent > 0 {
+		log.Printf("Синхронизация офлайн-кэша: отправлено изменений %d, осталось %d", sent, s.Pending())
+	}
+}
+
+// send отправляет одно изменение и возвращает события, которые нужно сообщить окнам
+func (s *OfflineStore) send(change pendingChange, now time.Time) ([]events.Event, error) {
+	switch change.Kind {
+	case changeCreate:
+		note := *change.Note
+		if err := s.Store.CreateNote(&note); err != nil {
+			return nil, err
+		}
+		// Заметка получила настоящий ID: окна заменяют временную
+		return []events.Event{
+			{Kind: events.NoteDeleted, NoteID: change.NoteID},
+			{Kind: events.NoteCreated, NoteID: note.ID, Note: &note},
+		}, nil
+	case changeUpdate:
+		remote, err := s.Store.GetNoteByID(change.NoteID)
+		if errors.Is(err, ErrNoteNotFound) {
+			// Изменения не пропадают вместе с удаленной заметкой
+			copied, err := s.sendCopy(change, now, "удалена в БД")
+			if err != nil {
+				return nil, err
+			}
+			return []events.Event{{Kind: events.NoteDeleted, NoteID: change.NoteID}, copied}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if remote.UpdatedAt.After(change.Base) {
+			copied, err := s.sendCopy(change, now, "изменена в БД")
+			if err != nil {
+				return nil, err
+			}
+			return []events.Event{{Kind: events.NoteUpdated, NoteID: remote.ID, Note: remote}, copied}, nil
+		}
+		note := *change.Note
+		if err := s.Store.UpdateNote(&note); err != nil {
+			return nil, err
+		}
+		return []events.Event{{Kind: events.NoteUpdated, NoteID: note.ID, Note: &note}}, nil
+	case changeDelete:
+		remote, err := s.Store.GetNoteByID(change.NoteID)
+		if errors.Is(err, ErrNoteNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if remote.UpdatedAt.After(change.Base) {
+			log.Printf("Заметка ID %d изменена в БД после удаления без связи и оставлена", remote.ID)
+			return []events.Event{{Kind: events.NoteCreated, NoteID: remote.ID, Note: remote}}, nil
+		}
+		return nil, s.Store.DeleteNote(change.NoteID)
+	}
+	return nil, fmt.Errorf("неизвестное изменение %q", change.Kind)
+}
+
+// sendCopy сохраняет изменения без связи новой заметкой, когда исходную за это время изменили или удалили в БД
+func (s *OfflineStore) sendCopy(change pendingChange, now time.Time, reason string) (events.Event, error) {
+	note := *change.Note
+	suffix := fmt.Sprintf(" (конфликт: %s, копия от %s)", reason, now.Format("02.01.2006 15:04"))
+	title := []rune(note.Title)
+	if limit := MaxTitleLength - len([]rune(suffix)); len(title) > limit {
+		title = title[:limit]
+	}
+	note.Title = string(title) + suffix
+	note.UID = "" // Копия — отдельная заметка со своим UID
+	if err := s.Store.CreateNote(&note); err != nil {
+		return events.Event{}, err
+	}
+	log.Printf("Заметка ID %d %s, пока не было связи: изменения сохранены в заметке ID %d", change.NoteID, reason, note.ID)
+	return events.Event{Kind: events.NoteCreated, NoteID: note.ID, Note: &note}, nil
+}
@@ -0,0 +1,355 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"GNote/events"
+	"GNote/models"
+)
+
+// memStore — хранилище в памяти, связь с которым можно «разорвать»
+type memStore struct {
+	Store
+	mu     sync.Mutex
+	notes  map[int]models.Note
+	nextID int
+	down   bool
+}
+
+func newMemStore(notes ...models.Note) *memStore {
+	m := &memStore{notes: make(map[int]models.Note), nextID: 100}
+	for _, note := range notes {
+		m.notes[note.ID] = note
+	}
+	return m
+}
+
+func (m *memStore) Ping(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (m *memStore) GetAllNotes() ([]models.Note, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return nil, driver.ErrBadConn
+	}
+	var notes []models.Note
+	for _, note := range m.notes {
+		notes = append(notes, note)
+	}
+	slices.SortFunc(notes, func(a, b models.Note) int { return b.ID - a.ID })
+	return notes, nil
+}
+
+func (m *memStore) GetNoteByID(id int) (*models.Note, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return nil, driver.ErrBadConn
+	}
+	note, ok := m.notes[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: ID %d", ErrNoteNotFound, id)
+	}
+	return &note, nil
+}
+
+func (m *memStore) CreateNote(note *models.Note) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return driver.ErrBadConn
+	}
+	m.nextID++
+	note.ID = m.nextID
+	m.notes[note.ID] = *note
+	return nil
+}
+
+func (m *memStore) UpdateNote(note *models.Note) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return driver.ErrBadConn
+	}
+	if _, ok := m.notes[note.ID]; !ok {
+		return fmt.Errorf("%w: ID %d", ErrNoteNotFound, note.ID)
+	}
+	m.notes[note.ID] = *note
+	return nil
+}
+
+func (m *memStore) DeleteNote(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return driver.ErrBadConn
+	}
+	delete(m.notes, id)
+	return nil
+}
+
+func (m *memStore) SetFavorite(id int, favorite bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return driver.ErrBadConn
+	}
+	note := m.notes[id]
+	note.Favorite = favorite
+	m.notes[id] = note
+	return nil
+}
+
+// setDown разрывает или восстанавливает связь
+func (m *memStore) setDown(down bool) {
+	m.mu.Lock()
+	m.down = down
+	m.mu.Unlock()
+}
+
+// titles возвращает заголовки заметок в БД по ID
+func (m *memStore) titles() []string {
+	notes, _ := m.GetAllNotes()
+	titles := make([]string, len(notes))
+	for i, note := range notes {
+		titles[len(notes)-1-i] = note.Title
+	}
+	return titles
+}
+
+func TestApplyPending(t *testing.T) {
+	note := func(id int, title string) *models.Note { return &models.Note{ID: id, Title: title} }
+	tests := []struct {
+		name    string
+		pending []pendingChange
+		want    []string
+	}{
+		{"без изменений", nil, []string{"два", "один"}},
+		{"создание — первой", []pendingChange{{Kind: changeCreate, NoteID: -1, Note: note(-1, "новая")}}, []string{"новая", "два", "один"}},
+		{"изменение", []pendingChange{{Kind: changeUpdate, NoteID: 1, Note: note(1, "один*")}}, []string{"два", "один*"}},
+		{"удаление", []pendingChange{{Kind: changeDelete, NoteID: 2}}, []string{"один"}},
+		{"удаление отсутствующей", []pendingChange{{Kind: changeDelete, NoteID: 5}}, []string{"два", "один"}},
+		{"изменение удаленной в БД возвращает ее", []pendingChange{{Kind: changeUpdate, NoteID: 5, Note: note(5, "пять")}}, []string{"пять", "два", "один"}},
+		{"по порядку", []pendingChange{
+			{Kind: changeCreate, NoteID: -1, Note: note(-1, "новая")},
+			{Kind: changeUpdate, NoteID: -1, Note: note(-1, "новая*")},
+			{Kind: changeDelete, NoteID: 1},
+		}, []string{"новая*", "два"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notes := applyPending([]models.Note{*note(2, "два"), *note(1, "один")}, tt.pending)
+			var got []string
+			for _, n := range notes {
+				got = append(got, n.Title)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("applyPending() = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOfflineSync(t *testing.T) {
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 3, 2, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		offline func(t *testing.T, s *OfflineStore) // Действия без связи
+		remote  func(m *memStore)                   // Изменения в БД, пока не было связи
+		want    []string                            // Заголовки в БД после синхронизации по возрастанию ID
+		events  []events.Kind
+	}{
+		{"создание", func(t *testing.T, s *OfflineStore) {
+			if err := s.CreateNote(&models.Note{Title: "новая"}); err != nil {
+				t.Fatal(err)
+			}
+		}, nil, []string{"первая", "вторая", "новая"}, []events.Kind{events.NoteDeleted, events.NoteCreated}},
+		{"создание и удаление не доходят до БД", func(t *testing.T, s *OfflineStore) {
+			note := &models.Note{Title: "новая"}
+			s.CreateNote(note)
+			if err := s.DeleteNote(note.ID); err != nil {
+				t.Fatal(err)
+			}
+		}, nil, []string{"первая", "вторая"}, nil},
+		{"изменение без конфликта", func(t *testing.T, s *OfflineStore) {
+			s.UpdateNote(&models.Note{ID: 1, Title: "первая*", UpdatedAt: base})
+			s.UpdateNote(&models.Note{ID: 1, Title: "первая**", UpdatedAt: base})
+		}, nil, []string{"первая**", "вторая"}, []events.Kind{events.NoteUpdated}},
+		{"изменение заметки, измененной в БД, — копией", func(t *testing.T, s *OfflineStore) {
+			s.UpdateNote(&models.Note{ID: 1, Title: "первая*", UID: "uid-1", UpdatedAt: base})
+		}, func(m *memStore) {
+			m.notes[1] = models.Note{ID: 1, Title: "первая из БД", UID: "uid-1", UpdatedAt: base.Add(time.Hour)}
+		}, []string{"первая из БД", "вторая", "первая* (конфликт: изменена в БД, копия от 02.03.2025 09:30)"},
+			[]events.Kind{events.NoteUpdated, events.NoteCreated}},
+		{"изменение заметки, удаленной в БД, — копией", func(t *testing.T, s *OfflineStore) {
+			s.UpdateNote(&models.Note{ID: 2, Title: "вторая*", UpdatedAt: base})
+		}, func(m *memStore) {
+			delete(m.notes, 2)
+		}, []string{"первая", "вторая* (конфликт: удалена в БД, копия от 02.03.2025 09:30)"},
+			[]events.Kind{events.NoteDeleted, events.NoteCreated}},
+		{"удаление без конфликта", func(t *testing.T, s *OfflineStore) {
+			s.DeleteNote(2)
+		}, nil, []string{"первая"}, nil},
+		{"удаление заметки, измененной в БД, не выполняется", func(t *testing.T, s *OfflineStore) {
+			s.UpdateNote(&models.Note{ID: 2, Title: "вторая*", UpdatedAt: base})
+			s.DeleteNote(2)
+		}, func(m *memStore) {
+			m.notes[2] = models.Note{ID: 2, Title: "вторая из БД", UpdatedAt: base.Add(time.Hour)}
+		}, []string{"первая", "вторая из БД"}, []events.Kind{events.NoteCreated}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMemStore(
+				models.Note{ID: 1, Title: "первая", UpdatedAt: base},
+				models.Note{ID: 2, Title: "вторая", UpdatedAt: base},
+			)
+			bus := events.NewBus()
+			var published []events.Kind
+			bus.Subscribe(func(e events.Event) { published = append(published, e.Kind) })
+			s, err := NewOfflineStore(m, filepath.Join(t.TempDir(), "cache.json"), bus)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.GetAllNotes(); err != nil {
+				t.Fatal(err)
+			}
+
+			m.setDown(true)
+			tt.offline(t, s)
+			if tt.remote != nil {
+				tt.remote(m)
+			}
+			m.setDown(false)
+			if err := s.Ping(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			s.Sync(now)
+
+			if s.Pending() != 0 {
+				t.Errorf("после синхронизации осталось изменений %d", s.Pending())
+			}
+			if got := m.titles(); !slices.Equal(got, tt.want) {
+				t.Errorf("в БД %q, ожидалось %q", got, tt.want)
+			}
+			if !slices.Equal(published, tt.events) {
+				t.Errorf("события %v, ожидались %v", published, tt.events)
+			}
+			notes, _ := s.GetAllNotes()
+			for _, note := range notes {
+				if note.ID < 0 {
+					t.Errorf("в кэше осталась заметка с временным ID %d", note.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestOfflineSyncInterrupted(t *testing.T) {
+	m := newMemStore(models.Note{ID: 1, Title: "первая"})
+	path := filepath.Join(t.TempDir(), "cache.json")
+	s, err := NewOfflineStore(m, path, events.NewBus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.setDown(true)
+	s.CreateNote(&models.Note{Title: "новая"})
+	s.UpdateNote(&models.Note{ID: 1, Title: "первая*"})
+	s.Sync(time.Now()) // Связи нет: ничего не отправляется
+
+	// Изменения переживают перезапуск
+	reopened, err := NewOfflineStore(m, path, events.NewBus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Pending() != 2 {
+		t.Fatalf("после перезапуска изменений %d, ожидалось 2", reopened.Pending())
+	}
+	notes, err := reopened.GetAllNotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, note := range notes {
+		titles = append(titles, fmt.Sprintf("%d %s", note.ID, note.Title))
+	}
+	slices.Sort(titles)
+	if want := []string{"-1 новая", "1 первая*"}; !slices.Equal(titles, want) {
+		t.Errorf("заметки из кэша %q, ожидалось %q", titles, want)
+	}
+}
+
+func TestLoadContent(t *testing.T) {
+	full := strings.Repeat("текст ", 10)
+	tests := []struct {
+		name    string
+		down    bool
+		notes   []models.Note
+		want    string
+		wantErr error
+	}{
+		{"полная заметка не загружается", true, []models.Note{{ID: 1, Content: "коротко"}}, "коротко", nil},
+		{"превью заменяется содержимым", false, []models.Note{{ID: 1, Content: "текст", ContentTruncated: true}}, full, nil},
+		{"заметку удалили", false, []models.Note{{ID: 2, Content: "текст", ContentTruncated: true}}, "", ErrNoteNotFound},
+		{"без связи в кэше только превью", true, []models.Note{{ID: 1, Content: "текст", ContentTruncated: true}}, "", ErrContentTruncated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMemStore(models.Note{ID: 1, Content: full})
+			s, err := NewOfflineStore(m, filepath.Join(t.TempDir(), "cache.json"), events.NewBus())
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.cache.Notes = []models.Note{{ID: 1, Content: "текст", ContentTruncated: true}}
+			m.setDown(tt.down)
+			err = LoadContent(s, tt.notes)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("LoadContent() = %v, ожидалось %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.notes[0].Content != tt.want || tt.notes[0].ContentTruncated {
+				t.Errorf("содержимое %q (превью %v), ожидалось %q", tt.notes[0].Content, tt.notes[0].ContentTruncated, tt.want)
+			}
+		})
+	}
+}
+
+func TestOfflineRefusesTruncated(t *testing.T) {
+	m := newMemStore(models.Note{ID: 1, Content: "полный текст"})
+	s, err := NewOfflineStore(m, filepath.Join(t.TempDir(), "cache.json"), events.NewBus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.cache.Notes = []models.Note{{ID: 1, Content: "полный", ContentTruncated: true}}
+	m.setDown(true)
+	if err := s.UpdateNote(&models.Note{ID: 1, Content: "полный", ContentTruncated: true}); !errors.Is(err, ErrContentTruncated) {
+		t.Errorf("UpdateNote() = %v, ожидалось ErrContentTruncated", err)
+	}
+	if err := s.SetFavorite(1, true); !errors.Is(err, ErrContentTruncated) {
+		t.Errorf("SetFavorite() = %v, ожидалось ErrContentTruncated", err)
+	}
+	if s.Pending() != 0 {
+		t.Errorf("в очереди изменений %d, ожидалось 0", s.Pending())
+	}
+}
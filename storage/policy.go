@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"GNote/models"
+	"GNote/policy"
+)
+
+// PolicyStore оборачивает Store и принимает только вложения, подходящие под правила:
+// тип, расширение, размер и, если задан антивирус, проверка файла перед сохранением
+type PolicyStore struct {
+	Store
+	policy *policy.Policy
+}
+
+// NewPolicyStore создает хранилище, проверяющее вложения по правилам p
+func NewPolicyStore(inner Store, p *policy.Policy) *PolicyStore {
+	return &PolicyStore{Store: inner, policy: p}
+}
+
+// CreateAttachment проверяет уже записанный на диск файл вложения и создает запись о нем.
+// Если вложение отклонено, файл остается на месте: его удаляет вызывающий, как при любой ошибке.
+func (s *PolicyStore) CreateAttachment(attachment *models.Attachment) error {
+	if err := s.policy.Check(attachment.Filename, attachment.MimeType, attachment.SizeBytes); err != nil {
+		return err
+	}
+	if err := s.policy.Scan(context.Background(), attachment.Filepath); err != nil {
+		return err
+	}
+	return s.Store.CreateAttachment(attachment)
+}
+
+// CreateAttachmentBlob проверяет вложение и сохраняет его содержимое в БД.
+// Для проверки антивирусом содержимое сначала записывается во временный файл.
+func (s *PolicyStore) CreateAttachmentBlob(attachment *models.Attachment, r io.Reader) error {
+	if err := s.policy.Check(attachment.Filename, attachment.MimeType, -1); err != nil {
+		return err
+	}
+	if s.policy.MaxSize > 0 {
+		r = &sizeLimitReader{r: r, policy: s.policy}
+	}
+	if !s.policy.Scans() {
+		return s.Store.CreateAttachmentBlob(attachment, r)
+	}
+
+	tmp, err := os.CreateTemp("", "gnote-scan-*")
+	if err != nil {
+		return fmt.Errorf("не удалось создать временный файл для проверки вложения: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if _, err := io.Copy(tmp, r); err != nil {
+		return err
+	}
+	if err := s.policy.Scan(context.Background(), tmp.Name()); err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return s.Store.CreateAttachmentBlob(attachment, tmp)
+}
+
+// ImportNotes импортирует заметки, не пропуская вложения, не подходящие под правила
+func (s *PolicyStore) ImportNotes(notes []models.Note) (models.ImportReport, error) {
+	rejected := make([][]models.AttachmentError, len(notes))
+	allowed := make([]models.Note, len(notes))
+	for i, note := range notes {
+		note.Attachments = nil
+		for _, attach := range notes[i].Attachments {
+			err := s.policy.Check(attach.Filename, attach.MimeType, attach.SizeBytes)
+			if err == nil {
+				err = s.policy.Scan(context.Background(), attach.Filepath)
+			}
+			if err != nil {
+				rejected[i] = append(rejected[i], models.AttachmentError{Filename: attach.Filename, Reason: err.Error()})
+				continue
+			}
+			note.Attachments = append(note.Attachments, attach)
+		}
+		allowed[i] = note
+	}
+	report, err := s.Store.ImportNotes(allowed)
+	for i := range report.Results {
+		report.Results[i].FailedAttachments = append(rejected[i], report.Results[i].FailedAttachments...)
+	}
+	return report, err
+}
+
+// sizeLimitReader прерывает чтение ошибкой, как только прочитано больше разрешенного размера
+type sizeLimitReader struct {
+	r      io.Reader
+	read   int64
+	policy *policy.Policy
+}
+
+// Read читает очередную порцию и проверяет общий размер
+func (l *sizeLimitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if sizeErr := l.policy.CheckSize(l.read); sizeErr != nil {
+		return n, sizeErr
+	}
+	return n, err
+}
@@ -1,11 +1,16 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"log"
-	"time"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/lib/pq" 
 	"GNote/models" 
@@ -13,12 +18,23 @@ import (
 
 // Config содержит конфигурацию для подключения к БД
 type Config struct {
+	URL      string // Строка подключения postgres://...; если задана, остальные поля не используются
 	Host     string
 	Port     int
 	User     string
 	Password string
 	DBName   string
 	SSLMode  string
+	// Файлы TLS: сертификат центра сертификации для проверки сервера (sslmode verify-ca и verify-full),
+	// сертификат и закрытый ключ клиента. Дополняют и строку URL
+	SSLRootCert    string
+	SSLCert        string
+	SSLKey         string
+	ChannelBinding string // disable, prefer или require; пусто — как в URL
+	// Строка подключения к реплике только для чтения: на нее идут список заметок, поиск и статистика.
+	// Пусто — все запросы в основную БД
+	ReplicaURL string
+	Limits     Limits // Ограничения размера заметки; нулевые значения — по умолчанию
 }
 
 // Store представляет собой интерфейс для взаимодействия с заметками
@@ -28,84 +44,249 @@ type Store interface {
 	GetAllNotes() ([]models.Note, error)
 	UpdateNote(note *models.Note) error
 	DeleteNote(id int) error
+	SetFavorite(id int, favorite bool) error
+	MergeNotes(targetID, sourceID int) error
+	ImportNotes(notes []models.Note) (models.ImportReport, error)
+	UpsertNoteByUID(note *models.Note) (created bool, err error)
+	MoveAttachments(fromNoteID, toNoteID int) error
+	CopyAttachment(id, newNoteID int) (*models.Attachment, error)
+	SearchTags(prefix string) ([]string, error)
+	RenameTag(oldName, newName string) ([]int, error)
+	ReplaceInNotes(description string, edits []models.TextEdit) (int, error)
+	GetReplaceSnapshots() ([]models.ReplaceSnapshot, error)
+	UndoReplace(snapshotID int) ([]int, error)
+	GetTagStyles() (map[string]models.TagStyle, error)
+	SetTagStyle(name string, style models.TagStyle) error
+	GetRecurringRules() ([]models.RecurringRule, error)
+	SaveRecurringRule(rule *models.RecurringRule) error
+	DeleteRecurringRule(id int) error
+	ClaimRecurringRun(rule *models.RecurringRule, runAt time.Time) (bool, error)
+	LogActivity(activity *models.Activity) error
+	GetActivity(limit int) ([]models.Activity, error)
+	GetSnippets() ([]models.Snippet, error)
+	SaveSnippet(snippet *models.Snippet) error
+	DeleteSnippet(id int) error
+	GetDueReminders(from, to time.Time) ([]models.Note, error)
+	GetStatistics(from time.Time) (*models.Statistics, error)
+	CountNotes(filter models.NoteFilter) (int, error)
+	CountByTag() (map[string]int, error)
+	StorageUsage() (*models.StorageUsage, error)
 	CreateAttachment(attachment *models.Attachment) error
 	GetAttachmentsByNoteID(noteID int) ([]models.Attachment, error)
+	GetNotesWithAttachments(ids []int) ([]models.Note, error)
 	DeleteAttachment(attachmentID int) error
+	CreateAttachmentBlob(attachment *models.Attachment, r io.Reader) error
+	OpenAttachmentBlob(attachmentID int) (io.ReadCloser, error)
+	GetAttachmentsWithoutText(mimePrefix string, limit int) ([]models.Attachment, error)
+	SetAttachmentText(attachment *models.Attachment, text string) error
+	UpdateAttachment(attachment *models.Attachment) error
+	SetAttachmentOrder(noteID int, attachmentIDs []int) error
+	NoteLimits() Limits
+	Ping(ctx context.Context) error
 }
 
 // PostgresStore реализует Store для PostgreSQL
 type PostgresStore struct {
-	db *sql.DB
+	db       *sql.DB
+	connStr  string // Строка подключения с application_name экземпляра, для LISTEN
+	instance string // application_name подключений; по нему уведомления о своих изменениях пропускаются
+	limits   Limits
+	replica  *replica // Реплика для тяжелых чтений; nil — не задана
+
+	stmtMu sync.Mutex
+	stmts  map[string]*sql.Stmt // Кэш подготовленных запросов по тексту запроса
 }
 
-// NewPostgresStore создает новый экземпляр PostgresStore
-func NewPostgresStore(cfg Config) (*PostgresStore, error) {
-	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode)
+// ErrNoteNotFound — заметки с запрошенным ID нет в БД
+var ErrNoteNotFound = errors.New("заметка не найдена")
 
-	db, err := sql.Open("postgres", connStr)
+// ErrContentTruncated — заметка загружена с превью вместо содержимого, и ее сохранение потеряло бы конец
+var ErrContentTruncated = errors.New("заметка загружена не полностью")
+
+// NewPostgresStore создает новый экземпляр PostgresStore и проверяет соединение и схему БД.
+// Если в БД не хватает таблиц или столбцов, возвращает *SchemaError.
+func NewPostgresStore(cfg Config) (*PostgresStore, error) {
+	s, err := OpenPostgresStore(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при открытии соединения с БД: %w", err)
+		return nil, err
 	}
 
 	// Проверяем соединение
-	if err = db.Ping(); err != nil {
+	if err = s.db.Ping(); err != nil {
+		s.db.Close()
 		return nil, fmt.Errorf("ошибка при подключении к БД: %w", err)
 	}
 
 	log.Println("Успешное подключение к PostgreSQL!")
-	return &PostgresStore{db: db}, nil
+	// Устаревшая схема обнаруживается сразу, а не первым запросом к отсутствующему столбцу
+	if err = s.checkSchema(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	s.checkReplica()
+	return s, nil
+}
+
+// OpenPostgresStore создает PostgresStore, не проверяя соединение: с ним можно начать работу
+// с офлайн-кэшем, пока БД недоступна, а подключение произойдет при первом успешном запросе
+func OpenPostgresStore(cfg Config) (*PostgresStore, error) {
+	connStr, err := cfg.connString()
+	if err != nil {
+		return nil, err
+	}
+
+	instance := newInstanceName()
+	connStr, err = withApplicationName(connStr, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии соединения с БД: %w", err)
+	}
+	r, err := openReplica(cfg, instance)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &PostgresStore{db: db, connStr: connStr, instance: instance, limits: cfg.Limits.withDefaults(), replica: r, stmts: make(map[string]*sql.Stmt)}, nil
+}
+
+// Запросы, выполняемые при каждом сохранении заметки; готовятся один раз и кэшируются
+const (
+	// UID заметки сохраняется, если он задан (импорт, синхронизация), иначе создается новый
+	insertNoteQuery = `INSERT INTO notes (title, content, reminder_at, source_url, location_name, latitude, longitude, is_favorite, priority, due_at, uid) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE(NULLIF($11, '')::uuid, gen_random_uuid())) RETURNING id, created_at, updated_at, uid`
+	updateNoteQuery = `UPDATE notes SET title = $1, content = $2, reminder_at = $3, updated_at = $4, source_url = $5, location_name = $6, latitude = $7, longitude = $8, is_favorite = $9, priority = $10, due_at = $11 WHERE id = $12`
+	clearTagsQuery  = `DELETE FROM note_tags WHERE note_id = $1`
+	// Все теги заметки создаются и привязываются одним запросом
+	setTagsQuery = `
+		WITH note_tag_ids AS (
+			INSERT INTO tags (name) SELECT DISTINCT unnest($2::text[])
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		)
+		INSERT INTO note_tags (note_id, tag_id) SELECT $1, id FROM note_tag_ids
+		ON CONFLICT DO NOTHING`
+	noteTagsQuery = `SELECT t.name FROM tags t JOIN note_tags nt ON t.id = nt.tag_id WHERE nt.note_id = $1`
+)
+
+// prepared возвращает подготовленный запрос из кэша, подготавливая его при первом обращении
+func (s *PostgresStore) prepared(query string) (*sql.Stmt, error) {
+	s.stmtMu.Lock()
+	defer s.stmtMu.Unlock()
+
+	if stmt, ok := s.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := s.db.Prepare(query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подготовке запроса: %w", err)
+	}
+	s.stmts[query] = stmt
+	return stmt, nil
+}
+
+// txStmt возвращает подготовленный запрос, привязанный к транзакции
+func (s *PostgresStore) txStmt(tx *sql.Tx, query string) (*sql.Stmt, error) {
+	stmt, err := s.prepared(query)
+	if err != nil {
+		return nil, err
+	}
+	return tx.Stmt(stmt), nil
+}
+
+// setNoteTags создает недостающие теги и привязывает их к заметке одним запросом
+func (s *PostgresStore) setNoteTags(tx *sql.Tx, noteID int, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	stmt, err := s.txStmt(tx, setTagsQuery)
+	if err != nil {
+		return err
+	}
+	if _, err := stmt.Exec(noteID, pq.Array(tags)); err != nil {
+		return fmt.Errorf("ошибка при привязке тегов к заметке: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает подготовленные запросы и соединение с БД
+func (s *PostgresStore) Close() error {
+	s.stmtMu.Lock()
+	for query, stmt := range s.stmts {
+		stmt.Close()
+		delete(s.stmts, query)
+	}
+	s.stmtMu.Unlock()
+	if s.replica != nil {
+		s.replica.db.Close()
+	}
+	return s.db.Close()
+}
+
+// Ping проверяет, что БД отвечает; разорванные соединения пул открывает заново
+func (s *PostgresStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
 }
 
 // CreateNote создает новую заметку в БД, включая теги и напоминания
 func (s *PostgresStore) CreateNote(note *models.Note) error {
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return fmt.Errorf("не удалось начать транзакцию: %w", err)
 	}
 	defer tx.Rollback() // Откат в случае ошибки
 
+	if err := s.createNoteTx(tx, note); err != nil {
+		return err
+	}
+	return s.commit(tx) // Подтверждаем транзакцию
+}
+
+// createNoteTx создает заметку с тегами и записью в журнале в транзакции tx
+func (s *PostgresStore) createNoteTx(tx *sql.Tx, note *models.Note) error {
+	if !note.Priority.Valid() {
+		return fmt.Errorf("недопустимый приоритет заметки: %d", note.Priority)
+	}
+	if err := s.limits.CheckNote(note); err != nil {
+		return err
+	}
+
 	// Вставляем заметку
-	query := `INSERT INTO notes (title, content, reminder_at) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`
+	insertStmt, err := s.txStmt(tx, insertNoteQuery)
+	if err != nil {
+		return err
+	}
 	var reminderAtSQL sql.NullTime
 	if note.ReminderAt != nil {
 		reminderAtSQL = sql.NullTime{Time: *note.ReminderAt, Valid: true}
 	}
-	err = tx.QueryRow(query, note.Title, note.Content, reminderAtSQL).Scan(&note.ID, &note.CreatedAt, &note.UpdatedAt)
+	locName, lat, lon := locationArgs(note.Location)
+	err = insertStmt.QueryRow(note.Title, note.Content, reminderAtSQL, note.SourceURL, locName, lat, lon, note.Favorite, note.Priority, nullTime(note.DueAt), note.UID).Scan(&note.ID, &note.CreatedAt, &note.UpdatedAt, &note.UID)
 	if err != nil {
 		return fmt.Errorf("ошибка при создании заметки: %w", err)
 	}
 
-	// Обрабатываем теги
-	if len(note.Tags) > 0 {
-		for _, tagName := range note.Tags {
-			var tagID int
-			// Ищем существующий тег или создаем новый
-			err := tx.QueryRow(`INSERT INTO tags (name) VALUES ($1) ON CONFLICT (name) DO UPDATE SET name=EXCLUDED.name RETURNING id`, tagName).Scan(&tagID)
-			if err != nil {
-				return fmt.Errorf("ошибка при создании/получении тега: %w", err)
-			}
-			// Привязываем тег к заметке
-			_, err = tx.Exec(`INSERT INTO note_tags (note_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, note.ID, tagID)
-			if err != nil {
-				return fmt.Errorf("ошибка при привязке тега к заметке: %w", err)
-			}
-		}
+	// Создаем и привязываем теги одним запросом
+	if err := s.setNoteTags(tx, note.ID, note.Tags); err != nil {
+		return err
 	}
-
-	return tx.Commit() // Подтверждаем транзакцию
+	return s.logActivity(tx, models.ActivityCreated, note.ID, note.Title, "")
 }
 
 // GetNoteByID получает заметку по ID, включая теги и вложения
 func (s *PostgresStore) GetNoteByID(id int) (*models.Note, error) {
 	var note models.Note
-	var reminderAtSQL sql.NullTime
+	var reminderAtSQL, dueAtSQL sql.NullTime
+	var locName string
+	var lat, lon sql.NullFloat64
 
-	query := `SELECT id, title, content, created_at, updated_at, reminder_at FROM notes WHERE id = $1`
-	err := s.db.QueryRow(query, id).Scan(&note.ID, &note.Title, &note.Content, &note.CreatedAt, &note.UpdatedAt, &reminderAtSQL)
+	query := `SELECT id, title, content, created_at, updated_at, reminder_at, source_url, location_name, latitude, longitude, is_favorite, priority, due_at, uid FROM notes WHERE id = $1`
+	err := s.db.QueryRow(query, id).Scan(&note.ID, &note.Title, &note.Content, &note.CreatedAt, &note.UpdatedAt, &reminderAtSQL, &note.SourceURL, &locName, &lat, &lon, &note.Favorite, &note.Priority, &dueAtSQL, &note.UID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("заметка с ID %d не найдена", id)
+			return nil, fmt.Errorf("%w: ID %d", ErrNoteNotFound, id)
 		}
 		return nil, fmt.Errorf("ошибка при получении заметки по ID: %w", err)
 	}
@@ -113,9 +294,17 @@ func (s *PostgresStore) GetNoteByID(id int) (*models.Note, error) {
 	if reminderAtSQL.Valid {
 		note.ReminderAt = &reminderAtSQL.Time
 	}
+	if dueAtSQL.Valid {
+		note.DueAt = &dueAtSQL.Time
+	}
+	note.Location = locationFromSQL(locName, lat, lon)
 
 	// Получаем теги для заметки
-	rows, err := s.db.Query(`SELECT t.name FROM tags t JOIN note_tags nt ON t.id = nt.tag_id WHERE nt.note_id = $1`, note.ID)
+	tagsStmt, err := s.prepared(noteTagsQuery)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tagsStmt.Query(note.ID)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении тегов заметки: %w", err)
 	}
@@ -137,23 +326,40 @@ func (s *PostgresStore) GetNoteByID(id int) (*models.Note, error) {
 		return nil, fmt.Errorf("ошибка при получении вложений заметки: %w", err)
 	}
 	note.Attachments = attachments
+	note.AttachmentText = attachmentText(attachments)
+	note.AttachmentNames, note.AttachmentTypes = models.AttachmentMeta(attachments)
 
 	return &note, nil
 }
 
-// GetAllNotes получает все заметки, включая теги (вложения не загружаем для списка, чтобы не перегружать)
+// GetAllNotes получает все заметки, включая теги (вложения не загружаем для списка, чтобы не перегружать).
+// У больших заметок загружается только превью — первые NotePreviewLength символов с отметкой
+// ContentTruncated; полностью заметку загружает GetNoteByID при открытии, а списки — LoadContent.
 func (s *PostgresStore) GetAllNotes() ([]models.Note, error) {
 	query := `
 		SELECT
-			n.id, n.title, n.content, n.created_at, n.updated_at, n.reminder_at,
-			COALESCE(ARRAY_AGG(t.name ORDER BY t.name) FILTER (WHERE t.name IS NOT NULL), '{}') AS tags
+			n.id, n.title, LEFT(n.content, $1), length(n.content) > $1, n.created_at, n.updated_at, n.reminder_at, n.source_url,
+			n.location_name, n.latitude, n.longitude, n.is_favorite, n.priority, n.due_at, n.uid,
+			COALESCE(ARRAY_AGG(t.name ORDER BY t.name) FILTER (WHERE t.name IS NOT NULL), '{}') AS tags,
+			COALESCE(att.text, '') AS attachment_text,
+			COALESCE(att.names, '{}') AS attachment_names,
+			COALESCE(att.types, '{}') AS attachment_types
 		FROM notes n
 		LEFT JOIN note_tags nt ON n.id = nt.note_id
 		LEFT JOIN tags t ON nt.tag_id = t.id
-		GROUP BY n.id, n.title, n.content, n.created_at, n.updated_at, n.reminder_at
+		-- Текст, имена и типы вложений для поиска собираются одним проходом по вложениям заметки
+		LEFT JOIN LATERAL (
+			SELECT string_agg(concat_ws(E'\n', NULLIF(a.description, ''), a.attachment_text), E'\n') AS text,
+				array_agg(a.filename ORDER BY a.position, a.id) AS names,
+				array_agg(COALESCE(a.mimetype, '') ORDER BY a.position, a.id) AS types
+			FROM attachments a WHERE a.note_id = n.id
+		) att ON TRUE
+		GROUP BY n.id, n.title, n.created_at, n.updated_at, n.reminder_at, n.source_url,
+			n.location_name, n.latitude, n.longitude, n.is_favorite, n.priority, n.due_at, n.uid,
+			att.text, att.names, att.types
 		ORDER BY n.created_at DESC`
 
-	rows, err := s.db.Query(query)
+	rows, err := s.readQuery(query, NotePreviewLength)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении всех заметок: %w", err)
 	}
@@ -163,20 +369,29 @@ func (s *PostgresStore) GetAllNotes() ([]models.Note, error) {
 	for rows.Next() {
 		var note models.Note
 		var tagsArray pq.StringArray // <--- ИЗМЕНЕНИЕ ЗДЕСЬ: используем pq.StringArray
-		var reminderAtSQL sql.NullTime
+		var attachmentNames, attachmentTypes pq.StringArray
+		var reminderAtSQL, dueAtSQL sql.NullTime
+		var locName string
+		var lat, lon sql.NullFloat64
 
-		if err := rows.Scan(&note.ID, &note.Title, &note.Content, &note.CreatedAt, &note.UpdatedAt, &reminderAtSQL, &tagsArray); err != nil {
+		if err := rows.Scan(&note.ID, &note.Title, &note.Content, &note.ContentTruncated, &note.CreatedAt, &note.UpdatedAt, &reminderAtSQL, &note.SourceURL, &locName, &lat, &lon, &note.Favorite, &note.Priority, &dueAtSQL, &note.UID, &tagsArray, &note.AttachmentText, &attachmentNames, &attachmentTypes); err != nil {
 			return nil, fmt.Errorf("ошибка при сканировании заметки: %w", err)
 		}
 
 		if reminderAtSQL.Valid {
 			note.ReminderAt = &reminderAtSQL.Time
 		}
+		if dueAtSQL.Valid {
+			note.DueAt = &dueAtSQL.Time
+		}
+		note.Location = locationFromSQL(locName, lat, lon)
 
 		// Преобразуем pq.StringArray в []string
 		note.Tags = []string(tagsArray) // <--- ИЗМЕНЕНИЕ ЗДЕСЬ: прямое преобразование
-		// Вложения не загружаем здесь, только при выборе конкретной заметки
+		// Вложения не загружаем здесь, только при выборе конкретной заметки; для поиска хватает имен и типов
 		note.Attachments = []models.Attachment{}
+		note.AttachmentNames = []string(attachmentNames)
+		note.AttachmentTypes = []string(attachmentTypes)
 		notes = append(notes, note)
 	}
 
@@ -189,22 +404,50 @@ func (s *PostgresStore) GetAllNotes() ([]models.Note, error) {
 
 // UpdateNote обновляет существующую заметку, включая теги и напоминания
 func (s *PostgresStore) UpdateNote(note *models.Note) error {
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return fmt.Errorf("не удалось начать транзакцию: %w", err)
 	}
 	defer tx.Rollback()
 
+	if err := s.updateNoteTx(tx, note); err != nil {
+		return err
+	}
+	return s.commit(tx)
+}
+
+// updateNoteTx обновляет заметку с тегами и записью в журнале в транзакции tx
+func (s *PostgresStore) updateNoteTx(tx *sql.Tx, note *models.Note) error {
+	if note.ContentTruncated {
+		return fmt.Errorf("%w: ID %d, откройте ее заново", ErrContentTruncated, note.ID)
+	}
+	if !note.Priority.Valid() {
+		return fmt.Errorf("недопустимый приоритет заметки: %d", note.Priority)
+	}
+	if err := s.limits.CheckNote(note); err != nil {
+		return err
+	}
+
+	// Прежнее состояние нужно журналу изменений, чтобы записать, какие поля изменились
+	before, err := s.noteBeforeUpdate(tx, note.ID)
+	if err != nil {
+		return err
+	}
+
 	// Устанавливаем updated_at в Go, чтобы явно использовать пакет time
 	note.UpdatedAt = time.Now()
 
 	// Обновляем заметку
-	query := `UPDATE notes SET title = $1, content = $2, reminder_at = $3, updated_at = $4 WHERE id = $5`
+	updateStmt, err := s.txStmt(tx, updateNoteQuery)
+	if err != nil {
+		return err
+	}
 	var reminderAtSQL sql.NullTime
 	if note.ReminderAt != nil {
 		reminderAtSQL = sql.NullTime{Time: *note.ReminderAt, Valid: true}
 	}
-	res, err := tx.Exec(query, note.Title, note.Content, reminderAtSQL, note.UpdatedAt, note.ID)
+	locName, lat, lon := locationArgs(note.Location)
+	res, err := updateStmt.Exec(note.Title, note.Content, reminderAtSQL, note.UpdatedAt, note.SourceURL, locName, lat, lon, note.Favorite, note.Priority, nullTime(note.DueAt), note.ID)
 	if err != nil {
 		return fmt.Errorf("ошибка при обновлении заметки: %w", err)
 	}
@@ -217,32 +460,29 @@ func (s *PostgresStore) UpdateNote(note *models.Note) error {
 	}
 
 	// Удаляем старые привязки тегов для этой заметки
-	_, err = tx.Exec(`DELETE FROM note_tags WHERE note_id = $1`, note.ID)
+	clearStmt, err := s.txStmt(tx, clearTagsQuery)
 	if err != nil {
+		return err
+	}
+	if _, err = clearStmt.Exec(note.ID); err != nil {
 		return fmt.Errorf("ошибка при удалении старых тегов: %w", err)
 	}
 
-	// Добавляем новые привязки тегов
-	if len(note.Tags) > 0 {
-		for _, tagName := range note.Tags {
-			var tagID int
-			err := tx.QueryRow(`INSERT INTO tags (name) VALUES ($1) ON CONFLICT (name) DO UPDATE SET name=EXCLUDED.name RETURNING id`, tagName).Scan(&tagID)
-			if err != nil {
-				return fmt.Errorf("ошибка при создании/получении тега: %w", err)
-			}
-			_, err = tx.Exec(`INSERT INTO note_tags (note_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, note.ID, tagID)
-			if err != nil {
-				return fmt.Errorf("ошибка при привязке тега к заметке: %w", err)
-			}
+	// Добавляем новые привязки тегов одним запросом
+	if err := s.setNoteTags(tx, note.ID, note.Tags); err != nil {
+		return err
+	}
+	if changed := models.ChangedFields(before, note); len(changed) > 0 {
+		if err := s.logActivity(tx, models.ActivityUpdated, note.ID, note.Title, strings.Join(changed, ", ")); err != nil {
+			return err
 		}
 	}
-
-	return tx.Commit()
+	return nil
 }
 
 // DeleteNote удаляет заметку по ID
 func (s *PostgresStore) DeleteNote(id int) error {
-	tx, err := s.db.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return fmt.Errorf("не удалось начать транзакцию: %w", err)
 	}
@@ -261,9 +501,19 @@ func (s *PostgresStore) DeleteNote(id int) error {
 		return fmt.Errorf("ошибка при удалении привязок тегов: %w", err)
 	}
 
+	// Содержимое вложений, хранящееся в БД, каскадно не удаляется
+	_, err = tx.Exec(`SELECT lo_unlink(data_oid) FROM attachments WHERE note_id = $1 AND data_oid IS NOT NULL`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении содержимого вложений: %w", err)
+	}
+
 	// Удаляем вложения из таблицы attachments (благодаря ON DELETE CASCADE это сделает сама БД)
 	// Но если бы не было CASCADE, здесь был бы DELETE FROM attachments WHERE note_id = $1
 
+	if err := s.logNoteActivity(tx, models.ActivityDeleted, id, ""); err != nil {
+		return err
+	}
+
 	// Удаляем заметку
 	res, err := tx.Exec(`DELETE FROM notes WHERE id = $1`, id)
 	if err != nil {
@@ -281,6 +531,9 @@ func (s *PostgresStore) DeleteNote(id int) error {
 
 	// Если заметка успешно удалена из БД, удаляем физические файлы вложений
 	for _, attach := range attachments {
+		if attach.InDatabase {
+			continue
+		}
 		if err := os.Remove(attach.Filepath); err != nil {
 			log.Printf("Ошибка при удалении файла вложения '%s': %v", attach.Filepath, err)
 		} else {
@@ -288,22 +541,142 @@ func (s *PostgresStore) DeleteNote(id int) error {
 		}
 	}
 
-	return tx.Commit()
+	return s.commit(tx)
+}
+
+// SetFavorite отмечает заметку звездочкой или снимает отметку, не изменяя остальные поля
+func (s *PostgresStore) SetFavorite(id int, favorite bool) error {
+	res, err := s.exec(`UPDATE notes SET is_favorite = $1 WHERE id = $2`, favorite, id)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении отметки избранного: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества затронутых строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("заметка с ID %d не найдена", id)
+	}
+	return nil
+}
+
+// MergeNotes переносит в заметку targetID содержимое, теги и вложения заметки sourceID и удаляет ее.
+// Содержимое дописывается через разделитель, если оно отличается; отметка избранного объединяется,
+// приоритет берется наибольший.
+func (s *PostgresStore) MergeNotes(targetID, sourceID int) error {
+	if targetID == sourceID {
+		return fmt.Errorf("нельзя объединить заметку с самой собой")
+	}
+	tx, err := s.begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE notes t SET
+			content = CASE WHEN t.content = src.content THEN t.content
+				ELSE t.content || E'\n\n---\n\n' || src.content END,
+			is_favorite = t.is_favorite OR src.is_favorite,
+			priority = GREATEST(t.priority, src.priority),
+			reminder_at = COALESCE(t.reminder_at, src.reminder_at),
+			due_at = COALESCE(t.due_at, src.due_at),
+			updated_at = NOW()
+		FROM notes src
+		WHERE t.id = $1 AND src.id = $2`, targetID, sourceID)
+	if err != nil {
+		return fmt.Errorf("ошибка при объединении содержимого заметок: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества затронутых строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("заметки с ID %d и %d не найдены", targetID, sourceID)
+	}
+	if _, err := tx.Exec(`INSERT INTO note_tags (note_id, tag_id) SELECT $1, tag_id FROM note_tags WHERE note_id = $2 ON CONFLICT DO NOTHING`, targetID, sourceID); err != nil {
+		return fmt.Errorf("ошибка при переносе тегов: %w", err)
+	}
+	if _, err := tx.Exec(moveAttachmentsQuery, targetID, sourceID); err != nil {
+		return fmt.Errorf("ошибка при переносе вложений: %w", err)
+	}
+	if err := s.logNoteActivity(tx, models.ActivityUpdated, targetID, fmt.Sprintf("объединена с заметкой #%d", sourceID)); err != nil {
+		return err
+	}
+	if err := s.logNoteActivity(tx, models.ActivityDeleted, sourceID, fmt.Sprintf("объединена с заметкой #%d", targetID)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM notes WHERE id = $1`, sourceID); err != nil {
+		return fmt.Errorf("ошибка при удалении объединенной заметки: %w", err)
+	}
+	return s.commit(tx)
+}
+
+// GetDueReminders возвращает заметки, напоминания которых наступили в интервале (from, to]
+func (s *PostgresStore) GetDueReminders(from, to time.Time) ([]models.Note, error) {
+	query := `SELECT id, title, content, created_at, updated_at, reminder_at FROM notes WHERE reminder_at > $1 AND reminder_at <= $2 ORDER BY reminder_at ASC`
+	rows, err := s.db.Query(query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении напоминаний: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []models.Note
+	for rows.Next() {
+		var note models.Note
+		var reminderAtSQL sql.NullTime
+		if err := rows.Scan(&note.ID, &note.Title, &note.Content, &note.CreatedAt, &note.UpdatedAt, &reminderAtSQL); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании напоминания: %w", err)
+		}
+		if reminderAtSQL.Valid {
+			note.ReminderAt = &reminderAtSQL.Time
+		}
+		notes = append(notes, note)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по строкам напоминаний: %w", err)
+	}
+	return notes, nil
 }
 
 // CreateAttachment создает запись о вложении в БД
 func (s *PostgresStore) CreateAttachment(attachment *models.Attachment) error {
-	query := `INSERT INTO attachments (note_id, filename, filepath, mimetype, size_bytes) VALUES ($1, $2, $3, $4, $5) RETURNING id, uploaded_at`
-	err := s.db.QueryRow(query, attachment.NoteID, attachment.Filename, attachment.Filepath, attachment.MimeType, attachment.SizeBytes).Scan(&attachment.ID, &attachment.UploadedAt)
+	s.wrote()
+	return createAttachment(s.db, attachment)
+}
+
+// queryRower — *sql.DB или *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// createAttachment создает запись о вложении через db: соединение или транзакцию
+func createAttachment(db queryRower, attachment *models.Attachment) error {
+	query := `INSERT INTO attachments (note_id, filename, filepath, mimetype, size_bytes, description, source_url, position) VALUES ($1, $2, $3, $4, $5, $6, $7, ` + nextAttachmentPosition + `) RETURNING id, uploaded_at, position`
+	err := db.QueryRow(query, attachment.NoteID, attachment.Filename, attachment.Filepath, attachment.MimeType, attachment.SizeBytes, attachment.Description, attachment.SourceURL).Scan(&attachment.ID, &attachment.UploadedAt, &attachment.Position)
 	if err != nil {
 		return fmt.Errorf("ошибка при создании вложения: %w", err)
 	}
 	return nil
 }
 
+// UpdateAttachment сохраняет отображаемое имя и описание вложения; файл на диске не переименовывается
+func (s *PostgresStore) UpdateAttachment(attachment *models.Attachment) error {
+	res, err := s.exec(`UPDATE attachments SET filename = $1, description = $2 WHERE id = $3`,
+		attachment.Filename, attachment.Description, attachment.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении вложения: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("вложение с ID %d не найдено", attachment.ID)
+	}
+	return nil
+}
+
 // GetAttachmentsByNoteID получает все вложения для указанной заметки
 func (s *PostgresStore) GetAttachmentsByNoteID(noteID int) ([]models.Attachment, error) {
-	query := `SELECT id, note_id, filename, filepath, mimetype, size_bytes, uploaded_at FROM attachments WHERE note_id = $1 ORDER BY uploaded_at ASC`
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE note_id = $1 ORDER BY position, uploaded_at, id`
 	rows, err := s.db.Query(query, noteID)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении вложений для заметки %d: %w", noteID, err)
@@ -312,9 +685,9 @@ func (s *PostgresStore) GetAttachmentsByNoteID(noteID int) ([]models.Attachment,
 
 	var attachments []models.Attachment
 	for rows.Next() {
-		var attach models.Attachment
-		if err := rows.Scan(&attach.ID, &attach.NoteID, &attach.Filename, &attach.Filepath, &attach.MimeType, &attach.SizeBytes, &attach.UploadedAt); err != nil {
-			return nil, fmt.Errorf("ошибка при сканировании вложения: %w", err)
+		attach, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
 		}
 		attachments = append(attachments, attach)
 	}
@@ -325,12 +698,19 @@ func (s *PostgresStore) GetAttachmentsByNoteID(noteID int) ([]models.Attachment,
 	return attachments, nil
 }
 
-// DeleteAttachment удаляет запись о вложении из БД и сам файл с диска
+// DeleteAttachment удаляет запись о вложении из БД и само содержимое: файл с диска или large object
 func (s *PostgresStore) DeleteAttachment(attachmentID int) error {
-	// Сначала получаем путь к файлу
+	tx, err := s.begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Сначала получаем путь к файлу или идентификатор large object
 	var filepath string
-	query := `SELECT filepath FROM attachments WHERE id = $1`
-	err := s.db.QueryRow(query, attachmentID).Scan(&filepath)
+	var oid sql.NullInt64
+	query := `SELECT COALESCE(filepath, ''), data_oid FROM attachments WHERE id = $1`
+	err = tx.QueryRow(query, attachmentID).Scan(&filepath, &oid)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("вложение с ID %d не найдено", attachmentID)
@@ -339,7 +719,7 @@ func (s *PostgresStore) DeleteAttachment(attachmentID int) error {
 	}
 
 	// Удаляем запись из БД
-	res, err := s.db.Exec(`DELETE FROM attachments WHERE id = $1`, attachmentID)
+	res, err := tx.Exec(`DELETE FROM attachments WHERE id = $1`, attachmentID)
 	if err != nil {
 		return fmt.Errorf("ошибка при удалении вложения из БД: %w", err)
 	}
@@ -350,8 +730,19 @@ func (s *PostgresStore) DeleteAttachment(attachmentID int) error {
 	if rowsAffected == 0 {
 		return fmt.Errorf("вложение с ID %d не найдено для удаления в БД", attachmentID)
 	}
+	if oid.Valid {
+		if _, err := tx.Exec(`SELECT lo_unlink($1)`, oid.Int64); err != nil {
+			return fmt.Errorf("ошибка при удалении содержимого вложения из БД: %w", err)
+		}
+	}
+	if err := s.commit(tx); err != nil {
+		return fmt.Errorf("ошибка при удалении вложения из БД: %w", err)
+	}
 
 	// Удаляем физический файл
+	if filepath == "" {
+		return nil
+	}
 	if err := os.Remove(filepath); err != nil {
 		// Логируем ошибку, но не возвращаем ее, так как запись из БД уже удалена
 		log.Printf("Ошибка при удалении физического файла вложения '%s': %v", filepath, err)
@@ -361,3 +752,27 @@ func (s *PostgresStore) DeleteAttachment(attachmentID int) error {
 
 	return nil
 }
+
+// locationArgs возвращает значения колонок location_name, latitude и longitude для места заметки
+func locationArgs(loc *models.Location) (string, sql.NullFloat64, sql.NullFloat64) {
+	if loc == nil {
+		return "", sql.NullFloat64{}, sql.NullFloat64{}
+	}
+	return loc.Name, sql.NullFloat64{Float64: loc.Lat, Valid: true}, sql.NullFloat64{Float64: loc.Lon, Valid: true}
+}
+
+// locationFromSQL собирает место заметки из значений колонок (nil, если координаты не заданы)
+func locationFromSQL(name string, lat, lon sql.NullFloat64) *models.Location {
+	if !lat.Valid || !lon.Valid {
+		return nil
+	}
+	return &models.Location{Name: name, Lat: lat.Float64, Lon: lon.Float64}
+}
+
+// nullTime возвращает значение колонки времени: NULL, если время не задано
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
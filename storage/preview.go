@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"fmt"
+
+	"GNote/models"
+)
+
+// NotePreviewLength — сколько символов содержимого заметки GetAllNotes загружает для списка.
+// Поиск по списку находит текст больших заметок только в этом начале.
+const NotePreviewLength = 64 * 1024
+
+// LoadContent загружает полное содержимое заметок, у которых в списке только превью,
+// для тех, кому нужен весь текст: массовой замены, копии в markdown. Без связи с БД полного
+// содержимого может не быть и в офлайн-кэше — тогда возвращается ErrContentTruncated.
+func LoadContent(store Store, notes []models.Note) error {
+	for i := range notes {
+		if !notes[i].ContentTruncated {
+			continue
+		}
+		note, err := store.GetNoteByID(notes[i].ID)
+		if err != nil {
+			return fmt.Errorf("не удалось загрузить содержимое заметки ID %d: %w", notes[i].ID, err)
+		}
+		if note.ContentTruncated {
+			return fmt.Errorf("%w: ID %d", ErrContentTruncated, note.ID)
+		}
+		notes[i].Content, notes[i].ContentTruncated = note.Content, false
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"GNote/models"
+)
+
+// GetRecurringRules возвращает все правила периодического создания заметок
+func (s *PostgresStore) GetRecurringRules() ([]models.RecurringRule, error) {
+	rows, err := s.db.Query(`SELECT id, title, COALESCE(template_note_id, 0), tags, frequency, day, at_time, enabled, last_run_at
+		FROM recurring_rules ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении правил повторяющихся заметок: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []models.RecurringRule
+	for rows.Next() {
+		var rule models.RecurringRule
+		var tags pq.StringArray
+		var frequency string
+		var lastRun sql.NullTime
+		if err := rows.Scan(&rule.ID, &rule.Title, &rule.TemplateNoteID, &tags, &frequency, &rule.Day, &rule.Time, &rule.Enabled, &lastRun); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании правила: %w", err)
+		}
+		rule.Tags = []string(tags)
+		rule.Frequency = models.Frequency(frequency)
+		if lastRun.Valid {
+			rule.LastRunAt = &lastRun.Time
+		}
+		rules = append(rules, rule)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по правилам: %w", err)
+	}
+	return rules, nil
+}
+
+// SaveRecurringRule создает правило (ID == 0) или обновляет существующее.
+// Новое правило считается сработавшим в момент создания, чтобы не создать заметку за прошедший период.
+func (s *PostgresStore) SaveRecurringRule(rule *models.RecurringRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	var templateID sql.NullInt64
+	if rule.TemplateNoteID != 0 {
+		templateID = sql.NullInt64{Int64: int64(rule.TemplateNoteID), Valid: true}
+	}
+	if rule.ID == 0 {
+		now := time.Now()
+		err := s.writeRow(`INSERT INTO recurring_rules (title, template_note_id, tags, frequency, day, at_time, enabled, last_run_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+			rule.Title, templateID, pq.Array(rule.Tags), string(rule.Frequency), rule.Day, rule.Time, rule.Enabled, now).Scan(&rule.ID)
+		if err != nil {
+			return fmt.Errorf("ошибка при создании правила: %w", err)
+		}
+		rule.LastRunAt = &now
+		return nil
+	}
+	res, err := s.exec(`UPDATE recurring_rules SET title = $1, template_note_id = $2, tags = $3, frequency = $4, day = $5, at_time = $6, enabled = $7
+		WHERE id = $8`,
+		rule.Title, templateID, pq.Array(rule.Tags), string(rule.Frequency), rule.Day, rule.Time, rule.Enabled, rule.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении правила: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества затронутых строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("правило с ID %d не найдено", rule.ID)
+	}
+	return nil
+}
+
+// DeleteRecurringRule удаляет правило; созданные им заметки остаются
+func (s *PostgresStore) DeleteRecurringRule(id int) error {
+	if _, err := s.exec(`DELETE FROM recurring_rules WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("ошибка при удалении правила: %w", err)
+	}
+	return nil
+}
+
+// ClaimRecurringRun отмечает запуск правила в момент runAt, если с прошлого чтения его никто не запускал.
+// Возвращает false, если правило уже запустил другой экземпляр приложения с той же базой.
+func (s *PostgresStore) ClaimRecurringRun(rule *models.RecurringRule, runAt time.Time) (bool, error) {
+	res, err := s.exec(`UPDATE recurring_rules SET last_run_at = $1 WHERE id = $2 AND last_run_at IS NOT DISTINCT FROM $3`,
+		runAt, rule.ID, nullTime(rule.LastRunAt))
+	if err != nil {
+		return false, fmt.Errorf("ошибка при отметке запуска правила: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка при получении количества затронутых строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+	rule.LastRunAt = &runAt
+	return true, nil
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"GNote/models"
+)
+
+// ReplaceInNotes применяет массовую замену одной транзакцией, сохраняет снимок заметок до замены
+// и записывает изменение каждой заметки в журнал изменений.
+// Если заметка изменилась после поиска, замена не применяется ни к одной заметке.
+func (s *PostgresStore) ReplaceInNotes(description string, edits []models.TextEdit) (int, error) {
+	if len(edits) == 0 {
+		return 0, fmt.Errorf("нет заметок для замены")
+	}
+	tx, err := s.begin()
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	var snapshotID int
+	if err := tx.QueryRow(`INSERT INTO replace_snapshots (description) VALUES ($1) RETURNING id`, description).Scan(&snapshotID); err != nil {
+		return 0, fmt.Errorf("ошибка при создании снимка замены: %w", err)
+	}
+	now := time.Now()
+	for _, edit := range edits {
+		res, err := tx.Exec(`UPDATE notes SET title = $1, content = $2, updated_at = $3
+			WHERE id = $4 AND title = $5 AND COALESCE(content, '') = $6`,
+			edit.Title, edit.Content, now, edit.NoteID, edit.OldTitle, edit.OldContent)
+		if err != nil {
+			return 0, fmt.Errorf("ошибка при замене в заметке ID %d: %w", edit.NoteID, err)
+		}
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("ошибка при получении количества затронутых строк: %w", err)
+		}
+		if rowsAffected == 0 {
+			return 0, fmt.Errorf("заметка «%s» (ID %d) изменилась или удалена после поиска, повторите поиск", edit.OldTitle, edit.NoteID)
+		}
+		if _, err := tx.Exec(`INSERT INTO replace_snapshot_notes (snapshot_id, note_id, title, content) VALUES ($1, $2, $3, $4)`,
+			snapshotID, edit.NoteID, edit.OldTitle, edit.OldContent); err != nil {
+			return 0, fmt.Errorf("ошибка при сохранении снимка заметки ID %d: %w", edit.NoteID, err)
+		}
+		if err := s.logActivity(tx, models.ActivityUpdated, edit.NoteID, edit.Title, "замена "+description); err != nil {
+			return 0, err
+		}
+	}
+	if err := s.commit(tx); err != nil {
+		return 0, fmt.Errorf("ошибка при фиксации замены: %w", err)
+	}
+	return snapshotID, nil
+}
+
+// GetReplaceSnapshots возвращает снимки массовых замен, начиная с последней
+func (s *PostgresStore) GetReplaceSnapshots() ([]models.ReplaceSnapshot, error) {
+	rows, err := s.db.Query(`SELECT s.id, s.description, s.created_at, COUNT(n.note_id)
+		FROM replace_snapshots s LEFT JOIN replace_snapshot_notes n ON n.snapshot_id = s.id
+		GROUP BY s.id ORDER BY s.id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении снимков замены: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []models.ReplaceSnapshot
+	for rows.Next() {
+		var snapshot models.ReplaceSnapshot
+		if err := rows.Scan(&snapshot.ID, &snapshot.Description, &snapshot.CreatedAt, &snapshot.NoteCount); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании снимка замены: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по снимкам замены: %w", err)
+	}
+	return snapshots, nil
+}
+
+// UndoReplace возвращает заметкам заголовки и содержимое из снимка, записывает это в журнал изменений
+// и удаляет снимок. Возвращает ID восстановленных заметок (удаленные с тех пор заметки пропускаются).
+func (s *PostgresStore) UndoReplace(snapshotID int) ([]int, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`UPDATE notes n SET title = s.title, content = s.content, updated_at = $2
+		FROM replace_snapshot_notes s WHERE s.snapshot_id = $1 AND s.note_id = n.id RETURNING n.id, n.title`,
+		snapshotID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при отмене замены: %w", err)
+	}
+	var noteIDs []int
+	var titles []string
+	for rows.Next() {
+		var id int
+		var title string
+		if err := rows.Scan(&id, &title); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("ошибка при сканировании ID заметки: %w", err)
+		}
+		noteIDs = append(noteIDs, id)
+		titles = append(titles, title)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по восстановленным заметкам: %w", err)
+	}
+
+	var description string
+	err = tx.QueryRow(`DELETE FROM replace_snapshots WHERE id = $1 RETURNING description`, snapshotID).Scan(&description)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("снимок замены ID %d не найден", snapshotID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при удалении снимка замены: %w", err)
+	}
+	for i, id := range noteIDs {
+		if err := s.logActivity(tx, models.ActivityUpdated, id, titles[i], "отмена замены "+description); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.commit(tx); err != nil {
+		return nil, fmt.Errorf("ошибка при фиксации отмены замены: %w", err)
+	}
+	return noteIDs, nil
+}
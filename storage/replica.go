@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// Чтение с реплики: список заметок, поиск, счетчики и статистика идут на реплику только для чтения,
+// а запись и загрузка отдельной заметки — в основную БД. Реплика получает изменения с задержкой,
+// поэтому какое-то время после записи из этого экземпляра или уведомления о записи из другого
+// чтения тоже идут в основную БД: пользователь сразу видит то, что сохранил он сам или другое окно.
+const (
+	replicaGrace = 10 * time.Second // Сколько после записи чтения идут в основную БД
+	replicaRetry = time.Minute      // Через сколько снова пробовать реплику после ошибки
+)
+
+// replica — подключение к реплике и время, до которого ее не используем
+type replica struct {
+	db        *sql.DB
+	lastWrite atomic.Int64 // Время последней записи из этого экземпляра, UnixNano
+	downUntil atomic.Int64 // Реплика не используется до этого времени после ошибки, UnixNano
+}
+
+// openReplica открывает пул подключений к реплике по настройкам cfg; nil — реплика не задана.
+// Файлы TLS и channel_binding берутся из основных настроек: реплика обычно в том же кластере.
+func openReplica(cfg Config, instance string) (*replica, error) {
+	if cfg.ReplicaURL == "" {
+		return nil, nil
+	}
+	connStr, err := Config{
+		URL:            cfg.ReplicaURL,
+		SSLRootCert:    cfg.SSLRootCert,
+		SSLCert:        cfg.SSLCert,
+		SSLKey:         cfg.SSLKey,
+		ChannelBinding: cfg.ChannelBinding,
+	}.connString()
+	if err != nil {
+		return nil, fmt.Errorf("реплика: %w", err)
+	}
+	if connStr, err = withApplicationName(connStr, instance); err != nil {
+		return nil, fmt.Errorf("реплика: %w", err)
+	}
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии соединения с репликой: %w", err)
+	}
+	return &replica{db: db}, nil
+}
+
+// checkReplica проверяет связь с репликой при запуске; недоступная реплика не мешает работе
+func (s *PostgresStore) checkReplica() {
+	if s.replica == nil {
+		return
+	}
+	if err := s.replica.db.Ping(); err != nil {
+		log.Printf("Реплика БД недоступна, чтение переключено на основную БД на %s: %v", replicaRetry, err)
+		s.replica.downUntil.Store(time.Now().Add(replicaRetry).UnixNano())
+		return
+	}
+	log.Println("Подключена реплика БД: список заметок и поиск читаются с нее")
+}
+
+// wrote отмечает запись в основную БД из этого экземпляра или уведомление о чужой записи
+func (s *PostgresStore) wrote() {
+	if s.replica != nil {
+		s.replica.lastWrite.Store(time.Now().UnixNano())
+	}
+}
+
+// begin начинает транзакцию записи в основной БД
+func (s *PostgresStore) begin() (*sql.Tx, error) {
+	s.wrote()
+	return s.db.Begin()
+}
+
+// commit подтверждает транзакцию записи. Время записи отмечается еще раз: длинная транзакция
+// (например, импорт) могла начаться раньше, чем реплика успеет получить ее результат.
+func (s *PostgresStore) commit(tx *sql.Tx) error {
+	defer s.wrote()
+	return tx.Commit()
+}
+
+// exec выполняет запрос записи в основной БД
+func (s *PostgresStore) exec(query string, args ...any) (sql.Result, error) {
+	s.wrote()
+	return s.db.Exec(query, args...)
+}
+
+// writeRow выполняет запрос записи с RETURNING в основной БД
+func (s *PostgresStore) writeRow(query string, args ...any) *sql.Row {
+	s.wrote()
+	return s.db.QueryRow(query, args...)
+}
+
+// reader возвращает подключение для тяжелых чтений: реплику, если она задана, доступна
+// и из этого экземпляра давно ничего не записывалось; иначе основную БД
+func (s *PostgresStore) reader() *sql.DB {
+	r := s.replica
+	if r == nil {
+		return s.db
+	}
+	now := time.Now().UnixNano()
+	if now-r.lastWrite.Load() < int64(replicaGrace) || now < r.downUntil.Load() {
+		return s.db
+	}
+	return r.db
+}
+
+// readQuery выполняет тяжелый запрос чтения на реплике, а если она не ответила — в основной БД
+func (s *PostgresStore) readQuery(query string, args ...any) (*sql.Rows, error) {
+	db := s.reader()
+	rows, err := db.Query(query, args...)
+	if err == nil || db == s.db {
+		return rows, err
+	}
+	log.Printf("Реплика БД недоступна, чтение переключено на основную БД на %s: %v", replicaRetry, err)
+	s.replica.downUntil.Store(time.Now().Add(replicaRetry).UnixNano())
+	return s.db.Query(query, args...)
+}
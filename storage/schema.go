@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// expectedSchema — таблицы и столбцы, которые использует эта версия GNote; должны совпадать с database.sql
+var expectedSchema = []struct {
+	table   string
+	columns []string
+}{
+	{"notes", []string{"id", "title", "content", "created_at", "updated_at", "reminder_at", "due_at",
+		"source_url", "location_name", "latitude", "longitude", "is_favorite", "priority", "uid"}},
+	{"tags", []string{"id", "name", "color", "icon"}},
+	{"note_tags", []string{"note_id", "tag_id"}},
+	{"attachments", []string{"id", "note_id", "filename", "filepath", "mimetype", "size_bytes", "uploaded_at",
+		"data_oid", "attachment_text", "description", "position", "source_url"}},
+	{"recurring_rules", []string{"id", "title", "template_note_id", "tags", "frequency", "day", "at_time",
+		"enabled", "last_run_at"}},
+	{"snippets", []string{"id", "abbreviation", "expansion"}},
+	{"replace_snapshots", []string{"id", "description", "created_at"}},
+	{"replace_snapshot_notes", []string{"snapshot_id", "note_id", "title", "content"}},
+	{"activity_log", []string{"id", "at", "action", "note_id", "note_title", "details", "actor"}},
+}
+
+// SchemaError — в БД не хватает таблиц или столбцов: база создана старой версией database.sql
+// и не обновлена. Без проверки первый же запрос завершился бы непонятной ошибкой PostgreSQL.
+type SchemaError struct {
+	Missing []string // Отсутствующие таблицы ("notes") и столбцы ("notes.due_at")
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("схема БД устарела, не хватает: %s; обновите ее: psql -f database.sql",
+		strings.Join(e.Missing, ", "))
+}
+
+// checkSchema сравнивает таблицы и столбцы БД с ожидаемыми и возвращает *SchemaError, если чего-то нет.
+// Таблицы ищутся по search_path, как и в остальных запросах.
+func (s *PostgresStore) checkSchema() error {
+	tables := make([]string, len(expectedSchema))
+	for i, t := range expectedSchema {
+		tables[i] = t.table
+	}
+	rows, err := s.db.Query(`
+		SELECT t.name, a.attname
+		FROM unnest($1::text[]) AS t(name)
+		JOIN pg_attribute a ON a.attrelid = to_regclass(t.name) AND a.attnum > 0 AND NOT a.attisdropped`,
+		pq.Array(tables))
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке схемы БД: %w", err)
+	}
+	defer rows.Close()
+	existing := make(map[string]map[string]bool)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return fmt.Errorf("ошибка при проверке схемы БД: %w", err)
+		}
+		if existing[table] == nil {
+			existing[table] = make(map[string]bool)
+		}
+		existing[table][column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при проверке схемы БД: %w", err)
+	}
+
+	var missing []string
+	for _, t := range expectedSchema {
+		columns, ok := existing[t.table]
+		if !ok {
+			missing = append(missing, t.table)
+			continue
+		}
+		for _, column := range t.columns {
+			if !columns[column] {
+				missing = append(missing, t.table+"."+column)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return &SchemaError{Missing: missing}
+	}
+	return nil
+}
+
+// ApplySchema выполняет скрипт схемы (database.sql) в БД из cfg одной транзакцией: создает недостающие
+// таблицы и добавляет столбцы. Строка CREATE DATABASE пропускается — база уже существует.
+func ApplySchema(cfg Config, script string) error {
+	connStr, err := cfg.connString()
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return fmt.Errorf("ошибка при открытии соединения с БД: %w", err)
+	}
+	defer db.Close()
+
+	lines := strings.Split(script, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(line)), "CREATE DATABASE") {
+			kept = append(kept, line)
+		}
+	}
+	// Без параметров запрос уходит простым протоколом, который допускает несколько команд
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении схемы БД: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(strings.Join(kept, "\n")); err != nil {
+		return fmt.Errorf("ошибка при обновлении схемы БД: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при обновлении схемы БД: %w", err)
+	}
+	log.Println("Схема БД обновлена по database.sql")
+	return nil
+}
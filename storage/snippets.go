@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"fmt"
+
+	"GNote/models"
+)
+
+// GetSnippets возвращает все сниппеты по алфавиту сокращений
+func (s *PostgresStore) GetSnippets() ([]models.Snippet, error) {
+	rows, err := s.db.Query(`SELECT id, abbreviation, expansion FROM snippets ORDER BY abbreviation`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении сниппетов: %w", err)
+	}
+	defer rows.Close()
+
+	var snippets []models.Snippet
+	for rows.Next() {
+		var snippet models.Snippet
+		if err := rows.Scan(&snippet.ID, &snippet.Abbreviation, &snippet.Expansion); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании сниппета: %w", err)
+		}
+		snippets = append(snippets, snippet)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по сниппетам: %w", err)
+	}
+	return snippets, nil
+}
+
+// SaveSnippet создает сниппет (ID == 0) или обновляет существующий
+func (s *PostgresStore) SaveSnippet(snippet *models.Snippet) error {
+	if err := snippet.Validate(); err != nil {
+		return err
+	}
+	if snippet.ID == 0 {
+		err := s.writeRow(`INSERT INTO snippets (abbreviation, expansion) VALUES ($1, $2) RETURNING id`,
+			snippet.Abbreviation, snippet.Expansion).Scan(&snippet.ID)
+		if err != nil {
+			return fmt.Errorf("ошибка при создании сниппета: %w", err)
+		}
+		return nil
+	}
+	res, err := s.exec(`UPDATE snippets SET abbreviation = $1, expansion = $2 WHERE id = $3`,
+		snippet.Abbreviation, snippet.Expansion, snippet.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении сниппета: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества затронутых строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("сниппет с ID %d не найден", snippet.ID)
+	}
+	return nil
+}
+
+// DeleteSnippet удаляет сниппет; уже развернутый текст в заметках остается
+func (s *PostgresStore) DeleteSnippet(id int) error {
+	if _, err := s.exec(`DELETE FROM snippets WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("ошибка при удалении сниппета: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"GNote/models"
+)
+
+// wordCountSQL считает слова содержимого заметки n на стороне БД, как strings.Fields
+const wordCountSQL = `CASE WHEN btrim(COALESCE(n.content, ''), E' \t\r\n') = '' THEN 0
+	ELSE array_length(regexp_split_to_array(btrim(n.content, E' \t\r\n'), E'\\s+'), 1) END`
+
+// GetStatistics возвращает статистику заметок, созданных начиная с from (нулевое время — за все время).
+// Слова и символы считаются агрегатными запросами, без загрузки содержимого заметок.
+func (s *PostgresStore) GetStatistics(from time.Time) (*models.Statistics, error) {
+	stats := &models.Statistics{}
+	err := s.reader().QueryRow(`SELECT COUNT(*), COALESCE(SUM(`+wordCountSQL+`), 0), COALESCE(SUM(char_length(n.content)), 0)
+		FROM notes n WHERE n.created_at >= $1`, from).
+		Scan(&stats.Total.Notes, &stats.Total.Words, &stats.Total.Chars)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчете статистики заметок: %w", err)
+	}
+
+	rows, err := s.readQuery(`SELECT t.name, COUNT(*), COALESCE(SUM(`+wordCountSQL+`), 0), COALESCE(SUM(char_length(n.content)), 0)
+		FROM tags t
+		JOIN note_tags nt ON nt.tag_id = t.id
+		JOIN notes n ON n.id = nt.note_id
+		WHERE n.created_at >= $1
+		GROUP BY t.name ORDER BY 3 DESC, t.name`, from)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчете статистики по тегам: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tag models.TagStats
+		if err := rows.Scan(&tag.Tag, &tag.Notes, &tag.Words, &tag.Chars); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании статистики тега: %w", err)
+		}
+		stats.Tags = append(stats.Tags, tag)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по статистике тегов: %w", err)
+	}
+
+	monthRows, err := s.readQuery(`SELECT date_trunc('month', n.created_at) AS month, COUNT(*),
+			COALESCE(SUM(`+wordCountSQL+`), 0), COALESCE(SUM(char_length(n.content)), 0)
+		FROM notes n WHERE n.created_at >= $1
+		GROUP BY month ORDER BY month`, from)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчете статистики по месяцам: %w", err)
+	}
+	defer monthRows.Close()
+	for monthRows.Next() {
+		var month models.MonthStats
+		if err := monthRows.Scan(&month.Month, &month.Notes, &month.Words, &month.Chars); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании статистики месяца: %w", err)
+		}
+		stats.Months = append(stats.Months, month)
+	}
+	if err = monthRows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по статистике месяцев: %w", err)
+	}
+	return stats, nil
+}
+
+// CountNotes возвращает число заметок, подходящих под filter, не загружая их
+func (s *PostgresStore) CountNotes(filter models.NoteFilter) (int, error) {
+	var conds []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	// hasTag — условие «у заметки есть тег или вложенный в него»
+	hasTag := func(name string) string {
+		tag := arg(strings.ToLower(name))
+		return `EXISTS (SELECT 1 FROM note_tags nt JOIN tags t ON t.id = nt.tag_id WHERE nt.note_id = n.id
+			AND (lower(t.name) = ` + tag + ` OR left(lower(t.name), length(` + tag + `) + 1) = ` + tag + ` || '/'))`
+	}
+	if filter.Tag != "" {
+		conds = append(conds, hasTag(filter.Tag))
+	}
+	for _, name := range filter.ExcludeTags {
+		conds = append(conds, `NOT `+hasTag(name))
+	}
+	if filter.Favorite {
+		conds = append(conds, `n.is_favorite`)
+	}
+	if filter.MinPriority > models.PriorityNone {
+		conds = append(conds, `n.priority >= `+arg(filter.MinPriority))
+	}
+	if filter.HasReminder {
+		conds = append(conds, `n.reminder_at IS NOT NULL`)
+	}
+	if filter.HasAttachments {
+		conds = append(conds, `EXISTS (SELECT 1 FROM attachments a WHERE a.note_id = n.id)`)
+	}
+	if text := strings.TrimSpace(filter.Text); text != "" {
+		pattern := arg(likeEscaper.Replace(text))
+		conds = append(conds, `(n.title ILIKE '%' || `+pattern+` || '%' ESCAPE '\' OR n.content ILIKE '%' || `+pattern+` || '%' ESCAPE '\')`)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		conds = append(conds, `n.created_at >= `+arg(filter.CreatedFrom))
+	}
+	if !filter.CreatedTo.IsZero() {
+		conds = append(conds, `n.created_at < `+arg(filter.CreatedTo))
+	}
+	if !filter.UpdatedFrom.IsZero() {
+		conds = append(conds, `n.updated_at >= `+arg(filter.UpdatedFrom))
+	}
+	if !filter.UpdatedTo.IsZero() {
+		conds = append(conds, `n.updated_at < `+arg(filter.UpdatedTo))
+	}
+
+	query := `SELECT COUNT(*) FROM notes n`
+	if len(conds) > 0 {
+		query += ` WHERE ` + strings.Join(conds, ` AND `)
+	}
+	var count int
+	if err := s.reader().QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка при подсчете заметок: %w", err)
+	}
+	return count, nil
+}
+
+// CountByTag возвращает число заметок по тегам так же, как их считает дерево тегов:
+// заметка учитывается и во всех родительских тегах, но в каждом только один раз
+func (s *PostgresStore) CountByTag() (map[string]int, error) {
+	rows, err := s.readQuery(`
+		WITH paths AS (
+			SELECT DISTINCT nt.note_id, array_to_string(parts[1:depth], '` + models.TagSeparator + `') AS path
+			FROM note_tags nt
+			JOIN tags t ON t.id = nt.tag_id
+			CROSS JOIN LATERAL string_to_array(t.name, '` + models.TagSeparator + `') AS parts
+			CROSS JOIN LATERAL generate_series(1, cardinality(parts)) AS depth
+		)
+		SELECT path, COUNT(*) FROM paths GROUP BY path`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчете заметок по тегам: %w", err)
+	}
+	defer rows.Close()
+	counts := make(map[string]int)
+	for rows.Next() {
+		var path string
+		var count int
+		if err := rows.Scan(&path, &count); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании числа заметок тега: %w", err)
+		}
+		counts[path] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по тегам: %w", err)
+	}
+	return counts, nil
+}
+
+// StorageUsage возвращает, сколько места занимают заметки и вложения, одним агрегатным запросом
+func (s *PostgresStore) StorageUsage() (*models.StorageUsage, error) {
+	usage := &models.StorageUsage{}
+	err := s.db.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM notes),
+			(SELECT COALESCE(SUM(octet_length(title) + octet_length(COALESCE(content, ''))), 0) FROM notes),
+			COUNT(*),
+			COALESCE(SUM(size_bytes) FILTER (WHERE data_oid IS NULL), 0),
+			COALESCE(SUM(size_bytes) FILTER (WHERE data_oid IS NOT NULL), 0)
+		FROM attachments`).
+		Scan(&usage.Notes, &usage.ContentBytes, &usage.Attachments, &usage.FileBytes, &usage.DatabaseBytes)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчете занятого места: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"GNote/models"
+)
+
+// maxTagSuggestions — сколько тегов возвращает SearchTags
+const maxTagSuggestions = 10
+
+// likeEscaper экранирует спецсимволы шаблона LIKE, чтобы префикс искался буквально
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchTags возвращает существующие теги, начинающиеся с prefix (без учета регистра),
+// от самых используемых
+func (s *PostgresStore) SearchTags(prefix string) ([]string, error) {
+	query := `SELECT t.name FROM tags t LEFT JOIN note_tags nt ON nt.tag_id = t.id
+		WHERE t.name ILIKE $1 || '%' ESCAPE '\'
+		GROUP BY t.id, t.name ORDER BY COUNT(nt.note_id) DESC, t.name LIMIT $2`
+	rows, err := s.readQuery(query, likeEscaper.Replace(prefix), maxTagSuggestions)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при поиске тегов: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании тега: %w", err)
+		}
+		tags = append(tags, name)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по строкам тегов: %w", err)
+	}
+	return tags, nil
+}
+
+// RenameTag переименовывает тег oldName вместе с вложенными в него тегами: "работа" → "дела"
+// превращает и "работа/проекты" в "дела/проекты". Если новое имя уже занято, теги объединяются.
+// Возвращает ID заметок, теги которых изменились.
+func (s *PostgresStore) RenameTag(oldName, newName string) ([]int, error) {
+	oldName, newName = models.NormalizeTag(oldName), models.NormalizeTag(newName)
+	if oldName == "" || newName == "" {
+		return nil, fmt.Errorf("имя тега не может быть пустым")
+	}
+	if oldName == newName {
+		return nil, nil
+	}
+	tx, err := s.begin()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id, name FROM tags WHERE name = $1 OR name LIKE $2 ESCAPE '\' FOR UPDATE`,
+		oldName, likeEscaper.Replace(oldName+models.TagSeparator)+"%")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при поиске переименуемых тегов: %w", err)
+	}
+	type tag struct {
+		id   int
+		name string
+	}
+	var tags []tag
+	var tagIDs []int64
+	for rows.Next() {
+		var t tag
+		if err := rows.Scan(&t.id, &t.name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("ошибка при сканировании тега: %w", err)
+		}
+		tags = append(tags, t)
+		tagIDs = append(tagIDs, int64(t.id))
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по строкам тегов: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("тег «%s» не найден", oldName)
+	}
+
+	noteIDs, err := taggedNoteIDs(tx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Сначала переименовываем более глубокие теги: при "а" → "а/б" тег "а/б" должен освободить имя
+	sort.Slice(tags, func(i, j int) bool { return len(tags[i].name) > len(tags[j].name) })
+	for _, t := range tags {
+		target := models.RenameTagPrefix(t.name, oldName, newName)
+		var existingID int
+		err := tx.QueryRow(`SELECT id FROM tags WHERE name = $1`, target).Scan(&existingID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if _, err := tx.Exec(`UPDATE tags SET name = $1 WHERE id = $2`, target, t.id); err != nil {
+				return nil, fmt.Errorf("ошибка при переименовании тега «%s»: %w", t.name, err)
+			}
+		case err != nil:
+			return nil, fmt.Errorf("ошибка при поиске тега «%s»: %w", target, err)
+		default: // Тег с новым именем уже есть: переносим заметки к нему
+			if _, err := tx.Exec(`INSERT INTO note_tags (note_id, tag_id) SELECT note_id, $1 FROM note_tags WHERE tag_id = $2 ON CONFLICT DO NOTHING`, existingID, t.id); err != nil {
+				return nil, fmt.Errorf("ошибка при объединении тега «%s» с «%s»: %w", t.name, target, err)
+			}
+			if _, err := tx.Exec(`DELETE FROM tags WHERE id = $1`, t.id); err != nil {
+				return nil, fmt.Errorf("ошибка при удалении тега «%s»: %w", t.name, err)
+			}
+		}
+	}
+	if err := s.commit(tx); err != nil {
+		return nil, fmt.Errorf("ошибка при фиксации переименования тега: %w", err)
+	}
+	return noteIDs, nil
+}
+
+// taggedNoteIDs возвращает ID заметок, отмеченных любым из тегов tagIDs
+func taggedNoteIDs(tx *sql.Tx, tagIDs []int64) ([]int, error) {
+	rows, err := tx.Query(`SELECT DISTINCT note_id FROM note_tags WHERE tag_id = ANY($1) ORDER BY note_id`, pq.Array(tagIDs))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при поиске заметок с тегом: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании ID заметки: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по заметкам с тегом: %w", err)
+	}
+	return ids, nil
+}
+
+// GetTagStyles возвращает оформление тегов, у которых задан цвет или значок, по имени тега
+func (s *PostgresStore) GetTagStyles() (map[string]models.TagStyle, error) {
+	rows, err := s.db.Query(`SELECT name, color, icon FROM tags WHERE color <> '' OR icon <> ''`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении оформления тегов: %w", err)
+	}
+	defer rows.Close()
+
+	styles := make(map[string]models.TagStyle)
+	for rows.Next() {
+		var name string
+		var style models.TagStyle
+		if err := rows.Scan(&name, &style.Color, &style.Icon); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании оформления тега: %w", err)
+		}
+		styles[name] = style
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка после итерации по оформлению тегов: %w", err)
+	}
+	return styles, nil
+}
+
+// SetTagStyle задает цвет и значок тега. Тег создается, если его еще нет
+// (например, для уровня "работа", у которого есть только вложенные теги).
+func (s *PostgresStore) SetTagStyle(name string, style models.TagStyle) error {
+	name = models.NormalizeTag(name)
+	if name == "" {
+		return fmt.Errorf("имя тега не может быть пустым")
+	}
+	_, err := s.exec(`INSERT INTO tags (name, color, icon) VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET color = EXCLUDED.color, icon = EXCLUDED.icon`,
+		name, style.Color, style.Icon)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении оформления тега «%s»: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package summary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxAPIText — сколько символов заметки отправляется модели
+const maxAPIText = 20000
+
+// prompt — инструкция модели
+const prompt = "Кратко перескажи заметку пользователя в двух-трех предложениях на языке заметки. " +
+	"Отвечай только пересказом, без вступлений."
+
+// API пересказывает языковой моделью через API, совместимый с OpenAI /v1/chat/completions
+// (OpenAI, Ollama, llama.cpp server и т.п.)
+type API struct {
+	URL    string // Полный адрес метода, например http://localhost:11434/v1/chat/completions
+	Key    string // Ключ API; пусто — без авторизации (локальный сервер)
+	Model  string
+	Client *http.Client
+}
+
+// Summarize отправляет текст модели и возвращает ее пересказ
+func (a *API) Summarize(ctx context.Context, text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", ErrEmpty
+	}
+	if runes := []rune(text); len(runes) > maxAPIText {
+		text = string(runes[:maxAPIText])
+	}
+	client := a.Client
+	if client == nil {
+		client = &http.Client{Timeout: 2 * time.Minute}
+	}
+
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	body, err := json.Marshal(struct {
+		Model    string    `json:"model"`
+		Messages []message `json:"messages"`
+	}{a.Model, []message{{"system", prompt}, {"user", text}}})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("некорректный адрес API пересказа: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.Key != "" {
+		req.Header.Set("Authorization", "Bearer "+a.Key)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ошибка запроса к API пересказа: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("API пересказа вернул статус %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		Choices []struct {
+			Message message `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("ошибка чтения ответа API пересказа: %w", err)
+	}
+	if len(result.Choices) == 0 || strings.TrimSpace(result.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("API пересказа вернул пустой ответ")
+	}
+	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+}
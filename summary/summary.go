@@ -0,0 +1,110 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"GNote/nlp"
+)
+
+// DefaultSentences — сколько предложений оставляет локальный пересказ по умолчанию
+const DefaultSentences = 3
+
+// ErrEmpty — в заметке нет текста для пересказа
+var ErrEmpty = errors.New("в заметке нет текста")
+
+// Summarizer кратко пересказывает текст заметки
+type Summarizer interface {
+	Summarize(ctx context.Context, text string) (string, error)
+}
+
+// Local пересказывает без сети: выбирает предложения, в которых больше всего частых
+// значимых слов текста, и оставляет их в исходном порядке
+type Local struct {
+	Sentences int // Сколько предложений оставить; 0 — DefaultSentences
+}
+
+// Summarize возвращает самые содержательные предложения текста
+func (l Local) Summarize(_ context.Context, text string) (string, error) {
+	sentences := splitSentences(text)
+	if len(sentences) == 0 {
+		return "", ErrEmpty
+	}
+	n := l.Sentences
+	if n <= 0 {
+		n = DefaultSentences
+	}
+	if len(sentences) <= n {
+		return strings.Join(sentences, " "), nil
+	}
+
+	freq := make(map[string]int)
+	words := make([][]string, len(sentences))
+	for i, s := range sentences {
+		words[i] = nlp.Tokenize(s)
+		for _, w := range words[i] {
+			freq[w]++
+		}
+	}
+	type scored struct {
+		index int
+		score float64
+	}
+	scores := make([]scored, len(sentences))
+	for i, ws := range words {
+		sum := 0
+		for _, w := range ws {
+			sum += freq[w]
+		}
+		score := 0.0
+		if len(ws) > 0 {
+			// Корень из длины: длинные предложения не выигрывают только за счет количества слов
+			score = float64(sum) / math.Sqrt(float64(len(ws)))
+		}
+		if i == 0 {
+			score *= 1.2 // Первое предложение обычно вводит тему
+		}
+		scores[i] = scored{i, score}
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].score > scores[j].score })
+	picked := scores[:n]
+	sort.Slice(picked, func(i, j int) bool { return picked[i].index < picked[j].index })
+
+	result := make([]string, len(picked))
+	for i, p := range picked {
+		result[i] = sentences[p.index]
+	}
+	return strings.Join(result, " "), nil
+}
+
+// splitSentences делит текст на предложения: по знакам конца предложения и по строкам
+// (пункты списков без точки в конце). Заголовки markdown пропускаются, разметка в начале строк убирается.
+func splitSentences(text string) []string {
+	var sentences []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue // Заголовок повторяет тему, а не пересказывает ее
+		}
+		line = strings.TrimSpace(strings.TrimLeft(line, ">-*+ "))
+		start := 0
+		runes := []rune(line)
+		for i, r := range runes {
+			end := r == '.' || r == '!' || r == '?' || r == '…'
+			if end && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])) {
+				if s := strings.TrimSpace(string(runes[start : i+1])); s != "" {
+					sentences = append(sentences, s)
+				}
+				start = i + 1
+			}
+		}
+		if s := strings.TrimSpace(string(runes[start:])); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+	return sentences
+}
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// activityLimit — сколько последних записей журнала изменений показывается
+const activityLimit = 500
+
+// activityNames — подписи действий в журнале изменений
+var activityNames = map[models.ActivityAction]string{
+	models.ActivityCreated:  "Создана",
+	models.ActivityUpdated:  "Изменена",
+	models.ActivityDeleted:  "Удалена",
+	models.ActivityExported: "Экспорт",
+}
+
+// describeActivity возвращает описание записи журнала: "Изменена «План» (#12): заголовок, теги"
+func describeActivity(entry models.Activity) string {
+	name, ok := activityNames[entry.Action]
+	if !ok {
+		name = string(entry.Action)
+	}
+	text := name
+	if entry.NoteID != 0 {
+		text += fmt.Sprintf(" «%s» (#%d)", truncateTitle(entry.NoteTitle, 50), entry.NoteID)
+	}
+	if entry.Details != "" {
+		text += ": " + entry.Details
+	}
+	return text
+}
+
+// showActivity показывает журнал изменений: когда, кем и что сделано с заметками.
+// Двойного щелчка у списка нет, поэтому заметка открывается выбором строки.
+func (a *NoteApp) showActivity() {
+	entries, err := a.store.GetActivity(activityLimit)
+	if err != nil {
+		a.showStoreError("не удалось загрузить журнал изменений", err)
+		log.Printf("Ошибка при загрузке журнала изменений: %v", err)
+		return
+	}
+	if len(entries) == 0 {
+		dialog.ShowInformation("Журнал изменений", "Журнал пока пуст.", a.window)
+		return
+	}
+
+	var d dialog.Dialog
+	list := widget.NewList(
+		func() int { return len(entries) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, widget.NewLabel("00.00.2006 00:00"), widget.NewLabel(""), widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			entry := entries[id]
+			row := o.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(describeActivity(entry))
+			row.Objects[1].(*widget.Label).SetText(entry.At.Local().Format("02.01.2006 15:04"))
+			row.Objects[2].(*widget.Label).SetText(entry.Actor)
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		entry := entries[id]
+		list.UnselectAll()
+		if entry.NoteID == 0 || entry.Action == models.ActivityDeleted {
+			return
+		}
+		d.Hide()
+		a.OpenNoteByID(entry.NoteID)
+	}
+
+	content := container.NewBorder(widget.NewLabel("Выберите запись, чтобы открыть заметку."), nil, nil, nil, list)
+	d = dialog.NewCustom(fmt.Sprintf("Журнал изменений: последние %d", len(entries)), "Закрыть", content, a.window)
+	d.Resize(fyne.NewSize(900, 500))
+	d.Show()
+}
+
+// logExport записывает выгрузку заметок в файл name в журнал изменений
+func (a *NoteApp) logExport(notes []models.Note, name string) {
+	entry := models.Activity{Action: models.ActivityExported, Details: fmt.Sprintf("заметок: %d, файл %s", len(notes), name)}
+	if len(notes) == 1 {
+		entry.NoteID, entry.NoteTitle, entry.Details = notes[0].ID, notes[0].Title, name
+	}
+	if err := a.store.LogActivity(&entry); err != nil {
+		log.Printf("Ошибка при записи экспорта в журнал изменений: %v", err)
+	}
+}
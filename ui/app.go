@@ -1,78 +1,131 @@
 package ui
 
 import (
-	"encoding/json"
 	"fmt"
-	"image/color"
 	"io/ioutil"
 	"log"
-	"sort"
 	"strings"
+	"sync"
 	"time"
 	"os"     
 	"path/filepath"
-	"mime"
-	"os/exec"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"GNote/events"
+	"GNote/hooks"
+	"GNote/index"
+	"GNote/journal"
 	"GNote/models"
+	"GNote/scan"
+	"GNote/speech"
 	"GNote/storage"
+	"GNote/summary"
 )
 
-// NoteApp представляет собой основную структуру приложения Fyne
+// NoteApp представляет собой основную структуру приложения Fyne.
+// Окно собирается из отдельных представлений, которые отображают состояние NoteViewModel.
 type NoteApp struct {
-	window fyne.Window
-	store  storage.Store
-
-	allNotes          []models.Note // Все загруженные заметки
-	filteredNotes     []models.Note // Отфильтрованные заметки для отображения в списке
-	selectedNoteIndex int           // Индекс выбранной заметки в filteredNotes (-1, если ничего не выбрано)
-	hasUnsavedChanges bool          // Флаг для отслеживания несохраненных изменений
-
-	// UI элементы
-	noteList       *widget.List
-	searchEntry    *widget.Entry
-	sortSelect     *widget.Select
-	titleEntry     *widget.Entry
-	contentEntry   *widget.Entry
-	charCountLabel *widget.Label
-	tagsEntry      *widget.Entry 
-	reminderButton *widget.Button 
-	reminderLabel  *widget.Label  
-	saveButton     *widget.Button
-	deleteButton   *widget.Button
+	window  fyne.Window
+	store   storage.Store
+	profile string // Профиль; от него зависят ключи сохраненного состояния окна
+
+	*NoteViewModel   // Список заметок, фильтр, выбор и флаг изменений
+	*NoteListView    // Левая панель со списком
+	*NoteEditorView  // Поля основного редактора
+	*AttachmentsView // Вложения выбранной заметки
+
+	saveButton   *widget.Button
+	deleteButton *widget.Button
 
 	// Для диалога напоминания
 	reminderDateEntry *widget.Entry
 	reminderTimeEntry *widget.Entry
-	currentReminder   *time.Time // Временное хранилище для даты/времени напоминания в диалоге
-
-	// НОВЫЕ ЭЛЕМЕНТЫ ДЛЯ ВЛОЖЕНИЙ
-	attachmentsContainer *fyne.Container // Контейнер для списка вложений и кнопки "Прикрепить"
-	attachmentsList      *widget.List    // Список отображаемых вложений
-	attachButton         *widget.Button  // Кнопка для прикрепления файла
-	attachmentsDirPath   string          // Путь к директории для хранения вложений
+	currentReminder   *time.Time // Напоминание редактируемой заметки (сохраняется в saveNote)
+	currentDue        *time.Time // Срок редактируемой заметки
+	loadedUpdatedAt   time.Time  // updated_at открытой заметки при загрузке или последнем сохранении в редакторе
+
+	// Совпадения строки поиска в открытой заметке
+	matches      []int // Позиции совпадений в содержимом (в символах)
+	matchLength  int   // Длина искомого текста в символах
+	currentMatch int   // Текущее совпадение (-1 — еще не переходили)
+
+	// Поиск и замена в содержимом (Ctrl+H)
+	findReplaceView *FindReplaceView
+	findFrom        int // С какой позиции (в байтах) ищет «Найти далее»
+	foundStart      int // Начало найденного совпадения, которое заменит «Заменить» (-1 — нет)
+
+	// Чтение заметки вслух
+	readAloudView *ReadAloudView
+	speaker       *speech.Speaker // nil — синтезатор речи не найден
+	reading       *readAloud      // nil — заметку не читают
+
+	snippets map[string]models.Snippet // Сниппеты по сокращению
+	hooks    *hooks.Hooks              // Обработчики событий из настроек; nil — не заданы
+
+	summarizer summary.Summarizer // Краткий пересказ; nil — локальный
+	metrics    *storage.Metrics   // Время запросов к БД; nil — не собирается
+
+	linkPreviewView *LinkPreviewView // Карточки ссылок под редактором
+	linksView       *LinksView       // Вкладка со всеми ссылками заметки
+	attachmentTabs  *container.AppTabs
+	scanner         *scan.Scanner // Сканер документов; nil — программа сканирования не найдена
+
+	currentLocation    *models.Location // Место редактируемой заметки
+	currentPriority    models.Priority  // Приоритет редактируемой заметки
+	attachmentsDirPath string           // Путь к директории для хранения вложений
+	attachmentsInDB    bool             // Новые вложения сохраняются в БД, а не в attachmentsDirPath
+
+	contextProviders []journal.ContextProvider // Провайдеры контекста для дневниковых записей
+	profiles         *Profiles                 // Переключатель профилей (nil — меню профилей нет)
+	menu             *appMenu                  // Главное меню окна
+	toolbar          *toolbar                  // Панель кнопок действий под редактором
+	lock             appLock                   // Блокировка окна PIN-кодом или паролем
+
+	// Основной редактор и правая панель для второй заметки
+	noteDetail  fyne.CanvasObject
+	detailArea  *fyne.Container
+	layout      *responsiveSplit // Список и детали рядом или по очереди в узком окне
+	sidePane    *notePane
+	noteWindows map[int]*noteWindow // Заметки, открытые в отдельных окнах, по ID
+	noteTabs    *noteTabs           // Вкладки: основной редактор и открытые заметки
+
+	// Похожие заметки по фоновому индексу (nil — индекс не запущен)
+	relatedView *RelatedView
+	related     *index.Background
+
+	// Состояние связи с БД
+	healthView        *HealthView
+	health            *storage.HealthChecker // nil — проверка не подключена, ошибки показываются диалогами
+	unsubscribeHealth func()
+
+	// События хранилища
+	unsubscribe     func()
+	eventsMu        sync.Mutex
+	pendingEvents   []events.Event // События, ожидающие применения в потоке интерфейса
+	pendingSelectID int            // ID заметки, которую нужно выделить после появления в списке
 }
 
-// NewNoteApp создает новый экземпляр NoteApp
-func NewNoteApp(w fyne.Window, s storage.Store) *NoteApp {
+// NewNoteApp создает новый экземпляр NoteApp для профиля profile (пусто — основной профиль)
+func NewNoteApp(w fyne.Window, s storage.Store, profile string) *NoteApp {
 	app := &NoteApp{
-		window:            w,
-		store:             s,
-		selectedNoteIndex: -1, 
-		hasUnsavedChanges: false,
+		window:        w,
+		store:         s,
+		profile:       profile,
+		NoteViewModel: NewNoteViewModel(),
+		foundStart:    -1,
 	}
 	app.window.SetContent(app.MakeUI())
-	app.window.SetMaster() // Устанавливаем окно как основное
-	app.window.Resize(fyne.NewSize(1000, 700)) // Устанавливаем начальный размер
-	app.window.SetOnClosed(app.onWindowClosed) // Обработчик закрытия окна
+	app.setupMainMenu()
+	app.restoreWindowState()
+	app.restoreLineNumbers()
+	app.window.SetCloseIntercept(app.onWindowClosed) // Перед закрытием спрашиваем о несохраненных изменениях
 
 	// Определяем путь для хранения вложений
 	// Используем Storage().RootURI().Path() для кроссплатформенного пути к данным приложения
@@ -89,216 +142,176 @@ func NewNoteApp(w fyne.Window, s storage.Store) *NoteApp {
 	// Загружаем заметки при старте
 	app.loadNotes()
 	app.newNote() // Начинаем с пустой формы для новой заметки
+	app.noteTabs.restore() // Восстанавливаем вкладки прошлого сеанса
+	app.restoreStickyNotes()
+	app.setupLock()
 	return app
 }
 
-// MakeUI создает и возвращает пользовательский интерфейс приложения
-func (a *NoteApp) MakeUI() fyne.CanvasObject {
-	// --- Левая панель: Поиск, Сортировка, Список заметок ---
-	a.searchEntry = widget.NewEntry()
-	a.searchEntry.SetPlaceHolder("Поиск по заголовку, содержимому или тегам...")
-	a.searchEntry.OnChanged = func(s string) {
-		a.filterNotes()
-	}
-
-	// Инициализируем a.noteList ДО a.sortSelect
-	a.noteList = widget.NewList(
-		func() int {
-			return len(a.filteredNotes)
-		},
-		func() fyne.CanvasObject {
-			// Кастомный элемент списка для выделения фона
-			bg := canvas.NewRectangle(color.Transparent) // Фон
-			label := widget.NewLabel("Название заметки") // Текст
-			return container.NewMax(bg, label)           // bg будет под label
-		},
-		func(i widget.ListItemID, o fyne.CanvasObject) {
-			note := a.filteredNotes[i]
-			box := o.(*fyne.Container)
-			bg := box.Objects[0].(*canvas.Rectangle)
-			label := box.Objects[1].(*widget.Label)
-
-			label.SetText(note.Title)
-
-			// Визуальное выделение активной заметки
-			if i == a.selectedNoteIndex {
-				bg.FillColor = theme.PrimaryColor() // Используем PrimaryColor для фона
-				label.TextStyle.Bold = true
-			} else {
-				bg.FillColor = color.Transparent // Прозрачный фон
-				label.TextStyle.Bold = false
-			}
-			bg.Refresh()
-			label.Refresh()
-		},
-	)
-	a.noteList.OnSelected = a.onNoteSelected
-	a.noteList.OnUnselected = func(id widget.ListItemID) {
-		// При сбросе выделения, убедимся, что стиль сброшен
-		// Это важно, так как Fyne переиспользует объекты списка
-		if id >= 0 && id < len(a.filteredNotes) {
-			// Вызываем UpdateItem для сброса стиля
-			a.noteList.UpdateItem(id, a.noteList.CreateItem())
-		}
+// SetAttachmentsDir задает каталог для файлов вложений вместо каталога данных приложения
+func (a *NoteApp) SetAttachmentsDir(dir string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("Ошибка при создании директории для вложений '%s': %v", dir, err)
+		dialog.ShowError(fmt.Errorf("не удалось создать директорию для вложений: %w", err), a.window)
+		return
 	}
+	a.attachmentsDirPath = dir
+	log.Printf("Директория для вложений: %s", dir)
+}
 
-	a.sortSelect = widget.NewSelect([]string{
-		"По дате создания (новые)",
-		"По дате создания (старые)",
-		"По дате обновления (новые)",
-		"По дате обновления (старые)",
-		"По заголовку (А-Я)",
-		"По заголовку (Я-А)",
-	}, func(s string) {
-		a.sortNotes(s)
-		a.noteList.Refresh() // Теперь a.noteList инициализирован
-	})
-	a.sortSelect.SetSelectedIndex(0) // Это вызовет коллбэк OnChanged
+// SetAttachmentsInDatabase включает хранение содержимого новых вложений в БД вместо каталога вложений
+func (a *NoteApp) SetAttachmentsInDatabase(inDB bool) {
+	a.attachmentsInDB = inDB
+}
 
-	leftPanel := container.NewBorder(
-		container.NewVBox(a.searchEntry, a.sortSelect), // Поиск и сортировка сверху
-		nil,
-		nil,
-		nil,
-		a.noteList,
-	)
+// MakeUI создает и возвращает пользовательский интерфейс приложения
+func (a *NoteApp) MakeUI() fyne.CanvasObject {
+	// --- Левая панель: Избранное, Поиск, Сортировка, Список заметок ---
+	a.NoteListView = NewNoteListView(a.NoteViewModel)
+	a.NoteListView.OnSelected = a.onNoteSelected
+	a.NoteListView.OnFilterChanged = a.filterNotes
+	a.NoteListView.OnProximity = a.proximityDialog
+	a.NoteListView.OnClearProximity = a.clearProximity
+	a.NoteListView.OnRenameTag = a.renameTagDialog
+	a.NoteListView.OnTagStyle = a.tagStyleDialog
+	a.NoteListView.OnTwoLineTitles = a.refreshMainMenu
+	a.NoteListView.OnSavedSearches = a.showSavedSearchMenu
+	a.NoteListView.OnTimeline = a.refreshMainMenu
 
 	// --- Правая панель: Детали заметки и кнопки ---
-	a.titleEntry = widget.NewEntry()
-	a.titleEntry.SetPlaceHolder("Заголовок заметки")
-	a.titleEntry.OnChanged = func(s string) {
+	a.NoteEditorView = NewNoteEditorView(a.NoteViewModel)
+	a.NoteEditorView.OnChanged = func() {
 		a.setUnsavedChanges(true)
+		a.updateLimitWarning()
 	}
-
-	a.contentEntry = widget.NewMultiLineEntry()
-	a.contentEntry.SetPlaceHolder("Содержимое заметки...")
-	a.contentEntry.Wrapping = fyne.TextWrapWord
-	a.contentEntry.OnChanged = func(s string) {
-		a.setUnsavedChanges(true)
+	a.NoteEditorView.OnContentChanged = func() {
 		a.updateCharCount()
+		a.updateMatches()
+		a.resetFind()
+		if a.lineNumbers.Visible() {
+			a.updateLineNumbers()
+		}
+		a.scheduleLinkPreviews()
+	}
+	a.contentEntry.OnFindReplace = a.showFindReplace
+	a.contentEntry.Expand = a.expandAbbreviation
+	a.window.Canvas().AddShortcut(findReplaceShortcut, func(fyne.Shortcut) { a.showFindReplace() })
+	a.contentEntry.OnGoToLine = a.showGoToLine
+	a.contentEntry.OnCursorChanged = func() {
+		a.followCursor()
+		a.updateSelectionCount()
+	}
+	a.window.Canvas().AddShortcut(goToLineShortcut, func(fyne.Shortcut) { a.showGoToLine() })
+	a.NoteEditorView.OnLineNumbers = a.toggleLineNumbers
+	a.NoteEditorView.OnPrevMatch = func() { a.showMatch(-1) }
+	a.NoteEditorView.OnNextMatch = func() { a.showMatch(1) }
+	a.NoteEditorView.OnFavorite = a.toggleFavorite
+	a.NoteEditorView.OnShowQR = a.showQR
+	a.NoteEditorView.OnReadAloud = a.startReadAloud
+	a.NoteEditorView.OnReadingMode = a.showReadingMode
+	a.NoteEditorView.OnInsertSymbol = a.showSymbolPicker
+	a.NoteEditorView.OnPriorityChanged = a.onPriorityChanged
+	a.NoteEditorView.OnSetReminder = a.setReminderDialog
+	a.NoteEditorView.OnClearReminder = func() {
+		a.setUnsavedChanges(true)
+		a.updateReminderUI(nil)
 	}
-
-	a.charCountLabel = widget.NewLabel("Символов: 0 | Слов: 0")
-	a.charCountLabel.Alignment = fyne.TextAlignTrailing // Выравнивание по правому краю
-
-	a.tagsEntry = widget.NewEntry()
-	a.tagsEntry.SetPlaceHolder("Теги (через запятую, например: работа, личное)")
-	a.tagsEntry.OnChanged = func(s string) {
+	a.NoteEditorView.OnSetDue = a.setDueDialog
+	a.NoteEditorView.OnClearDue = func() {
 		a.setUnsavedChanges(true)
+		a.updateDueUI(nil)
 	}
-
-	a.reminderLabel = widget.NewLabel("Напоминание: Не установлено")
-	a.reminderButton = widget.NewButton("Установить напоминание", a.setReminderDialog)
-	clearReminderButton := widget.NewButton("Очистить", func() {
+	a.NoteEditorView.OnSetLocation = a.setLocationDialog
+	a.NoteEditorView.OnClearLocation = func() {
 		a.setUnsavedChanges(true)
-		a.updateReminderUI(nil)
-	})
-	reminderContainer := container.NewHBox(a.reminderLabel, a.reminderButton, clearReminderButton)
-
-	// НОВЫЙ БЛОК: Вложения
-	a.attachButton = widget.NewButtonWithIcon("Прикрепить файл", theme.ContentAddIcon(), a.attachFile)
-	a.attachButton.Disable() // Изначально отключена, пока не выбрана заметка
-
-	a.attachmentsList = widget.NewList(
-		func() int {
-			selectedNote := a.getSelectedNote()
-			if selectedNote == nil {
-				return 0
-			}
-			return len(selectedNote.Attachments)
-		},
-		func() fyne.CanvasObject {
-			// Кастомный элемент списка для вложений
-			filenameLabel := widget.NewLabel("Имя файла")
-			sizeLabel := widget.NewLabel("Размер")
-			openButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), nil)
-			deleteButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
-			return container.NewHBox(filenameLabel, layout.NewSpacer(), sizeLabel, openButton, deleteButton)
-		},
-		func(i widget.ListItemID, o fyne.CanvasObject) {
-			selectedNote := a.getSelectedNote()
-			if selectedNote == nil || i >= len(selectedNote.Attachments) {
-				return
-			}
-			attachment := selectedNote.Attachments[i]
-
-			hbox := o.(*fyne.Container)
-			filenameLabel := hbox.Objects[0].(*widget.Label)
-			sizeLabel := hbox.Objects[2].(*widget.Label)
-			openButton := hbox.Objects[3].(*widget.Button)
-			deleteButton := hbox.Objects[4].(*widget.Button)
-
-			filenameLabel.SetText(attachment.Filename)
-			sizeLabel.SetText(formatBytes(attachment.SizeBytes))
-
-			// Обработчики кнопок для каждого элемента списка
-			openButton.OnTapped = func() {
-				a.openAttachment(attachment)
-			}
-			deleteButton.OnTapped = func() {
-				a.deleteAttachment(attachment)
-			}
-		},
-	)
-	a.attachmentsContainer = container.NewBorder(
-		container.NewHBox(widget.NewLabel("Вложения:"), layout.NewSpacer(), a.attachButton),
-		nil,
-		nil,
-		nil,
-		container.NewScroll(a.attachmentsList),
-	)
-	// КОНЕЦ НОВОГО БЛОКА ВЛОЖЕНИЙ
+		a.updateLocationUI(nil)
+	}
+	a.NoteEditorView.OnSearchTags = a.store.SearchTags
+
+	a.linkPreviewView = NewLinkPreviewView()
+	a.linksView = NewLinksView()
+	a.linksView.OnOpen = a.openLink
+	a.linksView.OnCopy = func(rawURL string) { a.window.Clipboard().SetContent(rawURL) }
+
+	a.AttachmentsView = NewAttachmentsView(a.selectedAttachments)
+	a.AttachmentsView.OnAttach = a.attachFile
+	a.AttachmentsView.OnSketch = a.showSketch
+	a.AttachmentsView.OnAttachURL = a.attachFromURL
+	a.AttachmentsView.OnSaveAll = a.saveAllAttachments
+	a.AttachmentsView.OnScan = a.scanPage
+	a.AttachmentsView.OnOpen = a.openAttachment
+	a.AttachmentsView.OnDelete = a.deleteAttachment
+	a.AttachmentsView.OnShowText = a.showAttachmentText
+	a.AttachmentsView.OnCopy = a.copyAttachment
+	a.AttachmentsView.OnUpdate = a.updateAttachment
+	a.AttachmentsView.OnMove = a.moveAttachment
+
+	a.healthView = NewHealthView()
+	a.relatedView = NewRelatedView()
+	a.relatedView.OnOpen = a.OpenNoteByID
+	a.findReplaceView = NewFindReplaceView()
+	a.findReplaceView.OnFindNext = a.findNext
+	a.findReplaceView.OnReplace = a.replaceFound
+	a.findReplaceView.OnReplaceAll = a.replaceAllFound
+	a.readAloudView = NewReadAloudView()
+	a.readAloudView.OnPlayPause = a.toggleReadAloud
+	a.readAloudView.OnStop = a.stopReadAloud
+	a.readAloudView.OnSpeedChange = a.changeReadAloudSpeed
 
 	a.saveButton = widget.NewButtonWithIcon("Сохранить", theme.DocumentSaveIcon(), a.saveNote)
 	a.saveButton.Disable()
+	// Кнопка "Сохранить" следит за флагом изменений модели
+	a.dirty.AddListener(binding.NewDataListener(func() {
+		if a.hasUnsavedChanges() {
+			a.saveButton.Enable()
+		} else {
+			a.saveButton.Disable()
+		}
+	}))
 
 	a.deleteButton = widget.NewButtonWithIcon("Удалить", theme.DeleteIcon(), a.deleteNote)
 	a.deleteButton.Disable()
 
-	newNoteButton := widget.NewButtonWithIcon("Новая заметка", theme.ContentAddIcon(), a.newNote)
-	exportButton := widget.NewButtonWithIcon("Экспорт", theme.DownloadIcon(), a.exportNote)
-	importButton := widget.NewButtonWithIcon("Импорт", theme.UploadIcon(), a.importNote)
-	aboutButton := widget.NewButtonWithIcon("О программе", theme.InfoIcon(), a.showAboutDialog)
+	// Панель кнопок действий; набор кнопок настраивается, остальные команды — в главном меню (см. setupMainMenu)
+	actionButtons := a.makeToolbar()
 
-	// Контейнер для кнопок действий
-	actionButtons := container.New(layout.NewGridLayoutWithColumns(4),
-		newNoteButton, a.saveButton, a.deleteButton, exportButton,
-		importButton, aboutButton,
-	)
+	// Вложения и ссылки заметки на соседних вкладках
+	a.attachmentTabs = container.NewAppTabs(container.NewTabItem("Вложения", a.attachmentsContainer), a.linksView.tab)
 
 	// Контейнер для деталей заметки
 	noteDetailContainer := container.NewBorder(
 		container.NewVBox(
-			a.titleEntry,
-			a.tagsEntry,
-			reminderContainer,
+			a.NoteEditorView.header,
 			widget.NewSeparator(),
-			a.attachmentsContainer, // <-- ДОБАВЛЕНО: Контейнер для вложений
+			a.attachmentTabs,
+			a.relatedView.content,
 			widget.NewSeparator(),
-		), // Заголовок, теги, напоминание, вложения сверху
+		), // Заголовок, теги, напоминание, вложения и похожие заметки сверху
 		container.NewVBox(
-			a.charCountLabel,
+			container.NewHBox(a.charCountLabel, a.limitLabel, a.matchBar, a.readAloudView.content, layout.NewSpacer(), a.healthView.content),
 			actionButtons,
-		), // Счетчик символов и кнопки снизу
+		), // Счетчик символов, состояние БД и кнопки снизу
 		nil,
 		nil,
-		container.NewScroll(a.contentEntry), // Содержимое с прокруткой в центре
+		container.NewBorder(a.findReplaceView.content, a.linkPreviewView.content, nil, nil, a.contentArea), // Поиск и замена над содержимым, карточки ссылок под ним
 	)
 
-	// Горизонтальное разделение для списка и деталей
-	split := container.NewHSplit(leftPanel, noteDetailContainer)
-	split.SetOffset(0.25) // Список занимает 25% ширины
+	// Область деталей: основной редактор и, при необходимости, правая панель
+	a.noteTabs = newNoteTabs(a, noteDetailContainer)
+	a.noteDetail = a.noteTabs.content
+	a.detailArea = container.NewStack(a.noteDetail)
+
+	// Список и детали рядом, а в узком окне — по очереди
+	a.layout = newResponsiveSplit(a.NoteListView.content, a.detailArea, a.newNote)
 
-	return split
+	return a.layout
 }
 
-// setUnsavedChanges устанавливает флаг несохраненных изменений и обновляет состояние кнопки "Сохранить"
+// setUnsavedChanges устанавливает флаг несохраненных изменений в модели
 func (a *NoteApp) setUnsavedChanges(changed bool) {
-	a.hasUnsavedChanges = changed
-	if changed {
-		a.saveButton.Enable()
-	} else {
-		a.saveButton.Disable()
+	a.dirty.Set(changed) // Кнопка "Сохранить" обновится через привязку
+	if a.noteTabs != nil { // Заголовок вкладки меняется и без смены флага
+		a.noteTabs.refreshMain()
 	}
 }
 
@@ -306,103 +319,19 @@ func (a *NoteApp) setUnsavedChanges(changed bool) {
 func (a *NoteApp) loadNotes() {
 	notes, err := a.store.GetAllNotes()
 	if err != nil {
-		dialog.ShowError(fmt.Errorf("не удалось загрузить заметки: %w", err), a.window)
+		a.showStoreError("не удалось загрузить заметки", err)
 		log.Printf("Ошибка при загрузке заметок: %v", err)
 		return
 	}
 	a.allNotes = notes
-	a.filterNotes()             // Применяем текущий фильтр
-	a.sortNotes(a.sortSelect.Selected) // Применяем текущую сортировку
-	a.noteList.Refresh()
+	a.loadTagStyles()
+	a.loadSnippets()
+	a.refreshFavoritesBar()
+	a.refreshTagTree()
+	a.filterNotes() // Применяем текущий фильтр и сортировку
 	log.Println("Заметки загружены и отфильтрованы/отсортированы")
 }
 
-// filterNotes фильтрует заметки на основе поискового запроса
-func (a *NoteApp) filterNotes() {
-	query := strings.ToLower(a.searchEntry.Text)
-	if query == "" {
-		a.filteredNotes = a.allNotes
-	} else {
-		a.filteredNotes = []models.Note{}
-		for _, note := range a.allNotes {
-			if strings.Contains(strings.ToLower(note.Title), query) ||
-				strings.Contains(strings.ToLower(note.Content), query) ||
-				strings.Contains(strings.ToLower(strings.Join(note.Tags, ",")), query) { // Поиск по тегам
-				a.filteredNotes = append(a.filteredNotes, note)
-			}
-		}
-	}
-	a.sortNotes(a.sortSelect.Selected) // Пересортируем после фильтрации
-	a.noteList.Refresh()
-	// Если выбранная заметка больше не в отфильтрованном списке, сбросить выбор
-	if a.selectedNoteIndex != -1 {
-		selectedNote := a.getSelectedNote() // Получаем текущую выбранную заметку
-		found := false
-		for i, note := range a.filteredNotes {
-			if selectedNote != nil && note.ID == selectedNote.ID {
-				a.selectedNoteIndex = i // Обновляем индекс, если заметка все еще в списке
-				a.noteList.Select(i)
-				found = true
-				break
-			}
-		}
-		if !found {
-			a.noteList.UnselectAll()
-			a.selectedNoteIndex = -1
-			a.newNote() // Очищаем поля, если выбранная заметка пропала
-		}
-	}
-}
-
-// sortNotes сортирует filteredNotes на основе выбранного критерия
-func (a *NoteApp) sortNotes(criteria string) {
-	switch criteria {
-	case "По дате создания (новые)":
-		sort.Slice(a.filteredNotes, func(i, j int) bool {
-			return a.filteredNotes[i].CreatedAt.After(a.filteredNotes[j].CreatedAt)
-		})
-	case "По дате создания (старые)":
-		sort.Slice(a.filteredNotes, func(i, j int) bool {
-			return a.filteredNotes[i].CreatedAt.Before(a.filteredNotes[j].CreatedAt)
-		})
-	case "По дате обновления (новые)":
-		sort.Slice(a.filteredNotes, func(i, j int) bool {
-			return a.filteredNotes[i].UpdatedAt.After(a.filteredNotes[j].UpdatedAt)
-		})
-	case "По дате обновления (старые)":
-		sort.Slice(a.filteredNotes, func(i, j int) bool {
-			return a.filteredNotes[i].UpdatedAt.Before(a.filteredNotes[j].UpdatedAt)
-		})
-	case "По заголовку (А-Я)":
-		sort.Slice(a.filteredNotes, func(i, j int) bool {
-			return strings.ToLower(a.filteredNotes[i].Title) < strings.ToLower(a.filteredNotes[j].Title)
-		})
-	case "По заголовку (Я-А)":
-		sort.Slice(a.filteredNotes, func(i, j int) bool {
-			return strings.ToLower(a.filteredNotes[i].Title) > strings.ToLower(a.filteredNotes[j].Title)
-		})
-	}
-}
-
-// getSelectedNote возвращает выбранную заметку или nil
-func (a *NoteApp) getSelectedNote() *models.Note {
-	if a.selectedNoteIndex == -1 || a.selectedNoteIndex >= len(a.filteredNotes) {
-		return nil
-	}
-	return &a.filteredNotes[a.selectedNoteIndex]
-}
-
-// onNoteSelected вызывается при выборе заметки из списка
-func (a *NoteApp) onNoteSelected(id widget.ListItemID) {
-	if a.hasUnsavedChanges {
-		a.showUnsavedChangesDialog(func() {
-			a.doSelectNote(id)
-		})
-	} else {
-		a.doSelectNote(id)
-	}
-}
-
 // doSelectNote выполняет фактический выбор заметки после проверки изменений
 func (a *NoteApp) doSelectNote(id widget.ListItemID) {
 	if id < 0 || id >= len(a.filteredNotes) {
@@ -412,7 +341,7 @@ func (a *NoteApp) doSelectNote(id widget.ListItemID) {
 	// Загружаем заметку с вложениями из БД
 	selectedNoteFromDB, err := a.store.GetNoteByID(a.filteredNotes[id].ID)
 	if err != nil {
-		dialog.ShowError(fmt.Errorf("не удалось загрузить детали заметки: %w", err), a.window)
+		a.showStoreError("не удалось загрузить детали заметки", err)
 		log.Printf("Ошибка при загрузке деталей заметки: %v", err)
 		return
 	}
@@ -423,24 +352,45 @@ func (a *NoteApp) doSelectNote(id widget.ListItemID) {
 	selectedNote := a.filteredNotes[id] // Используем обновленную заметку
 
 	a.titleEntry.SetText(selectedNote.Title)
-	a.contentEntry.SetText(selectedNote.Content)
+	a.showContent(selectedNote.Content)
+	a.updateLinks()
 	a.tagsEntry.SetText(strings.Join(selectedNote.Tags, ", "))
+	if selectedNote.ID != a.suggestedFor { // Новую заметку после сохранения выделяют заново, предложения остаются
+		a.clearTagSuggestions()
+	}
 	a.updateReminderUI(selectedNote.ReminderAt)
+	a.updateDueUI(selectedNote.DueAt)
+	a.updateSourceLink(selectedNote.SourceURL)
+	a.updateLocationUI(selectedNote.Location)
+	a.updateFavoriteButton(&selectedNote)
+	a.updatePriorityUI(selectedNote.Priority)
 
 	a.setUnsavedChanges(false) // Сброс флага после загрузки
+	a.loadedUpdatedAt = selectedNote.UpdatedAt
+	if selectedNote.ContentTruncated {
+		log.Printf("Заметка ID %d открыта без связи с БД, в офлайн-кэше только ее начало", selectedNote.ID)
+		dialog.ShowInformation("Заметка загружена не полностью",
+			"Без связи с БД доступно только начало этой большой заметки. Изменить ее можно будет после восстановления связи.", a.window)
+	}
 	a.deleteButton.Enable()
 	a.attachButton.Enable() // Включаем кнопку "Прикрепить файл"
+	a.sketchButton.Enable()
+	a.urlButton.Enable()
+	a.scanButton.Enable()
 	a.updateCharCount()     // Обновить счетчик для выбранной заметки
+	a.updateMatches()
 	a.attachmentsList.Refresh() // Обновляем список вложений
-	log.Printf("Выбрана заметка: %s (ID: %d)", selectedNote.Title, selectedNote.ID)
+	a.updateRelated()
+	log.Printf("Выбрана заметка: %s (ID: %d)", selectedNote.LogTitle(), selectedNote.ID)
 
 	// Обновляем визуальное выделение
-	a.noteList.Refresh()
+	a.refreshList()
+	a.layout.showNote() // В узком окне вместо списка открывается заметка
 }
 
 // newNote очищает поля для создания новой заметки
 func (a *NoteApp) newNote() {
-	if a.hasUnsavedChanges {
+	if a.hasUnsavedChanges() {
 		a.showUnsavedChangesDialog(func() {
 			a.doNewNote()
 		})
@@ -453,38 +403,61 @@ func (a *NoteApp) newNote() {
 func (a *NoteApp) doNewNote() {
 	a.selectedNoteIndex = -1 // Указываем, что это новая заметка
 	a.titleEntry.SetText("")
-	a.contentEntry.SetText("")
+	a.showContent("")
+	a.updateLinks()
 	a.tagsEntry.SetText("")
+	a.clearTagSuggestions()
 	a.updateReminderUI(nil) // Сброс напоминания
+	a.updateDueUI(nil)
+	a.updateSourceLink("")
+	a.updateLocationUI(nil)
+	a.updateFavoriteButton(nil)
+	a.updatePriorityUI(models.PriorityNone)
 	a.setUnsavedChanges(false)
 	a.deleteButton.Disable()
 	a.attachButton.Disable() // Отключаем кнопку "Прикрепить файл" для новой заметки (пока не сохранена)
+	a.sketchButton.Disable()
+	a.urlButton.Disable()
+	a.scanButton.Disable()
 	a.noteList.UnselectAll() // Снимаем выделение со списка
 	a.updateCharCount()      // Обновить счетчик для пустой заметки
+	a.updateMatches()
 	// Очищаем список вложений для новой/несвязанной заметки
 	if a.attachmentsList != nil {
 		a.attachmentsList.Refresh()
 	}
+	a.updateRelated()
 	log.Println("Подготовлена форма для новой заметки")
-	a.noteList.Refresh() // Обновляем список, чтобы снять выделение
+	a.refreshList() // Обновляем список, чтобы снять выделение
 }
 
-// saveNote сохраняет или обновляет заметку
+// saveNote сохраняет заметку; если ее изменили в другом редакторе после открытия, сначала спрашивает
 func (a *NoteApp) saveNote() {
-	title := a.titleEntry.Text
-	content := a.contentEntry.Text
-	tags := parseTags(a.tagsEntry.Text)
-	var reminderAt *time.Time
-	// Проверяем, установлено ли напоминание, и пытаемся его распарсить
-	if a.reminderLabel.Text != "Напоминание: Не установлено" {
-		// Формат, используемый в updateReminderUI
-		t, err := time.Parse("Напоминание: 02.01.2006 15:04", a.reminderLabel.Text)
-		if err == nil {
-			reminderAt = &t
-		} else {
-			log.Printf("Ошибка парсинга напоминания из UI: %v", err)
-		}
+	a.saveNoteThen(func() {})
+}
+
+// saveNoteThen сохраняет заметку и вызывает then. Если пользователь отказался заменять изменения
+// из другого редактора, then не вызывается: заметка остается открытой с несохраненными изменениями.
+func (a *NoteApp) saveNoteThen(then func()) {
+	selected := a.getSelectedNote()
+	if selected == nil {
+		a.doSaveNote()
+		then()
+		return
 	}
+	a.confirmOverwrite(selected.ID, a.loadedUpdatedAt, a.window, func() {
+		a.doSaveNote()
+		then()
+	})
+}
+
+// doSaveNote сохраняет или обновляет заметку
+func (a *NoteApp) doSaveNote() {
+	// Значения берем из модели: поля редактора привязаны к ней, а напоминание хранится в currentReminder
+	title := a.editedTitle()
+	content := a.contentText()
+	tags := a.editedTags()
+	reminderAt := a.currentReminder
 
 	if title == "" {
 		dialog.ShowInformation("Ошибка", "Заголовок заметки не может быть пустым.", a.window)
@@ -499,11 +472,14 @@ func (a *NoteApp) saveNote() {
 			Content:    content,
 			Tags:       tags,
 			ReminderAt: reminderAt,
+			DueAt:      a.currentDue,
+			Location:   a.currentLocation,
+			Priority:   a.currentPriority,
 		}
 		err = a.store.CreateNote(note)
 		currentNote = note
 		if err == nil {
-			log.Printf("Создана новая заметка: %s (ID: %d)", note.Title, note.ID)
+			log.Printf("Создана новая заметка: %s (ID: %d)", note.LogTitle(), note.ID)
 		}
 	} else { // Обновление существующей
 		note := a.getSelectedNote()
@@ -511,37 +487,36 @@ func (a *NoteApp) saveNote() {
 		note.Content = content
 		note.Tags = tags
 		note.ReminderAt = reminderAt
+		note.DueAt = a.currentDue
+		note.Location = a.currentLocation
+		note.Priority = a.currentPriority
 		err = a.store.UpdateNote(note)
 		currentNote = note
 		if err == nil {
-			log.Printf("Обновлена заметка: %s (ID: %d)", note.Title, note.ID)
+			log.Printf("Обновлена заметка: %s (ID: %d)", note.LogTitle(), note.ID)
 		}
 	}
 
 	if err != nil {
-		dialog.ShowError(fmt.Errorf("не удалось сохранить заметку: %w", err), a.window)
+		a.showStoreError("не удалось сохранить заметку", err)
 		log.Printf("Ошибка при сохранении заметки: %v", err)
 		return
 	}
 
 	dialog.ShowInformation("Успех", "Заметка успешно сохранена!", a.window)
 	a.setUnsavedChanges(false) // Сброс флага после сохранения
+	a.loadedUpdatedAt = currentNote.UpdatedAt
 	a.deleteButton.Enable()
 	a.attachButton.Enable() // Включаем кнопку "Прикрепить файл" после сохранения
-	a.loadNotes()           // Перезагружаем список, чтобы обновить/добавить заметку
-	// Попытка снова выбрать заметку после обновления списка
-	if currentNote != nil {
-		for i, note := range a.filteredNotes {
-			if note.ID == currentNote.ID {
-				a.noteList.Select(i)
-				// Убедимся, что selectedNoteIndex обновлен корректно
-				a.selectedNoteIndex = i
-				// Перезагружаем вложения для выбранной заметки после сохранения
-				a.doSelectNote(i) // Это обновит вложения
-				break
-			}
-		}
-	}
+	a.sketchButton.Enable()
+	a.urlButton.Enable()
+	a.scanButton.Enable()
+	// Список обновится по событию хранилища; новую заметку выделяем, когда она в нем появится
+	if a.getSelectedNote() == nil {
+		a.selectWhenListed(currentNote.ID)
+	}
+	a.updateLinks() // Список ссылок следует за сохраненным содержимым
+	a.suggestTags(*currentNote)
 }
 
 // deleteNote удаляет текущую заметку
@@ -557,41 +532,26 @@ func (a *NoteApp) deleteNote() {
 			if confirmed {
 				err := a.store.DeleteNote(selectedNote.ID)
 				if err != nil {
-					dialog.ShowError(fmt.Errorf("не удалось удалить заметку: %w", err), a.window)
+					a.showStoreError("не удалось удалить заметку", err)
 					log.Printf("Ошибка при удалении заметки: %v", err)
 					return
 				}
 				dialog.ShowInformation("Успех", "Заметка успешно удалена.", a.window)
 				log.Printf("Удалена заметка с ID: %d", selectedNote.ID)
-				a.loadNotes() // Перезагружаем список
-				a.newNote()   // Переходим к созданию новой заметки
+				a.doNewNote() // Переходим к созданию новой заметки, список обновится по событию
 			}
 		}, a.window)
 }
 
-// updateCharCount обновляет счетчик символов и слов
-func (a *NoteApp) updateCharCount() {
-	content := a.contentEntry.Text
-	chars := len(content)
-	words := len(strings.Fields(content)) // Разделяем по пробелам и считаем
-	a.charCountLabel.SetText(fmt.Sprintf("Символов: %d | Слов: %d", chars, words))
-}
-
 // showUnsavedChangesDialog показывает диалог подтверждения несохраненных изменений
 func (a *NoteApp) showUnsavedChangesDialog(onContinue func()) {
 	dialog.ShowConfirm("Несохраненные изменения",
 		"У вас есть несохраненные изменения. Сохранить их?",
 		func(save bool) {
 			if save {
-				a.saveNote() // Попытаться сохранить
-				// Если сохранение успешно, тогда продолжить
-				// Мы не можем гарантировать, что saveNote() завершится до того, как этот коллбэк вернется.
-				// Лучше вызвать onContinue() после успешного сохранения внутри saveNote(),
-				// или просто продолжить, если пользователь выбрал "Сохранить"
-				// Для простоты сейчас, предполагаем, что если пользователь выбрал сохранить,
-				// он хочет продолжить, даже если сохранение не удалось (появится ошибка).
-				// В более сложном приложении здесь нужна более сложная логика.
-				onContinue()
+				// Продолжаем и при ошибке сохранения (она будет показана), но не после отказа
+				// заменить изменения из другого редактора
+				a.saveNoteThen(onContinue)
 			} else {
 				a.setUnsavedChanges(false) // Отменить изменения
 				onContinue()
@@ -601,25 +561,32 @@ func (a *NoteApp) showUnsavedChangesDialog(onContinue func()) {
 
 // onWindowClosed обрабатывает закрытие окна
 func (a *NoteApp) onWindowClosed() {
-	if a.hasUnsavedChanges {
+	if nw := a.dirtyNoteWindow(); nw != nil { // Окна заметок закроются вместе с основным
+		dialog.ShowInformation("Несохраненные изменения",
+			"Заметка '"+nw.pane.note.Title+"' в отдельном окне изменена. Сохраните или закройте ее.", a.window)
+		nw.window.RequestFocus()
+		return
+	}
+	a.saveWindowState()
+	if a.hasUnsavedChanges() {
 		a.showUnsavedChangesDialog(func() {
 			// Если пользователь выбрал не сохранять или сохранил,
 			// то закрываем приложение
-			if !a.hasUnsavedChanges { // Если флаг сброшен, значит, сохранение прошло успешно или отменено
-				a.window.Close()
+			if !a.hasUnsavedChanges() { // Если флаг сброшен, значит, сохранение прошло успешно или отменено
+				a.closeWhenIdle()
 			}
 		})
 	} else {
-		a.window.Close()
+		a.closeWhenIdle()
 	}
 }
 
-// parseTags преобразует строку тегов в срез строк
+// parseTags преобразует строку тегов в срез строк; вложенные теги записываются через "/"
 func parseTags(tagString string) []string {
 	tags := strings.Split(tagString, ",")
 	cleanTags := []string{}
 	for _, tag := range tags {
-		trimmedTag := strings.TrimSpace(tag)
+		trimmedTag := models.NormalizeTag(tag)
 		if trimmedTag != "" {
 			cleanTags = append(cleanTags, trimmedTag)
 		}
@@ -627,65 +594,6 @@ func parseTags(tagString string) []string {
 	return cleanTags
 }
 
-// updateReminderUI обновляет отображение напоминания
-func (a *NoteApp) updateReminderUI(t *time.Time) {
-	if t == nil {
-		a.reminderLabel.SetText("Напоминание: Не установлено")
-		a.currentReminder = nil
-	} else {
-		a.reminderLabel.SetText(fmt.Sprintf("Напоминание: %s", t.Format("02.01.2006 15:04")))
-		a.currentReminder = t
-	}
-}
-
-// setReminderDialog открывает диалог для установки напоминания
-func (a *NoteApp) setReminderDialog() {
-	// Инициализируем текущее напоминание для диалога
-	initialTime := time.Now()
-	if a.currentReminder != nil {
-		initialTime = *a.currentReminder
-	}
-
-	a.reminderDateEntry = widget.NewEntry()
-	a.reminderDateEntry.SetPlaceHolder("ДД.ММ.ГГГГ")
-	a.reminderDateEntry.SetText(initialTime.Format("02.01.2006"))
-
-	a.reminderTimeEntry = widget.NewEntry()
-	a.reminderTimeEntry.SetPlaceHolder("ЧЧ:ММ")
-	a.reminderTimeEntry.SetText(initialTime.Format("15:04"))
-
-	// Кнопка для открытия календаря
-	calendarButton := widget.NewButton("Выбрать дату", func() {
-		dialog.ShowCustom("Выберите дату", "Закрыть",
-			widget.NewCalendar(initialTime, func(t time.Time) {
-				a.reminderDateEntry.SetText(t.Format("02.01.2006"))
-			}), a.window)
-	})
-
-	content := container.NewVBox(
-		widget.NewLabel("Дата:"),
-		container.NewHBox(a.reminderDateEntry, calendarButton),
-		widget.NewLabel("Время (ЧЧ:ММ):"),
-		a.reminderTimeEntry,
-	)
-
-	dialog.ShowCustomConfirm("Установить напоминание", "Установить", "Отмена", content, func(ok bool) {
-		if ok {
-			dateStr := a.reminderDateEntry.Text
-			timeStr := a.reminderTimeEntry.Text
-			combinedStr := fmt.Sprintf("%s %s", dateStr, timeStr)
-
-			parsedTime, err := time.Parse("02.01.2006 15:04", combinedStr)
-			if err != nil {
-				dialog.ShowError(fmt.Errorf("неверный формат даты или времени. Используйте ДД.ММ.ГГГГ ЧЧ:ММ: %w", err), a.window)
-				return
-			}
-			a.updateReminderUI(&parsedTime)
-			a.setUnsavedChanges(true)
-		}
-	}, a.window)
-}
-
 // exportNote экспортирует выбранную заметку или все заметки
 func (a *NoteApp) exportNote() {
 	dialog.ShowConfirm("Экспорт заметок",
@@ -693,16 +601,18 @@ func (a *NoteApp) exportNote() {
 		func(exportAll bool) {
 			var notesToExport []models.Note
 			if exportAll {
-				notesToExport = a.allNotes // Экспортируем все заметки
-				// Для экспорта всех заметок, нужно загрузить их вложения
-				for i, note := range notesToExport {
-					attachments, err := a.store.GetAttachmentsByNoteID(note.ID)
-					if err != nil {
-						log.Printf("Ошибка при загрузке вложений для заметки ID %d при экспорте: %v", note.ID, err)
-						// Продолжаем, но без вложений для этой заметки
-					}
-					notesToExport[i].Attachments = attachments
+				// Заметки загружаются вместе с вложениями одним пакетом, а не запросом на каждую заметку
+				ids := make([]int, len(a.allNotes))
+				for i, note := range a.allNotes {
+					ids[i] = note.ID
+				}
+				notes, err := a.store.GetNotesWithAttachments(ids)
+				if err != nil {
+					log.Printf("Ошибка при загрузке вложений заметок при экспорте: %v", err)
+					// Продолжаем, но без вложений
+					notes = append([]models.Note(nil), a.allNotes...)
 				}
+				notesToExport = notes
 			} else {
 				selectedNote := a.getSelectedNote()
 				if selectedNote == nil {
@@ -711,31 +621,9 @@ func (a *NoteApp) exportNote() {
 				}
 				notesToExport = []models.Note{*selectedNote}
 			}
-
-			dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
-				if err != nil {
-					dialog.ShowError(err, a.window)
-					return
-				}
-				if writer == nil { // Пользователь отменил
-					return
-				}
-				defer writer.Close()
-
-				// Простой формат JSON для экспорта
-				data, err := json.MarshalIndent(notesToExport, "", "  ")
-				if err != nil {
-					dialog.ShowError(fmt.Errorf("ошибка при форматировании JSON: %w", err), a.window)
-					return
-				}
-
-				_, err = writer.Write(data)
-				if err != nil {
-					dialog.ShowError(fmt.Errorf("ошибка при записи файла: %w", err), a.window)
-					return
-				}
-				dialog.ShowInformation("Экспорт", "Заметки успешно экспортированы!", a.window)
-			}, a.window)
+			a.confirmSensitive("Экспорт заметок", notesToExport, func(redact bool) {
+				a.saveExport(notesToExport, exportAll, redact)
+			})
 		}, a.window)
 }
 
@@ -757,87 +645,22 @@ func (a *NoteApp) importNote() {
 			return
 		}
 
-		var importedNotes []models.Note
-		err = json.Unmarshal(data, &importedNotes)
+		// Поддерживаются и старые файлы — просто массив заметок
+		export, err := models.ParseExport(data)
 		if err != nil {
-			dialog.ShowError(fmt.Errorf("ошибка при парсинге JSON: %w", err), a.window)
+			dialog.ShowError(err, a.window)
 			return
 		}
 
-		if len(importedNotes) == 0 {
+		if export.IsEmpty() {
 			dialog.ShowInformation("Импорт", "В файле не найдено заметок для импорта.", a.window)
 			return
 		}
 
-		dialog.ShowConfirm("Импорт заметок",
-			fmt.Sprintf("Вы уверены, что хотите импортировать %d заметки(ок)? Существующие заметки с такими же ID будут перезаписаны, а новые добавлены. Вложения будут импортированы, если файлы существуют.", len(importedNotes)),
-			func(confirmed bool) {
-				if !confirmed {
-					return
-				}
-
-				importedCount := 0
-				for _, note := range importedNotes {
-					// Попытаемся обновить, если заметка с таким ID уже существует
-					existingNote, getErr := a.store.GetNoteByID(note.ID)
-					if getErr == nil && existingNote != nil {
-						// Заметка существует, обновляем
-						// Сохраняем оригинальные даты создания/обновления из БД, если они не заданы в импортированной заметке
-						if note.CreatedAt.IsZero() {
-							note.CreatedAt = existingNote.CreatedAt
-						}
-						// Fyne DatePicker/TimePicker не возвращают часовой пояс, поэтому убедимся, что время в UTC, если это важно
-						if note.ReminderAt != nil && note.ReminderAt.Location().String() == "Local" {
-							utcTime := note.ReminderAt.In(time.UTC)
-							note.ReminderAt = &utcTime
-						}
-
-						if err := a.store.UpdateNote(&note); err != nil {
-							log.Printf("Ошибка при обновлении заметки ID %d: %v", note.ID, err)
-							continue
-						}
-					} else {
-						// Заметка не существует или ошибка при получении, создаем новую
-						// Обнуляем ID, чтобы БД сгенерировала новый
-						note.ID = 0
-						// Fyne DatePicker/TimePicker не возвращают часовой пояс, поэтому убедимся, что время в UTC, если это важно
-						if note.ReminderAt != nil && note.ReminderAt.Location().String() == "Local" {
-							utcTime := note.ReminderAt.In(time.UTC)
-							note.ReminderAt = &utcTime
-						}
-						if err := a.store.CreateNote(&note); err != nil {
-							log.Printf("Ошибка при создании заметки '%s': %v", note.Title, err)
-							continue
-						}
-					}
-					importedCount++
-
-					// Импортируем вложения для этой заметки
-					for _, attach := range note.Attachments {
-						// Здесь мы предполагаем, что файлы вложений должны быть скопированы вручную
-						// или быть доступны по исходным путям.
-						// Для реального импорта, нужно будет скопировать файлы и обновить filepath.
-						// Сейчас просто создаем запись в БД, если файл существует по указанному пути.
-						if _, err := os.Stat(attach.Filepath); err == nil {
-							// Файл существует, создаем запись в БД
-							attach.NoteID = note.ID // Привязываем к только что созданной/обновленной заметке
-							if err := a.store.CreateAttachment(&attach); err != nil {
-								log.Printf("Ошибка при импорте вложения '%s' для заметки ID %d: %v", attach.Filename, note.ID, err)
-							}
-						} else {
-							log.Printf("Файл вложения '%s' не найден по пути '%s', запись не импортирована.", attach.Filename, attach.Filepath)
-						}
-					}
-				}
-
-				if importedCount > 0 {
-					dialog.ShowInformation("Импорт", fmt.Sprintf("Успешно импортировано %d заметок.", importedCount), a.window)
-					a.loadNotes() // Перезагружаем список после импорта
-					a.newNote()
-				} else {
-					dialog.ShowError(fmt.Errorf("не удалось импортировать ни одной заметки"), a.window)
-				}
-			}, a.window)
+		// Пользователь выбирает, какие заметки восстановить
+		a.chooseRestore(export, func(chosen *models.Export) {
+			a.startImport(chosen, reader.URI().Name())
+		})
 	}, a.window)
 }
 
@@ -857,141 +680,6 @@ func (a *NoteApp) showAboutDialog() {
 
 // НОВЫЕ ФУНКЦИИ ДЛЯ ВЛОЖЕНИЙ
 
-// attachFile открывает диалог выбора файла и прикрепляет его к текущей заметке
-func (a *NoteApp) attachFile() {
-	selectedNote := a.getSelectedNote()
-	if selectedNote == nil {
-		dialog.ShowInformation("Ошибка", "Сначала выберите или сохраните заметку, чтобы прикрепить к ней файл.", a.window)
-		return
-	}
-
-	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
-		if err != nil {
-			dialog.ShowError(err, a.window)
-			return
-		}
-		if reader == nil { // Пользователь отменил выбор
-			return
-		}
-		defer reader.Close()
-
-		originalFilename := filepath.Base(reader.URI().Path())
-		// Генерируем уникальное имя файла для хранения, чтобы избежать коллизий
-		uniqueFilename := fmt.Sprintf("%d_%s_%s", selectedNote.ID, time.Now().Format("20060102150405"), originalFilename)
-		destPath := filepath.Join(a.attachmentsDirPath, uniqueFilename)
 
-		// Копируем файл
-		destFile, err := os.Create(destPath)
-		if err != nil {
-			dialog.ShowError(fmt.Errorf("не удалось создать файл вложения: %w", err), a.window)
-			return
-		}
-		defer destFile.Close()
-
-		fileContent, err := ioutil.ReadAll(reader)
-		if err != nil {
-			dialog.ShowError(fmt.Errorf("не удалось прочитать файл: %w", err), a.window)
-			return
-		}
-		_, err = destFile.Write(fileContent)
-		if err != nil {
-			dialog.ShowError(fmt.Errorf("не удалось записать файл: %w", err), a.window)
-			return
-		}
-
-		// Получаем MIME-тип
-		mimeType := mime.TypeByExtension(filepath.Ext(originalFilename))
-		if mimeType == "" {
-			mimeType = "application/octet-stream" // Дефолтный тип, если не удалось определить
-		}
-
-		// Создаем запись в БД
-		attachment := &models.Attachment{
-			NoteID:    selectedNote.ID,
-			Filename:  originalFilename,
-			Filepath:  destPath,
-			MimeType:  mimeType,
-			SizeBytes: int64(len(fileContent)),
-		}
-
-		err = a.store.CreateAttachment(attachment)
-		if err != nil {
-			// Если запись в БД не удалась, пытаемся удалить скопированный файл
-			if removeErr := os.Remove(destPath); removeErr != nil {
-				log.Printf("Ошибка: не удалось удалить скопированный файл '%s' после ошибки БД: %v", destPath, removeErr)
-			}
-			dialog.ShowError(fmt.Errorf("не удалось сохранить информацию о вложении в БД: %w", err), a.window)
-			return
-		}
 
-		dialog.ShowInformation("Успех", "Файл успешно прикреплен!", a.window)
-		log.Printf("Файл '%s' прикреплен к заметке ID %d, сохранен как '%s'", originalFilename, selectedNote.ID, destPath)
 
-		// Обновляем UI
-		a.doSelectNote(a.selectedNoteIndex) // Перезагружаем заметку, чтобы обновить список вложений
-	}, a.window)
-}
-
-// openAttachment открывает выбранный файл вложения с помощью системного приложения
-// openAttachment открывает выбранный файл вложения с помощью системного приложения
-func (a *NoteApp) openAttachment(attachment models.Attachment) {
-	cmd := ""
-	args := []string{}
-
-	// Определяем команду для открытия файла в зависимости от ОС
-	switch fyne.CurrentDevice() {
-	// case "windows": //винда
-	// 	cmd = "cmd"
-	// 	args = []string{"/c", "start", attachment.Filepath}
-	// case "darwin": //mac
-	// 	cmd = "open"
-	// 	args = []string{attachment.Filepath}
-	default: // Linux и другие Unix-подобные
-		cmd = "xdg-open"
-		args = []string{attachment.Filepath}
-	}
-
-	command := exec.Command(cmd, args...)
-	err := command.Start()
-	if err != nil {
-		dialog.ShowError(fmt.Errorf("не удалось открыть файл '%s': %w", attachment.Filename, err), a.window)
-		log.Printf("Ошибка при открытии файла '%s' (%s): %v", attachment.Filename, attachment.Filepath, err)
-	} else {
-		log.Printf("Открыт файл '%s' (%s)", attachment.Filename, attachment.Filepath)
-	}
-}
-
-// deleteAttachment удаляет выбранное вложение
-func (a *NoteApp) deleteAttachment(attachment models.Attachment) {
-	dialog.ShowConfirm("Подтверждение удаления",
-		fmt.Sprintf("Вы уверены, что хотите удалить вложение '%s'? Файл будет удален с диска.", attachment.Filename),
-		func(confirmed bool) {
-			if confirmed {
-				err := a.store.DeleteAttachment(attachment.ID)
-				if err != nil {
-					dialog.ShowError(fmt.Errorf("не удалось удалить вложение: %w", err), a.window)
-					log.Printf("Ошибка при удалении вложения ID %d: %v", attachment.ID, err)
-					return
-				}
-				dialog.ShowInformation("Успех", "Вложение успешно удалено.", a.window)
-				log.Printf("Вложение ID %d ('%s') удалено.", attachment.ID, attachment.Filename)
-
-				// Обновляем UI
-				a.doSelectNote(a.selectedNoteIndex) // Перезагружаем заметку, чтобы обновить список вложений
-			}
-		}, a.window)
-}
-
-// formatBytes форматирует размер файла в удобочитаемый вид
-func formatBytes(b int64) string {
-	const unit = 1024
-	if b < unit {
-		return fmt.Sprintf("%d B", b)
-	}
-	div, exp := int64(unit), 0
-	for n := b / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
-}
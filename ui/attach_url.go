@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+	"GNote/storage"
+)
+
+// downloadClient загружает файлы для вложений; общего тайм-аута нет, большой файл можно прервать кнопкой "Отмена"
+var downloadClient = &http.Client{}
+
+// attachFromURL спрашивает адрес файла и прикрепляет его к выбранной заметке
+func (a *NoteApp) attachFromURL() {
+	selectedNote := a.getSelectedNote()
+	if selectedNote == nil {
+		dialog.ShowInformation("Ошибка", "Сначала выберите или сохраните заметку, чтобы прикрепить к ней файл.", a.window)
+		return
+	}
+	noteID := selectedNote.ID
+
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder("https://example.com/report.pdf")
+	dialog.ShowForm("Прикрепить по ссылке", "Загрузить", "Отмена",
+		[]*widget.FormItem{widget.NewFormItem("Адрес", urlEntry)},
+		func(ok bool) {
+			rawURL := strings.TrimSpace(urlEntry.Text)
+			if !ok || rawURL == "" {
+				return
+			}
+			u, err := url.Parse(rawURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				dialog.ShowError(fmt.Errorf("некорректный адрес файла '%s'", rawURL), a.window)
+				return
+			}
+			a.downloadAttachment(noteID, u.String())
+		}, a.window)
+}
+
+// downloadAttachment загружает файл в фоне с индикатором и сохраняет его как вложение заметки noteID
+func (a *NoteApp) downloadAttachment(noteID int, rawURL string) {
+	store, dir, inDatabase := a.store, a.attachmentsDirPath, a.attachmentsInDB // Профиль могут сменить во время загрузки
+	ctx, cancel := context.WithCancel(context.Background())
+
+	nameLabel := widget.NewLabel(truncateTitle(rawURL, 80))
+	bar := widget.NewProgressBar()
+	barInfinite := widget.NewProgressBarInfinite()
+	barInfinite.Hide()
+	progress := dialog.NewCustom("Загрузка вложения", "Отмена", container.NewVBox(nameLabel, bar, barInfinite), a.window)
+	progress.SetOnClosed(cancel)
+	progress.Show()
+
+	background.Go("загрузка "+rawURL, func() {
+		defer cancel()
+		attachment, err := download(ctx, noteID, rawURL, func(attachment *models.Attachment, size int64) (io.Writer, error) {
+			if size > maxAttachmentSize {
+				return nil, fmt.Errorf("файл слишком большой (%s), предельный размер вложения — %s",
+					formatBytes(size), formatBytes(maxAttachmentSize))
+			}
+			fyne.Do(func() {
+				nameLabel.SetText(attachment.Filename)
+				if size <= 0 { // Сервер не сообщил размер
+					bar.Hide()
+					barInfinite.Show()
+				} else {
+					bar.Max = float64(size)
+				}
+			})
+			return &progressWriter{limit: maxAttachmentSize, report: func(written int64) {
+				fyne.Do(func() { bar.SetValue(float64(written)) })
+			}}, nil
+		}, func(attachment *models.Attachment, body io.Reader) error {
+			return storage.SaveAttachmentStream(store, dir, inDatabase, attachment, body)
+		})
+
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				if ctx.Err() == context.Canceled {
+					log.Printf("Загрузка вложения '%s' отменена", rawURL)
+					return
+				}
+				dialog.ShowError(fmt.Errorf("не удалось прикрепить файл по ссылке: %w", err), a.window)
+				log.Printf("Ошибка при загрузке вложения '%s': %v", rawURL, err)
+				return
+			}
+			dialog.ShowInformation("Успех", "Файл успешно прикреплен!", a.window)
+			log.Printf("Файл '%s' загружен с '%s' и прикреплен к заметке ID %d (ID вложения: %d)",
+				attachment.Filename, rawURL, noteID, attachment.ID)
+		})
+		// Список вложений обновится по событию хранилища
+	})
+}
+
+// download выполняет GET-запрос и передает тело ответа в save. start вызывается, когда известны
+// имя, тип и размер файла (-1 — неизвестен); возвращенный им счетчик получает копию загружаемых данных.
+func download(ctx context.Context, noteID int, rawURL string, start func(*models.Attachment, int64) (io.Writer, error),
+	save func(*models.Attachment, io.Reader) error) (*models.Attachment, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "GNote")
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("сервер вернул статус %s", resp.Status)
+	}
+
+	attachment := &models.Attachment{
+		NoteID:    noteID,
+		Filename:  downloadFilename(resp),
+		SourceURL: rawURL,
+	}
+	// Серверы часто отдают файлы как application/octet-stream; тогда тип определяется по содержимому
+	body := bufio.NewReaderSize(resp.Body, storage.SniffLen)
+	attachment.MimeType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if attachment.MimeType == "" || attachment.MimeType == "application/octet-stream" {
+		head, _ := body.Peek(storage.SniffLen)
+		attachment.MimeType = storage.DetectMimeType(attachment.Filename, head)
+	}
+
+	counter, err := start(attachment, resp.ContentLength)
+	if err != nil {
+		return nil, err
+	}
+	if err := save(attachment, io.TeeReader(body, counter)); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// downloadFilename берет имя файла из Content-Disposition, иначе из пути адреса (после переадресаций), иначе из имени сайта
+func downloadFilename(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if name := filepath.Base(params["filename"]); name != "." && name != "/" && params["filename"] != "" {
+			return name
+		}
+	}
+	if name := path.Base(resp.Request.URL.Path); name != "." && name != "/" {
+		return name
+	}
+	return resp.Request.URL.Host
+}
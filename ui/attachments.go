@@ -0,0 +1,495 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+	"GNote/storage"
+)
+
+// AttachmentsView — список вложений выбранной заметки с кнопкой "Прикрепить файл"
+type AttachmentsView struct {
+	attachmentsContainer *fyne.Container // Контейнер для списка вложений и кнопки "Прикрепить"
+	attachmentsList      *widget.List    // Список отображаемых вложений
+	attachButton         *widget.Button  // Кнопка для прикрепления файла
+	sketchButton         *widget.Button  // Кнопка для прикрепления рисунка
+	urlButton            *widget.Button  // Кнопка для прикрепления файла по ссылке
+	scanButton           *widget.Button  // Кнопка для прикрепления скана
+	saveAllButton        *widget.Button  // Кнопка для сохранения всех вложений в каталог
+
+	OnAttach    func()
+	OnSketch    func() // Нарисовать и прикрепить рисунок
+	OnAttachURL func() // Загрузить и прикрепить файл по ссылке
+	OnScan      func() // Отсканировать и прикрепить страницу
+	OnSaveAll   func() // Сохранить все вложения заметки в каталог
+	OnOpen      func(attachment models.Attachment)
+	OnCopy      func(attachment models.Attachment) // Копирование изображения или пути к файлу в буфер обмена
+	OnDelete    func(attachment models.Attachment)
+	OnShowText  func(attachment models.Attachment)                               // Показ текста, извлеченного из вложения
+	OnUpdate    func(attachment models.Attachment, filename, description string) // Переименование и описание вложения
+	OnMove      func(attachment models.Attachment, delta int)                    // Перемещение вложения вверх (-1) или вниз (1)
+}
+
+// NewAttachmentsView создает список вложений; items возвращает вложения для отображения
+func NewAttachmentsView(items func() []models.Attachment) *AttachmentsView {
+	v := &AttachmentsView{}
+
+	v.attachButton = widget.NewButtonWithIcon("Прикрепить файл", theme.ContentAddIcon(), func() { v.OnAttach() })
+	v.attachButton.Disable() // Изначально отключена, пока не выбрана заметка
+	v.sketchButton = widget.NewButtonWithIcon("Рисунок", theme.DocumentCreateIcon(), func() { v.OnSketch() })
+	v.sketchButton.Disable()
+	v.urlButton = widget.NewButtonWithIcon("По ссылке", theme.DownloadIcon(), func() { v.OnAttachURL() })
+	v.urlButton.Disable()
+	v.scanButton = widget.NewButtonWithIcon("Сканировать", theme.DocumentIcon(), func() { v.OnScan() })
+	v.scanButton.Disable()
+	v.saveAllButton = widget.NewButtonWithIcon("Сохранить все…", theme.DocumentSaveIcon(), func() { v.OnSaveAll() })
+
+	v.attachmentsList = widget.NewList(
+		func() int {
+			return len(items())
+		},
+		func() fyne.CanvasObject {
+			// Кастомный элемент списка для вложений: имя и описание редактируются прямо в списке
+			filenameEntry := widget.NewEntry()
+			descriptionEntry := widget.NewEntry()
+			descriptionEntry.SetPlaceHolder("Описание")
+			sizeLabel := widget.NewLabel("Размер")
+			saveButton := widget.NewButtonWithIcon("", theme.ConfirmIcon(), nil) // Появляется, когда имя или описание изменены
+			upButton := widget.NewButtonWithIcon("", theme.MoveUpIcon(), nil)
+			downButton := widget.NewButtonWithIcon("", theme.MoveDownIcon(), nil)
+			textButton := widget.NewButtonWithIcon("", theme.DocumentIcon(), nil)    // Распознанный текст или расшифровка
+			copyButton := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), nil) // Изображение или путь к файлу в буфер обмена
+			openButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), nil)
+			deleteButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
+			return container.NewBorder(nil, nil, nil,
+				container.NewHBox(sizeLabel, saveButton, upButton, downButton, textButton, copyButton, openButton, deleteButton),
+				container.NewVBox(filenameEntry, descriptionEntry))
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			attachments := items()
+			if i >= len(attachments) {
+				return
+			}
+			attachment := attachments[i]
+
+			row := o.(*fyne.Container)
+			fields := row.Objects[0].(*fyne.Container)
+			filenameEntry := fields.Objects[0].(*widget.Entry)
+			descriptionEntry := fields.Objects[1].(*widget.Entry)
+			hbox := row.Objects[1].(*fyne.Container)
+			sizeLabel := hbox.Objects[0].(*widget.Label)
+			saveButton := hbox.Objects[1].(*widget.Button)
+			upButton := hbox.Objects[2].(*widget.Button)
+			downButton := hbox.Objects[3].(*widget.Button)
+			textButton := hbox.Objects[4].(*widget.Button)
+			copyButton := hbox.Objects[5].(*widget.Button)
+			openButton := hbox.Objects[6].(*widget.Button)
+			deleteButton := hbox.Objects[7].(*widget.Button)
+
+			// Обработчики снимаются до SetText, чтобы заполнение строки не считалось правкой
+			filenameEntry.OnChanged, descriptionEntry.OnChanged = nil, nil
+			filenameEntry.SetText(attachment.Filename)
+			descriptionEntry.SetText(attachment.Description)
+			sizeLabel.SetText(formatBytes(attachment.SizeBytes))
+			saveButton.Hide()
+
+			// Первое вложение нельзя поднять выше, последнее — опустить ниже
+			if i == 0 {
+				upButton.Disable()
+			} else {
+				upButton.Enable()
+			}
+			if i == len(attachments)-1 {
+				downButton.Disable()
+			} else {
+				downButton.Enable()
+			}
+			upButton.OnTapped = func() { v.OnMove(attachment, -1) }
+			downButton.OnTapped = func() { v.OnMove(attachment, 1) }
+
+			if attachment.Text != "" {
+				textButton.Show()
+			} else {
+				textButton.Hide()
+			}
+
+			changed := func(string) {
+				if filenameEntry.Text != attachment.Filename || descriptionEntry.Text != attachment.Description {
+					saveButton.Show()
+				} else {
+					saveButton.Hide()
+				}
+			}
+			save := func() {
+				if filenameEntry.Text != attachment.Filename || descriptionEntry.Text != attachment.Description {
+					v.OnUpdate(attachment, filenameEntry.Text, descriptionEntry.Text)
+				}
+			}
+			filenameEntry.OnChanged, descriptionEntry.OnChanged = changed, changed
+			filenameEntry.OnSubmitted = func(string) { save() }
+			descriptionEntry.OnSubmitted = func(string) { save() }
+			saveButton.OnTapped = save
+
+			// Обработчики кнопок для каждого элемента списка
+			textButton.OnTapped = func() {
+				v.OnShowText(attachment)
+			}
+			copyButton.OnTapped = func() {
+				v.OnCopy(attachment)
+			}
+			openButton.OnTapped = func() {
+				v.OnOpen(attachment)
+			}
+			deleteButton.OnTapped = func() {
+				v.OnDelete(attachment)
+			}
+		},
+	)
+	v.attachmentsContainer = container.NewBorder(
+		container.NewHBox(widget.NewLabel("Вложения:"), layout.NewSpacer(), v.saveAllButton, v.sketchButton, v.scanButton, v.urlButton, v.attachButton),
+		nil,
+		nil,
+		nil,
+		container.NewScroll(v.attachmentsList),
+	)
+	return v
+}
+
+// selectedAttachments возвращает вложения выбранной заметки
+func (a *NoteApp) selectedAttachments() []models.Attachment {
+	selectedNote := a.getSelectedNote()
+	if selectedNote == nil {
+		return nil
+	}
+	return selectedNote.Attachments
+}
+
+// attachFile открывает диалог выбора файла и прикрепляет его к текущей заметке
+func (a *NoteApp) attachFile() {
+	selectedNote := a.getSelectedNote()
+	if selectedNote == nil {
+		dialog.ShowInformation("Ошибка", "Сначала выберите или сохраните заметку, чтобы прикрепить к ней файл.", a.window)
+		return
+	}
+
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if reader == nil { // Пользователь отменил выбор
+			return
+		}
+		originalFilename := reader.URI().Name() // У content:// на Android нет пути в файловой системе, только имя
+		// Генерируем уникальное имя файла для хранения, чтобы избежать коллизий
+		uniqueFilename := fmt.Sprintf("%d_%s_%s", selectedNote.ID, time.Now().Format("20060102150405"), originalFilename)
+		destPath := filepath.Join(a.attachmentsDirPath, uniqueFilename)
+		store, inDatabase := a.store, a.attachmentsInDB // Профиль могут сменить во время копирования
+
+		// Размер проверяем до начала копирования; для источников без размера предел проверяется при копировании
+		size := int64(-1)
+		if reader.URI().Scheme() == "file" {
+			if info, statErr := os.Stat(reader.URI().Path()); statErr == nil {
+				size = info.Size()
+			}
+		}
+		if size > maxAttachmentSize {
+			reader.Close()
+			dialog.ShowError(fmt.Errorf("файл слишком большой (%s), предельный размер вложения — %s",
+				formatBytes(size), formatBytes(maxAttachmentSize)), a.window)
+			return
+		}
+
+		var bar fyne.CanvasObject = widget.NewProgressBarInfinite()
+		report := func(int64) {}
+		if size > 0 {
+			progressBar := widget.NewProgressBar()
+			progressBar.Max = float64(size)
+			bar = progressBar
+			report = func(written int64) { fyne.Do(func() { progressBar.SetValue(float64(written)) }) }
+		}
+		progress := dialog.NewCustomWithoutButtons("Копирование вложения",
+			container.NewVBox(widget.NewLabel(originalFilename), bar), a.window)
+		progress.Show()
+
+		// Копирование идет в фоне; при закрытии окна приложение дождется его завершения
+		background.Go("вложение "+originalFilename, func() {
+			defer reader.Close()
+			defer fyne.Do(progress.Hide)
+
+			// MIME-тип определяется по содержимому, чтобы файлы без расширения тоже распознавались;
+			// прочитанное начало файла остается в буфере и копируется вместе с остальным
+			buffered := bufio.NewReaderSize(reader, storage.SniffLen)
+			head, _ := buffered.Peek(storage.SniffLen) // Файл может быть короче; ошибка чтения проявится при копировании
+			attachment := &models.Attachment{
+				NoteID:   selectedNote.ID,
+				Filename: originalFilename,
+				MimeType: storage.DetectMimeType(originalFilename, head),
+			}
+
+			// Файл читается потоком, не загружаясь в память целиком; счетчик прерывает копирование сверх предела
+			counter := &progressWriter{limit: maxAttachmentSize, report: report}
+			source := io.TeeReader(buffered, counter)
+
+			if inDatabase {
+				// Содержимое и запись о вложении сохраняются в БД одной транзакцией
+				if err := store.CreateAttachmentBlob(attachment, source); err != nil {
+					fyne.Do(func() { a.showStoreError("не удалось сохранить вложение в БД", err) })
+					return
+				}
+			} else {
+				written, err := copyToFile(destPath, source)
+				if err != nil {
+					fyne.Do(func() {
+						dialog.ShowError(fmt.Errorf("не удалось скопировать файл: %w", err), a.window)
+					})
+					return
+				}
+				attachment.Filepath = destPath
+				attachment.SizeBytes = written
+
+				// Создаем запись в БД
+				if err := store.CreateAttachment(attachment); err != nil {
+					// Если запись в БД не удалась, пытаемся удалить скопированный файл
+					if removeErr := os.Remove(destPath); removeErr != nil {
+						log.Printf("Ошибка: не удалось удалить скопированный файл '%s' после ошибки БД: %v", destPath, removeErr)
+					}
+					fyne.Do(func() {
+						a.showStoreError("не удалось сохранить информацию о вложении в БД", err)
+					})
+					return
+				}
+			}
+
+			fyne.Do(func() {
+				dialog.ShowInformation("Успех", "Файл успешно прикреплен!", a.window)
+			})
+			log.Printf("Файл '%s' прикреплен к заметке ID %d (ID вложения: %d)", originalFilename, selectedNote.ID, attachment.ID)
+		})
+
+		// Список вложений обновится по событию хранилища
+	}, a.window)
+}
+
+// openAttachment открывает выбранный файл вложения с помощью системного приложения
+func (a *NoteApp) openAttachment(attachment models.Attachment) {
+	if attachment.InDatabase {
+		a.openAttachmentBlob(attachment)
+		return
+	}
+	openFile(a.window, attachment.Filename, attachment.Filepath)
+}
+
+// updateAttachment сохраняет новое отображаемое имя и описание вложения; файл на диске не переименовывается
+func (a *NoteApp) updateAttachment(attachment models.Attachment, filename, description string) {
+	filename = strings.TrimSpace(filename)
+	if filename == "" {
+		dialog.ShowInformation("Ошибка", "Имя вложения не может быть пустым.", a.window)
+		a.attachmentsList.Refresh() // Возвращаем прежнее имя
+		return
+	}
+	attachment.Filename = filename
+	attachment.Description = strings.TrimSpace(description)
+	if err := a.store.UpdateAttachment(&attachment); err != nil {
+		a.showStoreError("не удалось сохранить вложение", err)
+		log.Printf("Ошибка при обновлении вложения ID %d: %v", attachment.ID, err)
+		return
+	}
+	log.Printf("Вложение ID %d переименовано в '%s'", attachment.ID, attachment.Filename)
+	// Список вложений обновится по событию хранилища
+}
+
+// moveAttachment перемещает вложение на delta мест в списке вложений заметки
+func (a *NoteApp) moveAttachment(attachment models.Attachment, delta int) {
+	attachments := a.selectedAttachments()
+	ids := make([]int, len(attachments))
+	from := -1
+	for i, att := range attachments {
+		ids[i] = att.ID
+		if att.ID == attachment.ID {
+			from = i
+		}
+	}
+	to := from + delta
+	if from == -1 || to < 0 || to >= len(ids) {
+		return
+	}
+	ids[from], ids[to] = ids[to], ids[from]
+	if err := a.store.SetAttachmentOrder(attachment.NoteID, ids); err != nil {
+		a.showStoreError("не удалось изменить порядок вложений", err)
+		log.Printf("Ошибка при изменении порядка вложений заметки ID %d: %v", attachment.NoteID, err)
+	}
+	// Список вложений обновится по событию хранилища
+}
+
+// showAttachmentText показывает текст, распознанный на изображении или расшифрованный из аудио
+func (a *NoteApp) showAttachmentText(attachment models.Attachment) {
+	text := widget.NewLabel(attachment.Text)
+	text.Wrapping = fyne.TextWrapWord
+	text.Selectable = true
+	scroll := container.NewVScroll(text)
+	scroll.SetMinSize(fyne.NewSize(500, 300))
+	dialog.ShowCustom(attachment.Filename, "Закрыть", scroll, a.window)
+}
+
+// openAttachmentBlob выгружает содержимое вложения из БД во временный файл и открывает его
+func (a *NoteApp) openAttachmentBlob(attachment models.Attachment) {
+	store := a.store
+	progress := dialog.NewCustomWithoutButtons("Открытие вложения",
+		container.NewVBox(widget.NewLabel(attachment.Filename), widget.NewProgressBarInfinite()), a.window)
+	progress.Show()
+
+	go func() {
+		path, err := exportAttachmentBlob(store, attachment)
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				a.showStoreError(fmt.Sprintf("не удалось получить вложение '%s'", attachment.Filename), err)
+				log.Printf("Ошибка при выгрузке вложения ID %d: %v", attachment.ID, err)
+				return
+			}
+			openFile(a.window, attachment.Filename, path)
+		})
+	}()
+}
+
+// cacheDir — каталог для временных копий вложений, которые открываются другими программами
+var cacheDir = filepath.Join(os.TempDir(), "gnote")
+
+// SetCacheDir задает каталог кэша вместо временного каталога системы
+func SetCacheDir(dir string) {
+	cacheDir = dir
+}
+
+// exportAttachmentBlob сохраняет содержимое вложения из БД в каталог кэша и возвращает путь к файлу
+func exportAttachmentBlob(store storage.Store, attachment models.Attachment) (string, error) {
+	dir := filepath.Join(cacheDir, "attachments")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	blob, err := store.OpenAttachmentBlob(attachment.ID)
+	if err != nil {
+		return "", err
+	}
+	defer blob.Close()
+	path := filepath.Join(dir, fmt.Sprintf("%d_%s", attachment.ID, filepath.Base(attachment.Filename)))
+	if _, err := copyToFile(path, blob); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// openFile открывает файл вложения программой, назначенной в системе
+func openFile(w fyne.Window, filename, path string) {
+	// Fyne открывает файл средствами системы: xdg-open (в Flatpak — через портал), open или start
+	fileURL := &url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	if !strings.HasPrefix(fileURL.Path, "/") { // Путь Windows: file:///C:/...
+		fileURL.Path = "/" + fileURL.Path
+	}
+	err := fyne.CurrentApp().OpenURL(fileURL)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось открыть файл '%s': %w", filename, err), w)
+		log.Printf("Ошибка при открытии файла '%s' (%s): %v", filename, path, err)
+	} else {
+		log.Printf("Открыт файл '%s' (%s)", filename, path)
+	}
+}
+
+// deleteAttachment удаляет выбранное вложение
+func (a *NoteApp) deleteAttachment(attachment models.Attachment) {
+	where := "Файл будет удален с диска."
+	if attachment.InDatabase {
+		where = "Содержимое будет удалено из БД."
+	}
+	dialog.ShowConfirm("Подтверждение удаления",
+		fmt.Sprintf("Вы уверены, что хотите удалить вложение '%s'? %s", attachment.Filename, where),
+		func(confirmed bool) {
+			if confirmed {
+				err := a.store.DeleteAttachment(attachment.ID)
+				if err != nil {
+					a.showStoreError("не удалось удалить вложение", err)
+					log.Printf("Ошибка при удалении вложения ID %d: %v", attachment.ID, err)
+					return
+				}
+				dialog.ShowInformation("Успех", "Вложение успешно удалено.", a.window)
+				log.Printf("Вложение ID %d ('%s') удалено.", attachment.ID, attachment.Filename)
+				// Список вложений обновится по событию хранилища
+			}
+		}, a.window)
+}
+
+// maxAttachmentSize — предельный размер прикрепляемого файла
+const maxAttachmentSize = 4 << 30
+
+// progressInterval — как часто обновляется индикатор копирования
+const progressInterval = 100 * time.Millisecond
+
+// progressWriter считает скопированные байты и сообщает о них не чаще раза в progressInterval.
+// Если задан limit, запись сверх него завершается ошибкой и прерывает копирование.
+type progressWriter struct {
+	written int64
+	limit   int64
+	last    time.Time
+	report  func(written int64)
+}
+
+// Write учитывает очередную порцию данных
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	if w.limit > 0 && w.written > w.limit {
+		return 0, fmt.Errorf("файл больше предельного размера вложения %s", formatBytes(w.limit))
+	}
+	if now := time.Now(); now.Sub(w.last) >= progressInterval {
+		w.last = now
+		w.report(w.written)
+	}
+	return len(p), nil
+}
+
+// copyToFile записывает src в новый файл path; при ошибке недописанный файл удаляется
+func copyToFile(path string, src io.Reader) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось создать файл вложения: %w", err)
+	}
+	written, err := io.Copy(f, src)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if removeErr := os.Remove(path); removeErr != nil {
+			log.Printf("Ошибка: не удалось удалить недописанный файл '%s': %v", path, removeErr)
+		}
+		return 0, err
+	}
+	return written, nil
+}
+
+// formatBytes форматирует размер файла в удобочитаемый вид
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// shutdownTimeout ограничивает ожидание фоновых записей при завершении
+const shutdownTimeout = 30 * time.Second
+
+// splashDelay — через сколько ожидания при закрытии показывается окно "Сохранение…"
+const splashDelay = time.Second
+
+// taskGroup отслеживает фоновые записи (копирование вложений и т.п.),
+// которые должны завершиться до закрытия хранилища
+type taskGroup struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	active map[int]string // Названия выполняющихся задач для журнала
+	nextID int
+}
+
+// background — фоновые записи всех окон; переживают смену профиля
+var background = &taskGroup{active: make(map[int]string)}
+
+// Go запускает fn в фоне и учитывает ее при завершении
+func (g *taskGroup) Go(name string, fn func()) {
+	g.mu.Lock()
+	id := g.nextID
+	g.nextID++
+	g.active[id] = name
+	g.wg.Add(1)
+	g.mu.Unlock()
+
+	go func() {
+		defer func() {
+			g.mu.Lock()
+			delete(g.active, id)
+			g.mu.Unlock()
+			g.wg.Done()
+		}()
+		fn()
+	}()
+}
+
+// idle сообщает, что фоновых записей нет
+func (g *taskGroup) idle() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.active) == 0
+}
+
+// Wait дожидается фоновых записей, но не дольше timeout.
+// Возвращает false и пишет в журнал незавершенные задачи, если время вышло.
+func (g *taskGroup) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+	}
+
+	g.mu.Lock()
+	names := make([]string, 0, len(g.active))
+	for _, name := range g.active {
+		names = append(names, name)
+	}
+	g.mu.Unlock()
+	sort.Strings(names)
+	log.Printf("Фоновые записи не завершились за %v: %v", timeout, names)
+	return false
+}
+
+// WaitBackground дожидается фоновых записей перед закрытием хранилища
+func WaitBackground() {
+	if !background.idle() {
+		log.Println("Ожидание завершения фоновых записей...")
+	}
+	background.Wait(shutdownTimeout)
+}
+
+// closeWhenIdle закрывает окно после завершения фоновых записей.
+// Если ожидание длится дольше splashDelay, показывается окно "Сохранение…".
+func (a *NoteApp) closeWhenIdle() {
+	if background.idle() {
+		a.window.Close()
+		return
+	}
+
+	var splash dialog.Dialog
+	timer := time.AfterFunc(splashDelay, func() {
+		fyne.Do(func() {
+			splash = dialog.NewCustomWithoutButtons("Сохранение…", widget.NewProgressBarInfinite(), a.window)
+			splash.Show()
+		})
+	})
+	go func() {
+		background.Wait(shutdownTimeout)
+		timer.Stop()
+		fyne.Do(func() {
+			if splash != nil {
+				splash.Hide()
+			}
+			a.window.Close()
+		})
+	}()
+}
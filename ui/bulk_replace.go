@@ -0,0 +1,234 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+	"GNote/storage"
+)
+
+// replacePreviewContext — сколько символов текста показывать вокруг совпадения в предпросмотре замены
+const replacePreviewContext = 30
+
+// bulkMatch — заметка с совпадениями при замене во всех заметках
+type bulkMatch struct {
+	edit  models.TextEdit
+	check *widget.Check // Отмечена ли заметка для замены
+}
+
+// replacePreview показывает первое совпадение в text с заменой: "…текст «старое» → «новое» текст…"
+func replacePreview(f *textFinder, text, replace string) string {
+	matches := f.find(text)
+	if len(matches) == 0 {
+		return ""
+	}
+	m := matches[0]
+	before, after := []rune(text[:m[0]]), []rune(text[m[1]:])
+	prefix, suffix := "", ""
+	if len(before) > replacePreviewContext {
+		before, prefix = before[len(before)-replacePreviewContext:], "…"
+	}
+	if len(after) > replacePreviewContext {
+		after, suffix = after[:replacePreviewContext], "…"
+	}
+	preview := fmt.Sprintf("%s%s«%s» → «%s»%s%s", prefix, string(before), text[m[0]:m[1]],
+		f.replacement(text, m, replace), string(after), suffix)
+	return strings.ReplaceAll(preview, "\n", " ")
+}
+
+// showBulkReplace показывает замену текста во всех заметках с предпросмотром совпадений.
+// Замена применяется одной транзакцией, а снимок заметок до нее позволяет ее отменить.
+func (a *NoteApp) showBulkReplace() {
+	if a.hasUnsavedChanges() || a.hasDirtyPanes() {
+		dialog.ShowInformation("Замена во всех заметках", "Сначала сохраните изменения в открытых заметках.", a.window)
+		return
+	}
+
+	findEntry := widget.NewEntry()
+	findEntry.SetPlaceHolder("Найти, например: Проект А")
+	replaceEntry := widget.NewEntry()
+	replaceEntry.SetPlaceHolder("Заменить на ($1 — группа регулярного выражения)")
+	caseCheck := widget.NewCheck("Учитывать регистр", nil)
+	wordCheck := widget.NewCheck("Слово целиком", nil)
+	regexCheck := widget.NewCheck("Регулярное выражение", nil)
+	summary := widget.NewLabel("Нажмите «Найти», чтобы увидеть заметки с совпадениями.")
+	preview := container.NewVBox()
+
+	var matches []*bulkMatch
+	var applyButton *widget.Button
+	// Изменение условий делает предпросмотр устаревшим
+	outdated := func() {
+		applyButton.Disable()
+		summary.SetText("Условия изменились, нажмите «Найти».")
+	}
+	findEntry.OnChanged = func(string) { outdated() }
+	replaceEntry.OnChanged = func(string) { outdated() }
+	caseCheck.OnChanged = func(bool) { outdated() }
+	wordCheck.OnChanged = func(bool) { outdated() }
+	regexCheck.OnChanged = func(bool) { outdated() }
+
+	search := func() {
+		matches = nil
+		preview.RemoveAll()
+		applyButton.Disable()
+		if findEntry.Text == "" {
+			summary.SetText("Введите текст для поиска.")
+			return
+		}
+		finder, err := newTextFinder(findEntry.Text, caseCheck.Checked, wordCheck.Checked, regexCheck.Checked)
+		if err != nil {
+			summary.SetText(err.Error())
+			return
+		}
+		notes, err := a.store.GetAllNotes() // Свежие данные: список в окне мог устареть
+		if err == nil {
+			err = storage.LoadContent(a.store, notes) // Замена идет по всему тексту, а не по превью
+		}
+		if err != nil {
+			a.showStoreError("не удалось загрузить заметки", err)
+			log.Printf("Ошибка при загрузке заметок для замены: %v", err)
+			return
+		}
+		total := 0
+		for _, note := range notes {
+			title, inTitle := finder.replaceAll(note.Title, replaceEntry.Text)
+			content, inContent := finder.replaceAll(note.Content, replaceEntry.Text)
+			if inTitle+inContent == 0 {
+				continue
+			}
+			total += inTitle + inContent
+			m := &bulkMatch{edit: models.TextEdit{
+				NoteID: note.ID, OldTitle: note.Title, OldContent: note.Content, Title: title, Content: content,
+			}}
+			m.check = widget.NewCheck(fmt.Sprintf("%s — совпадений: %d", truncateTitle(note.Title, 50), inTitle+inContent), nil)
+			m.check.SetChecked(true)
+			text := replacePreview(finder, note.Content, replaceEntry.Text)
+			if inContent == 0 {
+				text = replacePreview(finder, note.Title, replaceEntry.Text)
+			}
+			context := widget.NewLabel(text)
+			context.Wrapping = fyne.TextWrapWord
+			preview.Add(container.NewVBox(m.check, context))
+			matches = append(matches, m)
+		}
+		if len(matches) == 0 {
+			summary.SetText("Совпадений нет.")
+			return
+		}
+		summary.SetText(fmt.Sprintf("Совпадений: %d в %d заметках. Снимите отметку с заметок, которые менять не нужно.", total, len(matches)))
+		applyButton.Enable()
+	}
+
+	var d dialog.Dialog
+	applyButton = widget.NewButtonWithIcon("Заменить в отмеченных", theme.ConfirmIcon(), func() {
+		var edits []models.TextEdit
+		for _, m := range matches {
+			if m.check.Checked {
+				edits = append(edits, m.edit)
+			}
+		}
+		if len(edits) == 0 {
+			dialog.ShowInformation("Замена во всех заметках", "Не отмечено ни одной заметки.", a.window)
+			return
+		}
+		description := fmt.Sprintf("«%s» → «%s»", findEntry.Text, replaceEntry.Text)
+		dialog.ShowConfirm("Замена во всех заметках",
+			fmt.Sprintf("Заменить %s в %d заметках? Замену можно будет отменить в истории замен.", description, len(edits)),
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				snapshotID, err := a.store.ReplaceInNotes(description, edits)
+				if err != nil {
+					a.showStoreError("не удалось выполнить замену", err)
+					log.Printf("Ошибка при замене %s: %v", description, err)
+					return
+				}
+				log.Printf("Замена %s выполнена в %d заметках (снимок ID %d)", description, len(edits), snapshotID)
+				d.Hide()
+				dialog.ShowInformation("Замена во всех заметках",
+					fmt.Sprintf("Изменено заметок: %d. Отменить замену можно в истории замен.", len(edits)), a.window)
+			}, a.window)
+	})
+	applyButton.Disable()
+
+	findButton := widget.NewButtonWithIcon("Найти", theme.SearchIcon(), search)
+	findEntry.OnSubmitted = func(string) { search() }
+	historyButton := widget.NewButtonWithIcon("История замен", theme.HistoryIcon(), a.showReplaceHistory)
+
+	form := container.NewVBox(
+		container.NewBorder(nil, nil, nil, findButton, findEntry),
+		replaceEntry,
+		container.NewHBox(caseCheck, wordCheck, regexCheck),
+		summary,
+	)
+	scroll := container.NewVScroll(preview)
+	scroll.SetMinSize(fyne.NewSize(650, 300))
+	content := container.NewBorder(form, container.NewHBox(historyButton, layout.NewSpacer(), applyButton), nil, nil, scroll)
+	d = dialog.NewCustom("Замена во всех заметках", "Закрыть", content, a.window)
+	d.Show()
+	a.window.Canvas().Focus(findEntry)
+}
+
+// showReplaceHistory показывает выполненные массовые замены с кнопками отмены
+func (a *NoteApp) showReplaceHistory() {
+	snapshots, err := a.store.GetReplaceSnapshots()
+	if err != nil {
+		a.showStoreError("не удалось загрузить историю замен", err)
+		log.Printf("Ошибка при загрузке истории замен: %v", err)
+		return
+	}
+	rows := container.NewVBox()
+	if len(snapshots) == 0 {
+		rows.Add(widget.NewLabel("Массовых замен еще не было."))
+	}
+	for _, snapshot := range snapshots {
+		snapshot := snapshot
+		label := widget.NewLabel(fmt.Sprintf("%s: %s, заметок: %d",
+			snapshot.CreatedAt.In(time.Local).Format(reminderDateLayout+" "+reminderTimeLayout), snapshot.Description, snapshot.NoteCount))
+		label.Wrapping = fyne.TextWrapWord
+		var row fyne.CanvasObject
+		undoButton := widget.NewButtonWithIcon("Отменить", theme.ContentUndoIcon(), func() {
+			a.undoReplace(snapshot, func() { rows.Remove(row) })
+		})
+		row = container.NewBorder(nil, nil, nil, undoButton, label)
+		rows.Add(row)
+	}
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(600, 250))
+	dialog.ShowCustom("История замен", "Закрыть", scroll, a.window)
+}
+
+// undoReplace возвращает заметкам текст до замены после подтверждения; после отмены вызывает undone
+func (a *NoteApp) undoReplace(snapshot models.ReplaceSnapshot, undone func()) {
+	if a.hasUnsavedChanges() || a.hasDirtyPanes() {
+		dialog.ShowInformation("История замен", "Сначала сохраните изменения в открытых заметках.", a.window)
+		return
+	}
+	dialog.ShowConfirm("Отмена замены",
+		fmt.Sprintf("Отменить замену %s? Заголовки и содержимое %d заметок вернутся к состоянию до замены, "+
+			"а их более поздние правки будут потеряны.", snapshot.Description, snapshot.NoteCount),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			noteIDs, err := a.store.UndoReplace(snapshot.ID)
+			if err != nil {
+				a.showStoreError("не удалось отменить замену", err)
+				log.Printf("Ошибка при отмене замены (снимок ID %d): %v", snapshot.ID, err)
+				return
+			}
+			log.Printf("Отменена замена %s: восстановлено заметок: %d", snapshot.Description, len(noteIDs))
+			undone()
+		}, a.window)
+}
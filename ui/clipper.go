@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/clipper"
+	"GNote/models"
+	"GNote/storage"
+)
+
+// importFromURL спрашивает адрес страницы и создает из нее заметку
+func (a *NoteApp) importFromURL() {
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder("https://example.com/article")
+
+	dialog.ShowForm("Импорт из URL", "Импортировать", "Отмена",
+		[]*widget.FormItem{widget.NewFormItem("Адрес", urlEntry)},
+		func(ok bool) {
+			if !ok || urlEntry.Text == "" {
+				return
+			}
+			a.clipURL(urlEntry.Text)
+		}, a.window)
+}
+
+// clipURL загружает страницу в фоне и создает заметку с ее содержимым и изображениями
+func (a *NoteApp) clipURL(rawURL string) {
+	progress := dialog.NewCustomWithoutButtons("Импорт из URL",
+		widget.NewProgressBarInfinite(), a.window)
+	progress.Show()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		clip, err := clipper.New().Fetch(ctx, rawURL)
+
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("не удалось импортировать страницу: %w", err), a.window)
+				log.Printf("Ошибка при импорте страницы '%s': %v", rawURL, err)
+				return
+			}
+			a.saveClip(clip)
+		})
+	}()
+}
+
+// saveClip создает заметку из загруженной страницы и сохраняет изображения как вложения
+func (a *NoteApp) saveClip(clip *clipper.Clip) {
+	note := &models.Note{
+		Title:     clip.Title,
+		Content:   clip.Markdown,
+		SourceURL: clip.SourceURL,
+	}
+	if err := a.store.CreateNote(note); err != nil {
+		a.showStoreError("не удалось сохранить заметку", err)
+		log.Printf("Ошибка при сохранении заметки из '%s': %v", clip.SourceURL, err)
+		return
+	}
+	log.Printf("Создана заметка из страницы '%s' (ID: %d)", clip.SourceURL, note.ID)
+
+	a.selectWhenListed(note.ID) // Заметка появится в списке по событию хранилища
+	if len(clip.Images) == 0 {
+		return
+	}
+
+	// Изображения сохраняются в фоне; при закрытии окна приложение дождется их
+	store, dir, inDatabase := a.store, a.attachmentsDirPath, a.attachmentsInDB
+	background.Go("изображения "+clip.SourceURL, func() {
+		failed := 0
+		for _, img := range clip.Images {
+			if err := storage.SaveAttachmentData(store, dir, inDatabase, note.ID, img.Name, img.MimeType, img.Data); err != nil {
+				log.Printf("Ошибка при сохранении изображения '%s': %v", img.URL, err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			fyne.Do(func() {
+				dialog.ShowInformation("Импорт из URL",
+					fmt.Sprintf("Заметка создана, но %d изображений сохранить не удалось.", failed), a.window)
+			})
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"GNote/models"
+	"GNote/storage"
+)
+
+// copyAttachment помещает в буфер обмена изображение из вложения, а для остальных вложений — путь к файлу
+func (a *NoteApp) copyAttachment(attachment models.Attachment) {
+	store := a.store
+	background.Go("копирование "+attachment.Filename, func() {
+		if strings.HasPrefix(attachment.MimeType, "image/") {
+			err := copyImageAttachment(store, attachment)
+			if err == nil {
+				log.Printf("Изображение '%s' скопировано в буфер обмена", attachment.Filename)
+				fyne.Do(func() {
+					dialog.ShowInformation("Буфер обмена", "Изображение скопировано в буфер обмена.", a.window)
+				})
+				return
+			}
+			// Изображение не удалось скопировать, например нет xclip: копируем путь, как для других файлов
+			log.Printf("Не удалось скопировать изображение '%s' в буфер обмена: %v", attachment.Filename, err)
+		}
+
+		path := attachment.Filepath
+		if attachment.InDatabase {
+			var err error
+			if path, err = exportAttachmentBlob(store, attachment); err != nil {
+				fyne.Do(func() {
+					a.showStoreError(fmt.Sprintf("не удалось получить вложение '%s'", attachment.Filename), err)
+				})
+				return
+			}
+		}
+		fyne.Do(func() {
+			a.window.Clipboard().SetContent(path)
+			dialog.ShowInformation("Буфер обмена", "Путь к файлу скопирован в буфер обмена.", a.window)
+		})
+	})
+}
+
+// copyImageAttachment переводит изображение из вложения в PNG и помещает его в буфер обмена
+func copyImageAttachment(store storage.Store, attachment models.Attachment) error {
+	var src io.ReadCloser
+	var err error
+	if attachment.InDatabase {
+		src, err = store.OpenAttachmentBlob(attachment.ID)
+	} else {
+		src, err = os.Open(attachment.Filepath)
+	}
+	if err != nil {
+		return err
+	}
+	img, _, err := image.Decode(src)
+	src.Close()
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать изображение: %w", err)
+	}
+
+	// Буфер обмена надежнее всего принимает PNG; форматы вроде JPEG переводятся в него
+	f, err := os.CreateTemp("", "gnote-clipboard-*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	err = png.Encode(f, img)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("не удалось подготовить изображение: %w", err)
+	}
+	return copyImageFile(f.Name())
+}
+
+// copyImageFile помещает PNG-файл в буфер обмена программой системы: wl-copy или xclip в Linux,
+// osascript в macOS, PowerShell в Windows
+func copyImageFile(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e",
+			fmt.Sprintf(`set the clipboard to (read (POSIX file %q) as «class PNGf»)`, path))
+	case "windows":
+		cmd = exec.Command("powershell", "-STA", "-NoProfile", "-Command",
+			"Add-Type -AssemblyName System.Windows.Forms, System.Drawing; "+
+				"[System.Windows.Forms.Clipboard]::SetImage([System.Drawing.Image]::FromFile('"+strings.ReplaceAll(path, "'", "''")+"'))")
+	default:
+		if _, err := exec.LookPath("wl-copy"); err == nil && os.Getenv("WAYLAND_DISPLAY") != "" {
+			cmd = exec.Command("wl-copy", "--type", "image/png")
+		} else if _, err := exec.LookPath("xclip"); err == nil {
+			cmd = exec.Command("xclip", "-selection", "clipboard", "-t", "image/png")
+		} else {
+			return errors.New("не найдена программа для работы с буфером обмена: установите wl-clipboard или xclip")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		// Вывод не перехватывается: xclip и wl-copy остаются в фоне, пока буфер не займут другим содержимым
+		cmd.Stdin = bytes.NewReader(data)
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("команда %s завершилась с ошибкой: %w", filepath.Base(cmd.Path), err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+
+	"GNote/events"
+	"GNote/hooks"
+	"GNote/journal"
+	"GNote/linkpreview"
+	"GNote/models"
+	"GNote/scan"
+	"GNote/speech"
+	"GNote/storage"
+	"GNote/summary"
+)
+
+// Daemon управляет фоновым режимом: значок в трее и окно заметок, открываемое по требованию
+type Daemon struct {
+	app     fyne.App
+	store   storage.Store
+	window  fyne.Window
+	noteApp *NoteApp
+	bus     *events.Bus
+
+	contextProviders []journal.ContextProvider
+	attachmentsDir   string
+	attachmentsInDB  bool
+	profiles         *Profiles
+	health           *storage.HealthChecker
+	speaker          *speech.Speaker
+	hooks            *hooks.Hooks
+	summarizer       summary.Summarizer
+	scanner          *scan.Scanner
+	linkPreviews     *linkpreview.Fetcher
+	metrics          *storage.Metrics
+}
+
+// NewDaemon создает фоновый режим приложения
+func NewDaemon(a fyne.App, s storage.Store, bus *events.Bus) *Daemon {
+	return &Daemon{app: a, store: s, bus: bus}
+}
+
+// SetContextProviders задает провайдеров контекста для дневниковых записей окна заметок
+func (d *Daemon) SetContextProviders(providers []journal.ContextProvider) {
+	d.contextProviders = providers
+}
+
+// SetSpeaker задает синтезатор речи для чтения заметок вслух в окне заметок
+func (d *Daemon) SetSpeaker(speaker *speech.Speaker) {
+	d.speaker = speaker
+}
+
+// SetHooks задает обработчики событий для окна заметок
+func (d *Daemon) SetHooks(h *hooks.Hooks) {
+	d.hooks = h
+}
+
+// SetSummarizer задает способ краткого пересказа заметок в окне заметок
+func (d *Daemon) SetSummarizer(s summary.Summarizer) {
+	d.summarizer = s
+}
+
+// SetMetrics задает сбор времени запросов к БД для окна заметок
+func (d *Daemon) SetMetrics(m *storage.Metrics) {
+	d.metrics = m
+}
+
+// SetScanner задает сканер документов для окна заметок
+func (d *Daemon) SetScanner(s *scan.Scanner) {
+	d.scanner = s
+}
+
+// SetLinkPreviews задает загрузчик карточек ссылок для окна заметок; nil — карточки выключены
+func (d *Daemon) SetLinkPreviews(f *linkpreview.Fetcher) {
+	d.linkPreviews = f
+}
+
+// SetAttachmentsDir задает каталог вложений для окна заметок (пусто — каталог данных приложения)
+func (d *Daemon) SetAttachmentsDir(dir string) {
+	d.attachmentsDir = dir
+}
+
+// SetAttachmentsInDatabase включает хранение содержимого новых вложений в БД
+func (d *Daemon) SetAttachmentsInDatabase(inDB bool) {
+	d.attachmentsInDB = inDB
+}
+
+// SetProfiles включает переключение профилей в окне заметок.
+// Хранилище и каталог вложений берутся из открытого профиля.
+func (d *Daemon) SetProfiles(p *Profiles) {
+	d.profiles = p
+	p.AddListener(func(app *NoteApp, session *ProfileSession) {
+		d.noteApp = app
+		d.store = session.Store
+		d.bus = session.Bus
+		d.attachmentsDir = session.AttachmentsDir
+		d.attachmentsInDB = session.AttachmentsInDB
+		if d.window != nil {
+			d.window.SetCloseIntercept(d.window.Hide) // Новое окно заметок заменяет обработчик закрытия
+		}
+	})
+}
+
+// SetHealth подключает индикатор состояния БД в окне заметок
+func (d *Daemon) SetHealth(h *storage.HealthChecker) {
+	d.health = h
+}
+
+// SetupTray добавляет значок в системный трей с меню для открытия заметок.
+// Возвращает false, если платформа не поддерживает системный трей.
+func (d *Daemon) SetupTray() bool {
+	desk, ok := d.app.(desktop.App)
+	if !ok {
+		return false
+	}
+	menu := fyne.NewMenu("GNote",
+		fyne.NewMenuItem("Открыть заметки", d.ShowNotes),
+	)
+	desk.SetSystemTrayMenu(menu) // Пункт "Выход" Fyne добавляет сам
+	desk.SetSystemTrayIcon(theme.DocumentIcon())
+	return true
+}
+
+// ShowNotes открывает главное окно заметок, создавая его при первом обращении.
+// Закрытие окна только скрывает его, чтобы фоновый режим продолжал работать.
+func (d *Daemon) ShowNotes() {
+	if d.window != nil {
+		d.window.Show()
+		d.window.RequestFocus()
+		return
+	}
+	d.window = d.app.NewWindow("Приложение для заметок")
+	profile := ""
+	if d.profiles != nil {
+		profile = d.profiles.Current().Name
+	}
+	d.noteApp = NewNoteApp(d.window, d.store, profile)
+	d.noteApp.SetContextProviders(d.contextProviders)
+	d.noteApp.Subscribe(d.bus)
+	if d.attachmentsDir != "" {
+		d.noteApp.SetAttachmentsDir(d.attachmentsDir)
+	}
+	d.noteApp.SetAttachmentsInDatabase(d.attachmentsInDB)
+	d.noteApp.SetSpeaker(d.speaker)
+	d.noteApp.SetHooks(d.hooks)
+	d.noteApp.SetSummarizer(d.summarizer)
+	d.noteApp.SetMetrics(d.metrics)
+	d.noteApp.SetScanner(d.scanner)
+	d.noteApp.SetLinkPreviews(d.linkPreviews)
+	if d.profiles != nil {
+		d.noteApp.SetProfiles(d.profiles)
+	}
+	if d.health != nil {
+		d.noteApp.SetHealth(d.health)
+	}
+	d.window.SetCloseIntercept(d.window.Hide)
+	d.window.Show()
+}
+
+// ReminderNotifier возвращает функцию, показывающую системное уведомление о напоминании
+func ReminderNotifier(a fyne.App) func(note models.Note) {
+	return func(note models.Note) {
+		content := []rune(note.Content)
+		if len(content) > 100 {
+			content = append(content[:100], '…')
+		}
+		fyne.Do(func() {
+			a.SendNotification(fyne.NewNotification("Напоминание: "+note.Title, string(content)))
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// dateFieldOptions — по какой дате фильтровать список: создания или последнего изменения
+var dateFieldOptions = []string{"Создана", "Изменена"}
+
+// datePicker — кнопка выбора дня: показывает выбранную дату и открывает календарь под собой
+type datePicker struct {
+	button *widget.Button
+	prefix string    // Подпись перед датой, например «с»
+	date   time.Time // Выбранный день в местном времени; нулевое — не выбран
+
+	OnChanged func(date time.Time)
+}
+
+// newDatePicker создает кнопку выбора дня с подписью prefix
+func newDatePicker(prefix string) *datePicker {
+	p := &datePicker{prefix: prefix}
+	p.button = widget.NewButtonWithIcon("", theme.CalendarIcon(), p.showCalendar)
+	p.set(time.Time{})
+	return p
+}
+
+// set показывает дату date (нулевая — не выбрана), не вызывая OnChanged
+func (p *datePicker) set(date time.Time) {
+	p.date = date
+	if date.IsZero() {
+		p.button.SetText(p.prefix + " …")
+		return
+	}
+	p.button.SetText(p.prefix + " " + date.Format(reminderDateLayout))
+}
+
+// showCalendar открывает календарь под кнопкой; выбор дня закрывает его
+func (p *datePicker) showCalendar() {
+	c := fyne.CurrentApp().Driver().CanvasForObject(p.button)
+	if c == nil {
+		return
+	}
+	initial := p.date
+	if initial.IsZero() {
+		initial = time.Now()
+	}
+	var popUp *widget.PopUp
+	popUp = widget.NewPopUp(widget.NewCalendar(initial, func(t time.Time) {
+		popUp.Hide()
+		p.set(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local))
+		if p.OnChanged != nil {
+			p.OnChanged(p.date)
+		}
+	}), c)
+	popUp.ShowAtPosition(fyne.CurrentApp().Driver().AbsolutePositionForObject(p.button).AddXY(0, p.button.Size().Height))
+}
+
+// dateRange возвращает границы фильтра по датам [from, to): с начала первого дня до конца последнего.
+// Нулевая граница не ограничивает.
+func (vm *NoteViewModel) dateRange() (from, to time.Time) {
+	from = vm.dateFrom
+	if !vm.dateTo.IsZero() {
+		to = vm.dateTo.AddDate(0, 0, 1)
+	}
+	return from, to
+}
+
+// matchesDates сообщает, что дата создания или изменения заметки попадает в выбранный диапазон
+func (vm *NoteViewModel) matchesDates(note models.Note) bool {
+	from, to := vm.dateRange()
+	at := note.CreatedAt
+	if vm.dateByUpdated {
+		at = note.UpdatedAt
+	}
+	return (from.IsZero() || !at.Before(from)) && (to.IsZero() || at.Before(to))
+}
+
+// dateSummary возвращает описание фильтра по датам для сводки над списком; "" — фильтра нет
+func (vm *NoteViewModel) dateSummary() string {
+	if vm.dateFrom.IsZero() && vm.dateTo.IsZero() {
+		return ""
+	}
+	text := "создана"
+	if vm.dateByUpdated {
+		text = "изменена"
+	}
+	if !vm.dateFrom.IsZero() {
+		text += " с " + vm.dateFrom.Format(reminderDateLayout)
+	}
+	if !vm.dateTo.IsZero() {
+		text += " по " + vm.dateTo.Format(reminderDateLayout)
+	}
+	return text
+}
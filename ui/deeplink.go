@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2/dialog"
+
+	"GNote/deeplink"
+)
+
+// HandleLink открывает заметку по ссылке вида gnote://note/{id}
+func (a *NoteApp) HandleLink(link string) {
+	id, err := deeplink.ParseNoteID(link)
+	if err != nil {
+		log.Printf("Ошибка при обработке ссылки '%s': %v", link, err)
+		dialog.ShowError(err, a.window)
+		return
+	}
+	a.window.RequestFocus()
+	a.OpenNoteByID(id)
+}
+
+// OpenNoteByID выбирает заметку с указанным ID в списке, при необходимости сбрасывая поиск
+func (a *NoteApp) OpenNoteByID(id int) {
+	index := a.filteredIndexOf(id)
+	if index == -1 && a.searchEntry.Text != "" {
+		a.searchEntry.SetText("") // Заметка может быть скрыта фильтром
+		index = a.filteredIndexOf(id)
+	}
+	if index == -1 {
+		a.loadNotes() // Заметка могла быть создана другим экземпляром
+		index = a.filteredIndexOf(id)
+	}
+	if index == -1 {
+		dialog.ShowError(fmt.Errorf("заметка с ID %d не найдена", id), a.window)
+		return
+	}
+	a.noteList.Select(index)
+	a.noteList.ScrollTo(index)
+}
+
+// HandleLink открывает окно заметок и переходит к заметке по ссылке
+func (d *Daemon) HandleLink(link string) {
+	d.ShowNotes()
+	d.noteApp.HandleLink(link)
+}
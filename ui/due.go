@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// defaultDueTime — время срока по умолчанию: задача должна быть сделана до конца дня
+const defaultDueTime = "23:59"
+
+// isOverdue сообщает, что срок заметки прошел к моменту now
+func isOverdue(note models.Note, now time.Time) bool {
+	return note.DueAt != nil && note.DueAt.Before(now)
+}
+
+// updateDueUI задает срок редактируемой заметки и показывает его; просроченный срок выделяется красным
+func (a *NoteApp) updateDueUI(t *time.Time) {
+	a.dueLabel.Importance = widget.MediumImportance
+	if t == nil {
+		a.currentDue = nil
+		a.dueLabel.SetText("Срок: Не установлен")
+		return
+	}
+	utc := t.UTC()
+	a.currentDue = &utc
+	text := "Срок: " + formatReminder(utc)
+	if utc.Before(time.Now()) {
+		text += " — просрочено"
+		a.dueLabel.Importance = widget.DangerImportance
+	}
+	a.dueLabel.SetText(text)
+}
+
+// setDueDialog открывает диалог для установки срока выполнения
+func (a *NoteApp) setDueDialog() {
+	dateEntry := widget.NewEntry()
+	dateEntry.SetPlaceHolder("ДД.ММ.ГГГГ")
+	timeEntry := widget.NewEntry()
+	timeEntry.SetPlaceHolder("ЧЧ:ММ")
+
+	initial := time.Now()
+	timeEntry.SetText(defaultDueTime)
+	if a.currentDue != nil {
+		initial = a.currentDue.In(time.Local) // Поля диалога — в местном времени
+		timeEntry.SetText(initial.Format(reminderTimeLayout))
+	}
+	dateEntry.SetText(initial.Format(reminderDateLayout))
+
+	calendarButton := widget.NewButton("Выбрать дату", func() {
+		dialog.ShowCustom("Выберите дату", "Закрыть",
+			widget.NewCalendar(initial, func(t time.Time) {
+				dateEntry.SetText(t.Format(reminderDateLayout))
+			}), a.window)
+	})
+
+	content := container.NewVBox(
+		widget.NewLabel("Дата:"),
+		container.NewHBox(dateEntry, calendarButton),
+		widget.NewLabel("Время (ЧЧ:ММ, местное):"),
+		timeEntry,
+	)
+	dialog.ShowCustomConfirm("Установить срок", "Установить", "Отмена", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		due, err := parseReminder(dateEntry.Text, timeEntry.Text, time.Local)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("неверный формат даты или времени. Используйте ДД.ММ.ГГГГ ЧЧ:ММ: %w", err), a.window)
+			return
+		}
+		a.updateDueUI(&due)
+		a.setUnsavedChanges(true)
+	}, a.window)
+}
@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/index"
+)
+
+// duplicateMinScore — с какого сходства текста заметки считаются почти одинаковыми
+const duplicateMinScore = 0.9
+
+// findDuplicates ищет почти одинаковые заметки в фоне и показывает их для объединения или удаления
+func (a *NoteApp) findDuplicates() {
+	if a.related == nil {
+		dialog.ShowInformation("Дубликаты", "Индекс заметок еще не готов, попробуйте позже.", a.window)
+		return
+	}
+	progress := dialog.NewCustomWithoutButtons("Поиск дубликатов", widget.NewProgressBarInfinite(), a.window)
+	progress.Show()
+
+	related := a.related
+	go func() {
+		dups := related.Duplicates(duplicateMinScore)
+		fyne.Do(func() {
+			progress.Hide()
+			a.showDuplicates(dups)
+		})
+	}()
+}
+
+// showDuplicates показывает найденные пары с кнопками "Открыть", "Объединить" и "Удалить вторую"
+func (a *NoteApp) showDuplicates(dups []index.Duplicate) {
+	titles := make(map[int]string, len(a.allNotes))
+	for _, note := range a.allNotes {
+		titles[note.ID] = note.Title
+	}
+
+	rows := container.NewVBox()
+	rowPairs := make(map[fyne.CanvasObject]index.Duplicate) // Какую пару показывает строка
+	// removed убирает строки пар, в которых участвует удаленная заметка
+	removed := func(id int) {
+		for row, pair := range rowPairs {
+			if pair.A == id || pair.B == id {
+				rows.Remove(row)
+				delete(rowPairs, row)
+			}
+		}
+		if len(rows.Objects) == 0 {
+			rows.Add(widget.NewLabel("Дубликатов больше нет."))
+		}
+	}
+	var d dialog.Dialog
+	for _, dup := range dups {
+		dup := dup
+		titleA, okA := titles[dup.A]
+		titleB, okB := titles[dup.B]
+		if !okA || !okB { // Индекс мог еще не узнать об удалении
+			continue
+		}
+		reason := fmt.Sprintf("сходство %.0f%%", dup.Score*100)
+		if dup.SameTitle {
+			reason = "одинаковый заголовок, " + reason
+		}
+		label := widget.NewLabel(fmt.Sprintf("«%s» и «%s» — %s",
+			truncateTitle(titleA, 40), truncateTitle(titleB, 40), reason))
+		label.Wrapping = fyne.TextWrapWord
+
+		openButton := widget.NewButton("Открыть", func() {
+			d.Hide()
+			a.OpenNoteByID(dup.A)
+		})
+		mergeButton := widget.NewButton("Объединить", func() {
+			a.mergeDuplicate(dup.A, dup.B, titleA, titleB, removed)
+		})
+		deleteButton := widget.NewButton("Удалить вторую", func() {
+			a.deleteDuplicate(dup.B, titleB, removed)
+		})
+		row := container.NewVBox(label,
+			container.NewHBox(layout.NewSpacer(), openButton, mergeButton, deleteButton),
+			widget.NewSeparator())
+		rowPairs[row] = dup
+		rows.Add(row)
+	}
+	if len(rows.Objects) == 0 {
+		dialog.ShowInformation("Дубликаты", "Похожих заметок не найдено.", a.window)
+		return
+	}
+
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(600, 400))
+	d = dialog.NewCustom(fmt.Sprintf("Дубликаты: %d", len(rows.Objects)), "Закрыть", scroll, a.window)
+	d.Show()
+}
+
+// editingOneOf сообщает, что в редакторе есть несохраненные изменения одной из заметок ids
+func (a *NoteApp) editingOneOf(ids ...int) bool {
+	selected := a.getSelectedNote()
+	if selected == nil || !a.hasUnsavedChanges() {
+		return false
+	}
+	for _, id := range ids {
+		if selected.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeDuplicate переносит заметку sourceID в targetID после подтверждения
+func (a *NoteApp) mergeDuplicate(targetID, sourceID int, targetTitle, sourceTitle string, removed func(id int)) {
+	if a.editingOneOf(targetID, sourceID) {
+		dialog.ShowInformation("Дубликаты", "Сначала сохраните изменения в открытой заметке.", a.window)
+		return
+	}
+	dialog.ShowConfirm("Объединение заметок",
+		fmt.Sprintf("Перенести текст, теги и вложения «%s» в «%s»? Вторая заметка будет удалена.", sourceTitle, targetTitle),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := a.store.MergeNotes(targetID, sourceID); err != nil {
+				a.showStoreError("не удалось объединить заметки", err)
+				log.Printf("Ошибка при объединении заметки ID %d с ID %d: %v", sourceID, targetID, err)
+				return
+			}
+			log.Printf("Заметка ID %d объединена с ID %d", sourceID, targetID)
+			removed(sourceID) // Список заметок обновится по событиям хранилища
+		}, a.window)
+}
+
+// deleteDuplicate удаляет заметку-дубликат после подтверждения
+func (a *NoteApp) deleteDuplicate(id int, title string, removed func(id int)) {
+	if a.editingOneOf(id) {
+		dialog.ShowInformation("Дубликаты", "Сначала сохраните изменения в открытой заметке.", a.window)
+		return
+	}
+	dialog.ShowConfirm("Удаление дубликата",
+		fmt.Sprintf("Удалить заметку «%s» вместе с ее вложениями?", title),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := a.store.DeleteNote(id); err != nil {
+				a.showStoreError("не удалось удалить заметку", err)
+				log.Printf("Ошибка при удалении дубликата ID %d: %v", id, err)
+				return
+			}
+			log.Printf("Удален дубликат ID %d", id)
+			removed(id)
+		}, a.window)
+}
@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"GNote/events"
+	"GNote/index"
+	"GNote/models"
+)
+
+// Subscribe подписывает окно заметок на события хранилища.
+// Список заметок и вложения обновляются по событиям, а не после каждого вызова хранилища.
+func (a *NoteApp) Subscribe(bus *events.Bus) {
+	if a.unsubscribe != nil {
+		a.unsubscribe()
+	}
+	unsubscribe := bus.Subscribe(a.onStoreEvent)
+	// Индекс похожих заметок строится в фоне и следует за теми же событиями
+	related, stopIndex := index.Start(a.store, bus)
+	a.related = related
+	a.unsubscribe = func() {
+		unsubscribe()
+		stopIndex()
+	}
+}
+
+// onStoreEvent ставит событие в очередь и планирует его применение в потоке интерфейса.
+// События, пришедшие до обработки очереди, применяются вместе (например, при импорте).
+func (a *NoteApp) onStoreEvent(e events.Event) {
+	a.eventsMu.Lock()
+	a.pendingEvents = append(a.pendingEvents, e)
+	schedule := len(a.pendingEvents) == 1
+	a.eventsMu.Unlock()
+	if schedule {
+		fyne.Do(a.applyStoreEvents)
+	}
+}
+
+// applyStoreEvents применяет накопленные события к списку заметок и вложениям
+func (a *NoteApp) applyStoreEvents() {
+	a.eventsMu.Lock()
+	pending := a.pendingEvents
+	a.pendingEvents = nil
+	a.eventsMu.Unlock()
+
+	listChanged, attachmentsChanged := false, false
+	remoteChangedID := 0 // Открытую заметку изменил другой экземпляр
+	for _, e := range pending {
+		switch e.Kind {
+		case events.NoteCreated:
+			a.noteCreated(*e.Note)
+			listChanged = true
+		case events.NoteUpdated:
+			if e.Note == nil {
+				note, err := a.store.GetNoteByID(e.NoteID)
+				if err != nil {
+					log.Printf("Ошибка при загрузке измененной заметки ID %d: %v", e.NoteID, err)
+					continue
+				}
+				e.Note = note
+			}
+			a.noteUpdated(*e.Note)
+			listChanged = true
+			if selected := a.getSelectedNote(); selected != nil && selected.ID == e.NoteID {
+				a.attachmentsList.Refresh() // Например, у вложения появился распознанный текст
+				if e.Remote {
+					remoteChangedID = e.NoteID
+				}
+			}
+		case events.NoteDeleted:
+			a.noteDeleted(e.NoteID)
+			listChanged = true
+		case events.AttachmentCreated:
+			if e.Attachment != nil {
+				a.attachmentAdded(*e.Attachment)
+				listChanged = true // Заметка может начать подходить под has: или file:
+			}
+			attachmentsChanged = true
+		case events.AttachmentDeleted:
+			attachmentsChanged = true
+		}
+	}
+
+	if listChanged {
+		a.refreshFavoritesBar()
+		a.refreshTagTree()
+		a.filterNotes()
+		if a.pendingSelectID != 0 {
+			if i := a.filteredIndexOf(a.pendingSelectID); i != -1 {
+				a.pendingSelectID = 0
+				a.noteList.Select(i)
+			}
+		}
+	}
+	if attachmentsChanged {
+		a.reloadAttachments()
+	}
+	if remoteChangedID != 0 {
+		a.remoteNoteChanged(remoteChangedID)
+	}
+}
+
+// remoteNoteChanged показывает в редакторе новую версию открытой заметки, измененной другим экземпляром.
+// Несохраненные изменения не затираются: пользователь только получает предупреждение.
+func (a *NoteApp) remoteNoteChanged(id int) {
+	i := a.filteredIndexOf(id)
+	if i == -1 || i != a.selectedNoteIndex {
+		return // Заметка больше не открыта или пропала из списка
+	}
+	if !a.hasUnsavedChanges() {
+		a.doSelectNote(i)
+		return
+	}
+	log.Printf("Открытую заметку ID %d изменили в другом окне, пока в ней есть несохраненные изменения", id)
+	dialog.ShowInformation("Заметка изменена",
+		"Эту заметку только что изменили в другом окне GNote. Ваши изменения не сохранены; "+
+			"при сохранении GNote спросит, заменить ли ими чужие.", a.window)
+}
+
+// selectWhenListed выделяет заметку в списке, как только она в нем появится
+func (a *NoteApp) selectWhenListed(id int) {
+	if i := a.filteredIndexOf(id); i != -1 {
+		a.noteList.Select(i)
+		return
+	}
+	a.pendingSelectID = id
+}
+
+// reloadAttachments перечитывает вложения выбранной заметки, не трогая несохраненные поля редактора
+func (a *NoteApp) reloadAttachments() {
+	selectedNote := a.getSelectedNote()
+	if selectedNote == nil {
+		return
+	}
+	attachments, err := a.store.GetAttachmentsByNoteID(selectedNote.ID)
+	if err != nil {
+		log.Printf("Ошибка при загрузке вложений заметки ID %d: %v", selectedNote.ID, err)
+		return
+	}
+	selectedNote.Attachments = attachments
+	names, types := models.AttachmentMeta(attachments) // В том числе после удаления вложения
+	a.setAttachmentMeta(selectedNote.ID, names, types)
+	a.attachmentsList.Refresh()
+}
@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"GNote/models"
+)
+
+// saveExport готовит файл экспорта заметок notes, пропускает его через обработчики before-export
+// и сохраняет в выбранный пользователем файл. С redact секреты заметок в файл не попадают.
+func (a *NoteApp) saveExport(notes []models.Note, withOrganization, redact bool) {
+	// Вместе со всеми заметками сохраняем их организацию: оформление тегов, правила, сортировку
+	export, err := a.buildExport(notes, withOrganization, redact)
+	if err != nil {
+		a.showStoreError("не удалось подготовить экспорт", err)
+		log.Printf("Ошибка при подготовке экспорта: %v", err)
+		return
+	}
+
+	// Простой формат JSON для экспорта
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("ошибка при форматировании JSON: %w", err), a.window)
+		return
+	}
+
+	a.runBeforeExport(data, func(data []byte) {
+		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, a.window)
+				return
+			}
+			if writer == nil { // Пользователь отменил
+				return
+			}
+			defer writer.Close()
+
+			_, err = writer.Write(data)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("ошибка при записи файла: %w", err), a.window)
+				return
+			}
+			a.logExport(notes, writer.URI().Name())
+			dialog.ShowInformation("Экспорт", "Заметки успешно экспортированы!", a.window)
+		}, a.window)
+	})
+}
+
+// buildExport готовит файл экспорта заметок notes. С withOrganization в него попадают
+// оформление тегов, повторяющиеся заметки и настройки списка, чтобы восстановление их не теряло.
+// С redact секретные заметки и фрагменты заменяются на models.RedactedText.
+func (a *NoteApp) buildExport(notes []models.Note, withOrganization, redact bool) (*models.Export, error) {
+	if redact {
+		notes = models.RedactNotes(notes)
+	}
+	export := &models.Export{
+		Version:    models.ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Notes:      notes,
+	}
+	if !withOrganization {
+		return export, nil
+	}
+	styles, err := a.store.GetTagStyles()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := a.store.GetRecurringRules()
+	if err != nil {
+		return nil, err
+	}
+	export.TagStyles = styles
+	export.RecurringRules = rules
+	export.Settings = &models.ExportSettings{Sort: a.sortCriteria}
+	return export, nil
+}
+
+// sameRule сообщает, что правила создают одну и ту же заметку по одному расписанию
+func sameRule(a, b models.RecurringRule) bool {
+	return a.Title == b.Title && a.Frequency == b.Frequency && a.Day == b.Day && a.Time == b.Time
+}
+
+// importOrganization восстанавливает организацию из файла экспорта после импорта заметок:
+// оформление тегов, повторяющиеся заметки (с шаблонами, указывающими на импортированные заметки) и сортировку
+func (a *NoteApp) importOrganization(s *importSession) {
+	export := s.export
+	for tag, style := range export.TagStyles {
+		if err := a.store.SetTagStyle(tag, style); err != nil {
+			s.report.addSkipped("Оформление тега «"+tag+"»", err.Error())
+			continue
+		}
+		s.report.addOrganization("оформление тега «" + tag + "»")
+	}
+
+	if len(export.RecurringRules) > 0 {
+		a.importRecurringRules(s)
+	}
+
+	if settings := export.Settings; settings != nil && slices.Contains(sortOptions, settings.Sort) {
+		a.sortSelect.SetSelected(settings.Sort) // Пересортирует список
+		s.report.addOrganization("сортировка «" + settings.Sort + "»")
+	}
+}
+
+// importRecurringRules создает повторяющиеся заметки из файла экспорта, пропуская уже существующие правила
+func (a *NoteApp) importRecurringRules(s *importSession) {
+	existing, err := a.store.GetRecurringRules()
+	if err != nil {
+		s.report.addSkipped("Повторяющиеся заметки", err.Error())
+		return
+	}
+rules:
+	for _, rule := range s.export.RecurringRules {
+		title := "Повторяющаяся заметка «" + rule.Title + "»"
+		for _, other := range existing {
+			if sameRule(rule, other) {
+				s.report.addDuplicate(title, "такое правило уже есть")
+				continue rules
+			}
+		}
+		rule.ID, rule.LastRunAt = 0, nil
+		if rule.TemplateNoteID != 0 {
+			templateID, ok := s.newIDs[rule.TemplateNoteID] // ID заметок при импорте меняются
+			if !ok {
+				s.report.addSkipped(title, "заметка-шаблон не импортирована, правило создано без шаблона")
+			}
+			rule.TemplateNoteID = templateID
+		}
+		if err := a.store.SaveRecurringRule(&rule); err != nil {
+			s.report.addSkipped(title, err.Error())
+			continue
+		}
+		s.report.addOrganization("повторяющаяся заметка «" + rule.Title + "»")
+	}
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"log"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// maxFavoriteTitle — максимальная длина заголовка на кнопке быстрого доступа
+const maxFavoriteTitle = 20
+
+// refreshFavoritesBar пересоздает кнопки избранных заметок
+func (a *NoteApp) refreshFavoritesBar() {
+	a.favoritesBox.RemoveAll()
+	for _, note := range a.allNotes {
+		if !note.Favorite {
+			continue
+		}
+		id := note.ID
+		button := widget.NewButton("★ "+truncateTitle(note.Title, maxFavoriteTitle), func() {
+			a.OpenNoteByID(id)
+		})
+		button.Importance = widget.LowImportance
+		a.favoritesBox.Add(button)
+	}
+	if len(a.favoritesBox.Objects) == 0 {
+		a.favoritesScroll.Hide()
+	} else {
+		a.favoritesScroll.Show()
+	}
+	a.favoritesBox.Refresh()
+}
+
+// toggleFavorite отмечает выбранную заметку звездочкой или снимает отметку
+func (a *NoteApp) toggleFavorite() {
+	selectedNote := a.getSelectedNote()
+	if selectedNote == nil {
+		dialog.ShowInformation("Избранное", "Сначала сохраните заметку, чтобы добавить ее в избранное.", a.window)
+		return
+	}
+	favorite := !selectedNote.Favorite
+	if err := a.store.SetFavorite(selectedNote.ID, favorite); err != nil {
+		a.showStoreError("не удалось изменить избранное", err)
+		log.Printf("Ошибка при изменении избранного для заметки ID %d: %v", selectedNote.ID, err)
+		return
+	}
+	selectedNote.Favorite = favorite
+	a.updateFavoriteButton(selectedNote) // Панель избранного обновится по событию хранилища
+}
+
+// updateFavoriteButton показывает состояние отметки для редактируемой заметки
+func (a *NoteApp) updateFavoriteButton(note *models.Note) {
+	if note == nil {
+		a.favoriteButton.SetText("☆")
+		a.favoriteButton.Disable()
+		return
+	}
+	a.favoriteButton.Enable()
+	if note.Favorite {
+		a.favoriteButton.SetText("★")
+	} else {
+		a.favoriteButton.SetText("☆")
+	}
+}
+
+// truncateTitle обрезает заголовок до max символов с многоточием
+func truncateTitle(title string, max int) string {
+	runes := []rune(title)
+	if len(runes) <= max {
+		return title
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,344 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// findReplaceShortcut открывает панель поиска и замены в содержимом заметки
+var findReplaceShortcut = &desktop.CustomShortcut{KeyName: fyne.KeyH, Modifier: fyne.KeyModifierControl}
+
+// contentEditor — многострочное поле содержимого заметки. Сочетания клавиш получает поле в фокусе,
+// а не окно, поэтому Ctrl+H перехватывается здесь. Здесь же разворачиваются сокращения сниппетов.
+type contentEditor struct {
+	widget.Entry
+	OnFindReplace func()
+	OnGoToLine    func()
+	Expand        func(word string) (text string, cursorBack int, ok bool) // Расшифровка сокращения word
+}
+
+// newContentEditor создает поле содержимого с переносом слов
+func newContentEditor() *contentEditor {
+	e := &contentEditor{}
+	e.MultiLine = true
+	e.Wrapping = fyne.TextWrapWord
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// TypedShortcut открывает поиск и замену по Ctrl+H и переход к строке по Ctrl+G,
+// остальные сочетания обрабатывает как обычное поле
+func (e *contentEditor) TypedShortcut(shortcut fyne.Shortcut) {
+	if s, ok := shortcut.(*desktop.CustomShortcut); ok {
+		switch s.ShortcutName() {
+		case findReplaceShortcut.ShortcutName():
+			if e.OnFindReplace != nil {
+				e.OnFindReplace()
+			}
+			return
+		case goToLineShortcut.ShortcutName():
+			if e.OnGoToLine != nil {
+				e.OnGoToLine()
+			}
+			return
+		}
+	}
+	e.Entry.TypedShortcut(shortcut)
+}
+
+// TypedRune вводит символ; пробел или знак препинания разворачивают сокращение перед ним
+func (e *contentEditor) TypedRune(r rune) {
+	e.Entry.TypedRune(r)
+	if models.IsSnippetDelimiter(r) {
+		e.expandSnippet()
+	}
+}
+
+// TypedKey обрабатывает клавишу как обычное поле; Enter разворачивает сокращение перед новой строкой
+func (e *contentEditor) TypedKey(key *fyne.KeyEvent) {
+	e.Entry.TypedKey(key)
+	if key.Name == fyne.KeyReturn || key.Name == fyne.KeyEnter {
+		e.expandSnippet()
+	}
+}
+
+// expandSnippet заменяет слово перед только что введенным разделителем расшифровкой сниппета.
+// Позиции курсора у Entry нет, поэтому слово выделяется стрелками с Shift, как в selectEntryRange,
+// вместе с разделителями по краям, и выделение заменяется вводом расшифровки.
+func (e *contentEditor) expandSnippet() {
+	if e.Expand == nil || e.SelectedText() != "" {
+		return
+	}
+	shift := &fyne.KeyEvent{Name: desktop.KeyShiftLeft}
+	press := func(name fyne.KeyName) { e.Entry.TypedKey(&fyne.KeyEvent{Name: name}) }
+	selected := func() []rune { return []rune(e.SelectedText()) }
+	// selectBack выделяет влево от курсора, пока stop не сообщит, что хватит; false — дошли до начала текста
+	selectBack := func(stop func(sel []rune) bool) {
+		e.KeyDown(shift)
+		defer e.KeyUp(shift)
+		n := 0
+		for stalls := 0; stalls < 2; { // На границе перенесенной строки стрелка только переводит курсор
+			press(fyne.KeyLeft)
+			sel := selected()
+			if len(sel) == n {
+				stalls++
+				continue
+			}
+			stalls, n = 0, len(sel)
+			if stop(sel) {
+				return
+			}
+		}
+	}
+
+	var sel []rune
+	selectBack(func(s []rune) bool {
+		sel = s
+		return len(s) > 1 && models.IsSnippetDelimiter(s[0]) || len(s) > models.MaxAbbreviationLength+1
+	})
+	if len(sel) < 2 {
+		press(fyne.KeyRight) // Курсор возвращается в конец выделения, где и был
+		return
+	}
+	word, before, after := sel[:len(sel)-1], "", string(sel[len(sel)-1])
+	if models.IsSnippetDelimiter(word[0]) {
+		before, word = string(word[0]), word[1:]
+	}
+	text, cursorBack, ok := e.Expand(string(word))
+	if !ok {
+		press(fyne.KeyRight)
+		return
+	}
+	for _, r := range before + text + after {
+		e.Entry.TypedRune(r) // Первый символ заменяет выделение
+	}
+	if cursorBack > 0 {
+		// Выделяем хвост расшифровки с разделителем и ставим курсор в его начало, на место {cursor}
+		selectBack(func(s []rune) bool { return len(s) >= cursorBack+1 })
+		press(fyne.KeyLeft)
+	}
+}
+
+// textFinder ищет текст с учетом регистра, целых слов и регулярных выражений
+type textFinder struct {
+	re        *regexp.Regexp
+	wholeWord bool
+	isRegex   bool
+}
+
+// newTextFinder готовит поиск query; без isRegex query ищется как обычный текст
+func newTextFinder(query string, caseSensitive, wholeWord, isRegex bool) (*textFinder, error) {
+	expr := query
+	if !isRegex {
+		expr = regexp.QuoteMeta(query)
+	}
+	if !caseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка в регулярном выражении: %w", err)
+	}
+	return &textFinder{re: re, wholeWord: wholeWord, isRegex: isRegex}, nil
+}
+
+// find возвращает непустые совпадения в text (границы в байтах, с подгруппами, как FindAllStringSubmatchIndex)
+func (f *textFinder) find(text string) [][]int {
+	var matches [][]int
+	for _, m := range f.re.FindAllStringSubmatchIndex(text, -1) {
+		if m[0] == m[1] || (f.wholeWord && !isWholeWord(text, m[0], m[1])) {
+			continue
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// replacement возвращает замену для совпадения m; в регулярном выражении $1 и ${name} подставляют группы
+func (f *textFinder) replacement(text string, m []int, replace string) string {
+	if !f.isRegex {
+		return replace
+	}
+	return string(f.re.ExpandString(nil, replace, text, m))
+}
+
+// replaceAll заменяет все совпадения и возвращает новый текст и количество замен
+func (f *textFinder) replaceAll(text, replace string) (string, int) {
+	matches := f.find(text)
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(text[last:m[0]])
+		b.WriteString(f.replacement(text, m, replace))
+		last = m[1]
+	}
+	b.WriteString(text[last:])
+	return b.String(), len(matches)
+}
+
+// isWholeWord сообщает, что text[start:end] не продолжает соседние слова.
+// \b в regexp знает только латиницу, поэтому границы проверяются по буквам Unicode.
+func isWholeWord(text string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(text[:start])
+	after, _ := utf8.DecodeRuneInString(text[end:])
+	return (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after))
+}
+
+// isWordRune сообщает, что символ может быть частью слова
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// FindReplaceView — панель поиска и замены над содержимым заметки
+type FindReplaceView struct {
+	findEntry    *widget.Entry
+	replaceEntry *widget.Entry
+	caseCheck    *widget.Check
+	wordCheck    *widget.Check
+	regexCheck   *widget.Check
+	status       *widget.Label
+	content      fyne.CanvasObject
+
+	OnFindNext   func()
+	OnReplace    func()
+	OnReplaceAll func()
+}
+
+// NewFindReplaceView создает панель поиска и замены; до Ctrl+H она скрыта
+func NewFindReplaceView() *FindReplaceView {
+	v := &FindReplaceView{
+		findEntry:    widget.NewEntry(),
+		replaceEntry: widget.NewEntry(),
+		caseCheck:    widget.NewCheck("Учитывать регистр", nil),
+		wordCheck:    widget.NewCheck("Слово целиком", nil),
+		regexCheck:   widget.NewCheck("Регулярное выражение", nil),
+		status:       widget.NewLabel(""),
+	}
+	v.findEntry.SetPlaceHolder("Найти")
+	v.findEntry.OnSubmitted = func(string) { v.OnFindNext() }
+	v.replaceEntry.SetPlaceHolder("Заменить на ($1 — группа регулярного выражения)")
+	v.replaceEntry.OnSubmitted = func(string) { v.OnReplace() }
+
+	closeButton := widget.NewButtonWithIcon("", theme.CancelIcon(), func() { v.content.Hide() })
+	v.content = container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(
+			widget.NewButtonWithIcon("Найти далее", theme.SearchIcon(), func() { v.OnFindNext() }),
+			closeButton,
+		), v.findEntry),
+		container.NewBorder(nil, nil, nil, container.NewHBox(
+			widget.NewButton("Заменить", func() { v.OnReplace() }),
+			widget.NewButton("Заменить все", func() { v.OnReplaceAll() }),
+		), v.replaceEntry),
+		container.NewHBox(v.caseCheck, v.wordCheck, v.regexCheck, layout.NewSpacer(), v.status),
+	)
+	v.content.Hide()
+	return v
+}
+
+// finder возвращает поиск по настройкам панели; при ошибке показывает ее в строке состояния
+func (v *FindReplaceView) finder() (*textFinder, bool) {
+	if v.findEntry.Text == "" {
+		v.status.SetText("Введите текст для поиска")
+		return nil, false
+	}
+	f, err := newTextFinder(v.findEntry.Text, v.caseCheck.Checked, v.wordCheck.Checked, v.regexCheck.Checked)
+	if err != nil {
+		v.status.SetText(err.Error())
+		return nil, false
+	}
+	return f, true
+}
+
+// showFindReplace показывает панель поиска и замены; выделенный текст становится искомым
+func (a *NoteApp) showFindReplace() {
+	v := a.findReplaceView
+	if selected := a.contentEntry.SelectedText(); selected != "" && !strings.Contains(selected, "\n") {
+		v.findEntry.SetText(selected)
+	}
+	v.status.SetText("")
+	a.resetFind()
+	v.content.Show()
+	a.window.Canvas().Focus(v.findEntry)
+}
+
+// resetFind начинает поиск с начала текста (например, после изменения содержимого)
+func (a *NoteApp) resetFind() {
+	a.findFrom, a.foundStart = 0, -1
+}
+
+// findNext выделяет следующее совпадение после предыдущего найденного, по кругу
+func (a *NoteApp) findNext() {
+	v := a.findReplaceView
+	finder, ok := v.finder()
+	if !ok {
+		return
+	}
+	text := a.contentText()
+	matches := finder.find(text)
+	if len(matches) == 0 {
+		a.foundStart = -1
+		v.status.SetText("Не найдено")
+		return
+	}
+	m := matches[0] // После последнего совпадения возвращаемся к первому
+	number := 1
+	for i, candidate := range matches {
+		if candidate[0] >= a.findFrom {
+			m, number = candidate, i+1
+			break
+		}
+	}
+	a.foundStart, a.findFrom = m[0], m[1]
+	if a.largeView != nil {
+		a.largeView.showLine(strings.Count(text[:m[0]], "\n"))
+	} else {
+		selectEntryRange(&a.contentEntry.Entry, utf8.RuneCountInString(text[:m[0]]), utf8.RuneCountInString(text[m[0]:m[1]]))
+	}
+	v.status.SetText(fmt.Sprintf("Совпадение %d из %d", number, len(matches)))
+}
+
+// replaceFound заменяет найденное совпадение и переходит к следующему
+func (a *NoteApp) replaceFound() {
+	v := a.findReplaceView
+	finder, ok := v.finder()
+	if !ok {
+		return
+	}
+	text := a.contentText()
+	for _, m := range finder.find(text) {
+		if m[0] != a.foundStart {
+			continue
+		}
+		replacement := finder.replacement(text, m, v.replaceEntry.Text)
+		a.setContentText(text[:m[0]] + replacement + text[m[1]:])
+		a.findFrom = m[0] + len(replacement) // Замену не ищем повторно
+		break
+	}
+	a.findNext()
+}
+
+// replaceAllFound заменяет все совпадения в текущей заметке
+func (a *NoteApp) replaceAllFound() {
+	v := a.findReplaceView
+	finder, ok := v.finder()
+	if !ok {
+		return
+	}
+	text, count := finder.replaceAll(a.contentText(), v.replaceEntry.Text)
+	if count > 0 {
+		a.setContentText(text)
+	}
+	v.status.SetText(fmt.Sprintf("Заменено: %d", count))
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/storage"
+)
+
+var (
+	healthOKColor   = color.NRGBA{R: 0x2e, G: 0xb8, B: 0x4b, A: 0xff}
+	healthDownColor = color.NRGBA{R: 0xd9, G: 0x30, B: 0x25, A: 0xff}
+)
+
+// HealthView показывает в нижней строке состояние связи с БД и кнопку переподключения
+type HealthView struct {
+	lamp            *canvas.Circle
+	label           *widget.Label
+	reconnectButton *widget.Button
+	content         fyne.CanvasObject
+
+	OnReconnect func()
+}
+
+// NewHealthView создает индикатор состояния БД; до первой проверки он скрыт
+func NewHealthView() *HealthView {
+	v := &HealthView{
+		lamp:  canvas.NewCircle(healthOKColor),
+		label: widget.NewLabel(""),
+	}
+	v.reconnectButton = widget.NewButtonWithIcon("Переподключиться", theme.ViewRefreshIcon(), func() {
+		if v.OnReconnect != nil {
+			v.OnReconnect()
+		}
+	})
+	v.reconnectButton.Hide()
+	lamp := container.NewCenter(container.NewGridWrap(fyne.NewSize(10, 10), v.lamp))
+	v.content = container.NewHBox(lamp, v.label, v.reconnectButton)
+	v.content.Hide()
+	return v
+}
+
+// show отображает результат проверки БД
+func (v *HealthView) show(h storage.Health) {
+	v.content.Show()
+	v.reconnectButton.Enable()
+	if h.Available {
+		v.lamp.FillColor = healthOKColor
+		v.label.SetText(fmt.Sprintf("БД: %d мс", h.Latency.Milliseconds()))
+		v.reconnectButton.Hide()
+	} else {
+		v.lamp.FillColor = healthDownColor
+		v.label.SetText("БД недоступна")
+		v.reconnectButton.Show()
+	}
+	v.lamp.Refresh()
+}
+
+// SetHealth подключает индикатор состояния БД к проверке связи.
+// После восстановления связи список заметок загружается заново.
+func (a *NoteApp) SetHealth(h *storage.HealthChecker) {
+	if a.unsubscribeHealth != nil {
+		a.unsubscribeHealth()
+	}
+	a.health = h
+	a.healthView.OnReconnect = func() {
+		a.healthView.reconnectButton.Disable()
+		go h.Check(time.Now())
+	}
+	a.healthView.show(h.Last())
+	wasAvailable := h.Last().Available
+	a.unsubscribeHealth = h.Subscribe(func(health storage.Health) {
+		fyne.Do(func() {
+			a.healthView.show(health)
+			if health.Available && !wasAvailable {
+				a.loadNotes() // Пока БД была недоступна, список мог устареть или не загрузиться
+			}
+			wasAvailable = health.Available
+		})
+	})
+}
+
+// showStoreError сообщает об ошибке обращения к хранилищу.
+// Потеря связи с БД показывается индикатором в нижней строке, а не отдельным диалогом на каждое действие.
+func (a *NoteApp) showStoreError(message string, err error) {
+	if a.health != nil && storage.IsUnavailable(err) {
+		a.health.MarkUnavailable(err)
+		return
+	}
+	dialog.ShowError(fmt.Errorf("%s: %w", message, err), a.window)
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/hooks"
+)
+
+// SetHooks задает обработчики событий; в окне заметок используются обработчики before-export
+func (a *NoteApp) SetHooks(h *hooks.Hooks) {
+	a.hooks = h
+}
+
+// runBeforeExport пропускает выгружаемый JSON через обработчики before-export в фоне
+// и передает результат в onReady. Если обработчик завершился с ошибкой, экспорт отменяется.
+func (a *NoteApp) runBeforeExport(data []byte, onReady func(data []byte)) {
+	if a.hooks == nil {
+		onReady(data)
+		return
+	}
+	progress := dialog.NewCustomWithoutButtons("Подготовка экспорта", widget.NewProgressBarInfinite(), a.window)
+	progress.Show()
+	h := a.hooks
+	go func() {
+		out, err := h.BeforeExport(data)
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				log.Printf("Экспорт отменен обработчиком before-export: %v", err)
+				dialog.ShowError(fmt.Errorf("экспорт отменен: %w", err), a.window)
+				return
+			}
+			onReady(out)
+		})
+	}()
+}
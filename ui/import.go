@@ -0,0 +1,298 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+	"GNote/storage"
+)
+
+// duplicateAction определяет, как поступить с импортируемой заметкой, совпавшей с существующей
+type duplicateAction int
+
+const (
+	duplicateAsk       duplicateAction = iota // Спросить пользователя
+	duplicateSkip                             // Пропустить импортируемую заметку
+	duplicateOverwrite                        // Перезаписать существующую заметку
+	duplicateKeepBoth                         // Создать копию рядом с существующей
+)
+
+// importSession хранит состояние пошагового импорта заметок: сначала для каждой заметки
+// решается, что с ней делать, затем весь план записывается в хранилище одной транзакцией
+type importSession struct {
+	export        *models.Export
+	notes         []models.Note
+	report        *importReport
+	byHash        map[string]importTarget // Существующие и запланированные заметки по хешу заголовка и содержимого
+	byID          map[int]importTarget    // Существующие и запланированные заметки по ID
+	byUID         map[string]importTarget // Существующие и запланированные заметки по UID
+	policy        duplicateAction         // Выбор, примененный ко всем оставшимся дубликатам
+	plan          []importEntry           // Заметки к импорту в порядке файла
+	newIDs        map[int]int             // ID заметок из файла → ID импортированных или совпавших заметок
+	importedCount int
+}
+
+// importTarget — заметка, с которой может совпасть импортируемая
+type importTarget struct {
+	note    models.Note
+	planned int // Индекс в плане для заметки из этого же файла; -1 — заметка уже есть в хранилище
+}
+
+// importEntry — запланированное действие с заметкой из файла
+type importEntry struct {
+	note      models.Note // Заметка для записи; ID существующей заметки означает перезапись
+	sourceID  int         // ID заметки в файле
+	duplicate string      // Пометка о дубликате для отчета
+	sameAs    int         // Индекс записи плана, вместо которой импортирована заметка; -1 — импортируется сама
+}
+
+// newImportSession создает сессию импорта и индексирует уже существующие заметки
+func newImportSession(export *models.Export, existing []models.Note, source string) *importSession {
+	s := &importSession{
+		export: export,
+		notes:  export.Notes,
+		report: newImportReport(source),
+		byHash: make(map[string]importTarget, len(existing)),
+		byID:   make(map[int]importTarget, len(existing)),
+		byUID:  make(map[string]importTarget, len(existing)),
+		policy: duplicateAsk,
+		newIDs: make(map[int]int, len(export.Notes)),
+	}
+	for _, note := range existing {
+		s.remember(note, -1)
+	}
+	return s
+}
+
+// remember добавляет заметку в индексы, чтобы находить дубликаты и внутри импортируемого файла
+func (s *importSession) remember(note models.Note, planned int) {
+	target := importTarget{note: note, planned: planned}
+	s.byHash[note.ContentHash()] = target
+	if note.ID != 0 {
+		s.byID[note.ID] = target
+	}
+	if note.UID != "" {
+		s.byUID[note.UID] = target
+	}
+}
+
+// findDuplicate ищет существующую или уже запланированную заметку, совпадающую с импортируемой.
+// UID одинаков во всех базах, поэтому совпадение по нему надежнее всего;
+// совпадение по заголовку и содержимому важнее совпадения по ID.
+func (s *importSession) findDuplicate(note models.Note) (importTarget, string, bool) {
+	if note.UID != "" {
+		if target, ok := s.byUID[note.UID]; ok {
+			return target, "совпадает UID", true
+		}
+	}
+	if target, ok := s.byHash[note.ContentHash()]; ok {
+		return target, "совпадают заголовок и содержимое", true
+	}
+	if note.ID != 0 {
+		if target, ok := s.byID[note.ID]; ok {
+			return target, fmt.Sprintf("совпадает ID %d", note.ID), true
+		}
+	}
+	return importTarget{}, "", false
+}
+
+// add добавляет заметку в план импорта
+func (s *importSession) add(note models.Note, sourceID int, duplicate string) {
+	s.plan = append(s.plan, importEntry{note: note, sourceID: sourceID, duplicate: duplicate, sameAs: -1})
+	s.remember(note, len(s.plan)-1)
+}
+
+// startImport запускает импорт заметок из файла экспорта с проверкой дубликатов
+func (a *NoteApp) startImport(export *models.Export, source string) {
+	existing := slices.Clone(a.allNotes)
+	// Дубликаты ищутся по всему содержимому, а у больших заметок в списке только превью
+	if err := storage.LoadContent(a.store, existing); err != nil {
+		log.Printf("Дубликаты больших заметок при импорте могут не найтись: %v", err)
+	}
+	session := newImportSession(export, existing, source)
+	a.importFrom(session, 0)
+}
+
+// importFrom планирует импорт заметок начиная с индекса i, останавливаясь на дубликатах для вопроса пользователю
+func (a *NoteApp) importFrom(s *importSession, i int) {
+	for ; i < len(s.notes); i++ {
+		note := s.notes[i]
+		// Fyne DatePicker/TimePicker не возвращают часовой пояс, поэтому убедимся, что время в UTC, если это важно
+		if note.ReminderAt != nil && note.ReminderAt.Location().String() == "Local" {
+			utcTime := note.ReminderAt.In(time.UTC)
+			note.ReminderAt = &utcTime
+		}
+
+		target, reason, found := s.findDuplicate(note)
+		if !found {
+			sourceID := note.ID
+			note.ID = 0 // Хранилище выдаст новый ID
+			s.add(note, sourceID, "")
+			continue
+		}
+		if s.policy != duplicateAsk {
+			resolveDuplicate(s, note, target, reason, s.policy)
+			continue
+		}
+
+		next := i + 1
+		a.askDuplicateAction(note, target, reason, func(action duplicateAction, applyToAll bool) {
+			if applyToAll {
+				s.policy = action
+			}
+			resolveDuplicate(s, note, target, reason, action)
+			a.importFrom(s, next)
+		})
+		return // Продолжим после ответа пользователя
+	}
+	a.finishImport(s)
+}
+
+// resolveDuplicate добавляет в план выбранное действие с импортируемой заметкой-дубликатом
+func resolveDuplicate(s *importSession, note models.Note, target importTarget, reason string, action duplicateAction) {
+	existing := target.note
+	switch action {
+	case duplicateSkip:
+		if target.planned >= 0 {
+			s.plan = append(s.plan, importEntry{sourceID: note.ID, sameAs: target.planned})
+			s.report.addDuplicate(note.Title, fmt.Sprintf("пропущена (%s с заметкой '%s' из этого же файла)", reason, existing.Title))
+			return
+		}
+		s.newIDs[note.ID] = existing.ID
+		s.report.addDuplicate(note.Title, fmt.Sprintf("пропущена (%s с заметкой ID %d)", reason, existing.ID))
+	case duplicateOverwrite:
+		if note.CreatedAt.IsZero() {
+			note.CreatedAt = existing.CreatedAt
+		}
+		sourceID := note.ID
+		note.ID, note.UID = existing.ID, existing.UID // Хранилище сопоставляет заметки по UID, затем по ID
+		if target.planned >= 0 {
+			// Заметка из этого же файла еще не записана: заменяем ее в плане
+			entry := &s.plan[target.planned]
+			entry.note = note
+			entry.duplicate = fmt.Sprintf("заменена заметкой '%s' из этого же файла (%s)", note.Title, reason)
+			s.plan = append(s.plan, importEntry{sourceID: sourceID, sameAs: target.planned})
+			s.remember(note, target.planned)
+			return
+		}
+		s.add(note, sourceID, fmt.Sprintf("перезаписана заметка ID %d (%s)", existing.ID, reason))
+	case duplicateKeepBoth:
+		sourceID := note.ID
+		note.ID, note.UID = 0, "" // Копия — отдельная заметка со своим UID
+		if target.planned >= 0 {
+			s.add(note, sourceID, fmt.Sprintf("создана копия заметки '%s' из этого же файла (%s)", existing.Title, reason))
+			return
+		}
+		s.add(note, sourceID, fmt.Sprintf("создана копия заметки ID %d (%s)", existing.ID, reason))
+	}
+}
+
+// finishImport записывает план импорта одной транзакцией, восстанавливает организацию,
+// обновляет список заметок и показывает отчет об импорте
+func (a *NoteApp) finishImport(s *importSession) {
+	var notes []models.Note
+	sent := make([]int, len(s.plan)) // Индекс записи плана → индекс в notes
+	for i, entry := range s.plan {
+		if entry.sameAs < 0 {
+			sent[i] = len(notes)
+			notes = append(notes, entry.note)
+		}
+	}
+	if len(notes) > 0 {
+		result, err := a.store.ImportNotes(notes)
+		if err != nil {
+			log.Printf("Ошибка при импорте заметок: %v", err)
+			a.showStoreError("не удалось импортировать заметки", err)
+			return
+		}
+		a.applyImportResults(s, result, sent)
+	}
+
+	a.importOrganization(s)
+	if s.importedCount > 0 || len(s.report.Organization) > 0 {
+		a.loadNotes() // Перезагружаем список после импорта
+		a.newNote()
+	}
+	a.showImportReport(s.report)
+}
+
+// applyImportResults переносит итоги записи в хранилище в отчет и сопоставляет ID из файла с новыми ID
+func (a *NoteApp) applyImportResults(s *importSession, result models.ImportReport, sent []int) {
+	for i, entry := range s.plan {
+		if entry.sameAs >= 0 {
+			continue
+		}
+		res := result.Results[sent[i]]
+		title := entry.note.Title
+		if res.Err != nil {
+			log.Printf("Ошибка при импорте заметки '%s': %v", title, res.Err)
+			if entry.note.ID != 0 {
+				s.report.addSkipped(title, fmt.Sprintf("не удалось перезаписать заметку ID %d: %v", entry.note.ID, res.Err))
+			} else {
+				s.report.addSkipped(title, fmt.Sprintf("не удалось создать заметку: %v", res.Err))
+			}
+			continue
+		}
+		if entry.sourceID != 0 {
+			s.newIDs[entry.sourceID] = res.Note.ID
+		}
+		if entry.duplicate != "" {
+			s.report.addDuplicate(title, entry.duplicate)
+		}
+		s.importedCount++
+		s.report.addImported(title)
+		for _, failed := range res.FailedAttachments {
+			log.Printf("Вложение '%s' заметки ID %d не импортировано: %s", failed.Filename, res.Note.ID, failed.Reason)
+			s.report.addFailedAttachment(title, failed.Filename, failed.Reason)
+		}
+	}
+	// Пропущенные и замененные дубликаты получают ID заметок, вместо которых импортированы
+	for _, entry := range s.plan {
+		if entry.sameAs < 0 || entry.sourceID == 0 {
+			continue
+		}
+		if res := result.Results[sent[entry.sameAs]]; res.Err == nil {
+			s.newIDs[entry.sourceID] = res.Note.ID
+		}
+	}
+}
+
+// askDuplicateAction спрашивает пользователя, что делать с найденным дубликатом
+func (a *NoteApp) askDuplicateAction(note models.Note, target importTarget, reason string, onChosen func(action duplicateAction, applyToAll bool)) {
+	text := fmt.Sprintf("Импортируемая заметка '%s' совпадает с существующей заметкой '%s' (ID %d): %s.\nЧто сделать?",
+		note.Title, target.note.Title, target.note.ID, reason)
+	if target.planned >= 0 {
+		text = fmt.Sprintf("Импортируемая заметка '%s' совпадает с заметкой '%s' из этого же файла: %s.\nЧто сделать?",
+			note.Title, target.note.Title, reason)
+	}
+	message := widget.NewLabel(text)
+	message.Wrapping = fyne.TextWrapWord
+	applyToAll := widget.NewCheck("Применить ко всем оставшимся дубликатам", nil)
+
+	var d dialog.Dialog
+	choose := func(action duplicateAction) func() {
+		return func() {
+			d.Hide()
+			onChosen(action, applyToAll.Checked)
+		}
+	}
+	buttons := container.NewHBox(
+		layout.NewSpacer(),
+		widget.NewButton("Пропустить", choose(duplicateSkip)),
+		widget.NewButton("Перезаписать", choose(duplicateOverwrite)),
+		widget.NewButton("Создать копию", choose(duplicateKeepBoth)),
+	)
+	content := container.NewVBox(message, applyToAll, buttons)
+	d = dialog.NewCustomWithoutButtons("Найден дубликат", content, a.window)
+	d.Resize(fyne.NewSize(500, 0))
+	d.Show()
+}
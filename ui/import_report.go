@@ -0,0 +1,182 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// importIssue описывает одну проблему, возникшую при импорте заметки
+type importIssue struct {
+	Title  string // Заголовок заметки, к которой относится проблема
+	Reason string // Причина (текст ошибки или пояснение)
+}
+
+// importReport собирает результаты импорта по каждой заметке
+type importReport struct {
+	Source            string        // Имя импортируемого файла
+	StartedAt         time.Time     // Время начала импорта
+	Imported          []string      // Заголовки успешно импортированных заметок
+	Skipped           []importIssue // Заметки, которые не удалось импортировать
+	FailedAttachments []importIssue // Вложения, которые не удалось импортировать
+	Duplicates        []importIssue // Заметки, совпавшие с уже существующими
+	Organization      []string      // Восстановленное оформление тегов, правила и настройки
+}
+
+// newImportReport создает пустой отчет об импорте
+func newImportReport(source string) *importReport {
+	return &importReport{
+		Source:    source,
+		StartedAt: time.Now(),
+	}
+}
+
+// addImported отмечает заметку как успешно импортированную
+func (r *importReport) addImported(title string) {
+	r.Imported = append(r.Imported, title)
+}
+
+// addSkipped отмечает заметку как пропущенную
+func (r *importReport) addSkipped(title string, reason string) {
+	r.Skipped = append(r.Skipped, importIssue{Title: title, Reason: reason})
+}
+
+// addFailedAttachment отмечает вложение заметки, которое не удалось импортировать
+func (r *importReport) addFailedAttachment(noteTitle, filename, reason string) {
+	r.FailedAttachments = append(r.FailedAttachments, importIssue{
+		Title:  noteTitle,
+		Reason: fmt.Sprintf("%s: %s", filename, reason),
+	})
+}
+
+// addDuplicate отмечает заметку, совпавшую с уже существующей
+func (r *importReport) addDuplicate(title string, reason string) {
+	r.Duplicates = append(r.Duplicates, importIssue{Title: title, Reason: reason})
+}
+
+// addOrganization отмечает восстановленный элемент организации заметок
+func (r *importReport) addOrganization(item string) {
+	r.Organization = append(r.Organization, item)
+}
+
+// hasProblems возвращает true, если при импорте были пропуски, ошибки или дубликаты
+func (r *importReport) hasProblems() bool {
+	return len(r.Skipped) > 0 || len(r.FailedAttachments) > 0 || len(r.Duplicates) > 0
+}
+
+// summary возвращает краткую сводку по импорту
+func (r *importReport) summary() string {
+	summary := fmt.Sprintf("Импортировано: %d | Пропущено: %d | Ошибки вложений: %d | Дубликаты: %d",
+		len(r.Imported), len(r.Skipped), len(r.FailedAttachments), len(r.Duplicates))
+	if len(r.Organization) > 0 {
+		summary += fmt.Sprintf(" | Организация: %d", len(r.Organization))
+	}
+	return summary
+}
+
+// String формирует текстовый отчет, пригодный для сохранения в файл
+func (r *importReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Отчет об импорте заметок\n")
+	fmt.Fprintf(&b, "Файл: %s\n", r.Source)
+	fmt.Fprintf(&b, "Дата: %s\n", r.StartedAt.Format("02.01.2006 15:04:05"))
+	fmt.Fprintf(&b, "%s\n", r.summary())
+
+	writeSection := func(header string, issues []importIssue) {
+		if len(issues) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s (%d):\n", header, len(issues))
+		for _, issue := range issues {
+			fmt.Fprintf(&b, "  - %s: %s\n", issue.Title, issue.Reason)
+		}
+	}
+	writeSection("Пропущенные заметки", r.Skipped)
+	writeSection("Ошибки вложений", r.FailedAttachments)
+	writeSection("Дубликаты", r.Duplicates)
+
+	if len(r.Imported) > 0 {
+		fmt.Fprintf(&b, "\nИмпортированные заметки (%d):\n", len(r.Imported))
+		for _, title := range r.Imported {
+			fmt.Fprintf(&b, "  - %s\n", title)
+		}
+	}
+	if len(r.Organization) > 0 {
+		fmt.Fprintf(&b, "\nВосстановленная организация (%d):\n", len(r.Organization))
+		for _, item := range r.Organization {
+			fmt.Fprintf(&b, "  - %s\n", item)
+		}
+	}
+	return b.String()
+}
+
+// showImportReport показывает диалог с итогами импорта и возможностью сохранить отчет
+func (a *NoteApp) showImportReport(report *importReport) {
+	makeSection := func(header string, issues []importIssue) *widget.AccordionItem {
+		lines := make([]string, 0, len(issues))
+		for _, issue := range issues {
+			lines = append(lines, fmt.Sprintf("%s: %s", issue.Title, issue.Reason))
+		}
+		label := widget.NewLabel(strings.Join(lines, "\n"))
+		label.Wrapping = fyne.TextWrapWord
+		return widget.NewAccordionItem(fmt.Sprintf("%s (%d)", header, len(issues)), label)
+	}
+
+	accordion := widget.NewAccordion()
+	if len(report.Skipped) > 0 {
+		accordion.Append(makeSection("Пропущенные заметки", report.Skipped))
+	}
+	if len(report.FailedAttachments) > 0 {
+		accordion.Append(makeSection("Ошибки вложений", report.FailedAttachments))
+	}
+	if len(report.Duplicates) > 0 {
+		accordion.Append(makeSection("Дубликаты", report.Duplicates))
+	}
+
+	var details fyne.CanvasObject = widget.NewLabel("Импорт завершен без ошибок.")
+	if report.hasProblems() {
+		scroll := container.NewVScroll(accordion)
+		scroll.SetMinSize(fyne.NewSize(500, 250))
+		details = scroll
+	}
+
+	saveButton := widget.NewButton("Сохранить отчет", func() {
+		a.saveImportReport(report)
+	})
+
+	content := container.NewBorder(
+		widget.NewLabel(report.summary()),
+		container.NewHBox(saveButton),
+		nil,
+		nil,
+		details,
+	)
+	dialog.ShowCustom("Итоги импорта", "Закрыть", content, a.window)
+}
+
+// saveImportReport сохраняет текстовый отчет об импорте в выбранный пользователем файл
+func (a *NoteApp) saveImportReport(report *importReport) {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if writer == nil { // Пользователь отменил
+			return
+		}
+		defer writer.Close()
+
+		if _, err := writer.Write([]byte(report.String())); err != nil {
+			dialog.ShowError(fmt.Errorf("ошибка при записи отчета: %w", err), a.window)
+			return
+		}
+		dialog.ShowInformation("Отчет", "Отчет об импорте сохранен.", a.window)
+	}, a.window)
+	saveDialog.SetFileName(fmt.Sprintf("import_report_%s.txt", report.StartedAt.Format("20060102_150405")))
+	saveDialog.Show()
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"GNote/journal"
+)
+
+// SetContextProviders задает провайдеров контекста, добавляемого в новые дневниковые записи
+func (a *NoteApp) SetContextProviders(providers []journal.ContextProvider) {
+	a.contextProviders = providers
+}
+
+// openJournal открывает дневниковую запись за сегодня или готовит новую
+func (a *NoteApp) openJournal() {
+	if a.hasUnsavedChanges() {
+		a.showUnsavedChangesDialog(a.doOpenJournal)
+	} else {
+		a.doOpenJournal()
+	}
+}
+
+// doOpenJournal выполняет открытие дневниковой записи после проверки изменений
+func (a *NoteApp) doOpenJournal() {
+	now := time.Now()
+	title := journal.Title(now)
+	for _, note := range a.allNotes {
+		if note.Title == title {
+			a.OpenNoteByID(note.ID)
+			return
+		}
+	}
+
+	a.doNewNote()
+	a.titleEntry.SetText(title)
+	a.tagsEntry.SetText("дневник")
+	a.setUnsavedChanges(true)
+	log.Printf("Подготовлена новая дневниковая запись: %s", title)
+
+	providers := a.contextProviders
+	if len(providers) == 0 {
+		providers = []journal.ContextProvider{journal.DateProvider{}}
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		stamp := journal.Stamp(ctx, now, providers)
+		if stamp == "" {
+			return
+		}
+		fyne.Do(func() {
+			// Пользователь мог уже перейти к другой заметке
+			if a.getSelectedNote() != nil || a.editedTitle() != title {
+				return
+			}
+			content := stamp + "\n\n" + a.contentText()
+			a.contentEntry.SetText(content)
+		})
+	}()
+}
@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	largeNoteThreshold = 200 * 1024 // Начиная с этого размера содержимое показывается построчно
+	largeNoteChunk     = 200        // Количество строк в редактируемом фрагменте
+)
+
+// largeTextView показывает большой текст виртуализированным списком строк:
+// отрисовываются только видимые строки, а редактирование идет фрагментами.
+type largeTextView struct {
+	lines     []string
+	list      *widget.List
+	info      *widget.Label
+	content   fyne.CanvasObject
+	window    fyne.Window
+	onChanged func()
+}
+
+// newLargeTextView создает просмотрщик большого текста; onFullEdit переключает на обычный редактор
+func newLargeTextView(win fyne.Window, text string, onChanged func(), onFullEdit func()) *largeTextView {
+	v := &largeTextView{
+		lines:     strings.Split(text, "\n"),
+		window:    win,
+		onChanged: onChanged,
+	}
+
+	v.list = widget.NewList(
+		func() int {
+			return len(v.lines)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(v.lines[i])
+		},
+	)
+	v.list.OnSelected = func(id widget.ListItemID) {
+		v.list.Unselect(id)
+		v.editChunk(id)
+	}
+
+	v.info = widget.NewLabel("")
+	v.updateInfo()
+	fullEditButton := widget.NewButtonWithIcon("Обычный редактор", theme.DocumentCreateIcon(), onFullEdit)
+
+	v.content = container.NewBorder(
+		container.NewHBox(v.info, layout.NewSpacer(), fullEditButton),
+		nil, nil, nil,
+		v.list,
+	)
+	return v
+}
+
+// Text возвращает текущий текст целиком
+func (v *largeTextView) Text() string {
+	return strings.Join(v.lines, "\n")
+}
+
+// setText заменяет текст целиком (например, после замены в поиске)
+func (v *largeTextView) setText(text string) {
+	v.lines = strings.Split(text, "\n")
+	v.updateInfo()
+	v.list.Refresh()
+	v.onChanged()
+}
+
+// showLine прокручивает просмотр к строке line
+func (v *largeTextView) showLine(line int) {
+	v.list.ScrollTo(line)
+}
+
+// updateInfo обновляет подсказку о режиме просмотра
+func (v *largeTextView) updateInfo() {
+	v.info.SetText(fmt.Sprintf("Большая заметка: %d строк. Нажмите на строку, чтобы редактировать фрагмент.", len(v.lines)))
+}
+
+// editChunk открывает для редактирования фрагмент, содержащий строку line
+func (v *largeTextView) editChunk(line int) {
+	start := line - line%largeNoteChunk
+	end := start + largeNoteChunk
+	if end > len(v.lines) {
+		end = len(v.lines)
+	}
+
+	entry := widget.NewMultiLineEntry()
+	entry.Wrapping = fyne.TextWrapWord
+	entry.SetText(strings.Join(v.lines[start:end], "\n"))
+	scroll := container.NewScroll(entry)
+	scroll.SetMinSize(fyne.NewSize(700, 450))
+
+	title := fmt.Sprintf("Строки %d–%d", start+1, end)
+	dialog.ShowCustomConfirm(title, "Применить", "Отмена", scroll, func(ok bool) {
+		if !ok {
+			return
+		}
+		chunk := strings.Split(entry.Text, "\n")
+		lines := make([]string, 0, len(v.lines)-(end-start)+len(chunk))
+		lines = append(lines, v.lines[:start]...)
+		lines = append(lines, chunk...)
+		lines = append(lines, v.lines[end:]...)
+		v.lines = lines
+		v.updateInfo()
+		v.list.Refresh()
+		log.Printf("Изменен фрагмент большой заметки: %s", title)
+		v.onChanged()
+	}, v.window)
+}
+
+// contentText возвращает содержимое заметки из активного редактора
+func (a *NoteApp) contentText() string {
+	if a.largeView != nil {
+		return a.largeView.Text()
+	}
+	content, _ := a.noteContent.Get()
+	return content
+}
+
+// showContent показывает содержимое заметки: большие заметки — построчным просмотрщиком,
+// остальные — в обычном редакторе
+func (a *NoteApp) showContent(text string) {
+	defer a.updateLinkPreviews() // Карточки ссылок открытой заметки показываются сразу, без паузы
+	if len(text) <= largeNoteThreshold {
+		a.useEntryEditor(text)
+		return
+	}
+	a.largeView = newLargeTextView(a.window, text, func() {
+		a.setUnsavedChanges(true)
+		a.updateCharCount()
+		a.updateMatches()
+		a.resetFind()
+	}, func() {
+		wasDirty := a.hasUnsavedChanges()
+		a.useEntryEditor(a.largeView.Text())
+		a.setUnsavedChanges(wasDirty)
+	})
+	a.contentEntry.SetText("") // Не держим большой текст в обычном редакторе
+	a.contentArea.Objects = []fyne.CanvasObject{a.largeView.content}
+	a.contentArea.Refresh()
+	log.Printf("Заметка размером %s открыта в построчном режиме", formatBytes(int64(len(text))))
+}
+
+// setContentText заменяет содержимое заметки в активном редакторе
+func (a *NoteApp) setContentText(text string) {
+	if a.largeView != nil {
+		a.largeView.setText(text)
+		return
+	}
+	a.contentEntry.SetText(text)
+}
+
+// useEntryEditor переключает область содержимого на обычный редактор
+func (a *NoteApp) useEntryEditor(text string) {
+	if a.largeView != nil {
+		a.largeView = nil
+		a.contentArea.Objects = []fyne.CanvasObject{a.contentScroll}
+		a.contentArea.Refresh()
+	}
+	a.contentEntry.SetText(text)
+}
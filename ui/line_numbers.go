@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// goToLineShortcut открывает переход к строке содержимого
+var goToLineShortcut = &desktop.CustomShortcut{KeyName: fyne.KeyG, Modifier: fyne.KeyModifierControl}
+
+const lineNumbersPreference = "line_numbers"
+
+// restoreLineNumbers показывает номера строк, если они были включены в прошлый раз
+func (a *NoteApp) restoreLineNumbers() {
+	if fyne.CurrentApp().Preferences().Bool(profileKey(lineNumbersPreference, a.profile)) {
+		a.setLineNumbers(true)
+	}
+}
+
+// toggleLineNumbers показывает или скрывает номера строк и запоминает выбор для профиля
+func (a *NoteApp) toggleLineNumbers() {
+	show := !a.lineNumbers.Visible()
+	a.setLineNumbers(show)
+	fyne.CurrentApp().Preferences().SetBool(profileKey(lineNumbersPreference, a.profile), show)
+}
+
+// setLineNumbers показывает или скрывает номера строк. С номерами строки не переносятся, чтобы строке текста
+// соответствовала одна строка на экране; поле растягивается на весь текст и прокручивается вместе с номерами.
+func (a *NoteApp) setLineNumbers(show bool) {
+	e := a.contentEntry
+	if show {
+		e.Wrapping = fyne.TextWrapOff
+		e.Scroll = fyne.ScrollNone
+		a.updateLineNumbers()
+		a.lineNumbers.Show()
+	} else {
+		e.Wrapping = fyne.TextWrapWord
+		e.Scroll = fyne.ScrollBoth
+		a.lineNumbers.Hide()
+	}
+	e.Refresh()
+	a.contentScroll.Refresh()
+	a.refreshMainMenu()
+}
+
+// updateLineNumbers перенумеровывает строки, если их количество изменилось
+func (a *NoteApp) updateLineNumbers() {
+	lines := strings.Count(a.contentText(), "\n") + 1
+	if strings.Count(a.lineNumbers.Text, "\n")+1 == lines {
+		return
+	}
+	var b strings.Builder
+	for i := 1; i <= lines; i++ {
+		if i > 1 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(strconv.Itoa(i))
+	}
+	a.lineNumbers.SetText(b.String())
+}
+
+// followCursor прокручивает содержимое к строке курсора. Без переноса строк поле не прокручивается само:
+// оно занимает весь текст, а прокрутка у contentScroll.
+func (a *NoteApp) followCursor() {
+	if !a.lineNumbers.Visible() {
+		return
+	}
+	lineHeight := fyne.MeasureText("M", theme.TextSize(), a.contentEntry.TextStyle).Height
+	top := float32(a.contentEntry.CursorRow) * lineHeight
+	bottom := top + lineHeight + 2*theme.InnerPadding()
+	offset := a.contentScroll.Offset
+	switch view := a.contentScroll.Size().Height; {
+	case top < offset.Y:
+		offset.Y = top
+	case bottom > offset.Y+view:
+		offset.Y = bottom - view
+	default:
+		return
+	}
+	a.contentScroll.ScrollToOffset(offset)
+}
+
+// showGoToLine спрашивает номер строки и переводит на нее курсор
+func (a *NoteApp) showGoToLine() {
+	text := a.contentText()
+	lines := strings.Count(text, "\n") + 1
+	lineEntry := widget.NewEntry()
+	lineEntry.SetPlaceHolder(fmt.Sprintf("От 1 до %d", lines))
+	items := []*widget.FormItem{widget.NewFormItem("Строка", lineEntry)}
+	d := dialog.NewForm("Перейти к строке", "Перейти", "Отмена", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		line, err := strconv.Atoi(strings.TrimSpace(lineEntry.Text))
+		if err != nil || line < 1 || line > lines {
+			dialog.ShowError(fmt.Errorf("номер строки должен быть от 1 до %d", lines), a.window)
+			return
+		}
+		a.goToLine(text, line)
+	}, a.window)
+	lineEntry.OnSubmitted = func(string) { d.Submit() }
+	d.Show()
+	a.window.Canvas().Focus(lineEntry)
+}
+
+// goToLine ставит курсор в начало строки line (с 1) текста text
+func (a *NoteApp) goToLine(text string, line int) {
+	if a.largeView != nil {
+		a.largeView.showLine(line - 1)
+		return
+	}
+	start := 0
+	for i := 1; i < line; i++ {
+		start += strings.IndexByte(text[start:], '\n') + 1
+	}
+	a.window.Canvas().Focus(a.contentEntry)
+	selectEntryRange(&a.contentEntry.Entry, utf8.RuneCountInString(text[:start]), 0)
+}
@@ -0,0 +1,151 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"image"
+	_ "image/gif" // Значки сайтов бывают в GIF, JPEG и PNG
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"net/url"
+	"slices"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/linkpreview"
+)
+
+const (
+	maxLinkPreviews    = 5                // Сколько ссылок заметки показываются карточками
+	linkPreviewDelay   = time.Second      // Пауза в наборе, после которой ссылки ищутся заново
+	linkPreviewTimeout = 20 * time.Second // Сколько ждать загрузки одной карточки
+	maxPreviewText     = 120              // Длина описания на карточке
+	previewIconSize    = float32(16)      // Размер значка сайта
+)
+
+// LinkPreviewView — карточки ссылок из содержимого заметки под редактором
+type LinkPreviewView struct {
+	box     *fyne.Container
+	content fyne.CanvasObject
+
+	fetcher *linkpreview.Fetcher // nil — карточки выключены
+	urls    []string             // Адреса, для которых показаны карточки
+	timer   *time.Timer
+	cancel  context.CancelFunc // Отменяет загрузку карточек прошлого набора ссылок
+}
+
+// NewLinkPreviewView создает строку карточек; пока ссылок нет, она скрыта
+func NewLinkPreviewView() *LinkPreviewView {
+	v := &LinkPreviewView{box: container.NewHBox(), cancel: func() {}}
+	v.content = container.NewHScroll(v.box)
+	v.content.Hide()
+	return v
+}
+
+// SetLinkPreviews задает загрузчик карточек ссылок; nil — карточки не показываются
+func (a *NoteApp) SetLinkPreviews(f *linkpreview.Fetcher) {
+	a.linkPreviewView.fetcher = f
+	a.updateLinkPreviews()
+}
+
+// scheduleLinkPreviews обновляет карточки, когда пользователь сделает паузу в наборе
+func (a *NoteApp) scheduleLinkPreviews() {
+	v := a.linkPreviewView
+	if v.fetcher == nil {
+		return
+	}
+	if v.timer != nil {
+		v.timer.Stop()
+	}
+	v.timer = time.AfterFunc(linkPreviewDelay, func() { fyne.Do(a.updateLinkPreviews) })
+}
+
+// updateLinkPreviews показывает карточки ссылок из содержимого заметки. Незагруженная карточка
+// показывает только адрес: страница загружается по кнопке, а не при открытии заметки, чтобы
+// простой просмотр заметки не сообщал сайтам из нее, что ее открыли.
+func (a *NoteApp) updateLinkPreviews() {
+	v := a.linkPreviewView
+	var urls []string
+	if v.fetcher != nil {
+		urls = linkpreview.FindURLs(a.contentText(), maxLinkPreviews)
+	}
+	if slices.Equal(urls, v.urls) {
+		return
+	}
+	v.urls = urls
+	v.cancel()
+	v.box.RemoveAll()
+	if len(urls) == 0 {
+		v.content.Hide()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	v.cancel = cancel
+	fetcher := v.fetcher
+	for _, rawURL := range urls {
+		card := container.NewStack()
+		if preview, ok := fetcher.Cached(rawURL); ok {
+			card.Objects = []fyne.CanvasObject{newLinkCard(preview)}
+		} else {
+			var load *widget.Button
+			load = widget.NewButtonWithIcon("", theme.DownloadIcon(), func() {
+				load.Disable()
+				go func() {
+					fetchCtx, fetchCancel := context.WithTimeout(ctx, linkPreviewTimeout)
+					defer fetchCancel()
+					preview, err := fetcher.Get(fetchCtx, rawURL)
+					fyne.Do(func() {
+						if ctx.Err() != nil {
+							return // Ссылки в заметке уже другие
+						}
+						if err != nil {
+							log.Printf("Ошибка при загрузке карточки ссылки %s: %v", rawURL, err)
+							load.Enable() // Карточка остается с одним адресом
+							return
+						}
+						card.Objects = []fyne.CanvasObject{newLinkCard(preview)}
+						card.Refresh()
+					})
+				}()
+			})
+			card.Objects = []fyne.CanvasObject{container.NewBorder(nil, nil, nil, load,
+				newLinkCard(&linkpreview.Preview{URL: rawURL, Title: rawURL}))}
+		}
+		v.box.Add(card)
+	}
+	v.content.Show()
+	v.box.Refresh()
+}
+
+// newLinkCard создает карточку ссылки: значок, заголовок-ссылка, сайт и описание
+func newLinkCard(p *linkpreview.Preview) fyne.CanvasObject {
+	var icon fyne.CanvasObject = widget.NewIcon(theme.FileIcon())
+	if img, _, err := image.Decode(bytes.NewReader(p.Icon)); err == nil {
+		favicon := canvas.NewImageFromImage(img)
+		favicon.FillMode = canvas.ImageFillContain
+		favicon.SetMinSize(fyne.NewSize(previewIconSize, previewIconSize))
+		icon = favicon
+	}
+	u, _ := url.Parse(p.URL) // Адрес уже проверен при поиске ссылок
+	title := widget.NewHyperlink(truncateTitle(p.Title, maxFavoriteTitle), u)
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	rows := container.NewVBox(container.NewHBox(icon, title))
+	if p.SiteName != "" {
+		site := widget.NewLabel(p.SiteName)
+		site.Importance = widget.LowImportance
+		rows.Add(site)
+	}
+	if p.Description != "" {
+		rows.Add(widget.NewLabel(truncateTitle(p.Description, maxPreviewText)))
+	}
+	bg := canvas.NewRectangle(theme.Color(theme.ColorNameInputBackground))
+	bg.CornerRadius = theme.InputRadiusSize()
+	return container.NewStack(bg, container.NewPadded(rows))
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/linkpreview"
+)
+
+// linkCheckWorkers — сколько ссылок проверяется одновременно
+const linkCheckWorkers = 4
+
+// LinksView — вкладка со всеми ссылками заметки: открыть, скопировать, проверить, работают ли они
+type LinksView struct {
+	tab         *container.TabItem
+	content     fyne.CanvasObject
+	list        *widget.List
+	checkButton *widget.Button
+
+	urls     []string
+	statuses map[string]linkpreview.Status // Результаты проверки за сеанс по адресам
+
+	OnOpen func(u *url.URL)
+	OnCopy func(rawURL string)
+}
+
+// NewLinksView создает вкладку ссылок заметки
+func NewLinksView() *LinksView {
+	v := &LinksView{statuses: make(map[string]linkpreview.Status)}
+	v.list = widget.NewList(
+		func() int { return len(v.urls) },
+		func() fyne.CanvasObject {
+			status := widget.NewLabel("")
+			status.Importance = widget.LowImportance
+			openButton := widget.NewButtonWithIcon("", theme.ComputerIcon(), nil)
+			copyButton := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), nil)
+			return container.NewBorder(nil, nil, nil, container.NewHBox(status, openButton, copyButton), widget.NewLabel(""))
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			if i >= len(v.urls) {
+				return
+			}
+			rawURL := v.urls[i]
+			row := o.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(truncateTitle(rawURL, 80))
+			buttons := row.Objects[1].(*fyne.Container)
+			status := buttons.Objects[0].(*widget.Label)
+			if s, ok := v.statuses[rawURL]; ok {
+				status.SetText(s.String())
+				status.Importance = widget.SuccessImportance
+				if !s.Alive() {
+					status.Importance = widget.DangerImportance
+				}
+			} else {
+				status.SetText("")
+				status.Importance = widget.LowImportance
+			}
+			status.Refresh()
+			buttons.Objects[1].(*widget.Button).OnTapped = func() {
+				if u, err := url.Parse(rawURL); err == nil {
+					v.OnOpen(u)
+				}
+			}
+			buttons.Objects[2].(*widget.Button).OnTapped = func() { v.OnCopy(rawURL) }
+		},
+	)
+	v.checkButton = widget.NewButtonWithIcon("Проверить ссылки", theme.ViewRefreshIcon(), v.checkAll)
+	v.content = container.NewBorder(
+		container.NewHBox(layout.NewSpacer(), v.checkButton),
+		nil, nil, nil,
+		container.NewScroll(v.list),
+	)
+	v.tab = container.NewTabItem("Ссылки", v.content)
+	v.setURLs(nil)
+	return v
+}
+
+// setURLs показывает ссылки заметки
+func (v *LinksView) setURLs(urls []string) {
+	v.urls = urls
+	v.tab.Text = fmt.Sprintf("Ссылки (%d)", len(urls))
+	if len(urls) == 0 {
+		v.checkButton.Disable()
+	} else {
+		v.checkButton.Enable()
+	}
+	v.list.Refresh()
+}
+
+// checkAll проверяет все ссылки заметки в фоне; результаты появляются в списке по мере готовности
+func (v *LinksView) checkAll() {
+	urls := v.urls
+	v.checkButton.Disable()
+	v.checkButton.SetText("Проверка...")
+	go func() {
+		jobs := make(chan string)
+		var wg sync.WaitGroup
+		dead := 0
+		var mu sync.Mutex
+		for i := 0; i < linkCheckWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for rawURL := range jobs {
+					status := linkpreview.Check(context.Background(), rawURL)
+					mu.Lock()
+					if !status.Alive() {
+						dead++
+					}
+					mu.Unlock()
+					fyne.Do(func() {
+						v.statuses[rawURL] = status
+						v.list.Refresh()
+					})
+				}
+			}()
+		}
+		for _, rawURL := range urls {
+			jobs <- rawURL
+		}
+		close(jobs)
+		wg.Wait()
+		log.Printf("Проверено ссылок: %d, не работают: %d", len(urls), dead)
+		fyne.Do(func() {
+			v.checkButton.SetText("Проверить ссылки")
+			if len(v.urls) > 0 { // Пока шла проверка, могли открыть заметку без ссылок
+				v.checkButton.Enable()
+			}
+		})
+	}()
+}
+
+// openLink открывает ссылку в браузере
+func (a *NoteApp) openLink(u *url.URL) {
+	if err := fyne.CurrentApp().OpenURL(u); err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось открыть ссылку: %w", err), a.window)
+	}
+}
+
+// updateLinks заново разбирает ссылки из содержимого заметки, например после сохранения
+func (a *NoteApp) updateLinks() {
+	a.linksView.setURLs(linkpreview.FindURLs(a.contentText(), 0))
+	a.attachmentTabs.Refresh() // Число ссылок в заголовке вкладки
+}
@@ -0,0 +1,292 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// updateLocationUI обновляет отображение места заметки
+func (a *NoteApp) updateLocationUI(loc *models.Location) {
+	a.currentLocation = loc
+	if loc == nil {
+		a.locationLabel.SetText("Место: Не указано")
+		return
+	}
+	a.locationLabel.SetText("Место: " + formatLocation(*loc))
+}
+
+// formatLocation возвращает текстовое представление места
+func formatLocation(loc models.Location) string {
+	coords := fmt.Sprintf("%.5f, %.5f", loc.Lat, loc.Lon)
+	if loc.Name == "" {
+		return coords
+	}
+	return fmt.Sprintf("%s (%s)", loc.Name, coords)
+}
+
+// setLocationDialog открывает диалог для указания места заметки вручную или на карте
+func (a *NoteApp) setLocationDialog() {
+	initial := models.Location{}
+	if a.currentLocation != nil {
+		initial = *a.currentLocation
+	}
+	a.showLocationPicker("Место заметки", "Установить", initial, true, nil, func(loc models.Location) {
+		a.updateLocationUI(&loc)
+		a.setUnsavedChanges(true)
+	})
+}
+
+// showLocationPicker показывает форму выбора точки: название, координаты и карта.
+// extra — дополнительные поля формы, которые читаются в onPicked.
+func (a *NoteApp) showLocationPicker(title, confirm string, initial models.Location, withName bool, extra []*widget.FormItem, onPicked func(loc models.Location)) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Название (необязательно)")
+	nameEntry.SetText(initial.Name)
+
+	latEntry := widget.NewEntry()
+	latEntry.SetPlaceHolder("Широта, например 55.75580")
+	lonEntry := widget.NewEntry()
+	lonEntry.SetPlaceHolder("Долгота, например 37.61730")
+	if initial.Lat != 0 || initial.Lon != 0 {
+		latEntry.SetText(strconv.FormatFloat(initial.Lat, 'f', 5, 64))
+		lonEntry.SetText(strconv.FormatFloat(initial.Lon, 'f', 5, 64))
+	}
+
+	picker := newMapPicker(initial.Lat, initial.Lon, func(lat, lon float64) {
+		latEntry.SetText(strconv.FormatFloat(lat, 'f', 5, 64))
+		lonEntry.SetText(strconv.FormatFloat(lon, 'f', 5, 64))
+	})
+	syncPicker := func(string) {
+		if lat, lon, err := parseCoordinates(latEntry.Text, lonEntry.Text); err == nil {
+			picker.SetPoint(lat, lon)
+		}
+	}
+	latEntry.OnChanged = syncPicker
+	lonEntry.OnChanged = syncPicker
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Широта", latEntry),
+		widget.NewFormItem("Долгота", lonEntry),
+	}
+	if withName {
+		items = append([]*widget.FormItem{widget.NewFormItem("Название", nameEntry)}, items...)
+	}
+	items = append(items, extra...)
+	content := container.NewVBox(
+		widget.NewForm(items...),
+		widget.NewLabel("Или выберите точку на карте:"),
+		picker,
+	)
+
+	dialog.ShowCustomConfirm(title, confirm, "Отмена", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		lat, lon, err := parseCoordinates(latEntry.Text, lonEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		onPicked(models.Location{Name: strings.TrimSpace(nameEntry.Text), Lat: lat, Lon: lon})
+	}, a.window)
+}
+
+// parseCoordinates разбирает широту и долготу, допуская запятую в качестве десятичного разделителя
+func parseCoordinates(latStr, lonStr string) (float64, float64, error) {
+	lat, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(latStr), ",", "."), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, fmt.Errorf("широта должна быть числом от -90 до 90")
+	}
+	lon, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(lonStr), ",", "."), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return 0, 0, fmt.Errorf("долгота должна быть числом от -180 до 180")
+	}
+	return lat, lon, nil
+}
+
+// proximityDialog задает точку и радиус для фильтрации и сортировки заметок по близости
+func (a *NoteApp) proximityDialog() {
+	initial := models.Location{}
+	if a.proximityCenter != nil {
+		initial = *a.proximityCenter
+	} else if a.currentLocation != nil {
+		initial = *a.currentLocation
+	}
+
+	radiusEntry := widget.NewEntry()
+	radiusEntry.SetPlaceHolder("Пусто — без ограничения")
+	if a.proximityRadiusKm > 0 {
+		radiusEntry.SetText(strconv.FormatFloat(a.proximityRadiusKm, 'f', -1, 64))
+	}
+
+	extra := []*widget.FormItem{widget.NewFormItem("Радиус, км", radiusEntry)}
+	a.showLocationPicker("Заметки рядом с точкой", "Применить", initial, false, extra, func(loc models.Location) {
+		radius := 0.0
+		if text := strings.TrimSpace(radiusEntry.Text); text != "" {
+			r, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64)
+			if err != nil || r <= 0 {
+				dialog.ShowError(fmt.Errorf("радиус должен быть положительным числом"), a.window)
+				return
+			}
+			radius = r
+		}
+		a.proximityCenter = &loc
+		a.proximityRadiusKm = radius
+		a.updateProximityUI()
+		a.filterNotes()
+	})
+}
+
+// clearProximity сбрасывает фильтр по близости
+func (a *NoteApp) clearProximity() {
+	a.proximityCenter = nil
+	a.proximityRadiusKm = 0
+	a.updateProximityUI()
+	a.filterNotes()
+}
+
+// updateProximityUI обновляет подпись фильтра по близости
+func (a *NoteApp) updateProximityUI() {
+	if a.proximityCenter == nil {
+		a.proximityLabel.SetText("Рядом: —")
+		return
+	}
+	text := fmt.Sprintf("Рядом: %.3f, %.3f", a.proximityCenter.Lat, a.proximityCenter.Lon)
+	if a.proximityRadiusKm > 0 {
+		text += fmt.Sprintf(" (≤ %g км)", a.proximityRadiusKm)
+	}
+	a.proximityLabel.SetText(text)
+}
+
+// mapPicker — простая карта в равнопромежуточной проекции с сеткой, на которой можно выбрать точку
+type mapPicker struct {
+	widget.BaseWidget
+	lat, lon float64
+	hasPoint bool
+	onPicked func(lat, lon float64)
+}
+
+// newMapPicker создает карту для выбора точки
+func newMapPicker(lat, lon float64, onPicked func(lat, lon float64)) *mapPicker {
+	m := &mapPicker{lat: lat, lon: lon, hasPoint: lat != 0 || lon != 0, onPicked: onPicked}
+	m.ExtendBaseWidget(m)
+	return m
+}
+
+// SetPoint перемещает маркер в указанную точку
+func (m *mapPicker) SetPoint(lat, lon float64) {
+	m.lat, m.lon, m.hasPoint = lat, lon, true
+	m.Refresh()
+}
+
+// Tapped выбирает точку по месту нажатия
+func (m *mapPicker) Tapped(e *fyne.PointEvent) {
+	size := m.Size()
+	if size.Width == 0 || size.Height == 0 {
+		return
+	}
+	lon := float64(e.Position.X/size.Width)*360 - 180
+	lat := 90 - float64(e.Position.Y/size.Height)*180
+	m.SetPoint(lat, lon)
+	if m.onPicked != nil {
+		m.onPicked(lat, lon)
+	}
+}
+
+// CreateRenderer создает отрисовщик карты
+func (m *mapPicker) CreateRenderer() fyne.WidgetRenderer {
+	r := &mapPickerRenderer{
+		picker: m,
+		bg:     canvas.NewRectangle(color.NRGBA{R: 0x2a, G: 0x5d, B: 0x8a, A: 0xff}),
+		marker: canvas.NewCircle(theme.Color(theme.ColorNameError)),
+	}
+	// Параллели и меридианы через каждые 30 градусов
+	for lat := -60; lat <= 60; lat += 30 {
+		r.parallels = append(r.parallels, gridLine(lat == 0))
+	}
+	for lon := -150; lon <= 150; lon += 30 {
+		r.meridians = append(r.meridians, gridLine(lon == 0))
+	}
+	if !m.hasPoint {
+		r.marker.Hide()
+	}
+	return r
+}
+
+// gridLine создает линию сетки карты; основные линии (экватор, нулевой меридиан) ярче
+func gridLine(major bool) *canvas.Line {
+	c := color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x40}
+	if major {
+		c.A = 0xa0
+	}
+	line := canvas.NewLine(c)
+	line.StrokeWidth = 1
+	return line
+}
+
+// mapPickerRenderer отрисовывает mapPicker
+type mapPickerRenderer struct {
+	picker    *mapPicker
+	bg        *canvas.Rectangle
+	parallels []*canvas.Line
+	meridians []*canvas.Line
+	marker    *canvas.Circle
+}
+
+func (r *mapPickerRenderer) Layout(size fyne.Size) {
+	r.bg.Resize(size)
+	for i, line := range r.parallels {
+		y := size.Height * float32(i+1) / float32(len(r.parallels)+1)
+		line.Position1 = fyne.NewPos(0, y)
+		line.Position2 = fyne.NewPos(size.Width, y)
+	}
+	for i, line := range r.meridians {
+		x := size.Width * float32(i+1) / float32(len(r.meridians)+1)
+		line.Position1 = fyne.NewPos(x, 0)
+		line.Position2 = fyne.NewPos(x, size.Height)
+	}
+
+	const markerSize = 10
+	x := float32((r.picker.lon+180)/360) * size.Width
+	y := float32((90-r.picker.lat)/180) * size.Height
+	r.marker.Resize(fyne.NewSize(markerSize, markerSize))
+	r.marker.Move(fyne.NewPos(x-markerSize/2, y-markerSize/2))
+}
+
+func (r *mapPickerRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(360, 180)
+}
+
+func (r *mapPickerRenderer) Refresh() {
+	if r.picker.hasPoint {
+		r.marker.Show()
+	} else {
+		r.marker.Hide()
+	}
+	r.Layout(r.picker.Size())
+	canvas.Refresh(r.picker)
+}
+
+func (r *mapPickerRenderer) Objects() []fyne.CanvasObject {
+	objects := []fyne.CanvasObject{r.bg}
+	for _, line := range r.parallels {
+		objects = append(objects, line)
+	}
+	for _, line := range r.meridians {
+		objects = append(objects, line)
+	}
+	return append(objects, r.marker)
+}
+
+func (r *mapPickerRenderer) Destroy() {}
@@ -0,0 +1,339 @@
+package ui
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Настройки блокировки общие для всех профилей: блокируется приложение, а не отдельная база
+const (
+	lockHashPreference    = "lock_hash"    // Хэш PIN-кода или пароля; пусто — блокировка выключена
+	lockSaltPreference    = "lock_salt"    // Соль хэша
+	lockMinutesPreference = "lock_minutes" // Автоблокировка после бездействия, в минутах; 0 — выключена
+
+	lockIterations   = 200_000 // Итерации PBKDF2: подбор пароля по файлу настроек должен быть дорогим
+	minSecretLength  = 4
+	maxUnlockBackoff = 30 * time.Second // Предельная пауза после неудачных попыток разблокировки
+)
+
+// startupLockDone — окно уже блокировалось при запуске. При смене профиля создается новый NoteApp,
+// и блокировать его снова не нужно: пользователь уже ввел PIN-код или пароль.
+var startupLockDone bool
+
+// autoLockOptions — варианты автоблокировки в минутах для настройки
+var autoLockOptions = []int{0, 1, 5, 15, 30, 60}
+
+// appLock — состояние блокировки окна заметок
+type appLock struct {
+	locked   bool
+	content  fyne.CanvasObject   // Содержимое окна, скрытое на время блокировки
+	overlays []fyne.CanvasObject // Диалоги, скрытые на время блокировки
+	windows  []fyne.Window       // Окна заметок, скрытые на время блокировки
+	timer    *time.Timer         // Автоблокировка; nil — выключена
+	failures int                 // Неудачные попытки разблокировки подряд
+}
+
+// lockConfigured сообщает, задан ли PIN-код или пароль
+func lockConfigured() bool {
+	return fyne.CurrentApp().Preferences().String(lockHashPreference) != ""
+}
+
+// hashSecret возвращает хэш PIN-кода или пароля с солью
+func hashSecret(secret string, salt []byte) (string, error) {
+	key, err := pbkdf2.Key(sha256.New, secret, salt, lockIterations, 32)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при вычислении хэша пароля: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// checkSecret сравнивает PIN-код или пароль с сохраненным хэшем
+func checkSecret(secret string) bool {
+	prefs := fyne.CurrentApp().Preferences()
+	salt, err := base64.StdEncoding.DecodeString(prefs.String(lockSaltPreference))
+	if err != nil {
+		return false
+	}
+	hash, err := hashSecret(secret, salt)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(prefs.String(lockHashPreference))) == 1
+}
+
+// saveSecret сохраняет хэш нового PIN-кода или пароля с новой солью
+func saveSecret(secret string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("ошибка при создании соли: %w", err)
+	}
+	hash, err := hashSecret(secret, salt)
+	if err != nil {
+		return err
+	}
+	prefs := fyne.CurrentApp().Preferences()
+	prefs.SetString(lockSaltPreference, base64.StdEncoding.EncodeToString(salt))
+	prefs.SetString(lockHashPreference, hash)
+	return nil
+}
+
+// setupLock следит за действиями пользователя для автоблокировки и блокирует окно при запуске программы,
+// если задан PIN-код или пароль
+func (a *NoteApp) setupLock() {
+	a.window.SetContent(newActivityTracker(a.window.Content(), a.touchActivity))
+	// Ввод текста и выбор заметок перехватывают указатель, поэтому тоже считаются действиями
+	onChanged, onCursor, onSelected := a.NoteEditorView.OnChanged, a.contentEntry.OnCursorChanged, a.NoteListView.OnSelected
+	a.NoteEditorView.OnChanged = func() {
+		a.touchActivity()
+		onChanged()
+	}
+	a.contentEntry.OnCursorChanged = func() {
+		a.touchActivity()
+		onCursor()
+	}
+	a.NoteListView.OnSelected = func(id widget.ListItemID) {
+		a.touchActivity()
+		onSelected(id)
+	}
+	a.resetLockTimer()
+	if !startupLockDone {
+		startupLockDone = true
+		a.lockApp()
+	}
+}
+
+// touchActivity откладывает автоблокировку
+func (a *NoteApp) touchActivity() {
+	if a.lock.timer != nil && !a.lock.locked {
+		a.lock.timer.Reset(autoLockDelay())
+	}
+}
+
+// autoLockDelay возвращает время бездействия до автоблокировки; 0 — выключена
+func autoLockDelay() time.Duration {
+	return time.Duration(fyne.CurrentApp().Preferences().Int(lockMinutesPreference)) * time.Minute
+}
+
+// resetLockTimer запускает автоблокировку заново по текущим настройкам
+func (a *NoteApp) resetLockTimer() {
+	a.stopLockTimer()
+	if delay := autoLockDelay(); delay > 0 && lockConfigured() {
+		a.lock.timer = time.AfterFunc(delay, func() { fyne.Do(a.lockApp) })
+	}
+}
+
+// stopLockTimer выключает автоблокировку, например при смене профиля
+func (a *NoteApp) stopLockTimer() {
+	if a.lock.timer != nil {
+		a.lock.timer.Stop()
+		a.lock.timer = nil
+	}
+}
+
+// lockNow блокирует окно по команде пользователя
+func (a *NoteApp) lockNow() {
+	if !lockConfigured() {
+		dialog.ShowInformation("Блокировка", "Сначала задайте PIN-код или пароль: Инструменты → Блокировка.", a.window)
+		return
+	}
+	a.lockApp()
+}
+
+// lockApp скрывает заметки за экраном ввода PIN-кода или пароля. Меню убирается, чтобы его
+// сочетания клавиш не работали; окна заметок и открытые диалоги прячутся до разблокировки.
+func (a *NoteApp) lockApp() {
+	if a.lock.locked || !lockConfigured() {
+		return
+	}
+	a.lock.locked = true
+	a.lock.content = a.window.Content()
+	a.lock.overlays = a.window.Canvas().Overlays().List()
+	for _, o := range a.lock.overlays {
+		o.Hide()
+	}
+	a.lock.windows = a.lock.windows[:0]
+	for _, nw := range a.noteWindows {
+		nw.window.Hide()
+		a.lock.windows = append(a.lock.windows, nw.window)
+	}
+	a.window.SetMainMenu(nil)
+
+	entry := widget.NewPasswordEntry()
+	entry.SetPlaceHolder("PIN-код или пароль")
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Hide()
+	var unlockButton *widget.Button
+	unlock := func() {
+		if checkSecret(entry.Text) {
+			a.unlockApp()
+			return
+		}
+		a.lock.failures++
+		entry.SetText("")
+		errorLabel.SetText("Неверный PIN-код или пароль")
+		errorLabel.Show()
+		// Каждая неудачная попытка удлиняет паузу перед следующей
+		pause := min(time.Duration(a.lock.failures)*time.Second, maxUnlockBackoff)
+		entry.Disable()
+		unlockButton.Disable()
+		time.AfterFunc(pause, func() {
+			fyne.Do(func() {
+				entry.Enable()
+				unlockButton.Enable()
+				a.window.Canvas().Focus(entry)
+			})
+		})
+	}
+	entry.OnSubmitted = func(string) { unlock() }
+	unlockButton = widget.NewButtonWithIcon("Разблокировать", theme.LoginIcon(), unlock)
+
+	form := container.NewVBox(
+		widget.NewLabelWithStyle("GNote заблокирован", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		entry,
+		unlockButton,
+		errorLabel,
+	)
+	a.window.SetContent(container.NewCenter(container.NewGridWrap(fyne.NewSize(280, form.MinSize().Height), form)))
+	a.window.Canvas().Focus(entry)
+	log.Println("Окно заметок заблокировано")
+}
+
+// unlockApp возвращает окно, меню, диалоги и окна заметок после ввода верного PIN-кода или пароля
+func (a *NoteApp) unlockApp() {
+	a.lock.locked = false
+	a.lock.failures = 0
+	a.window.SetContent(a.lock.content)
+	a.lock.content = nil
+	if a.menu != nil {
+		a.window.SetMainMenu(a.menu.main)
+	}
+	for _, o := range a.lock.overlays {
+		o.Show()
+	}
+	a.lock.overlays = nil
+	for _, w := range a.lock.windows {
+		w.Show()
+	}
+	a.lock.windows = nil
+	a.resetLockTimer()
+	log.Println("Окно заметок разблокировано")
+}
+
+// showLockSettings показывает настройку PIN-кода или пароля и автоблокировки.
+// Изменить или отключить блокировку можно, только введя текущий PIN-код или пароль.
+func (a *NoteApp) showLockSettings() {
+	configured := lockConfigured()
+	currentEntry := widget.NewPasswordEntry()
+	newEntry := widget.NewPasswordEntry()
+	repeatEntry := widget.NewPasswordEntry()
+	disableCheck := widget.NewCheck("Отключить блокировку", nil)
+
+	autoLockNames := make([]string, len(autoLockOptions))
+	for i, minutes := range autoLockOptions {
+		autoLockNames[i] = autoLockName(minutes)
+	}
+	autoLockSelect := widget.NewSelect(autoLockNames, nil)
+	autoLockSelect.SetSelected(autoLockName(fyne.CurrentApp().Preferences().Int(lockMinutesPreference)))
+
+	newItem := widget.NewFormItem("Новый PIN-код или пароль", newEntry)
+	if configured {
+		newItem.HintText = "Оставьте пустым, чтобы не менять"
+	} else {
+		newItem.HintText = fmt.Sprintf("Не короче %d символов", minSecretLength)
+	}
+	items := []*widget.FormItem{newItem, widget.NewFormItem("Повторите", repeatEntry),
+		widget.NewFormItem("Блокировать после бездействия", autoLockSelect)}
+	if configured {
+		items = append([]*widget.FormItem{widget.NewFormItem("Текущий PIN-код или пароль", currentEntry)}, items...)
+		items = append(items, widget.NewFormItem("", disableCheck))
+	}
+
+	d := dialog.NewForm("Блокировка", "Сохранить", "Отмена", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if configured && !checkSecret(currentEntry.Text) {
+			dialog.ShowError(fmt.Errorf("неверный текущий PIN-код или пароль"), a.window)
+			return
+		}
+		prefs := fyne.CurrentApp().Preferences()
+		if disableCheck.Checked {
+			prefs.RemoveValue(lockHashPreference)
+			prefs.RemoveValue(lockSaltPreference)
+			a.resetLockTimer()
+			log.Println("Блокировка отключена")
+			return
+		}
+		if newEntry.Text != "" || !configured {
+			if len([]rune(newEntry.Text)) < minSecretLength {
+				dialog.ShowError(fmt.Errorf("PIN-код или пароль должен быть не короче %d символов", minSecretLength), a.window)
+				return
+			}
+			if newEntry.Text != repeatEntry.Text {
+				dialog.ShowError(fmt.Errorf("PIN-коды или пароли не совпадают"), a.window)
+				return
+			}
+			if err := saveSecret(newEntry.Text); err != nil {
+				dialog.ShowError(err, a.window)
+				return
+			}
+			log.Println("PIN-код или пароль блокировки изменен")
+		}
+		if i := autoLockSelect.SelectedIndex(); i >= 0 {
+			prefs.SetInt(lockMinutesPreference, autoLockOptions[i])
+		}
+		a.resetLockTimer()
+	}, a.window)
+	d.Resize(fyne.NewSize(480, d.MinSize().Height))
+	d.Show()
+}
+
+// autoLockName возвращает название варианта автоблокировки
+func autoLockName(minutes int) string {
+	if minutes <= 0 {
+		return "Никогда"
+	}
+	return fmt.Sprintf("%d мин", minutes)
+}
+
+// activityTracker передает движения указателя над окном как действия пользователя для автоблокировки
+type activityTracker struct {
+	widget.BaseWidget
+	content    fyne.CanvasObject
+	onActivity func()
+}
+
+// newActivityTracker оборачивает содержимое окна
+func newActivityTracker(content fyne.CanvasObject, onActivity func()) *activityTracker {
+	t := &activityTracker{content: content, onActivity: onActivity}
+	t.ExtendBaseWidget(t)
+	return t
+}
+
+// CreateRenderer отрисовывает содержимое без изменений
+func (t *activityTracker) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(t.content)
+}
+
+// MouseIn считается действием пользователя
+func (t *activityTracker) MouseIn(*desktop.MouseEvent) { t.onActivity() }
+
+// MouseMoved считается действием пользователя
+func (t *activityTracker) MouseMoved(*desktop.MouseEvent) { t.onActivity() }
+
+// MouseOut ничего не делает
+func (t *activityTracker) MouseOut() {}
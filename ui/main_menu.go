@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+// Сочетания клавиш пунктов главного меню. Поиск и замена (Ctrl+H) и переход к строке (Ctrl+G)
+// заданы рядом со своими окнами.
+var (
+	newNoteShortcut     = &desktop.CustomShortcut{KeyName: fyne.KeyN, Modifier: fyne.KeyModifierShortcutDefault}
+	saveNoteShortcut    = &desktop.CustomShortcut{KeyName: fyne.KeyS, Modifier: fyne.KeyModifierShortcutDefault}
+	importShortcut      = &desktop.CustomShortcut{KeyName: fyne.KeyO, Modifier: fyne.KeyModifierShortcutDefault}
+	importURLShortcut   = &desktop.CustomShortcut{KeyName: fyne.KeyO, Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift}
+	exportShortcut      = &desktop.CustomShortcut{KeyName: fyne.KeyE, Modifier: fyne.KeyModifierShortcutDefault}
+	journalShortcut     = &desktop.CustomShortcut{KeyName: fyne.KeyJ, Modifier: fyne.KeyModifierShortcutDefault}
+	bulkReplaceShortcut = &desktop.CustomShortcut{KeyName: fyne.KeyH, Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift}
+	readingModeShortcut = &desktop.CustomShortcut{KeyName: fyne.KeyR, Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift}
+	lineNumbersShortcut = &desktop.CustomShortcut{KeyName: fyne.KeyL, Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift}
+	lockShortcut        = &desktop.CustomShortcut{KeyName: fyne.KeyL, Modifier: fyne.KeyModifierShortcutDefault}
+)
+
+// appMenu — главное меню окна и пункты, отметки которых меняются вместе с состоянием окна
+type appMenu struct {
+	main          *fyne.MainMenu
+	lineNumbers   *fyne.MenuItem
+	twoLineTitles *fyne.MenuItem
+	timeline      *fyne.MenuItem
+}
+
+// menuItem создает пункт меню с сочетанием клавиш. Сочетания пунктов главного меню срабатывают
+// раньше сфокусированного поля, поэтому работают и во время ввода текста.
+func menuItem(label string, shortcut fyne.Shortcut, action func()) *fyne.MenuItem {
+	item := fyne.NewMenuItem(label, action)
+	item.Shortcut = shortcut
+	return item
+}
+
+// setupMainMenu строит главное меню окна: файл, правка, вид, инструменты, профили (если есть) и справка.
+// Выход добавляет Fyne в меню «Файл».
+func (a *NoteApp) setupMainMenu() {
+	m := &appMenu{
+		lineNumbers:   menuItem("Номера строк", lineNumbersShortcut, a.toggleLineNumbers),
+		twoLineTitles: fyne.NewMenuItem("Заголовки в 2 строки", func() { a.twoLineCheck.SetChecked(!a.twoLineTitles) }),
+		timeline:      fyne.NewMenuItem("Хронология", func() { a.setTimeline(!a.timelineOn) }),
+	}
+
+	file := fyne.NewMenu("Файл",
+		menuItem("Новая заметка", newNoteShortcut, a.newNote),
+		menuItem("Сохранить", saveNoteShortcut, a.saveNote),
+		menuItem("Дневник", journalShortcut, a.openJournal),
+		fyne.NewMenuItemSeparator(),
+		menuItem("Импорт...", importShortcut, a.importNote),
+		menuItem("Импорт из URL...", importURLShortcut, a.importFromURL),
+		menuItem("Экспорт...", exportShortcut, a.exportNote),
+		fyne.NewMenuItemSeparator(),
+		menuItem("Заблокировать", lockShortcut, a.lockNow),
+	)
+	edit := fyne.NewMenu("Правка",
+		menuItem("Найти и заменить...", findReplaceShortcut, a.showFindReplace),
+		menuItem("Перейти к строке...", goToLineShortcut, a.showGoToLine),
+		fyne.NewMenuItemSeparator(),
+		menuItem("Замена во всех заметках...", bulkReplaceShortcut, a.showBulkReplace),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Секретный фрагмент", a.markSensitive),
+		fyne.NewMenuItem("Секретная заметка", a.toggleSensitive),
+	)
+	view := fyne.NewMenu("Вид",
+		menuItem("Режим чтения", readingModeShortcut, a.showReadingMode),
+		fyne.NewMenuItem("Открыть рядом", a.openSidePane),
+		fyne.NewMenuItem("Открыть во вкладке", a.openInTab),
+		fyne.NewMenuItem("Открыть в новом окне", a.openInWindow),
+		fyne.NewMenuItem("Открыть стикером", a.openSticky),
+		fyne.NewMenuItemSeparator(),
+		m.lineNumbers,
+		m.twoLineTitles,
+		m.timeline,
+		fyne.NewMenuItem("Панель кнопок...", a.showToolbarSettings),
+	)
+	tools := fyne.NewMenu("Инструменты",
+		fyne.NewMenuItem("Поиск дубликатов", a.findDuplicates),
+		fyne.NewMenuItem("Повторяющиеся заметки", a.showRecurringRules),
+		fyne.NewMenuItem("Сниппеты", a.showSnippets),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Статистика", a.showStatistics),
+		fyne.NewMenuItem("Журнал изменений", a.showActivity),
+		fyne.NewMenuItem("Запросы к БД", a.showQueryMetrics),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Блокировка...", a.showLockSettings),
+	)
+	help := fyne.NewMenu("Справка",
+		fyne.NewMenuItem("О программе", a.showAboutDialog),
+	)
+
+	menus := []*fyne.Menu{file, edit, view, tools}
+	if a.profiles != nil {
+		menus = append(menus, a.profileMenu())
+	}
+	m.main = fyne.NewMainMenu(append(menus, help)...)
+	a.menu = m
+	a.refreshMainMenu()
+	if !a.lock.locked { // Заблокированное окно остается без меню до разблокировки
+		a.window.SetMainMenu(m.main)
+	}
+}
+
+// refreshMainMenu обновляет отметки пунктов меню по текущему состоянию окна
+func (a *NoteApp) refreshMainMenu() {
+	if a.menu == nil { // Меню еще не построено (например, при восстановлении номеров строк)
+		return
+	}
+	a.menu.lineNumbers.Checked = a.lineNumbers.Visible()
+	a.menu.twoLineTitles.Checked = a.twoLineTitles
+	a.menu.timeline.Checked = a.timelineOn
+	a.menu.main.Refresh()
+}
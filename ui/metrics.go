@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/storage"
+)
+
+// SetMetrics задает сбор длительности запросов к БД для панели «Запросы к БД»; nil — панель пуста
+func (a *NoteApp) SetMetrics(m *storage.Metrics) {
+	a.metrics = m
+}
+
+// formatQueryDuration возвращает длительность запроса в миллисекундах с разумной точностью
+func formatQueryDuration(d time.Duration) string {
+	if d < 10*time.Millisecond {
+		return fmt.Sprintf("%.2f мс", float64(d)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%d мс", d.Milliseconds())
+}
+
+// showQueryMetrics показывает число и длительность вызовов методов хранилища с запуска программы:
+// помогает найти, какое действие тормозит на медленной БД
+func (a *NoteApp) showQueryMetrics() {
+	if a.metrics == nil {
+		dialog.ShowInformation("Запросы к БД", "Сбор длительности запросов не включен.", a.window)
+		return
+	}
+	metrics := a.metrics
+	tableBox := container.NewVBox()
+	load := func() {
+		tableBox.RemoveAll()
+		stats := metrics.Snapshot()
+		if len(stats) == 0 {
+			tableBox.Add(widget.NewLabel("Запросов к БД пока не было."))
+			return
+		}
+		grid := container.NewGridWithColumns(6,
+			boldLabel("Метод"), boldLabel("Вызовов"), boldLabel("Ошибок"),
+			boldLabel("Среднее"), boldLabel("Макс"), boldLabel("Всего"))
+		for _, s := range stats {
+			grid.Add(widget.NewLabel(s.Method))
+			grid.Add(widget.NewLabel(fmt.Sprint(s.Calls)))
+			grid.Add(widget.NewLabel(fmt.Sprint(s.Errors)))
+			grid.Add(widget.NewLabel(formatQueryDuration(s.Average())))
+			grid.Add(widget.NewLabel(formatQueryDuration(s.Max)))
+			grid.Add(widget.NewLabel(formatQueryDuration(s.Total)))
+		}
+		tableBox.Add(grid)
+	}
+	load()
+
+	refreshButton := widget.NewButton("Обновить", load)
+	resetButton := widget.NewButton("Сбросить", func() {
+		metrics.Reset()
+		load()
+	})
+	scroll := container.NewVScroll(tableBox)
+	scroll.SetMinSize(fyne.NewSize(760, 360))
+	content := container.NewBorder(
+		container.NewHBox(layout.NewSpacer(), resetButton, refreshButton), nil, nil, nil, scroll)
+	dialog.ShowCustom("Запросы к БД", "Закрыть", content, a.window)
+}
@@ -0,0 +1,358 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+	"GNote/readability"
+)
+
+// NoteEditorView — поля основного редактора: заголовок, теги, напоминание, место, источник и содержимое
+type NoteEditorView struct {
+	titleEntry     *widget.Entry
+	favoriteButton *widget.Button
+	qrButton       *widget.Button
+	prioritySelect *widget.Select
+	tagsEntry      *tagEntry
+	tagSuggestions *fyne.Container // Предложенные теги кнопками; скрыты, пока предлагать нечего
+	suggestedBox   *fyne.Container
+	suggestedFor   int // ID заметки, для которой предложены теги
+	reminderLabel  *widget.Label
+	reminderButton *widget.Button
+	dueLabel       *widget.Label
+	locationLabel  *widget.Label
+	sourceLink     *widget.Hyperlink // Ссылка на исходную страницу (для заметок из URL)
+	contentEntry   *contentEditor
+	lineNumbers    *widget.Label     // Номера строк слева от содержимого; скрыты, пока не включены
+	contentScroll  *container.Scroll // Прокрутка обычного редактора содержимого
+	contentArea    *fyne.Container   // Область содержимого: обычный редактор или построчный просмотр
+	largeView      *largeTextView    // Построчный просмотр большой заметки (nil для обычных)
+	charCountLabel *widget.Label
+	countText      string          // Подпись счетчика без выделения; выделение дописывается при движении курсора
+	limitLabel     *widget.Label   // Предупреждение о приближении к ограничениям размера заметки
+	matchLabel     *widget.Label   // Счетчик совпадений строки поиска в заметке
+	matchBar       *fyne.Container // Счетчик и переход между совпадениями; скрыт без поиска
+
+	header fyne.CanvasObject // Поля над содержимым
+
+	OnChanged         func() // Пользователь изменил заголовок, теги или содержимое
+	OnContentChanged  func() // Пользователь изменил содержимое
+	OnFavorite        func()
+	OnShowQR          func()                         // Показать заметку QR-кодом
+	OnReadAloud       func()                         // Прочитать заметку вслух
+	OnReadingMode     func()                         // Открыть заметку в режиме чтения
+	OnInsertSymbol    func(anchor fyne.CanvasObject) // Выбрать эмодзи или символ для вставки
+	OnLineNumbers     func()                         // Показать или скрыть номера строк
+	OnPriorityChanged func(p models.Priority)        // Пользователь выбрал другой приоритет
+	OnSetReminder     func()
+	OnClearReminder   func()
+	OnSetDue          func()
+	OnClearDue        func()
+	OnSetLocation     func()
+	OnClearLocation   func()
+	OnSearchTags      func(prefix string) ([]string, error) // Подсказки существующих тегов
+	OnPrevMatch       func()                                // Переход к предыдущему совпадению поиска
+	OnNextMatch       func()                                // Переход к следующему совпадению поиска
+}
+
+// NewNoteEditorView создает поля редактора, привязанные к полям модели vm.
+// Обработчики On* назначаются после создания.
+func NewNoteEditorView(vm *NoteViewModel) *NoteEditorView {
+	v := &NoteEditorView{}
+	changed := func(string) {
+		if v.OnChanged != nil {
+			v.OnChanged()
+		}
+	}
+
+	v.titleEntry = widget.NewEntry()
+	v.titleEntry.SetPlaceHolder("Заголовок заметки")
+	v.titleEntry.OnChanged = changed
+	bindEntry(v.titleEntry, vm.noteTitle)
+
+	v.favoriteButton = widget.NewButton("☆", func() { v.OnFavorite() })
+	v.favoriteButton.Disable()
+	v.qrButton = widget.NewButton("QR", func() { v.OnShowQR() })
+	readAloudButton := widget.NewButtonWithIcon("", theme.VolumeUpIcon(), func() { v.OnReadAloud() })
+	readingButton := widget.NewButtonWithIcon("", theme.VisibilityIcon(), func() { v.OnReadingMode() })
+	lineNumbersButton := widget.NewButtonWithIcon("", theme.ListIcon(), func() { v.OnLineNumbers() })
+	var symbolButton *widget.Button
+	symbolButton = widget.NewButton("☺", func() { v.OnInsertSymbol(symbolButton) })
+
+	v.prioritySelect = widget.NewSelect(priorityNames, nil)
+	v.prioritySelect.SetSelected(priorityNames[models.PriorityNone])
+	v.prioritySelect.OnChanged = func(name string) { // Назначаем после SetSelected, как у сортировки
+		if v.OnPriorityChanged != nil {
+			v.OnPriorityChanged(priorityFromName(name))
+		}
+	}
+
+	v.contentEntry = newContentEditor()
+	v.contentEntry.SetPlaceHolder("Содержимое заметки...")
+	bindEntry(&v.contentEntry.Entry, vm.noteContent)
+	v.contentEntry.OnChanged = func(s string) {
+		changed(s)
+		if v.OnContentChanged != nil {
+			v.OnContentChanged()
+		}
+	}
+
+	v.lineNumbers = widget.NewLabel("1")
+	v.lineNumbers.Alignment = fyne.TextAlignTrailing
+	v.lineNumbers.Importance = widget.LowImportance
+	v.lineNumbers.Hide()
+	v.contentScroll = container.NewScroll(container.NewBorder(nil, nil, v.lineNumbers, nil, v.contentEntry))
+	v.contentArea = container.NewStack(v.contentScroll)
+
+	v.charCountLabel = widget.NewLabel("Символов: 0 | Слов: 0 | Строк: 0")
+	v.charCountLabel.Alignment = fyne.TextAlignTrailing // Выравнивание по правому краю
+	v.limitLabel = widget.NewLabel("")
+	v.limitLabel.Hide()
+
+	v.matchLabel = widget.NewLabel("")
+	v.matchBar = container.NewHBox(
+		v.matchLabel,
+		widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() { v.OnPrevMatch() }),
+		widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() { v.OnNextMatch() }),
+	)
+	v.matchBar.Hide() // Показывается, когда в списке заметок что-то ищут
+
+	v.tagsEntry = newTagEntry(func(prefix string) ([]string, error) { return v.OnSearchTags(prefix) })
+	v.tagsEntry.SetPlaceHolder("Теги (через запятую, например: работа, личное)")
+	v.tagsEntry.OnChanged = changed
+	bindEntry(&v.tagsEntry.Entry, vm.noteTags)
+	v.suggestedBox = container.NewHBox()
+	v.tagSuggestions = container.NewBorder(nil, nil, widget.NewLabel("Добавить теги:"), nil, container.NewHScroll(v.suggestedBox))
+	v.tagSuggestions.Hide() // Показывается после сохранения, если нашлись подходящие теги
+
+	v.reminderLabel = widget.NewLabel("Напоминание: Не установлено")
+	v.reminderButton = widget.NewButton("Установить напоминание", func() { v.OnSetReminder() })
+	clearReminderButton := widget.NewButton("Очистить", func() { v.OnClearReminder() })
+	reminderContainer := container.NewHBox(v.reminderLabel, v.reminderButton, clearReminderButton)
+
+	v.dueLabel = widget.NewLabel("Срок: Не установлен")
+	dueContainer := container.NewHBox(
+		v.dueLabel,
+		widget.NewButton("Установить срок", func() { v.OnSetDue() }),
+		widget.NewButton("Очистить", func() { v.OnClearDue() }),
+	)
+
+	v.locationLabel = widget.NewLabel("Место: Не указано")
+	locationContainer := container.NewHBox(
+		v.locationLabel,
+		widget.NewButton("Указать место", func() { v.OnSetLocation() }),
+		widget.NewButton("Очистить", func() { v.OnClearLocation() }),
+	)
+
+	v.sourceLink = widget.NewHyperlink("", nil)
+	v.sourceLink.Hide() // Показывается только для заметок, созданных из веб-страниц
+
+	v.header = container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(v.prioritySelect, lineNumbersButton, symbolButton, readingButton, readAloudButton, v.qrButton, v.favoriteButton), v.titleEntry),
+		v.tagsEntry,
+		v.tagSuggestions,
+		reminderContainer,
+		dueContainer,
+		locationContainer,
+		v.sourceLink,
+	)
+	return v
+}
+
+// bindEntry привязывает поле ввода к строке модели. Программный SetText сразу обновляет модель,
+// поэтому поля можно заполнять как обычно. Значок проверки, который добавляет Bind, здесь не нужен.
+func bindEntry(e *widget.Entry, data binding.String) {
+	e.Bind(data)
+	e.Validator = nil
+}
+
+// updateCharCount обновляет счетчик символов, слов и строк, время чтения и оценку читаемости
+func (a *NoteApp) updateCharCount() {
+	content := a.contentText()
+	words := len(strings.Fields(content)) // Разделяем по пробелам и считаем
+	text := fmt.Sprintf("Символов: %d | Слов: %d | Строк: %d", countChars(content), words, countLines(content))
+	if stats := readability.Analyze(content); stats.Words > 0 {
+		text += fmt.Sprintf(" | Чтение: %s | Читаемость: %.0f, %s",
+			formatReadingTime(stats.ReadingTime()), stats.Score(), readability.Level(stats.Score()))
+	}
+	a.countText = text
+	a.updateSelectionCount()
+	a.updateLimitWarning()
+}
+
+// updateSelectionCount дописывает к счетчику число символов и слов в выделенном тексте
+func (a *NoteApp) updateSelectionCount() {
+	text := a.countText
+	if selected := a.contentEntry.SelectedText(); selected != "" {
+		text += fmt.Sprintf(" | Выделено: %d симв., %d сл.", countChars(selected), len(strings.Fields(selected)))
+	}
+	a.charCountLabel.SetText(text)
+}
+
+// countChars считает видимые символы (графемы), а не байты: кириллическая буква — один символ,
+// буква с диакритическими знаками, эмодзи с модификаторами и эмодзи, соединенные через ZWJ, — тоже
+func countChars(text string) int {
+	count := 0
+	var prev rune
+	for _, r := range text {
+		switch {
+		case prev == '\u200d': // Продолжение эмодзи после соединителя
+		case r == '\u200d', r == '\n' && prev == '\r':
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Variation_Selector):
+		case r >= 0x1f3fb && r <= 0x1f3ff: // Модификаторы цвета кожи
+		default:
+			count++
+		}
+		prev = r
+	}
+	return count
+}
+
+// countLines возвращает число строк текста; у пустого текста строк нет
+func countLines(text string) int {
+	if text == "" {
+		return 0
+	}
+	return strings.Count(text, "\n") + 1
+}
+
+// limitWarningShare — с какой доли ограничения размера редактор предупреждает о нем
+const limitWarningShare = 0.9
+
+// updateLimitWarning предупреждает, что заголовок или содержимое приближаются к ограничениям хранилища
+// или превышают их, чтобы об этом не пришлось узнавать из ошибки при сохранении
+func (a *NoteApp) updateLimitWarning() {
+	limits := a.store.NoteLimits()
+	titleLen := utf8.RuneCountInString(a.editedTitle())
+	contentSize := int64(len(a.contentText()))
+
+	text, importance := "", widget.WarningImportance
+	switch {
+	case titleLen > limits.MaxTitleLength:
+		text, importance = fmt.Sprintf("Заголовок длиннее %d символов — заметку не сохранить", limits.MaxTitleLength), widget.DangerImportance
+	case contentSize > limits.MaxContentBytes:
+		text, importance = fmt.Sprintf("Содержимое больше %s — заметку не сохранить", formatBytes(limits.MaxContentBytes)), widget.DangerImportance
+	case float64(titleLen) >= limitWarningShare*float64(limits.MaxTitleLength):
+		text = fmt.Sprintf("Заголовок: %d из %d символов", titleLen, limits.MaxTitleLength)
+	case float64(contentSize) >= limitWarningShare*float64(limits.MaxContentBytes):
+		text = fmt.Sprintf("Содержимое: %s из %s", formatBytes(contentSize), formatBytes(limits.MaxContentBytes))
+	}
+	if text == "" {
+		a.limitLabel.Hide()
+		return
+	}
+	a.limitLabel.Importance = importance
+	a.limitLabel.SetText(text)
+	a.limitLabel.Show()
+}
+
+// formatReadingTime округляет время чтения до минут: "< 1 мин", "~3 мин"
+func formatReadingTime(d time.Duration) string {
+	if d < time.Minute {
+		return "< 1 мин"
+	}
+	return fmt.Sprintf("~%d мин", int(math.Round(d.Minutes())))
+}
+
+// Форматы даты и времени напоминания в интерфейсе
+const (
+	reminderDateLayout = "02.01.2006"
+	reminderTimeLayout = "15:04"
+)
+
+// parseReminder разбирает дату и время, введенные в часовом поясе loc, и возвращает момент в UTC
+func parseReminder(dateStr, timeStr string, loc *time.Location) (time.Time, error) {
+	t, err := time.ParseInLocation(reminderDateLayout+" "+reminderTimeLayout,
+		strings.TrimSpace(dateStr)+" "+strings.TrimSpace(timeStr), loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// formatReminder показывает момент напоминания в местном часовом поясе
+func formatReminder(t time.Time) string {
+	return t.In(time.Local).Format(reminderDateLayout + " " + reminderTimeLayout + " (MST)")
+}
+
+// updateReminderUI задает напоминание редактируемой заметки и обновляет его отображение.
+// Значение хранится в UTC, подпись только показывает его и обратно не разбирается.
+func (a *NoteApp) updateReminderUI(t *time.Time) {
+	if t == nil {
+		a.reminderLabel.SetText("Напоминание: Не установлено")
+		a.currentReminder = nil
+	} else {
+		utc := t.UTC()
+		a.reminderLabel.SetText(fmt.Sprintf("Напоминание: %s", formatReminder(utc)))
+		a.currentReminder = &utc
+	}
+}
+
+// updateSourceLink показывает ссылку на исходную страницу заметки или скрывает ее
+func (a *NoteApp) updateSourceLink(rawURL string) {
+	u, err := url.Parse(rawURL)
+	if rawURL == "" || err != nil {
+		a.sourceLink.Hide()
+		return
+	}
+	a.sourceLink.SetText("Источник: " + rawURL)
+	a.sourceLink.SetURL(u)
+	a.sourceLink.Show()
+}
+
+// setReminderDialog открывает диалог для установки напоминания
+func (a *NoteApp) setReminderDialog() {
+	// Инициализируем текущее напоминание для диалога
+	initialTime := time.Now()
+	if a.currentReminder != nil {
+		initialTime = a.currentReminder.In(time.Local) // Поля диалога — в местном времени
+	}
+
+	a.reminderDateEntry = widget.NewEntry()
+	a.reminderDateEntry.SetPlaceHolder("ДД.ММ.ГГГГ")
+	a.reminderDateEntry.SetText(initialTime.Format(reminderDateLayout))
+
+	a.reminderTimeEntry = widget.NewEntry()
+	a.reminderTimeEntry.SetPlaceHolder("ЧЧ:ММ")
+	a.reminderTimeEntry.SetText(initialTime.Format(reminderTimeLayout))
+
+	// Кнопка для открытия календаря
+	calendarButton := widget.NewButton("Выбрать дату", func() {
+		dialog.ShowCustom("Выберите дату", "Закрыть",
+			widget.NewCalendar(initialTime, func(t time.Time) {
+				a.reminderDateEntry.SetText(t.Format(reminderDateLayout))
+			}), a.window)
+	})
+
+	content := container.NewVBox(
+		widget.NewLabel("Дата:"),
+		container.NewHBox(a.reminderDateEntry, calendarButton),
+		widget.NewLabel("Время (ЧЧ:ММ, местное):"),
+		a.reminderTimeEntry,
+	)
+
+	dialog.ShowCustomConfirm("Установить напоминание", "Установить", "Отмена", content, func(ok bool) {
+		if ok {
+			parsedTime, err := parseReminder(a.reminderDateEntry.Text, a.reminderTimeEntry.Text, time.Local)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("неверный формат даты или времени. Используйте ДД.ММ.ГГГГ ЧЧ:ММ: %w", err), a.window)
+				return
+			}
+			a.updateReminderUI(&parsedTime)
+			a.setUnsavedChanges(true)
+		}
+	}, a.window)
+}
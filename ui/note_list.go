@@ -0,0 +1,351 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// NoteListView — левая панель: избранное, поиск, сортировка, фильтр по близости и список заметок
+type NoteListView struct {
+	vm *NoteViewModel
+
+	noteList             *widget.List
+	searchEntry          *widget.Entry
+	savedSearchButton    *widget.Button // Меню сохраненных поисков
+	sortSelect           *widget.Select
+	priorityFilterSelect *widget.Select // Фильтр по приоритету
+	overdueCheck         *widget.Check  // Умный фильтр «Просроченные»
+	dateFieldSelect      *widget.Select // Фильтр по дате создания или изменения
+	dateFromPicker       *datePicker    // Первый день диапазона дат
+	dateToPicker         *datePicker    // Последний день диапазона дат
+	proximityLabel       *widget.Label
+	favoritesBox         *fyne.Container
+	favoritesScroll      *container.Scroll
+	tagTree              *TagTreeView
+	timeline             *timelineView  // Хронология: заметки по дням или месяцам вместо списка
+	twoLineCheck         *widget.Check  // Заголовки в списке в две строки
+	summaryLabel         *widget.Label  // Сколько заметок показано и какие фильтры действуют
+	clearFiltersButton   *widget.Button // Сбрасывает все фильтры одним нажатием
+	twoLineTitles        bool
+	timelineOn           bool // Вместо списка показана хронология
+
+	content fyne.CanvasObject
+
+	OnSelected       func(id widget.ListItemID)     // Пользователь выбрал заметку в списке
+	OnFilterChanged  func()                         // Изменились поиск или сортировка
+	OnProximity      func()                         // Нажата кнопка фильтра по близости
+	OnClearProximity func()                         // Нажата кнопка сброса фильтра по близости
+	OnRenameTag      func(tag string)               // Нажата кнопка переименования выбранного тега
+	OnTagStyle       func(tag string)               // Нажата кнопка оформления выбранного тега
+	OnTwoLineTitles  func()                         // Переключен показ заголовков в две строки
+	OnSavedSearches  func(anchor fyne.CanvasObject) // Нажата кнопка сохраненных поисков
+	OnTimeline       func()                         // Переключен показ хронологии вместо списка
+}
+
+// NewNoteListView создает левую панель, отображающую состояние модели vm.
+// Обработчики On* назначаются после создания.
+func NewNoteListView(vm *NoteViewModel) *NoteListView {
+	v := &NoteListView{vm: vm}
+
+	v.searchEntry = widget.NewEntry()
+	v.searchEntry.SetPlaceHolder("Поиск по заголовку, содержимому или тегам... (has:pdf, file:счет, -#архив)")
+	v.searchEntry.OnChanged = func(s string) {
+		vm.query = s
+		v.filterChanged()
+	}
+	v.savedSearchButton = widget.NewButtonWithIcon("", theme.ListIcon(), func() {
+		if v.OnSavedSearches != nil {
+			v.OnSavedSearches(v.savedSearchButton)
+		}
+	})
+
+	v.noteList = widget.NewList(
+		func() int {
+			return len(vm.filteredNotes)
+		},
+		func() fyne.CanvasObject {
+			return newNoteRow(func() bool { return v.twoLineTitles })
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) { v.updateNoteRow(i, o.(*noteRow)) },
+	)
+	v.noteList.OnSelected = func(id widget.ListItemID) {
+		if v.OnSelected != nil {
+			v.OnSelected(id)
+		}
+	}
+	v.noteList.OnUnselected = func(id widget.ListItemID) {
+		// При сбросе выделения, убедимся, что стиль сброшен
+		// Это важно, так как Fyne переиспользует объекты списка
+		if id >= 0 && id < len(vm.filteredNotes) {
+			// Вызываем UpdateItem для сброса стиля
+			v.noteList.UpdateItem(id, v.noteList.CreateItem())
+		}
+	}
+
+	v.sortSelect = widget.NewSelect(sortOptions, nil)
+	v.sortSelect.SetSelected(vm.sortCriteria)
+	v.sortSelect.OnChanged = func(s string) { // Назначаем после SetSelected, чтобы не сортировать при создании
+		vm.sortCriteria = s
+		v.filterChanged()
+	}
+
+	v.priorityFilterSelect = widget.NewSelect(append([]string{anyPriorityOption}, priorityNames...), nil)
+	v.priorityFilterSelect.SetSelected(anyPriorityOption)
+	v.priorityFilterSelect.OnChanged = func(s string) {
+		vm.priorityFilter = priorityFromName(s)
+		v.filterChanged()
+	}
+
+	v.overdueCheck = widget.NewCheck("Просроченные", func(on bool) {
+		vm.overdueOnly = on
+		v.filterChanged()
+	})
+
+	v.dateFieldSelect = widget.NewSelect(dateFieldOptions, nil)
+	v.dateFieldSelect.SetSelectedIndex(0)
+	v.dateFieldSelect.OnChanged = func(string) {
+		vm.dateByUpdated = v.dateFieldSelect.SelectedIndex() == 1
+		if !vm.dateFrom.IsZero() || !vm.dateTo.IsZero() {
+			v.filterChanged()
+		}
+	}
+	v.dateFromPicker = newDatePicker("с")
+	v.dateFromPicker.OnChanged = func(date time.Time) {
+		if !vm.dateTo.IsZero() && date.After(vm.dateTo) { // Период не может закончиться раньше начала
+			vm.dateTo = date
+			v.dateToPicker.set(date)
+		}
+		vm.dateFrom = date
+		v.filterChanged()
+	}
+	v.dateToPicker = newDatePicker("по")
+	v.dateToPicker.OnChanged = func(date time.Time) {
+		if !vm.dateFrom.IsZero() && date.Before(vm.dateFrom) {
+			vm.dateFrom = date
+			v.dateFromPicker.set(date)
+		}
+		vm.dateTo = date
+		v.filterChanged()
+	}
+	dateRow := container.NewBorder(nil, nil, v.dateFieldSelect,
+		widget.NewButtonWithIcon("", theme.CancelIcon(), v.clearDates),
+		container.NewGridWithColumns(2, v.dateFromPicker.button, v.dateToPicker.button))
+
+	v.twoLineTitles = fyne.CurrentApp().Preferences().Bool(twoLineTitlesPreference)
+	v.twoLineCheck = widget.NewCheck("В 2 строки", func(on bool) {
+		v.twoLineTitles = on
+		fyne.CurrentApp().Preferences().SetBool(twoLineTitlesPreference, on)
+		v.refreshList() // Высота строк списка пересчитывается по новой строке
+		if v.OnTwoLineTitles != nil {
+			v.OnTwoLineTitles()
+		}
+	})
+	v.twoLineCheck.SetChecked(v.twoLineTitles)
+
+	v.proximityLabel = widget.NewLabel("Рядом: —")
+	proximityRow := container.NewHBox(
+		v.overdueCheck,
+		v.twoLineCheck,
+		v.proximityLabel,
+		layout.NewSpacer(),
+		widget.NewButtonWithIcon("", theme.SearchIcon(), func() { v.OnProximity() }),
+		widget.NewButtonWithIcon("", theme.CancelIcon(), func() { v.OnClearProximity() }),
+	)
+
+	v.favoritesBox = container.NewHBox()
+	v.favoritesScroll = container.NewHScroll(v.favoritesBox)
+	v.favoritesScroll.Hide() // Показывается, только когда есть избранные заметки
+
+	v.tagTree = NewTagTreeView()
+	v.tagTree.OnFilterChanged = func(included, excluded []string) {
+		vm.includedTags, vm.excludedTags = included, excluded
+		v.filterChanged()
+	}
+	v.tagTree.OnRename = func(tag string) { v.OnRenameTag(tag) }
+	v.tagTree.OnStyle = func(tag string) { v.OnTagStyle(tag) }
+	v.summaryLabel = widget.NewLabel("")
+	v.summaryLabel.Truncation = fyne.TextTruncateEllipsis
+	v.clearFiltersButton = widget.NewButtonWithIcon("Сбросить", theme.CancelIcon(), v.clearFilters)
+	v.clearFiltersButton.Importance = widget.LowImportance
+	v.clearFiltersButton.Hide()
+	v.timeline = newTimelineView(v)
+	v.timeline.content.Hide()
+	listWithSummary := container.NewBorder(container.NewBorder(nil, nil, nil, v.clearFiltersButton, v.summaryLabel), nil, nil, nil,
+		container.NewStack(v.noteList, v.timeline.content))
+	tagsSplit := container.NewVSplit(v.tagTree.content, listWithSummary) // Дерево тегов над списком заметок
+	tagsSplit.Offset = 0.3
+
+	v.content = container.NewBorder(
+		// Избранное, поиск, сортировка, фильтры по приоритету и по месту сверху
+		container.NewVBox(v.favoritesScroll, container.NewBorder(nil, nil, nil, v.savedSearchButton, v.searchEntry), container.NewGridWithColumns(2, v.sortSelect, v.priorityFilterSelect), dateRow, proximityRow),
+		nil,
+		nil,
+		nil,
+		tagsSplit,
+	)
+	if fyne.CurrentApp().Preferences().Bool(timelinePreference) {
+		v.setTimeline(true)
+	}
+	return v
+}
+
+// setTimeline показывает хронологию вместо списка заметок или возвращает список
+func (v *NoteListView) setTimeline(on bool) {
+	v.timelineOn = on
+	fyne.CurrentApp().Preferences().SetBool(timelinePreference, on)
+	if on {
+		v.noteList.Hide()
+		v.timeline.update()
+		v.timeline.content.Show()
+	} else {
+		v.timeline.content.Hide()
+		v.noteList.Show()
+		v.noteList.Refresh()
+	}
+	if v.OnTimeline != nil {
+		v.OnTimeline()
+	}
+}
+
+// refreshList перерисовывает список заметок, а если показана хронология — перестраивает и ее
+func (v *NoteListView) refreshList() {
+	v.noteList.Refresh()
+	if v.timelineOn {
+		v.timeline.update()
+	}
+}
+
+// updateNoteRow заполняет строку row заметкой с индексом i в отфильтрованном списке
+func (v *NoteListView) updateNoteRow(i int, row *noteRow) {
+	vm := v.vm
+	note := vm.filteredNotes[i]
+
+	row.mark.FillColor = priorityColor(note.Priority)
+	row.mark.Refresh()
+
+	row.chips.RemoveAll()
+	for _, tag := range note.Tags {
+		if style, ok := vm.tagStyles[tag]; ok {
+			row.chips.Add(newTagChip(tag, style))
+		}
+	}
+
+	// Визуальное выделение активной заметки
+	selected := i == vm.selectedNoteIndex
+	plain, highlight := snippetStyle, highlightStyle
+	if selected { // На цветном фоне выделяем только жирным
+		plain.ColorName, highlight.ColorName = theme.ColorNameForeground, theme.ColorNameForeground
+	}
+	query := parseSearchQuery(vm.query).text
+	row.snippet.Segments = matchSnippet(note.Content, query, plain, highlight)
+	if row.snippet.Segments == nil { // Текст, распознанный во вложениях
+		row.snippet.Segments = matchSnippet(note.AttachmentText, query, plain, highlight)
+	}
+
+	if selected {
+		row.bg.FillColor = theme.PrimaryColor() // Используем PrimaryColor для фона
+	} else {
+		row.bg.FillColor = color.Transparent // Прозрачный фон
+	}
+
+	// Просроченные заметки выделяются красным
+	importance := widget.MediumImportance
+	if isOverdue(note, time.Now()) {
+		importance = widget.DangerImportance
+	}
+	title := note.Title
+	if note.Favorite {
+		title = "★ " + title
+	}
+	row.setTitle(title, importance, selected) // Перерисовывает строку целиком
+}
+
+// filterChanged сообщает об изменении поиска или сортировки
+func (v *NoteListView) filterChanged() {
+	if v.OnFilterChanged != nil {
+		v.OnFilterChanged()
+	}
+}
+
+// updateSummary показывает над списком, сколько заметок отобрано фильтрами и какими
+func (v *NoteListView) updateSummary() {
+	total, shown := len(v.vm.allNotes), len(v.vm.filteredNotes)
+	filters := v.vm.filterSummary()
+	if len(filters) == 0 {
+		v.summaryLabel.SetText(fmt.Sprintf("Заметок: %d", total))
+		v.clearFiltersButton.Hide()
+		return
+	}
+	v.summaryLabel.SetText(fmt.Sprintf("Показано %d из %d %s • фильтр: %s",
+		shown, total, notesGenitive(total), strings.Join(filters, ", ")))
+	v.clearFiltersButton.Show()
+}
+
+// notesGenitive возвращает слово «заметка» в форме после «из n»: из 1 заметки, из 5 заметок
+func notesGenitive(n int) string {
+	if n%10 == 1 && n%100 != 11 {
+		return "заметки"
+	}
+	return "заметок"
+}
+
+// clearDates сбрасывает фильтр по датам
+func (v *NoteListView) clearDates() {
+	if v.vm.dateFrom.IsZero() && v.vm.dateTo.IsZero() {
+		return
+	}
+	v.vm.dateFrom, v.vm.dateTo = time.Time{}, time.Time{}
+	v.dateFromPicker.set(time.Time{})
+	v.dateToPicker.set(time.Time{})
+	v.filterChanged()
+}
+
+// clearFilters сбрасывает поиск, тег, приоритет, просроченные, даты и близость
+func (v *NoteListView) clearFilters() {
+	v.searchEntry.SetText("")
+	v.priorityFilterSelect.SetSelected(anyPriorityOption)
+	v.overdueCheck.SetChecked(false)
+	v.clearDates()
+	v.tagTree.clear()
+	if v.vm.proximityCenter != nil && v.OnClearProximity != nil {
+		v.OnClearProximity()
+	}
+}
+
+// onNoteSelected вызывается при выборе заметки из списка
+func (a *NoteApp) onNoteSelected(id widget.ListItemID) {
+	if a.hasUnsavedChanges() {
+		a.showUnsavedChangesDialog(func() {
+			a.doSelectNote(id)
+		})
+	} else {
+		a.doSelectNote(id)
+	}
+}
+
+// filterNotes применяет фильтр и сортировку модели и обновляет список
+func (a *NoteApp) filterNotes() {
+	hadSelection := a.selectedNoteIndex != -1
+	kept := a.refilter()
+	a.refreshList()
+	a.updateSummary()
+	a.updateMatches() // Строка поиска могла измениться
+	if !hadSelection {
+		return
+	}
+	if kept {
+		a.noteList.Select(a.selectedNoteIndex) // Индекс мог измениться после сортировки
+		return
+	}
+	// Выбранная заметка пропала из списка: очищаем поля
+	a.noteList.UnselectAll()
+	a.newNote()
+}
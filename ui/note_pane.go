@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// notePane — дополнительный редактор одной заметки со своим состоянием сохранения.
+// Используется для просмотра одной заметки во время редактирования другой.
+type notePane struct {
+	app    *NoteApp
+	window fyne.Window // Окно для диалогов панели
+	note   models.Note
+
+	titleEntry   *widget.Entry
+	tagsEntry    *tagEntry
+	contentEntry *widget.Entry
+	saveButton   *widget.Button
+	dirty        bool
+
+	content        fyne.CanvasObject
+	onClose        func()
+	onDirtyChanged func() // Вызывается при изменении флага несохраненных изменений
+}
+
+// newNotePane создает панель редактирования для заметки; onClose вызывается после закрытия панели
+func newNotePane(a *NoteApp, win fyne.Window, note models.Note, onClose func()) *notePane {
+	p := &notePane{app: a, window: win, note: note, onClose: onClose}
+
+	p.titleEntry = widget.NewEntry()
+	p.titleEntry.SetText(note.Title)
+	p.tagsEntry = newTagEntry(a.store.SearchTags)
+	p.tagsEntry.SetPlaceHolder("Теги (через запятую)")
+	p.tagsEntry.SetText(strings.Join(note.Tags, ", "))
+	p.contentEntry = widget.NewMultiLineEntry()
+	p.contentEntry.Wrapping = fyne.TextWrapWord
+	p.contentEntry.SetText(note.Content)
+
+	p.saveButton = widget.NewButtonWithIcon("Сохранить", theme.DocumentSaveIcon(), p.save)
+	p.saveButton.Disable()
+	closeButton := widget.NewButtonWithIcon("", theme.CancelIcon(), p.close)
+
+	// Обработчики назначаем после заполнения полей, чтобы не пометить панель измененной
+	markDirty := func(string) { p.setDirty(true) }
+	p.titleEntry.OnChanged = markDirty
+	p.tagsEntry.OnChanged = markDirty
+	p.contentEntry.OnChanged = markDirty
+
+	p.content = container.NewBorder(
+		container.NewVBox(
+			container.NewBorder(nil, nil, nil, closeButton, p.titleEntry),
+			p.tagsEntry,
+		),
+		container.NewHBox(layout.NewSpacer(), p.saveButton),
+		nil,
+		nil,
+		container.NewScroll(p.contentEntry),
+	)
+	return p
+}
+
+// setDirty устанавливает флаг несохраненных изменений панели
+func (p *notePane) setDirty(dirty bool) {
+	p.dirty = dirty
+	if dirty {
+		p.saveButton.Enable()
+	} else {
+		p.saveButton.Disable()
+	}
+	if p.onDirtyChanged != nil {
+		p.onDirtyChanged()
+	}
+}
+
+// save сохраняет заметку панели
+func (p *notePane) save() {
+	p.saveThen(func() {})
+}
+
+// saveThen сохраняет заметку панели и, если сохранение удалось, вызывает then
+func (p *notePane) saveThen(then func()) {
+	title := strings.TrimSpace(p.titleEntry.Text)
+	if title == "" {
+		dialog.ShowInformation("Ошибка", "Заголовок заметки не может быть пустым.", p.window)
+		return
+	}
+	p.app.confirmOverwrite(p.note.ID, p.note.UpdatedAt, p.window, func() {
+		p.note.Title = title
+		p.note.Content = p.contentEntry.Text
+		p.note.Tags = parseTags(p.tagsEntry.Text)
+
+		if err := p.app.store.UpdateNote(&p.note); err != nil {
+			p.app.showStoreError("не удалось сохранить заметку", err)
+			log.Printf("Ошибка при сохранении заметки ID %d из панели: %v", p.note.ID, err)
+			return
+		}
+		log.Printf("Обновлена заметка из панели: %s (ID: %d)", p.note.LogTitle(), p.note.ID)
+		p.setDirty(false) // Список заметок обновится по событию хранилища
+		then()
+	})
+}
+
+// confirmOverwrite вызывает save, если заметку id не меняли после loaded — времени ее загрузки
+// или последнего сохранения в редакторе. Если ее уже сохранили из другого редактора (основного,
+// панели рядом, вкладки, окна) или другого экземпляра GNote, сначала спрашивает, заменить ли те изменения.
+func (a *NoteApp) confirmOverwrite(id int, loaded time.Time, win fyne.Window, save func()) {
+	current, err := a.store.GetNoteByID(id)
+	if err != nil || !current.UpdatedAt.After(loaded) {
+		save() // Ошибку загрузки покажет само сохранение
+		return
+	}
+	log.Printf("Заметку ID %d изменили в другом редакторе после открытия (%s)", id,
+		current.UpdatedAt.In(time.Local).Format("02.01.2006 15:04:05"))
+	dialog.ShowConfirm("Заметка изменена",
+		fmt.Sprintf("Заметку «%s» сохранили в другом редакторе в %s, после того как она была открыта здесь. "+
+			"Заменить те изменения вашими?", current.Title, current.UpdatedAt.In(time.Local).Format("15:04:05")),
+		func(overwrite bool) {
+			if overwrite {
+				save()
+			}
+		}, win)
+}
+
+// close закрывает панель, спрашивая о несохраненных изменениях
+func (p *notePane) close() {
+	if !p.dirty {
+		p.onClose()
+		return
+	}
+	dialog.ShowConfirm("Несохраненные изменения",
+		fmt.Sprintf("Заметка '%s' изменена. Сохранить изменения?", p.note.Title),
+		func(save bool) {
+			if save {
+				p.saveThen(p.onClose) // Если сохранение не удалось, панель остается открытой
+				return
+			}
+			p.onClose()
+		}, p.window)
+}
+
+// openSidePane открывает выбранную заметку в правой панели рядом с основным редактором
+func (a *NoteApp) openSidePane() {
+	selectedNote := a.getSelectedNote()
+	if selectedNote == nil {
+		dialog.ShowInformation("Открыть рядом", "Выберите сохраненную заметку, чтобы открыть ее рядом.", a.window)
+		return
+	}
+	if a.sidePane != nil && a.sidePane.dirty {
+		dialog.ShowInformation("Открыть рядом", "Сначала сохраните или закройте заметку в правой панели.", a.window)
+		return
+	}
+
+	note, err := a.store.GetNoteByID(selectedNote.ID)
+	if err != nil {
+		a.showStoreError("не удалось загрузить заметку", err)
+		return
+	}
+	a.sidePane = newNotePane(a, a.window, *note, a.closeSidePane)
+
+	split := container.NewHSplit(a.noteDetail, a.sidePane.content)
+	split.SetOffset(0.5)
+	a.detailArea.Objects = []fyne.CanvasObject{split}
+	a.detailArea.Refresh()
+	log.Printf("Заметка '%s' (ID: %d) открыта в правой панели", note.LogTitle(), note.ID)
+}
+
+// closeSidePane убирает правую панель
+func (a *NoteApp) closeSidePane() {
+	a.sidePane = nil
+	a.detailArea.Objects = []fyne.CanvasObject{a.noteDetail}
+	a.detailArea.Refresh()
+}
@@ -0,0 +1,224 @@
+package ui
+
+import (
+	"image/color"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	twoLineTitlesPreference = "two_line_titles"
+	titleShare              = 0.6 // Какую долю ширины строки заголовок может занять рядом с фрагментом поиска
+	tooltipMaxWidth         = 400 // Ширина подсказки с полным заголовком, после которой текст переносится
+	priorityMarkWidth       = 4   // Ширина полоски цвета приоритета
+)
+
+// noteRow — строка списка заметок: полоска приоритета, заголовок, фрагмент совпадений поиска и плашки тегов.
+// Длинный заголовок обрезается многоточием, а полный показывается подсказкой при наведении.
+// В режиме twoLines заголовок переносится на вторую строку и обрезается уже в ней.
+type noteRow struct {
+	widget.BaseWidget
+
+	twoLines  func() bool // Показывать заголовок в две строки; спрашивается при каждой раскладке
+	bg        *canvas.Rectangle
+	mark      *canvas.Rectangle // Полоска цвета приоритета слева
+	lines     [2]*widget.Label  // Строки заголовка; вторая используется только в режиме двух строк
+	snippet   *widget.RichText  // Фрагмент с совпадениями поиска
+	chips     *fyne.Container   // Плашки тегов с цветом или значком
+	title     string
+	truncated bool // Заголовок не поместился целиком при последней раскладке
+	tooltip   *widget.PopUp
+}
+
+// newNoteRow создает пустую строку списка
+func newNoteRow(twoLines func() bool) *noteRow {
+	r := &noteRow{
+		twoLines: twoLines,
+		bg:       canvas.NewRectangle(color.Transparent),
+		mark:     canvas.NewRectangle(color.Transparent),
+		snippet:  widget.NewRichText(),
+		chips:    container.NewHBox(),
+	}
+	for i := range r.lines {
+		r.lines[i] = widget.NewLabel("")
+		r.lines[i].Truncation = fyne.TextTruncateEllipsis
+	}
+	r.snippet.Truncation = fyne.TextTruncateEllipsis
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+// setTitle задает заголовок; строки заполняются при раскладке, когда известна ширина
+func (r *noteRow) setTitle(title string, importance widget.Importance, bold bool) {
+	r.title = title
+	for _, line := range r.lines {
+		line.Importance = importance
+		line.TextStyle.Bold = bold
+	}
+	r.Refresh()
+}
+
+// CreateRenderer создает отрисовку строки
+func (r *noteRow) CreateRenderer() fyne.WidgetRenderer {
+	return &noteRowRenderer{row: r}
+}
+
+// MouseIn показывает полный заголовок, если он не поместился в строку
+func (r *noteRow) MouseIn(e *desktop.MouseEvent) {
+	if !r.truncated {
+		return
+	}
+	c := fyne.CurrentApp().Driver().CanvasForObject(r)
+	if c == nil {
+		return
+	}
+	text := widget.NewLabel(r.title)
+	text.Wrapping = fyne.TextWrapWord
+	size := text.MinSize()
+	if size.Width > tooltipMaxWidth {
+		text.Resize(fyne.NewSize(tooltipMaxWidth, 0))
+		size = fyne.NewSize(tooltipMaxWidth, text.MinSize().Height)
+	}
+	r.tooltip = widget.NewPopUp(text, c)
+	r.tooltip.Resize(size)
+	// Под указателем, чтобы подсказка не перехватывала наведение
+	pos := e.AbsolutePosition.AddXY(0, theme.IconInlineSize()+theme.Padding())
+	if limit := c.Size().Width - size.Width; pos.X > limit {
+		pos.X = limit
+	}
+	r.tooltip.ShowAtPosition(pos)
+}
+
+// MouseMoved ничего не делает: подсказка остается на месте, пока указатель над строкой
+func (r *noteRow) MouseMoved(*desktop.MouseEvent) {}
+
+// MouseOut скрывает подсказку
+func (r *noteRow) MouseOut() {
+	if r.tooltip != nil {
+		r.tooltip.Hide()
+		r.tooltip = nil
+	}
+}
+
+// noteRowRenderer раскладывает строку: заголовок занимает свою ширину, но не больше доли titleShare,
+// если рядом есть фрагмент поиска, и всю ширину, если фрагмента нет
+type noteRowRenderer struct {
+	row *noteRow
+}
+
+// lineHeight возвращает высоту одной строки заголовка
+func (rr *noteRowRenderer) lineHeight() float32 {
+	return rr.row.lines[0].MinSize().Height
+}
+
+// lineStep возвращает сдвиг второй строки заголовка: строки текста идут подряд,
+// и внутренний отступ между ними заменяется межстрочным интервалом
+func (rr *noteRowRenderer) lineStep() float32 {
+	return rr.lineHeight() - 2*theme.InnerPadding() + theme.LineSpacing()
+}
+
+// MinSize возвращает размер строки; в режиме двух строк вторая строка заголовка прибавляет высоту текста
+func (rr *noteRowRenderer) MinSize() fyne.Size {
+	r := rr.row
+	height := fyne.Max(rr.lineHeight(), r.snippet.MinSize().Height)
+	if r.twoLines() {
+		height = rr.lineHeight() + rr.lineStep()
+	}
+	return fyne.NewSize(priorityMarkWidth+r.chips.MinSize().Width+theme.IconInlineSize()*3, height)
+}
+
+// Layout размещает части строки и делит заголовок на строки по доступной ширине
+func (rr *noteRowRenderer) Layout(size fyne.Size) {
+	r := rr.row
+	r.bg.Resize(size)
+	r.mark.Move(fyne.NewPos(0, 0))
+	r.mark.Resize(fyne.NewSize(priorityMarkWidth, size.Height))
+
+	chipsWidth := r.chips.MinSize().Width
+	r.chips.Move(fyne.NewPos(size.Width-chipsWidth, (size.Height-r.chips.MinSize().Height)/2))
+	r.chips.Resize(fyne.NewSize(chipsWidth, r.chips.MinSize().Height))
+
+	left := float32(priorityMarkWidth)
+	available := size.Width - left - chipsWidth
+	pad := 2 * theme.InnerPadding()
+	style := r.lines[0].TextStyle
+	titleWidth := available
+	if len(r.snippet.Segments) > 0 {
+		natural := fyne.MeasureText(r.title, theme.TextSize(), style).Width + pad
+		titleWidth = fyne.Min(natural, available*titleShare)
+	}
+
+	lineHeight := rr.lineHeight()
+	if r.twoLines() {
+		first, rest := splitTitle(r.title, titleWidth-pad, style)
+		r.lines[0].SetText(first)
+		r.lines[1].SetText(rest)
+		r.lines[1].Show()
+		r.truncated = fyne.MeasureText(rest, theme.TextSize(), style).Width > titleWidth-pad
+		top := (size.Height - lineHeight - rr.lineStep()) / 2
+		r.lines[0].Move(fyne.NewPos(left, top))
+		r.lines[1].Move(fyne.NewPos(left, top+rr.lineStep()))
+	} else {
+		r.lines[0].SetText(r.title)
+		r.lines[1].Hide()
+		r.truncated = fyne.MeasureText(r.title, theme.TextSize(), style).Width > titleWidth-pad
+		r.lines[0].Move(fyne.NewPos(left, (size.Height-lineHeight)/2))
+	}
+	r.lines[0].Resize(fyne.NewSize(titleWidth, lineHeight))
+	r.lines[1].Resize(fyne.NewSize(titleWidth, lineHeight))
+
+	snippetHeight := r.snippet.MinSize().Height
+	r.snippet.Move(fyne.NewPos(left+titleWidth, (size.Height-snippetHeight)/2))
+	r.snippet.Resize(fyne.NewSize(fyne.Max(available-titleWidth, 0), snippetHeight))
+}
+
+// Refresh перерисовывает части строки с новой раскладкой заголовка
+func (rr *noteRowRenderer) Refresh() {
+	r := rr.row
+	rr.Layout(r.Size())
+	for _, o := range rr.Objects() {
+		o.Refresh()
+	}
+}
+
+// Objects возвращает части строки снизу вверх
+func (rr *noteRowRenderer) Objects() []fyne.CanvasObject {
+	r := rr.row
+	return []fyne.CanvasObject{r.bg, r.mark, r.lines[0], r.lines[1], r.snippet, r.chips}
+}
+
+// Destroy ничего не освобождает
+func (rr *noteRowRenderer) Destroy() {}
+
+// splitTitle делит заголовок на первую строку шириной не больше width (по словам, а слово длиннее
+// строки — по символам) и остаток, который обрежется многоточием во второй строке
+func splitTitle(title string, width float32, style fyne.TextStyle) (string, string) {
+	fits := func(s string) bool { return fyne.MeasureText(s, theme.TextSize(), style).Width <= width }
+	if fits(title) {
+		return title, ""
+	}
+	end := 0
+	for i, r := range title {
+		if r == ' ' {
+			if !fits(title[:i]) {
+				break
+			}
+			end = i
+		}
+	}
+	if end == 0 { // Первое слово не помещается: делим по символам
+		for i := range title {
+			if i > 0 && !fits(title[:i]) {
+				break
+			}
+			end = i
+		}
+	}
+	return title[:end], strings.TrimLeft(title[end:], " ")
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"GNote/models"
+)
+
+// noteWindow — отдельное окно с заметкой, например справочной, видной во время работы в основном окне.
+// Сохраняется и закрывается независимо от основного редактора.
+type noteWindow struct {
+	window fyne.Window
+	pane   *notePane
+	sticky *stickyState // Оформление окна-стикера; nil — обычное окно
+}
+
+// openInWindow открывает выбранную заметку в отдельном окне или переключается на уже открытое
+func (a *NoteApp) openInWindow() {
+	a.openSelectedInWindow("Открыть в окне", false)
+}
+
+// openSelectedInWindow открывает выбранную заметку в отдельном окне или стикере
+func (a *NoteApp) openSelectedInWindow(title string, sticky bool) {
+	selectedNote := a.getSelectedNote()
+	if selectedNote == nil {
+		dialog.ShowInformation(title, "Выберите сохраненную заметку, чтобы открыть ее в отдельном окне.", a.window)
+		return
+	}
+	if nw, ok := a.noteWindows[selectedNote.ID]; ok {
+		nw.window.RequestFocus()
+		return
+	}
+
+	note, err := a.store.GetNoteByID(selectedNote.ID)
+	if err != nil {
+		a.showStoreError("не удалось загрузить заметку", err)
+		return
+	}
+	a.showNoteWindow(*note, sticky)
+}
+
+// showNoteWindow создает и показывает окно заметки
+func (a *NoteApp) showNoteWindow(note models.Note, sticky bool) {
+	w := fyne.CurrentApp().NewWindow(note.Title)
+	nw := &noteWindow{window: w}
+	nw.pane = newNotePane(a, w, note, func() {
+		if nw.sticky != nil {
+			a.forgetSticky(note.ID)
+		}
+		delete(a.noteWindows, note.ID)
+		w.Close()
+	})
+	nw.pane.onDirtyChanged = nw.updateTitle
+	if a.noteWindows == nil {
+		a.noteWindows = make(map[int]*noteWindow)
+	}
+	a.noteWindows[note.ID] = nw
+
+	if sticky {
+		a.makeSticky(nw)
+	} else {
+		w.SetContent(nw.pane.content)
+		w.Resize(fyne.NewSize(500, 600))
+	}
+	w.SetCloseIntercept(nw.pane.close) // Перед закрытием спрашиваем о несохраненных изменениях
+	w.Show()
+	log.Printf("Заметка '%s' (ID: %d) открыта в отдельном окне", note.LogTitle(), note.ID)
+}
+
+// updateTitle показывает в заголовке окна название заметки и звездочку при несохраненных изменениях
+func (nw *noteWindow) updateTitle() {
+	title := nw.pane.note.Title
+	if nw.pane.dirty {
+		title = "* " + title
+	}
+	nw.window.SetTitle(title)
+}
+
+// dirtyNoteWindow возвращает окно заметки с несохраненными изменениями или nil
+func (a *NoteApp) dirtyNoteWindow() *noteWindow {
+	for _, nw := range a.noteWindows {
+		if nw.pane.dirty {
+			return nw
+		}
+	}
+	return nil
+}
+
+// closeNoteWindows закрывает окна заметок без вопросов, например при смене профиля.
+// Стикеры остаются в списке профиля и откроются снова вместе с ним; их размеры запоминает saveWindowState.
+func (a *NoteApp) closeNoteWindows() {
+	for id, nw := range a.noteWindows {
+		nw.window.Close()
+		delete(a.noteWindows, id)
+	}
+}
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"image/color"
+
+	"GNote/models"
+)
+
+// priorityNames — подписи приоритетов; индекс совпадает со значением models.Priority
+var priorityNames = []string{"Без приоритета", "Низкий", "Средний", "Высокий"}
+
+// priorityColors — цвет метки приоритета в списке заметок
+var priorityColors = map[models.Priority]color.Color{
+	models.PriorityLow:    color.NRGBA{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff},
+	models.PriorityMedium: color.NRGBA{R: 0xfb, G: 0x8c, B: 0x00, A: 0xff},
+	models.PriorityHigh:   color.NRGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff},
+}
+
+// anyPriority — фильтр списка без ограничения по приоритету
+const anyPriority models.Priority = -1
+
+// anyPriorityOption — вариант фильтра, показывающий заметки с любым приоритетом
+const anyPriorityOption = "Любой приоритет"
+
+// priorityFromName возвращает приоритет по подписи; для незнакомой подписи — anyPriority
+func priorityFromName(name string) models.Priority {
+	for i, n := range priorityNames {
+		if n == name {
+			return models.Priority(i)
+		}
+	}
+	return anyPriority
+}
+
+// priorityColor возвращает цвет метки приоритета; без приоритета метка прозрачна
+func priorityColor(p models.Priority) color.Color {
+	if c, ok := priorityColors[p]; ok {
+		return c
+	}
+	return color.Transparent
+}
+
+// updatePriorityUI задает приоритет редактируемой заметки и показывает его в редакторе
+func (a *NoteApp) updatePriorityUI(p models.Priority) {
+	a.currentPriority = p // До SetSelected: обработчик выбора не сочтет это изменением
+	a.prioritySelect.SetSelected(priorityNames[p])
+}
+
+// onPriorityChanged отмечает изменение приоритета пользователем
+func (a *NoteApp) onPriorityChanged(p models.Priority) {
+	if p == a.currentPriority {
+		return
+	}
+	a.currentPriority = p
+	a.setUnsavedChanges(true)
+}
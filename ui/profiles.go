@@ -0,0 +1,194 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"GNote/events"
+	"GNote/storage"
+)
+
+// Ключи настроек с размером окна; к ним, как и к open_tabs, добавляется имя профиля
+const (
+	windowWidthPreference  = "window_width"
+	windowHeightPreference = "window_height"
+)
+
+// profileKey возвращает ключ настроек для профиля; у основного профиля ключи без суффикса
+func profileKey(key, profile string) string {
+	if profile == "" {
+		return key
+	}
+	return key + "." + profile
+}
+
+// restoreWindowState восстанавливает размер окна, сохраненный для профиля
+func (a *NoteApp) restoreWindowState() {
+	prefs := fyne.CurrentApp().Preferences()
+	width := prefs.FloatWithFallback(profileKey(windowWidthPreference, a.profile), 1000)
+	height := prefs.FloatWithFallback(profileKey(windowHeightPreference, a.profile), 700)
+	a.window.Resize(fyne.NewSize(float32(width), float32(height)))
+}
+
+// saveWindowState запоминает размер окна и стикеров для профиля
+func (a *NoteApp) saveWindowState() {
+	a.saveStickyState()
+	size := a.window.Canvas().Size()
+	if size.Width == 0 || size.Height == 0 {
+		return // Окно еще не было показано
+	}
+	prefs := fyne.CurrentApp().Preferences()
+	prefs.SetFloat(profileKey(windowWidthPreference, a.profile), float64(size.Width))
+	prefs.SetFloat(profileKey(windowHeightPreference, a.profile), float64(size.Height))
+}
+
+// ProfileSession — открытое хранилище профиля
+type ProfileSession struct {
+	Name            string                // Имя профиля (пусто — основной)
+	Store           storage.Store         // Хранилище, публикующее события в Bus
+	Offline         *storage.OfflineStore // Офлайн-кэш под Store; nil — выключен
+	Bus             *events.Bus
+	AttachmentsDir  string
+	AttachmentsInDB bool   // Содержимое новых вложений хранится в БД
+	Theme           string // Тема профиля, см. ApplyTheme
+	Density         string // Плотность интерфейса профиля, см. ApplyTheme
+	Close           func() // Освобождает хранилище при переключении на другой профиль
+}
+
+// ProfileOpener открывает хранилище профиля по имени
+type ProfileOpener func(name string) (*ProfileSession, error)
+
+// Profiles переключает окно заметок между профилями без перезапуска приложения
+type Profiles struct {
+	names   []string // Имена профилей; основной профиль ("") всегда первый
+	open    ProfileOpener
+	current *ProfileSession
+
+	listeners []func(app *NoteApp, session *ProfileSession)
+}
+
+// NewProfiles создает переключатель профилей. current — уже открытый профиль,
+// names — имена профилей из настроек (основной профиль добавляется автоматически).
+func NewProfiles(current *ProfileSession, names []string, open ProfileOpener) *Profiles {
+	return &Profiles{names: append([]string{""}, names...), open: open, current: current}
+}
+
+// AddListener добавляет обработчик, вызываемый после переключения с новым окном заметок и открытым профилем
+func (p *Profiles) AddListener(fn func(app *NoteApp, session *ProfileSession)) {
+	p.listeners = append(p.listeners, fn)
+}
+
+// Current возвращает открытый профиль
+func (p *Profiles) Current() *ProfileSession {
+	return p.current
+}
+
+// profileLabel возвращает название профиля для меню
+func profileLabel(name string) string {
+	if name == "" {
+		return "Основной"
+	}
+	return name
+}
+
+// SetProfiles добавляет в главное меню окна меню переключения профилей
+func (a *NoteApp) SetProfiles(p *Profiles) {
+	a.profiles = p
+	a.setupMainMenu()
+}
+
+// profileMenu создает меню профилей с отметкой открытого
+func (a *NoteApp) profileMenu() *fyne.Menu {
+	items := make([]*fyne.MenuItem, 0, len(a.profiles.names))
+	for _, name := range a.profiles.names {
+		name := name
+		item := fyne.NewMenuItem(profileLabel(name), func() { a.switchProfile(name) })
+		item.Checked = name == a.profiles.current.Name
+		items = append(items, item)
+	}
+	return fyne.NewMenu("Профиль", items...)
+}
+
+// switchProfile переключает окно на другой профиль, спрашивая о несохраненных изменениях
+func (a *NoteApp) switchProfile(name string) {
+	if name == a.profiles.current.Name {
+		return
+	}
+	if a.hasDirtyPanes() {
+		dialog.ShowInformation("Смена профиля", "Сначала сохраните или закройте измененные заметки во вкладках, правой панели и отдельных окнах.", a.window)
+		return
+	}
+	if a.hasUnsavedChanges() {
+		a.showUnsavedChangesDialog(func() { a.doSwitchProfile(name) })
+		return
+	}
+	a.doSwitchProfile(name)
+}
+
+// hasDirtyPanes проверяет, есть ли несохраненные изменения в правой панели, вкладках или окнах заметок
+func (a *NoteApp) hasDirtyPanes() bool {
+	if a.sidePane != nil && a.sidePane.dirty || a.dirtyNoteWindow() != nil {
+		return true
+	}
+	for _, tab := range a.noteTabs.tabs {
+		if tab.pane.dirty {
+			return true
+		}
+	}
+	return false
+}
+
+// doSwitchProfile открывает профиль name и заменяет содержимое окна новым окном заметок
+func (a *NoteApp) doSwitchProfile(name string) {
+	p := a.profiles
+	session, err := p.open(name)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось открыть профиль %s: %w", profileLabel(name), err), a.window)
+		log.Printf("Ошибка при открытии профиля '%s': %v", name, err)
+		return
+	}
+
+	a.saveWindowState()
+	a.stopLockTimer()
+	a.closeNoteWindows() // Окна заметок относятся к хранилищу прежнего профиля
+	if a.unsubscribe != nil {
+		a.unsubscribe()
+	}
+	if a.unsubscribeHealth != nil {
+		a.unsubscribeHealth()
+	}
+	previous := p.current
+	p.current = session
+
+	ApplyTheme(fyne.CurrentApp(), session.Theme, session.Density)
+	next := NewNoteApp(a.window, session.Store, session.Name)
+	next.SetContextProviders(a.contextProviders)
+	next.Subscribe(session.Bus)
+	next.SetAttachmentsDir(session.AttachmentsDir)
+	next.SetAttachmentsInDatabase(session.AttachmentsInDB)
+	next.SetProfiles(p)
+	next.SetSpeaker(a.speaker)
+	next.SetHooks(a.hooks)
+	next.SetSummarizer(a.summarizer)
+	next.SetMetrics(a.metrics)
+	next.SetScanner(a.scanner)
+	next.SetLinkPreviews(a.linkPreviewView.fetcher)
+	if a.health != nil {
+		next.SetHealth(a.health)
+	}
+
+	if previous.Close != nil {
+		// Прежнее хранилище закрывается после фоновых записей, начатых в нем
+		go func() {
+			background.Wait(shutdownTimeout)
+			previous.Close()
+		}()
+	}
+	for _, fn := range p.listeners {
+		fn(next, session)
+	}
+	log.Printf("Открыт профиль: %s", profileLabel(name))
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/deeplink"
+	"GNote/models"
+	"GNote/qr"
+)
+
+// qrModuleSize — сколько пикселей занимает один модуль QR-кода на экране
+const qrModuleSize = 6
+
+// qrSource — что показать QR-кодом
+type qrSource struct {
+	name string
+	text string
+}
+
+// qrSources возвращает, что можно показать QR-кодом для открытой заметки:
+// выделенный фрагмент, текст заметки, адрес источника и ссылку gnote://.
+// QR-код легко сфотографировать через плечо, поэтому секретные фрагменты скрываются,
+// а текст секретной заметки не предлагается вовсе.
+func (a *NoteApp) qrSources() []qrSource {
+	var sources []qrSource
+	edited := models.Note{Tags: a.editedTags()}
+	if !edited.HasSensitiveTag() {
+		if a.largeView == nil {
+			if selected := models.RedactText(a.contentEntry.SelectedText()); strings.TrimSpace(selected) != "" {
+				sources = append(sources, qrSource{"Выделенный фрагмент", selected})
+			}
+		}
+		text := models.RedactText(a.contentText())
+		if strings.TrimSpace(text) == "" {
+			text, _ = a.noteTitle.Get()
+		}
+		if strings.TrimSpace(text) != "" {
+			sources = append(sources, qrSource{"Текст заметки", text})
+		}
+	}
+	note := a.getSelectedNote()
+	if note != nil && note.SourceURL != "" {
+		sources = append(sources, qrSource{"Адрес источника", note.SourceURL})
+	}
+	if note != nil {
+		sources = append(sources, qrSource{"Ссылка на заметку", deeplink.NoteURL(note.ID)})
+	}
+	return sources
+}
+
+// showQR показывает QR-код короткой заметки (или ссылки на нее), чтобы перенести текст на телефон
+func (a *NoteApp) showQR() {
+	sources := a.qrSources()
+	if len(sources) == 0 {
+		dialog.ShowInformation("QR-код", "Заметка пуста.", a.window)
+		return
+	}
+	names := make([]string, len(sources))
+	for i, s := range sources {
+		names[i] = s.name
+	}
+
+	image := canvas.NewImageFromImage(nil)
+	image.FillMode = canvas.ImageFillOriginal
+	image.ScaleMode = canvas.ImageScalePixels // Без сглаживания границ модулей
+	message := widget.NewLabel("")
+	message.Wrapping = fyne.TextWrapWord
+	show := func(text string) {
+		code, err := qr.Encode([]byte(text))
+		if err != nil {
+			if errors.Is(err, qr.ErrTooLong) {
+				err = fmt.Errorf("%w: %d байт, помещается около %d. Выделите фрагмент или покажите ссылку", err, len(text), qr.MaxBytes)
+			}
+			image.Hide()
+			message.SetText(err.Error())
+			message.Show()
+			return
+		}
+		image.Image = code.Image(qrModuleSize)
+		image.Refresh()
+		image.Show()
+		message.Hide()
+	}
+
+	sourceSelect := widget.NewRadioGroup(names, nil)
+	sourceSelect.OnChanged = func(name string) {
+		for _, s := range sources {
+			if s.name == name {
+				show(s.text)
+			}
+		}
+	}
+	sourceSelect.SetSelected(names[0])
+
+	content := container.NewVBox(sourceSelect, container.NewCenter(image), message)
+	dialog.ShowCustom("QR-код", "Закрыть", content, a.window)
+}
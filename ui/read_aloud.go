@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/speech"
+)
+
+// speedOptions — скорости чтения вслух
+var speedOptions = []struct {
+	name  string
+	speed float64
+}{
+	{"0,75×", 0.75}, {"1×", 1}, {"1,25×", 1.25}, {"1,5×", 1.5}, {"2×", 2},
+}
+
+// readAloud — состояние чтения заметки вслух
+type readAloud struct {
+	sentences []string
+	next      int                // Предложение, с которого продолжится чтение
+	cancel    context.CancelFunc // Останавливает текущее чтение; nil — на паузе
+}
+
+// ReadAloudView — панель чтения вслух: пауза, остановка и скорость; скрыта, пока заметку не читают
+type ReadAloudView struct {
+	playButton  *widget.Button
+	speedSelect *widget.Select
+	status      *widget.Label
+	content     fyne.CanvasObject
+
+	OnPlayPause   func()
+	OnStop        func()
+	OnSpeedChange func()
+}
+
+// NewReadAloudView создает скрытую панель чтения вслух
+func NewReadAloudView() *ReadAloudView {
+	v := &ReadAloudView{status: widget.NewLabel("")}
+	v.playButton = widget.NewButtonWithIcon("", theme.MediaPauseIcon(), func() { v.OnPlayPause() })
+	names := make([]string, len(speedOptions))
+	for i, o := range speedOptions {
+		names[i] = o.name
+	}
+	v.speedSelect = widget.NewSelect(names, nil)
+	v.speedSelect.SetSelected("1×")
+	v.speedSelect.OnChanged = func(string) { v.OnSpeedChange() } // Назначаем после SetSelected
+	v.content = container.NewHBox(
+		v.playButton,
+		widget.NewButtonWithIcon("", theme.MediaStopIcon(), func() { v.OnStop() }),
+		v.speedSelect,
+		v.status,
+	)
+	v.content.Hide()
+	return v
+}
+
+// speed возвращает выбранную скорость чтения
+func (v *ReadAloudView) speed() float64 {
+	if i := v.speedSelect.SelectedIndex(); i >= 0 {
+		return speedOptions[i].speed
+	}
+	return 1
+}
+
+// SetSpeaker задает синтезатор речи для чтения заметок вслух; nil — чтение недоступно
+func (a *NoteApp) SetSpeaker(speaker *speech.Speaker) {
+	a.speaker = speaker
+}
+
+// startReadAloud читает вслух выделенный фрагмент или всю заметку
+func (a *NoteApp) startReadAloud() {
+	if a.speaker == nil {
+		dialog.ShowInformation("Чтение вслух",
+			"Программа синтеза речи не найдена. Установите espeak-ng или укажите команду в разделе [speech] настроек.", a.window)
+		return
+	}
+	text := ""
+	if a.largeView == nil {
+		text = a.contentEntry.SelectedText()
+	}
+	if strings.TrimSpace(text) == "" {
+		title, _ := a.noteTitle.Get()
+		text = title + "\n" + a.contentText()
+	}
+	sentences := speech.Sentences(text)
+	if len(sentences) == 0 {
+		dialog.ShowInformation("Чтение вслух", "Заметка пуста.", a.window)
+		return
+	}
+	a.stopReadAloud()
+	a.reading = &readAloud{sentences: sentences}
+	a.readAloudView.content.Show()
+	a.playReadAloud()
+}
+
+// toggleReadAloud ставит чтение на паузу или продолжает его с прерванного предложения
+func (a *NoteApp) toggleReadAloud() {
+	r := a.reading
+	if r == nil {
+		return
+	}
+	if r.cancel == nil {
+		a.playReadAloud()
+		return
+	}
+	r.cancel()
+	r.cancel = nil
+	a.readAloudView.playButton.SetIcon(theme.MediaPlayIcon())
+	a.readAloudView.status.SetText(fmt.Sprintf("Пауза: предложение %d из %d", r.next+1, len(r.sentences)))
+}
+
+// changeReadAloudSpeed перечитывает текущее предложение с новой скоростью
+func (a *NoteApp) changeReadAloudSpeed() {
+	if r := a.reading; r != nil && r.cancel != nil {
+		r.cancel()
+		a.playReadAloud()
+	}
+}
+
+// stopReadAloud останавливает чтение и скрывает панель
+func (a *NoteApp) stopReadAloud() {
+	if r := a.reading; r != nil && r.cancel != nil {
+		r.cancel()
+	}
+	a.reading = nil
+	a.readAloudView.content.Hide()
+}
+
+// playReadAloud читает предложения в фоне начиная с r.next; синтезатор запускается на каждое предложение,
+// поэтому пауза и смена скорости действуют сразу
+func (a *NoteApp) playReadAloud() {
+	r := a.reading
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	a.readAloudView.playButton.SetIcon(theme.MediaPauseIcon())
+	speaker, speed, start := a.speaker, a.readAloudView.speed(), r.next
+	go func() {
+		for i := start; i < len(r.sentences); i++ {
+			fyne.Do(func() {
+				if ctx.Err() == nil {
+					r.next = i
+					a.readAloudView.status.SetText(fmt.Sprintf("Предложение %d из %d", i+1, len(r.sentences)))
+				}
+			})
+			if err := speaker.Speak(ctx, r.sentences[i], speed); err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Ошибка при чтении заметки вслух: %v", err)
+					fyne.Do(func() {
+						a.stopReadAloud()
+						dialog.ShowError(fmt.Errorf("не удалось прочитать заметку вслух: %w", err), a.window)
+					})
+				}
+				return
+			}
+		}
+		fyne.Do(func() {
+			if ctx.Err() == nil { // Дочитали до конца
+				a.stopReadAloud()
+			}
+		})
+	}()
+}
@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Ключи настроек режима чтения; они общие для всех профилей
+const (
+	readingWidthPreference   = "reading_width"
+	readingScalePreference   = "reading_scale"
+	readingPalettePreference = "reading_palette"
+)
+
+// Пределы и значения по умолчанию ширины колонки и масштаба шрифта
+const (
+	defaultReadingWidth = 720
+	minReadingWidth     = 400
+	maxReadingWidth     = 1400
+	defaultReadingScale = 1.2
+	minReadingScale     = 0.8
+	maxReadingScale     = 2.5
+)
+
+// readingPalette — фон и цвет текста режима чтения
+type readingPalette struct {
+	name       string
+	background color.Color
+	foreground color.Color
+	link       color.Color
+	variant    fyne.ThemeVariant // Вариант стандартной темы для остальных цветов
+}
+
+// readingPalettes — фоны режима чтения в порядке выбора; первый используется по умолчанию
+var readingPalettes = []readingPalette{
+	{"Светлый", color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, color.NRGBA{R: 0x22, G: 0x22, B: 0x22, A: 0xff},
+		color.NRGBA{R: 0x19, G: 0x5f, B: 0xc2, A: 0xff}, theme.VariantLight},
+	{"Сепия", color.NRGBA{R: 0xf4, G: 0xec, B: 0xd8, A: 0xff}, color.NRGBA{R: 0x5b, G: 0x46, B: 0x36, A: 0xff},
+		color.NRGBA{R: 0x8a, G: 0x4b, B: 0x1c, A: 0xff}, theme.VariantLight},
+	{"Темный", color.NRGBA{R: 0x1e, G: 0x1e, B: 0x1e, A: 0xff}, color.NRGBA{R: 0xd8, G: 0xd8, B: 0xd8, A: 0xff},
+		color.NRGBA{R: 0x6c, G: 0xb4, B: 0xff, A: 0xff}, theme.VariantDark},
+}
+
+// paletteByName возвращает фон по названию или фон по умолчанию
+func paletteByName(name string) readingPalette {
+	for _, p := range readingPalettes {
+		if p.name == name {
+			return p
+		}
+	}
+	return readingPalettes[0]
+}
+
+// readingTheme — тема текста в режиме чтения: свой фон и масштаб шрифта поверх стандартной темы
+type readingTheme struct {
+	fyne.Theme
+	palette readingPalette
+	scale   float32
+}
+
+// Color возвращает цвета выбранного фона; остальные — из стандартной темы нужного варианта
+func (t readingTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	switch name {
+	case theme.ColorNameBackground:
+		return t.palette.background
+	case theme.ColorNameForeground:
+		return t.palette.foreground
+	case theme.ColorNameHyperlink:
+		return t.palette.link
+	}
+	return t.Theme.Color(name, t.palette.variant)
+}
+
+// Size увеличивает размеры текста в масштабе шрифта
+func (t readingTheme) Size(name fyne.ThemeSizeName) float32 {
+	size := t.Theme.Size(name)
+	switch name {
+	case theme.SizeNameText, theme.SizeNameHeadingText, theme.SizeNameSubHeadingText,
+		theme.SizeNameCaptionText, theme.SizeNameLineSpacing, theme.SizeNameInlineIcon:
+		return size * t.scale
+	}
+	return size
+}
+
+// columnLayout размещает содержимое колонкой не шире width по центру
+type columnLayout struct {
+	width float32
+}
+
+func (l *columnLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	w := fyne.Min(l.width, size.Width)
+	for _, o := range objects {
+		o.Resize(fyne.NewSize(w, fyne.Max(o.MinSize().Height, size.Height)))
+		o.Move(fyne.NewPos((size.Width-w)/2, 0))
+	}
+}
+
+func (l *columnLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	var size fyne.Size
+	for _, o := range objects {
+		size = size.Max(fyne.NewSize(0, o.MinSize().Height))
+	}
+	return size
+}
+
+// showReadingMode открывает заметку в отдельном окне только для чтения: markdown без разметки,
+// колонка настраиваемой ширины, крупный шрифт и свой фон, не зависящий от темы редактора
+func (a *NoteApp) showReadingMode() {
+	title := a.editedTitle()
+	text := a.contentText()
+	if title == "" && text == "" {
+		return
+	}
+	prefs := fyne.CurrentApp().Preferences()
+	width := float32(prefs.FloatWithFallback(readingWidthPreference, defaultReadingWidth))
+	scale := float32(prefs.FloatWithFallback(readingScalePreference, defaultReadingScale))
+	palette := paletteByName(prefs.String(readingPalettePreference))
+
+	markdown := text
+	if title != "" {
+		markdown = "# " + title + "\n\n" + text
+	}
+	rich := widget.NewRichTextFromMarkdown(markdown)
+	rich.Wrapping = fyne.TextWrapWord
+	column := &columnLayout{width: width}
+	page := container.New(column, rich)
+	bg := canvas.NewRectangle(palette.background)
+	override := container.NewThemeOverride(container.NewVScroll(page), readingTheme{Theme: theme.DefaultTheme(), palette: palette, scale: scale})
+	apply := func() {
+		bg.FillColor = palette.background
+		bg.Refresh()
+		override.Theme = readingTheme{Theme: theme.DefaultTheme(), palette: palette, scale: scale}
+		override.Refresh()
+		page.Refresh()
+	}
+
+	w := fyne.CurrentApp().NewWindow(fmt.Sprintf("Чтение: %s", title))
+
+	widthSlider := widget.NewSlider(minReadingWidth, maxReadingWidth)
+	widthSlider.Step = 20
+	widthSlider.SetValue(float64(width))
+	widthSlider.OnChanged = func(v float64) {
+		column.width = float32(v)
+		page.Refresh()
+	}
+	widthSlider.OnChangeEnded = func(v float64) { prefs.SetFloat(readingWidthPreference, v) }
+
+	scaleSlider := widget.NewSlider(minReadingScale, maxReadingScale)
+	scaleSlider.Step = 0.1
+	scaleSlider.SetValue(float64(scale))
+	scaleSlider.OnChanged = func(v float64) {
+		scale = float32(v)
+		apply()
+	}
+	scaleSlider.OnChangeEnded = func(v float64) { prefs.SetFloat(readingScalePreference, v) }
+
+	names := make([]string, len(readingPalettes))
+	for i, p := range readingPalettes {
+		names[i] = p.name
+	}
+	paletteRadio := widget.NewRadioGroup(names, nil)
+	paletteRadio.Horizontal = true
+	paletteRadio.Required = true
+	paletteRadio.SetSelected(palette.name)
+	paletteRadio.OnChanged = func(name string) { // Назначаем после SetSelected, чтобы не сохранять исходный фон
+		palette = paletteByName(name)
+		prefs.SetString(readingPalettePreference, name)
+		apply()
+	}
+
+	fullScreenButton := widget.NewButtonWithIcon("", theme.ViewFullScreenIcon(), func() { w.SetFullScreen(!w.FullScreen()) })
+	controls := container.NewBorder(nil, nil,
+		widget.NewLabel("Ширина:"),
+		container.NewHBox(paletteRadio, fullScreenButton),
+		container.NewGridWithColumns(3,
+			widthSlider,
+			widget.NewLabelWithStyle("Шрифт:", fyne.TextAlignTrailing, fyne.TextStyle{}),
+			scaleSlider,
+		),
+	)
+
+	w.Canvas().SetOnTypedKey(func(e *fyne.KeyEvent) {
+		if e.Name == fyne.KeyEscape { // Escape выходит из полноэкранного режима, затем закрывает окно
+			if w.FullScreen() {
+				w.SetFullScreen(false)
+			} else {
+				w.Close()
+			}
+		}
+	})
+	w.SetContent(container.NewBorder(controls, nil, nil, nil, container.NewStack(bg, override)))
+	w.Resize(fyne.NewSize(width+80, 700))
+	w.Show()
+}
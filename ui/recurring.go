@@ -0,0 +1,219 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// frequencyNames — подписи периодичности правил
+var frequencyNames = map[models.Frequency]string{
+	models.FrequencyDaily:   "Каждый день",
+	models.FrequencyWeekly:  "Каждую неделю",
+	models.FrequencyMonthly: "Каждый месяц",
+}
+
+// frequencyOptions — порядок периодичностей в списке выбора
+var frequencyOptions = []models.Frequency{models.FrequencyDaily, models.FrequencyWeekly, models.FrequencyMonthly}
+
+// weekdayNames — дни недели в порядке time.Weekday (с воскресенья)
+var weekdayNames = []string{"Воскресенье", "Понедельник", "Вторник", "Среда", "Четверг", "Пятница", "Суббота"}
+
+// noTemplateOption — вариант выбора шаблона, при котором заметка создается пустой
+const noTemplateOption = "Без шаблона"
+
+// describeRule возвращает описание правила для списка: "Каждую неделю, понедельник в 09:00"
+func describeRule(rule models.RecurringRule) string {
+	when := frequencyNames[rule.Frequency]
+	switch rule.Frequency {
+	case models.FrequencyWeekly:
+		when += ", " + strings.ToLower(weekdayNames[rule.Day])
+	case models.FrequencyMonthly:
+		when += fmt.Sprintf(", %d-го числа", rule.Day)
+	}
+	text := fmt.Sprintf("«%s» — %s в %s", rule.Title, when, rule.Time)
+	if len(rule.Tags) > 0 {
+		text += ", теги: " + strings.Join(rule.Tags, ", ")
+	}
+	if !rule.Enabled {
+		text += " (выключено)"
+	}
+	return text
+}
+
+// showRecurringRules показывает правила повторяющихся заметок с кнопками изменения и удаления
+func (a *NoteApp) showRecurringRules() {
+	rows := container.NewVBox()
+	var reload func()
+	reload = func() {
+		rules, err := a.store.GetRecurringRules()
+		if err != nil {
+			a.showStoreError("не удалось загрузить правила повторяющихся заметок", err)
+			log.Printf("Ошибка при загрузке правил повторяющихся заметок: %v", err)
+			return
+		}
+		rows.RemoveAll()
+		if len(rules) == 0 {
+			rows.Add(widget.NewLabel("Правил пока нет. Например, можно каждый понедельник создавать отчет по шаблону."))
+		}
+		for _, rule := range rules {
+			rule := rule
+			label := widget.NewLabel(describeRule(rule))
+			label.Wrapping = fyne.TextWrapWord
+			editButton := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() { a.editRecurringRule(rule, reload) })
+			deleteButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() { a.deleteRecurringRule(rule, reload) })
+			rows.Add(container.NewBorder(nil, nil, nil, container.NewHBox(editButton, deleteButton), label))
+		}
+	}
+	reload()
+
+	addButton := widget.NewButtonWithIcon("Добавить правило", theme.ContentAddIcon(), func() {
+		a.editRecurringRule(models.RecurringRule{Frequency: models.FrequencyWeekly, Day: 1, Time: "09:00", Enabled: true}, reload)
+	})
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(600, 300))
+	content := container.NewBorder(nil, container.NewHBox(layout.NewSpacer(), addButton), nil, nil, scroll)
+	dialog.ShowCustom("Повторяющиеся заметки", "Закрыть", content, a.window)
+}
+
+// editRecurringRule показывает форму правила; после сохранения вызывает saved
+func (a *NoteApp) editRecurringRule(rule models.RecurringRule, saved func()) {
+	titleEntry := widget.NewEntry()
+	titleEntry.SetPlaceHolder("Например: Отчет за неделю {week}")
+	titleEntry.SetText(rule.Title)
+
+	// Шаблоном служит любая заметка: копируются ее содержимое, теги и приоритет
+	templateOptions := []string{noTemplateOption}
+	templateIDs := map[string]int{noTemplateOption: 0}
+	selectedTemplate := noTemplateOption
+	for _, note := range a.allNotes {
+		option := fmt.Sprintf("%s (#%d)", truncateTitle(note.Title, 40), note.ID)
+		templateOptions = append(templateOptions, option)
+		templateIDs[option] = note.ID
+		if note.ID == rule.TemplateNoteID {
+			selectedTemplate = option
+		}
+	}
+	templateSelect := widget.NewSelect(templateOptions, nil)
+	templateSelect.SetSelected(selectedTemplate)
+
+	tagsEntry := newTagEntry(a.store.SearchTags)
+	tagsEntry.SetPlaceHolder("Например: работа/отчеты")
+	tagsEntry.SetText(strings.Join(rule.Tags, ", "))
+
+	daySelect := widget.NewSelect(nil, nil)
+	setDayOptions := func(frequency models.Frequency) {
+		switch frequency {
+		case models.FrequencyWeekly:
+			daySelect.Options = weekdayNames
+			daySelect.SetSelectedIndex(min(max(rule.Day, 0), 6))
+			daySelect.Enable()
+		case models.FrequencyMonthly:
+			days := make([]string, 31)
+			for i := range days {
+				days[i] = strconv.Itoa(i + 1)
+			}
+			daySelect.Options = days
+			daySelect.SetSelectedIndex(min(max(rule.Day, 1), 31) - 1)
+			daySelect.Enable()
+		default:
+			daySelect.Options = nil
+			daySelect.ClearSelected()
+			daySelect.Disable()
+		}
+	}
+	frequencyLabels := make([]string, len(frequencyOptions))
+	for i, f := range frequencyOptions {
+		frequencyLabels[i] = frequencyNames[f]
+	}
+	frequencySelect := widget.NewSelect(frequencyLabels, nil)
+	frequencySelect.OnChanged = func(string) {
+		setDayOptions(frequencyOptions[frequencySelect.SelectedIndex()])
+	}
+	for i, f := range frequencyOptions {
+		if f == rule.Frequency {
+			frequencySelect.SetSelectedIndex(i)
+		}
+	}
+
+	timeEntry := widget.NewEntry()
+	timeEntry.SetPlaceHolder("ЧЧ:ММ")
+	timeEntry.SetText(rule.Time)
+	enabledCheck := widget.NewCheck("Включено", nil)
+	enabledCheck.SetChecked(rule.Enabled)
+
+	titleItem := widget.NewFormItem("Заголовок", titleEntry)
+	titleItem.HintText = "{date} — дата создания, {week} — номер недели"
+	items := []*widget.FormItem{
+		titleItem,
+		widget.NewFormItem("Шаблон", templateSelect),
+		widget.NewFormItem("Теги", tagsEntry),
+		widget.NewFormItem("Периодичность", frequencySelect),
+		widget.NewFormItem("День", daySelect),
+		widget.NewFormItem("Время", timeEntry),
+		widget.NewFormItem("", enabledCheck),
+	}
+	formTitle := "Новое правило"
+	if rule.ID != 0 {
+		formTitle = "Изменить правило"
+	}
+	d := dialog.NewForm(formTitle, "Сохранить", "Отмена", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		rule.Title = strings.TrimSpace(titleEntry.Text)
+		rule.TemplateNoteID = templateIDs[templateSelect.Selected]
+		rule.Tags = parseTags(tagsEntry.Text)
+		rule.Frequency = frequencyOptions[frequencySelect.SelectedIndex()]
+		rule.Day = 0
+		switch rule.Frequency {
+		case models.FrequencyWeekly:
+			rule.Day = daySelect.SelectedIndex()
+		case models.FrequencyMonthly:
+			rule.Day = daySelect.SelectedIndex() + 1
+		}
+		rule.Time = strings.TrimSpace(timeEntry.Text)
+		rule.Enabled = enabledCheck.Checked
+		if err := rule.Validate(); err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if err := a.store.SaveRecurringRule(&rule); err != nil {
+			a.showStoreError("не удалось сохранить правило", err)
+			log.Printf("Ошибка при сохранении правила повторяющейся заметки: %v", err)
+			return
+		}
+		log.Printf("Сохранено правило повторяющейся заметки ID %d: %s", rule.ID, describeRule(rule))
+		saved()
+	}, a.window)
+	d.Resize(fyne.NewSize(500, 0))
+	d.Show()
+}
+
+// deleteRecurringRule удаляет правило после подтверждения
+func (a *NoteApp) deleteRecurringRule(rule models.RecurringRule, deleted func()) {
+	dialog.ShowConfirm("Удаление правила",
+		fmt.Sprintf("Удалить правило «%s»? Уже созданные заметки останутся.", rule.Title),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := a.store.DeleteRecurringRule(rule.ID); err != nil {
+				a.showStoreError("не удалось удалить правило", err)
+				log.Printf("Ошибка при удалении правила ID %d: %v", rule.ID, err)
+				return
+			}
+			log.Printf("Удалено правило повторяющейся заметки ID %d", rule.ID)
+			deleted()
+		}, a.window)
+}
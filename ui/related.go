@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxRelated — сколько похожих заметок показывается под вложениями
+const maxRelated = 5
+
+// RelatedView — строка со ссылками на похожие заметки (по общим тегам и тексту)
+type RelatedView struct {
+	box     *fyne.Container
+	content fyne.CanvasObject
+
+	OnOpen func(id int)
+}
+
+// NewRelatedView создает строку похожих заметок; пока их нет, она скрыта
+func NewRelatedView() *RelatedView {
+	v := &RelatedView{box: container.NewHBox()}
+	v.content = container.NewBorder(nil, nil, widget.NewLabel("Похожие:"), nil, container.NewHScroll(v.box))
+	v.content.Hide()
+	return v
+}
+
+// updateRelated показывает заметки, похожие на выбранную, по фоновому индексу
+func (a *NoteApp) updateRelated() {
+	v := a.relatedView
+	v.box.RemoveAll()
+	selected := a.getSelectedNote()
+	if selected == nil || a.related == nil {
+		v.content.Hide()
+		return
+	}
+
+	titles := make(map[int]string, len(a.allNotes))
+	for _, note := range a.allNotes {
+		titles[note.ID] = note.Title
+	}
+	for _, match := range a.related.Related(selected.ID, maxRelated) {
+		title, ok := titles[match.NoteID]
+		if !ok { // Индекс мог еще не узнать об удалении
+			continue
+		}
+		id := match.NoteID
+		button := widget.NewButton(truncateTitle(title, maxFavoriteTitle), func() { v.OnOpen(id) })
+		button.Importance = widget.LowImportance
+		v.box.Add(button)
+	}
+	if len(v.box.Objects) == 0 {
+		v.content.Hide()
+	} else {
+		v.content.Show()
+	}
+	v.box.Refresh()
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// narrowWindowWidth — ширина окна, ниже которой список и заметка показываются по очереди
+const narrowWindowWidth = 700
+
+// responsiveSplit показывает список и заметку рядом, а в узком окне и на мобильных устройствах —
+// по одному: список, а после выбора заметки — ее с кнопкой возврата к списку
+type responsiveSplit struct {
+	widget.BaseWidget
+
+	split      *container.Split
+	listPage   fyne.CanvasObject // Список с кнопкой новой заметки для узкого окна
+	detailPage fyne.CanvasObject // Заметка с кнопкой «К списку» для узкого окна
+	content    *fyne.Container
+
+	narrow     bool
+	showDetail bool // В узком окне показана заметка, а не список
+}
+
+// newResponsiveSplit создает раскладку списка list и области заметки detail; onNew создает заметку
+func newResponsiveSplit(list, detail fyne.CanvasObject, onNew func()) *responsiveSplit {
+	r := &responsiveSplit{
+		split:   container.NewHSplit(list, detail),
+		content: container.NewStack(),
+	}
+	r.split.SetOffset(0.25) // Список занимает 25% ширины
+	newButton := widget.NewButtonWithIcon("Новая заметка", theme.ContentAddIcon(), func() {
+		onNew()
+		r.showNote()
+	})
+	backButton := widget.NewButtonWithIcon("К списку", theme.NavigateBackIcon(), r.showList)
+	r.listPage = container.NewBorder(container.NewHBox(layout.NewSpacer(), newButton), nil, nil, nil, list)
+	r.detailPage = container.NewBorder(container.NewHBox(backButton), nil, nil, nil, detail)
+	r.content.Objects = []fyne.CanvasObject{r.split}
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+// showNote переключает узкое окно на заметку; в широком окне заметка и так видна
+func (r *responsiveSplit) showNote() {
+	r.showDetail = true
+	r.update()
+}
+
+// showList возвращает узкое окно к списку
+func (r *responsiveSplit) showList() {
+	r.showDetail = false
+	r.update()
+}
+
+// setNarrow переключает раскладку по ширине окна
+func (r *responsiveSplit) setNarrow(narrow bool) {
+	if narrow == r.narrow {
+		return
+	}
+	r.narrow = narrow
+	r.update()
+}
+
+// update показывает разделенную раскладку или одну страницу узкого окна
+func (r *responsiveSplit) update() {
+	page := fyne.CanvasObject(r.split)
+	if r.narrow {
+		page = r.listPage
+		if r.showDetail {
+			page = r.detailPage
+		}
+	}
+	if len(r.content.Objects) == 1 && r.content.Objects[0] == page {
+		return
+	}
+	r.content.Objects = []fyne.CanvasObject{page}
+	r.content.Refresh()
+}
+
+// CreateRenderer создает отрисовку раскладки
+func (r *responsiveSplit) CreateRenderer() fyne.WidgetRenderer {
+	return &responsiveSplitRenderer{r: r}
+}
+
+// responsiveSplitRenderer выбирает раскладку при каждом изменении размера
+type responsiveSplitRenderer struct {
+	r *responsiveSplit
+}
+
+// MinSize возвращает размер страниц узкого окна, чтобы окно можно было сузить ниже ширины
+// разделенной раскладки: тогда оно переключится на страницы
+func (rr *responsiveSplitRenderer) MinSize() fyne.Size {
+	return rr.r.listPage.MinSize().Max(rr.r.detailPage.MinSize())
+}
+
+// Layout переключает раскладку по ширине и растягивает ее на весь размер
+func (rr *responsiveSplitRenderer) Layout(size fyne.Size) {
+	r := rr.r
+	r.setNarrow(fyne.CurrentDevice().IsMobile() || size.Width < fyne.Max(narrowWindowWidth, r.split.MinSize().Width))
+	r.content.Resize(size)
+}
+
+// Refresh перерисовывает текущую раскладку
+func (rr *responsiveSplitRenderer) Refresh() {
+	rr.r.content.Refresh()
+}
+
+// Objects возвращает текущую раскладку
+func (rr *responsiveSplitRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{rr.r.content}
+}
+
+// Destroy ничего не освобождает
+func (rr *responsiveSplitRenderer) Destroy() {}
@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// Узлы дерева восстановления: теги ("t:работа/проекты"), группа заметок без тегов
+// и заметки ("n:3:t:работа" — заметка 3 в теге работа; одна заметка может быть в нескольких тегах)
+const (
+	restoreTagPrefix  = "t:"
+	restoreNotePrefix = "n:"
+	restoreUntagged   = "u:"
+)
+
+// restoreTree — дерево выбора заметок из резервной копии: теги как блокноты, в них заметки
+type restoreTree struct {
+	notes    []models.Note
+	selected []bool                         // Отмечена ли заметка для восстановления
+	children map[widget.TreeNodeID][]string // Дочерние узлы; "" — верхний уровень
+	subtree  map[widget.TreeNodeID][]int    // Заметки узла-тега вместе с вложенными тегами
+}
+
+// newRestoreTree строит дерево по тегам заметок notes; сначала отмечены все заметки
+func newRestoreTree(notes []models.Note) *restoreTree {
+	t := &restoreTree{
+		notes:    notes,
+		selected: make([]bool, len(notes)),
+		children: make(map[widget.TreeNodeID][]string),
+		subtree:  make(map[widget.TreeNodeID][]int),
+	}
+	var tags []string
+	for i, note := range notes {
+		t.selected[i] = true
+		if len(note.Tags) == 0 {
+			t.subtree[restoreUntagged] = append(t.subtree[restoreUntagged], i)
+			continue
+		}
+		seen := make(map[string]bool) // Заметка с "а/б" и "а/в" входит в "а" один раз
+		for _, tag := range note.Tags {
+			for _, path := range models.TagAncestors(tag) {
+				if !seen[path] {
+					seen[path] = true
+					if t.subtree[restoreTagPrefix+path] == nil {
+						tags = append(tags, path)
+					}
+					t.subtree[restoreTagPrefix+path] = append(t.subtree[restoreTagPrefix+path], i)
+				}
+			}
+			id := restoreTagPrefix + tag
+			t.children[id] = append(t.children[id], restoreNotePrefix+strconv.Itoa(i)+":"+id)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return strings.ToLower(tags[i]) < strings.ToLower(tags[j]) })
+	for _, path := range tags {
+		parent := ""
+		if i := strings.LastIndex(path, models.TagSeparator); i != -1 {
+			parent = restoreTagPrefix + path[:i]
+		}
+		t.children[parent] = append(t.children[parent], restoreTagPrefix+path)
+	}
+	for id, nodes := range t.children { // Вложенные теги перед заметками тега
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return strings.HasPrefix(nodes[i], restoreTagPrefix) && !strings.HasPrefix(nodes[j], restoreTagPrefix)
+		})
+		t.children[id] = nodes
+	}
+	if untagged := t.subtree[restoreUntagged]; len(untagged) > 0 {
+		t.children[""] = append(t.children[""], restoreUntagged)
+		for _, i := range untagged {
+			t.children[restoreUntagged] = append(t.children[restoreUntagged], restoreNotePrefix+strconv.Itoa(i)+":"+restoreUntagged)
+		}
+	}
+	return t
+}
+
+// noteIndex возвращает номер заметки узла-заметки
+func (t *restoreTree) noteIndex(id widget.TreeNodeID) (int, bool) {
+	rest, ok := strings.CutPrefix(id, restoreNotePrefix)
+	if !ok {
+		return 0, false
+	}
+	number, _, _ := strings.Cut(rest, ":")
+	i, err := strconv.Atoi(number)
+	return i, err == nil
+}
+
+// label возвращает подпись узла
+func (t *restoreTree) label(id widget.TreeNodeID) string {
+	if i, ok := t.noteIndex(id); ok {
+		return t.notes[i].Title
+	}
+	name := "Без тегов"
+	if path, ok := strings.CutPrefix(id, restoreTagPrefix); ok {
+		name = path[strings.LastIndex(path, models.TagSeparator)+1:]
+	}
+	return fmt.Sprintf("%s (%d)", name, len(t.subtree[id]))
+}
+
+// state возвращает отметку узла: тег отмечен, если отмечены все его заметки, и частично — если некоторые
+func (t *restoreTree) state(id widget.TreeNodeID) (checked, partial bool) {
+	if i, ok := t.noteIndex(id); ok {
+		return t.selected[i], false
+	}
+	count := 0
+	for _, i := range t.subtree[id] {
+		if t.selected[i] {
+			count++
+		}
+	}
+	return count == len(t.subtree[id]), count > 0 && count < len(t.subtree[id])
+}
+
+// set отмечает заметку узла или все заметки тега
+func (t *restoreTree) set(id widget.TreeNodeID, checked bool) {
+	if i, ok := t.noteIndex(id); ok {
+		t.selected[i] = checked
+		return
+	}
+	for _, i := range t.subtree[id] {
+		t.selected[i] = checked
+	}
+}
+
+// setAll отмечает все заметки или снимает со всех отметку
+func (t *restoreTree) setAll(checked bool) {
+	for i := range t.selected {
+		t.selected[i] = checked
+	}
+}
+
+// chosen возвращает отмеченные заметки
+func (t *restoreTree) chosen() []models.Note {
+	var notes []models.Note
+	for i, note := range t.notes {
+		if t.selected[i] {
+			notes = append(notes, note)
+		}
+	}
+	return notes
+}
+
+// chooseRestore показывает заметки резервной копии деревом тегов, чтобы восстановить только отмеченные.
+// onChosen получает копию export только с выбранными заметками (и без организации, если ее не восстанавливать).
+func (a *NoteApp) chooseRestore(export *models.Export, onChosen func(*models.Export)) {
+	t := newRestoreTree(export.Notes)
+	summary := widget.NewLabel("")
+	updateSummary := func() {
+		summary.SetText(fmt.Sprintf("Отмечено заметок: %d из %d", len(t.chosen()), len(t.notes)))
+	}
+	updateSummary()
+
+	var tree *widget.Tree
+	tree = widget.NewTree(
+		func(id widget.TreeNodeID) []widget.TreeNodeID { return t.children[id] },
+		func(id widget.TreeNodeID) bool { return len(t.children[id]) > 0 },
+		func(bool) fyne.CanvasObject { return widget.NewCheck("заметка", nil) },
+		func(id widget.TreeNodeID, _ bool, o fyne.CanvasObject) {
+			check := o.(*widget.Check)
+			check.OnChanged = nil // SetChecked не должен менять отметки
+			check.Text = t.label(id)
+			checked, partial := t.state(id)
+			check.SetChecked(checked)
+			check.Partial = partial
+			check.Refresh()
+			check.OnChanged = func(on bool) {
+				t.set(id, on)
+				updateSummary()
+				tree.Refresh() // Обновляем отметки тегов и той же заметки в других тегах
+			}
+		},
+	)
+	for _, id := range t.children[""] {
+		tree.OpenBranch(id)
+	}
+
+	setAll := func(checked bool) func() {
+		return func() {
+			t.setAll(checked)
+			updateSummary()
+			tree.Refresh()
+		}
+	}
+	organization := widget.NewCheck("Восстановить оформление тегов, повторяющиеся заметки и сортировку", nil)
+	organization.SetChecked(true)
+	if len(export.TagStyles) == 0 && len(export.RecurringRules) == 0 && export.Settings == nil {
+		organization.SetChecked(false)
+		organization.Disable() // В файле только заметки
+	}
+	hint := widget.NewLabel("Для заметок, совпадающих с существующими, будет предложено пропустить, перезаписать или создать копию. " +
+		"Вложения будут импортированы, если файлы существуют.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	scroll := container.NewScroll(tree)
+	scroll.SetMinSize(fyne.NewSize(550, 350))
+	content := container.NewBorder(
+		container.NewHBox(
+			widget.NewButton("Отметить все", setAll(true)),
+			widget.NewButton("Снять все", setAll(false)),
+			layout.NewSpacer(),
+			summary,
+		),
+		container.NewVBox(organization, hint),
+		nil, nil,
+		scroll,
+	)
+	dialog.ShowCustomConfirm("Восстановление заметок", "Восстановить", "Отмена", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		chosen := *export
+		chosen.Notes = t.chosen()
+		if !organization.Checked {
+			chosen.TagStyles, chosen.RecurringRules, chosen.Settings = nil, nil, nil
+		}
+		if chosen.IsEmpty() {
+			dialog.ShowInformation("Восстановление заметок", "Ничего не отмечено.", a.window)
+			return
+		}
+		onChosen(&chosen)
+	}, a.window)
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+	"GNote/storage"
+)
+
+// saveAllAttachments копирует все вложения выбранной заметки в выбранный каталог под исходными именами
+func (a *NoteApp) saveAllAttachments() {
+	selectedNote := a.getSelectedNote()
+	if selectedNote == nil || len(selectedNote.Attachments) == 0 {
+		dialog.ShowInformation("Сохранение вложений", "У выбранной заметки нет вложений.", a.window)
+		return
+	}
+	attachments := selectedNote.Attachments
+	store := a.store
+
+	dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if uri == nil { // Пользователь отменил выбор
+			return
+		}
+		dir := uri.Path()
+
+		bar := widget.NewProgressBar()
+		bar.Max = float64(len(attachments))
+		progress := dialog.NewCustomWithoutButtons("Сохранение вложений",
+			container.NewVBox(widget.NewLabel(dir), bar), a.window)
+		progress.Show()
+
+		background.Go("сохранение вложений", func() {
+			var failed []string
+			for i, attachment := range attachments {
+				if err := saveAttachmentTo(store, attachment, dir); err != nil {
+					log.Printf("Ошибка при сохранении вложения ID %d в '%s': %v", attachment.ID, dir, err)
+					failed = append(failed, fmt.Sprintf("%s: %v", attachment.Filename, err))
+				}
+				fyne.Do(func() { bar.SetValue(float64(i + 1)) })
+			}
+			saved := len(attachments) - len(failed)
+			log.Printf("Вложения заметки ID %d сохранены в '%s': %d из %d", selectedNote.ID, dir, saved, len(attachments))
+			fyne.Do(func() {
+				progress.Hide()
+				if len(failed) > 0 {
+					dialog.ShowError(fmt.Errorf("сохранено вложений: %d из %d; не удалось сохранить:\n%s",
+						saved, len(attachments), strings.Join(failed, "\n")), a.window)
+					return
+				}
+				dialog.ShowInformation("Успех", fmt.Sprintf("Сохранено вложений: %d", saved), a.window)
+			})
+		})
+	}, a.window)
+}
+
+// saveAttachmentTo копирует вложение в каталог dir; если файл с таким именем уже есть,
+// к имени добавляется номер: "отчет (2).pdf"
+func saveAttachmentTo(store storage.Store, attachment models.Attachment, dir string) error {
+	var src io.ReadCloser
+	var err error
+	if attachment.InDatabase {
+		src, err = store.OpenAttachmentBlob(attachment.ID)
+	} else {
+		src, err = os.Open(attachment.Filepath)
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, path, err := createUnique(dir, filepath.Base(attachment.Filename))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if removeErr := os.Remove(path); removeErr != nil {
+			log.Printf("Ошибка: не удалось удалить недописанный файл '%s': %v", path, removeErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// createUnique создает в dir новый файл с именем name, а если оно занято — с первым свободным
+// именем вида "name (N).ext"; существующие файлы не перезаписываются
+func createUnique(dir, name string) (*os.File, string, error) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	for n := 1; ; n++ {
+		candidate := name
+		if n > 1 {
+			candidate = fmt.Sprintf("%s (%d)%s", stem, n, ext)
+		}
+		path := filepath.Join(dir, candidate)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			return f, path, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, "", fmt.Errorf("не удалось создать файл: %w", err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"encoding/json"
+	"log"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// savedSearchesPreference — сохраненные поиски профиля в формате JSON
+const savedSearchesPreference = "saved_searches"
+
+// savedSearch — сохраненный поиск: строка поиска и теги из дерева, включенные и исключенные
+type savedSearch struct {
+	Name        string   `json:"name"`
+	Query       string   `json:"query,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	ExcludeTags []string `json:"exclude_tags,omitempty"` // Заметки с этими тегами скрываются
+}
+
+// savedSearches возвращает сохраненные поиски профиля
+func (a *NoteApp) savedSearches() []savedSearch {
+	data := fyne.CurrentApp().Preferences().String(profileKey(savedSearchesPreference, a.profile))
+	if data == "" {
+		return nil
+	}
+	var searches []savedSearch
+	if err := json.Unmarshal([]byte(data), &searches); err != nil {
+		log.Printf("Ошибка при чтении сохраненных поисков: %v", err)
+		return nil
+	}
+	return searches
+}
+
+// setSavedSearches запоминает сохраненные поиски профиля
+func (a *NoteApp) setSavedSearches(searches []savedSearch) {
+	key := profileKey(savedSearchesPreference, a.profile)
+	if len(searches) == 0 {
+		fyne.CurrentApp().Preferences().RemoveValue(key)
+		return
+	}
+	data, err := json.Marshal(searches)
+	if err != nil {
+		log.Printf("Ошибка при сохранении поисков: %v", err)
+		return
+	}
+	fyne.CurrentApp().Preferences().SetString(key, string(data))
+}
+
+// currentSearch возвращает действующие строку поиска и фильтр по тегам как сохраняемый поиск
+func (a *NoteApp) currentSearch() savedSearch {
+	return savedSearch{
+		Query:       strings.TrimSpace(a.query),
+		Tags:        slices.Clone(a.includedTags),
+		ExcludeTags: slices.Clone(a.excludedTags),
+	}
+}
+
+// showSavedSearchMenu показывает под anchor меню сохраненных поисков: применить, сохранить текущий, удалить
+func (a *NoteApp) showSavedSearchMenu(anchor fyne.CanvasObject) {
+	searches := a.savedSearches()
+	var items []*fyne.MenuItem
+	for _, s := range searches {
+		items = append(items, fyne.NewMenuItem(s.Name, func() { a.applySavedSearch(s) }))
+	}
+	if len(items) > 0 {
+		items = append(items, fyne.NewMenuItemSeparator())
+	}
+	current := a.currentSearch()
+	saveItem := fyne.NewMenuItem("Сохранить текущий поиск...", a.saveSearchDialog)
+	saveItem.Disabled = current.Query == "" && len(current.Tags) == 0 && len(current.ExcludeTags) == 0
+	items = append(items, saveItem)
+	if len(searches) > 0 {
+		deleteItem := fyne.NewMenuItem("Удалить", nil)
+		var deleteItems []*fyne.MenuItem
+		for _, s := range searches {
+			deleteItems = append(deleteItems, fyne.NewMenuItem(s.Name, func() { a.deleteSavedSearch(s.Name) }))
+		}
+		deleteItem.ChildMenu = fyne.NewMenu("", deleteItems...)
+		items = append(items, deleteItem)
+	}
+
+	c := fyne.CurrentApp().Driver().CanvasForObject(anchor)
+	if c == nil {
+		return
+	}
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(anchor).AddXY(0, anchor.Size().Height)
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), c, pos)
+}
+
+// applySavedSearch подставляет строку поиска и фильтр по тегам сохраненного поиска
+func (a *NoteApp) applySavedSearch(s savedSearch) {
+	a.tagTree.unselect()
+	a.tagTree.setFilter(slices.Clone(s.Tags), slices.Clone(s.ExcludeTags))
+	a.searchEntry.SetText(s.Query)
+	log.Printf("Применен сохраненный поиск «%s»", s.Name)
+}
+
+// saveSearchDialog запрашивает имя и сохраняет текущий поиск; поиск с тем же именем заменяется
+func (a *NoteApp) saveSearchDialog() {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("Например: Работа без архива")
+	item := widget.NewFormItem("Название", entry)
+	item.HintText = "Сохраняются строка поиска и теги, выбранные и исключенные в дереве"
+	dialog.ShowForm("Сохранить поиск", "Сохранить", "Отмена", []*widget.FormItem{item}, func(confirmed bool) {
+		name := strings.TrimSpace(entry.Text)
+		if !confirmed || name == "" {
+			return
+		}
+		search := a.currentSearch()
+		search.Name = name
+		searches := slices.DeleteFunc(a.savedSearches(), func(s savedSearch) bool { return s.Name == name })
+		a.setSavedSearches(append(searches, search))
+		log.Printf("Сохранен поиск «%s»", name)
+	}, a.window)
+}
+
+// deleteSavedSearch удаляет сохраненный поиск name после подтверждения
+func (a *NoteApp) deleteSavedSearch(name string) {
+	dialog.ShowConfirm("Удалить поиск", "Удалить сохраненный поиск «"+name+"»?", func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		a.setSavedSearches(slices.DeleteFunc(a.savedSearches(), func(s savedSearch) bool { return s.Name == name }))
+		log.Printf("Удален сохраненный поиск «%s»", name)
+	}, a.window)
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/scan"
+	"GNote/storage"
+)
+
+// scanTimeout — сколько может длиться сканирование одной страницы
+const scanTimeout = 5 * time.Minute
+
+// SetScanner задает сканер документов; nil — программа сканирования не найдена
+func (a *NoteApp) SetScanner(s *scan.Scanner) {
+	a.scanner = s
+}
+
+// uiWaitTimeout — сколько фоновая задача ждет ответа потока интерфейса (при закрытии приложения он уже не ответит)
+const uiWaitTimeout = 5 * time.Second
+
+// CurrentNoteID возвращает ID открытой заметки или 0, если она не сохранена или не выбрана.
+// Можно вызывать из любой горутины.
+func (a *NoteApp) CurrentNoteID() int {
+	return selectedNoteID(func() *NoteApp { return a })
+}
+
+// CurrentNoteID возвращает ID заметки, открытой в окне заметок, или 0, если окно не открывали
+func (d *Daemon) CurrentNoteID() int {
+	return selectedNoteID(func() *NoteApp { return d.noteApp })
+}
+
+// selectedNoteID возвращает в потоке интерфейса ID заметки, выбранной в окне app
+func selectedNoteID(app func() *NoteApp) int {
+	result := make(chan int, 1)
+	fyne.Do(func() {
+		id := 0
+		if a := app(); a != nil {
+			if note := a.getSelectedNote(); note != nil {
+				id = note.ID
+			}
+		}
+		result <- id
+	})
+	select {
+	case id := <-result:
+		return id
+	case <-time.After(uiWaitTimeout):
+		return 0
+	}
+}
+
+// scanPage сканирует страницу в фоне и прикрепляет ее к открытой заметке
+func (a *NoteApp) scanPage() {
+	if a.scanner == nil {
+		dialog.ShowInformation("Сканирование",
+			"Программа сканирования не найдена. Установите SANE (scanimage) или укажите команду в разделе [scanner] настроек.", a.window)
+		return
+	}
+	selectedNote := a.getSelectedNote()
+	if selectedNote == nil {
+		dialog.ShowInformation("Ошибка", "Сначала выберите или сохраните заметку, чтобы прикрепить к ней скан.", a.window)
+		return
+	}
+	noteID := selectedNote.ID
+	scanner := a.scanner
+	store, dir, inDatabase := a.store, a.attachmentsDirPath, a.attachmentsInDB // Профиль могут сменить во время сканирования
+
+	ctx, cancel := context.WithTimeout(context.Background(), scanTimeout)
+	progress := dialog.NewCustom("Сканирование", "Отмена", widget.NewProgressBarInfinite(), a.window)
+	progress.SetOnClosed(cancel)
+	progress.Show()
+	background.Go("сканирование", func() {
+		defer cancel()
+		page, err := scanner.Scan(ctx)
+		if err != nil {
+			fyne.Do(func() {
+				progress.Hide()
+				if !errors.Is(err, context.Canceled) {
+					log.Printf("Ошибка при сканировании для заметки ID %d: %v", noteID, err)
+					dialog.ShowError(fmt.Errorf("не удалось отсканировать страницу: %w", err), a.window)
+				}
+			})
+			return
+		}
+		filename := "Скан " + time.Now().Format("2006-01-02 15-04-05") + filepath.Ext(page.Filename)
+		err = storage.SaveAttachmentData(store, dir, inDatabase, noteID, filename, page.MimeType, page.Data)
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				a.showStoreError("не удалось сохранить скан", err)
+				return
+			}
+			log.Printf("Скан '%s' прикреплен к заметке ID %d", filename, noteID)
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/storage"
+)
+
+// ShowSchemaError показывает окно устаревшей схемы БД: что именно отсутствует и кнопку обновления схемы.
+// migrate обновляет схему и заново открывает профиль в фоне; onConnected вызывается в потоке интерфейса после успеха.
+func ShowSchemaError(a fyne.App, cause *storage.SchemaError,
+	migrate func() (*ProfileSession, error), onConnected func(session *ProfileSession)) {
+	w := a.NewWindow("Схема базы данных устарела")
+
+	status := widget.NewLabel(schemaErrorText(cause))
+	status.Wrapping = fyne.TextWrapWord
+
+	progress := widget.NewProgressBarInfinite()
+	progress.Hide()
+
+	detailsButton := widget.NewButton("Подробнее", func() {
+		missing := widget.NewMultiLineEntry()
+		missing.SetText(strings.Join(cause.Missing, "\n"))
+		missing.SetMinRowsVisible(8)
+		hint := widget.NewLabel("Схему можно обновить и вручную от имени владельца БД:\npsql -d <база> -f database.sql\n" +
+			"Скрипт только добавляет недостающее и не удаляет данные.")
+		dialog.ShowCustom("Отсутствующие таблицы и столбцы", "Закрыть",
+			container.NewBorder(nil, hint, nil, nil, missing), w)
+	})
+	var migrateButton *widget.Button
+	migrateButton = widget.NewButtonWithIcon("Обновить схему", theme.ConfirmIcon(), func() {
+		migrateButton.Disable()
+		progress.Show()
+		status.SetText("Обновление схемы...")
+		go func() {
+			session, err := migrate()
+			fyne.Do(func() {
+				progress.Hide()
+				migrateButton.Enable()
+				if err != nil {
+					log.Printf("Ошибка при обновлении схемы БД: %v", err)
+					status.SetText(fmt.Sprintf("Не удалось обновить схему:\n%v\n\n"+
+						"Возможно, у пользователя БД нет прав на изменение таблиц: выполните database.sql от имени владельца базы.", err))
+					return
+				}
+				onConnected(session)
+				w.Close()
+			})
+		}()
+	})
+	migrateButton.Importance = widget.HighImportance
+	quitButton := widget.NewButton("Выход", a.Quit)
+
+	w.SetContent(container.NewVBox(
+		status,
+		progress,
+		container.NewHBox(detailsButton, layout.NewSpacer(), quitButton, migrateButton),
+	))
+	w.Resize(fyne.NewSize(640, 0))
+	w.CenterOnScreen()
+	w.Show()
+}
+
+// schemaErrorText формирует понятное сообщение об устаревшей схеме
+func schemaErrorText(err *storage.SchemaError) string {
+	return fmt.Sprintf("База данных создана более старой версией GNote: не хватает таблиц или столбцов (%d).\n\n"+
+		"Обновите схему — недостающие таблицы и столбцы будут добавлены, заметки останутся на месте.", len(err.Missing))
+}
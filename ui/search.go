@@ -0,0 +1,274 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// snippetContext — сколько символов текста показывать перед совпадением во фрагменте в списке заметок
+const snippetContext = 30
+
+// Стили фрагмента текста в списке: совпадения выделяются цветом и жирным шрифтом
+var (
+	snippetStyle   = widget.RichTextStyle{Inline: true, ColorName: theme.ColorNamePlaceHolder}
+	highlightStyle = widget.RichTextStyle{Inline: true, ColorName: theme.ColorNamePrimary, TextStyle: fyne.TextStyle{Bold: true}}
+)
+
+// anyAttachmentKinds — значения has:, означающие «есть любое вложение»
+var anyAttachmentKinds = map[string]bool{"attachment": true, "file": true, "вложение": true, "файл": true}
+
+// excludeTagPrefixes — приставки оператора исключения тега: -#архив или -tag:архив
+var excludeTagPrefixes = []string{"-#", "-tag:", "-тег:"}
+
+// searchQuery — разобранная строка поиска: свободный текст, операторы по вложениям
+// has:pdf (тип вложения) и file:счет (часть имени файла) и исключение тега -#архив
+type searchQuery struct {
+	text        string   // Свободный текст в нижнем регистре
+	has         []string // Типы вложений, которые должны быть у заметки
+	files       []string // Части имен файлов, которые должны быть у вложений заметки
+	excludeTags []string // Теги, заметки с которыми (или с вложенными в них) не подходят
+}
+
+// parseSearchQuery выделяет из строки поиска операторы has:, file: и -#, остальное считается текстом
+func parseSearchQuery(query string) searchQuery {
+	var q searchQuery
+	var words []string
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		switch {
+		case strings.HasPrefix(field, "has:") && len(field) > len("has:"):
+			q.has = append(q.has, strings.TrimPrefix(field, "has:"))
+		case strings.HasPrefix(field, "file:") && len(field) > len("file:"):
+			q.files = append(q.files, strings.TrimPrefix(field, "file:"))
+		case excludedTag(field) != "":
+			q.excludeTags = append(q.excludeTags, excludedTag(field))
+		default:
+			words = append(words, field)
+		}
+	}
+	q.text = strings.Join(words, " ")
+	return q
+}
+
+// excludedTag возвращает тег из оператора исключения вроде -#архив; "" — слово не оператор
+func excludedTag(field string) string {
+	for _, prefix := range excludeTagPrefixes {
+		if tag, ok := strings.CutPrefix(field, prefix); ok {
+			return models.NormalizeTag(tag)
+		}
+	}
+	return ""
+}
+
+// empty сообщает, что строка поиска ничего не ограничивает
+func (q searchQuery) empty() bool {
+	return q.text == "" && len(q.has) == 0 && len(q.files) == 0 && len(q.excludeTags) == 0
+}
+
+// matchesTags сообщает, что у заметки нет исключенных тегов (и вложенных в них)
+func (q searchQuery) matchesTags(note models.Note) bool {
+	for _, tag := range q.excludeTags {
+		if hasTagOrNested(note, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAttachments сообщает, что вложения заметки подходят под все операторы has: и file:
+func (q searchQuery) matchesAttachments(note models.Note) bool {
+	for _, kind := range q.has {
+		if !hasAttachmentKind(note, kind) {
+			return false
+		}
+	}
+	for _, part := range q.files {
+		found := false
+		for _, name := range note.AttachmentNames {
+			if strings.Contains(strings.ToLower(name), part) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAttachmentKind сообщает, что у заметки есть вложение вида kind: "pdf" — по подтипу MIME или
+// расширению файла, "image" — по типу MIME, "application/pdf" — по MIME целиком, "attachment" — любое
+func hasAttachmentKind(note models.Note, kind string) bool {
+	if anyAttachmentKinds[kind] {
+		return len(note.AttachmentNames) > 0
+	}
+	for i, name := range note.AttachmentNames {
+		mimeType := ""
+		if i < len(note.AttachmentTypes) {
+			mimeType = strings.ToLower(note.AttachmentTypes[i])
+		}
+		mimeType, _, _ = strings.Cut(mimeType, ";") // Без параметров вроде charset
+		major, minor, _ := strings.Cut(mimeType, "/")
+		if mimeType == kind || major == kind || minor == kind ||
+			strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".") == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// findMatches возвращает позиции (в символах) совпадений query в text без учета регистра;
+// query должна быть в нижнем регистре, как текст из parseSearchQuery
+func findMatches(text, query string) []int {
+	needle := []rune(query)
+	if len(needle) == 0 {
+		return nil
+	}
+	haystack := []rune(strings.ToLower(text)) // ToLower меняет символы один к одному, позиции сохраняются
+	var matches []int
+	for i := 0; i+len(needle) <= len(haystack); {
+		if slices.Equal(haystack[i:i+len(needle)], needle) {
+			matches = append(matches, i)
+			i += len(needle)
+		} else {
+			i++
+		}
+	}
+	return matches
+}
+
+// matchSnippet возвращает фрагмент text вокруг первого совпадения с query, где совпадения
+// выделены стилем highlight; nil — совпадений нет
+func matchSnippet(text, query string, plain, highlight widget.RichTextStyle) []widget.RichTextSegment {
+	matches := findMatches(text, query)
+	if len(matches) == 0 {
+		return nil
+	}
+	runes := []rune(text)
+	n := utf8.RuneCountInString(query)
+	start := max(matches[0]-snippetContext, 0)
+	end := min(matches[0]+n+2*snippetContext, len(runes))
+
+	var segments []widget.RichTextSegment
+	add := func(text string, style widget.RichTextStyle) {
+		text = strings.ReplaceAll(text, "\n", " ") // Фрагмент показывается одной строкой
+		if text != "" {
+			segments = append(segments, &widget.TextSegment{Text: text, Style: style})
+		}
+	}
+	prefix := ""
+	if start > 0 {
+		prefix = "…"
+	}
+	pos := start
+	for _, m := range matches {
+		if m+n > end {
+			break
+		}
+		add(prefix+string(runes[pos:m]), plain)
+		add(string(runes[m:m+n]), highlight)
+		prefix, pos = "", m+n
+	}
+	suffix := ""
+	if end < len(runes) {
+		suffix = "…"
+	}
+	add(prefix+string(runes[pos:end])+suffix, plain)
+	return segments
+}
+
+// selectEntryRange выделяет в поле ввода length символов начиная с позиции start.
+// У Entry нет метода для выделения, поэтому оно набирается как с клавиатуры, стрелками с Shift.
+// Строки Entry учитывают перенос слов, поэтому начало ищется по длине выделенного текста.
+func selectEntryRange(e *widget.Entry, start, length int) {
+	shift := &fyne.KeyEvent{Name: desktop.KeyShiftLeft}
+	press := func(name fyne.KeyName) { e.TypedKey(&fyne.KeyEvent{Name: name}) }
+	selected := func() int { return utf8.RuneCountInString(e.SelectedText()) }
+
+	e.KeyUp(shift)
+	press(fyne.KeyPageUp) // В начало текста, снимая прежнее выделение
+	if start > 0 {
+		// Выделяем от начала построчно, пока не дойдем до start, и возвращаемся на лишние символы
+		e.KeyDown(shift)
+		for n := selected(); n < start; {
+			press(fyne.KeyDown)
+			next := selected()
+			if next == n {
+				break // Конец текста
+			}
+			n = next
+		}
+		for selected() > start {
+			press(fyne.KeyLeft)
+		}
+		e.KeyUp(shift)
+		press(fyne.KeyRight) // Без Shift курсор встает в конец выделения, то есть в start
+	}
+	// На границе перенесенной строки стрелка только переводит курсор, поэтому нажатий может быть больше length
+	e.KeyDown(shift)
+	for i := 0; i < 2*length && selected() < length; i++ {
+		press(fyne.KeyRight)
+	}
+	e.KeyUp(shift)
+}
+
+// updateMatches ищет в открытой заметке совпадения со строкой поиска и показывает их количество
+func (a *NoteApp) updateMatches() {
+	query := parseSearchQuery(a.query).text
+	a.matches, a.currentMatch = nil, -1
+	if query == "" {
+		a.matchBar.Hide()
+		return
+	}
+	a.matches = findMatches(a.contentText(), query)
+	a.matchLength = utf8.RuneCountInString(query)
+	a.updateMatchLabel()
+	a.matchBar.Show()
+}
+
+// updateMatchLabel обновляет счетчик совпадений
+func (a *NoteApp) updateMatchLabel() {
+	switch {
+	case len(a.matches) == 0:
+		a.matchLabel.SetText("Совпадений нет")
+	case a.currentMatch < 0:
+		a.matchLabel.SetText(fmt.Sprintf("Совпадений: %d", len(a.matches)))
+	default:
+		a.matchLabel.SetText(fmt.Sprintf("Совпадение %d из %d", a.currentMatch+1, len(a.matches)))
+	}
+}
+
+// showMatch переходит к следующему (step = 1) или предыдущему (step = -1) совпадению по кругу
+func (a *NoteApp) showMatch(step int) {
+	n := len(a.matches)
+	if n == 0 {
+		return
+	}
+	switch {
+	case a.currentMatch >= 0:
+		a.currentMatch = (a.currentMatch + step + n) % n
+	case step > 0:
+		a.currentMatch = 0
+	default:
+		a.currentMatch = n - 1
+	}
+	pos := a.matches[a.currentMatch]
+	if a.largeView != nil {
+		text := []rune(a.contentText())
+		a.largeView.showLine(strings.Count(string(text[:pos]), "\n"))
+	} else {
+		a.window.Canvas().Focus(a.contentEntry)
+		selectEntryRange(&a.contentEntry.Entry, pos, a.matchLength)
+	}
+	a.updateMatchLabel()
+}
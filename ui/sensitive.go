@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// toggleSensitive отмечает открытую заметку как секретную или снимает отметку: меняет тег sensitive
+// в поле тегов, заметка становится измененной
+func (a *NoteApp) toggleSensitive() {
+	tags := a.editedTags()
+	if i := slices.IndexFunc(tags, func(tag string) bool { return strings.EqualFold(tag, models.SensitiveTag) }); i >= 0 {
+		tags = slices.Delete(tags, i, i+1)
+	} else {
+		tags = append(tags, models.SensitiveTag)
+	}
+	a.noteTags.Set(strings.Join(tags, ", "))
+}
+
+// markSensitive отмечает выделенный фрагмент как секретный: он скрывается при экспорте и передаче в другие программы
+func (a *NoteApp) markSensitive() {
+	selected := ""
+	if a.largeView == nil {
+		selected = a.contentEntry.SelectedText()
+	}
+	if selected == "" {
+		dialog.ShowInformation("Секретный фрагмент",
+			fmt.Sprintf("Выделите текст в редакторе или окружите его вручную: %s...%s", models.SensitiveStart, models.SensitiveEnd), a.window)
+		return
+	}
+	// Ввод заменяет выделение, как при подстановке сниппета, поэтому замену можно отменить
+	for _, r := range models.SensitiveStart + selected + models.SensitiveEnd {
+		a.contentEntry.TypedRune(r)
+	}
+}
+
+// editedNoteSensitive сообщает, что в открытой заметке есть секреты (с учетом несохраненных правок)
+func (a *NoteApp) editedNoteSensitive() bool {
+	note := models.Note{Tags: a.editedTags(), Content: a.contentText()}
+	return note.IsSensitive()
+}
+
+// confirmSensitive спрашивает, что делать с секретами заметок notes перед тем, как они покинут программу:
+// скрыть (onDone(true)) или передать как есть (onDone(false)). Без секретов onDone вызывается сразу.
+func (a *NoteApp) confirmSensitive(title string, notes []models.Note, onDone func(redact bool)) {
+	count := 0
+	for i := range notes {
+		if notes[i].IsSensitive() {
+			count++
+		}
+	}
+	if count == 0 {
+		onDone(false)
+		return
+	}
+	a.askSensitive(title, fmt.Sprintf("Секретных заметок или заметок с секретными фрагментами: %d.\n"+
+		"Скрыть секреты или включить их как есть?", count), onDone)
+}
+
+// askSensitive показывает вопрос о секретах с кнопками «Скрыть секреты», «Включить» и «Отмена»
+func (a *NoteApp) askSensitive(title, message string, onDone func(redact bool)) {
+	var d dialog.Dialog
+	choose := func(redact bool) func() {
+		return func() {
+			d.Hide()
+			onDone(redact)
+		}
+	}
+	redactButton := widget.NewButton("Скрыть секреты", choose(true))
+	redactButton.Importance = widget.HighImportance
+	includeButton := widget.NewButton("Включить", choose(false))
+	includeButton.Importance = widget.DangerImportance
+	cancelButton := widget.NewButton("Отмена", func() { d.Hide() })
+	label := widget.NewLabel(message)
+	label.Wrapping = fyne.TextWrapWord
+	buttons := container.NewHBox(layout.NewSpacer(), cancelButton, includeButton, redactButton)
+	d = dialog.NewCustomWithoutButtons(title, container.NewVBox(label, buttons), a.window)
+	d.Resize(fyne.NewSize(460, d.MinSize().Height))
+	d.Show()
+}
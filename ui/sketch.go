@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"math"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/storage"
+)
+
+// Размер рисунка в пикселях; холст на экране растягивает его до своего размера
+const (
+	sketchWidth  = 1200
+	sketchHeight = 800
+)
+
+// Толщина пера и ластика в пикселях рисунка
+const (
+	penWidth    = 3
+	eraserWidth = 24
+)
+
+// sketchColors — цвета пера по названиям в порядке выбора
+var sketchColors = []struct {
+	name  string
+	color color.RGBA
+}{
+	{"Черный", color.RGBA{A: 0xff}},
+	{"Красный", color.RGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff}},
+	{"Синий", color.RGBA{R: 0x19, G: 0x76, B: 0xd2, A: 0xff}},
+	{"Зеленый", color.RGBA{R: 0x38, G: 0x8e, B: 0x3c, A: 0xff}},
+	{"Оранжевый", color.RGBA{R: 0xf5, G: 0x7c, B: 0x00, A: 0xff}},
+}
+
+// sketchPad — холст для рисования мышью или пером: линии рисуются на изображении по перетаскиванию
+type sketchPad struct {
+	widget.BaseWidget
+	img     *image.RGBA
+	view    *canvas.Image
+	color   color.RGBA
+	width   float64
+	erasing bool
+	empty   bool // На холсте еще ничего не нарисовано
+}
+
+// newSketchPad создает чистый белый холст с черным пером
+func newSketchPad() *sketchPad {
+	p := &sketchPad{
+		img:   image.NewRGBA(image.Rect(0, 0, sketchWidth, sketchHeight)),
+		color: sketchColors[0].color,
+		width: penWidth,
+	}
+	p.view = canvas.NewImageFromImage(p.img)
+	p.view.FillMode = canvas.ImageFillStretch
+	p.Clear()
+	p.ExtendBaseWidget(p)
+	return p
+}
+
+// Clear стирает весь рисунок
+func (p *sketchPad) Clear() {
+	draw.Draw(p.img, p.img.Bounds(), image.White, image.Point{}, draw.Src)
+	p.empty = true
+	p.view.Refresh()
+}
+
+// Tapped ставит точку в месте нажатия
+func (p *sketchPad) Tapped(e *fyne.PointEvent) {
+	p.stroke(e.Position, e.Position)
+}
+
+// Dragged рисует линию от предыдущей точки перетаскивания до текущей
+func (p *sketchPad) Dragged(e *fyne.DragEvent) {
+	from := e.Position.Subtract(fyne.NewPos(e.Dragged.DX, e.Dragged.DY))
+	p.stroke(from, e.Position)
+}
+
+// DragEnd завершает линию; отдельных действий не требуется
+func (p *sketchPad) DragEnd() {}
+
+// stroke рисует отрезок между точками холста пером или ластиком
+func (p *sketchPad) stroke(from, to fyne.Position) {
+	size := p.Size()
+	if size.Width == 0 || size.Height == 0 {
+		return
+	}
+	sx, sy := sketchWidth/float64(size.Width), sketchHeight/float64(size.Height)
+	x0, y0 := float64(from.X)*sx, float64(from.Y)*sy
+	x1, y1 := float64(to.X)*sx, float64(to.Y)*sy
+
+	c, width := p.color, p.width
+	if p.erasing {
+		c, width = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, eraserWidth
+	} else {
+		p.empty = false
+	}
+	// Линия — круги вдоль отрезка с шагом в пиксель: так концы и изгибы получаются скругленными
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))) + 1
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		p.dot(x0+(x1-x0)*t, y0+(y1-y0)*t, width/2, c)
+	}
+	p.view.Refresh()
+}
+
+// dot закрашивает круг радиуса r с центром (cx, cy)
+func (p *sketchPad) dot(cx, cy, r float64, c color.RGBA) {
+	bounds := p.img.Bounds()
+	for y := int(cy - r); y <= int(cy+r); y++ {
+		for x := int(cx - r); x <= int(cx+r); x++ {
+			dx, dy := float64(x)-cx, float64(y)-cy
+			if dx*dx+dy*dy <= r*r && image.Pt(x, y).In(bounds) {
+				p.img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+// PNG кодирует рисунок в PNG
+func (p *sketchPad) PNG() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, p.img); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить рисунок в PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// CreateRenderer показывает изображение рисунка во весь размер холста
+func (p *sketchPad) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(p.view)
+}
+
+// MinSize — холст с пропорциями рисунка, помещающийся в диалог
+func (p *sketchPad) MinSize() fyne.Size {
+	return fyne.NewSize(sketchWidth/2, sketchHeight/2)
+}
+
+// showSketch открывает холст для рисунка; сохраненный рисунок прикрепляется к заметке PNG-файлом
+func (a *NoteApp) showSketch() {
+	selectedNote := a.getSelectedNote()
+	if selectedNote == nil {
+		dialog.ShowInformation("Ошибка", "Сначала выберите или сохраните заметку, чтобы прикрепить к ней рисунок.", a.window)
+		return
+	}
+	noteID := selectedNote.ID
+
+	pad := newSketchPad()
+	tool := widget.NewRadioGroup([]string{"Перо", "Ластик"}, func(s string) { pad.erasing = s == "Ластик" })
+	tool.Horizontal = true
+	tool.Required = true
+	tool.SetSelected("Перо")
+	colorNames := make([]string, len(sketchColors))
+	for i, c := range sketchColors {
+		colorNames[i] = c.name
+	}
+	colorSelect := widget.NewSelect(colorNames, func(name string) {
+		for _, c := range sketchColors {
+			if c.name == name {
+				pad.color = c.color
+			}
+		}
+		tool.SetSelected("Перо") // Выбор цвета возвращает к перу
+	})
+	colorSelect.SetSelected(sketchColors[0].name)
+	clearButton := widget.NewButton("Очистить", pad.Clear)
+
+	toolbar := container.NewHBox(tool, widget.NewLabel("Цвет:"), colorSelect, clearButton)
+	d := dialog.NewCustomConfirm("Рисунок", "Прикрепить", "Отмена", container.NewBorder(toolbar, nil, nil, nil, pad), func(ok bool) {
+		if !ok {
+			return
+		}
+		if pad.empty {
+			dialog.ShowInformation("Рисунок", "На холсте ничего не нарисовано.", a.window)
+			return
+		}
+		data, err := pad.PNG()
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		a.saveSketch(noteID, data)
+	}, a.window)
+	d.Resize(fyne.NewSize(sketchWidth/2+40, sketchHeight/2+160))
+	d.Show()
+}
+
+// saveSketch прикрепляет рисунок к заметке noteID в фоне; список вложений обновится по событию хранилища
+func (a *NoteApp) saveSketch(noteID int, data []byte) {
+	filename := "Рисунок " + time.Now().Format("2006-01-02 15-04-05") + ".png"
+	store, dir, inDatabase := a.store, a.attachmentsDirPath, a.attachmentsInDB // Профиль могут сменить во время сохранения
+	background.Go("рисунок "+filename, func() {
+		if err := storage.SaveAttachmentData(store, dir, inDatabase, noteID, filename, "image/png", data); err != nil {
+			log.Printf("Ошибка при сохранении рисунка для заметки ID %d: %v", noteID, err)
+			fyne.Do(func() { a.showStoreError("не удалось сохранить рисунок", err) })
+			return
+		}
+		log.Printf("Рисунок '%s' прикреплен к заметке ID %d", filename, noteID)
+	})
+}
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// loadSnippets загружает сниппеты; без них сокращения просто не разворачиваются
+func (a *NoteApp) loadSnippets() {
+	snippets, err := a.store.GetSnippets()
+	if err != nil {
+		log.Printf("Ошибка при загрузке сниппетов: %v", err)
+		return
+	}
+	a.setSnippets(snippets)
+}
+
+// setSnippets запоминает сниппеты, по которым разворачиваются сокращения в редакторе
+func (a *NoteApp) setSnippets(snippets []models.Snippet) {
+	a.snippets = make(map[string]models.Snippet, len(snippets))
+	for _, snippet := range snippets {
+		a.snippets[snippet.Abbreviation] = snippet
+	}
+}
+
+// expandAbbreviation возвращает расшифровку сниппета с сокращением word и отступ курсора от ее конца
+func (a *NoteApp) expandAbbreviation(word string) (string, int, bool) {
+	snippet, ok := a.snippets[word]
+	if !ok {
+		return "", 0, false
+	}
+	text, cursorBack := snippet.Expand(time.Now())
+	return text, cursorBack, true
+}
+
+// describeSnippet возвращает описание сниппета для списка: "адр → г. Москва, ул. Ленина…"
+func describeSnippet(snippet models.Snippet) string {
+	expansion := strings.Join(strings.Fields(snippet.Expansion), " ")
+	return fmt.Sprintf("%s → %s", snippet.Abbreviation, truncateTitle(expansion, 60))
+}
+
+// showSnippets показывает сниппеты с кнопками изменения и удаления
+func (a *NoteApp) showSnippets() {
+	rows := container.NewVBox()
+	var reload func()
+	reload = func() {
+		snippets, err := a.store.GetSnippets()
+		if err != nil {
+			a.showStoreError("не удалось загрузить сниппеты", err)
+			log.Printf("Ошибка при загрузке сниппетов: %v", err)
+			return
+		}
+		a.setSnippets(snippets)
+		rows.RemoveAll()
+		if len(snippets) == 0 {
+			rows.Add(widget.NewLabel("Сниппетов пока нет. Например, «адр» с пробелом после него может превращаться в полный адрес."))
+		}
+		for _, snippet := range snippets {
+			snippet := snippet
+			label := widget.NewLabel(describeSnippet(snippet))
+			label.Wrapping = fyne.TextWrapWord
+			editButton := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() { a.editSnippet(snippet, reload) })
+			deleteButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() { a.deleteSnippet(snippet, reload) })
+			rows.Add(container.NewBorder(nil, nil, nil, container.NewHBox(editButton, deleteButton), label))
+		}
+	}
+	reload()
+
+	addButton := widget.NewButtonWithIcon("Добавить сниппет", theme.ContentAddIcon(), func() {
+		a.editSnippet(models.Snippet{}, reload)
+	})
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(600, 300))
+	content := container.NewBorder(nil, container.NewHBox(layout.NewSpacer(), addButton), nil, nil, scroll)
+	dialog.ShowCustom("Сниппеты", "Закрыть", content, a.window)
+}
+
+// editSnippet показывает форму сниппета; после сохранения вызывает saved
+func (a *NoteApp) editSnippet(snippet models.Snippet, saved func()) {
+	abbreviationEntry := widget.NewEntry()
+	abbreviationEntry.SetPlaceHolder("Например: адр")
+	abbreviationEntry.SetText(snippet.Abbreviation)
+	expansionEntry := widget.NewMultiLineEntry()
+	expansionEntry.SetPlaceHolder("Например: Встреча {date}\nУчастники: {cursor}")
+	expansionEntry.SetText(snippet.Expansion)
+	expansionEntry.SetMinRowsVisible(5)
+
+	abbreviationItem := widget.NewFormItem("Сокращение", abbreviationEntry)
+	abbreviationItem.HintText = "Разворачивается после пробела, знака препинания или Enter"
+	expansionItem := widget.NewFormItem("Расшифровка", expansionEntry)
+	expansionItem.HintText = "{cursor} — место курсора, {date} — дата, {week} — номер недели"
+	formTitle := "Новый сниппет"
+	if snippet.ID != 0 {
+		formTitle = "Изменить сниппет"
+	}
+	d := dialog.NewForm(formTitle, "Сохранить", "Отмена", []*widget.FormItem{abbreviationItem, expansionItem}, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		snippet.Abbreviation = strings.TrimSpace(abbreviationEntry.Text)
+		snippet.Expansion = expansionEntry.Text
+		if err := snippet.Validate(); err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if other, ok := a.snippets[snippet.Abbreviation]; ok && other.ID != snippet.ID {
+			dialog.ShowError(fmt.Errorf("сниппет с сокращением %q уже есть", snippet.Abbreviation), a.window)
+			return
+		}
+		if err := a.store.SaveSnippet(&snippet); err != nil {
+			a.showStoreError("не удалось сохранить сниппет", err)
+			log.Printf("Ошибка при сохранении сниппета: %v", err)
+			return
+		}
+		log.Printf("Сохранен сниппет ID %d: %s", snippet.ID, snippet.Abbreviation)
+		saved()
+	}, a.window)
+	d.Resize(fyne.NewSize(500, 0))
+	d.Show()
+}
+
+// deleteSnippet удаляет сниппет после подтверждения
+func (a *NoteApp) deleteSnippet(snippet models.Snippet, deleted func()) {
+	dialog.ShowConfirm("Удаление сниппета",
+		fmt.Sprintf("Удалить сниппет «%s»? Уже развернутый текст в заметках останется.", snippet.Abbreviation),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := a.store.DeleteSnippet(snippet.ID); err != nil {
+				a.showStoreError("не удалось удалить сниппет", err)
+				log.Printf("Ошибка при удалении сниппета ID %d: %v", snippet.ID, err)
+				return
+			}
+			log.Printf("Удален сниппет ID %d", snippet.ID)
+			deleted()
+		}, a.window)
+}
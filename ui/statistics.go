@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+const (
+	chartHeight    = 150 // Высота самого высокого столбца графика по месяцам
+	maxChartMonths = 24  // Сколько последних месяцев показывает график
+)
+
+// statsPeriods — периоды статистики и начало каждого относительно now
+var statsPeriods = []struct {
+	name string
+	from func(now time.Time) time.Time
+}{
+	{"За все время", func(time.Time) time.Time { return time.Time{} }},
+	{"За последний год", func(now time.Time) time.Time { return now.AddDate(-1, 0, 0) }},
+	{"За последние 3 месяца", func(now time.Time) time.Time { return now.AddDate(0, -3, 0) }},
+	{"За последний месяц", func(now time.Time) time.Time { return now.AddDate(0, -1, 0) }},
+}
+
+// formatStats возвращает подпись объема написанного
+func formatStats(s models.WritingStats) string {
+	return fmt.Sprintf("Заметок: %d, слов: %d, символов: %d", s.Notes, s.Words, s.Chars)
+}
+
+// formatUsage возвращает подпись места, занятого заметками и вложениями
+func formatUsage(u models.StorageUsage) string {
+	text := fmt.Sprintf("Всего заметок: %d (текст — %s), вложений: %d", u.Notes, formatBytes(u.ContentBytes), u.Attachments)
+	switch {
+	case u.FileBytes > 0 && u.DatabaseBytes > 0:
+		text += fmt.Sprintf(" (файлы — %s, в БД — %s)", formatBytes(u.FileBytes), formatBytes(u.DatabaseBytes))
+	case u.Attachments > 0:
+		text += fmt.Sprintf(" (%s)", formatBytes(u.FileBytes+u.DatabaseBytes))
+	}
+	return text
+}
+
+// showStatistics показывает статистику заметок за выбранный период: итог, слова по тегам и объем по месяцам
+func (a *NoteApp) showStatistics() {
+	totalLabel := widget.NewLabel("")
+	usageLabel := widget.NewLabel("Подсчет занятого места…")
+	store := a.store
+	background.Go("подсчет занятого места", func() {
+		usage, err := store.StorageUsage()
+		fyne.Do(func() {
+			if err != nil {
+				log.Printf("Ошибка при подсчете занятого места: %v", err)
+				usageLabel.SetText("Не удалось подсчитать занятое место.")
+				return
+			}
+			usageLabel.SetText(formatUsage(*usage))
+		})
+	})
+	tagsBox := container.NewVBox()
+	chartBox := container.NewStack()
+
+	load := func(from time.Time) {
+		stats, err := a.store.GetStatistics(from)
+		if err != nil {
+			a.showStoreError("не удалось загрузить статистику", err)
+			log.Printf("Ошибка при загрузке статистики: %v", err)
+			return
+		}
+		totalLabel.SetText(formatStats(stats.Total))
+
+		tagsBox.RemoveAll()
+		if len(stats.Tags) == 0 {
+			tagsBox.Add(widget.NewLabel("Заметок с тегами за этот период нет."))
+		} else {
+			grid := container.NewGridWithColumns(4,
+				boldLabel("Тег"), boldLabel("Заметок"), boldLabel("Слов"), boldLabel("Символов"))
+			for _, tag := range stats.Tags {
+				grid.Add(widget.NewLabel(tag.Tag))
+				grid.Add(widget.NewLabel(fmt.Sprint(tag.Notes)))
+				grid.Add(widget.NewLabel(fmt.Sprint(tag.Words)))
+				grid.Add(widget.NewLabel(fmt.Sprint(tag.Chars)))
+			}
+			tagsBox.Add(grid)
+		}
+
+		chartBox.Objects = []fyne.CanvasObject{newMonthChart(stats.Months, time.Now())}
+		chartBox.Refresh()
+	}
+
+	names := make([]string, len(statsPeriods))
+	for i, p := range statsPeriods {
+		names[i] = p.name
+	}
+	periodSelect := widget.NewSelect(names, nil)
+	periodSelect.OnChanged = func(string) {
+		load(statsPeriods[periodSelect.SelectedIndex()].from(time.Now()))
+	}
+	periodSelect.SetSelectedIndex(0) // Загружает статистику за все время
+
+	tagsScroll := container.NewVScroll(tagsBox)
+	tagsScroll.SetMinSize(fyne.NewSize(600, 200))
+	content := container.NewVBox(
+		container.NewHBox(periodSelect, totalLabel),
+		usageLabel,
+		widget.NewSeparator(),
+		boldLabel("Слова по тегам"),
+		tagsScroll,
+		widget.NewSeparator(),
+		boldLabel("Написано слов по месяцам"),
+		chartBox,
+	)
+	dialog.ShowCustom("Статистика", "Закрыть", content, a.window)
+}
+
+// boldLabel создает подпись жирным шрифтом
+func boldLabel(text string) *widget.Label {
+	return widget.NewLabelWithStyle(text, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+}
+
+// newMonthChart рисует столбцы слов по месяцам до месяца now включительно; месяцы без заметок — пустые
+func newMonthChart(months []models.MonthStats, now time.Time) fyne.CanvasObject {
+	if len(months) == 0 {
+		return widget.NewLabel("Заметок за этот период нет.")
+	}
+	words := make(map[string]int, len(months))
+	for _, m := range months {
+		words[m.Month.Format("2006-01")] += m.Words
+	}
+	last := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	first := time.Date(months[0].Month.Year(), months[0].Month.Month(), 1, 0, 0, 0, 0, time.Local)
+	if limit := last.AddDate(0, -(maxChartMonths - 1), 0); first.Before(limit) {
+		first = limit
+	}
+
+	var keys []time.Time
+	maxWords := 1
+	for m := first; !m.After(last); m = m.AddDate(0, 1, 0) {
+		keys = append(keys, m)
+		maxWords = max(maxWords, words[m.Format("2006-01")])
+	}
+	chart := container.NewGridWithColumns(len(keys))
+	for _, m := range keys {
+		n := words[m.Format("2006-01")]
+		bar := canvas.NewRectangle(theme.Color(theme.ColorNamePrimary))
+		if n == 0 {
+			bar.FillColor = color.Transparent
+		}
+		bar.SetMinSize(fyne.NewSize(8, float32(chartHeight*n/maxWords)))
+		count := widget.NewLabelWithStyle(fmt.Sprint(n), fyne.TextAlignCenter, fyne.TextStyle{})
+		count.SizeName = theme.SizeNameCaptionText
+		month := widget.NewLabelWithStyle(m.Format("01.06"), fyne.TextAlignCenter, fyne.TextStyle{})
+		month.SizeName = theme.SizeNameCaptionText
+		chart.Add(container.NewVBox(layout.NewSpacer(), count, bar, month)) // Столбцы выровнены по низу
+	}
+	return chart
+}
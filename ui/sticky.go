@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/storage"
+)
+
+// stickyNotesPreference — ключ настроек со списком ID заметок, открытых стикерами
+const stickyNotesPreference = "sticky_notes"
+
+// Размер нового стикера
+const (
+	stickyWidth  = 280
+	stickyHeight = 300
+)
+
+// stickyColor — цвет стикера. Полупрозрачный, чтобы текст оставался читаемым и в светлой, и в темной теме.
+type stickyColor struct {
+	name  string
+	color color.NRGBA
+}
+
+// stickyColors — цвета стикеров; первый используется по умолчанию
+var stickyColors = []stickyColor{
+	{"Желтый", color.NRGBA{R: 0xff, G: 0xd8, B: 0x4d, A: 0x80}},
+	{"Зеленый", color.NRGBA{R: 0x7c, G: 0xd6, B: 0x7c, A: 0x80}},
+	{"Голубой", color.NRGBA{R: 0x6e, G: 0xc1, B: 0xf0, A: 0x80}},
+	{"Розовый", color.NRGBA{R: 0xf5, G: 0x8f, B: 0xba, A: 0x80}},
+	{"Сиреневый", color.NRGBA{R: 0xb9, G: 0x9a, B: 0xf0, A: 0x80}},
+}
+
+// stickyState — оформление окна-стикера
+type stickyState struct {
+	color string // Название цвета из stickyColors
+	bg    *canvas.Rectangle
+}
+
+// stickyColorByName возвращает цвет стикера по названию; неизвестное название — цвет по умолчанию
+func stickyColorByName(name string) color.Color {
+	for _, c := range stickyColors {
+		if c.name == name {
+			return c.color
+		}
+	}
+	return stickyColors[0].color
+}
+
+// stickyKey возвращает ключ настройки стикера заметки id для профиля
+func stickyKey(key string, id int, profile string) string {
+	return profileKey(fmt.Sprintf("sticky_%d_%s", id, key), profile)
+}
+
+// openSticky открывает выбранную заметку небольшим цветным окном-стикером.
+// Стикеры открываются снова при следующем запуске с тем же цветом и размером.
+func (a *NoteApp) openSticky() {
+	a.openSelectedInWindow("Стикер", true)
+}
+
+// makeSticky оформляет окно заметки как стикер: цветной фон, выбор цвета и сохраненный размер
+func (a *NoteApp) makeSticky(nw *noteWindow) {
+	prefs := fyne.CurrentApp().Preferences()
+	id := nw.pane.note.ID
+	state := &stickyState{color: prefs.StringWithFallback(stickyKey("color", id, a.profile), stickyColors[0].name)}
+	state.bg = canvas.NewRectangle(stickyColorByName(state.color))
+	nw.sticky = state
+
+	names := make([]string, len(stickyColors))
+	for i, c := range stickyColors {
+		names[i] = c.name
+	}
+	colorSelect := widget.NewSelect(names, func(name string) {
+		state.color = name
+		state.bg.FillColor = stickyColorByName(name)
+		state.bg.Refresh()
+		prefs.SetString(stickyKey("color", id, a.profile), name)
+	})
+	colorSelect.SetSelected(state.color)
+
+	nw.window.SetContent(container.NewStack(state.bg,
+		container.NewBorder(container.NewHBox(layout.NewSpacer(), colorSelect), nil, nil, nil, nw.pane.content)))
+	nw.window.Resize(fyne.NewSize(
+		float32(prefs.FloatWithFallback(stickyKey("width", id, a.profile), stickyWidth)),
+		float32(prefs.FloatWithFallback(stickyKey("height", id, a.profile), stickyHeight))))
+	a.rememberSticky(id)
+}
+
+// rememberSticky добавляет заметку в список стикеров профиля
+func (a *NoteApp) rememberSticky(id int) {
+	prefs := fyne.CurrentApp().Preferences()
+	key := profileKey(stickyNotesPreference, a.profile)
+	ids := prefs.IntList(key)
+	if !slices.Contains(ids, id) {
+		prefs.SetIntList(key, append(ids, id))
+	}
+}
+
+// forgetSticky убирает заметку из списка стикеров профиля вместе с ее цветом и размером
+func (a *NoteApp) forgetSticky(id int) {
+	prefs := fyne.CurrentApp().Preferences()
+	key := profileKey(stickyNotesPreference, a.profile)
+	prefs.SetIntList(key, slices.DeleteFunc(prefs.IntList(key), func(v int) bool { return v == id }))
+	for _, k := range []string{"color", "width", "height"} {
+		prefs.RemoveValue(stickyKey(k, id, a.profile))
+	}
+}
+
+// saveStickyState запоминает размеры открытых стикеров
+func (a *NoteApp) saveStickyState() {
+	prefs := fyne.CurrentApp().Preferences()
+	for id, nw := range a.noteWindows {
+		size := nw.window.Canvas().Size()
+		if nw.sticky == nil || size.Width == 0 || size.Height == 0 {
+			continue
+		}
+		prefs.SetFloat(stickyKey("width", id, a.profile), float64(size.Width))
+		prefs.SetFloat(stickyKey("height", id, a.profile), float64(size.Height))
+	}
+}
+
+// restoreStickyNotes открывает стикеры прошлого сеанса; удаленные заметки убираются из списка
+func (a *NoteApp) restoreStickyNotes() {
+	for _, id := range fyne.CurrentApp().Preferences().IntList(profileKey(stickyNotesPreference, a.profile)) {
+		note, err := a.store.GetNoteByID(id)
+		if err != nil {
+			log.Printf("Не удалось восстановить стикер заметки ID %d: %v", id, err)
+			if errors.Is(err, storage.ErrNoteNotFound) { // Без связи с БД стикер остается до следующего запуска
+				a.forgetSticky(id)
+			}
+			continue
+		}
+		a.showNoteWindow(*note, true)
+	}
+}
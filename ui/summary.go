@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+	"GNote/summary"
+)
+
+// summaryTimeout — сколько ждать пересказа
+const summaryTimeout = 2 * time.Minute
+
+// SetSummarizer задает способ краткого пересказа заметок; nil — локальный пересказ
+func (a *NoteApp) SetSummarizer(s summary.Summarizer) {
+	a.summarizer = s
+}
+
+// summarizeNote пересказывает открытую заметку в фоне и показывает результат.
+// Перед отправкой заметки с секретами во внешний сервис спрашивает, скрыть ли их.
+func (a *NoteApp) summarizeNote() {
+	if a.summarizer == nil || !a.editedNoteSensitive() {
+		a.runSummary(a.contentText())
+		return
+	}
+	a.askSensitive("Краткий пересказ", "В заметке есть секреты, а пересказ выполняет внешний сервис.\n"+
+		"Скрыть секреты или отправить заметку как есть?", func(redact bool) {
+		note := models.Note{Tags: a.editedTags(), Content: a.contentText()}
+		if redact {
+			note = note.Redacted()
+		}
+		a.runSummary(note.Content)
+	})
+}
+
+// runSummary пересказывает текст text в фоне и показывает результат
+func (a *NoteApp) runSummary(text string) {
+	summarizer := a.summarizer
+	if summarizer == nil {
+		summarizer = summary.Local{}
+	}
+	progress := dialog.NewCustomWithoutButtons("Краткий пересказ", widget.NewProgressBarInfinite(), a.window)
+	progress.Show()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), summaryTimeout)
+		defer cancel()
+		result, err := summarizer.Summarize(ctx, text)
+		fyne.Do(func() {
+			progress.Hide()
+			switch {
+			case errors.Is(err, summary.ErrEmpty):
+				dialog.ShowInformation("Краткий пересказ", "В заметке нет текста для пересказа.", a.window)
+			case err != nil:
+				log.Printf("Ошибка при пересказе заметки: %v", err)
+				dialog.ShowError(fmt.Errorf("не удалось пересказать заметку: %w", err), a.window)
+			default:
+				a.showSummary(result)
+			}
+		})
+	}()
+}
+
+// showSummary показывает пересказ с кнопками "Вставить в начало" и "Копировать"
+func (a *NoteApp) showSummary(text string) {
+	label := widget.NewLabel(text)
+	label.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(label)
+	scroll.SetMinSize(fyne.NewSize(500, 200))
+
+	var d dialog.Dialog
+	insertButton := widget.NewButton("Вставить в начало", func() {
+		d.Hide()
+		a.setContentText("**Кратко:** " + text + "\n\n" + a.contentText()) // Заметка станет измененной
+	})
+	copyButton := widget.NewButton("Копировать", func() {
+		a.window.Clipboard().SetContent(text)
+	})
+	closeButton := widget.NewButton("Закрыть", func() { d.Hide() })
+	buttons := container.NewHBox(layout.NewSpacer(), insertButton, copyButton, closeButton)
+	d = dialog.NewCustomWithoutButtons("Краткий пересказ", container.NewBorder(nil, buttons, nil, nil, scroll), a.window)
+	d.Show()
+}
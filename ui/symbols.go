@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	recentSymbolsPreference = "recent_symbols"
+	maxRecentSymbols        = 16
+)
+
+// symbol — эмодзи или символ Unicode и слова, по которым он ищется
+type symbol struct {
+	char     string
+	keywords string
+}
+
+// symbolGroups — эмодзи и символы выбора по группам
+var symbolGroups = []struct {
+	name    string
+	symbols []symbol
+}{
+	{"Смайлы", []symbol{
+		{"😀", "улыбка радость smile grin"}, {"😂", "смех слезы laugh joy"}, {"🙂", "улыбка smile"},
+		{"😉", "подмигивание wink"}, {"😊", "румянец радость blush"}, {"😍", "любовь влюблен love heart eyes"},
+		{"🤔", "думаю размышление think"}, {"😐", "нейтрально neutral"}, {"😴", "сон спать sleep"},
+		{"😎", "круто очки cool sunglasses"}, {"😢", "грусть слеза sad cry"}, {"😡", "злость гнев angry"},
+		{"😱", "ужас страх scream fear"}, {"🤯", "взрыв мозга mind blown"}, {"🥳", "праздник party"},
+		{"😅", "пот неловко sweat"}, {"🙃", "наоборот upside down"}, {"🤷", "не знаю пожимает плечами shrug"},
+	}},
+	{"Жесты", []symbol{
+		{"👍", "да хорошо нравится like thumbs up ok"}, {"👎", "нет плохо dislike thumbs down"},
+		{"👌", "окей отлично ok"}, {"👏", "аплодисменты браво clap"}, {"🙏", "спасибо пожалуйста please thanks pray"},
+		{"💪", "сила мышцы strong muscle"}, {"👋", "привет пока wave hello"}, {"✌️", "мир победа peace victory"},
+		{"🤝", "сделка рукопожатие handshake deal"}, {"👀", "смотреть глаза eyes look"},
+	}},
+	{"Отметки и статусы", []symbol{
+		{"✅", "готово сделано выполнено done check"}, {"❌", "нет отмена ошибка cross no"}, {"⚠️", "внимание предупреждение warning"},
+		{"❗", "важно восклицание important exclamation"}, {"❓", "вопрос question"}, {"⭐", "звезда избранное star"},
+		{"🔥", "огонь срочно горит fire hot"}, {"💡", "идея лампочка idea bulb"}, {"📌", "закрепить булавка pin"},
+		{"🚩", "флаг отметка flag"}, {"🎯", "цель мишень target goal"}, {"🚀", "запуск ракета launch rocket"},
+		{"⏳", "ожидание песочные часы wait hourglass"}, {"🔒", "замок секрет lock"}, {"🐛", "баг ошибка bug"},
+		{"🎉", "праздник поздравление party celebrate"}, {"❤️", "сердце любовь heart love"}, {"💯", "сто идеально hundred"},
+	}},
+	{"Предметы", []symbol{
+		{"📅", "календарь дата calendar date"}, {"⏰", "будильник напоминание alarm clock"}, {"📝", "заметка запись note memo"},
+		{"📎", "скрепка вложение clip attachment"}, {"📁", "папка folder"}, {"📚", "книги учеба books"},
+		{"💻", "компьютер ноутбук laptop computer"}, {"📱", "телефон phone mobile"}, {"✉️", "письмо почта mail letter"},
+		{"📞", "звонок телефон call"}, {"🔗", "ссылка link"}, {"🔑", "ключ пароль key password"},
+		{"💰", "деньги money"}, {"🛒", "покупки корзина shopping cart"}, {"🏠", "дом home house"},
+		{"✈️", "самолет путешествие plane travel"}, {"☕", "кофе coffee"}, {"🍕", "пицца еда pizza food"},
+	}},
+	{"Стрелки", []symbol{
+		{"→", "стрелка вправо arrow right"}, {"←", "стрелка влево arrow left"}, {"↑", "стрелка вверх arrow up"},
+		{"↓", "стрелка вниз arrow down"}, {"↔", "стрелка в обе стороны arrow both"}, {"⇒", "следует двойная стрелка implies"},
+		{"⇐", "двойная стрелка влево"}, {"⇔", "равносильно iff"}, {"↩", "возврат return"}, {"⟶", "длинная стрелка long arrow"},
+	}},
+	{"Математика", []symbol{
+		{"±", "плюс минус plus minus"}, {"×", "умножить times multiply"}, {"÷", "разделить divide"},
+		{"≈", "примерно approximately"}, {"≠", "не равно not equal"}, {"≤", "меньше или равно less equal"},
+		{"≥", "больше или равно greater equal"}, {"∞", "бесконечность infinity"}, {"√", "корень root sqrt"},
+		{"∑", "сумма sum sigma"}, {"π", "пи pi"}, {"°", "градус degree"}, {"½", "половина half"},
+		{"¼", "четверть quarter"}, {"‰", "промилле per mille"}, {"∆", "дельта разница delta"},
+	}},
+	{"Типографика", []symbol{
+		{"—", "тире длинное em dash"}, {"–", "короткое тире en dash"}, {"«", "кавычки елочки открывающая quote"},
+		{"»", "кавычки елочки закрывающая quote"}, {"„", "кавычки лапки quote"}, {"“", "кавычки quote"},
+		{"…", "многоточие ellipsis"}, {"•", "точка маркер bullet"}, {"§", "параграф section"},
+		{"№", "номер number"}, {"©", "копирайт copyright"}, {"®", "зарегистрировано registered"},
+		{"™", "товарный знак trademark"}, {"✓", "галочка check"}, {"✗", "крестик cross"},
+		{"€", "евро euro"}, {"₽", "рубль ruble"}, {"$", "доллар dollar"}, {"£", "фунт pound"},
+	}},
+}
+
+// matches сообщает, что символ подходит под строку поиска
+func (s symbol) matches(query string) bool {
+	return query == "" || s.char == query || strings.Contains(s.keywords, query)
+}
+
+// recentSymbols возвращает недавно вставленные символы, от последнего
+func recentSymbols() []string {
+	return fyne.CurrentApp().Preferences().StringList(recentSymbolsPreference)
+}
+
+// rememberSymbol добавляет символ в начало недавних
+func rememberSymbol(char string) {
+	recent := slices.DeleteFunc(recentSymbols(), func(s string) bool { return s == char })
+	recent = append([]string{char}, recent...)
+	if len(recent) > maxRecentSymbols {
+		recent = recent[:maxRecentSymbols]
+	}
+	fyne.CurrentApp().Preferences().SetStringList(recentSymbolsPreference, recent)
+}
+
+// showSymbolPicker показывает под anchor выбор эмодзи и символов с поиском и недавними;
+// выбранный символ вставляется в позицию курсора
+func (a *NoteApp) showSymbolPicker(anchor fyne.CanvasObject) {
+	var popUp *widget.PopUp
+	pick := func(char string) {
+		popUp.Hide()
+		rememberSymbol(char)
+		a.insertSymbol(char)
+	}
+	symbolButton := func(char string) fyne.CanvasObject {
+		return widget.NewButton(char, func() { pick(char) })
+	}
+	grid := func(chars []string) *fyne.Container {
+		box := container.NewGridWrap(fyne.NewSize(44, 36))
+		for _, char := range chars {
+			box.Add(symbolButton(char))
+		}
+		return box
+	}
+
+	groups := container.NewVBox()
+	fill := func(query string) {
+		query = strings.ToLower(strings.TrimSpace(query))
+		groups.RemoveAll()
+		if recent := recentSymbols(); query == "" && len(recent) > 0 {
+			groups.Add(boldLabel("Недавние"))
+			groups.Add(grid(recent))
+		}
+		for _, group := range symbolGroups {
+			var chars []string
+			for _, s := range group.symbols {
+				if s.matches(query) {
+					chars = append(chars, s.char)
+				}
+			}
+			if len(chars) > 0 {
+				groups.Add(boldLabel(group.name))
+				groups.Add(grid(chars))
+			}
+		}
+		if len(groups.Objects) == 0 {
+			groups.Add(widget.NewLabel("Ничего не найдено"))
+		}
+	}
+	fill("")
+
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Поиск: стрелка, готово, heart…")
+	searchEntry.OnChanged = fill
+	searchEntry.OnSubmitted = func(string) { // Enter вставляет первый найденный символ
+		for _, o := range groups.Objects {
+			if box, ok := o.(*fyne.Container); ok && len(box.Objects) > 0 {
+				box.Objects[0].(*widget.Button).OnTapped()
+				return
+			}
+		}
+	}
+	scroll := container.NewVScroll(groups)
+	scroll.SetMinSize(fyne.NewSize(420, 280))
+
+	popUp = widget.NewPopUp(container.NewBorder(searchEntry, nil, nil, nil, scroll), a.window.Canvas())
+	position := fyne.CurrentApp().Driver().AbsolutePositionForObject(anchor)
+	popUp.ShowAtPosition(position.AddXY(0, anchor.Size().Height))
+	a.window.Canvas().Focus(searchEntry)
+}
+
+// insertSymbol вставляет символ в позицию курсора; в построчном просмотре большой заметки
+// курсора нет, поэтому символ копируется в буфер обмена
+func (a *NoteApp) insertSymbol(char string) {
+	if a.largeView != nil {
+		a.window.Clipboard().SetContent(char)
+		dialog.ShowInformation("Символ", "Символ скопирован в буфер обмена: вставьте его в нужную строку.", a.window)
+		return
+	}
+	for _, r := range char {
+		a.contentEntry.TypedRune(r) // Заменяет выделение, как при вводе с клавиатуры
+	}
+	a.window.Canvas().Focus(a.contentEntry)
+}
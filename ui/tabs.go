@@ -0,0 +1,226 @@
+package ui
+
+import (
+	"image/color"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// openTabsPreference — ключ настроек со списком ID заметок, открытых во вкладках
+const openTabsPreference = "open_tabs"
+
+// noteTabs — панель вкладок: основной редактор и дополнительно открытые заметки
+type noteTabs struct {
+	app     *NoteApp
+	bar     *fyne.Container // Ярлыки вкладок
+	body    *fyne.Container // Содержимое активной вкладки
+	content fyne.CanvasObject
+
+	mainChip    *tabChip
+	mainContent fyne.CanvasObject
+	tabs        []*noteTab
+	current     *noteTab // nil — активен основной редактор
+}
+
+// noteTab — вкладка с отдельной заметкой
+type noteTab struct {
+	pane *notePane
+	chip *tabChip
+}
+
+// newNoteTabs создает панель вкладок с основным редактором в первой вкладке
+func newNoteTabs(a *NoteApp, mainContent fyne.CanvasObject) *noteTabs {
+	t := &noteTabs{app: a, mainContent: mainContent}
+	t.mainChip = newTabChip("Редактор", t.selectMain, nil)
+	t.mainChip.setSelected(true)
+	t.bar = container.NewHBox(t.mainChip)
+	t.body = container.NewStack(mainContent)
+	t.content = container.NewBorder(container.NewHScroll(t.bar), nil, nil, nil, t.body)
+	return t
+}
+
+// refreshMain обновляет ярлык основного редактора (заголовок и признак изменений)
+func (t *noteTabs) refreshMain() {
+	title := t.app.editedTitle()
+	if title == "" {
+		title = "Новая заметка"
+	}
+	t.mainChip.setTitle(truncateTitle(title, maxFavoriteTitle), t.app.hasUnsavedChanges())
+}
+
+// selectMain делает активным основной редактор
+func (t *noteTabs) selectMain() {
+	t.current = nil
+	t.show(t.mainContent)
+}
+
+// selectTab делает активной вкладку с заметкой
+func (t *noteTabs) selectTab(tab *noteTab) {
+	t.current = tab
+	t.show(tab.pane.content)
+}
+
+// show отображает содержимое и обновляет выделение ярлыков
+func (t *noteTabs) show(content fyne.CanvasObject) {
+	t.body.Objects = []fyne.CanvasObject{content}
+	t.body.Refresh()
+	t.mainChip.setSelected(t.current == nil)
+	for _, tab := range t.tabs {
+		tab.chip.setSelected(tab == t.current)
+	}
+}
+
+// open открывает заметку во вкладке или переключается на уже открытую
+func (t *noteTabs) open(noteID int) error {
+	for _, tab := range t.tabs {
+		if tab.pane.note.ID == noteID {
+			t.selectTab(tab)
+			return nil
+		}
+	}
+
+	note, err := t.app.store.GetNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	tab := &noteTab{}
+	tab.pane = newNotePane(t.app, t.app.window, *note, func() { t.remove(tab) })
+	tab.chip = newTabChip("", func() { t.selectTab(tab) }, tab.pane.close)
+	tab.pane.onDirtyChanged = func() {
+		tab.chip.setTitle(truncateTitle(tab.pane.note.Title, maxFavoriteTitle), tab.pane.dirty)
+	}
+	tab.pane.onDirtyChanged()
+
+	t.tabs = append(t.tabs, tab)
+	t.bar.Add(tab.chip)
+	t.selectTab(tab)
+	t.persist()
+	log.Printf("Заметка '%s' (ID: %d) открыта во вкладке", note.LogTitle(), note.ID)
+	return nil
+}
+
+// remove убирает вкладку после ее закрытия
+func (t *noteTabs) remove(tab *noteTab) {
+	for i, existing := range t.tabs {
+		if existing == tab {
+			t.tabs = append(t.tabs[:i], t.tabs[i+1:]...)
+			break
+		}
+	}
+	t.bar.Remove(tab.chip)
+	if t.current == tab {
+		t.selectMain()
+	}
+	t.persist()
+}
+
+// persist сохраняет список открытых вкладок для восстановления при следующем запуске
+func (t *noteTabs) persist() {
+	ids := make([]int, 0, len(t.tabs))
+	for _, tab := range t.tabs {
+		ids = append(ids, tab.pane.note.ID)
+	}
+	fyne.CurrentApp().Preferences().SetIntList(profileKey(openTabsPreference, t.app.profile), ids)
+}
+
+// restore открывает вкладки, сохраненные в прошлый раз; удаленные заметки пропускаются
+func (t *noteTabs) restore() {
+	ids := fyne.CurrentApp().Preferences().IntList(profileKey(openTabsPreference, t.app.profile))
+	for _, id := range ids {
+		if err := t.open(id); err != nil {
+			log.Printf("Не удалось восстановить вкладку заметки ID %d: %v", id, err)
+		}
+	}
+	t.selectMain()
+	t.persist()
+}
+
+// openInTab открывает выбранную заметку в отдельной вкладке
+func (a *NoteApp) openInTab() {
+	selectedNote := a.getSelectedNote()
+	if selectedNote == nil {
+		dialog.ShowInformation("Открыть во вкладке", "Выберите сохраненную заметку, чтобы открыть ее во вкладке.", a.window)
+		return
+	}
+	if err := a.noteTabs.open(selectedNote.ID); err != nil {
+		a.showStoreError("не удалось открыть заметку во вкладке", err)
+	}
+}
+
+// tabChip — ярлык вкладки: выбирается нажатием, закрывается кнопкой или средней кнопкой мыши
+type tabChip struct {
+	widget.BaseWidget
+	label       *widget.Label
+	closeButton *widget.Button
+	bg          *canvas.Rectangle
+	onSelect    func()
+	onClose     func()
+}
+
+// newTabChip создает ярлык; если onClose == nil, вкладку закрыть нельзя
+func newTabChip(title string, onSelect, onClose func()) *tabChip {
+	c := &tabChip{
+		label:    widget.NewLabel(title),
+		bg:       canvas.NewRectangle(color.Transparent),
+		onSelect: onSelect,
+		onClose:  onClose,
+	}
+	if onClose != nil {
+		c.closeButton = widget.NewButtonWithIcon("", theme.CancelIcon(), onClose)
+		c.closeButton.Importance = widget.LowImportance
+	}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// setTitle задает подпись ярлыка; измененные вкладки отмечаются точкой
+func (c *tabChip) setTitle(title string, dirty bool) {
+	if dirty {
+		title = "● " + title
+	}
+	c.label.SetText(title)
+}
+
+// setSelected подсвечивает активную вкладку
+func (c *tabChip) setSelected(selected bool) {
+	if selected {
+		c.bg.FillColor = theme.Color(theme.ColorNameSelection)
+		c.label.TextStyle.Bold = true
+	} else {
+		c.bg.FillColor = color.Transparent
+		c.label.TextStyle.Bold = false
+	}
+	c.bg.Refresh()
+	c.label.Refresh()
+}
+
+// Tapped делает вкладку активной
+func (c *tabChip) Tapped(*fyne.PointEvent) {
+	c.onSelect()
+}
+
+// MouseDown закрывает вкладку средней кнопкой мыши
+func (c *tabChip) MouseDown(e *desktop.MouseEvent) {
+	if e.Button == desktop.MouseButtonTertiary && c.onClose != nil {
+		c.onClose()
+	}
+}
+
+// MouseUp нужен для реализации desktop.Mouseable
+func (c *tabChip) MouseUp(*desktop.MouseEvent) {}
+
+// CreateRenderer создает отрисовщик ярлыка
+func (c *tabChip) CreateRenderer() fyne.WidgetRenderer {
+	row := container.NewHBox(c.label)
+	if c.closeButton != nil {
+		row.Add(c.closeButton)
+	}
+	return widget.NewSimpleRenderer(container.NewStack(c.bg, row))
+}
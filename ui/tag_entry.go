@@ -0,0 +1,237 @@
+package ui
+
+import (
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxVisibleSuggestions — сколько подсказок видно без прокрутки
+const maxVisibleSuggestions = 6
+
+// tagEntry — поле тегов через запятую, подсказывающее существующие теги для слова под курсором.
+// Стрелки выбирают подсказку, Enter или Tab подставляют ее, Escape закрывает список.
+type tagEntry struct {
+	widget.Entry
+	search func(prefix string) ([]string, error)
+
+	popup       *widget.PopUp
+	list        *tagSuggestionList
+	suggestions []string
+	request     int // Номер последнего запроса: ответы на устаревшие запросы не показываются
+}
+
+// newTagEntry создает поле тегов; search возвращает теги, начинающиеся с префикса
+func newTagEntry(search func(prefix string) ([]string, error)) *tagEntry {
+	e := &tagEntry{search: search}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// TypedRune вводит символ и обновляет подсказки
+func (e *tagEntry) TypedRune(r rune) {
+	e.Entry.TypedRune(r)
+	e.suggest()
+}
+
+// TypedKey обрабатывает клавишу; стрелка вниз открывает подсказки, удаление символов обновляет их
+func (e *tagEntry) TypedKey(key *fyne.KeyEvent) {
+	if key.Name == fyne.KeyDown {
+		e.suggest()
+		return
+	}
+	e.Entry.TypedKey(key)
+	switch key.Name {
+	case fyne.KeyBackspace, fyne.KeyDelete:
+		e.suggest()
+	case fyne.KeyLeft, fyne.KeyRight, fyne.KeyHome, fyne.KeyEnd:
+		e.hideSuggestions()
+	}
+}
+
+// tokenBounds возвращает границы тега под курсором (в символах) между соседними запятыми
+func (e *tagEntry) tokenBounds() (start, end int) {
+	text := []rune(e.Text)
+	cursor := min(max(e.CursorColumn, 0), len(text))
+	start = cursor
+	for start > 0 && text[start-1] != ',' {
+		start--
+	}
+	end = cursor
+	for end < len(text) && text[end] != ',' {
+		end++
+	}
+	return start, end
+}
+
+// currentPrefix возвращает начало тега под курсором, которое уже набрано
+func (e *tagEntry) currentPrefix() string {
+	start, _ := e.tokenBounds()
+	cursor := min(max(e.CursorColumn, 0), len([]rune(e.Text)))
+	return strings.TrimSpace(string([]rune(e.Text)[start:cursor]))
+}
+
+// suggest ищет теги для набранного префикса в фоне и показывает те, что еще не указаны в поле
+func (e *tagEntry) suggest() {
+	e.request++
+	prefix := e.currentPrefix()
+	if prefix == "" || e.search == nil {
+		e.hideSuggestions()
+		return
+	}
+	present := make(map[string]bool)
+	for _, tag := range parseTags(e.Text) {
+		present[strings.ToLower(tag)] = true
+	}
+
+	request := e.request
+	go func() {
+		tags, err := e.search(prefix)
+		if err != nil {
+			log.Printf("Ошибка при поиске тегов по префиксу %q: %v", prefix, err)
+			return
+		}
+		fyne.Do(func() {
+			if request != e.request {
+				return
+			}
+			var suggestions []string
+			for _, tag := range tags {
+				if !present[strings.ToLower(tag)] {
+					suggestions = append(suggestions, tag)
+				}
+			}
+			e.showSuggestions(suggestions)
+		})
+	}()
+}
+
+// showSuggestions показывает список подсказок под полем и передает ему клавиатуру
+func (e *tagEntry) showSuggestions(suggestions []string) {
+	e.suggestions = suggestions
+	if len(suggestions) == 0 {
+		e.hideSuggestions()
+		return
+	}
+	c := fyne.CurrentApp().Driver().CanvasForObject(e)
+	if c == nil {
+		return
+	}
+	if e.popup == nil {
+		e.list = newTagSuggestionList(e)
+		e.popup = widget.NewPopUp(e.list, c)
+	}
+	e.list.selectItem(0)
+
+	rowHeight := widget.NewLabel("").MinSize().Height + theme.Padding()
+	rows := min(len(suggestions), maxVisibleSuggestions)
+	e.popup.Resize(fyne.NewSize(e.Size().Width, float32(rows)*rowHeight))
+	e.popup.ShowAtRelativePosition(fyne.NewPos(0, e.Size().Height), e)
+	// Всплывающее окно забирает клавиатуру: список сам передает полю набираемые символы
+	c.Focus(e.list)
+}
+
+// hideSuggestions закрывает список подсказок
+func (e *tagEntry) hideSuggestions() {
+	if e.popup != nil && e.popup.Visible() {
+		e.popup.Hide()
+	}
+}
+
+// applySuggestion заменяет тег под курсором подсказкой и ставит курсор для следующего тега
+func (e *tagEntry) applySuggestion(tag string) {
+	e.hideSuggestions()
+	text := []rune(e.Text)
+	start, end := e.tokenBounds()
+	before := string(text[:start])
+	after := string(text[end:])
+	if start > 0 {
+		tag = " " + tag
+	}
+	cursor := len([]rune(before + tag))
+	if strings.TrimSpace(after) == "" {
+		after = ", "
+		cursor += 2
+	}
+	e.SetText(before + tag + after)
+	e.CursorColumn = cursor
+	e.Refresh()
+	if c := fyne.CurrentApp().Driver().CanvasForObject(e); c != nil {
+		c.Focus(e)
+	}
+}
+
+// tagSuggestionList — список подсказок тегов. Пока он открыт, у него фокус клавиатуры:
+// стрелки и Enter обрабатывает сам, остальной ввод передает полю.
+type tagSuggestionList struct {
+	widget.List
+	entry    *tagEntry
+	selected int // Подсказка, выбранная стрелками; выделяется цветом
+}
+
+// newTagSuggestionList создает список подсказок поля entry
+func newTagSuggestionList(entry *tagEntry) *tagSuggestionList {
+	l := &tagSuggestionList{entry: entry}
+	l.Length = func() int { return len(entry.suggestions) }
+	l.CreateItem = func() fyne.CanvasObject { return widget.NewLabel("") }
+	l.UpdateItem = func(id widget.ListItemID, item fyne.CanvasObject) {
+		label := item.(*widget.Label)
+		label.Importance = widget.MediumImportance
+		if id == l.selected {
+			label.Importance = widget.HighImportance
+		}
+		label.SetText(entry.suggestions[id])
+	}
+	l.OnSelected = func(id widget.ListItemID) { // Щелчок по подсказке
+		l.UnselectAll()
+		if id < len(entry.suggestions) {
+			entry.applySuggestion(entry.suggestions[id])
+		}
+	}
+	l.ExtendBaseWidget(l)
+	return l
+}
+
+// selectItem выделяет подсказку id, не подставляя ее
+func (l *tagSuggestionList) selectItem(id int) {
+	l.selected = id
+	l.Refresh()
+	l.ScrollTo(id)
+}
+
+// TypedKey переключает подсказки стрелками, подставляет по Enter или Tab, закрывает по Escape
+func (l *tagSuggestionList) TypedKey(key *fyne.KeyEvent) {
+	n := len(l.entry.suggestions)
+	switch key.Name {
+	case fyne.KeyDown:
+		if n > 0 {
+			l.selectItem((l.selected + 1) % n)
+		}
+	case fyne.KeyUp:
+		if n > 0 {
+			l.selectItem((l.selected - 1 + n) % n)
+		}
+	case fyne.KeyReturn, fyne.KeyEnter, fyne.KeyTab:
+		if l.selected < n {
+			l.entry.applySuggestion(l.entry.suggestions[l.selected])
+		}
+	case fyne.KeyEscape:
+		l.entry.hideSuggestions()
+	default:
+		l.entry.TypedKey(key)
+	}
+}
+
+// TypedRune передает набираемый символ полю тегов
+func (l *tagSuggestionList) TypedRune(r rune) {
+	l.entry.TypedRune(r)
+}
+
+// TypedShortcut передает полю тегов сочетания клавиш (например, вставку)
+func (l *tagSuggestionList) TypedShortcut(shortcut fyne.Shortcut) {
+	l.entry.TypedShortcut(shortcut)
+	l.entry.suggest()
+}
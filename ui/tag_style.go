@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// maxTagIconLen — сколько символов может занимать значок тега (как в столбце tags.icon)
+const maxTagIconLen = 16
+
+// tagChipAlpha — прозрачность фона плашки тега, чтобы текст читался в светлой и темной темах
+const tagChipAlpha = 0x70
+
+// tagPalette — цвета, которые можно выбрать для тега
+var tagPalette = []struct {
+	name string
+	hex  string
+}{
+	{"Без цвета", ""},
+	{"Красный", "#e53935"},
+	{"Оранжевый", "#fb8c00"},
+	{"Желтый", "#fdd835"},
+	{"Зеленый", "#43a047"},
+	{"Бирюзовый", "#00897b"},
+	{"Синий", "#1e88e5"},
+	{"Фиолетовый", "#8e24aa"},
+	{"Розовый", "#d81b60"},
+	{"Серый", "#757575"},
+}
+
+// parseTagColor разбирает цвет "#rrggbb"
+func parseTagColor(hex string) (color.NRGBA, bool) {
+	var c color.NRGBA
+	if _, err := fmt.Sscanf(hex, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil || len(hex) != 7 {
+		return c, false
+	}
+	c.A = 0xff
+	return c, true
+}
+
+// tagChipFill возвращает полупрозрачный фон плашки тега или прозрачный, если цвет не задан
+func tagChipFill(style models.TagStyle) color.Color {
+	c, ok := parseTagColor(style.Color)
+	if !ok {
+		return color.Transparent
+	}
+	c.A = tagChipAlpha
+	return c
+}
+
+// tagLabel возвращает подпись тега со значком, если он задан
+func tagLabel(name string, style models.TagStyle) string {
+	if style.Icon == "" {
+		return name
+	}
+	return style.Icon + " " + name
+}
+
+// newTagChip создает цветную плашку тега для строки списка заметок
+func newTagChip(tag string, style models.TagStyle) fyne.CanvasObject {
+	bg := canvas.NewRectangle(tagChipFill(style))
+	bg.CornerRadius = theme.InputRadiusSize()
+	if style.Color == "" { // Только значок: обводка, чтобы плашка не сливалась с фоном
+		bg.StrokeColor = theme.Color(theme.ColorNameSeparator)
+		bg.StrokeWidth = 1
+	}
+	text := canvas.NewText(tagLabel(tag, style), theme.Color(theme.ColorNameForeground))
+	text.TextSize = theme.CaptionTextSize()
+	return container.NewCenter(container.NewStack(bg, container.New(layout.NewCustomPaddedLayout(2, 2, 6, 6), text)))
+}
+
+// tagStyleDialog предлагает выбрать цвет и значок тега
+func (a *NoteApp) tagStyleDialog(tag string) {
+	style := a.tagStyles[tag]
+
+	names := make([]string, len(tagPalette))
+	selected := tagPalette[0].name
+	for i, p := range tagPalette {
+		names[i] = p.name
+		if p.hex == style.Color {
+			selected = p.name
+		}
+	}
+	colorSelect := widget.NewSelect(names, nil)
+	colorSelect.SetSelected(selected)
+
+	iconEntry := widget.NewEntry()
+	iconEntry.SetPlaceHolder("Например: 📌")
+	iconEntry.SetText(style.Icon)
+	iconEntry.Validator = func(s string) error {
+		if utf8.RuneCountInString(s) > maxTagIconLen {
+			return fmt.Errorf("не длиннее %d символов", maxTagIconLen)
+		}
+		return nil
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Цвет", colorSelect),
+		widget.NewFormItem("Значок", iconEntry),
+	}
+	dialog.ShowForm("Оформление тега «"+tag+"»", "Сохранить", "Отмена", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		newStyle := models.TagStyle{Icon: iconEntry.Text}
+		for _, p := range tagPalette {
+			if p.name == colorSelect.Selected {
+				newStyle.Color = p.hex
+			}
+		}
+		if err := a.store.SetTagStyle(tag, newStyle); err != nil {
+			a.showStoreError("не удалось сохранить оформление тега", err)
+			log.Printf("Ошибка при сохранении оформления тега «%s»: %v", tag, err)
+			return
+		}
+		log.Printf("Оформление тега «%s» изменено: цвет %q, значок %q", tag, newStyle.Color, newStyle.Icon)
+		if newStyle.IsZero() {
+			delete(a.tagStyles, tag)
+		} else {
+			a.tagStyles[tag] = newStyle
+		}
+		a.refreshTagTree()
+		a.refreshList()
+	}, a.window)
+}
+
+// loadTagStyles загружает оформление тегов; без него теги просто показываются без цвета
+func (a *NoteApp) loadTagStyles() {
+	styles, err := a.store.GetTagStyles()
+	if err != nil {
+		log.Printf("Ошибка при загрузке оформления тегов: %v", err)
+		return
+	}
+	a.tagStyles = styles
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+	"GNote/nlp"
+)
+
+// maxSuggestedTags — сколько тегов предлагается после сохранения заметки
+const maxSuggestedTags = 5
+
+// maxKeywordTerms — сколько характерных слов заметки учитывается при подборе тегов
+const maxKeywordTerms = 20
+
+// suggestTags предлагает теги для сохраненной заметки по ее ключевым словам и тегам других заметок.
+// Нажатие на предложенный тег добавляет его в поле тегов.
+func (a *NoteApp) suggestTags(note models.Note) {
+	text := note.Title + "\n" + note.Content
+	var terms []nlp.Term
+	if a.related != nil {
+		terms = a.related.Keywords(text, maxKeywordTerms)
+	} else { // Без индекса частоты по другим заметкам неизвестны, остается частота в самой заметке
+		terms = nlp.TopTerms(text, func(string) int { return 0 }, 0, maxKeywordTerms)
+	}
+	used := make(map[string]int)
+	for _, n := range a.allNotes {
+		for _, tag := range n.Tags {
+			used[tag]++
+		}
+	}
+
+	a.suggestedBox.RemoveAll()
+	a.suggestedFor = note.ID
+	for _, tag := range nlp.SuggestTags(terms, used, note.Tags, maxSuggestedTags) {
+		var button *widget.Button
+		button = widget.NewButton("+ "+tag, func() {
+			a.acceptSuggestedTag(tag)
+			a.suggestedBox.Remove(button)
+			if len(a.suggestedBox.Objects) == 0 {
+				a.tagSuggestions.Hide()
+			}
+		})
+		button.Importance = widget.LowImportance
+		a.suggestedBox.Add(button)
+	}
+	if len(a.suggestedBox.Objects) == 0 {
+		a.tagSuggestions.Hide()
+	} else {
+		a.tagSuggestions.Show()
+	}
+}
+
+// acceptSuggestedTag дописывает тег в поле тегов; заметка становится измененной
+func (a *NoteApp) acceptSuggestedTag(tag string) {
+	current := strings.TrimRight(strings.TrimSpace(a.tagsEntry.Text), ",")
+	if current == "" {
+		a.tagsEntry.SetText(tag)
+	} else {
+		a.tagsEntry.SetText(current + ", " + tag)
+	}
+	a.setUnsavedChanges(true)
+}
+
+// clearTagSuggestions убирает предложенные теги, например при переходе к другой заметке
+func (a *NoteApp) clearTagSuggestions() {
+	a.suggestedFor = 0
+	a.suggestedBox.RemoveAll()
+	a.tagSuggestions.Hide()
+}
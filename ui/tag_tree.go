@@ -0,0 +1,329 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/models"
+)
+
+// TagTreeView — сворачиваемое дерево тегов: "работа/проекты" показывается внутри "работа".
+// Выбор тега фильтрует заметки по нему и вложенным тегам; через меню правой кнопки теги
+// можно сочетать: добавить к фильтру (нужны все) или исключить (заметки с ними скрываются).
+type TagTreeView struct {
+	tree         *widget.Tree
+	children     map[string][]string        // Вложенные теги по полному пути родителя; "" — верхний уровень
+	counts       map[string]int             // Сколько заметок отмечено тегом или вложенными в него
+	styles       map[string]models.TagStyle // Цвет и значок тегов
+	selected     string                     // Выбранный тег ("" — без фильтра)
+	included     []string                   // Теги, которые должны быть у заметки (с вложенными)
+	excluded     []string                   // Теги, заметки с которыми скрываются (с вложенными)
+	restoring    bool                       // Выделение восстанавливается после обновления и не меняет фильтр
+	renameButton *widget.Button
+	styleButton  *widget.Button
+
+	content fyne.CanvasObject
+
+	OnFilterChanged func(included, excluded []string) // Изменился фильтр по тегам
+	OnRename        func(tag string)
+	OnStyle         func(tag string) // Изменить цвет и значок тега
+}
+
+// NewTagTreeView создает пустое дерево тегов; заполняется через update
+func NewTagTreeView() *TagTreeView {
+	v := &TagTreeView{children: make(map[string][]string), counts: make(map[string]int)}
+	v.tree = widget.NewTree(
+		func(id widget.TreeNodeID) []widget.TreeNodeID { return v.children[id] },
+		func(id widget.TreeNodeID) bool { return id == "" || len(v.children[id]) > 0 },
+		func(bool) fyne.CanvasObject { return newTagTreeRow(v) },
+		func(id widget.TreeNodeID, _ bool, o fyne.CanvasObject) {
+			row := o.(*tagTreeRow)
+			style := v.styles[id]
+			row.tag = id
+			row.bg.FillColor = tagChipFill(style)
+			row.bg.Refresh()
+			name := tagLabel(id[strings.LastIndex(id, models.TagSeparator)+1:], style)
+			row.label.TextStyle.Bold = slices.Contains(v.included, id)
+			switch {
+			case row.label.TextStyle.Bold:
+				row.label.Importance = widget.HighImportance
+				name = "+ " + name
+			case slices.Contains(v.excluded, id):
+				row.label.Importance = widget.DangerImportance
+				name = "− " + name
+			default:
+				row.label.Importance = widget.MediumImportance
+			}
+			row.label.SetText(name)
+			row.count.SetText(fmt.Sprint(v.counts[id]))
+		},
+	)
+	v.tree.OnSelected = func(id widget.TreeNodeID) {
+		v.selected = id
+		v.renameButton.Enable()
+		v.styleButton.Enable()
+		if !v.restoring {
+			v.setFilter([]string{id}, without(v.excluded, id))
+		}
+	}
+
+	v.renameButton = widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() {
+		if v.selected != "" && v.OnRename != nil {
+			v.OnRename(v.selected)
+		}
+	})
+	v.renameButton.Disable()
+	v.styleButton = widget.NewButtonWithIcon("", theme.ColorPaletteIcon(), func() {
+		if v.selected != "" && v.OnStyle != nil {
+			v.OnStyle(v.selected)
+		}
+	})
+	v.styleButton.Disable()
+	clearButton := widget.NewButtonWithIcon("", theme.CancelIcon(), v.clear)
+
+	v.content = container.NewBorder(
+		container.NewHBox(widget.NewLabel("Теги"), layout.NewSpacer(), v.styleButton, v.renameButton, clearButton),
+		nil, nil, nil,
+		v.tree,
+	)
+	return v
+}
+
+// unselect снимает выделение в дереве, не меняя фильтр
+func (v *TagTreeView) unselect() {
+	v.tree.UnselectAll()
+	v.selected = ""
+	v.renameButton.Disable()
+	v.styleButton.Disable()
+}
+
+// clear снимает выбор тега и сбрасывает фильтр по тегам
+func (v *TagTreeView) clear() {
+	v.unselect()
+	if len(v.included) == 0 && len(v.excluded) == 0 {
+		return
+	}
+	v.setFilter(nil, nil)
+}
+
+// setFilter задает включенные и исключенные теги и сообщает об изменении фильтра
+func (v *TagTreeView) setFilter(included, excluded []string) {
+	v.included, v.excluded = included, excluded
+	v.tree.Refresh()
+	if v.OnFilterChanged != nil {
+		v.OnFilterChanged(slices.Clone(included), slices.Clone(excluded))
+	}
+}
+
+// include добавляет тег к фильтру: заметка должна быть отмечена и им, и уже выбранными
+func (v *TagTreeView) include(tag string) {
+	v.setFilter(append(slices.Clone(v.included), tag), without(v.excluded, tag))
+}
+
+// exclude скрывает заметки с тегом или вложенными в него
+func (v *TagTreeView) exclude(tag string) {
+	if v.selected == tag {
+		v.unselect() // Выделение означает «только этот тег» и больше не соответствует фильтру
+	}
+	v.setFilter(without(v.included, tag), append(slices.Clone(v.excluded), tag))
+}
+
+// remove убирает тег из фильтра
+func (v *TagTreeView) remove(tag string) {
+	if v.selected == tag {
+		v.unselect()
+	}
+	v.setFilter(without(v.included, tag), without(v.excluded, tag))
+}
+
+// without возвращает копию tags без тега tag
+func without(tags []string, tag string) []string {
+	return slices.DeleteFunc(slices.Clone(tags), func(t string) bool { return t == tag })
+}
+
+// renameTag переносит выбор и фильтр с тега oldName на newName вместе с вложенными
+func (v *TagTreeView) renameTag(oldName, newName string) {
+	v.selected = models.RenameTagPrefix(v.selected, oldName, newName)
+	rename := func(tags []string) []string {
+		renamed := make([]string, len(tags))
+		for i, tag := range tags {
+			renamed[i] = models.RenameTagPrefix(tag, oldName, newName)
+		}
+		return renamed
+	}
+	v.setFilter(rename(v.included), rename(v.excluded))
+}
+
+// showMenu показывает меню тега tag в точке pos: сочетание с фильтром, переименование и оформление
+func (v *TagTreeView) showMenu(tag string, pos fyne.Position) {
+	c := fyne.CurrentApp().Driver().CanvasForObject(v.tree)
+	if c == nil {
+		return
+	}
+	included, excluded := slices.Contains(v.included, tag), slices.Contains(v.excluded, tag)
+	onlyItem := fyne.NewMenuItem("Только этот тег", func() {
+		if v.selected == tag {
+			v.setFilter([]string{tag}, without(v.excluded, tag))
+			return
+		}
+		v.tree.Select(tag)
+	})
+	includeItem := fyne.NewMenuItem("Добавить к фильтру", func() { v.include(tag) })
+	includeItem.Disabled = included
+	excludeItem := fyne.NewMenuItem("Исключить", func() { v.exclude(tag) })
+	excludeItem.Disabled = excluded
+	removeItem := fyne.NewMenuItem("Убрать из фильтра", func() { v.remove(tag) })
+	removeItem.Disabled = !included && !excluded
+	menu := fyne.NewMenu("", onlyItem, includeItem, excludeItem, removeItem, fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Переименовать...", func() {
+			if v.OnRename != nil {
+				v.OnRename(tag)
+			}
+		}),
+		fyne.NewMenuItem("Цвет и значок...", func() {
+			if v.OnStyle != nil {
+				v.OnStyle(tag)
+			}
+		}),
+	)
+	widget.ShowPopUpMenuAtPosition(menu, c, pos)
+}
+
+// update перестраивает дерево по тегам заметок notes с оформлением styles,
+// сохраняя выбранный тег и фильтр по тегам, которые остались
+func (v *TagTreeView) update(notes []models.Note, styles map[string]models.TagStyle) {
+	counts := make(map[string]int)
+	for _, note := range notes {
+		seen := make(map[string]bool) // Заметка с "а/б" и "а/в" считается в "а" один раз
+		for _, tag := range note.Tags {
+			for _, path := range models.TagAncestors(tag) {
+				if !seen[path] {
+					seen[path] = true
+					counts[path]++
+				}
+			}
+		}
+	}
+	children := make(map[string][]string)
+	for path := range counts {
+		parent := ""
+		if i := strings.LastIndex(path, models.TagSeparator); i != -1 {
+			parent = path[:i]
+		}
+		children[parent] = append(children[parent], path)
+	}
+	for _, paths := range children {
+		sort.Slice(paths, func(i, j int) bool { return strings.ToLower(paths[i]) < strings.ToLower(paths[j]) })
+	}
+	v.counts = counts
+	v.children = children
+	v.styles = styles
+	v.tree.Refresh()
+
+	// Теги, которых больше нет ни у одной заметки, убираются из фильтра
+	gone := func(tag string) bool { return counts[tag] == 0 }
+	if slices.ContainsFunc(v.included, gone) || slices.ContainsFunc(v.excluded, gone) {
+		v.setFilter(slices.DeleteFunc(slices.Clone(v.included), gone), slices.DeleteFunc(slices.Clone(v.excluded), gone))
+	}
+	switch {
+	case v.selected == "":
+	case gone(v.selected):
+		v.unselect()
+	default:
+		for _, path := range models.TagAncestors(v.selected) {
+			if path != v.selected {
+				v.tree.OpenBranch(path)
+			}
+		}
+		v.restoring = true
+		v.tree.Select(v.selected)
+		v.restoring = false
+	}
+}
+
+// tagTreeRow — строка дерева тегов: подпись на цветной плашке и число заметок справа.
+// Правая кнопка открывает меню тега; обычное нажатие обрабатывает само дерево.
+type tagTreeRow struct {
+	widget.BaseWidget
+
+	view  *TagTreeView
+	tag   string
+	bg    *canvas.Rectangle
+	label *widget.Label
+	count *widget.Label
+}
+
+// newTagTreeRow создает пустую строку дерева view
+func newTagTreeRow(view *TagTreeView) *tagTreeRow {
+	r := &tagTreeRow{view: view, bg: canvas.NewRectangle(color.Transparent), label: widget.NewLabel("тег"), count: widget.NewLabel("0")}
+	r.bg.CornerRadius = theme.InputRadiusSize()
+	r.label.Truncation = fyne.TextTruncateEllipsis
+	r.count.Importance = widget.LowImportance
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+// CreateRenderer создает отрисовку строки
+func (r *tagTreeRow) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewBorder(nil, nil, nil, r.count, container.NewStack(r.bg, r.label)))
+}
+
+// TappedSecondary открывает меню тега
+func (r *tagTreeRow) TappedSecondary(e *fyne.PointEvent) {
+	if r.tag != "" {
+		r.view.showMenu(r.tag, e.AbsolutePosition)
+	}
+}
+
+// refreshTagTree перестраивает дерево тегов по загруженным заметкам
+func (a *NoteApp) refreshTagTree() {
+	a.tagTree.update(a.allNotes, a.tagStyles)
+}
+
+// renameTagDialog запрашивает новое имя тега и переименовывает его вместе с вложенными
+func (a *NoteApp) renameTagDialog(tag string) {
+	if a.hasUnsavedChanges() {
+		dialog.ShowInformation("Переименование тега", "Сначала сохраните изменения в открытой заметке.", a.window)
+		return
+	}
+	entry := widget.NewEntry()
+	entry.SetText(tag)
+	item := widget.NewFormItem("Новое имя", entry)
+	item.HintText = "Вложенные теги переименуются вместе с ним"
+	dialog.ShowForm("Переименовать тег «"+tag+"»", "Переименовать", "Отмена", []*widget.FormItem{item}, func(confirmed bool) {
+		newName := models.NormalizeTag(entry.Text)
+		if !confirmed || newName == "" || newName == tag {
+			return
+		}
+		if _, err := a.store.RenameTag(tag, newName); err != nil {
+			a.showStoreError("не удалось переименовать тег", err)
+			log.Printf("Ошибка при переименовании тега «%s» в «%s»: %v", tag, newName, err)
+			return
+		}
+		log.Printf("Тег «%s» переименован в «%s»", tag, newName)
+		a.loadTagStyles() // Оформление переходит вместе с тегами
+
+		// Фильтр и поле тегов открытой заметки следуют за новым именем; список обновится по событиям
+		a.tagTree.renameTag(tag, newName)
+		if selected := a.getSelectedNote(); selected != nil {
+			tags := make([]string, len(selected.Tags))
+			for i, t := range selected.Tags {
+				tags[i] = models.RenameTagPrefix(t, tag, newName)
+			}
+			a.tagsEntry.SetText(strings.Join(tags, ", "))
+			a.setUnsavedChanges(false)
+		}
+	}, a.window)
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"image/color"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// compactSizes — размеры плотного режима: меньше отступов, межстрочного интервала и значков,
+// поэтому строки списка и поля редактора становятся ниже
+var compactSizes = map[fyne.ThemeSizeName]float32{
+	theme.SizeNamePadding:      2,
+	theme.SizeNameInnerPadding: 4,
+	theme.SizeNameLineSpacing:  2,
+	theme.SizeNameText:         13,
+	theme.SizeNameInlineIcon:   16,
+	theme.SizeNameScrollBar:    8,
+}
+
+// touchSizes — размеры на сенсорных экранах: отступы и значки крупнее, чтобы в кнопки было легко попасть пальцем
+var touchSizes = map[fyne.ThemeSizeName]float32{
+	theme.SizeNamePadding:      6,
+	theme.SizeNameInnerPadding: 12,
+	theme.SizeNameInlineIcon:   24,
+	theme.SizeNameScrollBar:    16,
+}
+
+// appTheme — стандартная тема с принудительным светлым или темным вариантом и плотностью интерфейса
+type appTheme struct {
+	fyne.Theme
+	variant *fyne.ThemeVariant // nil — вариант как в системе
+	compact bool
+	touch   bool // Сенсорный экран: плотность не учитывается, элементы крупнее
+}
+
+// Color возвращает цвет стандартной темы для выбранного варианта
+func (t appTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	if t.variant != nil {
+		variant = *t.variant
+	}
+	return t.Theme.Color(name, variant)
+}
+
+// Size возвращает размер стандартной темы: на сенсорном экране — увеличенный, в плотном режиме — уменьшенный
+func (t appTheme) Size(name fyne.ThemeSizeName) float32 {
+	if size, ok := touchSizes[name]; ok && t.touch {
+		return size
+	}
+	if size, ok := compactSizes[name]; ok && t.compact && !t.touch {
+		return size
+	}
+	return t.Theme.Size(name)
+}
+
+// ApplyTheme применяет тему по названию: "light", "dark" или "system" (как в системе),
+// и плотность интерфейса: "comfortable" (обычная) или "compact". На телефоне элементы всегда крупные.
+func ApplyTheme(a fyne.App, name, density string) {
+	t := appTheme{Theme: theme.DefaultTheme(), compact: density == "compact", touch: a.Driver().Device().IsMobile()}
+	switch name {
+	case "", "system":
+	case "light":
+		variant := theme.VariantLight
+		t.variant = &variant
+	case "dark":
+		variant := theme.VariantDark
+		t.variant = &variant
+	default:
+		log.Printf("Неизвестная тема %q, используется системная", name)
+	}
+	a.Settings().SetTheme(t)
+}
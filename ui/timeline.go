@@ -0,0 +1,201 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"GNote/journal"
+	"GNote/models"
+)
+
+const (
+	timelinePreference        = "timeline_view"     // Показывать хронологию вместо списка
+	timelineByMonthPreference = "timeline_by_month" // Группировать хронологию по месяцам, а не по дням
+)
+
+// timelineGroupOptions — варианты группировки хронологии
+var timelineGroupOptions = []string{"По дням", "По месяцам"}
+
+// monthNames — названия месяцев для заголовков хронологии
+var monthNames = [...]string{"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь",
+	"Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь"}
+
+// timelineRow — строка хронологии: заголовок дня или месяца либо заметка
+type timelineRow struct {
+	header string    // Заголовок группы; пусто — строка заметки
+	start  time.Time // Начало дня или месяца группы в местном времени
+	note   int       // Индекс заметки в filteredNotes
+}
+
+// timelineView — хронология: отфильтрованные заметки по дате создания (или изменения, если список
+// отсортирован по ней), сгруппированные по дням или месяцам, и переход к выбранной дате
+type timelineView struct {
+	list     *NoteListView
+	rows     []timelineRow
+	byMonth  bool
+	widget   *widget.List
+	jumpDate *datePicker
+
+	content fyne.CanvasObject
+}
+
+// newTimelineView создает хронологию заметок списка v
+func newTimelineView(v *NoteListView) *timelineView {
+	t := &timelineView{list: v, byMonth: fyne.CurrentApp().Preferences().Bool(timelineByMonthPreference)}
+	t.widget = widget.NewList(
+		func() int { return len(t.rows) },
+		func() fyne.CanvasObject {
+			return container.NewStack(boldLabel("заголовок"), newNoteRow(func() bool { return v.twoLineTitles }))
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			box := o.(*fyne.Container)
+			header, row := box.Objects[0].(*widget.Label), box.Objects[1].(*noteRow)
+			if r := t.rows[i]; r.header != "" {
+				header.SetText(r.header)
+				header.Show()
+				row.Hide()
+			} else {
+				header.Hide()
+				row.Show()
+				v.updateNoteRow(r.note, row)
+			}
+		},
+	)
+	t.widget.OnSelected = func(i widget.ListItemID) {
+		t.widget.Unselect(i) // Выделение рисуется по выбранной заметке, как в списке
+		if r := t.rows[i]; r.header == "" {
+			v.noteList.Select(r.note)
+		}
+	}
+
+	groupSelect := widget.NewSelect(timelineGroupOptions, nil)
+	if t.byMonth {
+		groupSelect.SetSelectedIndex(1)
+	} else {
+		groupSelect.SetSelectedIndex(0)
+	}
+	groupSelect.OnChanged = func(string) {
+		t.byMonth = groupSelect.SelectedIndex() == 1
+		fyne.CurrentApp().Preferences().SetBool(timelineByMonthPreference, t.byMonth)
+		t.update()
+	}
+	t.jumpDate = newDatePicker("Перейти к")
+	t.jumpDate.OnChanged = t.jumpTo
+
+	t.content = container.NewBorder(container.NewGridWithColumns(2, groupSelect, t.jumpDate.button), nil, nil, nil, t.widget)
+	return t
+}
+
+// byUpdate сообщает, что хронология строится по дате изменения: так отсортирован список
+func (t *timelineView) byUpdate() bool {
+	return strings.Contains(t.list.vm.sortCriteria, "обновления")
+}
+
+// oldestFirst сообщает, что хронология идет от старых заметок к новым, как выбрано в сортировке
+func (t *timelineView) oldestFirst() bool {
+	return strings.HasSuffix(t.list.vm.sortCriteria, "(старые)")
+}
+
+// noteTime возвращает дату заметки, по которой она стоит в хронологии, в местном времени
+func (t *timelineView) noteTime(note models.Note) time.Time {
+	if t.byUpdate() {
+		return note.UpdatedAt.In(time.Local)
+	}
+	return note.CreatedAt.In(time.Local)
+}
+
+// groupStart возвращает начало дня или месяца, в который попадает at
+func (t *timelineView) groupStart(at time.Time) time.Time {
+	if t.byMonth {
+		return time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.Local)
+	}
+	return time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// groupHeader возвращает заголовок группы, начинающейся в start, с числом заметок в ней
+func (t *timelineView) groupHeader(start time.Time, count int) string {
+	var title string
+	switch {
+	case t.byMonth:
+		title = fmt.Sprintf("%s %d", monthNames[start.Month()-1], start.Year())
+	case start.Equal(t.groupStart(time.Now())):
+		title = "Сегодня, " + journal.FormatDate(start)
+	default:
+		title = journal.FormatDate(start)
+	}
+	return fmt.Sprintf("%s • %d", title, count)
+}
+
+// update перестраивает хронологию по отфильтрованным заметкам
+func (t *timelineView) update() {
+	notes := t.list.vm.filteredNotes
+	order := make([]int, len(notes))
+	for i := range order {
+		order[i] = i
+	}
+	oldestFirst := t.oldestFirst()
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := t.noteTime(notes[order[i]]), t.noteTime(notes[order[j]])
+		if oldestFirst {
+			return a.Before(b)
+		}
+		return a.After(b)
+	})
+
+	t.rows = t.rows[:0]
+	header := -1 // Строка заголовка текущей группы
+	for _, i := range order {
+		start := t.groupStart(t.noteTime(notes[i]))
+		if header == -1 || !t.rows[header].start.Equal(start) {
+			header = len(t.rows)
+			t.rows = append(t.rows, timelineRow{start: start})
+		}
+		t.rows = append(t.rows, timelineRow{note: i})
+	}
+	// Заголовки ниже строк заметок; высота задается каждой строке, так как строки меняются местами
+	headerHeight := boldLabel("").MinSize().Height
+	noteHeight := t.widget.CreateItem().MinSize().Height
+	for i, r := range t.rows {
+		if r.start.IsZero() {
+			t.widget.SetItemHeight(i, noteHeight)
+			continue
+		}
+		count := 0
+		for _, next := range t.rows[i+1:] {
+			if !next.start.IsZero() {
+				break
+			}
+			count++
+		}
+		t.rows[i].header = t.groupHeader(r.start, count)
+		t.widget.SetItemHeight(i, headerHeight)
+	}
+	t.widget.Refresh()
+}
+
+// jumpTo прокручивает хронологию к группе выбранной даты, а если заметок в тот день (месяц) нет —
+// к ближайшей более ранней группе (при порядке от старых к новым — к ближайшей более поздней)
+func (t *timelineView) jumpTo(date time.Time) {
+	target := t.groupStart(date)
+	oldestFirst := t.oldestFirst()
+	last := -1
+	for i, r := range t.rows {
+		if r.header == "" {
+			continue
+		}
+		last = i
+		if (!oldestFirst && !r.start.After(target)) || (oldestFirst && !r.start.Before(target)) {
+			t.widget.ScrollTo(i)
+			return
+		}
+	}
+	if last != -1 {
+		t.widget.ScrollTo(last) // Все заметки новее (старше) выбранной даты
+	}
+}
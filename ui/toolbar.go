@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const toolbarPreference = "toolbar_buttons"
+
+// defaultToolbar — кнопки панели действий по умолчанию: действия с заметкой
+var defaultToolbar = []string{"new", "save", "delete", "journal", "side", "tab", "summary"}
+
+// toolbarButton — кнопка, которую можно показать на панели действий
+type toolbarButton struct {
+	id     string // Ключ кнопки в сохраненных настройках
+	button *widget.Button
+}
+
+// toolbar — панель кнопок действий под редактором. Набор и порядок кнопок выбирает пользователь.
+type toolbar struct {
+	buttons []toolbarButton // Все доступные кнопки в порядке списка настройки
+	box     *fyne.Container
+}
+
+// makeToolbar создает все кнопки действий и показывает выбранные в прошлый раз
+func (a *NoteApp) makeToolbar() *fyne.Container {
+	button := func(id, label string, icon fyne.Resource, action func()) toolbarButton {
+		return toolbarButton{id: id, button: widget.NewButtonWithIcon(label, icon, action)}
+	}
+	a.toolbar = &toolbar{
+		buttons: []toolbarButton{
+			button("new", "Новая заметка", theme.ContentAddIcon(), a.newNote),
+			{id: "save", button: a.saveButton},
+			{id: "delete", button: a.deleteButton},
+			button("journal", "Дневник", theme.CalendarIcon(), a.openJournal),
+			button("side", "Открыть рядом", theme.ViewRestoreIcon(), a.openSidePane),
+			button("tab", "Во вкладке", theme.ContentCopyIcon(), a.openInTab),
+			button("window", "В новом окне", theme.ViewFullScreenIcon(), a.openInWindow),
+			button("sticky", "Стикер", theme.DocumentIcon(), a.openSticky),
+			button("summary", "Кратко", theme.ListIcon(), a.summarizeNote),
+			button("export", "Экспорт", theme.DownloadIcon(), a.exportNote),
+			button("import", "Импорт", theme.UploadIcon(), a.importNote),
+			button("import_url", "Импорт из URL", theme.ComputerIcon(), a.importFromURL),
+			button("duplicates", "Дубликаты", theme.SearchReplaceIcon(), a.findDuplicates),
+			button("recurring", "Повторяющиеся", theme.HistoryIcon(), a.showRecurringRules),
+			button("snippets", "Сниппеты", theme.ContentPasteIcon(), a.showSnippets),
+			button("replace", "Замена во всех", theme.ContentRedoIcon(), a.showBulkReplace),
+			button("stats", "Статистика", theme.GridIcon(), a.showStatistics),
+			button("activity", "Журнал изменений", theme.DocumentIcon(), a.showActivity),
+			button("reading", "Чтение", theme.VisibilityIcon(), a.showReadingMode),
+		},
+		box: container.New(layout.NewGridLayoutWithColumns(4)),
+	}
+	a.toolbar.show(fyne.CurrentApp().Preferences().StringListWithFallback(toolbarPreference, defaultToolbar))
+	return a.toolbar.box
+}
+
+// show выставляет на панель кнопки ids в указанном порядке; неизвестные ключи пропускаются.
+// Пустая панель скрывается, чтобы не занимать место на маленьком экране.
+func (t *toolbar) show(ids []string) {
+	objects := make([]fyne.CanvasObject, 0, len(ids))
+	for _, id := range ids {
+		if b := t.find(id); b != nil {
+			objects = append(objects, b.button)
+		}
+	}
+	t.box.Objects = objects
+	if len(objects) == 0 {
+		t.box.Hide()
+	} else {
+		t.box.Show()
+	}
+	t.box.Refresh()
+}
+
+// find возвращает кнопку по ключу или nil
+func (t *toolbar) find(id string) *toolbarButton {
+	for i := range t.buttons {
+		if t.buttons[i].id == id {
+			return &t.buttons[i]
+		}
+	}
+	return nil
+}
+
+// shownIDs возвращает ключи кнопок, которые сейчас на панели, по порядку
+func (t *toolbar) shownIDs() []string {
+	ids := make([]string, 0, len(t.box.Objects))
+	for _, o := range t.box.Objects {
+		for _, b := range t.buttons {
+			if b.button == o {
+				ids = append(ids, b.id)
+			}
+		}
+	}
+	return ids
+}
+
+// showToolbarSettings показывает выбор кнопок панели и их порядка. Сначала идут показанные кнопки
+// в своем порядке, за ними скрытые; выбор сохраняется в настройках приложения.
+func (a *NoteApp) showToolbarSettings() {
+	var order []string
+	shown := make(map[string]bool)
+	reset := func(ids []string) {
+		order = append([]string{}, ids...)
+		clear(shown)
+		for _, id := range ids {
+			shown[id] = true
+		}
+		for _, b := range a.toolbar.buttons {
+			if !slices.Contains(order, b.id) {
+				order = append(order, b.id)
+			}
+		}
+	}
+	reset(a.toolbar.shownIDs())
+
+	rows := container.NewVBox()
+	var render func()
+	move := func(i, step int) {
+		order[i], order[i+step] = order[i+step], order[i]
+		render()
+	}
+	render = func() {
+		rows.RemoveAll()
+		for i, id := range order {
+			i, id := i, id
+			check := widget.NewCheck(a.toolbar.find(id).button.Text, func(on bool) { shown[id] = on })
+			check.SetChecked(shown[id])
+			up := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() { move(i, -1) })
+			down := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() { move(i, 1) })
+			if i == 0 {
+				up.Disable()
+			}
+			if i == len(order)-1 {
+				down.Disable()
+			}
+			rows.Add(container.NewBorder(nil, nil, nil, container.NewHBox(up, down), check))
+		}
+	}
+	render()
+
+	defaultsButton := widget.NewButton("По умолчанию", func() {
+		reset(defaultToolbar)
+		render()
+	})
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(360, 400))
+	content := container.NewBorder(nil, container.NewHBox(layout.NewSpacer(), defaultsButton), nil, nil, scroll)
+	dialog.ShowCustomConfirm("Панель кнопок", "Сохранить", "Отмена", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		ids := make([]string, 0, len(order))
+		for _, id := range order {
+			if shown[id] {
+				ids = append(ids, id)
+			}
+		}
+		a.toolbar.show(ids)
+		fyne.CurrentApp().Preferences().SetStringList(toolbarPreference, ids)
+	}, a.window)
+}
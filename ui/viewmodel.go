@@ -0,0 +1,329 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2/data/binding"
+
+	"GNote/models"
+)
+
+// sortOptions — варианты сортировки списка заметок
+var sortOptions = []string{
+	"По дате создания (новые)",
+	"По дате создания (старые)",
+	"По дате обновления (новые)",
+	"По дате обновления (старые)",
+	"По заголовку (А-Я)",
+	"По заголовку (Я-А)",
+	"По расстоянию (ближние)",
+	"По приоритету (высокий)",
+}
+
+// NoteViewModel хранит состояние окна заметок без виджетов: список, фильтр, сортировку,
+// выбранную заметку и флаг несохраненных изменений. Виджеты только отображают это состояние.
+type NoteViewModel struct {
+	allNotes          []models.Note // Все загруженные заметки
+	filteredNotes     []models.Note // Отфильтрованные заметки для отображения в списке
+	selectedNoteIndex int           // Индекс выбранной заметки в filteredNotes (-1, если ничего не выбрано)
+
+	query             string           // Поисковый запрос
+	includedTags      []string         // Теги из дерева, которые должны быть у заметки (с вложенными)
+	excludedTags      []string         // Теги из дерева, заметки с которыми скрываются (с вложенными)
+	priorityFilter    models.Priority  // Показываются только заметки с этим приоритетом (anyPriority — все)
+	overdueOnly       bool             // Умный фильтр «Просроченные»: только заметки с прошедшим сроком
+	dateByUpdated     bool             // Диапазон дат относится к дате изменения, а не создания
+	dateFrom          time.Time        // Первый день диапазона в местном времени; нулевое — без начала
+	dateTo            time.Time        // Последний день диапазона включительно; нулевое — без конца
+	sortCriteria      string           // Выбранный вариант из sortOptions
+	proximityCenter   *models.Location // Точка для фильтрации и сортировки по близости
+	proximityRadiusKm float64          // Радиус фильтра по близости (0 — без ограничения)
+
+	tagStyles map[string]models.TagStyle // Цвет и значок тегов, у которых они заданы
+
+	// Поля основного редактора, привязанные к виджетам
+	noteTitle   binding.String
+	noteTags    binding.String // Теги через запятую, как в поле ввода
+	noteContent binding.String // Содержимое обычного редактора (большие заметки — в largeTextView)
+
+	dirty binding.Bool // Есть ли несохраненные изменения в основном редакторе
+}
+
+// NewNoteViewModel создает пустую модель представления
+func NewNoteViewModel() *NoteViewModel {
+	return &NoteViewModel{
+		selectedNoteIndex: -1,
+		sortCriteria:      sortOptions[0],
+		tagStyles:         make(map[string]models.TagStyle),
+		priorityFilter:    anyPriority,
+		noteTitle:         binding.NewString(),
+		noteTags:          binding.NewString(),
+		noteContent:       binding.NewString(),
+		dirty:             binding.NewBool(),
+	}
+}
+
+// hasUnsavedChanges сообщает, есть ли несохраненные изменения
+func (vm *NoteViewModel) hasUnsavedChanges() bool {
+	dirty, _ := vm.dirty.Get()
+	return dirty
+}
+
+// editedTitle возвращает заголовок из редактора без пробелов по краям
+func (vm *NoteViewModel) editedTitle() string {
+	title, _ := vm.noteTitle.Get()
+	return strings.TrimSpace(title)
+}
+
+// editedTags возвращает теги из редактора
+func (vm *NoteViewModel) editedTags() []string {
+	tags, _ := vm.noteTags.Get()
+	return parseTags(tags)
+}
+
+// getSelectedNote возвращает выбранную заметку или nil
+func (vm *NoteViewModel) getSelectedNote() *models.Note {
+	if vm.selectedNoteIndex == -1 || vm.selectedNoteIndex >= len(vm.filteredNotes) {
+		return nil
+	}
+	return &vm.filteredNotes[vm.selectedNoteIndex]
+}
+
+// filteredIndexOf возвращает индекс заметки с указанным ID в filteredNotes или -1
+func (vm *NoteViewModel) filteredIndexOf(id int) int {
+	for i, note := range vm.filteredNotes {
+		if note.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// noteCreated добавляет созданную заметку в список без перезагрузки из БД.
+// Список на экране обновляется вызывающей стороной (см. applyStoreEvents).
+func (vm *NoteViewModel) noteCreated(note models.Note) {
+	note.Attachments = nil // Вложения загружаются при выборе заметки
+	for i := range vm.allNotes {
+		if vm.allNotes[i].ID == note.ID { // Заметка уже в списке (например, после loadNotes)
+			vm.allNotes[i] = note
+			return
+		}
+	}
+	vm.allNotes = append(vm.allNotes, note)
+}
+
+// noteUpdated заменяет заметку в списке обновленной версией
+func (vm *NoteViewModel) noteUpdated(note models.Note) {
+	for i := range vm.allNotes {
+		if vm.allNotes[i].ID == note.ID {
+			vm.allNotes[i] = note
+			break
+		}
+	}
+	// filteredNotes может не совпадать с allNotes, если применен фильтр
+	for i := range vm.filteredNotes {
+		if vm.filteredNotes[i].ID == note.ID {
+			vm.filteredNotes[i] = note
+			break
+		}
+	}
+}
+
+// setAttachmentMeta обновляет имена и типы вложений заметки в списке (для поиска has: и file:)
+func (vm *NoteViewModel) setAttachmentMeta(noteID int, names, types []string) {
+	for _, notes := range [][]models.Note{vm.allNotes, vm.filteredNotes} {
+		for i := range notes {
+			if notes[i].ID == noteID {
+				notes[i].AttachmentNames = names
+				notes[i].AttachmentTypes = types
+			}
+		}
+	}
+}
+
+// attachmentAdded добавляет вложение к именам и типам вложений заметки в списке
+func (vm *NoteViewModel) attachmentAdded(attachment models.Attachment) {
+	for _, note := range vm.allNotes {
+		if note.ID == attachment.NoteID {
+			names := append(append([]string{}, note.AttachmentNames...), attachment.Filename)
+			types := append(append([]string{}, note.AttachmentTypes...), attachment.MimeType)
+			vm.setAttachmentMeta(note.ID, names, types)
+			return
+		}
+	}
+}
+
+// noteDeleted убирает удаленную заметку из списка
+func (vm *NoteViewModel) noteDeleted(id int) {
+	notes := make([]models.Note, 0, len(vm.allNotes))
+	for _, note := range vm.allNotes {
+		if note.ID != id {
+			notes = append(notes, note)
+		}
+	}
+	vm.allNotes = notes
+}
+
+// refilter заново фильтрует и сортирует заметки, сохраняя выбор по ID.
+// Возвращает false, если выбранная заметка пропала из списка (выбор при этом сбрасывается).
+func (vm *NoteViewModel) refilter() bool {
+	selected := vm.getSelectedNote()
+	selectedID := -1 // Запоминаем выбранную заметку до изменения filteredNotes
+	if selected != nil {
+		selectedID = selected.ID
+	}
+
+	search := parseSearchQuery(vm.query)
+	query := search.text
+	now := time.Now()
+	if search.empty() && vm.proximityCenter == nil && len(vm.includedTags) == 0 && len(vm.excludedTags) == 0 && vm.priorityFilter == anyPriority && !vm.overdueOnly &&
+		vm.dateFrom.IsZero() && vm.dateTo.IsZero() {
+		vm.filteredNotes = vm.allNotes
+	} else {
+		vm.filteredNotes = []models.Note{}
+		for _, note := range vm.allNotes {
+			if !vm.matchesProximity(note) || !vm.matchesTag(note) ||
+				(vm.priorityFilter != anyPriority && note.Priority != vm.priorityFilter) ||
+				(vm.overdueOnly && !isOverdue(note, now)) || !vm.matchesDates(note) ||
+				!search.matchesAttachments(note) || !search.matchesTags(note) {
+				continue
+			}
+			if query == "" ||
+				strings.Contains(strings.ToLower(note.Title), query) ||
+				strings.Contains(strings.ToLower(note.Content), query) ||
+				strings.Contains(strings.ToLower(strings.Join(note.Tags, ",")), query) || // Поиск по тегам
+				strings.Contains(strings.ToLower(note.AttachmentText), query) { // Текст, распознанный во вложениях
+				vm.filteredNotes = append(vm.filteredNotes, note)
+			}
+		}
+	}
+	vm.sortNotes() // Пересортируем после фильтрации
+
+	if selected == nil {
+		return true
+	}
+	vm.selectedNoteIndex = vm.filteredIndexOf(selectedID)
+	return vm.selectedNoteIndex != -1
+}
+
+// filterSummary возвращает описания действующих фильтров списка; пусто — показаны все заметки
+func (vm *NoteViewModel) filterSummary() []string {
+	var filters []string
+	for _, tag := range vm.includedTags {
+		filters = append(filters, "#"+tag)
+	}
+	for _, tag := range vm.excludedTags {
+		filters = append(filters, "без #"+tag)
+	}
+	if query := strings.TrimSpace(vm.query); query != "" {
+		filters = append(filters, fmt.Sprintf("поиск «%s»", query))
+	}
+	if vm.priorityFilter != anyPriority {
+		filters = append(filters, "приоритет: "+strings.ToLower(priorityNames[vm.priorityFilter]))
+	}
+	if vm.overdueOnly {
+		filters = append(filters, "просроченные")
+	}
+	if dates := vm.dateSummary(); dates != "" {
+		filters = append(filters, dates)
+	}
+	if vm.proximityCenter != nil && vm.proximityRadiusKm > 0 {
+		filters = append(filters, fmt.Sprintf("в радиусе %g км", vm.proximityRadiusKm))
+	}
+	return filters
+}
+
+// matchesTag сообщает, что у заметки есть все включенные в фильтр теги и нет исключенных
+// (тег учитывается вместе с вложенными)
+func (vm *NoteViewModel) matchesTag(note models.Note) bool {
+	for _, filter := range vm.includedTags {
+		if !hasTagOrNested(note, filter) {
+			return false
+		}
+	}
+	for _, filter := range vm.excludedTags {
+		if hasTagOrNested(note, filter) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasTagOrNested сообщает, что заметка отмечена тегом tag или вложенным в него
+func hasTagOrNested(note models.Note, tag string) bool {
+	for _, t := range note.Tags {
+		if models.TagHasPrefix(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortNotes сортирует filteredNotes на основе выбранного критерия
+func (vm *NoteViewModel) sortNotes() {
+	notes := vm.filteredNotes
+	switch vm.sortCriteria {
+	case "По дате создания (новые)":
+		sort.Slice(notes, func(i, j int) bool {
+			return notes[i].CreatedAt.After(notes[j].CreatedAt)
+		})
+	case "По дате создания (старые)":
+		sort.Slice(notes, func(i, j int) bool {
+			return notes[i].CreatedAt.Before(notes[j].CreatedAt)
+		})
+	case "По дате обновления (новые)":
+		sort.Slice(notes, func(i, j int) bool {
+			return notes[i].UpdatedAt.After(notes[j].UpdatedAt)
+		})
+	case "По дате обновления (старые)":
+		sort.Slice(notes, func(i, j int) bool {
+			return notes[i].UpdatedAt.Before(notes[j].UpdatedAt)
+		})
+	case "По заголовку (А-Я)":
+		sort.Slice(notes, func(i, j int) bool {
+			return strings.ToLower(notes[i].Title) < strings.ToLower(notes[j].Title)
+		})
+	case "По заголовку (Я-А)":
+		sort.Slice(notes, func(i, j int) bool {
+			return strings.ToLower(notes[i].Title) > strings.ToLower(notes[j].Title)
+		})
+	case "По расстоянию (ближние)":
+		vm.sortByDistance()
+	case "По приоритету (высокий)":
+		sort.SliceStable(notes, func(i, j int) bool {
+			if notes[i].Priority != notes[j].Priority {
+				return notes[i].Priority > notes[j].Priority
+			}
+			return notes[i].UpdatedAt.After(notes[j].UpdatedAt) // При равном приоритете — недавно измененные выше
+		})
+	}
+}
+
+// matchesProximity проверяет, попадает ли заметка в радиус фильтра по близости
+func (vm *NoteViewModel) matchesProximity(note models.Note) bool {
+	if vm.proximityCenter == nil || vm.proximityRadiusKm <= 0 {
+		return true
+	}
+	if note.Location == nil {
+		return false
+	}
+	return vm.proximityCenter.DistanceKm(*note.Location) <= vm.proximityRadiusKm
+}
+
+// sortByDistance сортирует заметки по расстоянию до точки фильтра; заметки без места — в конце
+func (vm *NoteViewModel) sortByDistance() {
+	if vm.proximityCenter == nil {
+		return
+	}
+	center := *vm.proximityCenter
+	sort.SliceStable(vm.filteredNotes, func(i, j int) bool {
+		li, lj := vm.filteredNotes[i].Location, vm.filteredNotes[j].Location
+		if li == nil || lj == nil {
+			return li != nil && lj == nil
+		}
+		return center.DistanceKm(*li) < center.DistanceKm(*lj)
+	})
+}
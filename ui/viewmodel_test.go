@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"GNote/models"
+)
+
+// testNotes — заметки для проверки фильтров и сортировки
+func testNotes() []models.Note {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 12, 0, 0, 0, time.Local) }
+	past := day(1)
+	return []models.Note{
+		{ID: 1, Title: "Покупки", Content: "Молоко и хлеб", Tags: []string{"дом"},
+			CreatedAt: day(1), UpdatedAt: day(10), Priority: models.PriorityLow},
+		{ID: 2, Title: "Отчет", Content: "Квартальный отчет", Tags: []string{"работа/отчеты"},
+			CreatedAt: day(2), UpdatedAt: day(3), Priority: models.PriorityHigh, DueAt: &past,
+			AttachmentNames: []string{"итоги.pdf"}, AttachmentTypes: []string{"application/pdf"}},
+		{ID: 3, Title: "архив писем", Tags: []string{"работа", "архив"},
+			CreatedAt: day(3), UpdatedAt: day(5), Priority: models.PriorityMedium,
+			Location: &models.Location{Lat: 55.75, Lon: 37.62}},
+		{ID: 4, Title: "Билеты", Content: "Поезд в Казань", AttachmentText: "вагон 7",
+			CreatedAt: day(4), UpdatedAt: day(4), Location: &models.Location{Lat: 55.79, Lon: 49.12}},
+	}
+}
+
+// noteIDs возвращает ID заметок по порядку
+func noteIDs(notes []models.Note) []int {
+	ids := make([]int, len(notes))
+	for i, note := range notes {
+		ids[i] = note.ID
+	}
+	return ids
+}
+
+func TestRefilter(t *testing.T) {
+	moscow := &models.Location{Lat: 55.75, Lon: 37.62}
+	tests := []struct {
+		name  string
+		setup func(vm *NoteViewModel)
+		want  []int
+	}{
+		{"без фильтров", func(vm *NoteViewModel) {}, []int{4, 3, 2, 1}},
+		{"текст в заголовке без учета регистра", func(vm *NoteViewModel) { vm.query = "ОТЧЕТ" }, []int{2}},
+		{"текст в содержимом", func(vm *NoteViewModel) { vm.query = "хлеб" }, []int{1}},
+		{"текст в тегах", func(vm *NoteViewModel) { vm.query = "отчеты" }, []int{2}},
+		{"текст во вложениях", func(vm *NoteViewModel) { vm.query = "вагон" }, []int{4}},
+		{"тип вложения", func(vm *NoteViewModel) { vm.query = "has:pdf" }, []int{2}},
+		{"имя вложения", func(vm *NoteViewModel) { vm.query = "file:итоги" }, []int{2}},
+		{"исключение тега в поиске", func(vm *NoteViewModel) { vm.query = "-#архив" }, []int{4, 2, 1}},
+		{"тег с вложенными", func(vm *NoteViewModel) { vm.includedTags = []string{"работа"} }, []int{3, 2}},
+		{"несколько тегов", func(vm *NoteViewModel) { vm.includedTags = []string{"работа", "архив"} }, []int{3}},
+		{"исключенный тег с вложенными", func(vm *NoteViewModel) { vm.excludedTags = []string{"работа"} }, []int{4, 1}},
+		{"приоритет", func(vm *NoteViewModel) { vm.priorityFilter = models.PriorityHigh }, []int{2}},
+		{"просроченные", func(vm *NoteViewModel) { vm.overdueOnly = true }, []int{2}},
+		{"дата создания", func(vm *NoteViewModel) {
+			vm.dateFrom = time.Date(2025, 3, 2, 0, 0, 0, 0, time.Local)
+			vm.dateTo = time.Date(2025, 3, 3, 0, 0, 0, 0, time.Local)
+		}, []int{3, 2}},
+		{"дата изменения", func(vm *NoteViewModel) {
+			vm.dateByUpdated = true
+			vm.dateFrom = time.Date(2025, 3, 5, 0, 0, 0, 0, time.Local)
+		}, []int{3, 1}},
+		{"радиус", func(vm *NoteViewModel) {
+			vm.proximityCenter = moscow
+			vm.proximityRadiusKm = 10
+		}, []int{3}},
+		{"фильтры вместе", func(vm *NoteViewModel) {
+			vm.includedTags = []string{"работа"}
+			vm.query = "отчет"
+		}, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewNoteViewModel()
+			vm.allNotes = testNotes()
+			tt.setup(vm)
+			vm.refilter()
+			if got := noteIDs(vm.filteredNotes); !slices.Equal(got, tt.want) {
+				t.Errorf("заметки %v, ожидались %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortNotes(t *testing.T) {
+	tests := []struct {
+		criteria string
+		want     []int
+	}{
+		{"По дате создания (новые)", []int{4, 3, 2, 1}},
+		{"По дате создания (старые)", []int{1, 2, 3, 4}},
+		{"По дате обновления (новые)", []int{1, 3, 4, 2}},
+		{"По дате обновления (старые)", []int{2, 4, 3, 1}},
+		{"По заголовку (А-Я)", []int{3, 4, 2, 1}},
+		{"По заголовку (Я-А)", []int{1, 2, 4, 3}},
+		{"По расстоянию (ближние)", []int{3, 4, 1, 2}}, // Без места — в конце, в исходном порядке
+		{"По приоритету (высокий)", []int{2, 3, 1, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.criteria, func(t *testing.T) {
+			vm := NewNoteViewModel()
+			vm.allNotes = testNotes()
+			vm.sortCriteria = tt.criteria
+			vm.proximityCenter = &models.Location{Lat: 55.75, Lon: 37.62}
+			vm.refilter()
+			if got := noteIDs(vm.filteredNotes); !slices.Equal(got, tt.want) {
+				t.Errorf("порядок %v, ожидался %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefilterKeepsSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantKept  bool
+		wantIndex int
+	}{
+		{"заметка осталась в списке", "работа", true, 1},
+		{"заметка отфильтрована", "хлеб", false, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewNoteViewModel()
+			vm.allNotes = testNotes()
+			vm.refilter()
+			vm.selectedNoteIndex = vm.filteredIndexOf(2)
+			vm.query = tt.query
+			if kept := vm.refilter(); kept != tt.wantKept || vm.selectedNoteIndex != tt.wantIndex {
+				t.Errorf("refilter() = %v, индекс %d; ожидалось %v, %d", kept, vm.selectedNoteIndex, tt.wantKept, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestFilterSummary(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(vm *NoteViewModel)
+		want  []string
+	}{
+		{"без фильтров", func(vm *NoteViewModel) {}, nil},
+		{"теги и поиск", func(vm *NoteViewModel) {
+			vm.includedTags = []string{"работа"}
+			vm.excludedTags = []string{"архив"}
+			vm.query = "  отчет "
+		}, []string{"#работа", "без #архив", "поиск «отчет»"}},
+		{"приоритет и срок", func(vm *NoteViewModel) {
+			vm.priorityFilter = models.PriorityHigh
+			vm.overdueOnly = true
+		}, []string{"приоритет: высокий", "просроченные"}},
+		{"даты и радиус", func(vm *NoteViewModel) {
+			vm.dateFrom = time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)
+			vm.proximityCenter = &models.Location{}
+			vm.proximityRadiusKm = 5
+		}, []string{"создана с 01.03.2025", "в радиусе 5 км"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewNoteViewModel()
+			tt.setup(vm)
+			if got := vm.filterSummary(); !slices.Equal(got, tt.want) {
+				t.Errorf("filterSummary() = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}